@@ -269,7 +269,7 @@ func whenCreateTopic(
 		topicName,
 		partitionsCount,
 		iggcon.CompressionAlgorithmNone,
-		iggcon.MessengerExpiryNeverExpire,
+		iggcon.ExpiryNever,
 		0,
 		nil,
 		&uint32TopicID,
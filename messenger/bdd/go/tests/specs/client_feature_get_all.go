@@ -15,7 +15,7 @@
 // specific language governing permissions and limitations
 // under the License.
 
-package tcp_test
+package specs
 
 import (
 	"github.com/onsi/ginkgo/v2"
@@ -24,28 +24,28 @@ import (
 
 var _ = ginkgo.Describe("GET ALL CLIENT FEATURE:", func() {
 	ginkgo.When("user is logged in", func() {
-		ginkgo.Context("and tries to log with correct data", func() {
+		ginkgo.It("and tries to log with correct data", func() {
 			client := createAuthorizedConnection()
 			clients, err := client.GetClients()
 
 			itShouldNotReturnError(err)
-			ginkgo.It("should return stats", func() {
+			it("should return stats", func() {
 				gomega.Expect(clients).ToNot(gomega.BeNil())
 			})
 
-			ginkgo.It("should return at least one client", func() {
+			it("should return at least one client", func() {
 				gomega.Expect(len(clients)).ToNot(gomega.BeZero())
 			})
 		})
 	})
 
 	ginkgo.When("user is not logged in", func() {
-		ginkgo.Context("and tries get all clients", func() {
+		ginkgo.It("and tries get all clients", func() {
 			client := createClient()
 			clients, err := client.GetClients()
 
 			itShouldReturnUnauthenticatedError(err)
-			ginkgo.It("should not return clients", func() {
+			it("should not return clients", func() {
 				gomega.Expect(clients).To(gomega.BeNil())
 			})
 		})
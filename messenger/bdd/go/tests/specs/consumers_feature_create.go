@@ -15,7 +15,7 @@
 // specific language governing permissions and limitations
 // under the License.
 
-package tcp_test
+package specs
 
 import (
 	iggcon "github.com/apache/messenger/foreign/go/contracts"
@@ -25,10 +25,10 @@ import (
 var _ = ginkgo.Describe("CREATE CONSUMER GROUP:", func() {
 	prefix := "CreateConsumerGroup"
 	ginkgo.When("User is logged in", func() {
-		ginkgo.Context("and tries to create consumer group unique name and id", func() {
+		ginkgo.It("and tries to create consumer group unique name and id", func() {
 			client := createAuthorizedConnection()
 			streamId, _ := successfullyCreateStream(prefix, client)
-			defer deleteStreamAfterTests(streamId, client)
+			ginkgo.DeferCleanup(deleteStreamAfterTests, streamId, client)
 			topicId, _ := successfullyCreateTopic(streamId, client)
 			streamIdentifier, _ := iggcon.NewIdentifier(streamId)
 			topicIdentifier, _ := iggcon.NewIdentifier(topicId)
@@ -45,7 +45,7 @@ var _ = ginkgo.Describe("CREATE CONSUMER GROUP:", func() {
 			itShouldSuccessfullyCreateConsumer(streamId, topicId, groupId, name, client)
 		})
 
-		ginkgo.Context("and tries to create consumer group for a non existing stream", func() {
+		ginkgo.It("and tries to create consumer group for a non existing stream", func() {
 			client := createAuthorizedConnection()
 			groupId := createRandomUInt32()
 			_, err := client.CreateConsumerGroup(
@@ -57,10 +57,10 @@ var _ = ginkgo.Describe("CREATE CONSUMER GROUP:", func() {
 			itShouldReturnSpecificError(err, "stream_id_not_found")
 		})
 
-		ginkgo.Context("and tries to create consumer group for a non existing topic", func() {
+		ginkgo.It("and tries to create consumer group for a non existing topic", func() {
 			client := createAuthorizedConnection()
 			streamId, _ := successfullyCreateStream(prefix, client)
-			defer deleteStreamAfterTests(streamId, client)
+			ginkgo.DeferCleanup(deleteStreamAfterTests, streamId, client)
 			groupId := createRandomUInt32()
 			streamIdentifier, _ := iggcon.NewIdentifier(streamId)
 			_, err := client.CreateConsumerGroup(
@@ -73,10 +73,10 @@ var _ = ginkgo.Describe("CREATE CONSUMER GROUP:", func() {
 			itShouldReturnSpecificError(err, "topic_id_not_found")
 		})
 
-		ginkgo.Context("and tries to create consumer group with duplicate group name", func() {
+		ginkgo.It("and tries to create consumer group with duplicate group name", func() {
 			client := createAuthorizedConnection()
 			streamId, _ := successfullyCreateStream(prefix, client)
-			defer deleteStreamAfterTests(streamId, client)
+			ginkgo.DeferCleanup(deleteStreamAfterTests, streamId, client)
 			topicId, _ := successfullyCreateTopic(streamId, client)
 			_, name := successfullyCreateConsumer(streamId, topicId, client)
 
@@ -93,10 +93,10 @@ var _ = ginkgo.Describe("CREATE CONSUMER GROUP:", func() {
 			itShouldReturnSpecificError(err, "cannot_create_consumer_groups_directory")
 		})
 
-		ginkgo.Context("and tries to create consumer group with duplicate group id", func() {
+		ginkgo.It("and tries to create consumer group with duplicate group id", func() {
 			client := createAuthorizedConnection()
 			streamId, _ := successfullyCreateStream(prefix, client)
-			defer deleteStreamAfterTests(streamId, client)
+			ginkgo.DeferCleanup(deleteStreamAfterTests, streamId, client)
 			topicId, _ := successfullyCreateTopic(streamId, client)
 			groupId, _ := successfullyCreateConsumer(streamId, topicId, client)
 
@@ -111,10 +111,10 @@ var _ = ginkgo.Describe("CREATE CONSUMER GROUP:", func() {
 			itShouldReturnSpecificError(err, "consumer_group_already_exists")
 		})
 
-		ginkgo.Context("and tries to create group with name that's over 255 characters", func() {
+		ginkgo.It("and tries to create group with name that's over 255 characters", func() {
 			client := createAuthorizedConnection()
 			streamId, _ := successfullyCreateStream(prefix, client)
-			defer deleteStreamAfterTests(streamId, client)
+			ginkgo.DeferCleanup(deleteStreamAfterTests, streamId, client)
 			topicId, _ := successfullyCreateTopic(streamId, client)
 
 			groupId := createRandomUInt32()
@@ -131,7 +131,7 @@ var _ = ginkgo.Describe("CREATE CONSUMER GROUP:", func() {
 	})
 
 	ginkgo.When("User is not logged in", func() {
-		ginkgo.Context("and tries to create consumer group", func() {
+		ginkgo.It("and tries to create consumer group", func() {
 			client := createClient()
 			groupId := createRandomUInt32()
 			_, err := client.CreateConsumerGroup(
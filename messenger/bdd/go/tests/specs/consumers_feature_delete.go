@@ -15,7 +15,7 @@
 // specific language governing permissions and limitations
 // under the License.
 
-package tcp_test
+package specs
 
 import (
 	iggcon "github.com/apache/messenger/foreign/go/contracts"
@@ -26,10 +26,10 @@ import (
 var _ = ginkgo.Describe("DELETE CONSUMER GROUP:", func() {
 	prefix := "DeleteConsumerGroup"
 	ginkgo.When("User is logged in", func() {
-		ginkgo.Context("and tries to delete existing consumer group", func() {
+		ginkgo.It("and tries to delete existing consumer group", func() {
 			client := createAuthorizedConnection()
 			streamId, _ := successfullyCreateStream(prefix, client)
-			defer deleteStreamAfterTests(streamId, client)
+			ginkgo.DeferCleanup(deleteStreamAfterTests, streamId, client)
 			topicId, _ := successfullyCreateTopic(streamId, client)
 			groupId, _ := successfullyCreateConsumer(streamId, topicId, client)
 
@@ -46,10 +46,10 @@ var _ = ginkgo.Describe("DELETE CONSUMER GROUP:", func() {
 			itShouldSuccessfullyDeletedConsumer(streamId, topicId, groupId, client)
 		})
 
-		ginkgo.Context("and tries to delete non-existing consumer group", func() {
+		ginkgo.It("and tries to delete non-existing consumer group", func() {
 			client := createAuthorizedConnection()
 			streamId, _ := successfullyCreateStream(prefix, client)
-			defer deleteStreamAfterTests(streamId, client)
+			ginkgo.DeferCleanup(deleteStreamAfterTests, streamId, client)
 			topicId, _ := successfullyCreateTopic(streamId, client)
 
 			streamIdentifier, _ := iggcon.NewIdentifier(streamId)
@@ -63,10 +63,10 @@ var _ = ginkgo.Describe("DELETE CONSUMER GROUP:", func() {
 			itShouldReturnSpecificMessengerError(err, ierror.ConsumerGroupIdNotFound)
 		})
 
-		ginkgo.Context("and tries to delete consumer non-existing topic", func() {
+		ginkgo.It("and tries to delete consumer non-existing topic", func() {
 			client := createAuthorizedConnection()
 			streamId, _ := successfullyCreateStream(prefix, client)
-			defer deleteStreamAfterTests(streamId, client)
+			ginkgo.DeferCleanup(deleteStreamAfterTests, streamId, client)
 
 			streamIdentifier, _ := iggcon.NewIdentifier(streamId)
 			err := client.DeleteConsumerGroup(
@@ -78,7 +78,7 @@ var _ = ginkgo.Describe("DELETE CONSUMER GROUP:", func() {
 			itShouldReturnSpecificError(err, "topic_id_not_found")
 		})
 
-		ginkgo.Context("and tries to delete consumer for non-existing topic and stream", func() {
+		ginkgo.It("and tries to delete consumer for non-existing topic and stream", func() {
 			client := createAuthorizedConnection()
 			err := client.DeleteConsumerGroup(
 				randomU32Identifier(),
@@ -91,7 +91,7 @@ var _ = ginkgo.Describe("DELETE CONSUMER GROUP:", func() {
 	})
 
 	ginkgo.When("User is not logged in", func() {
-		ginkgo.Context("and tries to delete consumer group", func() {
+		ginkgo.It("and tries to delete consumer group", func() {
 			client := createClient()
 			err := client.DeleteConsumerGroup(
 				randomU32Identifier(),
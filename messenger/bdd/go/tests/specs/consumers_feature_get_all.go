@@ -15,7 +15,7 @@
 // specific language governing permissions and limitations
 // under the License.
 
-package tcp_test
+package specs
 
 import (
 	iggcon "github.com/apache/messenger/foreign/go/contracts"
@@ -25,10 +25,10 @@ import (
 var _ = ginkgo.Describe("GET ALL CONSUMER GROUPS:", func() {
 	prefix := "GetAllConsumerGroups"
 	ginkgo.When("User is logged in", func() {
-		ginkgo.Context("and tries to get all consumer groups", func() {
+		ginkgo.It("and tries to get all consumer groups", func() {
 			client := createAuthorizedConnection()
 			streamId, _ := successfullyCreateStream(prefix, client)
-			defer deleteStreamAfterTests(streamId, client)
+			ginkgo.DeferCleanup(deleteStreamAfterTests, streamId, client)
 			topicId, _ := successfullyCreateTopic(streamId, client)
 			groupId, name := successfullyCreateConsumer(streamId, topicId, client)
 			streamIdentifier, _ := iggcon.NewIdentifier(streamId)
@@ -41,7 +41,7 @@ var _ = ginkgo.Describe("GET ALL CONSUMER GROUPS:", func() {
 	})
 
 	ginkgo.When("User is not logged in", func() {
-		ginkgo.Context("and tries to get all consumer groups", func() {
+		ginkgo.It("and tries to get all consumer groups", func() {
 			client := createClient()
 			_, err := client.GetConsumerGroups(randomU32Identifier(), randomU32Identifier())
 
@@ -15,7 +15,7 @@
 // specific language governing permissions and limitations
 // under the License.
 
-package tcp_test
+package specs
 
 import (
 	iggcon "github.com/apache/messenger/foreign/go/contracts"
@@ -26,10 +26,10 @@ import (
 var _ = ginkgo.Describe("GET CONSUMER GROUP BY ID:", func() {
 	prefix := "GetConsumerGroup"
 	ginkgo.When("User is logged in", func() {
-		ginkgo.Context("and tries to get existing consumer group", func() {
+		ginkgo.It("and tries to get existing consumer group", func() {
 			client := createAuthorizedConnection()
 			streamId, _ := successfullyCreateStream(prefix, client)
-			defer deleteStreamAfterTests(streamId, client)
+			ginkgo.DeferCleanup(deleteStreamAfterTests, streamId, client)
 			topicId, _ := successfullyCreateTopic(streamId, client)
 			groupId, name := successfullyCreateConsumer(streamId, topicId, client)
 			streamIdentifier, _ := iggcon.NewIdentifier(streamId)
@@ -41,7 +41,7 @@ var _ = ginkgo.Describe("GET CONSUMER GROUP BY ID:", func() {
 			itShouldReturnSpecificConsumer(groupId, name, &group.ConsumerGroup)
 		})
 
-		ginkgo.Context("and tries to get consumer from non-existing stream", func() {
+		ginkgo.It("and tries to get consumer from non-existing stream", func() {
 			client := createAuthorizedConnection()
 
 			_, err := client.GetConsumerGroup(
@@ -53,10 +53,10 @@ var _ = ginkgo.Describe("GET CONSUMER GROUP BY ID:", func() {
 			itShouldReturnSpecificMessengerError(err, ierror.ConsumerGroupIdNotFound)
 		})
 
-		ginkgo.Context("and tries to get consumer from non-existing topic", func() {
+		ginkgo.It("and tries to get consumer from non-existing topic", func() {
 			client := createAuthorizedConnection()
 			streamId, _ := successfullyCreateStream(prefix, client)
-			defer deleteStreamAfterTests(streamId, client)
+			ginkgo.DeferCleanup(deleteStreamAfterTests, streamId, client)
 			streamIdentifier, _ := iggcon.NewIdentifier(streamId)
 			_, err := client.GetConsumerGroup(
 				streamIdentifier,
@@ -67,10 +67,10 @@ var _ = ginkgo.Describe("GET CONSUMER GROUP BY ID:", func() {
 			itShouldReturnSpecificMessengerError(err, ierror.ConsumerGroupIdNotFound)
 		})
 
-		ginkgo.Context("and tries to get from non-existing consumer", func() {
+		ginkgo.It("and tries to get from non-existing consumer", func() {
 			client := createAuthorizedConnection()
 			streamId, _ := successfullyCreateStream(prefix, client)
-			defer deleteStreamAfterTests(streamId, client)
+			ginkgo.DeferCleanup(deleteStreamAfterTests, streamId, client)
 			topicId, _ := successfullyCreateTopic(streamId, client)
 			streamIdentifier, _ := iggcon.NewIdentifier(streamId)
 			topicIdentifier, _ := iggcon.NewIdentifier(topicId)
@@ -15,7 +15,7 @@
 // specific language governing permissions and limitations
 // under the License.
 
-package tcp_test
+package specs
 
 import (
 	iggcon "github.com/apache/messenger/foreign/go/contracts"
@@ -25,10 +25,10 @@ import (
 var _ = ginkgo.Describe("JOIN CONSUMER GROUP:", func() {
 	prefix := "JoinConsumerGroup"
 	ginkgo.When("User is logged in", func() {
-		ginkgo.Context("and tries to join existing consumer group", func() {
+		ginkgo.It("and tries to join existing consumer group", func() {
 			client := createAuthorizedConnection()
 			streamId, _ := successfullyCreateStream(prefix, client)
-			defer deleteStreamAfterTests(streamId, client)
+			ginkgo.DeferCleanup(deleteStreamAfterTests, streamId, client)
 			topicId, _ := successfullyCreateTopic(streamId, client)
 			groupId, _ := successfullyCreateConsumer(streamId, topicId, client)
 			streamIdentifier, _ := iggcon.NewIdentifier(streamId)
@@ -44,10 +44,10 @@ var _ = ginkgo.Describe("JOIN CONSUMER GROUP:", func() {
 			itShouldSuccessfullyJoinConsumer(streamId, topicId, groupId, client)
 		})
 
-		ginkgo.Context("and tries to join non-existing consumer group", func() {
+		ginkgo.It("and tries to join non-existing consumer group", func() {
 			client := createAuthorizedConnection()
 			streamId, _ := successfullyCreateStream(prefix, client)
-			defer deleteStreamAfterTests(streamId, client)
+			ginkgo.DeferCleanup(deleteStreamAfterTests, streamId, client)
 			topicId, _ := successfullyCreateTopic(streamId, client)
 			streamIdentifier, _ := iggcon.NewIdentifier(streamId)
 			topicIdentifier, _ := iggcon.NewIdentifier(topicId)
@@ -60,10 +60,10 @@ var _ = ginkgo.Describe("JOIN CONSUMER GROUP:", func() {
 			itShouldReturnSpecificError(err, "consumer_group_not_found")
 		})
 
-		ginkgo.Context("and tries to join consumer non-existing topic", func() {
+		ginkgo.It("and tries to join consumer non-existing topic", func() {
 			client := createAuthorizedConnection()
 			streamId, _ := successfullyCreateStream(prefix, client)
-			defer deleteStreamAfterTests(streamId, client)
+			ginkgo.DeferCleanup(deleteStreamAfterTests, streamId, client)
 			streamIdentifier, _ := iggcon.NewIdentifier(streamId)
 			err := client.JoinConsumerGroup(
 				streamIdentifier,
@@ -74,7 +74,7 @@ var _ = ginkgo.Describe("JOIN CONSUMER GROUP:", func() {
 			itShouldReturnSpecificError(err, "topic_id_not_found")
 		})
 
-		ginkgo.Context("and tries to join consumer for non-existing topic and stream", func() {
+		ginkgo.It("and tries to join consumer for non-existing topic and stream", func() {
 			client := createAuthorizedConnection()
 			err := client.JoinConsumerGroup(
 				randomU32Identifier(),
@@ -87,7 +87,7 @@ var _ = ginkgo.Describe("JOIN CONSUMER GROUP:", func() {
 	})
 
 	ginkgo.When("User is not logged in", func() {
-		ginkgo.Context("and tries to join to the consumer group", func() {
+		ginkgo.It("and tries to join to the consumer group", func() {
 			client := createClient()
 			err := client.JoinConsumerGroup(
 				randomU32Identifier(),
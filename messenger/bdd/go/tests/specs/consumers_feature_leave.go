@@ -15,7 +15,7 @@
 // specific language governing permissions and limitations
 // under the License.
 
-package tcp_test
+package specs
 
 import (
 	iggcon "github.com/apache/messenger/foreign/go/contracts"
@@ -25,10 +25,10 @@ import (
 var _ = ginkgo.Describe("LEAVE CONSUMER GROUP:", func() {
 	prefix := "LeaveConsumerGroup"
 	ginkgo.When("User is logged in", func() {
-		ginkgo.Context("and tries to leave consumer group, that he is a part of", func() {
+		ginkgo.It("and tries to leave consumer group, that he is a part of", func() {
 			client := createAuthorizedConnection()
 			streamId, _ := successfullyCreateStream(prefix, client)
-			defer deleteStreamAfterTests(streamId, client)
+			ginkgo.DeferCleanup(deleteStreamAfterTests, streamId, client)
 			topicId, _ := successfullyCreateTopic(streamId, client)
 			groupId, _ := successfullyCreateConsumer(streamId, topicId, client)
 			successfullyJoinConsumer(streamId, topicId, groupId, client)
@@ -46,10 +46,10 @@ var _ = ginkgo.Describe("LEAVE CONSUMER GROUP:", func() {
 			itShouldSuccessfullyLeaveConsumer(streamId, topicId, groupId, client)
 		})
 
-		ginkgo.Context("and tries to leave non-existing consumer group", func() {
+		ginkgo.It("and tries to leave non-existing consumer group", func() {
 			client := createAuthorizedConnection()
 			streamId, _ := successfullyCreateStream(prefix, client)
-			defer deleteStreamAfterTests(streamId, client)
+			ginkgo.DeferCleanup(deleteStreamAfterTests, streamId, client)
 			topicId, _ := successfullyCreateTopic(streamId, client)
 			streamIdentifier, _ := iggcon.NewIdentifier(streamId)
 			topicIdentifier, _ := iggcon.NewIdentifier(topicId)
@@ -62,10 +62,10 @@ var _ = ginkgo.Describe("LEAVE CONSUMER GROUP:", func() {
 			itShouldReturnSpecificError(err, "consumer_group_not_found")
 		})
 
-		ginkgo.Context("and tries to leave consumer non-existing topic", func() {
+		ginkgo.It("and tries to leave consumer non-existing topic", func() {
 			client := createAuthorizedConnection()
 			streamId, _ := successfullyCreateStream(prefix, client)
-			defer deleteStreamAfterTests(streamId, client)
+			ginkgo.DeferCleanup(deleteStreamAfterTests, streamId, client)
 			streamIdentifier, _ := iggcon.NewIdentifier(streamId)
 			err := client.LeaveConsumerGroup(
 				streamIdentifier,
@@ -76,7 +76,7 @@ var _ = ginkgo.Describe("LEAVE CONSUMER GROUP:", func() {
 			itShouldReturnSpecificError(err, "topic_id_not_found")
 		})
 
-		ginkgo.Context("and tries to leave consumer for non-existing topic and stream", func() {
+		ginkgo.It("and tries to leave consumer for non-existing topic and stream", func() {
 			client := createAuthorizedConnection()
 
 			err := client.LeaveConsumerGroup(
@@ -90,7 +90,7 @@ var _ = ginkgo.Describe("LEAVE CONSUMER GROUP:", func() {
 	})
 
 	ginkgo.When("User is not logged in", func() {
-		ginkgo.Context("and tries to leave to the consumer group", func() {
+		ginkgo.It("and tries to leave to the consumer group", func() {
 			client := createClient()
 			err := client.LeaveConsumerGroup(
 				randomU32Identifier(),
@@ -15,13 +15,12 @@
 // specific language governing permissions and limitations
 // under the License.
 
-package tcp_test
+package specs
 
 import (
 	"fmt"
 	iggcon "github.com/apache/messenger/foreign/go/contracts"
 	"github.com/apache/messenger/foreign/go/messengercli"
-	"github.com/onsi/ginkgo/v2"
 	"github.com/onsi/gomega"
 )
 
@@ -59,19 +58,19 @@ func successfullyJoinConsumer(streamId uint32, topicId uint32, groupId uint32, c
 //assertions
 
 func itShouldReturnSpecificConsumer(id uint32, name string, consumer *iggcon.ConsumerGroup) {
-	ginkgo.It("should fetch consumer with id "+string(rune(id)), func() {
+	it("should fetch consumer with id "+string(rune(id)), func() {
 		gomega.Expect(consumer).NotTo(gomega.BeNil())
 		gomega.Expect(consumer.Id).To(gomega.Equal(id))
 	})
 
-	ginkgo.It("should fetch consumer with name "+name, func() {
+	it("should fetch consumer with name "+name, func() {
 		gomega.Expect(consumer).NotTo(gomega.BeNil())
 		gomega.Expect(consumer.Name).To(gomega.Equal(name))
 	})
 }
 
 func itShouldContainSpecificConsumer(id uint32, name string, consumers []iggcon.ConsumerGroup) {
-	ginkgo.It("should fetch at least one consumer", func() {
+	it("should fetch at least one consumer", func() {
 		gomega.Expect(len(consumers)).NotTo(gomega.Equal(0))
 	})
 
@@ -86,12 +85,12 @@ func itShouldContainSpecificConsumer(id uint32, name string, consumers []iggcon.
 		}
 	}
 
-	ginkgo.It(fmt.Sprintf("should fetch consumer with id %d", id), func() {
+	it(fmt.Sprintf("should fetch consumer with id %d", id), func() {
 		gomega.Expect(found).To(gomega.BeTrue(), "Consumer with id %d and name %s not found", id, name)
 		gomega.Expect(consumer.Id).To(gomega.Equal(id))
 	})
 
-	ginkgo.It("should fetch consumer with name "+name, func() {
+	it("should fetch consumer with name "+name, func() {
 		gomega.Expect(found).To(gomega.BeTrue(), "Consumer with id %d and name %s not found", id, name)
 		gomega.Expect(consumer.Name).To(gomega.Equal(name))
 	})
@@ -102,12 +101,12 @@ func itShouldSuccessfullyCreateConsumer(streamId uint32, topicId uint32, groupId
 	topicIdentifier, _ := iggcon.NewIdentifier(topicId)
 	groupIdentifier, _ := iggcon.NewIdentifier(groupId)
 	consumer, err := client.GetConsumerGroup(streamIdentifier, topicIdentifier, groupIdentifier)
-	ginkgo.It("should create consumer with id "+string(rune(groupId)), func() {
+	it("should create consumer with id "+string(rune(groupId)), func() {
 		gomega.Expect(consumer).NotTo(gomega.BeNil())
 		gomega.Expect(consumer.Id).To(gomega.Equal(groupId))
 	})
 
-	ginkgo.It("should create consumer with name "+expectedName, func() {
+	it("should create consumer with name "+expectedName, func() {
 		gomega.Expect(consumer).NotTo(gomega.BeNil())
 		gomega.Expect(consumer.Name).To(gomega.Equal(expectedName))
 	})
@@ -120,7 +119,7 @@ func itShouldSuccessfullyDeletedConsumer(streamId uint32, topicId uint32, groupI
 	groupIdentifier, _ := iggcon.NewIdentifier(groupId)
 	consumer, err := client.GetConsumerGroup(streamIdentifier, topicIdentifier, groupIdentifier)
 	itShouldReturnSpecificError(err, "consumer_group_not_found")
-	ginkgo.It("should not return consumer", func() {
+	it("should not return consumer", func() {
 		gomega.Expect(consumer).To(gomega.BeNil())
 	})
 }
@@ -131,12 +130,12 @@ func itShouldSuccessfullyJoinConsumer(streamId uint32, topicId uint32, groupId u
 	groupIdentifier, _ := iggcon.NewIdentifier(groupId)
 	consumer, err := client.GetConsumerGroup(streamIdentifier, topicIdentifier, groupIdentifier)
 
-	ginkgo.It("should join consumer with id "+string(rune(groupId)), func() {
+	it("should join consumer with id "+string(rune(groupId)), func() {
 		gomega.Expect(consumer).NotTo(gomega.BeNil())
 		gomega.Expect(consumer.MembersCount).ToNot(gomega.Equal(0))
 	})
 
-	ginkgo.It("should contain 1 member with 2 partitions", func() {
+	it("should contain 1 member with 2 partitions", func() {
 		gomega.Expect(len(consumer.Members)).To(gomega.Equal(1))
 		gomega.Expect(consumer.Members[0].PartitionsCount).To(gomega.Equal(uint32(2)))
 		gomega.Expect(len(consumer.Members[0].Partitions)).To(gomega.Equal(2))
@@ -150,7 +149,7 @@ func itShouldSuccessfullyLeaveConsumer(streamId uint32, topicId uint32, groupId
 	topicIdentifier, _ := iggcon.NewIdentifier(topicId)
 	groupIdentifier, _ := iggcon.NewIdentifier(groupId)
 	consumer, err := client.GetConsumerGroup(streamIdentifier, topicIdentifier, groupIdentifier)
-	ginkgo.It("should leave consumer with id "+string(rune(groupId)), func() {
+	it("should leave consumer with id "+string(rune(groupId)), func() {
 		gomega.Expect(consumer).NotTo(gomega.BeNil())
 		gomega.Expect(consumer.MembersCount).To(gomega.Equal(uint32(0)))
 	})
@@ -15,7 +15,7 @@
 // specific language governing permissions and limitations
 // under the License.
 
-package tcp_test
+package specs
 
 import (
 	iggcon "github.com/apache/messenger/foreign/go/contracts"
@@ -25,10 +25,10 @@ import (
 var _ = ginkgo.Describe("SEND MESSAGES:", func() {
 	prefix := "SendMessages"
 	ginkgo.When("User is logged in", func() {
-		ginkgo.Context("and tries to send messages to the topic with balanced partitioning", func() {
+		ginkgo.It("and tries to send messages to the topic with balanced partitioning", func() {
 			client := createAuthorizedConnection()
 			streamId, _ := successfullyCreateStream("1"+prefix, client)
-			defer deleteStreamAfterTests(streamId, client)
+			ginkgo.DeferCleanup(deleteStreamAfterTests, streamId, client)
 			topicId, _ := successfullyCreateTopic(streamId, client)
 			messages := createDefaultMessages()
 			streamIdentifier, _ := iggcon.NewIdentifier(streamId)
@@ -43,10 +43,10 @@ var _ = ginkgo.Describe("SEND MESSAGES:", func() {
 			itShouldSuccessfullyPublishMessages(streamId, topicId, messages, client)
 		})
 
-		ginkgo.Context("and tries to send messages to the non existing topic", func() {
+		ginkgo.It("and tries to send messages to the non existing topic", func() {
 			client := createAuthorizedConnection()
 			streamId, _ := successfullyCreateStream("2"+prefix, client)
-			defer deleteStreamAfterTests(streamId, client)
+			ginkgo.DeferCleanup(deleteStreamAfterTests, streamId, client)
 			messages := createDefaultMessages()
 			streamIdentifier, _ := iggcon.NewIdentifier(streamId)
 			err := client.SendMessages(
@@ -58,7 +58,7 @@ var _ = ginkgo.Describe("SEND MESSAGES:", func() {
 			itShouldReturnSpecificError(err, "topic_id_not_found")
 		})
 
-		ginkgo.Context("and tries to send messages to the non existing stream", func() {
+		ginkgo.It("and tries to send messages to the non existing stream", func() {
 			client := createAuthorizedConnection()
 			messages := createDefaultMessages()
 			err := client.SendMessages(
@@ -70,10 +70,10 @@ var _ = ginkgo.Describe("SEND MESSAGES:", func() {
 			itShouldReturnSpecificError(err, "stream_id_not_found")
 		})
 
-		ginkgo.Context("and tries to send messages to non existing partition", func() {
+		ginkgo.It("and tries to send messages to non existing partition", func() {
 			client := createAuthorizedConnection()
 			streamId, _ := successfullyCreateStream("3"+prefix, client)
-			defer deleteStreamAfterTests(streamId, client)
+			ginkgo.DeferCleanup(deleteStreamAfterTests, streamId, client)
 			topicId, _ := successfullyCreateTopic(streamId, client)
 			messages := createDefaultMessages()
 			streamIdentifier, _ := iggcon.NewIdentifier(streamId)
@@ -87,10 +87,10 @@ var _ = ginkgo.Describe("SEND MESSAGES:", func() {
 			itShouldReturnSpecificError(err, "partition_not_found")
 		})
 
-		ginkgo.Context("and tries to send messages to valid topic but with 0 messages in payload", func() {
+		ginkgo.It("and tries to send messages to valid topic but with 0 messages in payload", func() {
 			client := createAuthorizedConnection()
 			streamId, _ := successfullyCreateStream(prefix, client)
-			defer deleteStreamAfterTests(streamId, createAuthorizedConnection())
+			ginkgo.DeferCleanup(deleteStreamAfterTests, streamId, createAuthorizedConnection())
 			topicId, _ := successfullyCreateTopic(streamId, client)
 			streamIdentifier, _ := iggcon.NewIdentifier(streamId)
 			topicIdentifier, _ := iggcon.NewIdentifier(topicId)
@@ -105,7 +105,7 @@ var _ = ginkgo.Describe("SEND MESSAGES:", func() {
 	})
 
 	ginkgo.When("User is not logged in", func() {
-		ginkgo.Context("and tries to update stream", func() {
+		ginkgo.It("and tries to update stream", func() {
 			client := createClient()
 			messages := createDefaultMessages()
 			err := client.SendMessages(
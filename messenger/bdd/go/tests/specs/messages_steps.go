@@ -15,7 +15,7 @@
 // specific language governing permissions and limitations
 // under the License.
 
-package tcp_test
+package specs
 
 import (
 	"bytes"
@@ -24,7 +24,6 @@ import (
 	iggcon "github.com/apache/messenger/foreign/go/contracts"
 	"github.com/apache/messenger/foreign/go/messengercli"
 	"github.com/google/uuid"
-	"github.com/onsi/ginkgo/v2"
 	"github.com/onsi/gomega"
 )
 
@@ -54,22 +53,22 @@ func itShouldSuccessfullyPublishMessages(streamId uint32, topicId uint32, messag
 		true,
 		nil)
 
-	ginkgo.It("It should not be nil", func() {
+	it("It should not be nil", func() {
 		gomega.Expect(result).NotTo(gomega.BeNil())
 	})
 
-	ginkgo.It("It should contain 2 messages", func() {
+	it("It should contain 2 messages", func() {
 		gomega.Expect(len(result.Messages)).To(gomega.Equal(len(messages)))
 	})
 
 	for _, expectedMsg := range messages {
-		ginkgo.It("It should contain published messages", func() {
+		it("It should contain published messages", func() {
 			found := compareMessage(result.Messages, expectedMsg)
 			gomega.Expect(found).To(gomega.BeTrue(), "Message not found or does not match expected values")
 		})
 	}
 
-	ginkgo.It("Should not return error", func() {
+	it("Should not return error", func() {
 		gomega.Expect(err).To(gomega.BeNil())
 	})
 }
@@ -15,7 +15,7 @@
 // specific language governing permissions and limitations
 // under the License.
 
-package tcp_test
+package specs
 
 import (
 	iggcon "github.com/apache/messenger/foreign/go/contracts"
@@ -27,10 +27,10 @@ var _ = ginkgo.Describe("GET CONSUMER OFFSET:", func() {
 	prefix := "GetConsumerOffset"
 
 	ginkgo.When("User is logged in", func() {
-		ginkgo.Context("and tries to get offset for existing consumer group", func() {
+		ginkgo.It("and tries to get offset for existing consumer group", func() {
 			client := createAuthorizedConnection()
 			streamId, _ := successfullyCreateStream(prefix, client)
-			defer deleteStreamAfterTests(streamId, client)
+			ginkgo.DeferCleanup(deleteStreamAfterTests, streamId, client)
 			topicId, _ := successfullyCreateTopic(streamId, client)
 			groupId, _ := successfullyCreateConsumer(streamId, topicId, client)
 
@@ -59,10 +59,10 @@ var _ = ginkgo.Describe("GET CONSUMER OFFSET:", func() {
 			itShouldReturnNilOffsetForNewConsumerGroup(offset)
 		})
 
-		ginkgo.Context("and gets valid offset after sending messages and storing offset", func() {
+		ginkgo.It("and gets valid offset after sending messages and storing offset", func() {
 			client := createAuthorizedConnection()
 			streamId, _ := successfullyCreateStream(prefix+"Success", client)
-			defer deleteStreamAfterTests(streamId, client)
+			ginkgo.DeferCleanup(deleteStreamAfterTests, streamId, client)
 			topicId, _ := successfullyCreateTopic(streamId, client)
 			groupId, _ := successfullyCreateConsumer(streamId, topicId, client)
 
@@ -104,10 +104,10 @@ var _ = ginkgo.Describe("GET CONSUMER OFFSET:", func() {
 			itShouldReturnStoredConsumerOffset(offset, partitionId, testOffset)
 		})
 
-		ginkgo.Context("and tries to store and retrieve consumer offset", func() {
+		ginkgo.It("and tries to store and retrieve consumer offset", func() {
 			client := createAuthorizedConnection()
 			streamId, _ := successfullyCreateStream(prefix+"Store", client)
-			defer deleteStreamAfterTests(streamId, client)
+			ginkgo.DeferCleanup(deleteStreamAfterTests, streamId, client)
 			topicId, _ := successfullyCreateTopic(streamId, client)
 			groupId, _ := successfullyCreateConsumer(streamId, topicId, client)
 
@@ -138,10 +138,10 @@ var _ = ginkgo.Describe("GET CONSUMER OFFSET:", func() {
 			itShouldReturnNilOffsetForNewConsumerGroup(storedOffset)
 		})
 
-		ginkgo.Context("and tries to get offset from non-existing consumer group", func() {
+		ginkgo.It("and tries to get offset from non-existing consumer group", func() {
 			client := createAuthorizedConnection()
 			streamId, _ := successfullyCreateStream(prefix, client)
-			defer deleteStreamAfterTests(streamId, client)
+			ginkgo.DeferCleanup(deleteStreamAfterTests, streamId, client)
 			topicId, _ := successfullyCreateTopic(streamId, client)
 
 			streamIdentifier, _ := iggcon.NewIdentifier(streamId)
@@ -160,7 +160,7 @@ var _ = ginkgo.Describe("GET CONSUMER OFFSET:", func() {
 			itShouldReturnNilOffsetForNewConsumerGroup(offset)
 		})
 
-		ginkgo.Context("and tries to get offset from non-existing stream", func() {
+		ginkgo.It("and tries to get offset from non-existing stream", func() {
 			client := createAuthorizedConnection()
 			consumer := iggcon.NewGroupConsumer(randomU32Identifier())
 			partitionId := uint32(1)
@@ -178,7 +178,7 @@ var _ = ginkgo.Describe("GET CONSUMER OFFSET:", func() {
 	})
 
 	ginkgo.When("User is not logged in", func() {
-		ginkgo.Context("and tries to get consumer offset", func() {
+		ginkgo.It("and tries to get consumer offset", func() {
 			client := createClient()
 			consumer := iggcon.NewGroupConsumer(randomU32Identifier())
 			partitionId := uint32(1)
@@ -197,13 +197,13 @@ var _ = ginkgo.Describe("GET CONSUMER OFFSET:", func() {
 })
 
 func itShouldReturnNilOffsetForNewConsumerGroup(offset *iggcon.ConsumerOffsetInfo) {
-	ginkgo.It("should return nil offset for new consumer group with no stored offset", func() {
+	it("should return nil offset for new consumer group with no stored offset", func() {
 		gomega.Expect(offset).To(gomega.BeNil(), "Offset should be nil for new consumer group")
 	})
 }
 
 func itShouldReturnStoredConsumerOffset(offset *iggcon.ConsumerOffsetInfo, expectedPartitionId uint32, expectedStoredOffset uint64) {
-	ginkgo.It("should return the stored consumer offset", func() {
+	it("should return the stored consumer offset", func() {
 		gomega.Expect(offset).NotTo(gomega.BeNil(), "Offset should not be nil")
 		gomega.Expect(offset.PartitionId).To(gomega.Equal(expectedPartitionId), "PartitionId should match")
 		gomega.Expect(offset.StoredOffset).To(gomega.Equal(expectedStoredOffset), "StoredOffset should match the value we set")
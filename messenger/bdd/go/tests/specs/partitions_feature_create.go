@@ -15,7 +15,7 @@
 // specific language governing permissions and limitations
 // under the License.
 
-package tcp_test
+package specs
 
 import (
 	iggcon "github.com/apache/messenger/foreign/go/contracts"
@@ -25,10 +25,10 @@ import (
 var _ = ginkgo.Describe("CREATE PARTITION:", func() {
 	prefix := "CreatePartitions"
 	ginkgo.When("User is logged in", func() {
-		ginkgo.Context("and tries to create partitions for existing stream", func() {
+		ginkgo.It("and tries to create partitions for existing stream", func() {
 			client := createAuthorizedConnection()
 			streamId, _ := successfullyCreateStream(prefix, client)
-			defer deleteStreamAfterTests(streamId, client)
+			ginkgo.DeferCleanup(deleteStreamAfterTests, streamId, client)
 			topicId, _ := successfullyCreateTopic(streamId, client)
 			streamIdentifier, _ := iggcon.NewIdentifier(streamId)
 			topicIdentifier, _ := iggcon.NewIdentifier(topicId)
@@ -43,7 +43,7 @@ var _ = ginkgo.Describe("CREATE PARTITION:", func() {
 			itShouldHaveExpectedNumberOfPartitions(streamId, topicId, partitionsCount+2, client)
 		})
 
-		ginkgo.Context("and tries to create partitions for a non existing stream", func() {
+		ginkgo.It("and tries to create partitions for a non existing stream", func() {
 			client := createAuthorizedConnection()
 			err := client.CreatePartitions(
 				randomU32Identifier(),
@@ -54,10 +54,10 @@ var _ = ginkgo.Describe("CREATE PARTITION:", func() {
 			itShouldReturnSpecificError(err, "stream_id_not_found")
 		})
 
-		ginkgo.Context("and tries to create partitions for a non existing topic", func() {
+		ginkgo.It("and tries to create partitions for a non existing topic", func() {
 			client := createAuthorizedConnection()
 			streamId, _ := successfullyCreateStream(prefix, client)
-			defer deleteStreamAfterTests(streamId, client)
+			ginkgo.DeferCleanup(deleteStreamAfterTests, streamId, client)
 			streamIdentifier, _ := iggcon.NewIdentifier(streamId)
 			err := client.CreatePartitions(
 				streamIdentifier,
@@ -70,7 +70,7 @@ var _ = ginkgo.Describe("CREATE PARTITION:", func() {
 	})
 
 	ginkgo.When("User is not logged in", func() {
-		ginkgo.Context("and tries to create partitions", func() {
+		ginkgo.It("and tries to create partitions", func() {
 			client := createClient()
 			err := client.CreatePartitions(
 				randomU32Identifier(),
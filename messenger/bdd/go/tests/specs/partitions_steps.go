@@ -15,12 +15,11 @@
 // specific language governing permissions and limitations
 // under the License.
 
-package tcp_test
+package specs
 
 import (
 	iggcon "github.com/apache/messenger/foreign/go/contracts"
 	"github.com/apache/messenger/foreign/go/messengercli"
-	"github.com/onsi/ginkgo/v2"
 	"github.com/onsi/gomega"
 )
 
@@ -29,7 +28,7 @@ func itShouldHaveExpectedNumberOfPartitions(streamId uint32, topicId uint32, exp
 	topicIdentifier, _ := iggcon.NewIdentifier(topicId)
 	topic, err := client.GetTopic(streamIdentifier, topicIdentifier)
 
-	ginkgo.It("should have "+string(rune(expectedPartitions))+" partitions", func() {
+	it("should have "+string(rune(expectedPartitions))+" partitions", func() {
 		gomega.Expect(topic).NotTo(gomega.BeNil())
 		gomega.Expect(topic.PartitionsCount).To(gomega.Equal(expectedPartitions))
 		gomega.Expect(len(topic.Partitions)).To(gomega.Equal(int(expectedPartitions)))
@@ -15,7 +15,7 @@
 // specific language governing permissions and limitations
 // under the License.
 
-package tcp_test
+package specs
 
 import (
 	"github.com/onsi/ginkgo/v2"
@@ -23,7 +23,7 @@ import (
 
 var _ = ginkgo.Describe("CREATE PAT:", func() {
 	ginkgo.When("User is logged in", func() {
-		ginkgo.Context("tries to create PAT with correct data", func() {
+		ginkgo.It("tries to create PAT with correct data", func() {
 			client := createAuthorizedConnection()
 			name := createRandomString(16)
 			response, err := client.CreatePersonalAccessToken(name, 0)
@@ -35,7 +35,7 @@ var _ = ginkgo.Describe("CREATE PAT:", func() {
 	})
 
 	ginkgo.When("User is not logged in", func() {
-		ginkgo.Context("and tries to create PAT", func() {
+		ginkgo.It("and tries to create PAT", func() {
 			client := createClient()
 			_, err := client.CreatePersonalAccessToken(createRandomString(16), 0)
 			itShouldReturnUnauthenticatedError(err)
@@ -15,7 +15,7 @@
 // specific language governing permissions and limitations
 // under the License.
 
-package tcp_test
+package specs
 
 import (
 	"github.com/onsi/ginkgo/v2"
@@ -23,7 +23,7 @@ import (
 
 var _ = ginkgo.Describe("DELETE PAT:", func() {
 	ginkgo.When("User is logged in", func() {
-		ginkgo.Context("tries to delete PAT with correct data", func() {
+		ginkgo.It("tries to delete PAT with correct data", func() {
 			client := createAuthorizedConnection()
 			name := createRandomString(16)
 			token := successfullyCreateAccessToken(name, client)
@@ -36,7 +36,7 @@ var _ = ginkgo.Describe("DELETE PAT:", func() {
 	})
 
 	ginkgo.When("User is not logged in", func() {
-		ginkgo.Context("and tries to delete PAT", func() {
+		ginkgo.It("and tries to delete PAT", func() {
 			client := createClient()
 			err := client.DeletePersonalAccessToken(createRandomString(16))
 			itShouldReturnUnauthenticatedError(err)
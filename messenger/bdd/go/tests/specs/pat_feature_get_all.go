@@ -15,7 +15,7 @@
 // specific language governing permissions and limitations
 // under the License.
 
-package tcp_test
+package specs
 
 import (
 	"github.com/onsi/ginkgo/v2"
@@ -23,7 +23,7 @@ import (
 
 var _ = ginkgo.Describe("GET PAT:", func() {
 	ginkgo.When("User is logged in", func() {
-		ginkgo.Context("tries to get all PATs", func() {
+		ginkgo.It("tries to get all PATs", func() {
 			client := createAuthorizedConnection()
 			name := createRandomString(16)
 			successfullyCreateAccessToken(name, client)
@@ -36,7 +36,7 @@ var _ = ginkgo.Describe("GET PAT:", func() {
 	})
 
 	ginkgo.When("User is not logged in", func() {
-		ginkgo.Context("and tries to all get PAT's", func() {
+		ginkgo.It("and tries to all get PAT's", func() {
 			client := createClient()
 			_, err := client.GetPersonalAccessTokens()
 			itShouldReturnUnauthenticatedError(err)
@@ -15,12 +15,11 @@
 // specific language governing permissions and limitations
 // under the License.
 
-package tcp_test
+package specs
 
 import (
 	iggcon "github.com/apache/messenger/foreign/go/contracts"
 	"github.com/apache/messenger/foreign/go/messengercli"
-	"github.com/onsi/ginkgo/v2"
 	"github.com/onsi/gomega"
 )
 
@@ -54,7 +53,7 @@ func itShouldSuccessfullyDeleteAccessToken(name string, client messengercli.Clie
 		}
 	}
 
-	ginkgo.It("should not fetch token with name "+name, func() {
+	it("should not fetch token with name "+name, func() {
 		gomega.Expect(found).To(gomega.BeFalse(), "Token with name %s exists", name)
 	})
 }
@@ -64,13 +63,13 @@ func itShouldBePossibleToLogInWithAccessToken(token string) {
 	userId, err := ms.LoginWithPersonalAccessToken(token)
 
 	itShouldNotReturnError(err)
-	ginkgo.It("should return userId", func() {
+	it("should return userId", func() {
 		gomega.Expect(userId).NotTo(gomega.BeNil())
 	})
 }
 
 func itShouldContainSpecificAccessToken(name string, tokens []iggcon.PersonalAccessTokenInfo) {
-	ginkgo.It("should fetch at least one user", func() {
+	it("should fetch at least one user", func() {
 		gomega.Expect(len(tokens)).NotTo(gomega.Equal(0))
 	})
 
@@ -85,7 +84,7 @@ func itShouldContainSpecificAccessToken(name string, tokens []iggcon.PersonalAcc
 		}
 	}
 
-	ginkgo.It("should fetch token with name "+name, func() {
+	it("should fetch token with name "+name, func() {
 		gomega.Expect(found).To(gomega.BeTrue(), "Token with name %s not found", name)
 		gomega.Expect(token.Name).To(gomega.Equal(name))
 	})
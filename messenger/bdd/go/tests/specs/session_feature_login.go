@@ -15,7 +15,7 @@
 // specific language governing permissions and limitations
 // under the License.
 
-package tcp_test
+package specs
 
 import (
 	iggcon "github.com/apache/messenger/foreign/go/contracts"
@@ -25,7 +25,7 @@ import (
 
 var _ = ginkgo.Describe("LOGIN FEATURE:", func() {
 	ginkgo.When("user is already logged in", func() {
-		ginkgo.Context("and tries to log with correct data", func() {
+		ginkgo.It("and tries to log with correct data", func() {
 			client := createAuthorizedConnection()
 			user, err := client.LoginUser("messenger", "messenger")
 
@@ -33,7 +33,7 @@ var _ = ginkgo.Describe("LOGIN FEATURE:", func() {
 			itShouldReturnUserId(user, 1)
 		})
 
-		ginkgo.Context("and tries to log with invalid credentials", func() {
+		ginkgo.It("and tries to log with invalid credentials", func() {
 			client := createAuthorizedConnection()
 			user, err := client.LoginUser("incorrect", "random")
 
@@ -43,7 +43,7 @@ var _ = ginkgo.Describe("LOGIN FEATURE:", func() {
 	})
 
 	ginkgo.When("user is not logged in", func() {
-		ginkgo.Context("and tries to log with correct data", func() {
+		ginkgo.It("and tries to log with correct data", func() {
 			client := createClient()
 			user, err := client.LoginUser("messenger", "messenger")
 
@@ -51,7 +51,7 @@ var _ = ginkgo.Describe("LOGIN FEATURE:", func() {
 			itShouldReturnUserId(user, 1)
 		})
 
-		ginkgo.Context("and tries to log with invalid credentials", func() {
+		ginkgo.It("and tries to log with invalid credentials", func() {
 			client := createClient()
 			user, err := client.LoginUser("incorrect", "random")
 
@@ -62,13 +62,13 @@ var _ = ginkgo.Describe("LOGIN FEATURE:", func() {
 })
 
 func itShouldReturnUserId(user *iggcon.IdentityInfo, id uint32) {
-	ginkgo.It("should return user id", func() {
+	it("should return user id", func() {
 		gomega.Expect(user.UserId).To(gomega.Equal(id))
 	})
 }
 
 func itShouldNotReturnUser(user *iggcon.IdentityInfo) {
-	ginkgo.It("should return user id", func() {
+	it("should return user id", func() {
 		gomega.Expect(user).To(gomega.BeNil())
 	})
 }
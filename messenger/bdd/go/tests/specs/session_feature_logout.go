@@ -15,7 +15,7 @@
 // specific language governing permissions and limitations
 // under the License.
 
-package tcp_test
+package specs
 
 import (
 	"github.com/onsi/ginkgo/v2"
@@ -23,7 +23,7 @@ import (
 
 var _ = ginkgo.Describe("LOGOUT FEATURE:", func() {
 	ginkgo.When("User is logged in", func() {
-		ginkgo.Context("and tries to log out", func() {
+		ginkgo.It("and tries to log out", func() {
 			client := createAuthorizedConnection()
 			err := client.LogoutUser()
 
@@ -32,7 +32,7 @@ var _ = ginkgo.Describe("LOGOUT FEATURE:", func() {
 	})
 
 	ginkgo.When("User is not logged in", func() {
-		ginkgo.Context("and tries to log out", func() {
+		ginkgo.It("and tries to log out", func() {
 			client := createClient()
 			err := client.LogoutUser()
 
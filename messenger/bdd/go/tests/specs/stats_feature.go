@@ -15,7 +15,7 @@
 // specific language governing permissions and limitations
 // under the License.
 
-package tcp_test
+package specs
 
 import (
 	"github.com/onsi/ginkgo/v2"
@@ -24,12 +24,12 @@ import (
 
 var _ = ginkgo.Describe("STAT FEATURE:", func() {
 	ginkgo.When("user is logged in", func() {
-		ginkgo.Context("and tries to log with correct data", func() {
+		ginkgo.It("and tries to log with correct data", func() {
 			client := createAuthorizedConnection()
 			stats, err := client.GetStats()
 
 			itShouldNotReturnError(err)
-			ginkgo.It("should return stats", func() {
+			it("should return stats", func() {
 				gomega.Expect(stats).ToNot(gomega.BeNil())
 			})
 		})
@@ -15,7 +15,7 @@
 // specific language governing permissions and limitations
 // under the License.
 
-package tcp_test
+package specs
 
 import (
 	"github.com/onsi/ginkgo/v2"
@@ -23,25 +23,25 @@ import (
 
 var _ = ginkgo.Describe("CREATE STREAM:", func() {
 	ginkgo.When("User is logged in", func() {
-		ginkgo.Context("and tries to create stream with unique name and id", func() {
+		ginkgo.It("and tries to create stream with unique name and id", func() {
 			client := createAuthorizedConnection()
 			streamId := createRandomUInt32()
 			name := createRandomString(32)
 
 			_, err := client.CreateStream(name, &streamId)
-			defer deleteStreamAfterTests(streamId, client)
+			ginkgo.DeferCleanup(deleteStreamAfterTests, streamId, client)
 
 			itShouldNotReturnError(err)
 			itShouldSuccessfullyCreateStream(streamId, name, client)
 		})
 
-		ginkgo.Context("and tries to create stream with duplicate stream name", func() {
+		ginkgo.It("and tries to create stream with duplicate stream name", func() {
 			client := createAuthorizedConnection()
 			streamId := createRandomUInt32()
 			name := createRandomString(32)
 
 			_, err := client.CreateStream(name, &streamId)
-			defer deleteStreamAfterTests(streamId, client)
+			ginkgo.DeferCleanup(deleteStreamAfterTests, streamId, client)
 
 			itShouldNotReturnError(err)
 			itShouldSuccessfullyCreateStream(streamId, name, client)
@@ -52,13 +52,13 @@ var _ = ginkgo.Describe("CREATE STREAM:", func() {
 			itShouldReturnSpecificError(err, "stream_name_already_exists")
 		})
 
-		ginkgo.Context("and tries to create stream with duplicate stream id", func() {
+		ginkgo.It("and tries to create stream with duplicate stream id", func() {
 			client := createAuthorizedConnection()
 			streamId := createRandomUInt32()
 			name := createRandomString(32)
 
 			_, err := client.CreateStream(name, &streamId)
-			defer deleteStreamAfterTests(streamId, client)
+			ginkgo.DeferCleanup(deleteStreamAfterTests, streamId, client)
 
 			itShouldNotReturnError(err)
 			itShouldSuccessfullyCreateStream(streamId, name, client)
@@ -68,7 +68,7 @@ var _ = ginkgo.Describe("CREATE STREAM:", func() {
 			itShouldReturnSpecificError(err, "stream_id_already_exists")
 		})
 
-		ginkgo.Context("and tries to create stream name that's over 255 characters", func() {
+		ginkgo.It("and tries to create stream name that's over 255 characters", func() {
 			client := createAuthorizedConnection()
 			streamId := createRandomUInt32()
 			name := createRandomString(256)
@@ -80,7 +80,7 @@ var _ = ginkgo.Describe("CREATE STREAM:", func() {
 	})
 
 	ginkgo.When("User is not logged in", func() {
-		ginkgo.Context("and tries to create stream", func() {
+		ginkgo.It("and tries to create stream", func() {
 			client := createClient()
 			streamId := createRandomUInt32()
 			_, err := client.CreateStream(createRandomString(32), &streamId)
@@ -15,7 +15,7 @@
 // specific language governing permissions and limitations
 // under the License.
 
-package tcp_test
+package specs
 
 import (
 	iggcon "github.com/apache/messenger/foreign/go/contracts"
@@ -25,7 +25,7 @@ import (
 var _ = ginkgo.Describe("DELETE STREAM:", func() {
 	prefix := "DeleteStream"
 	ginkgo.When("User is logged in", func() {
-		ginkgo.Context("and tries to delete existing stream", func() {
+		ginkgo.It("and tries to delete existing stream", func() {
 			client := createAuthorizedConnection()
 			streamId, _ := successfullyCreateStream(prefix, client)
 			streamIdentifier, _ := iggcon.NewIdentifier(streamId)
@@ -35,7 +35,7 @@ var _ = ginkgo.Describe("DELETE STREAM:", func() {
 			itShouldSuccessfullyDeleteStream(streamId, client)
 		})
 
-		ginkgo.Context("and tries to delete non-existing stream", func() {
+		ginkgo.It("and tries to delete non-existing stream", func() {
 			client := createAuthorizedConnection()
 
 			err := client.DeleteStream(randomU32Identifier())
@@ -45,7 +45,7 @@ var _ = ginkgo.Describe("DELETE STREAM:", func() {
 	})
 
 	ginkgo.When("User is not logged in", func() {
-		ginkgo.Context("and tries to delete stream", func() {
+		ginkgo.It("and tries to delete stream", func() {
 			client := createClient()
 			err := client.DeleteStream(randomU32Identifier())
 
@@ -15,7 +15,7 @@
 // specific language governing permissions and limitations
 // under the License.
 
-package tcp_test
+package specs
 
 import (
 	"github.com/onsi/ginkgo/v2"
@@ -24,10 +24,10 @@ import (
 var _ = ginkgo.Describe("GET ALL STREAMS:", func() {
 	prefix := "GetAllStreams"
 	ginkgo.When("User is logged in", func() {
-		ginkgo.Context("and tries to get all streams", func() {
+		ginkgo.It("and tries to get all streams", func() {
 			client := createAuthorizedConnection()
 			streamId, name := successfullyCreateStream(prefix, client)
-			defer deleteStreamAfterTests(streamId, client)
+			ginkgo.DeferCleanup(deleteStreamAfterTests, streamId, client)
 			streams, err := client.GetStreams()
 
 			itShouldNotReturnError(err)
@@ -36,7 +36,7 @@ var _ = ginkgo.Describe("GET ALL STREAMS:", func() {
 	})
 
 	ginkgo.When("User is not logged in", func() {
-		ginkgo.Context("and tries to get all streams", func() {
+		ginkgo.It("and tries to get all streams", func() {
 			client := createClient()
 			_, err := client.GetStreams()
 
@@ -15,22 +15,22 @@
 // specific language governing permissions and limitations
 // under the License.
 
-package tcp_test
+package specs
 
 import (
 	iggcon "github.com/apache/messenger/foreign/go/contracts"
 	"github.com/onsi/ginkgo/v2"
 	"github.com/onsi/gomega"
-	"math"
+	"time"
 )
 
 var _ = ginkgo.Describe("GET STREAM BY ID:", func() {
 	prefix := "GetStream"
 	ginkgo.When("User is logged in", func() {
-		ginkgo.Context("and tries to get existing stream", func() {
+		ginkgo.It("and tries to get existing stream", func() {
 			client := createAuthorizedConnection()
 			streamId, name := successfullyCreateStream(prefix, client)
-			defer deleteStreamAfterTests(streamId, client)
+			ginkgo.DeferCleanup(deleteStreamAfterTests, streamId, client)
 			streamIdentifier, _ := iggcon.NewIdentifier(streamId)
 			stream, err := client.GetStream(streamIdentifier)
 
@@ -38,7 +38,7 @@ var _ = ginkgo.Describe("GET STREAM BY ID:", func() {
 			itShouldReturnSpecificStream(streamId, name, *stream)
 		})
 
-		ginkgo.Context("and tries to get non-existing stream", func() {
+		ginkgo.It("and tries to get non-existing stream", func() {
 			client := createAuthorizedConnection()
 
 			_, err := client.GetStream(randomU32Identifier())
@@ -46,10 +46,10 @@ var _ = ginkgo.Describe("GET STREAM BY ID:", func() {
 			itShouldReturnSpecificError(err, "stream_id_not_found")
 		})
 
-		ginkgo.Context("and tries to get stream after creating some topics", func() {
+		ginkgo.It("and tries to get stream after creating some topics", func() {
 			client := createAuthorizedConnection()
 			streamId, name := successfullyCreateStream(prefix, client)
-			defer deleteStreamAfterTests(streamId, client)
+			ginkgo.DeferCleanup(deleteStreamAfterTests, streamId, client)
 			streamIdentifier, _ := iggcon.NewIdentifier(streamId)
 
 			// create two topics
@@ -61,8 +61,8 @@ var _ = ginkgo.Describe("GET STREAM BY ID:", func() {
 				t1Name,
 				2,
 				iggcon.CompressionAlgorithmNone,
-				iggcon.Millisecond,
-				math.MaxUint64,
+				iggcon.ExpiryAfter(time.Millisecond),
+				iggcon.MaxTopicSizeUnlimited,
 				nil,
 				&t1Id)
 			itShouldNotReturnError(err)
@@ -71,8 +71,8 @@ var _ = ginkgo.Describe("GET STREAM BY ID:", func() {
 				t2Name,
 				2,
 				iggcon.CompressionAlgorithmNone,
-				iggcon.Millisecond,
-				math.MaxUint64,
+				iggcon.ExpiryAfter(time.Millisecond),
+				iggcon.MaxTopicSizeUnlimited,
 				nil,
 				&t2Id)
 			itShouldNotReturnError(err)
@@ -83,7 +83,7 @@ var _ = ginkgo.Describe("GET STREAM BY ID:", func() {
 			stream, err := client.GetStream(streamIdentifier)
 			itShouldNotReturnError(err)
 			itShouldReturnSpecificStream(streamId, name, *stream)
-			ginkgo.It("should have exactly 2 topics", func() {
+			it("should have exactly 2 topics", func() {
 				gomega.Expect(len(stream.Topics)).To(gomega.Equal(2))
 			})
 			itShouldContainSpecificTopic(t1Id, t1Name, stream.Topics)
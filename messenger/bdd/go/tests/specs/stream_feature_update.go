@@ -15,7 +15,7 @@
 // specific language governing permissions and limitations
 // under the License.
 
-package tcp_test
+package specs
 
 import (
 	iggcon "github.com/apache/messenger/foreign/go/contracts"
@@ -25,10 +25,10 @@ import (
 var _ = ginkgo.Describe("UPDATE STREAM:", func() {
 	prefix := "UpdateStream"
 	ginkgo.When("User is logged in", func() {
-		ginkgo.Context("and tries to update existing stream with a valid name", func() {
+		ginkgo.It("and tries to update existing stream with a valid name", func() {
 			client := createAuthorizedConnection()
 			streamId, _ := successfullyCreateStream(prefix, client)
-			defer deleteStreamAfterTests(streamId, client)
+			ginkgo.DeferCleanup(deleteStreamAfterTests, streamId, client)
 			newName := createRandomString(128)
 			streamIdentifier, _ := iggcon.NewIdentifier(streamId)
 			err := client.UpdateStream(streamIdentifier, newName)
@@ -36,12 +36,12 @@ var _ = ginkgo.Describe("UPDATE STREAM:", func() {
 			itShouldSuccessfullyUpdateStream(streamId, newName, client)
 		})
 
-		ginkgo.Context("and tries to update stream with duplicate stream name", func() {
+		ginkgo.It("and tries to update stream with duplicate stream name", func() {
 			client := createAuthorizedConnection()
 			stream1Id, stream1Name := successfullyCreateStream(prefix, client)
 			stream2Id, _ := successfullyCreateStream(prefix, client)
-			defer deleteStreamAfterTests(stream1Id, client)
-			defer deleteStreamAfterTests(stream2Id, client)
+			ginkgo.DeferCleanup(deleteStreamAfterTests, stream1Id, client)
+			ginkgo.DeferCleanup(deleteStreamAfterTests, stream2Id, client)
 
 			stream2Identifier, _ := iggcon.NewIdentifier(stream2Id)
 			err := client.UpdateStream(stream2Identifier, stream1Name)
@@ -49,17 +49,17 @@ var _ = ginkgo.Describe("UPDATE STREAM:", func() {
 			itShouldReturnSpecificError(err, "stream_name_already_exists")
 		})
 
-		ginkgo.Context("and tries to update non-existing stream", func() {
+		ginkgo.It("and tries to update non-existing stream", func() {
 			client := createAuthorizedConnection()
 			err := client.UpdateStream(randomU32Identifier(), createRandomString(128))
 
 			itShouldReturnSpecificError(err, "stream_id_not_found")
 		})
 
-		ginkgo.Context("and tries to update existing stream with a name that's over 255 characters", func() {
+		ginkgo.It("and tries to update existing stream with a name that's over 255 characters", func() {
 			client := createAuthorizedConnection()
 			streamId, _ := successfullyCreateStream(prefix, client)
-			defer deleteStreamAfterTests(streamId, createAuthorizedConnection())
+			ginkgo.DeferCleanup(deleteStreamAfterTests, streamId, createAuthorizedConnection())
 			streamIdentifier, _ := iggcon.NewIdentifier(streamId)
 			err := client.UpdateStream(streamIdentifier, createRandomString(256))
 
@@ -68,7 +68,7 @@ var _ = ginkgo.Describe("UPDATE STREAM:", func() {
 	})
 
 	ginkgo.When("User is not logged in", func() {
-		ginkgo.Context("and tries to update stream", func() {
+		ginkgo.It("and tries to update stream", func() {
 			client := createClient()
 			err := client.UpdateStream(randomU32Identifier(), createRandomString(128))
 
@@ -15,14 +15,13 @@
 // specific language governing permissions and limitations
 // under the License.
 
-package tcp_test
+package specs
 
 import (
 	"fmt"
 	iggcon "github.com/apache/messenger/foreign/go/contracts"
 	ierror "github.com/apache/messenger/foreign/go/errors"
 	"github.com/apache/messenger/foreign/go/messengercli"
-	"github.com/onsi/ginkgo/v2"
 	"github.com/onsi/gomega"
 )
 
@@ -42,17 +41,17 @@ func successfullyCreateStream(prefix string, client messengercli.Client) (uint32
 //assertions
 
 func itShouldReturnSpecificStream(id uint32, name string, stream iggcon.StreamDetails) {
-	ginkgo.It("should fetch stream with id "+string(rune(id)), func() {
+	it("should fetch stream with id "+string(rune(id)), func() {
 		gomega.Expect(stream.Id).To(gomega.Equal(id))
 	})
 
-	ginkgo.It("should fetch stream with name "+name, func() {
+	it("should fetch stream with name "+name, func() {
 		gomega.Expect(stream.Name).To(gomega.Equal(name))
 	})
 }
 
 func itShouldContainSpecificStream(id uint32, name string, streams []iggcon.Stream) {
-	ginkgo.It("should fetch at least one stream", func() {
+	it("should fetch at least one stream", func() {
 		gomega.Expect(len(streams)).NotTo(gomega.Equal(0))
 	})
 
@@ -67,12 +66,12 @@ func itShouldContainSpecificStream(id uint32, name string, streams []iggcon.Stre
 		}
 	}
 
-	ginkgo.It(fmt.Sprintf("should fetch stream with id %d", id), func() {
+	it(fmt.Sprintf("should fetch stream with id %d", id), func() {
 		gomega.Expect(found).To(gomega.BeTrue(), "Stream with id %d and name %s not found", id, name)
 		gomega.Expect(stream.Id).To(gomega.Equal(id))
 	})
 
-	ginkgo.It("should fetch stream with name "+name, func() {
+	it("should fetch stream with name "+name, func() {
 		gomega.Expect(found).To(gomega.BeTrue(), "Stream with id %d and name %s not found", id, name)
 		gomega.Expect(stream.Name).To(gomega.Equal(name))
 	})
@@ -83,11 +82,11 @@ func itShouldSuccessfullyCreateStream(id uint32, expectedName string, client mes
 	stream, err := client.GetStream(streamIdentifier)
 
 	itShouldNotReturnError(err)
-	ginkgo.It("should create stream with id "+string(rune(id)), func() {
+	it("should create stream with id "+string(rune(id)), func() {
 		gomega.Expect(stream.Id).To(gomega.Equal(id))
 	})
 
-	ginkgo.It("should create stream with name "+expectedName, func() {
+	it("should create stream with name "+expectedName, func() {
 		gomega.Expect(stream.Name).To(gomega.Equal(expectedName))
 	})
 }
@@ -97,11 +96,11 @@ func itShouldSuccessfullyUpdateStream(id uint32, expectedName string, client mes
 	stream, err := client.GetStream(streamIdentifier)
 
 	itShouldNotReturnError(err)
-	ginkgo.It("should update stream with id "+string(rune(id)), func() {
+	it("should update stream with id "+string(rune(id)), func() {
 		gomega.Expect(stream.Id).To(gomega.Equal(id))
 	})
 
-	ginkgo.It("should update stream with name "+expectedName, func() {
+	it("should update stream with name "+expectedName, func() {
 		gomega.Expect(stream.Name).To(gomega.Equal(expectedName))
 	})
 }
@@ -111,7 +110,7 @@ func itShouldSuccessfullyDeleteStream(id uint32, client messengercli.Client) {
 	stream, err := client.GetStream(streamIdentifier)
 
 	itShouldReturnSpecificMessengerError(err, ierror.StreamIdNotFound)
-	ginkgo.It("should not return stream", func() {
+	it("should not return stream", func() {
 		gomega.Expect(stream).To(gomega.BeNil())
 	})
 }
@@ -15,17 +15,16 @@
 // specific language governing permissions and limitations
 // under the License.
 
-package tcp_test
+package specs
 
 import (
-	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"fmt"
 	"math/rand"
-	"os"
 	"strings"
-	"time"
 
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
 	"github.com/apache/messenger/foreign/go/messengercli"
-	"github.com/apache/messenger/foreign/go/tcp"
+	"github.com/onsi/ginkgo/v2"
 )
 
 func createAuthorizedConnection() messengercli.Client {
@@ -38,15 +37,10 @@ func createAuthorizedConnection() messengercli.Client {
 }
 
 func createClient() messengercli.Client {
-	addr := os.Getenv("MESSENGER_TCP_ADDRESS")
-	if addr == "" {
-		addr = "127.0.0.1:8090"
+	if transport.NewClient == nil {
+		panic("specs: no transport selected, call specs.Use before running the specs")
 	}
-	cli, err := messengercli.NewMessengerClient(
-		messengercli.WithTcp(
-			tcp.WithServerAddress(addr),
-		),
-	)
+	cli, err := transport.NewClient()
 	if err != nil {
 		panic(err)
 	}
@@ -54,10 +48,9 @@ func createClient() messengercli.Client {
 }
 
 func createRandomUInt32() uint32 {
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
 	var v uint32
 	for v == 0 {
-		v = r.Uint32()
+		v = rand.Uint32()
 	}
 	return v
 }
@@ -71,9 +64,6 @@ func createRandomString(length int) string {
 	// Define the character set from which to create the random string
 	const charset = "abcdefghijklmnopqrstuvwxyz0123456789"
 
-	// Initialize the random number generator with a seed based on the current time
-	rand.New(rand.NewSource(time.Now().UnixNano()))
-
 	// Create the random string
 	result := make([]byte, length)
 	for i := range result {
@@ -82,17 +72,12 @@ func createRandomString(length int) string {
 	return string(result)
 }
 
-func createRandomStringWithPrefix(prefix string, length int) string {
-	// Define the character set from which to create the random string
-	const charset = "abcdefghijklmnopqrstuvwxyz0123456789"
-
-	// Initialize the random number generator with a seed based on the current time
-	rand.New(rand.NewSource(time.Now().UnixNano()))
+// namespace prefixes the names of the resources created by a parallel process, so the
+// processes never collide on names.
+func namespace() string {
+	return fmt.Sprintf("p%d", ginkgo.GinkgoParallelProcess())
+}
 
-	// Create the random string
-	result := make([]byte, length-len(prefix))
-	for i := range result {
-		result[i] = charset[rand.Intn(len(charset))]
-	}
-	return strings.ToLower(prefix) + string(result)
+func createRandomStringWithPrefix(prefix string, length int) string {
+	return strings.ToLower(namespace()+prefix) + createRandomString(length-len(namespace())-len(prefix))
 }
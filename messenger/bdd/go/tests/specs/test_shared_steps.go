@@ -15,7 +15,7 @@
 // specific language governing permissions and limitations
 // under the License.
 
-package tcp_test
+package specs
 
 import (
 	ierror "github.com/apache/messenger/foreign/go/errors"
@@ -23,14 +23,22 @@ import (
 	"github.com/onsi/gomega"
 )
 
+// it checks an assertion of the running spec, reported as one of its steps. The scenarios run
+// their operations inside the spec rather than while building the spec tree, so every parallel
+// process only runs its own specs.
+func it(description string, assertion func()) {
+	ginkgo.By(description)
+	assertion()
+}
+
 func itShouldReturnSpecificError(err error, errorMessage string) {
-	ginkgo.It("Should return error: "+errorMessage, func() {
+	it("Should return error: "+errorMessage, func() {
 		gomega.Expect(err.Error()).To(gomega.ContainSubstring(errorMessage))
 	})
 }
 
 func itShouldReturnSpecificMessengerError(err error, messengerError *ierror.MessengerError) {
-	ginkgo.It("Should return error: "+messengerError.Error(), func() {
+	it("Should return error: "+messengerError.Error(), func() {
 		gomega.Expect(err).To(gomega.MatchError(messengerError))
 	})
 }
@@ -40,13 +48,13 @@ func itShouldReturnUnauthenticatedError(err error) {
 }
 
 func itShouldNotReturnError(err error) {
-	ginkgo.It("Should not return error", func() {
+	it("Should not return error", func() {
 		gomega.Expect(err).To(gomega.BeNil())
 	})
 }
 
 func itShouldReturnError(err error) {
-	ginkgo.It("Should return error", func() {
+	it("Should return error", func() {
 		gomega.Expect(err).ToNot(gomega.BeNil())
 	})
 }
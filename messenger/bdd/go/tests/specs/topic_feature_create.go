@@ -15,10 +15,10 @@
 // specific language governing permissions and limitations
 // under the License.
 
-package tcp_test
+package specs
 
 import (
-	"math"
+	"time"
 
 	iggcon "github.com/apache/messenger/foreign/go/contracts"
 	"github.com/onsi/ginkgo/v2"
@@ -27,21 +27,21 @@ import (
 var _ = ginkgo.Describe("CREATE TOPIC:", func() {
 	prefix := "CreateTopic"
 	ginkgo.When("User is logged in", func() {
-		ginkgo.Context("and tries to create topic unique name and id", func() {
+		ginkgo.It("and tries to create topic unique name and id", func() {
 			client := createAuthorizedConnection()
 			streamId, _ := successfullyCreateStream(prefix, client)
 			topicId := uint32(1)
 			replicationFactor := uint8(1)
 			name := createRandomString(32)
-			defer deleteStreamAfterTests(streamId, client)
+			ginkgo.DeferCleanup(deleteStreamAfterTests, streamId, client)
 			streamIdentifier, _ := iggcon.NewIdentifier(streamId)
 			_, err := client.CreateTopic(
 				streamIdentifier,
 				name,
 				2,
 				iggcon.CompressionAlgorithmNone,
-				iggcon.Millisecond,
-				math.MaxUint64,
+				iggcon.ExpiryAfter(time.Millisecond),
+				iggcon.MaxTopicSizeUnlimited,
 				&replicationFactor,
 				&topicId)
 
@@ -49,7 +49,7 @@ var _ = ginkgo.Describe("CREATE TOPIC:", func() {
 			itShouldSuccessfullyCreateTopic(streamId, topicId, name, client)
 		})
 
-		ginkgo.Context("and tries to create topic for a non existing stream", func() {
+		ginkgo.It("and tries to create topic for a non existing stream", func() {
 			client := createAuthorizedConnection()
 			streamId := createRandomUInt32()
 			topicId := uint32(1)
@@ -61,18 +61,18 @@ var _ = ginkgo.Describe("CREATE TOPIC:", func() {
 				name,
 				2,
 				iggcon.CompressionAlgorithmNone,
-				iggcon.Millisecond,
-				math.MaxUint64,
+				iggcon.ExpiryAfter(time.Millisecond),
+				iggcon.MaxTopicSizeUnlimited,
 				&replicationFactor,
 				&topicId)
 
 			itShouldReturnSpecificError(err, "stream_id_not_found")
 		})
 
-		ginkgo.Context("and tries to create topic with duplicate topic name", func() {
+		ginkgo.It("and tries to create topic with duplicate topic name", func() {
 			client := createAuthorizedConnection()
 			streamId, _ := successfullyCreateStream(prefix, client)
-			defer deleteStreamAfterTests(streamId, client)
+			ginkgo.DeferCleanup(deleteStreamAfterTests, streamId, client)
 			_, name := successfullyCreateTopic(streamId, client)
 
 			replicationFactor := uint8(1)
@@ -83,17 +83,17 @@ var _ = ginkgo.Describe("CREATE TOPIC:", func() {
 				name,
 				2,
 				iggcon.CompressionAlgorithmNone,
-				iggcon.MessengerExpiryServerDefault,
-				math.MaxUint64,
+				iggcon.ExpiryServerDefault,
+				iggcon.MaxTopicSizeUnlimited,
 				&replicationFactor,
 				&topicId)
 			itShouldReturnSpecificError(err, "topic_name_already_exists")
 		})
 
-		ginkgo.Context("and tries to create topic with duplicate topic id", func() {
+		ginkgo.It("and tries to create topic with duplicate topic id", func() {
 			client := createAuthorizedConnection()
 			streamId, _ := successfullyCreateStream(prefix, client)
-			defer deleteStreamAfterTests(streamId, client)
+			ginkgo.DeferCleanup(deleteStreamAfterTests, streamId, client)
 			topicId, _ := successfullyCreateTopic(streamId, client)
 			streamIdentifier, _ := iggcon.NewIdentifier(streamId)
 			replicationFactor := uint8(1)
@@ -102,17 +102,17 @@ var _ = ginkgo.Describe("CREATE TOPIC:", func() {
 				createRandomString(32),
 				2,
 				iggcon.CompressionAlgorithmNone,
-				iggcon.MessengerExpiryServerDefault,
-				math.MaxUint64,
+				iggcon.ExpiryServerDefault,
+				iggcon.MaxTopicSizeUnlimited,
 				&replicationFactor,
 				&topicId)
 			itShouldReturnSpecificError(err, "topic_id_already_exists")
 		})
 
-		ginkgo.Context("and tries to create topic with name that's over 255 characters", func() {
+		ginkgo.It("and tries to create topic with name that's over 255 characters", func() {
 			client := createAuthorizedConnection()
 			streamId, _ := successfullyCreateStream(prefix, client)
-			defer deleteStreamAfterTests(streamId, createAuthorizedConnection())
+			ginkgo.DeferCleanup(deleteStreamAfterTests, streamId, createAuthorizedConnection())
 
 			streamIdentifier, _ := iggcon.NewIdentifier(streamId)
 			replicationFactor := uint8(1)
@@ -122,8 +122,8 @@ var _ = ginkgo.Describe("CREATE TOPIC:", func() {
 				createRandomString(256),
 				2,
 				iggcon.CompressionAlgorithmNone,
-				iggcon.MessengerExpiryServerDefault,
-				math.MaxUint64,
+				iggcon.ExpiryServerDefault,
+				iggcon.MaxTopicSizeUnlimited,
 				&replicationFactor,
 				&topicId)
 
@@ -132,7 +132,7 @@ var _ = ginkgo.Describe("CREATE TOPIC:", func() {
 	})
 
 	ginkgo.When("User is not logged in", func() {
-		ginkgo.Context("and tries to create topic", func() {
+		ginkgo.It("and tries to create topic", func() {
 			client := createClient()
 			replicationFactor := uint8(1)
 			topicId := uint32(1)
@@ -142,8 +142,8 @@ var _ = ginkgo.Describe("CREATE TOPIC:", func() {
 				"name",
 				2,
 				iggcon.CompressionAlgorithmNone,
-				iggcon.MessengerExpiryServerDefault,
-				math.MaxUint64,
+				iggcon.ExpiryServerDefault,
+				iggcon.MaxTopicSizeUnlimited,
 				&replicationFactor,
 				&topicId)
 
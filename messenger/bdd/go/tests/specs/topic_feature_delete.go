@@ -15,7 +15,7 @@
 // specific language governing permissions and limitations
 // under the License.
 
-package tcp_test
+package specs
 
 import (
 	iggcon "github.com/apache/messenger/foreign/go/contracts"
@@ -26,10 +26,10 @@ import (
 var _ = ginkgo.Describe("DELETE TOPIC:", func() {
 	prefix := "DeleteTopic"
 	ginkgo.When("User is logged in", func() {
-		ginkgo.Context("and tries to delete existing topic", func() {
+		ginkgo.It("and tries to delete existing topic", func() {
 			client := createAuthorizedConnection()
 			streamId, _ := successfullyCreateStream(prefix, client)
-			defer deleteStreamAfterTests(streamId, client)
+			ginkgo.DeferCleanup(deleteStreamAfterTests, streamId, client)
 			topicId, _ := successfullyCreateTopic(streamId, client)
 			streamIdentifier, _ := iggcon.NewIdentifier(streamId)
 			topicIdentifier, _ := iggcon.NewIdentifier(topicId)
@@ -39,17 +39,17 @@ var _ = ginkgo.Describe("DELETE TOPIC:", func() {
 			itShouldSuccessfullyDeleteTopic(streamId, topicId, client)
 		})
 
-		ginkgo.Context("and tries to delete non-existing topic", func() {
+		ginkgo.It("and tries to delete non-existing topic", func() {
 			client := createAuthorizedConnection()
 			streamId, _ := successfullyCreateStream(prefix, client)
-			defer deleteStreamAfterTests(streamId, client)
+			ginkgo.DeferCleanup(deleteStreamAfterTests, streamId, client)
 			streamIdentifier, _ := iggcon.NewIdentifier(streamId)
 			err := client.DeleteTopic(streamIdentifier, randomU32Identifier())
 
 			itShouldReturnSpecificMessengerError(err, ierror.TopicIdNotFound)
 		})
 
-		ginkgo.Context("and tries to delete non-existing topic and stream", func() {
+		ginkgo.It("and tries to delete non-existing topic and stream", func() {
 			client := createAuthorizedConnection()
 
 			err := client.DeleteTopic(randomU32Identifier(), randomU32Identifier())
@@ -59,7 +59,7 @@ var _ = ginkgo.Describe("DELETE TOPIC:", func() {
 	})
 
 	ginkgo.When("User is not logged in", func() {
-		ginkgo.Context("and tries to delete topic", func() {
+		ginkgo.It("and tries to delete topic", func() {
 			client := createClient()
 			err := client.DeleteTopic(randomU32Identifier(), randomU32Identifier())
 
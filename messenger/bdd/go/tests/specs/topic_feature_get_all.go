@@ -15,7 +15,7 @@
 // specific language governing permissions and limitations
 // under the License.
 
-package tcp_test
+package specs
 
 import (
 	iggcon "github.com/apache/messenger/foreign/go/contracts"
@@ -25,10 +25,10 @@ import (
 var _ = ginkgo.Describe("GET ALL TOPICS:", func() {
 	prefix := "GetAllTopics"
 	ginkgo.When("User is logged in", func() {
-		ginkgo.Context("and tries to get all topics", func() {
+		ginkgo.It("and tries to get all topics", func() {
 			client := createAuthorizedConnection()
 			streamId, _ := successfullyCreateStream(prefix, client)
-			defer deleteStreamAfterTests(streamId, client)
+			ginkgo.DeferCleanup(deleteStreamAfterTests, streamId, client)
 			topicId, name := successfullyCreateTopic(streamId, client)
 			streamIdentifier, _ := iggcon.NewIdentifier(streamId)
 			topics, err := client.GetTopics(streamIdentifier)
@@ -39,7 +39,7 @@ var _ = ginkgo.Describe("GET ALL TOPICS:", func() {
 	})
 
 	ginkgo.When("User is not logged in", func() {
-		ginkgo.Context("and tries to get all topics", func() {
+		ginkgo.It("and tries to get all topics", func() {
 			client := createClient()
 			_, err := client.GetTopics(randomU32Identifier())
 
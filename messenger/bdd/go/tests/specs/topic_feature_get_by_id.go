@@ -15,7 +15,7 @@
 // specific language governing permissions and limitations
 // under the License.
 
-package tcp_test
+package specs
 
 import (
 	iggcon "github.com/apache/messenger/foreign/go/contracts"
@@ -26,10 +26,10 @@ import (
 var _ = ginkgo.Describe("GET TOPIC BY ID:", func() {
 	prefix := "GetTopic"
 	ginkgo.When("User is logged in", func() {
-		ginkgo.Context("and tries to get existing topic", func() {
+		ginkgo.It("and tries to get existing topic", func() {
 			client := createAuthorizedConnection()
 			streamId, _ := successfullyCreateStream(prefix, client)
-			defer deleteStreamAfterTests(streamId, client)
+			ginkgo.DeferCleanup(deleteStreamAfterTests, streamId, client)
 			topicId, name := successfullyCreateTopic(streamId, client)
 			streamIdentifier, _ := iggcon.NewIdentifier(streamId)
 			topicIdentifier, _ := iggcon.NewIdentifier(topicId)
@@ -39,7 +39,7 @@ var _ = ginkgo.Describe("GET TOPIC BY ID:", func() {
 			itShouldReturnSpecificTopic(topicId, name, *topic)
 		})
 
-		ginkgo.Context("and tries to get topic from non-existing stream", func() {
+		ginkgo.It("and tries to get topic from non-existing stream", func() {
 			client := createAuthorizedConnection()
 
 			_, err := client.GetTopic(randomU32Identifier(), randomU32Identifier())
@@ -47,10 +47,10 @@ var _ = ginkgo.Describe("GET TOPIC BY ID:", func() {
 			itShouldReturnSpecificMessengerError(err, ierror.TopicIdNotFound)
 		})
 
-		ginkgo.Context("and tries to get non-existing topic", func() {
+		ginkgo.It("and tries to get non-existing topic", func() {
 			client := createAuthorizedConnection()
 			streamId, _ := successfullyCreateStream(prefix, client)
-			defer deleteStreamAfterTests(streamId, client)
+			ginkgo.DeferCleanup(deleteStreamAfterTests, streamId, client)
 			streamIdentifier, _ := iggcon.NewIdentifier(streamId)
 
 			_, err := client.GetTopic(streamIdentifier, randomU32Identifier())
@@ -15,10 +15,10 @@
 // specific language governing permissions and limitations
 // under the License.
 
-package tcp_test
+package specs
 
 import (
-	"math"
+	"time"
 
 	iggcon "github.com/apache/messenger/foreign/go/contracts"
 	"github.com/onsi/ginkgo/v2"
@@ -27,10 +27,10 @@ import (
 var _ = ginkgo.Describe("UPDATE TOPIC:", func() {
 	prefix := "UpdateTopic"
 	ginkgo.When("User is logged in", func() {
-		ginkgo.Context("and tries to update existing topic with a valid data", func() {
+		ginkgo.It("and tries to update existing topic with a valid data", func() {
 			client := createAuthorizedConnection()
 			streamId, _ := successfullyCreateStream(prefix, client)
-			defer deleteStreamAfterTests(streamId, client)
+			ginkgo.DeferCleanup(deleteStreamAfterTests, streamId, client)
 			topicId, _ := successfullyCreateTopic(streamId, client)
 			newName := createRandomString(128)
 			replicationFactor := uint8(1)
@@ -41,17 +41,17 @@ var _ = ginkgo.Describe("UPDATE TOPIC:", func() {
 				topicIdentifier,
 				newName,
 				iggcon.CompressionAlgorithmNone,
-				iggcon.Microsecond,
-				math.MaxUint64,
+				iggcon.ExpiryAfter(time.Microsecond),
+				iggcon.MaxTopicSizeUnlimited,
 				&replicationFactor)
 			itShouldNotReturnError(err)
 			itShouldSuccessfullyUpdateTopic(streamId, topicId, newName, client)
 		})
 
-		ginkgo.Context("and tries to create topic with duplicate topic name", func() {
+		ginkgo.It("and tries to create topic with duplicate topic name", func() {
 			client := createAuthorizedConnection()
 			streamId, _ := successfullyCreateStream(prefix, client)
-			defer deleteStreamAfterTests(streamId, client)
+			ginkgo.DeferCleanup(deleteStreamAfterTests, streamId, client)
 			_, topic1Name := successfullyCreateTopic(streamId, client)
 			topic2Id, _ := successfullyCreateTopic(streamId, client)
 			replicationFactor := uint8(1)
@@ -62,14 +62,14 @@ var _ = ginkgo.Describe("UPDATE TOPIC:", func() {
 				topic2Identifier,
 				topic1Name,
 				iggcon.CompressionAlgorithmNone,
-				iggcon.MessengerExpiryServerDefault,
-				math.MaxUint64,
+				iggcon.ExpiryServerDefault,
+				iggcon.MaxTopicSizeUnlimited,
 				&replicationFactor)
 
 			itShouldReturnSpecificError(err, "topic_name_already_exists")
 		})
 
-		ginkgo.Context("and tries to update non-existing topic", func() {
+		ginkgo.It("and tries to update non-existing topic", func() {
 			client := createAuthorizedConnection()
 			replicationFactor := uint8(1)
 			err := client.UpdateTopic(
@@ -78,16 +78,16 @@ var _ = ginkgo.Describe("UPDATE TOPIC:", func() {
 				createRandomString(128),
 				1,
 				0,
-				math.MaxUint64,
+				iggcon.MaxTopicSizeUnlimited,
 				&replicationFactor)
 
 			itShouldReturnSpecificError(err, "stream_id_not_found")
 		})
 
-		ginkgo.Context("and tries to update non-existing stream", func() {
+		ginkgo.It("and tries to update non-existing stream", func() {
 			client := createAuthorizedConnection()
 			streamId, _ := successfullyCreateStream(prefix, client)
-			defer deleteStreamAfterTests(streamId, createAuthorizedConnection())
+			ginkgo.DeferCleanup(deleteStreamAfterTests, streamId, createAuthorizedConnection())
 			replicationFactor := uint8(1)
 			streamIdentifier, _ := iggcon.NewIdentifier(streamId)
 			err := client.UpdateTopic(
@@ -96,16 +96,16 @@ var _ = ginkgo.Describe("UPDATE TOPIC:", func() {
 				createRandomString(128),
 				1,
 				0,
-				math.MaxUint64,
+				iggcon.MaxTopicSizeUnlimited,
 				&replicationFactor)
 
 			itShouldReturnSpecificError(err, "topic_id_not_found")
 		})
 
-		ginkgo.Context("and tries to update existing topic with a name that's over 255 characters", func() {
+		ginkgo.It("and tries to update existing topic with a name that's over 255 characters", func() {
 			client := createAuthorizedConnection()
 			streamId, _ := successfullyCreateStream(prefix, client)
-			defer deleteStreamAfterTests(streamId, createAuthorizedConnection())
+			ginkgo.DeferCleanup(deleteStreamAfterTests, streamId, createAuthorizedConnection())
 			topicId, _ := successfullyCreateTopic(streamId, client)
 			replicationFactor := uint8(1)
 			streamIdentifier, _ := iggcon.NewIdentifier(streamId)
@@ -116,7 +116,7 @@ var _ = ginkgo.Describe("UPDATE TOPIC:", func() {
 				createRandomString(256),
 				1,
 				0,
-				math.MaxUint64,
+				iggcon.MaxTopicSizeUnlimited,
 				&replicationFactor)
 
 			itShouldReturnSpecificError(err, "topic_name_too_long")
@@ -124,7 +124,7 @@ var _ = ginkgo.Describe("UPDATE TOPIC:", func() {
 	})
 
 	ginkgo.When("User is not logged in", func() {
-		ginkgo.Context("and tries to update stream", func() {
+		ginkgo.It("and tries to update stream", func() {
 			client := createClient()
 			err := client.UpdateStream(randomU32Identifier(), createRandomString(128))
 
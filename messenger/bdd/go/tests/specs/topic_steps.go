@@ -15,16 +15,14 @@
 // specific language governing permissions and limitations
 // under the License.
 
-package tcp_test
+package specs
 
 import (
 	"fmt"
 	iggcon "github.com/apache/messenger/foreign/go/contracts"
 	ierror "github.com/apache/messenger/foreign/go/errors"
 	"github.com/apache/messenger/foreign/go/messengercli"
-	"github.com/onsi/ginkgo/v2"
 	"github.com/onsi/gomega"
-	"math"
 )
 
 //operations
@@ -40,7 +38,7 @@ func successfullyCreateTopic(streamId uint32, client messengercli.Client) (uint3
 		2,
 		1,
 		0,
-		math.MaxUint64,
+		iggcon.MaxTopicSizeUnlimited,
 		&replicationFactor,
 		&topicId)
 
@@ -52,17 +50,17 @@ func successfullyCreateTopic(streamId uint32, client messengercli.Client) (uint3
 //assertions
 
 func itShouldReturnSpecificTopic(id uint32, name string, topic iggcon.TopicDetails) {
-	ginkgo.It("should fetch topic with id "+string(rune(id)), func() {
+	it("should fetch topic with id "+string(rune(id)), func() {
 		gomega.Expect(topic.Id).To(gomega.Equal(id))
 	})
 
-	ginkgo.It("should fetch topic with name "+name, func() {
+	it("should fetch topic with name "+name, func() {
 		gomega.Expect(topic.Name).To(gomega.Equal(name))
 	})
 }
 
 func itShouldContainSpecificTopic(id uint32, name string, topics []iggcon.Topic) {
-	ginkgo.It("should fetch at least one topic", func() {
+	it("should fetch at least one topic", func() {
 		gomega.Expect(len(topics)).NotTo(gomega.Equal(0))
 	})
 
@@ -77,12 +75,12 @@ func itShouldContainSpecificTopic(id uint32, name string, topics []iggcon.Topic)
 		}
 	}
 
-	ginkgo.It(fmt.Sprintf("should fetch topic with id %d", id), func() {
+	it(fmt.Sprintf("should fetch topic with id %d", id), func() {
 		gomega.Expect(found).To(gomega.BeTrue(), "Topic with id %d and name %s not found", id, name)
 		gomega.Expect(topic.Id).To(gomega.Equal(id))
 	})
 
-	ginkgo.It("should fetch topic with name "+name, func() {
+	it("should fetch topic with name "+name, func() {
 		gomega.Expect(found).To(gomega.BeTrue(), "Topic with id %d and name %s not found", id, name)
 		gomega.Expect(topic.Name).To(gomega.Equal(name))
 	})
@@ -92,12 +90,12 @@ func itShouldSuccessfullyCreateTopic(streamId uint32, topicId uint32, expectedNa
 	streamIdentifier, _ := iggcon.NewIdentifier(streamId)
 	topicIdentifier, _ := iggcon.NewIdentifier(topicId)
 	topic, err := client.GetTopic(streamIdentifier, topicIdentifier)
-	ginkgo.It("should create topic with id "+string(rune(topicId)), func() {
+	it("should create topic with id "+string(rune(topicId)), func() {
 		gomega.Expect(topic).NotTo(gomega.BeNil())
 		gomega.Expect(topic.Id).To(gomega.Equal(topicId))
 	})
 
-	ginkgo.It("should create topic with name "+expectedName, func() {
+	it("should create topic with name "+expectedName, func() {
 		gomega.Expect(topic).NotTo(gomega.BeNil())
 		gomega.Expect(topic.Name).To(gomega.Equal(expectedName))
 	})
@@ -109,12 +107,12 @@ func itShouldSuccessfullyUpdateTopic(streamId uint32, topicId uint32, expectedNa
 	topicIdentifier, _ := iggcon.NewIdentifier(topicId)
 	topic, err := client.GetTopic(streamIdentifier, topicIdentifier)
 
-	ginkgo.It("should update topic with id "+string(rune(topicId)), func() {
+	it("should update topic with id "+string(rune(topicId)), func() {
 		gomega.Expect(topic).NotTo(gomega.BeNil())
 		gomega.Expect(topic.Id).To(gomega.Equal(topicId))
 	})
 
-	ginkgo.It("should update topic with name "+expectedName, func() {
+	it("should update topic with name "+expectedName, func() {
 		gomega.Expect(topic).NotTo(gomega.BeNil())
 		gomega.Expect(topic.Name).To(gomega.Equal(expectedName))
 	})
@@ -127,7 +125,7 @@ func itShouldSuccessfullyDeleteTopic(streamId uint32, topicId uint32, client mes
 	topic, err := client.GetTopic(streamIdentifier, topicIdentifier)
 
 	itShouldReturnSpecificMessengerError(err, ierror.TopicIdNotFound)
-	ginkgo.It("should not return topic", func() {
+	it("should not return topic", func() {
 		gomega.Expect(topic).To(gomega.BeNil())
 	})
 }
@@ -0,0 +1,39 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package specs holds the BDD scenarios shared by the suites of every transport. A suite imports
+// the package, which registers the specs, and selects its transport with Use before running them.
+package specs
+
+import (
+	"github.com/apache/messenger/foreign/go/messengercli"
+)
+
+// Transport creates the clients the specs run with.
+type Transport struct {
+	// Name identifies the transport, e.g. "tcp".
+	Name string
+	// NewClient creates a client which is not logged in.
+	NewClient func() (messengercli.Client, error)
+}
+
+var transport Transport
+
+// Use selects the transport of the specs, it must be called before running them.
+func Use(t Transport) {
+	transport = t
+}
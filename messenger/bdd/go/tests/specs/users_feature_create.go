@@ -15,7 +15,7 @@
 // specific language governing permissions and limitations
 // under the License.
 
-package tcp_test
+package specs
 
 import (
 	iggcon "github.com/apache/messenger/foreign/go/contracts"
@@ -24,7 +24,7 @@ import (
 
 var _ = ginkgo.Describe("CREATE USER:", func() {
 	ginkgo.When("User is logged in", func() {
-		ginkgo.Context("tries to create user with correct data", func() {
+		ginkgo.It("tries to create user with correct data", func() {
 			client := createAuthorizedConnection()
 
 			username := createRandomString(16)
@@ -47,14 +47,14 @@ var _ = ginkgo.Describe("CREATE USER:", func() {
 					},
 				})
 			identifier, _ := iggcon.NewIdentifier(username)
-			defer deleteUserAfterTests(identifier, client)
+			ginkgo.DeferCleanup(deleteUserAfterTests, identifier, client)
 
 			itShouldNotReturnError(err)
 			itShouldSuccessfullyCreateUser(username, client)
 			//itShouldBePossibleToLogInWithCredentials(request.Username, request.Password)
 		})
 
-		ginkgo.Context("tries to create user with correct data and custom permissions", func() {
+		ginkgo.It("tries to create user with correct data and custom permissions", func() {
 			client := createAuthorizedConnection()
 			streamId, _ := successfullyCreateStream("ss", client)
 			topicId, _ := successfullyCreateTopic(streamId, client)
@@ -102,8 +102,8 @@ var _ = ginkgo.Describe("CREATE USER:", func() {
 					Streams: userStreamPermissions,
 				})
 			identifier, _ := iggcon.NewIdentifier(username)
-			defer deleteUserAfterTests(identifier, client)
-			defer deleteStreamAfterTests(streamId, client)
+			ginkgo.DeferCleanup(deleteUserAfterTests, identifier, client)
+			ginkgo.DeferCleanup(deleteStreamAfterTests, streamId, client)
 
 			itShouldNotReturnError(err)
 			itShouldSuccessfullyCreateUserWithPermissions(username, client, userStreamPermissions)
@@ -112,7 +112,7 @@ var _ = ginkgo.Describe("CREATE USER:", func() {
 	})
 
 	ginkgo.When("User is not logged in", func() {
-		ginkgo.Context("and tries to create user", func() {
+		ginkgo.It("and tries to create user", func() {
 			client := createClient()
 
 			_, err := client.CreateUser(
@@ -15,7 +15,7 @@
 // specific language governing permissions and limitations
 // under the License.
 
-package tcp_test
+package specs
 
 import (
 	iggcon "github.com/apache/messenger/foreign/go/contracts"
@@ -24,7 +24,7 @@ import (
 
 var _ = ginkgo.Describe("DELETE USER:", func() {
 	ginkgo.When("User is logged in", func() {
-		ginkgo.Context("tries to delete user with correct data", func() {
+		ginkgo.It("tries to delete user with correct data", func() {
 			client := createAuthorizedConnection()
 			userId := successfullyCreateUser(createRandomString(16), client)
 			userIdentifier, _ := iggcon.NewIdentifier(userId)
@@ -36,7 +36,7 @@ var _ = ginkgo.Describe("DELETE USER:", func() {
 	})
 
 	ginkgo.When("User is not logged in", func() {
-		ginkgo.Context("and tries to delete user", func() {
+		ginkgo.It("and tries to delete user", func() {
 			client := createClient()
 			err := client.DeleteUser(randomU32Identifier())
 			itShouldReturnUnauthenticatedError(err)
@@ -15,7 +15,7 @@
 // specific language governing permissions and limitations
 // under the License.
 
-package tcp_test
+package specs
 
 import (
 	iggcon "github.com/apache/messenger/foreign/go/contracts"
@@ -24,12 +24,12 @@ import (
 
 var _ = ginkgo.Describe("GET USER:", func() {
 	ginkgo.When("User is logged in", func() {
-		ginkgo.Context("tries to get existing user", func() {
+		ginkgo.It("tries to get existing user", func() {
 			client := createAuthorizedConnection()
 			name := createRandomString(16)
 			userId := successfullyCreateUser(name, client)
 			userIdentifier, _ := iggcon.NewIdentifier(userId)
-			defer deleteUserAfterTests(userIdentifier, client)
+			ginkgo.DeferCleanup(deleteUserAfterTests, userIdentifier, client)
 
 			user, err := client.GetUser(userIdentifier)
 
@@ -15,7 +15,7 @@
 // specific language governing permissions and limitations
 // under the License.
 
-package tcp_test
+package specs
 
 import (
 	iggcon "github.com/apache/messenger/foreign/go/contracts"
@@ -24,7 +24,7 @@ import (
 
 var _ = ginkgo.Describe("CHANGE PASSWORD:", func() {
 	ginkgo.When("User is logged in", func() {
-		ginkgo.Context("tries to change password of existing user", func() {
+		ginkgo.It("tries to change password of existing user", func() {
 			client := createAuthorizedConnection()
 
 			username := createRandomStringWithPrefix("ch_p_", 16)
@@ -49,7 +49,7 @@ var _ = ginkgo.Describe("CHANGE PASSWORD:", func() {
 				})
 			itShouldNotReturnError(err)
 			identifier, _ := iggcon.NewIdentifier(username)
-			defer deleteUserAfterTests(identifier, client)
+			ginkgo.DeferCleanup(deleteUserAfterTests, identifier, client)
 
 			err = client.ChangePassword(identifier, password, "newPassword")
 
@@ -59,7 +59,7 @@ var _ = ginkgo.Describe("CHANGE PASSWORD:", func() {
 	})
 
 	ginkgo.When("User is not logged in", func() {
-		ginkgo.Context("and tries to change password", func() {
+		ginkgo.It("and tries to change password", func() {
 			client := createClient()
 
 			err := client.UpdatePermissions(
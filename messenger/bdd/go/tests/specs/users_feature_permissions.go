@@ -15,7 +15,7 @@
 // specific language governing permissions and limitations
 // under the License.
 
-package tcp_test
+package specs
 
 import (
 	iggcon "github.com/apache/messenger/foreign/go/contracts"
@@ -24,11 +24,11 @@ import (
 
 var _ = ginkgo.Describe("UPDATE USER PERMISSIONS:", func() {
 	ginkgo.When("User is logged in", func() {
-		ginkgo.Context("tries to update permissions of existing user", func() {
+		ginkgo.It("tries to update permissions of existing user", func() {
 			client := createAuthorizedConnection()
 			userId := successfullyCreateUser(createRandomString(16), client)
 			identifier, _ := iggcon.NewIdentifier(userId)
-			defer deleteUserAfterTests(identifier, client)
+			ginkgo.DeferCleanup(deleteUserAfterTests, identifier, client)
 
 			err := client.UpdatePermissions(
 				identifier,
@@ -53,7 +53,7 @@ var _ = ginkgo.Describe("UPDATE USER PERMISSIONS:", func() {
 	})
 
 	ginkgo.When("User is not logged in", func() {
-		ginkgo.Context("and tries to change user permissions", func() {
+		ginkgo.It("and tries to change user permissions", func() {
 			client := createClient()
 			username := createRandomString(16)
 			err := client.UpdateUser(
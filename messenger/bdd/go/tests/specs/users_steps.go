@@ -15,12 +15,11 @@
 // specific language governing permissions and limitations
 // under the License.
 
-package tcp_test
+package specs
 
 import (
 	iggcon "github.com/apache/messenger/foreign/go/contracts"
 	"github.com/apache/messenger/foreign/go/messengercli"
-	"github.com/onsi/ginkgo/v2"
 	"github.com/onsi/gomega"
 )
 
@@ -61,7 +60,7 @@ func itShouldSuccessfullyCreateUser(name string, client messengercli.Client) {
 
 	itShouldNotReturnError(err)
 
-	ginkgo.It("should create user with name "+name, func() {
+	it("should create user with name "+name, func() {
 		gomega.Expect(user.Username).To(gomega.Equal(name))
 	})
 }
@@ -72,11 +71,11 @@ func itShouldSuccessfullyCreateUserWithPermissions(name string, client messenger
 
 	itShouldNotReturnError(err)
 
-	ginkgo.It("should create user with name "+name, func() {
+	it("should create user with name "+name, func() {
 		gomega.Expect(user.Username).To(gomega.Equal(name))
 	})
 
-	ginkgo.It("should create user with correct permissions", func() {
+	it("should create user with correct permissions", func() {
 
 		for streamId, streamPermission := range user.Permissions.Streams {
 
@@ -103,11 +102,11 @@ func itShouldSuccessfullyUpdateUser(id uint32, name string, client messengercli.
 
 	itShouldNotReturnError(err)
 
-	ginkgo.It("should update user with id "+string(rune(id)), func() {
+	it("should update user with id "+string(rune(id)), func() {
 		gomega.Expect(user.Id).To(gomega.Equal(id))
 	})
 
-	ginkgo.It("should update user with name "+name, func() {
+	it("should update user with name "+name, func() {
 		gomega.Expect(user.Username).To(gomega.Equal(name))
 	})
 }
@@ -117,7 +116,7 @@ func itShouldSuccessfullyDeleteUser(userId uint32, client messengercli.Client) {
 	user, err := client.GetUser(identifier)
 
 	itShouldReturnSpecificError(err, "resource_not_found")
-	ginkgo.It("should not return user", func() {
+	it("should not return user", func() {
 		gomega.Expect(user).To(gomega.BeNil())
 	})
 }
@@ -128,7 +127,7 @@ func itShouldSuccessfullyUpdateUserPermissions(userId uint32, client messengercl
 
 	itShouldNotReturnError(err)
 
-	ginkgo.It("should update user permissions with id "+string(rune(userId)), func() {
+	it("should update user permissions with id "+string(rune(userId)), func() {
 		gomega.Expect(user.Permissions.Global.ManageServers).To(gomega.BeFalse())
 		gomega.Expect(user.Permissions.Global.ReadServers).To(gomega.BeFalse())
 		gomega.Expect(user.Permissions.Global.ManageUsers).To(gomega.BeFalse())
@@ -143,13 +142,13 @@ func itShouldSuccessfullyUpdateUserPermissions(userId uint32, client messengercl
 }
 
 func itShouldReturnSpecificUser(name string, user iggcon.UserInfo) {
-	ginkgo.It("should fetch user with name "+name, func() {
+	it("should fetch user with name "+name, func() {
 		gomega.Expect(user.Username).To(gomega.Equal(name))
 	})
 }
 
 func itShouldContainSpecificUser(name string, users []iggcon.UserInfo) {
-	ginkgo.It("should fetch at least one user", func() {
+	it("should fetch at least one user", func() {
 		gomega.Expect(len(users)).NotTo(gomega.Equal(0))
 	})
 
@@ -164,7 +163,7 @@ func itShouldContainSpecificUser(name string, users []iggcon.UserInfo) {
 		}
 	}
 
-	ginkgo.It("should fetch user with name "+name, func() {
+	it("should fetch user with name "+name, func() {
 		gomega.Expect(found).To(gomega.BeTrue(), "User with name %s not found", name)
 		gomega.Expect(user.Username).To(gomega.Equal(name))
 	})
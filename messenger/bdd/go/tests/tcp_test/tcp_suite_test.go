@@ -18,15 +18,35 @@
 package tcp_test
 
 import (
+	"os"
 	"testing"
 
+	"github.com/apache/messenger/bdd/go/tests/specs"
+	"github.com/apache/messenger/foreign/go/messengercli"
+	"github.com/apache/messenger/foreign/go/messengertest"
+	"github.com/apache/messenger/foreign/go/tcp"
 	"github.com/onsi/ginkgo/v2"
 	"github.com/onsi/gomega"
 )
 
 func TestTcp(t *testing.T) {
 	//there should be no conflicts, but clean up messenger/local_data from time to time
-	//this assumes there is a running messenger server
+	//this assumes there is a running messenger server, unless MESSENGER_TCP_ADDRESS is "stub":
+	//the specs then run against an in-process stub server answering with the error codes of the server
+	//every spec sets up and cleans up its own resources, so the suite can run in parallel with `ginkgo -p`
+	addr := os.Getenv("MESSENGER_TCP_ADDRESS")
+	switch addr {
+	case "":
+		addr = "127.0.0.1:8090"
+	case "stub":
+		addr = messengertest.StartServer(t).Addr()
+	}
+	specs.Use(specs.Transport{
+		Name: "tcp",
+		NewClient: func() (messengercli.Client, error) {
+			return messengercli.NewMessengerClient(messengercli.WithTcp(tcp.WithServerAddress(addr)))
+		},
+	})
 	gomega.RegisterFailHandler(ginkgo.Fail)
 	ginkgo.RunSpecs(t, "My Feature Suite")
 }
@@ -73,8 +73,8 @@ func consumeMessages(client messengercli.Client) error {
 			return nil
 		}
 
-		streamIdentifier, _ := iggcon.NewIdentifier(StreamID)
-		topicIdentifier, _ := iggcon.NewIdentifier(TopicID)
+		streamIdentifier := iggcon.MustIdentifier(StreamID)
+		topicIdentifier := iggcon.MustIdentifier(TopicID)
 		pollMessages, err := client.
 			PollMessages(
 				streamIdentifier,
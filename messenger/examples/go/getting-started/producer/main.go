@@ -18,6 +18,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"github.com/apache/messenger/examples/go/common"
@@ -62,16 +63,13 @@ func initSystem(client messengercli.Client) {
 	}
 	log.Println("Stream was created.")
 
-	streamIdentifier, _ := iggcon.NewIdentifier(StreamId)
-	if _, err := client.CreateTopic(
-		streamIdentifier,
-		"sample-topic",
-		1,
-		iggcon.CompressionAlgorithmNone,
-		iggcon.MessengerExpiryNeverExpire,
-		0,
-		nil,
-		&TopicId); err != nil {
+	streamIdentifier := iggcon.MustIdentifier(StreamId)
+	if _, err := messengercli.CreateTopic(context.Background(), client, iggcon.CreateTopicRequest{
+		StreamId:      streamIdentifier,
+		Name:          "sample-topic",
+		TopicId:       &TopicId,
+		MessageExpiry: iggcon.ExpiryNever,
+	}); err != nil {
 		log.Printf("WARN: Topic already exists and will not be created again or error: %v", err)
 	}
 	log.Println("Topic was created.")
@@ -106,8 +104,8 @@ func produceMessages(client messengercli.Client) error {
 			messages = append(messages, message)
 		}
 
-		streamIdentifier, _ := iggcon.NewIdentifier(StreamId)
-		topicIdentifier, _ := iggcon.NewIdentifier(TopicId)
+		streamIdentifier := iggcon.MustIdentifier(StreamId)
+		topicIdentifier := iggcon.MustIdentifier(TopicId)
 		if err := client.SendMessages(
 			streamIdentifier,
 			topicIdentifier,
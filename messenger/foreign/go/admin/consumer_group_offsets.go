@@ -0,0 +1,179 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package admin
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengercli"
+)
+
+type OffsetResetKind int
+
+const (
+	// ResetToEarliest makes the group consume the partitions from their first message.
+	ResetToEarliest OffsetResetKind = iota + 1
+	// ResetToLatest makes the group skip every message already in the partitions.
+	ResetToLatest
+	// ResetToTimestamp makes the group consume from the first message appended at or after a timestamp.
+	ResetToTimestamp
+	// ResetToOffsets makes the group consume from specific offsets per partition.
+	ResetToOffsets
+)
+
+// OffsetReset describes where the offsets of a consumer group are moved to.
+type OffsetReset struct {
+	Kind      OffsetResetKind
+	Timestamp time.Time
+	// Offsets maps partition IDs to the offset of the next message to consume.
+	// Partitions missing from the map are left untouched.
+	Offsets map[uint32]uint64
+}
+
+// PartitionOffsetReset reports the offset reset of a single partition, offsets being
+// the ones of the next message the group consumes.
+type PartitionOffsetReset struct {
+	PartitionId uint32
+	Before      uint64
+	After       uint64
+}
+
+func (r PartitionOffsetReset) String() string {
+	return fmt.Sprintf("partition %d: %d -> %d", r.PartitionId, r.Before, r.After)
+}
+
+// ResetConsumerGroupOffsets moves the offsets of a consumer group for every partition of the
+// given stream and topic by unique IDs or names. With dryRun, the offsets are only computed
+// and nothing is stored, so the returned before/after offsets can be reviewed first.
+func ResetConsumerGroupOffsets(
+	client messengercli.Client,
+	streamId iggcon.Identifier,
+	topicId iggcon.Identifier,
+	groupId iggcon.Identifier,
+	reset OffsetReset,
+	dryRun bool,
+) ([]PartitionOffsetReset, error) {
+	topic, err := client.GetTopic(streamId, topicId)
+	if err != nil {
+		return nil, err
+	}
+	consumer := iggcon.NewGroupConsumer(groupId)
+
+	resets := make([]PartitionOffsetReset, 0, len(topic.Partitions))
+	for _, partition := range topic.Partitions {
+		before, err := nextOffset(client, consumer, streamId, topicId, partition.Id)
+		if err != nil {
+			return nil, err
+		}
+		after, ok, err := targetOffset(client, streamId, topicId, partition, reset)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		resets = append(resets, PartitionOffsetReset{
+			PartitionId: partition.Id,
+			Before:      before,
+			After:       after,
+		})
+	}
+
+	if dryRun {
+		return resets, nil
+	}
+	for _, reset := range resets {
+		if reset.Before == reset.After {
+			continue
+		}
+		if err := storeNextOffset(client, consumer, streamId, topicId, reset.PartitionId, reset.After); err != nil {
+			return nil, fmt.Errorf("failed to reset the offset of partition %d: %w", reset.PartitionId, err)
+		}
+	}
+	return resets, nil
+}
+
+// nextOffset returns the offset of the next message the consumer reads from the partition.
+func nextOffset(client messengercli.Client, consumer iggcon.Consumer, streamId, topicId iggcon.Identifier, partitionId uint32) (uint64, error) {
+	offset, err := client.GetConsumerOffset(consumer, streamId, topicId, &partitionId)
+	if err != nil {
+		return 0, err
+	}
+	if offset == nil {
+		return 0, nil
+	}
+	return offset.StoredOffset + 1, nil
+}
+
+func targetOffset(
+	client messengercli.Client,
+	streamId iggcon.Identifier,
+	topicId iggcon.Identifier,
+	partition iggcon.PartitionContract,
+	reset OffsetReset,
+) (uint64, bool, error) {
+	latest := endOffset(partition)
+
+	switch reset.Kind {
+	case ResetToEarliest:
+		// retention may have deleted the oldest segments, so the first offset still stored
+		// is asked for rather than assumed to be 0
+		return firstOffset(client, streamId, topicId, partition, iggcon.FirstPollingStrategy())
+	case ResetToLatest:
+		return latest, true, nil
+	case ResetToOffsets:
+		offset, ok := reset.Offsets[partition.Id]
+		return min(offset, latest), ok, nil
+	case ResetToTimestamp:
+		return firstOffset(client, streamId, topicId, partition, iggcon.TimestampPollingStrategy(uint64(reset.Timestamp.UnixMicro())))
+	default:
+		return 0, false, errors.New("unknown offset reset kind")
+	}
+}
+
+// firstOffset is the offset of the first message the strategy polls from the partition,
+// or the end of the partition when it has none.
+func firstOffset(
+	client messengercli.Client,
+	streamId iggcon.Identifier,
+	topicId iggcon.Identifier,
+	partition iggcon.PartitionContract,
+	strategy iggcon.PollingStrategy,
+) (uint64, bool, error) {
+	// the lookup is read-only, a standalone consumer neither needs the group membership
+	// nor touches the offsets of the group
+	polled, err := client.PollMessages(streamId, topicId, iggcon.DefaultConsumer(), strategy, 1, false, &partition.Id)
+	if err != nil {
+		return 0, false, err
+	}
+	if polled == nil || len(polled.Messages) == 0 {
+		return endOffset(partition), true, nil
+	}
+	return polled.Messages[0].Header.Offset, true, nil
+}
+
+// storeNextOffset makes offset the next message the consumer reads from the partition.
+func storeNextOffset(client messengercli.Client, consumer iggcon.Consumer, streamId, topicId iggcon.Identifier, partitionId uint32, offset uint64) error {
+	if offset == 0 {
+		return client.DeleteConsumerOffset(consumer, streamId, topicId, &partitionId)
+	}
+	return client.StoreConsumerOffset(consumer, streamId, topicId, offset-1, &partitionId)
+}
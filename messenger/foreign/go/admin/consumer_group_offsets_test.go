@@ -0,0 +1,171 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package admin
+
+import (
+	"testing"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengertest"
+)
+
+// standaloneLookups fails the test when the messages are polled on behalf of a consumer group.
+// The offsets below deleted are treated as removed by retention.
+type standaloneLookups struct {
+	*messengertest.Client
+	t       *testing.T
+	deleted uint64
+}
+
+func (c standaloneLookups) PollMessages(
+	streamId iggcon.Identifier,
+	topicId iggcon.Identifier,
+	consumer iggcon.Consumer,
+	strategy iggcon.PollingStrategy,
+	count uint32,
+	autoCommit bool,
+	partitionId *uint32,
+) (*iggcon.PolledMessage, error) {
+	if consumer.Kind == iggcon.ConsumerKindGroup {
+		c.t.Errorf("expected the messages to be polled by a standalone consumer, got the group %v", consumer.Id)
+	}
+	if strategy.Kind == iggcon.POLLING_FIRST {
+		strategy = iggcon.OffsetPollingStrategy(c.deleted)
+	}
+	return c.Client.PollMessages(streamId, topicId, consumer, strategy, count, autoCommit, partitionId)
+}
+
+func TestResetConsumerGroupOffsets(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		reset  OffsetReset
+		dryRun bool
+		// deleted is the number of messages of partition 1 removed by retention
+		deleted uint64
+		// resets are the reported offset resets and next the next offsets stored afterwards,
+		// the group having consumed partition 1 up to offset 4 and nothing of partition 2
+		resets []PartitionOffsetReset
+		next   map[uint32]uint64
+	}{
+		{
+			name:   "earliest deletes the stored offsets",
+			reset:  OffsetReset{Kind: ResetToEarliest},
+			resets: []PartitionOffsetReset{{PartitionId: 1, Before: 5, After: 0}, {PartitionId: 2, Before: 0, After: 0}},
+			next:   map[uint32]uint64{1: 0, 2: 0},
+		},
+		{
+			name:    "earliest after retention stores the offset before the first remaining message",
+			reset:   OffsetReset{Kind: ResetToEarliest},
+			deleted: 3,
+			resets:  []PartitionOffsetReset{{PartitionId: 1, Before: 5, After: 3}, {PartitionId: 2, Before: 0, After: 0}},
+			next:    map[uint32]uint64{1: 3, 2: 0},
+		},
+		{
+			name:   "latest stores the last offsets",
+			reset:  OffsetReset{Kind: ResetToLatest},
+			resets: []PartitionOffsetReset{{PartitionId: 1, Before: 5, After: 10}, {PartitionId: 2, Before: 0, After: 0}},
+			next:   map[uint32]uint64{1: 10, 2: 0},
+		},
+		{
+			name:   "timestamp stores the offset before the first later message",
+			reset:  OffsetReset{Kind: ResetToTimestamp, Timestamp: start.Add(3 * time.Second)},
+			resets: []PartitionOffsetReset{{PartitionId: 1, Before: 5, After: 3}, {PartitionId: 2, Before: 0, After: 0}},
+			next:   map[uint32]uint64{1: 3, 2: 0},
+		},
+		{
+			name:   "timestamp after the last message stores the last offsets",
+			reset:  OffsetReset{Kind: ResetToTimestamp, Timestamp: start.Add(time.Hour)},
+			resets: []PartitionOffsetReset{{PartitionId: 1, Before: 5, After: 10}, {PartitionId: 2, Before: 0, After: 0}},
+			next:   map[uint32]uint64{1: 10, 2: 0},
+		},
+		{
+			name:   "offsets are capped and leave the missing partitions untouched",
+			reset:  OffsetReset{Kind: ResetToOffsets, Offsets: map[uint32]uint64{1: 50}},
+			resets: []PartitionOffsetReset{{PartitionId: 1, Before: 5, After: 10}},
+			next:   map[uint32]uint64{1: 10, 2: 0},
+		},
+		{
+			name:   "offsets store the offset before the given one",
+			reset:  OffsetReset{Kind: ResetToOffsets, Offsets: map[uint32]uint64{1: 7}},
+			resets: []PartitionOffsetReset{{PartitionId: 1, Before: 5, After: 7}},
+			next:   map[uint32]uint64{1: 7, 2: 0},
+		},
+		{
+			name:   "dry run stores nothing",
+			reset:  OffsetReset{Kind: ResetToEarliest},
+			dryRun: true,
+			resets: []PartitionOffsetReset{{PartitionId: 1, Before: 5, After: 0}, {PartitionId: 2, Before: 0, After: 0}},
+			next:   map[uint32]uint64{1: 5, 2: 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := start
+			raw := messengertest.NewClient(messengertest.WithClock(iggcon.ClockFunc(func() time.Time { return now })))
+			streamId, topicId, groupId := iggcon.MustIdentifier("stream"), iggcon.MustIdentifier("topic"), iggcon.MustIdentifier("group")
+			if _, err := raw.CreateStream("stream", nil); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := raw.CreateTopic(streamId, "topic", 2, iggcon.CompressionAlgorithmNone, 0, 0, nil, nil); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := raw.CreateConsumerGroup(streamId, topicId, "group", nil); err != nil {
+				t.Fatal(err)
+			}
+			// a message per second on partition 1, the one at offset i appended at start+i seconds
+			for i := range 10 {
+				now = start.Add(time.Duration(i) * time.Second)
+				message, _ := iggcon.NewMessengerMessage([]byte("message"))
+				if err := raw.SendMessages(streamId, topicId, iggcon.PartitionId(1), []iggcon.MessengerMessage{message}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			group := iggcon.NewGroupConsumer(groupId)
+			partitionId := uint32(1)
+			if err := raw.StoreConsumerOffset(group, streamId, topicId, 4, &partitionId); err != nil {
+				t.Fatal(err)
+			}
+
+			client := standaloneLookups{Client: raw, t: t, deleted: tt.deleted}
+			resets, err := ResetConsumerGroupOffsets(client, streamId, topicId, groupId, tt.reset, tt.dryRun)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(resets) != len(tt.resets) {
+				t.Fatalf("expected %v, got %v", tt.resets, resets)
+			}
+			for i := range tt.resets {
+				if resets[i] != tt.resets[i] {
+					t.Errorf("expected %v, got %v", tt.resets[i], resets[i])
+				}
+			}
+			for partitionId, expected := range tt.next {
+				next, err := nextOffset(raw, group, streamId, topicId, partitionId)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if next != expected {
+					t.Errorf("expected partition %d to be consumed from offset %d, got %d", partitionId, expected, next)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package admin
+
+import (
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	ierror "github.com/apache/messenger/foreign/go/errors"
+	"github.com/apache/messenger/foreign/go/messengercli"
+)
+
+// PartitionOffsets reports the end of a partition.
+type PartitionOffsets struct {
+	PartitionId uint32 `json:"partitionId"`
+	// CurrentOffset is the offset of the last message of the partition, 0 when it is empty.
+	CurrentOffset uint64 `json:"currentOffset"`
+	// EndOffset is the offset the next message appended to the partition gets.
+	EndOffset     uint64 `json:"endOffset"`
+	MessagesCount uint64 `json:"messagesCount"`
+	SizeBytes     uint64 `json:"sizeBytes"`
+}
+
+// PartitionLag reports how far a consumer is behind the end of a partition.
+type PartitionLag struct {
+	PartitionId uint32 `json:"partitionId"`
+	EndOffset   uint64 `json:"endOffset"`
+	// ConsumerOffset is the offset of the next message the consumer reads.
+	ConsumerOffset uint64 `json:"consumerOffset"`
+	// Lag is the number of messages left to consume.
+	Lag uint64 `json:"lag"`
+}
+
+// ConsumerLag reports the lag of a consumer or consumer group over a topic.
+type ConsumerLag struct {
+	Partitions []PartitionLag `json:"partitions"`
+	// TotalLag is the number of messages left to consume over all partitions.
+	TotalLag uint64 `json:"totalLag"`
+}
+
+// GetTopicOffsets returns the end offsets of every partition of the given stream and topic by unique IDs or names.
+func GetTopicOffsets(client messengercli.AdminClient, streamId, topicId iggcon.Identifier) ([]PartitionOffsets, error) {
+	topic, err := client.GetTopic(streamId, topicId)
+	if err != nil {
+		return nil, err
+	}
+	offsets := make([]PartitionOffsets, len(topic.Partitions))
+	for i, partition := range topic.Partitions {
+		offsets[i] = partitionOffsets(partition)
+	}
+	return offsets, nil
+}
+
+// GetPartitionOffsets returns the end offsets of a partition of the given stream and topic by unique IDs or names.
+func GetPartitionOffsets(client messengercli.AdminClient, streamId, topicId iggcon.Identifier, partitionId uint32) (*PartitionOffsets, error) {
+	topic, err := client.GetTopic(streamId, topicId)
+	if err != nil {
+		return nil, err
+	}
+	for _, partition := range topic.Partitions {
+		if partition.Id == partitionId {
+			offsets := partitionOffsets(partition)
+			return &offsets, nil
+		}
+	}
+	return nil, ierror.MapFromCode(3007)
+}
+
+func partitionOffsets(partition iggcon.PartitionContract) PartitionOffsets {
+	return PartitionOffsets{
+		PartitionId:   partition.Id,
+		CurrentOffset: partition.CurrentOffset,
+		EndOffset:     endOffset(partition),
+		MessagesCount: partition.MessagesCount,
+		SizeBytes:     partition.SizeBytes,
+	}
+}
+
+// GetConsumerLag combines the end offsets of the partitions with the offsets stored by the consumer,
+// a consumer or a consumer group, to report its lag per partition of the given stream and topic.
+func GetConsumerLag(client messengercli.Client, streamId, topicId iggcon.Identifier, consumer iggcon.Consumer) (*ConsumerLag, error) {
+	offsets, err := GetTopicOffsets(client, streamId, topicId)
+	if err != nil {
+		return nil, err
+	}
+
+	lag := &ConsumerLag{Partitions: make([]PartitionLag, len(offsets))}
+	for i, partition := range offsets {
+		consumerOffset, err := nextOffset(client, consumer, streamId, topicId, partition.PartitionId)
+		if err != nil {
+			return nil, err
+		}
+		partitionLag := PartitionLag{
+			PartitionId:    partition.PartitionId,
+			EndOffset:      partition.EndOffset,
+			ConsumerOffset: consumerOffset,
+		}
+		if partition.EndOffset > consumerOffset {
+			partitionLag.Lag = partition.EndOffset - consumerOffset
+		}
+		lag.Partitions[i] = partitionLag
+		lag.TotalLag += partitionLag.Lag
+	}
+	return lag, nil
+}
+
+// endOffset returns the offset the next message appended to the partition gets.
+func endOffset(partition iggcon.PartitionContract) uint64 {
+	if partition.MessagesCount == 0 {
+		return 0
+	}
+	return partition.CurrentOffset + 1
+}
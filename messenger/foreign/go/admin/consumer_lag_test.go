@@ -0,0 +1,104 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package admin
+
+import (
+	"testing"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengercli"
+)
+
+type fakeClient struct {
+	messengercli.Client
+	topic   *iggcon.TopicDetails
+	offsets map[uint32]uint64
+}
+
+func (c *fakeClient) GetTopic(iggcon.Identifier, iggcon.Identifier) (*iggcon.TopicDetails, error) {
+	return c.topic, nil
+}
+
+func (c *fakeClient) GetConsumerOffset(_ iggcon.Consumer, _, _ iggcon.Identifier, partitionId *uint32) (*iggcon.ConsumerOffsetInfo, error) {
+	stored, ok := c.offsets[*partitionId]
+	if !ok {
+		return nil, nil
+	}
+	return &iggcon.ConsumerOffsetInfo{PartitionId: *partitionId, StoredOffset: stored}, nil
+}
+
+func TestGetConsumerLag(t *testing.T) {
+	client := &fakeClient{
+		topic: &iggcon.TopicDetails{Partitions: []iggcon.PartitionContract{
+			{Id: 1, MessagesCount: 100, CurrentOffset: 99},
+			{Id: 2, MessagesCount: 10, CurrentOffset: 9},
+			{Id: 3},
+		}},
+		// partition 1 consumed up to offset 49, partition 2 fully consumed, partition 3 never consumed
+		offsets: map[uint32]uint64{1: 49, 2: 9},
+	}
+	streamId, _ := iggcon.NewIdentifier("stream")
+	topicId, _ := iggcon.NewIdentifier("topic")
+	groupId, _ := iggcon.NewIdentifier("group")
+
+	lag, err := GetConsumerLag(client, streamId, topicId, iggcon.NewGroupConsumer(groupId))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []PartitionLag{
+		{PartitionId: 1, EndOffset: 100, ConsumerOffset: 50, Lag: 50},
+		{PartitionId: 2, EndOffset: 10, ConsumerOffset: 10, Lag: 0},
+		{PartitionId: 3, EndOffset: 0, ConsumerOffset: 0, Lag: 0},
+	}
+	if len(lag.Partitions) != len(expected) {
+		t.Fatalf("expected %d partitions, got %d", len(expected), len(lag.Partitions))
+	}
+	for i := range expected {
+		if lag.Partitions[i] != expected[i] {
+			t.Errorf("expected %+v, got %+v", expected[i], lag.Partitions[i])
+		}
+	}
+	if lag.TotalLag != 50 {
+		t.Errorf("expected a total lag of 50, got %d", lag.TotalLag)
+	}
+}
+
+func TestGetPartitionOffsets(t *testing.T) {
+	client := &fakeClient{topic: &iggcon.TopicDetails{Partitions: []iggcon.PartitionContract{
+		{Id: 1, MessagesCount: 100, CurrentOffset: 99, SizeBytes: 4096},
+		{Id: 2},
+	}}}
+	streamId, _ := iggcon.NewIdentifier("stream")
+	topicId, _ := iggcon.NewIdentifier("topic")
+
+	offsets, err := GetPartitionOffsets(client, streamId, topicId, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := PartitionOffsets{PartitionId: 1, CurrentOffset: 99, EndOffset: 100, MessagesCount: 100, SizeBytes: 4096}
+	if *offsets != expected {
+		t.Fatalf("expected %+v, got %+v", expected, *offsets)
+	}
+	if offsets, err = GetPartitionOffsets(client, streamId, topicId, 2); err != nil || offsets.EndOffset != 0 {
+		t.Fatalf("expected an empty partition to end at offset 0, got %+v, %v", offsets, err)
+	}
+	if _, err = GetPartitionOffsets(client, streamId, topicId, 3); err == nil {
+		t.Fatal("expected a missing partition to be reported")
+	}
+}
@@ -0,0 +1,307 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package admin
+
+import (
+	"context"
+	"fmt"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengercli"
+)
+
+// Spec is the desired state of streams and topics, applied by Provision.
+type Spec struct {
+	Streams []StreamSpec `json:"streams"`
+}
+
+// StreamSpec describes a stream and its topics. Streams and topics missing from a spec are left
+// untouched, so a spec only needs to list what a service depends on.
+type StreamSpec struct {
+	Name string `json:"name"`
+	// Id is the ID the stream is created with, nil to let the server assign one.
+	Id     *uint32     `json:"id,omitempty"`
+	Topics []TopicSpec `json:"topics"`
+}
+
+// TopicSpec describes a topic. Its zero valued settings are left to the server when the topic
+// is created, a single partition being created by default, and are not compared with the
+// existing topics.
+type TopicSpec struct {
+	Name string `json:"name"`
+	// Id is the ID the topic is created with, nil to let the server assign one.
+	Id                   *uint32                     `json:"id,omitempty"`
+	Partitions           uint32                      `json:"partitions"`
+	CompressionAlgorithm iggcon.CompressionAlgorithm `json:"compressionAlgorithm,omitempty"`
+	MessageExpiry        iggcon.Expiry               `json:"messageExpiry,omitempty"`
+	MaxTopicSize         iggcon.MaxTopicSize         `json:"maxTopicSize,omitempty"`
+	ReplicationFactor    *uint8                      `json:"replicationFactor,omitempty"`
+	// ConsumerGroups are the names of the consumer groups of the topic.
+	ConsumerGroups []string `json:"consumerGroups,omitempty"`
+}
+
+type ChangeKind int
+
+const (
+	CreateStream ChangeKind = iota + 1
+	CreateTopic
+	// UpdateTopic changes the compression, expiry, size limit or replication of a topic.
+	UpdateTopic
+	// AddPartitions adds partitions to a topic, which never loses partitions to a spec.
+	AddPartitions
+	CreateConsumerGroup
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case CreateStream:
+		return "create stream"
+	case CreateTopic:
+		return "create topic"
+	case UpdateTopic:
+		return "update topic"
+	case AddPartitions:
+		return "add partitions"
+	case CreateConsumerGroup:
+		return "create consumer group"
+	}
+	return fmt.Sprintf("ChangeKind(%d)", int(k))
+}
+
+// Change is a step bringing the server to the state of a Spec.
+type Change struct {
+	Kind   ChangeKind
+	Stream StreamSpec
+	Topic  TopicSpec
+	// Partitions is the number of partitions an AddPartitions change adds.
+	Partitions uint32
+	// ConsumerGroup is the name of the group a CreateConsumerGroup change creates.
+	ConsumerGroup string
+	// current is the topic an UpdateTopic change applies to.
+	current iggcon.Topic
+}
+
+func (c Change) String() string {
+	switch c.Kind {
+	case CreateStream:
+		return fmt.Sprintf("create stream %s", c.Stream.Name)
+	case AddPartitions:
+		return fmt.Sprintf("add %d partition(s) to topic %s/%s", c.Partitions, c.Stream.Name, c.Topic.Name)
+	case CreateConsumerGroup:
+		return fmt.Sprintf("create consumer group %s/%s/%s", c.Stream.Name, c.Topic.Name, c.ConsumerGroup)
+	}
+	return fmt.Sprintf("%s %s/%s", c.Kind, c.Stream.Name, c.Topic.Name)
+}
+
+// Plan compares the spec with the server and returns the changes Provision would apply, in order.
+// It fails when a topic has more partitions than specified, as deleting partitions deletes their
+// messages.
+func Plan(client messengercli.AdminClient, spec Spec) ([]Change, error) {
+	streams, err := client.GetStreams()
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]iggcon.Stream, len(streams))
+	for _, stream := range streams {
+		existing[stream.Name] = stream
+	}
+
+	var changes []Change
+	for _, streamSpec := range spec.Streams {
+		stream, ok := existing[streamSpec.Name]
+		if !ok {
+			changes = append(changes, Change{Kind: CreateStream, Stream: streamSpec})
+			for _, topicSpec := range streamSpec.Topics {
+				changes = append(changes, createTopic(streamSpec, topicSpec)...)
+			}
+			continue
+		}
+		streamId, err := iggcon.NewIdentifier(stream.Id)
+		if err != nil {
+			return nil, err
+		}
+		topics, err := client.GetTopics(streamId)
+		if err != nil {
+			return nil, err
+		}
+		streamChanges, err := planTopics(client, streamId, streamSpec, topics)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, streamChanges...)
+	}
+	return changes, nil
+}
+
+// createTopic returns the changes creating a topic and its consumer groups.
+func createTopic(streamSpec StreamSpec, topicSpec TopicSpec) []Change {
+	changes := []Change{{Kind: CreateTopic, Stream: streamSpec, Topic: topicSpec}}
+	for _, group := range topicSpec.ConsumerGroups {
+		changes = append(changes, Change{Kind: CreateConsumerGroup, Stream: streamSpec, Topic: topicSpec, ConsumerGroup: group})
+	}
+	return changes
+}
+
+func planTopics(client messengercli.AdminClient, streamId iggcon.Identifier, streamSpec StreamSpec, topics []iggcon.Topic) ([]Change, error) {
+	existing := make(map[string]iggcon.Topic, len(topics))
+	for _, topic := range topics {
+		existing[topic.Name] = topic
+	}
+
+	var changes []Change
+	for _, topicSpec := range streamSpec.Topics {
+		topic, ok := existing[topicSpec.Name]
+		if !ok {
+			changes = append(changes, createTopic(streamSpec, topicSpec)...)
+			continue
+		}
+		if topicSpec.Partitions != 0 && topicSpec.Partitions < topic.PartitionsCount {
+			return nil, fmt.Errorf("topic %s/%s has %d partitions, more than the %d specified",
+				streamSpec.Name, topicSpec.Name, topic.PartitionsCount, topicSpec.Partitions)
+		}
+		if topicSpec.Partitions > topic.PartitionsCount {
+			changes = append(changes, Change{
+				Kind:       AddPartitions,
+				Stream:     streamSpec,
+				Topic:      topicSpec,
+				Partitions: topicSpec.Partitions - topic.PartitionsCount,
+			})
+		}
+		if topicDiffers(topicSpec, topic) {
+			changes = append(changes, Change{Kind: UpdateTopic, Stream: streamSpec, Topic: topicSpec, current: topic})
+		}
+		if len(topicSpec.ConsumerGroups) > 0 {
+			groupChanges, err := planConsumerGroups(client, streamId, streamSpec, topicSpec, topic.Id)
+			if err != nil {
+				return nil, err
+			}
+			changes = append(changes, groupChanges...)
+		}
+	}
+	return changes, nil
+}
+
+func planConsumerGroups(client messengercli.AdminClient, streamId iggcon.Identifier, streamSpec StreamSpec, topicSpec TopicSpec, topic uint32) ([]Change, error) {
+	topicId, err := iggcon.NewIdentifier(topic)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := client.GetConsumerGroups(streamId, topicId)
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]bool, len(groups))
+	for _, group := range groups {
+		existing[group.Name] = true
+	}
+	var changes []Change
+	for _, group := range topicSpec.ConsumerGroups {
+		if !existing[group] {
+			changes = append(changes, Change{Kind: CreateConsumerGroup, Stream: streamSpec, Topic: topicSpec, ConsumerGroup: group})
+		}
+	}
+	return changes, nil
+}
+
+func topicDiffers(spec TopicSpec, topic iggcon.Topic) bool {
+	return spec.CompressionAlgorithm != 0 && uint8(spec.CompressionAlgorithm) != topic.CompressionAlgorithm ||
+		spec.MessageExpiry != 0 && spec.MessageExpiry != topic.MessageExpiry ||
+		spec.MaxTopicSize != 0 && spec.MaxTopicSize != topic.MaxTopicSize ||
+		spec.ReplicationFactor != nil && *spec.ReplicationFactor != topic.ReplicationFactor
+}
+
+// Provision applies the changes planned for the spec, stopping at the first failure or when the
+// context is done, and returns the changes applied. It is idempotent: provisioning the same spec
+// again applies no change, so services can run it on every start.
+func Provision(ctx context.Context, client messengercli.AdminClient, spec Spec) ([]Change, error) {
+	changes, err := Plan(client, spec)
+	if err != nil {
+		return nil, err
+	}
+	for i, change := range changes {
+		if err := ctx.Err(); err != nil {
+			return changes[:i], err
+		}
+		if err := apply(ctx, client, change); err != nil {
+			return changes[:i], fmt.Errorf("%s: %w", change, err)
+		}
+	}
+	return changes, nil
+}
+
+func apply(ctx context.Context, client messengercli.AdminClient, change Change) error {
+	if change.Kind == CreateStream {
+		_, err := client.CreateStream(change.Stream.Name, change.Stream.Id)
+		return err
+	}
+
+	streamId, err := iggcon.NewIdentifier(change.Stream.Name)
+	if err != nil {
+		return err
+	}
+	topicId, err := iggcon.NewIdentifier(change.Topic.Name)
+	if err != nil {
+		return err
+	}
+	switch change.Kind {
+	case CreateTopic:
+		_, err = messengercli.CreateTopic(ctx, client, iggcon.CreateTopicRequest{
+			StreamId:             streamId,
+			Name:                 change.Topic.Name,
+			PartitionsCount:      change.Topic.Partitions,
+			CompressionAlgorithm: change.Topic.CompressionAlgorithm,
+			MessageExpiry:        change.Topic.MessageExpiry,
+			MaxTopicSize:         change.Topic.MaxTopicSize,
+			ReplicationFactor:    change.Topic.ReplicationFactor,
+			TopicId:              change.Topic.Id,
+		})
+	case AddPartitions:
+		err = client.CreatePartitions(streamId, topicId, change.Partitions)
+	case UpdateTopic:
+		err = messengercli.UpdateTopic(ctx, client, updateTopicRequest(streamId, topicId, change.Topic, change.current))
+	case CreateConsumerGroup:
+		_, err = client.CreateConsumerGroup(streamId, topicId, change.ConsumerGroup, nil)
+	}
+	return err
+}
+
+// updateTopicRequest keeps the current settings of the topic the spec leaves to the server.
+func updateTopicRequest(streamId, topicId iggcon.Identifier, spec TopicSpec, current iggcon.Topic) iggcon.UpdateTopicRequest {
+	request := iggcon.UpdateTopicRequest{
+		StreamId:             streamId,
+		TopicId:              topicId,
+		Name:                 current.Name,
+		CompressionAlgorithm: iggcon.CompressionAlgorithm(current.CompressionAlgorithm),
+		MessageExpiry:        current.MessageExpiry,
+		MaxTopicSize:         current.MaxTopicSize,
+		ReplicationFactor:    &current.ReplicationFactor,
+	}
+	if spec.CompressionAlgorithm != 0 {
+		request.CompressionAlgorithm = spec.CompressionAlgorithm
+	}
+	if spec.MessageExpiry != 0 {
+		request.MessageExpiry = spec.MessageExpiry
+	}
+	if spec.MaxTopicSize != 0 {
+		request.MaxTopicSize = spec.MaxTopicSize
+	}
+	if spec.ReplicationFactor != nil {
+		request.ReplicationFactor = spec.ReplicationFactor
+	}
+	return request
+}
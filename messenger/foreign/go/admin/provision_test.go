@@ -0,0 +1,81 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package admin
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengertest"
+)
+
+func TestProvision(t *testing.T) {
+	client := messengertest.NewClient()
+	if _, err := client.CreateStream("orders", nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.CreateTopic(iggcon.MustIdentifier("orders"), "created", 2, iggcon.CompressionAlgorithmNone, 0, 0, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	spec := Spec{Streams: []StreamSpec{
+		{Name: "orders", Topics: []TopicSpec{
+			{Name: "created", Partitions: 4, MessageExpiry: iggcon.ExpiryAfter(24 * time.Hour)},
+			{Name: "shipped"},
+		}},
+		{Name: "billing", Topics: []TopicSpec{{Name: "invoices", Partitions: 3, MaxTopicSize: 10 * iggcon.GiB}}},
+	}}
+	changes, err := Provision(context.Background(), client, spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var applied []string
+	for _, change := range changes {
+		applied = append(applied, change.String())
+	}
+	expected := []string{
+		"add 2 partition(s) to topic orders/created",
+		"update topic orders/created",
+		"create topic orders/shipped",
+		"create stream billing",
+		"create topic billing/invoices",
+	}
+	if strings.Join(applied, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("expected the changes\n%s\ngot\n%s", strings.Join(expected, "\n"), strings.Join(applied, "\n"))
+	}
+
+	topic, err := client.GetTopic(iggcon.MustIdentifier("orders"), iggcon.MustIdentifier("created"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if topic.PartitionsCount != 4 || topic.MessageExpiry.Duration() != 24*time.Hour {
+		t.Fatalf("expected 4 partitions and a 24h expiry, got %d and %v", topic.PartitionsCount, topic.MessageExpiry)
+	}
+
+	if changes, err = Provision(context.Background(), client, spec); err != nil || len(changes) != 0 {
+		t.Fatalf("expected provisioning again to change nothing, got %v, %v", changes, err)
+	}
+
+	spec.Streams[0].Topics[0].Partitions = 1
+	if _, err = Plan(client, spec); err == nil {
+		t.Fatal("expected a spec removing partitions to be rejected")
+	}
+}
@@ -0,0 +1,159 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package admin
+
+import (
+	"context"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengercli"
+)
+
+// defaultReplayBatchSize is the number of messages polled and sent at once when ReplayRequest.BatchSize is 0.
+const defaultReplayBatchSize = 100
+
+// Bound is a bound of the range of messages replayed, an offset or a timestamp. The zero Bound
+// is the start or the end of the partition.
+type Bound struct {
+	kind  iggcon.MessagePolling
+	value uint64
+}
+
+// AtOffset returns the Bound at the message with the given offset.
+func AtOffset(offset uint64) Bound {
+	return Bound{kind: iggcon.POLLING_OFFSET, value: offset}
+}
+
+// AtTime returns the Bound at the first message appended at or after t.
+func AtTime(t time.Time) Bound {
+	return Bound{kind: iggcon.POLLING_TIMESTAMP, value: uint64(t.UnixMicro())}
+}
+
+func (b Bound) strategy() iggcon.PollingStrategy {
+	if b.kind == 0 {
+		return iggcon.OffsetPollingStrategy(0)
+	}
+	return iggcon.NewPollingStrategy(b.kind, b.value)
+}
+
+// reached tells whether the message is at or past the bound.
+func (b Bound) reached(message iggcon.MessengerMessage) bool {
+	switch b.kind {
+	case iggcon.POLLING_OFFSET:
+		return message.Header.Offset >= b.value
+	case iggcon.POLLING_TIMESTAMP:
+		return message.Header.Timestamp >= b.value
+	}
+	return false
+}
+
+// ReplayRequest describes the messages copied by Replay, from a partition of a topic to another topic.
+type ReplayRequest struct {
+	StreamId    iggcon.Identifier
+	TopicId     iggcon.Identifier
+	PartitionId uint32
+	// From is the first message replayed, and To the message the replay stops at, excluded.
+	From Bound
+	To   Bound
+
+	DestinationStreamId iggcon.Identifier
+	DestinationTopicId  iggcon.Identifier
+	// Partitioning is how the messages are sent to the destination, balanced when unset.
+	Partitioning iggcon.Partitioning
+
+	// BatchSize is the number of messages polled and sent at once, 100 when 0.
+	BatchSize uint32
+	// Transform, when set, is called on every message before it is sent, to change its headers.
+	Transform func(message *iggcon.MessengerMessage) error
+	// Progress, when set, is called after every batch sent.
+	Progress func(progress ReplayProgress)
+}
+
+// ReplayProgress is how far a replay went. An interrupted replay is resumed by replaying again
+// From AtOffset(NextOffset).
+type ReplayProgress struct {
+	// NextOffset is the offset of the next message of the source to replay.
+	NextOffset uint64
+	// Replayed is the number of messages sent to the destination.
+	Replayed uint64
+}
+
+// Replay copies the messages of a partition to another topic, polling them without storing any
+// offset and sending them with their original ID and origin timestamp. The replay stops at the
+// To bound, or at the end of the partition. The progress is returned along with the error.
+func Replay(ctx context.Context, client messengercli.Client, req ReplayRequest) (ReplayProgress, error) {
+	batchSize := req.BatchSize
+	if batchSize == 0 {
+		batchSize = defaultReplayBatchSize
+	}
+	partitioning := req.Partitioning
+	if partitioning.Kind == 0 {
+		partitioning = iggcon.None()
+	}
+	progress := ReplayProgress{}
+	if req.From.kind == iggcon.POLLING_OFFSET {
+		progress.NextOffset = req.From.value
+	}
+
+	consumer := iggcon.DefaultConsumer()
+	strategy := req.From.strategy()
+	for {
+		if err := ctx.Err(); err != nil {
+			return progress, err
+		}
+		polled, err := client.PollMessages(req.StreamId, req.TopicId, consumer, strategy, batchSize, false, &req.PartitionId)
+		if err != nil {
+			return progress, err
+		}
+		if polled == nil || len(polled.Messages) == 0 {
+			return progress, nil
+		}
+
+		batch := make([]iggcon.MessengerMessage, 0, len(polled.Messages))
+		done := false
+		for _, message := range polled.Messages {
+			if req.To.reached(message) {
+				progress.NextOffset = message.Header.Offset
+				done = true
+				break
+			}
+			iggcon.WithTimestamp(time.UnixMicro(int64(message.Header.OriginTimestamp)))(&message)
+			if req.Transform != nil {
+				if err := req.Transform(&message); err != nil {
+					return progress, err
+				}
+			}
+			batch = append(batch, message)
+		}
+		if len(batch) > 0 {
+			if err := client.SendMessages(req.DestinationStreamId, req.DestinationTopicId, partitioning, batch); err != nil {
+				return progress, err
+			}
+			progress.NextOffset = polled.Messages[len(batch)-1].Header.Offset + 1
+			progress.Replayed += uint64(len(batch))
+			if req.Progress != nil {
+				req.Progress(progress)
+			}
+		}
+		if done {
+			return progress, nil
+		}
+		strategy = iggcon.OffsetPollingStrategy(progress.NextOffset)
+	}
+}
@@ -0,0 +1,98 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package admin
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengertest"
+)
+
+func TestReplay(t *testing.T) {
+	client := messengertest.NewClient()
+	spec := Spec{Streams: []StreamSpec{{Name: "orders", Topics: []TopicSpec{{Name: "created"}, {Name: "replayed"}}}}}
+	if _, err := Provision(context.Background(), client, spec); err != nil {
+		t.Fatal(err)
+	}
+	stream := iggcon.MustIdentifier("orders")
+	var messages []iggcon.MessengerMessage
+	for i := 0; i < 10; i++ {
+		message, err := iggcon.NewMessengerMessage([]byte(fmt.Sprintf("order %d", i)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		messages = append(messages, message)
+	}
+	if err := client.SendMessages(stream, iggcon.MustIdentifier("created"), iggcon.PartitionId(1), messages); err != nil {
+		t.Fatal(err)
+	}
+
+	replayed := iggcon.HeaderKey{Value: "replayed"}
+	req := ReplayRequest{
+		StreamId:            stream,
+		TopicId:             iggcon.MustIdentifier("created"),
+		PartitionId:         1,
+		From:                AtOffset(2),
+		To:                  AtOffset(7),
+		DestinationStreamId: stream,
+		DestinationTopicId:  iggcon.MustIdentifier("replayed"),
+		BatchSize:           2,
+		Transform: func(message *iggcon.MessengerMessage) error {
+			return message.SetUserHeaders(map[iggcon.HeaderKey]iggcon.HeaderValue{replayed: {Kind: iggcon.Bool, Value: []byte{1}}})
+		},
+	}
+	var reported []ReplayProgress
+	req.Progress = func(progress ReplayProgress) { reported = append(reported, progress) }
+	progress, err := Replay(context.Background(), client, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if progress != (ReplayProgress{NextOffset: 7, Replayed: 5}) || len(reported) != 3 {
+		t.Fatalf("expected 5 messages replayed in 3 batches, got %+v after %v", progress, reported)
+	}
+
+	req.From, req.To = AtOffset(progress.NextOffset), Bound{}
+	if progress, err = Replay(context.Background(), client, req); err != nil {
+		t.Fatal(err)
+	}
+	if progress != (ReplayProgress{NextOffset: 10, Replayed: 3}) {
+		t.Fatalf("expected the replay to resume up to the end of the partition, got %+v", progress)
+	}
+
+	polled, err := client.PollMessages(stream, iggcon.MustIdentifier("replayed"), iggcon.DefaultConsumer(), iggcon.FirstPollingStrategy(), 100, false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(polled.Messages) != 8 {
+		t.Fatalf("expected 8 messages replayed, got %d", len(polled.Messages))
+	}
+	for i, message := range polled.Messages {
+		if string(message.Payload) != fmt.Sprintf("order %d", i+2) {
+			t.Fatalf("expected order %d, got %s", i+2, message.Payload)
+		}
+		if _, ok := message.UserHeader("replayed"); !ok {
+			t.Fatalf("expected message %d to have the replayed header", i)
+		}
+		if message.Header.OriginTimestamp != messages[i+2].Header.OriginTimestamp {
+			t.Fatalf("expected message %d to keep its origin timestamp", i)
+		}
+	}
+}
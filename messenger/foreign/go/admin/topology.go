@@ -0,0 +1,168 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengercli"
+)
+
+// Topology is the document written by ExportTopology: every stream, topic, partition count,
+// consumer group and user of a server. It holds no secret, the passwords of the users being
+// provided anew to ImportTopology.
+type Topology struct {
+	Streams []StreamSpec `json:"streams"`
+	Users   []UserSpec   `json:"users,omitempty"`
+}
+
+// UserSpec describes a user without its password.
+type UserSpec struct {
+	Username    string              `json:"username"`
+	Status      iggcon.UserStatus   `json:"status"`
+	Permissions *iggcon.Permissions `json:"permissions,omitempty"`
+}
+
+// PasswordFunc returns the password a user is created with by ImportTopology.
+type PasswordFunc func(username string) (string, error)
+
+// GetTopology reads the topology of the server.
+func GetTopology(client messengercli.AdminClient) (*Topology, error) {
+	streams, err := client.GetStreams()
+	if err != nil {
+		return nil, err
+	}
+	topology := &Topology{}
+	for _, stream := range streams {
+		streamSpec, err := getStreamSpec(client, stream)
+		if err != nil {
+			return nil, err
+		}
+		topology.Streams = append(topology.Streams, streamSpec)
+	}
+
+	users, err := client.GetUsers()
+	if err != nil {
+		return nil, err
+	}
+	for _, user := range users {
+		userId, err := iggcon.NewIdentifier(user.Id)
+		if err != nil {
+			return nil, err
+		}
+		details, err := client.GetUser(userId)
+		if err != nil {
+			return nil, err
+		}
+		topology.Users = append(topology.Users, UserSpec{Username: user.Username, Status: user.Status, Permissions: details.Permissions})
+	}
+	return topology, nil
+}
+
+func getStreamSpec(client messengercli.AdminClient, stream iggcon.Stream) (StreamSpec, error) {
+	streamId, err := iggcon.NewIdentifier(stream.Id)
+	if err != nil {
+		return StreamSpec{}, err
+	}
+	topics, err := client.GetTopics(streamId)
+	if err != nil {
+		return StreamSpec{}, err
+	}
+	streamSpec := StreamSpec{Name: stream.Name, Id: &stream.Id}
+	for _, topic := range topics {
+		topicId, err := iggcon.NewIdentifier(topic.Id)
+		if err != nil {
+			return StreamSpec{}, err
+		}
+		groups, err := client.GetConsumerGroups(streamId, topicId)
+		if err != nil {
+			return StreamSpec{}, err
+		}
+		topicSpec := TopicSpec{
+			Name:                 topic.Name,
+			Id:                   &topic.Id,
+			Partitions:           topic.PartitionsCount,
+			CompressionAlgorithm: iggcon.CompressionAlgorithm(topic.CompressionAlgorithm),
+			MessageExpiry:        topic.MessageExpiry,
+			MaxTopicSize:         topic.MaxTopicSize,
+		}
+		if topic.ReplicationFactor != 0 {
+			topicSpec.ReplicationFactor = &topic.ReplicationFactor
+		}
+		for _, group := range groups {
+			topicSpec.ConsumerGroups = append(topicSpec.ConsumerGroups, group.Name)
+		}
+		streamSpec.Topics = append(streamSpec.Topics, topicSpec)
+	}
+	return streamSpec, nil
+}
+
+// ExportTopology writes the topology of the server to w as JSON, to be recreated on another
+// server by ImportTopology.
+func ExportTopology(client messengercli.AdminClient, w io.Writer) error {
+	topology, err := GetTopology(client)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(topology)
+}
+
+// ImportTopology reads a topology written by ExportTopology and provisions it like Provision,
+// then creates the users missing from the server with the passwords returned by password. The
+// users are not imported when password is nil, and the existing users are left untouched.
+func ImportTopology(ctx context.Context, client messengercli.AdminClient, r io.Reader, password PasswordFunc) ([]Change, error) {
+	var topology Topology
+	if err := json.NewDecoder(r).Decode(&topology); err != nil {
+		return nil, fmt.Errorf("invalid topology: %w", err)
+	}
+	changes, err := Provision(ctx, client, Spec{Streams: topology.Streams})
+	if err != nil || password == nil {
+		return changes, err
+	}
+
+	users, err := client.GetUsers()
+	if err != nil {
+		return changes, err
+	}
+	existing := make(map[string]bool, len(users))
+	for _, user := range users {
+		existing[user.Username] = true
+	}
+	for _, user := range topology.Users {
+		if existing[user.Username] {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return changes, err
+		}
+		secret, err := password(user.Username)
+		if err != nil {
+			return changes, fmt.Errorf("create user %s: %w", user.Username, err)
+		}
+		if _, err = client.CreateUser(user.Username, secret, user.Status, user.Permissions); err != nil {
+			return changes, fmt.Errorf("create user %s: %w", user.Username, err)
+		}
+	}
+	return changes, nil
+}
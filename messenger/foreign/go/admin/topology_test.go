@@ -0,0 +1,90 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package admin
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengertest"
+)
+
+func TestExportImportTopology(t *testing.T) {
+	source := messengertest.NewClient()
+	spec := Spec{Streams: []StreamSpec{
+		{Name: "orders", Topics: []TopicSpec{
+			{Name: "created", Partitions: 3, ConsumerGroups: []string{"billing", "shipping"}},
+			{Name: "shipped", MaxTopicSize: 5 * iggcon.GiB},
+		}},
+	}}
+	if _, err := Provision(context.Background(), source, spec); err != nil {
+		t.Fatal(err)
+	}
+	permissions := &iggcon.Permissions{Global: iggcon.GlobalPermissions{ReadServers: true}}
+	if _, err := source.CreateUser("auditor", "s3cr3t", iggcon.Active, permissions); err != nil {
+		t.Fatal(err)
+	}
+
+	var document bytes.Buffer
+	if err := ExportTopology(source, &document); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(document.String(), "s3cr3t") {
+		t.Fatal("expected the export not to contain any password")
+	}
+
+	target := messengertest.NewClient()
+	password := func(username string) (string, error) { return username + "-password", nil }
+	changes, err := ImportTopology(context.Background(), target, bytes.NewReader(document.Bytes()), password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 5 {
+		t.Fatalf("expected 5 changes, got %v", changes)
+	}
+
+	topic, err := target.GetTopic(iggcon.MustIdentifier("orders"), iggcon.MustIdentifier("created"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if topic.PartitionsCount != 3 {
+		t.Fatalf("expected 3 partitions, got %d", topic.PartitionsCount)
+	}
+	groups, err := target.GetConsumerGroups(iggcon.MustIdentifier("orders"), iggcon.MustIdentifier("created"))
+	if err != nil || len(groups) != 2 {
+		t.Fatalf("expected 2 consumer groups, got %v, %v", groups, err)
+	}
+	user, err := target.GetUser(iggcon.MustIdentifier("auditor"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Permissions == nil || !user.Permissions.Global.ReadServers {
+		t.Fatalf("expected the permissions to be imported, got %+v", user.Permissions)
+	}
+	if _, err = target.LoginUser("auditor", "auditor-password"); err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err = ImportTopology(context.Background(), target, bytes.NewReader(document.Bytes()), password)
+	if err != nil || len(changes) != 0 {
+		t.Fatalf("expected importing again to change nothing, got %v, %v", changes, err)
+	}
+}
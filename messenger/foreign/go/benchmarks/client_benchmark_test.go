@@ -0,0 +1,180 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package benchmarks
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	binaryserialization "github.com/apache/messenger/foreign/go/binary_serialization"
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengercli"
+	"github.com/apache/messenger/foreign/go/messengertest"
+	"github.com/apache/messenger/foreign/go/tcp"
+)
+
+const (
+	benchBatchSize   = 100
+	benchMessageSize = 1000
+)
+
+// newBenchClient connects to the broker at MESSENGER_BENCH_ADDRESS, or to an in-process
+// messengertest server when unset, and creates a topic removed once the benchmark is done.
+func newBenchClient(b *testing.B) (messengercli.Client, iggcon.Identifier, iggcon.Identifier) {
+	b.Helper()
+	address := os.Getenv("MESSENGER_BENCH_ADDRESS")
+	if address == "" {
+		address = messengertest.StartServer(b).Addr()
+	}
+	cli, err := messengercli.NewMessengerClient(messengercli.WithTcp(tcp.WithServerAddress(address)))
+	if err != nil {
+		b.Fatal(err)
+	}
+	if _, err = cli.LoginUser("messenger", "messenger"); err != nil {
+		b.Fatal(err)
+	}
+
+	name := fmt.Sprintf("bench-%d", time.Now().UnixNano())
+	if _, err = cli.CreateStream(name, nil); err != nil {
+		b.Fatal(err)
+	}
+	streamId, _ := iggcon.NewIdentifier(name)
+	b.Cleanup(func() {
+		_ = cli.DeleteStream(streamId)
+	})
+	if _, err = cli.CreateTopic(streamId, "bench", 1, iggcon.CompressionAlgorithmNone, iggcon.ExpiryServerDefault, 0, nil, nil); err != nil {
+		b.Fatal(err)
+	}
+	topicId, _ := iggcon.NewIdentifier("bench")
+	return cli, streamId, topicId
+}
+
+func BenchmarkSerialization(b *testing.B) {
+	messages := CreateMessages(benchBatchSize, benchMessageSize)
+	streamId, _ := iggcon.NewIdentifier(uint32(1))
+	topicId, _ := iggcon.NewIdentifier(uint32(1))
+	b.Run("send", func(b *testing.B) {
+		request := binaryserialization.TcpSendMessagesRequest{
+			StreamId:     streamId,
+			TopicId:      topicId,
+			Partitioning: iggcon.None(),
+			Messages:     messages,
+		}
+		b.SetBytes(benchBatchSize * benchMessageSize)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			binaryserialization.PutBuffer(request.Serialize(iggcon.MESSAGE_COMPRESSION_NONE))
+		}
+	})
+	b.Run("poll", func(b *testing.B) {
+		payload := encodePolledMessages(messages)
+		b.SetBytes(int64(len(payload)))
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := binaryserialization.DeserializeFetchMessagesResponseWithDialect(payload, iggcon.MESSAGE_COMPRESSION_NONE, iggcon.MessengerDialect); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// encodePolledMessages encodes a poll response of partition 1 holding the messages.
+func encodePolledMessages(messages []iggcon.MessengerMessage) []byte {
+	payload := binary.LittleEndian.AppendUint32(nil, 1)
+	payload = binary.LittleEndian.AppendUint64(payload, uint64(len(messages)-1))
+	payload = binary.LittleEndian.AppendUint32(payload, uint32(len(messages)))
+	padding := make([]byte, iggcon.MessengerDialect.MessageHeaderSize-iggcon.MessageHeaderSize)
+	for i, message := range messages {
+		message.Header.Offset = uint64(i)
+		payload = append(payload, message.Header.ToBytes()...)
+		payload = append(payload, padding...)
+		payload = append(payload, message.Payload...)
+		payload = append(payload, message.UserHeaders...)
+	}
+	return payload
+}
+
+func BenchmarkSendThroughput(b *testing.B) {
+	cli, streamId, topicId := newBenchClient(b)
+	messages := CreateMessages(benchBatchSize, benchMessageSize)
+	b.SetBytes(benchBatchSize * benchMessageSize)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := cli.SendMessages(streamId, topicId, iggcon.PartitionId(1), messages); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(float64(b.N*benchBatchSize)/b.Elapsed().Seconds(), "msgs/s")
+}
+
+func BenchmarkPollThroughput(b *testing.B) {
+	cli, streamId, topicId := newBenchClient(b)
+	messages := CreateMessages(benchBatchSize, benchMessageSize)
+	for i := 0; i < b.N; i++ {
+		if err := cli.SendMessages(streamId, topicId, iggcon.PartitionId(1), messages); err != nil {
+			b.Fatal(err)
+		}
+	}
+	partition := uint32(1)
+	b.SetBytes(benchBatchSize * benchMessageSize)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		polled, err := cli.PollMessages(streamId, topicId, iggcon.DefaultConsumer(), iggcon.OffsetPollingStrategy(uint64(i*benchBatchSize)), benchBatchSize, false, &partition)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(polled.Messages) != benchBatchSize {
+			b.Fatalf("expected %d messages, got %d", benchBatchSize, len(polled.Messages))
+		}
+	}
+	b.ReportMetric(float64(b.N*benchBatchSize)/b.Elapsed().Seconds(), "msgs/s")
+}
+
+// BenchmarkEndToEndLatency measures the time from sending a message to polling it, reporting
+// the median and the 99th percentile.
+func BenchmarkEndToEndLatency(b *testing.B) {
+	cli, streamId, topicId := newBenchClient(b)
+	messages := CreateMessages(1, benchMessageSize)
+	partition := uint32(1)
+	latencies := make([]time.Duration, 0, b.N)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		start := time.Now()
+		if err := cli.SendMessages(streamId, topicId, iggcon.PartitionId(1), messages); err != nil {
+			b.Fatal(err)
+		}
+		for {
+			polled, err := cli.PollMessages(streamId, topicId, iggcon.DefaultConsumer(), iggcon.OffsetPollingStrategy(uint64(i)), 1, false, &partition)
+			if err != nil {
+				b.Fatal(err)
+			}
+			if len(polled.Messages) > 0 {
+				break
+			}
+		}
+		latencies = append(latencies, time.Since(start))
+	}
+	b.StopTimer()
+	slices.Sort(latencies)
+	b.ReportMetric(float64(latencies[len(latencies)/2].Microseconds()), "p50-us")
+	b.ReportMetric(float64(latencies[len(latencies)*99/100].Microseconds()), "p99-us")
+}
@@ -0,0 +1,138 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package benchmarks holds the benchmarks of the SDK: serialization, send and poll throughput
+// and end-to-end latency. The client benchmarks run against the broker at MESSENGER_BENCH_ADDRESS,
+// or an in-process messengertest server when unset, so they are reproducible without a broker:
+//
+//	go test ./benchmarks -run '^$' -bench 'Serialization|Throughput|Latency' -count 5 > head.txt
+//
+// The results of two runs are compared by the benchcompare command, failing when a benchmark
+// regressed more than a threshold.
+package benchmarks
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Result is the measurements of a benchmark, averaged over its runs.
+type Result struct {
+	Name string
+	Runs int
+	// Metrics maps the units (ns/op, B/op, allocs/op, MB/s or custom ones) to their average value.
+	Metrics map[string]float64
+}
+
+// ParseResults reads the output of go test -bench, averaging the runs of every benchmark, e.g.
+// with -count. The GOMAXPROCS suffix is removed from the names.
+func ParseResults(r io.Reader) (map[string]Result, error) {
+	sums := map[string]*Result{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 || !strings.HasPrefix(fields[0], "Benchmark") || len(fields)%2 != 0 {
+			continue
+		}
+		if _, err := strconv.Atoi(fields[1]); err != nil {
+			continue
+		}
+		name := fields[0]
+		if i := strings.LastIndexByte(name, '-'); i > 0 {
+			if _, err := strconv.Atoi(name[i+1:]); err == nil {
+				name = name[:i]
+			}
+		}
+		result, ok := sums[name]
+		if !ok {
+			result = &Result{Name: name, Metrics: map[string]float64{}}
+			sums[name] = result
+		}
+		result.Runs++
+		for i := 2; i < len(fields); i += 2 {
+			value, err := strconv.ParseFloat(fields[i], 64)
+			if err != nil {
+				return nil, fmt.Errorf("benchmarks: invalid value %q of %s", fields[i], name)
+			}
+			result.Metrics[fields[i+1]] += value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	results := make(map[string]Result, len(sums))
+	for name, result := range sums {
+		for unit, sum := range result.Metrics {
+			result.Metrics[unit] = sum / float64(result.Runs)
+		}
+		results[name] = *result
+	}
+	return results, nil
+}
+
+// Comparison is the change of a metric of a benchmark between two runs.
+type Comparison struct {
+	Name string
+	Unit string
+	Base float64
+	Head float64
+	// Delta is the relative change, positive when the head is worse.
+	Delta float64
+}
+
+// Regressed reports whether the head is worse than the base by more than threshold, e.g. 0.1
+// for 10%.
+func (c Comparison) Regressed(threshold float64) bool {
+	return c.Delta > threshold
+}
+
+// higherIsBetter lists the units of throughputs, for which a decrease is a regression.
+var higherIsBetter = map[string]bool{"MB/s": true, "msgs/s": true}
+
+// Compare compares the metrics of the benchmarks present in both runs, sorted by name and unit.
+func Compare(base, head map[string]Result) []Comparison {
+	var comparisons []Comparison
+	for name, h := range head {
+		b, ok := base[name]
+		if !ok {
+			continue
+		}
+		for unit, headValue := range h.Metrics {
+			baseValue, ok := b.Metrics[unit]
+			if !ok || baseValue == 0 {
+				continue
+			}
+			delta := (headValue - baseValue) / baseValue
+			if higherIsBetter[unit] {
+				delta = -delta
+			}
+			comparisons = append(comparisons, Comparison{Name: name, Unit: unit, Base: baseValue, Head: headValue, Delta: delta})
+		}
+	}
+	sort.Slice(comparisons, func(i, j int) bool {
+		if comparisons[i].Name != comparisons[j].Name {
+			return comparisons[i].Name < comparisons[j].Name
+		}
+		return comparisons[i].Unit < comparisons[j].Unit
+	})
+	return comparisons
+}
@@ -0,0 +1,57 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package benchmarks
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseAndCompare(t *testing.T) {
+	base, err := ParseResults(strings.NewReader(`goos: linux
+BenchmarkSendThroughput-8   	    5000	    200000 ns/op	 500.00 MB/s	  500000 msgs/s
+BenchmarkSendThroughput-8   	    5000	    220000 ns/op	 460.00 MB/s	  460000 msgs/s
+BenchmarkSerialization/send-8   	   20000	     50000 ns/op	  4 allocs/op
+PASS
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	send := base["BenchmarkSendThroughput"]
+	if send.Runs != 2 || send.Metrics["ns/op"] != 210000 || send.Metrics["MB/s"] != 480 {
+		t.Fatalf("expected the runs to be averaged, got %+v", send)
+	}
+
+	head, err := ParseResults(strings.NewReader(`BenchmarkSendThroughput-16   5000   210000 ns/op   360.00 MB/s
+BenchmarkSerialization/send-16   20000   51000 ns/op   4 allocs/op
+BenchmarkNew-16   1   1 ns/op
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	regressed := map[string]bool{}
+	comparisons := Compare(base, head)
+	for _, c := range comparisons {
+		if c.Regressed(0.1) {
+			regressed[c.Name+" "+c.Unit] = true
+		}
+	}
+	if len(comparisons) != 4 || len(regressed) != 1 || !regressed["BenchmarkSendThroughput MB/s"] {
+		t.Fatalf("expected only the throughput drop to regress, got %+v", comparisons)
+	}
+}
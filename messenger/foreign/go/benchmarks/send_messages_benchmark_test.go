@@ -119,7 +119,7 @@ func ensureInfrastructureIsInitialized(cli messengercli.Client, streamId uint32)
 			"benchmark",
 			1,
 			iggcon.CompressionAlgorithmNone,
-			iggcon.MessengerExpiryServerDefault,
+			iggcon.ExpiryServerDefault,
 			1,
 			nil,
 			nil,
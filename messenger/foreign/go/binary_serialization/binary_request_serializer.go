@@ -19,67 +19,11 @@ package binaryserialization
 
 import (
 	"encoding/binary"
+	"sort"
 
 	iggcon "github.com/apache/messenger/foreign/go/contracts"
 )
 
-func CreateGroup(request iggcon.CreateConsumerGroupRequest) []byte {
-	if request.ConsumerGroupId == nil {
-		request.ConsumerGroupId = new(uint32)
-	}
-	customIdOffset := 4 + request.StreamId.Length + request.TopicId.Length
-	bytes := make([]byte, 4+request.StreamId.Length+request.TopicId.Length+1+4+len(request.Name))
-	copy(bytes[0:customIdOffset], SerializeIdentifiers(request.StreamId, request.TopicId))
-	binary.LittleEndian.PutUint32(bytes[customIdOffset:customIdOffset+4], *request.ConsumerGroupId)
-	bytes[customIdOffset+4] = byte(len(request.Name))
-	copy(bytes[customIdOffset+5:], request.Name)
-	return bytes
-}
-
-func UpdateOffset(request iggcon.StoreConsumerOffsetRequest) []byte {
-	if request.PartitionId == nil {
-		request.PartitionId = new(uint32)
-	}
-	bytes := make([]byte, 6+request.StreamId.Length+request.TopicId.Length+request.Consumer.Id.Length+13)
-	bytes[0] = byte(request.Consumer.Kind)
-	position := 7 + request.StreamId.Length + request.TopicId.Length + request.Consumer.Id.Length
-	copy(bytes[1:position], SerializeIdentifiers(request.Consumer.Id, request.StreamId, request.TopicId))
-
-	binary.LittleEndian.PutUint32(bytes[position:position+4], *request.PartitionId)
-	binary.LittleEndian.PutUint64(bytes[position+4:position+12], uint64(request.Offset))
-	return bytes
-}
-
-func GetOffset(request iggcon.GetConsumerOffsetRequest) []byte {
-	if request.PartitionId == nil {
-		request.PartitionId = new(uint32)
-	}
-	bytes := make([]byte, 6+request.StreamId.Length+request.TopicId.Length+request.Consumer.Id.Length+5)
-	bytes[0] = byte(request.Consumer.Kind)
-	position := 7 + request.StreamId.Length + request.TopicId.Length + request.Consumer.Id.Length
-	copy(bytes[1:position], SerializeIdentifiers(request.Consumer.Id, request.StreamId, request.TopicId))
-	binary.LittleEndian.PutUint32(bytes[position:position+4], *request.PartitionId)
-	return bytes
-}
-
-func CreatePartitions(request iggcon.CreatePartitionsRequest) []byte {
-	bytes := make([]byte, 8+request.StreamId.Length+request.TopicId.Length)
-	position := 4 + request.StreamId.Length + request.TopicId.Length
-	copy(bytes[0:position], SerializeIdentifiers(request.StreamId, request.TopicId))
-	binary.LittleEndian.PutUint32(bytes[position:position+4], uint32(request.PartitionsCount))
-
-	return bytes
-}
-
-func DeletePartitions(request iggcon.DeletePartitionsRequest) []byte {
-	bytes := make([]byte, 8+request.StreamId.Length+request.TopicId.Length)
-	position := 4 + request.StreamId.Length + request.TopicId.Length
-	copy(bytes[0:position], SerializeIdentifiers(request.StreamId, request.TopicId))
-	binary.LittleEndian.PutUint32(bytes[position:position+4], uint32(request.PartitionsCount))
-
-	return bytes
-}
-
 //USERS
 
 func SerializeCreateUserRequest(request iggcon.CreateUserRequest) []byte {
@@ -220,7 +164,8 @@ func boolToByte(b bool) byte {
 }
 
 func SerializeUpdateUser(request iggcon.UpdateUserRequest) []byte {
-	length := request.UserID.Length + 2
+	// the identifier, then the username and the status, each preceded by a presence flag
+	length := request.UserID.Length + 2 + 2
 
 	if request.Username == nil {
 		request.Username = new(string)
@@ -229,18 +174,18 @@ func SerializeUpdateUser(request iggcon.UpdateUserRequest) []byte {
 	username := *request.Username
 
 	if len(username) != 0 {
-		length += 2 + len(username)
+		length += 1 + len(username)
 	}
 
 	if request.Status != nil {
-		length += 2
+		length++
 	}
 
-	bytes := make([]byte, length+1)
+	bytes := make([]byte, length)
 	position := 0
 
 	copy(bytes[position:position+request.UserID.Length+2], SerializeIdentifier(request.UserID))
-	position += position + request.UserID.Length + 2
+	position += request.UserID.Length + 2
 
 	if len(username) != 0 {
 		bytes[position] = 1
@@ -272,26 +217,6 @@ func SerializeUpdateUser(request iggcon.UpdateUserRequest) []byte {
 	return bytes
 }
 
-func SerializeChangePasswordRequest(request iggcon.ChangePasswordRequest) []byte {
-	length := request.UserID.Length + 2 + len(request.CurrentPassword) + len(request.NewPassword) + 2
-	bytes := make([]byte, length)
-	position := 0
-
-	copy(bytes[position:position+request.UserID.Length+2], SerializeIdentifier(request.UserID))
-	position += request.UserID.Length + 2
-
-	bytes[position] = byte(len(request.CurrentPassword))
-	position++
-	copy(bytes[position:position+len(request.CurrentPassword)], []byte(request.CurrentPassword))
-	position += len(request.CurrentPassword)
-
-	bytes[position] = byte(len(request.NewPassword))
-	position++
-	copy(bytes[position:position+len(request.NewPassword)], []byte(request.NewPassword))
-
-	return bytes
-}
-
 func SerializeUpdateUserPermissionsRequest(request iggcon.UpdatePermissionsRequest) []byte {
 	length := request.UserID.Length + 2
 
@@ -325,27 +250,36 @@ func SerializeUint32(value uint32) []byte {
 	return bytes
 }
 
-func SerializeLoginWithPersonalAccessToken(request iggcon.LoginWithPersonalAccessTokenRequest) []byte {
-	length := 1 + len(request.Token)
-	bytes := make([]byte, length)
-	bytes[0] = byte(len(request.Token))
-	copy(bytes[1:], []byte(request.Token))
-	return bytes
-}
-
-func SerializeDeletePersonalAccessToken(request iggcon.DeletePersonalAccessTokenRequest) []byte {
-	length := 1 + len(request.Name)
+func SerializeCreatePersonalAccessToken(request iggcon.CreatePersonalAccessTokenRequest) []byte {
+	length := 1 + len(request.Name) + 8
 	bytes := make([]byte, length)
 	bytes[0] = byte(len(request.Name))
 	copy(bytes[1:], []byte(request.Name))
+	binary.LittleEndian.PutUint32(bytes[len(bytes)-4:], request.Expiry)
 	return bytes
 }
 
-func SerializeCreatePersonalAccessToken(request iggcon.CreatePersonalAccessTokenRequest) []byte {
-	length := 1 + len(request.Name) + 8
+func SerializeUpdateClientLabels(request iggcon.UpdateClientLabelsRequest) []byte {
+	keys := make([]string, 0, len(request.Labels))
+	length := 4
+	for key, value := range request.Labels {
+		keys = append(keys, key)
+		length += 2 + len(key) + len(value)
+	}
+	// sorted to keep the payload deterministic
+	sort.Strings(keys)
+
 	bytes := make([]byte, length)
-	bytes[0] = byte(len(request.Name))
-	copy(bytes[1:], []byte(request.Name))
-	binary.LittleEndian.PutUint32(bytes[len(bytes)-4:], request.Expiry)
+	binary.LittleEndian.PutUint32(bytes[:4], uint32(len(keys)))
+	position := 4
+	for _, key := range keys {
+		value := request.Labels[key]
+		bytes[position] = byte(len(key))
+		copy(bytes[position+1:], key)
+		position += 1 + len(key)
+		bytes[position] = byte(len(value))
+		copy(bytes[position+1:], value)
+		position += 1 + len(value)
+	}
 	return bytes
 }
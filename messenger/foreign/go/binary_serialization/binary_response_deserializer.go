@@ -25,7 +25,7 @@ import (
 	"time"
 
 	iggcon "github.com/apache/messenger/foreign/go/contracts"
-	ierror "github.com/apache/messenger/foreign/go/errors"
+	"github.com/apache/messenger/foreign/go/deprecation"
 	"github.com/klauspost/compress/s2"
 )
 
@@ -36,22 +36,6 @@ func DeserializeLogInResponse(payload []byte) *iggcon.IdentityInfo {
 	}
 }
 
-func DeserializeOffset(payload []byte) *iggcon.ConsumerOffsetInfo {
-	if len(payload) == 0 {
-		return nil
-	}
-
-	partitionId := binary.LittleEndian.Uint32(payload[0:4])
-	currentOffset := binary.LittleEndian.Uint64(payload[4:12])
-	storedOffset := binary.LittleEndian.Uint64(payload[12:20])
-
-	return &iggcon.ConsumerOffsetInfo{
-		PartitionId:   partitionId,
-		CurrentOffset: currentOffset,
-		StoredOffset:  storedOffset,
-	}
-}
-
 func DeserializeStream(payload []byte) (*iggcon.StreamDetails, error) {
 	stream, pos := DeserializeToStream(payload, 0)
 	topics := make([]iggcon.Topic, 0)
@@ -112,7 +96,18 @@ func DeserializeToStream(payload []byte, position int) (iggcon.Stream, int) {
 	}, readBytes
 }
 
+// Deprecated: Use DeserializeFetchMessagesResponseWithDialect, which supports Iggy-era servers.
 func DeserializeFetchMessagesResponse(payload []byte, compression iggcon.MessengerMessageCompression) (*iggcon.PolledMessage, error) {
+	deprecation.Report(deprecation.Notice{
+		API:         "binaryserialization.DeserializeFetchMessagesResponse",
+		Replacement: "binaryserialization.DeserializeFetchMessagesResponseWithDialect",
+		Hint:        "pass iggcon.MessengerDialect to keep the current behavior",
+	})
+	return DeserializeFetchMessagesResponseWithDialect(payload, compression, iggcon.MessengerDialect)
+}
+
+// DeserializeFetchMessagesResponseWithDialect deserializes polled messages using the message layout of the given dialect.
+func DeserializeFetchMessagesResponseWithDialect(payload []byte, compression iggcon.MessengerMessageCompression, dialect *iggcon.Dialect) (*iggcon.PolledMessage, error) {
 	if len(payload) == 0 {
 		return &iggcon.PolledMessage{
 			PartitionId:   0,
@@ -128,7 +123,7 @@ func DeserializeFetchMessagesResponse(payload []byte, compression iggcon.Messeng
 	position := 16
 	var messages = make([]iggcon.MessengerMessage, 0)
 	for position < length {
-		if position+iggcon.MessageHeaderSize >= length {
+		if position+dialect.MessageHeaderSize >= length {
 			// body needs to be at least 1 byte
 			break
 		}
@@ -136,7 +131,7 @@ func DeserializeFetchMessagesResponse(payload []byte, compression iggcon.Messeng
 		if err != nil {
 			return nil, err
 		}
-		position += iggcon.MessageHeaderSize
+		position += dialect.MessageHeaderSize
 		payload_end := position + int(header.PayloadLength)
 		if int(payload_end) > length {
 			break
@@ -219,9 +214,9 @@ func DeserializeToTopic(payload []byte, position int) (iggcon.Topic, int, error)
 	topic.Id = binary.LittleEndian.Uint32(payload[position : position+4])
 	topic.CreatedAt = binary.LittleEndian.Uint64(payload[position+4 : position+12])
 	topic.PartitionsCount = binary.LittleEndian.Uint32(payload[position+12 : position+16])
-	topic.MessageExpiry = iggcon.Duration(binary.LittleEndian.Uint64(payload[position+16 : position+24]))
+	topic.MessageExpiry = iggcon.Expiry(binary.LittleEndian.Uint64(payload[position+16 : position+24]))
 	topic.CompressionAlgorithm = payload[position+24]
-	topic.MaxTopicSize = binary.LittleEndian.Uint64(payload[position+25 : position+33])
+	topic.MaxTopicSize = iggcon.MaxTopicSize(binary.LittleEndian.Uint64(payload[position+25 : position+33]))
 	topic.ReplicationFactor = payload[position+33]
 	topic.Size = binary.LittleEndian.Uint64(payload[position+34 : position+42])
 	topic.MessagesCount = binary.LittleEndian.Uint64(payload[position+42 : position+50])
@@ -488,7 +483,18 @@ func deserializeToUser(payload []byte, position int) (*iggcon.UserInfo, int, err
 	}, readBytes, nil
 }
 
+// Deprecated: Use DeserializeClientsWithDialect, which supports Iggy-era servers.
 func DeserializeClients(payload []byte) ([]iggcon.ClientInfo, error) {
+	deprecation.Report(deprecation.Notice{
+		API:         "binaryserialization.DeserializeClients",
+		Replacement: "binaryserialization.DeserializeClientsWithDialect",
+		Hint:        "pass iggcon.MessengerDialect to keep the current behavior",
+	})
+	return DeserializeClientsWithDialect(payload, iggcon.MessengerDialect)
+}
+
+// DeserializeClientsWithDialect deserializes the clients, reading their labels when the dialect reports them.
+func DeserializeClientsWithDialect(payload []byte, dialect *iggcon.Dialect) ([]iggcon.ClientInfo, error) {
 	if len(payload) == 0 {
 		return []iggcon.ClientInfo{}, nil
 	}
@@ -498,7 +504,7 @@ func DeserializeClients(payload []byte) ([]iggcon.ClientInfo, error) {
 	position := 0
 
 	for position < length {
-		client, readBytes := MapClientInfo(payload, position)
+		client, readBytes := mapClientInfo(payload, position, dialect.ClientLabels)
 		response = append(response, client)
 		position += readBytes
 	}
@@ -506,7 +512,17 @@ func DeserializeClients(payload []byte) ([]iggcon.ClientInfo, error) {
 	return response, nil
 }
 
+// Deprecated: Use DeserializeClientsWithDialect, MapClientInfo cannot tell whether the client
+// info carries labels.
 func MapClientInfo(payload []byte, position int) (iggcon.ClientInfo, int) {
+	deprecation.Report(deprecation.Notice{
+		API:         "binaryserialization.MapClientInfo",
+		Replacement: "binaryserialization.DeserializeClientsWithDialect",
+	})
+	return mapClientInfo(payload, position, iggcon.MessengerDialect.ClientLabels)
+}
+
+func mapClientInfo(payload []byte, position int, withLabels bool) (iggcon.ClientInfo, int) {
 	var readBytes int
 	id := binary.LittleEndian.Uint32(payload[position : position+4])
 	userId := binary.LittleEndian.Uint32(payload[position+4 : position+8])
@@ -526,6 +542,26 @@ func MapClientInfo(payload []byte, position int) (iggcon.ClientInfo, int) {
 	position += readBytes
 	consumerGroupsCount := binary.LittleEndian.Uint32(payload[position : position+4])
 	readBytes += 4
+	position += 4
+
+	var labels map[string]string
+	if withLabels {
+		labelsCount := int(binary.LittleEndian.Uint32(payload[position : position+4]))
+		readBytes += 4
+		position += 4
+		if labelsCount > 0 {
+			labels = make(map[string]string, labelsCount)
+		}
+		for i := 0; i < labelsCount; i++ {
+			keyLength := int(payload[position])
+			key := string(payload[position+1 : position+1+keyLength])
+			position += 1 + keyLength
+			valueLength := int(payload[position])
+			labels[key] = string(payload[position+1 : position+1+valueLength])
+			position += 1 + valueLength
+			readBytes += 2 + keyLength + valueLength
+		}
+	}
 
 	return iggcon.ClientInfo{
 		ID:                  id,
@@ -533,11 +569,23 @@ func MapClientInfo(payload []byte, position int) (iggcon.ClientInfo, int) {
 		Transport:           transport,
 		Address:             address,
 		ConsumerGroupsCount: consumerGroupsCount,
+		Labels:              labels,
 	}, readBytes
 }
 
+// Deprecated: Use DeserializeClientWithDialect, which supports Iggy-era servers.
 func DeserializeClient(payload []byte) *iggcon.ClientInfoDetails {
-	clientInfo, position := MapClientInfo(payload, 0)
+	deprecation.Report(deprecation.Notice{
+		API:         "binaryserialization.DeserializeClient",
+		Replacement: "binaryserialization.DeserializeClientWithDialect",
+		Hint:        "pass iggcon.MessengerDialect to keep the current behavior",
+	})
+	return DeserializeClientWithDialect(payload, iggcon.MessengerDialect)
+}
+
+// DeserializeClientWithDialect deserializes the client details, reading its labels when the dialect reports them.
+func DeserializeClientWithDialect(payload []byte, dialect *iggcon.Dialect) *iggcon.ClientInfoDetails {
+	clientInfo, position := mapClientInfo(payload, 0, dialect.ClientLabels)
 	consumerGroups := make([]iggcon.ConsumerGroupInfo, clientInfo.ConsumerGroupsCount)
 	length := len(payload)
 
@@ -570,12 +618,10 @@ func DeserializeAccessToken(payload []byte) (*iggcon.RawPersonalAccessToken, err
 	}, nil
 }
 
+// DeserializeAccessTokens decodes the personal access tokens of the user, the payload is empty
+// when the user has none.
 func DeserializeAccessTokens(payload []byte) ([]iggcon.PersonalAccessTokenInfo, error) {
-	if len(payload) == 0 {
-		return []iggcon.PersonalAccessTokenInfo{}, ierror.CustomError("Empty payload")
-	}
-
-	var result []iggcon.PersonalAccessTokenInfo
+	result := []iggcon.PersonalAccessTokenInfo{}
 	position := 0
 	length := len(payload)
 
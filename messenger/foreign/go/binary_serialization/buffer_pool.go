@@ -0,0 +1,50 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package binaryserialization
+
+import "sync"
+
+// maxPooledBufferSize bounds the buffers kept in the pool, so a single huge batch does not pin its memory.
+const maxPooledBufferSize = 8 * 1024 * 1024
+
+// bufferPool holds *[]byte, storing a pointer spares an allocation on every Put.
+var bufferPool sync.Pool
+
+// GetBuffer returns a zeroed buffer of the given size, reusing the memory of a released buffer when possible.
+// Release it with PutBuffer once it is not referenced anymore.
+func GetBuffer(size int) []byte {
+	if buffer, ok := bufferPool.Get().(*[]byte); ok {
+		if cap(*buffer) >= size {
+			b := (*buffer)[:size]
+			clear(b)
+			return b
+		}
+		// too small for this request, kept for smaller ones
+		bufferPool.Put(buffer)
+	}
+	return make([]byte, size)
+}
+
+// PutBuffer releases a buffer to the pool. The buffer must not be used afterwards.
+func PutBuffer(b []byte) {
+	if cap(b) == 0 || cap(b) > maxPooledBufferSize {
+		return
+	}
+	b = b[:0]
+	bufferPool.Put(&b)
+}
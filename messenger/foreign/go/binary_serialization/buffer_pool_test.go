@@ -0,0 +1,92 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package binaryserialization
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+)
+
+func TestGetBuffer_ZeroedOnReuse(t *testing.T) {
+	// the pool may drop its buffers at any time, a few rounds make a reuse all but certain
+	for range 10 {
+		released := GetBuffer(64)
+		for i := range released {
+			released[i] = 0xff
+		}
+		PutBuffer(released)
+
+		buffer := GetBuffer(32)
+		if len(buffer) != 32 {
+			t.Fatalf("expected a buffer of 32 bytes, got %d", len(buffer))
+		}
+		if !bytes.Equal(buffer, make([]byte, 32)) {
+			t.Fatalf("expected a zeroed buffer, got %x", buffer)
+		}
+		PutBuffer(buffer)
+	}
+}
+
+func TestPutBuffer_DropsHugeBuffers(t *testing.T) {
+	PutBuffer(make([]byte, maxPooledBufferSize+1))
+	if buffer := GetBuffer(1); cap(buffer) > maxPooledBufferSize {
+		t.Fatalf("expected a huge buffer not to be pooled, got %d bytes", cap(buffer))
+	}
+}
+
+func BenchmarkGetBuffer(b *testing.B) {
+	b.Run("pooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			PutBuffer(GetBuffer(64 * 1024))
+		}
+	})
+	b.Run("unpooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = io.Discard.Write(make([]byte, 64*1024))
+		}
+	})
+}
+
+func BenchmarkRelease_SendMessagesRequest(b *testing.B) {
+	b.Run("pooled", func(b *testing.B) {
+		request := newBatchRequest(1000, 1000)
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			buffers := request.Buffers(iggcon.MESSAGE_COMPRESSION_NONE)
+			_, _ = buffers.WriteTo(io.Discard)
+			request.Release()
+		}
+	})
+	b.Run("unpooled", func(b *testing.B) {
+		request := newBatchRequest(1000, 1000)
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			buffers := request.Buffers(iggcon.MESSAGE_COMPRESSION_NONE)
+			_, _ = buffers.WriteTo(io.Discard)
+			// dropped instead of released, left to the garbage collector
+			request.pooled, request.vectors = nil, nil
+		}
+	})
+}
@@ -0,0 +1,62 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package binaryserialization
+
+import (
+	"encoding/binary"
+	"testing"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+)
+
+func TestDeserializeClients_WithLabels(t *testing.T) {
+	labels := map[string]string{iggcon.LabelService: "billing", iggcon.LabelTeam: "payments"}
+	client := make([]byte, 13, 64)
+	binary.LittleEndian.PutUint32(client[0:4], 7)
+	binary.LittleEndian.PutUint32(client[4:8], 1)
+	client[8] = 1
+	binary.LittleEndian.PutUint32(client[9:13], 9)
+	client = append(client, "127.0.0.1"...)
+	client = binary.LittleEndian.AppendUint32(client, 0)
+	legacyClient := client
+	client = append(client, SerializeUpdateClientLabels(iggcon.UpdateClientLabelsRequest{Labels: labels})...)
+
+	clients, err := DeserializeClientsWithDialect(append(client, client...), iggcon.MessengerExtendedDialect)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(clients) != 2 {
+		t.Fatalf("Expected 2 clients, got %d", len(clients))
+	}
+	for _, info := range clients {
+		if info.ID != 7 || info.Address != "127.0.0.1" || len(info.Labels) != 2 ||
+			info.Labels[iggcon.LabelService] != "billing" || info.Labels[iggcon.LabelTeam] != "payments" {
+			t.Errorf("Client is incorrect, got %+v", info)
+		}
+	}
+
+	for _, dialect := range []*iggcon.Dialect{iggcon.MessengerDialect, iggcon.IggyDialect} {
+		legacy, err := DeserializeClientsWithDialect(legacyClient, dialect)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(legacy) != 1 || legacy[0].Labels != nil {
+			t.Errorf("%s clients should not carry labels, got %+v", dialect.Name, legacy)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package binaryserialization
+
+import (
+	"encoding/binary"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	ierror "github.com/apache/messenger/foreign/go/errors"
+)
+
+// DeserializeClusterMetadata deserializes the cluster metadata, laid out as the cluster name,
+// the nodes (id u32, role u8, status u8, name, address), then the partitions (stream id u32,
+// stream name, topic id u32, topic name, partition id u32, leader id u32), each list prefixed
+// by its u32 count and each string by its u8 length.
+func DeserializeClusterMetadata(payload []byte) (*iggcon.ClusterMetadata, error) {
+	d := clusterMetadataDecoder{payload: payload}
+	metadata := &iggcon.ClusterMetadata{Name: d.string()}
+
+	nodesCount := d.uint32()
+	for i := uint32(0); i < nodesCount && !d.truncated; i++ {
+		metadata.Nodes = append(metadata.Nodes, iggcon.ClusterNode{
+			Id:      d.uint32(),
+			Role:    iggcon.ClusterNodeRole(d.uint8()),
+			Status:  iggcon.ClusterNodeStatus(d.uint8()),
+			Name:    d.string(),
+			Address: d.string(),
+		})
+	}
+
+	partitionsCount := d.uint32()
+	for i := uint32(0); i < partitionsCount && !d.truncated; i++ {
+		metadata.Partitions = append(metadata.Partitions, iggcon.PartitionLeader{
+			StreamId:    d.uint32(),
+			StreamName:  d.string(),
+			TopicId:     d.uint32(),
+			TopicName:   d.string(),
+			PartitionId: d.uint32(),
+			LeaderId:    d.uint32(),
+		})
+	}
+
+	if d.truncated {
+		return nil, ierror.CustomError("invalid_cluster_metadata")
+	}
+	return metadata, nil
+}
+
+// clusterMetadataDecoder reads the cluster metadata, reporting truncated from the first read past
+// the end of the payload.
+type clusterMetadataDecoder struct {
+	payload   []byte
+	position  int
+	truncated bool
+}
+
+func (d *clusterMetadataDecoder) next(n int) []byte {
+	if d.truncated || d.position+n > len(d.payload) {
+		d.truncated = true
+		return make([]byte, n)
+	}
+	b := d.payload[d.position : d.position+n]
+	d.position += n
+	return b
+}
+
+func (d *clusterMetadataDecoder) uint8() uint8 {
+	return d.next(1)[0]
+}
+
+func (d *clusterMetadataDecoder) uint32() uint32 {
+	return binary.LittleEndian.Uint32(d.next(4))
+}
+
+func (d *clusterMetadataDecoder) string() string {
+	return string(d.next(int(d.uint8())))
+}
@@ -0,0 +1,369 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package binaryserialization
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/klauspost/compress/s2"
+)
+
+// The conformance tests compare the send messages requests serialized by this SDK with a golden
+// corpus: every JSON file in testdata/conformance describes a request and the bytes it serializes
+// to, see the README there for where the cases come from. Running
+//
+//	go test ./binary_serialization -run TestExportConformanceCorpus -conformance.export <dir>
+//
+// exports random requests in the same format, for the Rust side to check the other direction.
+var conformanceExport = flag.String("conformance.export", "", "directory to export a conformance corpus to")
+
+type conformanceCase struct {
+	Name              string               `json:"name"`
+	StreamId          string               `json:"stream_id"`
+	TopicId           string               `json:"topic_id"`
+	PartitioningKind  byte                 `json:"partitioning_kind"`
+	PartitioningValue string               `json:"partitioning_value"`
+	Dialect           string               `json:"dialect"`
+	Confirmation      byte                 `json:"confirmation"`
+	Messages          []conformanceMessage `json:"messages"`
+	Expected          string               `json:"expected"`
+}
+
+type conformanceMessage struct {
+	Id              string `json:"id"`
+	OriginTimestamp uint64 `json:"origin_timestamp"`
+	Payload         string `json:"payload"`
+	UserHeaders     string `json:"user_headers"`
+}
+
+func (c conformanceCase) request() (TcpSendMessagesRequest, error) {
+	streamId, err := conformanceIdentifier(c.StreamId)
+	if err != nil {
+		return TcpSendMessagesRequest{}, err
+	}
+	topicId, err := conformanceIdentifier(c.TopicId)
+	if err != nil {
+		return TcpSendMessagesRequest{}, err
+	}
+	partitioningValue, err := hex.DecodeString(c.PartitioningValue)
+	if err != nil {
+		return TcpSendMessagesRequest{}, err
+	}
+	dialect := iggcon.MessengerDialect
+	switch c.Dialect {
+	case iggcon.MessengerExtendedDialect.Name:
+		dialect = iggcon.MessengerExtendedDialect
+	case iggcon.IggyDialect.Name:
+		dialect = iggcon.IggyDialect
+	}
+
+	messages := make([]iggcon.MessengerMessage, len(c.Messages))
+	for i, m := range c.Messages {
+		var id iggcon.MessageID
+		idBytes, err := hex.DecodeString(m.Id)
+		if err != nil || len(idBytes) != len(id) {
+			return TcpSendMessagesRequest{}, fmt.Errorf("invalid message id %q", m.Id)
+		}
+		copy(id[:], idBytes)
+		payload, err := hex.DecodeString(m.Payload)
+		if err != nil {
+			return TcpSendMessagesRequest{}, err
+		}
+		userHeaders, err := hex.DecodeString(m.UserHeaders)
+		if err != nil {
+			return TcpSendMessagesRequest{}, err
+		}
+		messages[i] = iggcon.MessengerMessage{
+			Header:      iggcon.NewMessageHeader(id, uint32(len(payload)), uint32(len(userHeaders))),
+			Payload:     payload,
+			UserHeaders: userHeaders,
+		}
+		messages[i].Header.OriginTimestamp = m.OriginTimestamp
+	}
+
+	return TcpSendMessagesRequest{
+		StreamId: streamId,
+		TopicId:  topicId,
+		Partitioning: iggcon.Partitioning{
+			Kind:   iggcon.PartitioningKind(c.PartitioningKind),
+			Length: len(partitioningValue),
+			Value:  partitioningValue,
+		},
+		Messages:     messages,
+		Dialect:      dialect,
+		Confirmation: iggcon.Confirmation(c.Confirmation),
+	}, nil
+}
+
+func conformanceIdentifier(value string) (iggcon.Identifier, error) {
+	if id, err := strconv.ParseUint(value, 10, 32); err == nil {
+		return iggcon.NewIdentifier(uint32(id))
+	}
+	return iggcon.NewIdentifier(value)
+}
+
+func TestConformanceCorpus(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("testdata", "conformance", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Fatal("no golden corpus in testdata/conformance")
+	}
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			content, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var c conformanceCase
+			if err = json.Unmarshal(content, &c); err != nil {
+				t.Fatal(err)
+			}
+			request, err := c.request()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual := hex.EncodeToString(request.Serialize(iggcon.MESSAGE_COMPRESSION_NONE)); actual != c.Expected {
+				t.Errorf("%s diverges from the reference SDK.\nExpected:\t%s\nGot:\t\t%s", c.Name, c.Expected, actual)
+			}
+		})
+	}
+}
+
+func TestExportConformanceCorpus(t *testing.T) {
+	if *conformanceExport == "" {
+		t.Skip("-conformance.export is not set")
+	}
+	random := rand.New(rand.NewSource(1))
+	randomHex := func(minLength, maxLength int) string {
+		b := make([]byte, minLength+random.Intn(maxLength-minLength+1))
+		random.Read(b)
+		return hex.EncodeToString(b)
+	}
+
+	for i := 0; i < 100; i++ {
+		c := conformanceCase{
+			Name:         fmt.Sprintf("go-%03d", i),
+			StreamId:     strconv.Itoa(1 + random.Intn(1000)),
+			TopicId:      fmt.Sprintf("topic-%d", random.Intn(1000)),
+			Dialect:      []string{iggcon.MessengerDialect.Name, iggcon.MessengerExtendedDialect.Name, iggcon.IggyDialect.Name}[random.Intn(3)],
+			Confirmation: byte(random.Intn(4)),
+		}
+		switch random.Intn(3) {
+		case 0:
+			c.PartitioningKind = byte(iggcon.Balanced)
+		case 1:
+			c.PartitioningKind = byte(iggcon.PartitionIdKind)
+			c.PartitioningValue = randomHex(4, 4)
+		case 2:
+			c.PartitioningKind = byte(iggcon.MessageKey)
+			c.PartitioningValue = randomHex(1, 255)
+		}
+		if c.Dialect != iggcon.MessengerExtendedDialect.Name {
+			c.Confirmation = 0
+		}
+		for j := 1 + random.Intn(10); j > 0; j-- {
+			c.Messages = append(c.Messages, conformanceMessage{
+				Id:              randomHex(16, 16),
+				OriginTimestamp: uint64(random.Int63()),
+				Payload:         randomHex(1, 512),
+			})
+		}
+
+		request, err := c.request()
+		if err != nil {
+			t.Fatal(err)
+		}
+		c.Expected = hex.EncodeToString(request.Serialize(iggcon.MESSAGE_COMPRESSION_NONE))
+		content, err := json.MarshalIndent(c, "", "  ")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = os.WriteFile(filepath.Join(*conformanceExport, c.Name+".json"), content, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// decodedMessage is a message read back by decodeSendMessagesRequest.
+type decodedMessage struct {
+	header      iggcon.MessageHeader
+	payload     []byte
+	userHeaders []byte
+}
+
+// decodeSendMessagesRequest reads a send messages request following the protocol description
+// rather than the serializer, and checks the consistency of its indexes.
+func decodeSendMessagesRequest(b []byte, headerSize int) (confirmation byte, messages []decodedMessage, err error) {
+	if len(b) < 4 {
+		return 0, nil, errors.New("missing metadata length")
+	}
+	metadataLength := int(binary.LittleEndian.Uint32(b))
+	if len(b) < 4+metadataLength {
+		return 0, nil, errors.New("truncated metadata")
+	}
+	metadata := b[4 : 4+metadataLength]
+	position := 0
+	for field := 0; field < 3; field++ {
+		// stream ID, topic ID and partitioning all are a kind, a length and a value
+		if position+2 > len(metadata) {
+			return 0, nil, errors.New("truncated metadata field")
+		}
+		position += 2 + int(metadata[position+1])
+	}
+	if position+4 > len(metadata) {
+		return 0, nil, errors.New("missing messages count")
+	}
+	count := int(binary.LittleEndian.Uint32(metadata[position:]))
+	position += 4
+	switch len(metadata) - position {
+	case 0:
+	case 1:
+		confirmation = metadata[position]
+	default:
+		return 0, nil, fmt.Errorf("%d unexpected metadata bytes", len(metadata)-position)
+	}
+
+	indexes := b[4+metadataLength:]
+	if len(indexes) < count*indexSize {
+		return 0, nil, errors.New("truncated indexes")
+	}
+	block := indexes[count*indexSize:]
+	indexes = indexes[:count*indexSize]
+
+	position = 0
+	for i := 0; i < count; i++ {
+		if position+headerSize > len(block) {
+			return 0, nil, fmt.Errorf("message %d: truncated header", i)
+		}
+		header, err := iggcon.MessageHeaderFromBytes(block[position : position+iggcon.MessageHeaderSize])
+		if err != nil {
+			return 0, nil, err
+		}
+		if !bytes.Equal(block[position+iggcon.MessageHeaderSize:position+headerSize], make([]byte, headerSize-iggcon.MessageHeaderSize)) {
+			return 0, nil, fmt.Errorf("message %d: reserved header bytes are not zeroed", i)
+		}
+		position += headerSize
+		end := position + int(header.PayloadLength) + int(header.UserHeaderLength)
+		if end > len(block) {
+			return 0, nil, fmt.Errorf("message %d: truncated body", i)
+		}
+		messages = append(messages, decodedMessage{
+			header:      *header,
+			payload:     block[position : position+int(header.PayloadLength)],
+			userHeaders: block[position+int(header.PayloadLength) : end],
+		})
+		position = end
+
+		index := indexes[i*indexSize : (i+1)*indexSize]
+		if relativeOffset := binary.LittleEndian.Uint32(index); int(relativeOffset) != i {
+			return 0, nil, fmt.Errorf("message %d: index relative offset is %d", i, relativeOffset)
+		}
+		if indexPosition := binary.LittleEndian.Uint32(index[4:]); int(indexPosition) != position {
+			return 0, nil, fmt.Errorf("message %d: index position is %d, the message ends at %d", i, indexPosition, position)
+		}
+		if timestamp := binary.LittleEndian.Uint64(index[8:]); timestamp != header.OriginTimestamp && timestamp != header.Timestamp {
+			return 0, nil, fmt.Errorf("message %d: index timestamp %d is not the message timestamp", i, timestamp)
+		}
+	}
+	if position != len(block) {
+		return 0, nil, fmt.Errorf("%d trailing bytes", len(block)-position)
+	}
+	return confirmation, messages, nil
+}
+
+func FuzzSendMessagesRequest(f *testing.F) {
+	f.Add("stream", uint32(1), []byte("payload"), []byte{}, uint8(2), false, byte(0), byte(0))
+	f.Add("s", uint32(42), bytes.Repeat([]byte("compressible"), 10), []byte("headers"), uint8(5), true, byte(1), byte(0))
+	f.Add("stream", uint32(7), []byte{0}, []byte{}, uint8(1), false, byte(3), byte(2))
+
+	f.Fuzz(func(t *testing.T, stream string, topic uint32, payload, userHeaders []byte, count uint8, iggy bool, compression, confirmation byte) {
+		streamId, err := iggcon.NewIdentifier(stream)
+		if err != nil {
+			t.Skip()
+		}
+		topicId, err := iggcon.NewIdentifier(topic)
+		if err != nil || len(payload) == 0 || count == 0 {
+			t.Skip()
+		}
+		dialect := iggcon.MessengerDialect
+		if iggy {
+			dialect = iggcon.IggyDialect
+		}
+		messages := make([]iggcon.MessengerMessage, count%16+1)
+		for i := range messages {
+			messages[i] = iggcon.MessengerMessage{
+				Header:      iggcon.NewMessageHeader(iggcon.MessageID{byte(i)}, uint32(len(payload)), uint32(len(userHeaders))),
+				Payload:     payload,
+				UserHeaders: userHeaders,
+			}
+		}
+		request := TcpSendMessagesRequest{
+			StreamId:     streamId,
+			TopicId:      topicId,
+			Partitioning: iggcon.None(),
+			Messages:     append([]iggcon.MessengerMessage{}, messages...),
+			Dialect:      dialect,
+			Confirmation: iggcon.Confirmation(confirmation % 4),
+		}
+		compressionKind := []iggcon.MessengerMessageCompression{
+			iggcon.MESSAGE_COMPRESSION_NONE,
+			iggcon.MESSAGE_COMPRESSION_S2,
+			iggcon.MESSAGE_COMPRESSION_S2_BETTER,
+			iggcon.MESSAGE_COMPRESSION_S2_BEST,
+		}[compression%4]
+
+		decodedConfirmation, decoded, err := decodeSendMessagesRequest(request.Serialize(compressionKind), dialect.MessageHeaderSize)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if iggcon.Confirmation(decodedConfirmation) != request.Confirmation {
+			t.Fatalf("confirmation %d decoded as %d", request.Confirmation, decodedConfirmation)
+		}
+		if len(decoded) != len(messages) {
+			t.Fatalf("%d messages decoded as %d", len(messages), len(decoded))
+		}
+		for i, message := range decoded {
+			if message.header.Id != messages[i].Header.Id || message.header.OriginTimestamp != messages[i].Header.OriginTimestamp {
+				t.Fatalf("message %d: header fields differ", i)
+			}
+			decodedPayload := message.payload
+			if compressionKind != iggcon.MESSAGE_COMPRESSION_NONE && len(payload) >= 32 {
+				if decodedPayload, err = s2.Decode(nil, message.payload); err != nil {
+					t.Fatalf("message %d: %v", i, err)
+				}
+			}
+			if !bytes.Equal(decodedPayload, payload) || !bytes.Equal(message.userHeaders, userHeaders) {
+				t.Fatalf("message %d: body differs", i)
+			}
+		}
+	})
+}
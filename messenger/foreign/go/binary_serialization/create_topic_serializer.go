@@ -26,8 +26,8 @@ type TcpCreateTopicRequest struct {
 	StreamId             iggcon.Identifier           `json:"streamId"`
 	PartitionsCount      uint32                      `json:"partitionsCount"`
 	CompressionAlgorithm iggcon.CompressionAlgorithm `json:"compressionAlgorithm"`
-	MessageExpiry        iggcon.Duration             `json:"messageExpiry"`
-	MaxTopicSize         uint64                      `json:"maxTopicSize"`
+	MessageExpiry        iggcon.Expiry               `json:"messageExpiry"`
+	MaxTopicSize         iggcon.MaxTopicSize         `json:"maxTopicSize"`
 	Name                 string                      `json:"name"`
 	ReplicationFactor    *uint8                      `json:"replicationFactor"`
 	TopicId              *uint32                     `json:"topicId"`
@@ -78,7 +78,7 @@ func (request *TcpCreateTopicRequest) Serialize() []byte {
 	position += 8
 
 	// MaxTopicSize
-	binary.LittleEndian.PutUint64(bytes[position:], request.MaxTopicSize)
+	binary.LittleEndian.PutUint64(bytes[position:], uint64(request.MaxTopicSize))
 	position += 8
 
 	// ReplicationFactor
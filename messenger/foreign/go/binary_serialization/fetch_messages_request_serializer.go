@@ -19,6 +19,7 @@ package binaryserialization
 
 import (
 	"encoding/binary"
+	"time"
 
 	iggcon "github.com/apache/messenger/foreign/go/contracts"
 )
@@ -27,6 +28,7 @@ const (
 	partitionStrategySize = 5
 	offsetSize            = 12
 	commitFlagSize        = 1
+	maxWaitSize           = 4
 )
 
 type TcpFetchMessagesRequest struct {
@@ -37,6 +39,9 @@ type TcpFetchMessagesRequest struct {
 	Strategy    iggcon.PollingStrategy `json:"pollingStrategy"`
 	Count       uint32                 `json:"count"`
 	AutoCommit  bool                   `json:"autoCommit"`
+	// MaxWait is appended as the milliseconds the server holds the poll waiting for messages,
+	// unless zero. It is only understood by servers supporting long polling.
+	MaxWait time.Duration `json:"-"`
 }
 
 func (request *TcpFetchMessagesRequest) Serialize() []byte {
@@ -45,6 +50,9 @@ func (request *TcpFetchMessagesRequest) Serialize() []byte {
 	}
 	streamTopicIdLength := 2 + request.StreamId.Length + 2 + request.TopicId.Length
 	messageSize := 2 + request.Consumer.Id.Length + streamTopicIdLength + partitionStrategySize + offsetSize + commitFlagSize + 1
+	if request.MaxWait > 0 {
+		messageSize += maxWaitSize
+	}
 	bytes := make([]byte, messageSize)
 
 	bytes[0] = byte(request.Consumer.Kind)
@@ -70,5 +78,9 @@ func (request *TcpFetchMessagesRequest) Serialize() []byte {
 		bytes[position] = 0
 	}
 
+	if request.MaxWait > 0 {
+		binary.LittleEndian.PutUint32(bytes[position+1:], uint32(max(request.MaxWait.Milliseconds(), 1)))
+	}
+
 	return bytes
 }
@@ -18,7 +18,9 @@
 package binaryserialization
 
 import (
+	"bytes"
 	"testing"
+	"time"
 
 	iggcon "github.com/apache/messenger/foreign/go/contracts"
 )
@@ -70,6 +72,29 @@ func TestSerialize_TcpFetchMessagesRequest(t *testing.T) {
 	}
 }
 
+func TestSerialize_TcpFetchMessagesRequestWithMaxWait(t *testing.T) {
+	streamId, _ := iggcon.NewIdentifier(uint32(1))
+	topicId, _ := iggcon.NewIdentifier(uint32(2))
+	request := TcpFetchMessagesRequest{
+		Consumer: iggcon.DefaultConsumer(),
+		StreamId: streamId,
+		TopicId:  topicId,
+		Strategy: iggcon.NextPollingStrategy(),
+		Count:    10,
+	}
+	plain := request.Serialize()
+
+	request.MaxWait = 1500 * time.Millisecond
+	serialized := request.Serialize()
+
+	if !bytes.Equal(serialized[:len(plain)], plain) {
+		t.Fatalf("long polling changed the request prefix: %v vs %v", serialized, plain)
+	}
+	if tail := serialized[len(plain):]; !bytes.Equal(tail, []byte{0xDC, 0x05, 0x00, 0x00}) {
+		t.Errorf("MaxWait bytes are incorrect: %v", tail)
+	}
+}
+
 func areBytesEqual(a, b []byte) bool {
 	if len(a) != len(b) {
 		return false
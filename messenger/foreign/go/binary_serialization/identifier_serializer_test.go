@@ -18,11 +18,14 @@
 package binaryserialization
 
 import (
+	"bytes"
+	"encoding/binary"
 	"errors"
-	ierror "github.com/apache/messenger/foreign/go/errors"
+	"strings"
 	"testing"
 
 	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	ierror "github.com/apache/messenger/foreign/go/errors"
 )
 
 func TestSerializeIdentifier_StringId(t *testing.T) {
@@ -74,3 +77,184 @@ func TestSerializeIdentifier_EmptyStringId(t *testing.T) {
 		t.Errorf("Expected error: %v, got: %v", ierror.InvalidIdentifier, err)
 	}
 }
+
+func TestSerializeIdentifier_TooLongStringId(t *testing.T) {
+	if _, err := iggcon.NewIdentifier(strings.Repeat("a", iggcon.MaxIdentifierLength+1)); !errors.Is(err, ierror.InvalidIdentifier) {
+		t.Errorf("Expected error: %v, got: %v", ierror.InvalidIdentifier, err)
+	}
+}
+
+func TestIdentifier_Validate(t *testing.T) {
+	valid := []iggcon.Identifier{
+		iggcon.MustIdentifier(uint32(1)),
+		iggcon.MustIdentifier("s"),
+		iggcon.MustIdentifier(strings.Repeat("s", iggcon.MaxIdentifierLength)),
+	}
+	for _, id := range valid {
+		if err := id.Validate(); err != nil {
+			t.Errorf("expected %v to be valid, got %v", id, err)
+		}
+	}
+
+	invalid := []iggcon.Identifier{
+		{},
+		{Kind: iggcon.NumericId, Length: 4, Value: []byte{0, 0, 0, 0}},
+		{Kind: iggcon.NumericId, Length: 2, Value: []byte{1, 0}},
+		{Kind: iggcon.StringId, Length: 0, Value: []byte{}},
+		{Kind: iggcon.StringId, Length: 3, Value: []byte("stream")},
+		{Kind: 3, Length: 4, Value: []byte{1, 0, 0, 0}},
+	}
+	for _, id := range invalid {
+		if err := id.Validate(); !errors.Is(err, ierror.InvalidIdentifier) {
+			t.Errorf("expected %v to be invalid, got %v", id, err)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected MustIdentifier to panic on an empty name")
+		}
+	}()
+	iggcon.MustIdentifier("")
+}
+
+// commandIdentifiers are the identifiers a command is serialized with.
+type commandIdentifiers struct {
+	stream, topic, group, user, consumer iggcon.Identifier
+}
+
+// TestSerializers_NamedIdentifiers serializes every command taking identifiers with numeric and
+// then named ones, and checks that only the encoding of the identifiers differs.
+func TestSerializers_NamedIdentifiers(t *testing.T) {
+	numeric := commandIdentifiers{
+		stream:   iggcon.MustIdentifier(uint32(0x0a0b0c01)),
+		topic:    iggcon.MustIdentifier(uint32(0x0a0b0c02)),
+		group:    iggcon.MustIdentifier(uint32(0x0a0b0c03)),
+		user:     iggcon.MustIdentifier(uint32(0x0a0b0c04)),
+		consumer: iggcon.MustIdentifier(uint32(0x0a0b0c05)),
+	}
+	named := commandIdentifiers{
+		stream:   iggcon.MustIdentifier(strings.Repeat("s", iggcon.MaxIdentifierLength)),
+		topic:    iggcon.MustIdentifier("topic"),
+		group:    iggcon.MustIdentifier("group"),
+		user:     iggcon.MustIdentifier("u"),
+		consumer: iggcon.MustIdentifier("consumer"),
+	}
+	partitionId := uint32(3)
+	username := "renamed"
+	status := iggcon.Inactive
+
+	tests := []struct {
+		name      string
+		serialize func(ids commandIdentifiers) []byte
+		// lengthPrefixed is set for the requests starting with the u32 length of their metadata.
+		lengthPrefixed bool
+	}{
+		{name: "stream", serialize: func(ids commandIdentifiers) []byte {
+			return SerializeIdentifier(ids.stream)
+		}},
+		{name: "topic", serialize: func(ids commandIdentifiers) []byte {
+			return SerializeIdentifiers(ids.stream, ids.topic)
+		}},
+		{name: "consumer group", serialize: func(ids commandIdentifiers) []byte {
+			return SerializeIdentifiers(ids.stream, ids.topic, ids.group)
+		}},
+		{name: "create consumer group", serialize: func(ids commandIdentifiers) []byte {
+			return CreateGroup(iggcon.CreateConsumerGroupRequest{StreamId: ids.stream, TopicId: ids.topic, Name: "group"})
+		}},
+		{name: "update stream", serialize: func(ids commandIdentifiers) []byte {
+			request := TcpUpdateStreamRequest{StreamId: ids.stream, Name: "stream"}
+			return request.Serialize()
+		}},
+		{name: "create topic", serialize: func(ids commandIdentifiers) []byte {
+			request := TcpCreateTopicRequest{StreamId: ids.stream, PartitionsCount: 2, Name: "topic"}
+			return request.Serialize()
+		}},
+		{name: "update topic", serialize: func(ids commandIdentifiers) []byte {
+			request := TcpUpdateTopicRequest{StreamId: ids.stream, TopicId: ids.topic, MaxTopicSize: 1024, Name: "topic"}
+			return request.Serialize()
+		}},
+		{name: "create partitions", serialize: func(ids commandIdentifiers) []byte {
+			return CreatePartitions(iggcon.CreatePartitionsRequest{StreamId: ids.stream, TopicId: ids.topic, PartitionsCount: 2})
+		}},
+		{name: "delete partitions", serialize: func(ids commandIdentifiers) []byte {
+			return DeletePartitions(iggcon.DeletePartitionsRequest{StreamId: ids.stream, TopicId: ids.topic, PartitionsCount: 2})
+		}},
+		{name: "store offset", serialize: func(ids commandIdentifiers) []byte {
+			return UpdateOffset(iggcon.StoreConsumerOffsetRequest{
+				Consumer: iggcon.NewSingleConsumer(ids.consumer), StreamId: ids.stream, TopicId: ids.topic, PartitionId: &partitionId, Offset: 42,
+			})
+		}},
+		{name: "get offset", serialize: func(ids commandIdentifiers) []byte {
+			return GetOffset(iggcon.GetConsumerOffsetRequest{
+				Consumer: iggcon.NewGroupConsumer(ids.group), StreamId: ids.stream, TopicId: ids.topic, PartitionId: &partitionId,
+			})
+		}},
+		{name: "delete offset", serialize: func(ids commandIdentifiers) []byte {
+			return DeleteOffset(iggcon.DeleteConsumerOffsetRequest{
+				Consumer: iggcon.NewSingleConsumer(ids.consumer), StreamId: ids.stream, TopicId: ids.topic, PartitionId: &partitionId,
+			})
+		}},
+		{name: "poll messages", serialize: func(ids commandIdentifiers) []byte {
+			request := TcpFetchMessagesRequest{
+				StreamId: ids.stream, TopicId: ids.topic, Consumer: iggcon.NewGroupConsumer(ids.group),
+				PartitionId: &partitionId, Strategy: iggcon.NextPollingStrategy(), Count: 10, AutoCommit: true,
+			}
+			return request.Serialize()
+		}},
+		{name: "send messages", lengthPrefixed: true, serialize: func(ids commandIdentifiers) []byte {
+			message, err := iggcon.NewMessengerMessage([]byte("payload"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			message.Header.Id = iggcon.MessageID{1}
+			message.Header.OriginTimestamp = 1
+			request := TcpSendMessagesRequest{
+				StreamId: ids.stream, TopicId: ids.topic, Partitioning: iggcon.PartitionId(partitionId),
+				Messages: []iggcon.MessengerMessage{message}, Dialect: iggcon.MessengerDialect,
+			}
+			return request.Serialize(iggcon.MESSAGE_COMPRESSION_NONE)
+		}},
+		{name: "get user", serialize: func(ids commandIdentifiers) []byte {
+			return SerializeIdentifier(ids.user)
+		}},
+		{name: "update user", serialize: func(ids commandIdentifiers) []byte {
+			return SerializeUpdateUser(iggcon.UpdateUserRequest{UserID: ids.user, Username: &username, Status: &status})
+		}},
+		{name: "update nothing of user", serialize: func(ids commandIdentifiers) []byte {
+			return SerializeUpdateUser(iggcon.UpdateUserRequest{UserID: ids.user})
+		}},
+		{name: "change password", serialize: func(ids commandIdentifiers) []byte {
+			return SerializeChangePasswordRequest(iggcon.ChangePasswordRequest{UserID: ids.user, CurrentPassword: "old", NewPassword: "new"})
+		}},
+		{name: "update permissions", serialize: func(ids commandIdentifiers) []byte {
+			return SerializeUpdateUserPermissionsRequest(iggcon.UpdatePermissionsRequest{
+				UserID: ids.user, Permissions: &iggcon.Permissions{Global: iggcon.GlobalPermissions{ReadStreams: true}},
+			})
+		}},
+	}
+
+	replacements := []struct{ numeric, named iggcon.Identifier }{
+		{numeric.stream, named.stream},
+		{numeric.topic, named.topic},
+		{numeric.group, named.group},
+		{numeric.user, named.user},
+		{numeric.consumer, named.consumer},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected := tt.serialize(numeric)
+			for _, r := range replacements {
+				expected = bytes.ReplaceAll(expected, SerializeIdentifier(r.numeric), SerializeIdentifier(r.named))
+			}
+			actual := tt.serialize(named)
+			if tt.lengthPrefixed {
+				growth := len(actual) - len(tt.serialize(numeric))
+				binary.LittleEndian.PutUint32(expected, binary.LittleEndian.Uint32(expected)+uint32(growth))
+			}
+			if !bytes.Equal(actual, expected) {
+				t.Errorf("the named identifiers changed more than their encoding.\nExpected:\t%v\nGot:\t\t%v", expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package binaryserialization
+
+import (
+	"encoding/hex"
+	"testing"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+)
+
+func TestGeneratedSerializers(t *testing.T) {
+	groupId := uint32(3)
+	tests := []struct {
+		name       string
+		serialized []byte
+		// expected is the expected payload, hex encoded
+		expected string
+	}{
+		{
+			name: "CreateGroup",
+			serialized: CreateGroup(iggcon.CreateConsumerGroupRequest{
+				StreamId:        iggcon.MustIdentifier(uint32(1)),
+				TopicId:         iggcon.MustIdentifier(uint32(2)),
+				ConsumerGroupId: &groupId,
+				Name:            "g",
+			}),
+			expected: "010401000000" + "010402000000" + "03000000" + "0167",
+		},
+		{
+			name: "CreateGroup without an ID",
+			serialized: CreateGroup(iggcon.CreateConsumerGroupRequest{
+				StreamId: iggcon.MustIdentifier(uint32(1)),
+				TopicId:  iggcon.MustIdentifier(uint32(2)),
+				Name:     "g",
+			}),
+			expected: "010401000000" + "010402000000" + "00000000" + "0167",
+		},
+		{
+			name: "ChangePassword",
+			serialized: SerializeChangePasswordRequest(iggcon.ChangePasswordRequest{
+				UserID:          iggcon.MustIdentifier(uint32(5)),
+				CurrentPassword: "a",
+				NewPassword:     "bc",
+			}),
+			expected: "010405000000" + "0161" + "026263",
+		},
+		{
+			name:       "LoginWithPersonalAccessToken",
+			serialized: SerializeLoginWithPersonalAccessToken(iggcon.LoginWithPersonalAccessTokenRequest{Token: "tok"}),
+			expected:   "03746f6b",
+		},
+		{
+			name:       "DeletePersonalAccessToken",
+			serialized: SerializeDeletePersonalAccessToken(iggcon.DeletePersonalAccessTokenRequest{Name: "n"}),
+			expected:   "016e",
+		},
+	}
+	for _, tt := range tests {
+		if actual := hex.EncodeToString(tt.serialized); actual != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.expected, actual)
+		}
+	}
+}
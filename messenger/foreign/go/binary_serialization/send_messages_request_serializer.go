@@ -19,6 +19,9 @@ package binaryserialization
 
 import (
 	"encoding/binary"
+	"io"
+	"net"
+	"sync"
 
 	iggcon "github.com/apache/messenger/foreign/go/contracts"
 	"github.com/klauspost/compress/s2"
@@ -29,33 +32,73 @@ type TcpSendMessagesRequest struct {
 	TopicId      iggcon.Identifier    `json:"topicId"`
 	Partitioning iggcon.Partitioning  `json:"partitioning"`
 	Messages     []iggcon.MessengerMessage `json:"messages"`
+	// Dialect selects the wire layout of the messages, nil means iggcon.MessengerDialect.
+	Dialect *iggcon.Dialect `json:"-"`
+	// Confirmation is appended to the metadata unless it is iggcon.ConfirmationDefault,
+	// servers skip the metadata they do not know using its length.
+	Confirmation iggcon.Confirmation `json:"-"`
+	// Compression is applied to the payloads by WriteTo.
+	Compression iggcon.MessengerMessageCompression `json:"-"`
+
+	// pooled are the buffers returned by Buffers which belong to the buffer pool.
+	pooled [][]byte
+	// vectors is the pooled slice holding the buffers returned by Buffers.
+	vectors *net.Buffers
 }
 
+// vectorsPool holds the *net.Buffers returned by Buffers.
+var vectorsPool sync.Pool
+
+// indexSize is the size of an index entry, made of the offset of the message relative to the
+// first one of the batch (uint32), the position of the end of the message within the messages
+// block (uint32) and the timestamp of the message (uint64).
 const indexSize = 16
 
+func putIndex(dst []byte, relativeOffset uint32, position uint32, timestamp uint64) {
+	binary.LittleEndian.PutUint32(dst[0:4], relativeOffset)
+	binary.LittleEndian.PutUint32(dst[4:8], position)
+	binary.LittleEndian.PutUint64(dst[8:16], timestamp)
+}
+
+// messageTimestamp returns the timestamp assigned by the server, or the origin timestamp of a message yet to be sent.
+func messageTimestamp(header iggcon.MessageHeader) uint64 {
+	if header.Timestamp != 0 {
+		return header.Timestamp
+	}
+	return header.OriginTimestamp
+}
+
+// Serialize returns the request as a single contiguous buffer, copying every payload.
+// Prefer WriteTo for large batches.
 func (request *TcpSendMessagesRequest) Serialize(compression iggcon.MessengerMessageCompression) []byte {
-	for i, message := range request.Messages {
-		switch compression {
-		case iggcon.MESSAGE_COMPRESSION_S2:
-			if len(message.Payload) < 32 {
-				break
-			}
-			request.Messages[i].Payload = s2.Encode(nil, message.Payload)
-			message.Header.PayloadLength = uint32(len(message.Payload))
-		case iggcon.MESSAGE_COMPRESSION_S2_BETTER:
-			if len(message.Payload) < 32 {
-				break
-			}
-			request.Messages[i].Payload = s2.EncodeBetter(nil, message.Payload)
-			message.Header.PayloadLength = uint32(len(message.Payload))
-		case iggcon.MESSAGE_COMPRESSION_S2_BEST:
-			if len(message.Payload) < 32 {
-				break
-			}
-			request.Messages[i].Payload = s2.EncodeBest(nil, message.Payload)
-			message.Header.PayloadLength = uint32(len(message.Payload))
-		}
+	buffers := request.Buffers(compression)
+	size := 0
+	for _, buffer := range buffers {
+		size += len(buffer)
 	}
+	bytes := make([]byte, 0, size)
+	for _, buffer := range buffers {
+		bytes = append(bytes, buffer...)
+	}
+	request.Release()
+	return bytes
+}
+
+// WriteTo streams the request to w, compressed with request.Compression, without copying the
+// payloads into an intermediate buffer. Writing to a net.Conn uses vectored I/O (writev).
+func (request *TcpSendMessagesRequest) WriteTo(w io.Writer) (int64, error) {
+	buffers := request.Buffers(request.Compression)
+	defer request.Release()
+	return buffers.WriteTo(w)
+}
+
+// Buffers returns the serialized request as a sequence of buffers: the metadata and the indexes,
+// then the header, the payload and the user headers of every message. The payloads and the user
+// headers are not copied, the buffers reference the messages, except the compressed payloads
+// which are written to buffers of the request: the messages are left untouched, so the same
+// messages can be sent again. Call Release once the buffers are written to reuse their memory.
+func (request *TcpSendMessagesRequest) Buffers(compression iggcon.MessengerMessageCompression) net.Buffers {
+	payloads := request.compress(compression)
 
 	streamIdFieldSize := 2 + request.StreamId.Length
 	topicIdFieldSize := 2 + request.TopicId.Length
@@ -63,21 +106,23 @@ func (request *TcpSendMessagesRequest) Serialize(compression iggcon.MessengerMes
 	metadataLenFieldSize := 4 // uint32
 	messageCount := len(request.Messages)
 	messagesCountFieldSize := 4 // uint32
+	confirmationFieldSize := 0
+	if request.Confirmation != iggcon.ConfirmationDefault {
+		confirmationFieldSize = 1
+	}
 	metadataLen := streamIdFieldSize +
-		topicIdFieldSize +
-		partitioningFieldSize +
-		messagesCountFieldSize
-	indexesSize := messageCount * indexSize
-	messageBytesCount := calculateMessageBytesCount(request.Messages)
-	totalSize := metadataLenFieldSize +
-		streamIdFieldSize +
 		topicIdFieldSize +
 		partitioningFieldSize +
 		messagesCountFieldSize +
-		indexesSize +
-		messageBytesCount
+		confirmationFieldSize
+	indexesSize := messageCount * indexSize
+	headerPadding := 0
+	if request.Dialect != nil {
+		headerPadding = request.Dialect.MessageHeaderPadding()
+	}
+	headerSize := iggcon.MessageHeaderSize + headerPadding
 
-	bytes := make([]byte, totalSize)
+	bytes := GetBuffer(metadataLenFieldSize + metadataLen + indexesSize)
 
 	position := 0
 
@@ -96,36 +141,86 @@ func (request *TcpSendMessagesRequest) Serialize(compression iggcon.MessengerMes
 	position += partitioningFieldSize
 	binary.LittleEndian.PutUint32(bytes[position:position+4], uint32(messageCount))
 	position += 4
+	if confirmationFieldSize > 0 {
+		bytes[position] = byte(request.Confirmation)
+		position += confirmationFieldSize
+	}
 
-	currentIndexPosition := position
-	for i := 0; i < indexesSize; i++ {
-		bytes[position+i] = 0
+	if request.vectors == nil {
+		request.vectors, _ = vectorsPool.Get().(*net.Buffers)
+		if request.vectors == nil {
+			request.vectors = new(net.Buffers)
+		}
 	}
-	position += indexesSize
+	buffers := append((*request.vectors)[:0], bytes)
 
+	// every header is followed by its reserved bytes, left zeroed
+	headers := GetBuffer(messageCount * headerSize)
+	request.pooled = append(request.pooled, bytes, headers)
+	currentIndexPosition := position
 	msgSize := uint32(0)
-	for _, message := range request.Messages {
-		copy(bytes[position:position+iggcon.MessageHeaderSize], message.Header.ToBytes())
-		copy(bytes[position+iggcon.MessageHeaderSize:position+iggcon.MessageHeaderSize+int(message.Header.PayloadLength)], message.Payload)
-		position += iggcon.MessageHeaderSize + int(message.Header.PayloadLength)
-		copy(bytes[position:position+int(message.Header.UserHeaderLength)], message.UserHeaders)
-		position += int(message.Header.UserHeaderLength)
-
-		msgSize += iggcon.MessageHeaderSize + message.Header.PayloadLength + message.Header.UserHeaderLength
-
-		binary.LittleEndian.PutUint32(bytes[currentIndexPosition:currentIndexPosition+4], 0)
-		binary.LittleEndian.PutUint32(bytes[currentIndexPosition+4:currentIndexPosition+8], uint32(msgSize))
-		binary.LittleEndian.PutUint32(bytes[currentIndexPosition+8:currentIndexPosition+12], 0)
+	for i, message := range request.Messages {
+		if payloads != nil && payloads[i] != nil {
+			message.Payload = payloads[i]
+			message.Header.PayloadLength = uint32(len(payloads[i]))
+		}
+		header := headers[i*headerSize : (i+1)*headerSize]
+		copy(header, message.Header.ToBytes())
+		buffers = append(buffers, header)
+		if len(message.Payload) > 0 {
+			buffers = append(buffers, message.Payload[:message.Header.PayloadLength])
+		}
+		if message.Header.UserHeaderLength > 0 {
+			buffers = append(buffers, message.UserHeaders[:message.Header.UserHeaderLength])
+		}
+
+		msgSize += uint32(headerSize) + message.Header.PayloadLength + message.Header.UserHeaderLength
+
+		putIndex(bytes[currentIndexPosition:currentIndexPosition+indexSize], uint32(i), msgSize, messageTimestamp(message.Header))
 		currentIndexPosition += indexSize
 	}
 
-	return bytes
+	*request.vectors = buffers
+	return buffers
 }
 
-func calculateMessageBytesCount(messages []iggcon.MessengerMessage) int {
-	count := 0
-	for _, msg := range messages {
-		count += iggcon.MessageHeaderSize + len(msg.Payload) + len(msg.UserHeaders)
+// Release returns the buffers allocated by Buffers to the buffer pool, they must not be used afterwards.
+func (request *TcpSendMessagesRequest) Release() {
+	for _, buffer := range request.pooled {
+		PutBuffer(buffer)
+	}
+	request.pooled = request.pooled[:0]
+	if request.vectors != nil {
+		clear(*request.vectors)
+		vectorsPool.Put(request.vectors)
+		request.vectors = nil
+	}
+}
+
+// compress returns the compressed payloads of the messages, written to pooled buffers released
+// by Release, or nil without compression. The payloads shorter than 32 bytes are left as is,
+// with a nil entry.
+func (request *TcpSendMessagesRequest) compress(compression iggcon.MessengerMessageCompression) [][]byte {
+	var encode func(dst, src []byte) []byte
+	switch compression {
+	case iggcon.MESSAGE_COMPRESSION_S2:
+		encode = s2.Encode
+	case iggcon.MESSAGE_COMPRESSION_S2_BETTER:
+		encode = s2.EncodeBetter
+	case iggcon.MESSAGE_COMPRESSION_S2_BEST:
+		encode = s2.EncodeBest
+	default:
+		return nil
+	}
+	payloads := make([][]byte, len(request.Messages))
+	for i, message := range request.Messages {
+		payload := message.Payload[:min(int(message.Header.PayloadLength), len(message.Payload))]
+		if len(payload) < 32 {
+			continue
+		}
+		buffer := GetBuffer(s2.MaxEncodedLen(len(payload)))
+		request.pooled = append(request.pooled, buffer)
+		payloads[i] = encode(buffer, payload)
 	}
-	return count
+	return payloads
 }
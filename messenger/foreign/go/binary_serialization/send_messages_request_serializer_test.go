@@ -18,14 +18,22 @@
 package binaryserialization
 
 import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"math/rand"
+	"strings"
 	"testing"
 
 	iggcon "github.com/apache/messenger/foreign/go/contracts"
 	"github.com/google/uuid"
+	"github.com/klauspost/compress/s2"
 )
 
 func TestSerialize_SendMessagesRequest(t *testing.T) {
 	message1 := generateTestMessage("data1")
+	message1.Header.OriginTimestamp = 1_000_000
 	streamId, _ := iggcon.NewIdentifier("test_stream_id")
 	topicId, _ := iggcon.NewIdentifier("test_topic_id")
 	request := TcpSendMessagesRequest{
@@ -54,7 +62,9 @@ func TestSerialize_SendMessagesRequest(t *testing.T) {
 		0x04,                   // Partitioning Length
 		0x01, 0x00, 0x00, 0x00, // PartitionId (123)
 		0x01, 0x0, 0x0, 0x0, // MessageCount
-		0, 0, 0, 0, 110, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, // Index (16*1) bytes
+		0, 0, 0, 0, // Index relative offset
+		110, 0, 0, 0, // Index position
+		0x40, 0x42, 0x0F, 0x00, 0x00, 0x00, 0x00, 0x00, // Index timestamp (1000000)
 	}
 	expected = append(expected, message1.Header.ToBytes()...)
 	expected = append(expected, message1.Payload...)
@@ -80,3 +90,263 @@ func generateTestMessage(payload string) iggcon.MessengerMessage {
 		iggcon.WithUserHeaders(createDefaultMessageHeaders()))
 	return msg
 }
+
+func TestSerialize_SendMessagesRequestIggyDialect(t *testing.T) {
+	message := generateTestMessage("data1")
+	streamId, _ := iggcon.NewIdentifier(uint32(1))
+	topicId, _ := iggcon.NewIdentifier(uint32(1))
+	request := TcpSendMessagesRequest{
+		StreamId:     streamId,
+		TopicId:      topicId,
+		Partitioning: iggcon.None(),
+		Messages:     []iggcon.MessengerMessage{message},
+		Dialect:      iggcon.IggyDialect,
+	}
+
+	serialized := request.Serialize(iggcon.MESSAGE_COMPRESSION_NONE)
+
+	messagesPosition := len(serialized) - iggcon.IggyMessageHeaderSize - len(message.Payload) - len(message.UserHeaders)
+	header := serialized[messagesPosition : messagesPosition+iggcon.MessageHeaderSize]
+	if !areBytesEqual(header, message.Header.ToBytes()) {
+		t.Errorf("Header bytes are incorrect. \nExpected:\t%v\nGot:\t\t%v", message.Header.ToBytes(), header)
+	}
+	padding := serialized[messagesPosition+iggcon.MessageHeaderSize : messagesPosition+iggcon.IggyMessageHeaderSize]
+	if !areBytesEqual(padding, make([]byte, 8)) {
+		t.Errorf("Reserved header bytes should be zeroed, got %v", padding)
+	}
+	payload := serialized[messagesPosition+iggcon.IggyMessageHeaderSize : messagesPosition+iggcon.IggyMessageHeaderSize+len(message.Payload)]
+	if string(payload) != "data1" {
+		t.Errorf("Payload is incorrect, got %q", payload)
+	}
+
+	polled := append(make([]byte, 16), serialized[messagesPosition:]...)
+	response, err := DeserializeFetchMessagesResponseWithDialect(polled, iggcon.MESSAGE_COMPRESSION_NONE, iggcon.IggyDialect)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(response.Messages) != 1 || string(response.Messages[0].Payload) != "data1" {
+		t.Errorf("Round trip through the Iggy dialect failed, got %+v", response.Messages)
+	}
+}
+
+func TestSerialize_SendMessagesRequestConfirmation(t *testing.T) {
+	streamId, _ := iggcon.NewIdentifier(uint32(1))
+	topicId, _ := iggcon.NewIdentifier(uint32(1))
+	request := TcpSendMessagesRequest{
+		StreamId:     streamId,
+		TopicId:      topicId,
+		Partitioning: iggcon.None(),
+		Messages:     []iggcon.MessengerMessage{generateTestMessage("data1")},
+	}
+	withoutConfirmation := request.Serialize(iggcon.MESSAGE_COMPRESSION_NONE)
+
+	request.Confirmation = iggcon.ConfirmationFsync
+	serialized := request.Serialize(iggcon.MESSAGE_COMPRESSION_NONE)
+
+	metadataLength := binary.LittleEndian.Uint32(serialized[:4])
+	if metadataLength != binary.LittleEndian.Uint32(withoutConfirmation[:4])+1 {
+		t.Fatalf("Metadata length should include the confirmation, got %d", metadataLength)
+	}
+	if confirmation := iggcon.Confirmation(serialized[4+metadataLength-1]); confirmation != iggcon.ConfirmationFsync {
+		t.Errorf("Confirmation is incorrect, got %v", confirmation)
+	}
+	if !areBytesEqual(serialized[4+metadataLength:], withoutConfirmation[4+metadataLength-1:]) {
+		t.Errorf("Indexes and messages should follow the metadata unchanged")
+	}
+}
+
+func newBatchRequest(count int, payloadSize int) TcpSendMessagesRequest {
+	streamId, _ := iggcon.NewIdentifier(uint32(1))
+	topicId, _ := iggcon.NewIdentifier(uint32(1))
+	messages := make([]iggcon.MessengerMessage, count)
+	for i := range messages {
+		messages[i] = generateTestMessage(strings.Repeat("x", payloadSize))
+	}
+	return TcpSendMessagesRequest{
+		StreamId:     streamId,
+		TopicId:      topicId,
+		Partitioning: iggcon.None(),
+		Messages:     messages,
+	}
+}
+
+func TestWriteTo_SendMessagesRequestMatchesSerialize(t *testing.T) {
+	for _, compression := range []iggcon.MessengerMessageCompression{iggcon.MESSAGE_COMPRESSION_NONE, iggcon.MESSAGE_COMPRESSION_S2} {
+		request := newBatchRequest(3, 100)
+		expected := request.Serialize(compression)
+
+		request.Compression = compression
+		var written bytes.Buffer
+		n, err := request.WriteTo(&written)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if int(n) != written.Len() || !bytes.Equal(expected, written.Bytes()) {
+			t.Fatalf("Compression %v: WriteTo and Serialize outputs differ", compression)
+		}
+		if payloadLength := request.Messages[0].Header.PayloadLength; int(payloadLength) != len(request.Messages[0].Payload) {
+			t.Errorf("Payload length should match the sent payload, got %d", payloadLength)
+		}
+	}
+}
+
+func TestSerialize_SendMessagesRequestTwice(t *testing.T) {
+	payload := make([]byte, 512)
+	rand.New(rand.NewSource(1)).Read(payload)
+	message, err := iggcon.NewMessengerMessage(bytes.Clone(payload))
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := TcpSendMessagesRequest{
+		StreamId:     iggcon.MustIdentifier(uint32(1)),
+		TopicId:      iggcon.MustIdentifier(uint32(1)),
+		Partitioning: iggcon.None(),
+		Messages:     []iggcon.MessengerMessage{message},
+		Compression:  iggcon.MESSAGE_COMPRESSION_S2,
+	}
+
+	// a request is serialized again when it is retried or split, so the messages are not compressed twice
+	first := request.Serialize(iggcon.MESSAGE_COMPRESSION_S2)
+	second := request.Serialize(iggcon.MESSAGE_COMPRESSION_S2)
+	var written bytes.Buffer
+	if _, err := request.WriteTo(&written); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(first, second) || !bytes.Equal(first, written.Bytes()) {
+		t.Fatalf("expected the same request every time, got %d, %d and %d bytes", len(first), len(second), written.Len())
+	}
+	if !bytes.Equal(request.Messages[0].Payload, payload) || request.Messages[0].Header.PayloadLength != uint32(len(payload)) {
+		t.Fatalf("expected the message to be left untouched, got a payload of %d bytes", request.Messages[0].Header.PayloadLength)
+	}
+	compressed := first[len(first)-len(s2.Encode(nil, payload)):]
+	if decoded, err := s2.Decode(nil, compressed); err != nil || !bytes.Equal(decoded, payload) {
+		t.Fatalf("expected the payload compressed once, got %v", err)
+	}
+}
+
+func BenchmarkSerialize_SendMessagesRequest(b *testing.B) {
+	request := newBatchRequest(1000, 1000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = io.Discard.Write(request.Serialize(iggcon.MESSAGE_COMPRESSION_NONE))
+	}
+}
+
+func BenchmarkWriteTo_SendMessagesRequest(b *testing.B) {
+	request := newBatchRequest(1000, 1000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = request.WriteTo(io.Discard)
+	}
+}
+
+func TestSerialize_SendMessagesRequestIndexes(t *testing.T) {
+	newMessage := func(payload string, originTimestamp, timestamp uint64) iggcon.MessengerMessage {
+		message, _ := iggcon.NewMessengerMessage([]byte(payload), iggcon.WithID(iggcon.MessageID{1}))
+		message.Header.OriginTimestamp = originTimestamp
+		message.Header.Timestamp = timestamp
+		return message
+	}
+	streamId, _ := iggcon.NewIdentifier(uint32(1))
+	topicId, _ := iggcon.NewIdentifier(uint32(2))
+
+	// The frames are written out field by field from the protocol layout, the indexes being the
+	// relative offset, the position where the message ends and the timestamp of every message.
+	const (
+		// metadata length, stream 1, topic 2 and balanced partitioning, the message count follows
+		metadata = "12000000" + "0104" + "01000000" + "0104" + "02000000" + "0100"
+		// checksum, ID 1 and offset of every message header, the timestamps follow
+		headerStart = "0000000000000000" + "01000000000000000000000000000000" + "0000000000000000"
+		noTimestamp = "0000000000000000"
+		noHeaders   = "00000000"
+	)
+	tests := []struct {
+		name        string
+		messages    []iggcon.MessengerMessage
+		dialect     *iggcon.Dialect
+		compression iggcon.MessengerMessageCompression
+		// frame is the expected request, hex encoded
+		frame string
+	}{
+		{
+			name:     "single message",
+			messages: []iggcon.MessengerMessage{newMessage("a", 1_000, 0)},
+			frame: metadata + "01000000" +
+				"00000000" + "39000000" + "e803000000000000" +
+				headerStart + noTimestamp + "e803000000000000" + noHeaders + "01000000" + "61",
+		},
+		{
+			name: "multiple messages",
+			messages: []iggcon.MessengerMessage{
+				newMessage("a", 1_000, 0),
+				newMessage("bb", 2_000, 0),
+				newMessage("ccc", 3_000, 0),
+			},
+			frame: metadata + "03000000" +
+				"00000000" + "39000000" + "e803000000000000" +
+				"01000000" + "73000000" + "d007000000000000" +
+				"02000000" + "ae000000" + "b80b000000000000" +
+				headerStart + noTimestamp + "e803000000000000" + noHeaders + "01000000" + "61" +
+				headerStart + noTimestamp + "d007000000000000" + noHeaders + "02000000" + "6262" +
+				headerStart + noTimestamp + "b80b000000000000" + noHeaders + "03000000" + "636363",
+		},
+		{
+			name: "server timestamp takes precedence",
+			messages: []iggcon.MessengerMessage{
+				newMessage("a", 1_000, 5_000),
+				newMessage("bb", 2_000, 0),
+			},
+			frame: metadata + "02000000" +
+				"00000000" + "39000000" + "8813000000000000" +
+				"01000000" + "73000000" + "d007000000000000" +
+				headerStart + "8813000000000000" + "e803000000000000" + noHeaders + "01000000" + "61" +
+				headerStart + noTimestamp + "d007000000000000" + noHeaders + "02000000" + "6262",
+		},
+		{
+			name: "iggy dialect",
+			messages: []iggcon.MessengerMessage{
+				newMessage("a", 1_000, 0),
+				newMessage("bb", 2_000, 0),
+			},
+			dialect: iggcon.IggyDialect,
+			// every header is followed by 8 reserved bytes
+			frame: metadata + "02000000" +
+				"00000000" + "41000000" + "e803000000000000" +
+				"01000000" + "83000000" + "d007000000000000" +
+				headerStart + noTimestamp + "e803000000000000" + noHeaders + "01000000" + "0000000000000000" + "61" +
+				headerStart + noTimestamp + "d007000000000000" + noHeaders + "02000000" + "0000000000000000" + "6262",
+		},
+		{
+			name: "compressed payloads",
+			messages: []iggcon.MessengerMessage{
+				newMessage(strings.Repeat("a", 64), 1_000, 0),
+				newMessage("short", 2_000, 0),
+			},
+			compression: iggcon.MESSAGE_COMPRESSION_S2,
+			// the 64 bytes are a literal "a" copied 63 times from offset 1, the short payload is
+			// left uncompressed
+			frame: metadata + "02000000" +
+				"00000000" + "3e000000" + "e803000000000000" +
+				"01000000" + "7b000000" + "d007000000000000" +
+				headerStart + noTimestamp + "e803000000000000" + noHeaders + "06000000" + "400061fa0100" +
+				headerStart + noTimestamp + "d007000000000000" + noHeaders + "05000000" + "73686f7274",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := TcpSendMessagesRequest{
+				StreamId:     streamId,
+				TopicId:      topicId,
+				Partitioning: iggcon.None(),
+				Messages:     test.messages,
+				Dialect:      test.dialect,
+			}
+			if actual := hex.EncodeToString(request.Serialize(test.compression)); actual != test.frame {
+				t.Errorf("Request is incorrect.\nExpected:\t%s\nGot:\t\t%s", test.frame, actual)
+			}
+		})
+	}
+}
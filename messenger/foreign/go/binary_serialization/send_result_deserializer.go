@@ -0,0 +1,50 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package binaryserialization
+
+import (
+	"encoding/binary"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	ierror "github.com/apache/messenger/foreign/go/errors"
+)
+
+// DeserializeSendResult deserializes the response of a send messages request, laid out as the
+// partition id u32, the base offset u64 and the u32 count of the append timestamps u64. It
+// returns nil for an empty payload, sent by the servers not reporting where messages landed.
+func DeserializeSendResult(payload []byte) (*iggcon.SendResult, error) {
+	if len(payload) == 0 {
+		return nil, nil
+	}
+	if len(payload) < 16 {
+		return nil, ierror.CustomError("invalid_send_result")
+	}
+	count := int(binary.LittleEndian.Uint32(payload[12:16]))
+	if len(payload) != 16+count*8 {
+		return nil, ierror.CustomError("invalid_send_result")
+	}
+	result := &iggcon.SendResult{
+		PartitionId: binary.LittleEndian.Uint32(payload[0:4]),
+		BaseOffset:  binary.LittleEndian.Uint64(payload[4:12]),
+		Timestamps:  make([]uint64, count),
+	}
+	for i := range result.Timestamps {
+		result.Timestamps[i] = binary.LittleEndian.Uint64(payload[16+i*8:])
+	}
+	return result, nil
+}
@@ -0,0 +1,53 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package binaryserialization
+
+import (
+	"reflect"
+	"testing"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+)
+
+func TestDeserializeSendResult(t *testing.T) {
+	payload := []byte{
+		3, 0, 0, 0, // partition id: 3
+		42, 0, 0, 0, 0, 0, 0, 0, // base offset: 42
+		2, 0, 0, 0, // count: 2
+		1, 0, 0, 0, 0, 0, 0, 0, // timestamp: 1
+		2, 0, 0, 0, 0, 0, 0, 0, // timestamp: 2
+	}
+	result, err := DeserializeSendResult(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &iggcon.SendResult{PartitionId: 3, BaseOffset: 42, Timestamps: []uint64{1, 2}}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, result)
+	}
+	if result.Offset(1) != 43 {
+		t.Fatalf("expected the second message at offset 43, got %d", result.Offset(1))
+	}
+
+	if result, err := DeserializeSendResult(nil); err != nil || result != nil {
+		t.Fatalf("expected no result for an empty payload, got %v, %v", result, err)
+	}
+	if _, err := DeserializeSendResult(payload[:20]); err == nil {
+		t.Fatal("expected an error for a truncated payload")
+	}
+}
@@ -85,6 +85,15 @@ func (stats *TcpStats) Deserialize(payload []byte) error {
 
 	kernelVersionLength := int(binary.LittleEndian.Uint32(payload[position : position+4]))
 	stats.KernelVersion = string(payload[position+4 : position+4+kernelVersionLength])
+	position += 4 + kernelVersionLength
+
+	// only reported by Iggy-era servers
+	if len(payload) >= position+4 {
+		serverVersionLength := int(binary.LittleEndian.Uint32(payload[position : position+4]))
+		if len(payload) >= position+4+serverVersionLength {
+			stats.ServerVersion = string(payload[position+4 : position+4+serverVersionLength])
+		}
+	}
 
 	return nil
 }
@@ -27,8 +27,8 @@ type TcpUpdateTopicRequest struct {
 	StreamId             iggcon.Identifier           `json:"streamId"`
 	TopicId              iggcon.Identifier           `json:"topicId"`
 	CompressionAlgorithm iggcon.CompressionAlgorithm `json:"compressionAlgorithm"`
-	MessageExpiry        iggcon.Duration             `json:"messageExpiry"`
-	MaxTopicSize         uint64                      `json:"maxTopicSize"`
+	MessageExpiry        iggcon.Expiry               `json:"messageExpiry"`
+	MaxTopicSize         iggcon.MaxTopicSize         `json:"maxTopicSize"`
 	ReplicationFactor    *uint8                      `json:"replicationFactor"`
 	Name                 string                      `json:"name"`
 }
@@ -53,7 +53,7 @@ func (request *TcpUpdateTopicRequest) Serialize() []byte {
 	binary.LittleEndian.PutUint64(buffer[offset:], uint64(request.MessageExpiry))
 	offset += 8
 
-	binary.LittleEndian.PutUint64(buffer[offset:], request.MaxTopicSize)
+	binary.LittleEndian.PutUint64(buffer[offset:], uint64(request.MaxTopicSize))
 	offset += 8
 
 	buffer[offset] = *request.ReplicationFactor
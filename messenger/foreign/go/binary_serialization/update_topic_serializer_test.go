@@ -19,6 +19,7 @@ package binaryserialization
 
 import (
 	"testing"
+	"time"
 
 	iggcon "github.com/apache/messenger/foreign/go/contracts"
 )
@@ -30,7 +31,7 @@ func TestSerialize_UpdateTopic(t *testing.T) {
 		StreamId:      streamId,
 		TopicId:       topicId,
 		Name:          "update_topic",
-		MessageExpiry: 100 * iggcon.Microsecond,
+		MessageExpiry: iggcon.ExpiryAfter(100 * time.Microsecond),
 		MaxTopicSize:  100,
 	}
 
@@ -0,0 +1,164 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package capture records the raw frames exchanged with the server to a file, and replays them
+// through the deserializers of the SDK to diagnose protocol incompatibilities. Frames are
+// captured by the TCP client configured with tcp.WithFrameCapture, and replayed by the
+// messenger-replay command.
+package capture
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+)
+
+// magic starts every capture file.
+const magic = "MSGRCAP1"
+
+// Direction tells whether a frame was sent or received by the client.
+type Direction uint8
+
+const (
+	Request Direction = iota + 1
+	Response
+)
+
+func (d Direction) String() string {
+	switch d {
+	case Request:
+		return "request"
+	case Response:
+		return "response"
+	}
+	return "unknown"
+}
+
+// Frame is a raw frame, as written to or read from the connection.
+type Frame struct {
+	Direction Direction
+	// Time is the time the request started to be written, or the response was read.
+	Time time.Time
+	// Command is the SDK command code of the request, which the dialect may map to another one on the wire.
+	Command iggcon.CommandCode
+	// Dialect is the name of the dialect the client spoke.
+	Dialect string
+	// Data is the frame, header included.
+	Data []byte
+}
+
+// Writer writes frames to a capture file. It is safe for concurrent use, so clients can share it.
+type Writer struct {
+	mtx     sync.Mutex
+	w       io.Writer
+	started bool
+}
+
+// NewWriter creates a Writer appending the frames to w, which it does not buffer.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+// Write appends the frames, a request followed by its response, so the exchanges of several
+// clients do not interleave.
+func (w *Writer) Write(frames ...Frame) error {
+	var buffer []byte
+	for _, frame := range frames {
+		if len(frame.Dialect) > 255 {
+			return fmt.Errorf("capture: dialect name %q too long", frame.Dialect)
+		}
+		buffer = append(buffer, byte(frame.Direction))
+		buffer = binary.LittleEndian.AppendUint64(buffer, uint64(frame.Time.UnixNano()))
+		buffer = binary.LittleEndian.AppendUint32(buffer, uint32(frame.Command))
+		buffer = append(buffer, byte(len(frame.Dialect)))
+		buffer = append(buffer, frame.Dialect...)
+		buffer = binary.LittleEndian.AppendUint32(buffer, uint32(len(frame.Data)))
+		buffer = append(buffer, frame.Data...)
+	}
+
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	if !w.started {
+		buffer = append([]byte(magic), buffer...)
+	}
+	if _, err := w.w.Write(buffer); err != nil {
+		return err
+	}
+	w.started = true
+	return nil
+}
+
+// Reader reads the frames of a capture file.
+type Reader struct {
+	r       *bufio.Reader
+	started bool
+}
+
+// NewReader creates a Reader of the frames written to r by a Writer.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r)}
+}
+
+// Next returns the next frame, io.EOF once all were read.
+func (r *Reader) Next() (Frame, error) {
+	if !r.started {
+		header := make([]byte, len(magic))
+		if _, err := io.ReadFull(r.r, header); err != nil {
+			if errors.Is(err, io.ErrUnexpectedEOF) {
+				err = errors.New("capture: not a capture file")
+			}
+			return Frame{}, err
+		}
+		if string(header) != magic {
+			return Frame{}, errors.New("capture: not a capture file")
+		}
+		r.started = true
+	}
+
+	var fixed [14]byte
+	if _, err := io.ReadFull(r.r, fixed[:]); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return Frame{}, errors.New("capture: truncated frame")
+		}
+		return Frame{}, err
+	}
+	frame := Frame{
+		Direction: Direction(fixed[0]),
+		Time:      time.Unix(0, int64(binary.LittleEndian.Uint64(fixed[1:9]))),
+		Command:   iggcon.CommandCode(binary.LittleEndian.Uint32(fixed[9:13])),
+	}
+	dialect := make([]byte, fixed[13])
+	var length [4]byte
+	if _, err := io.ReadFull(r.r, dialect); err != nil {
+		return Frame{}, errors.New("capture: truncated frame")
+	}
+	if _, err := io.ReadFull(r.r, length[:]); err != nil {
+		return Frame{}, errors.New("capture: truncated frame")
+	}
+	frame.Dialect = string(dialect)
+	frame.Data = make([]byte, binary.LittleEndian.Uint32(length[:]))
+	if _, err := io.ReadFull(r.r, frame.Data); err != nil {
+		return Frame{}, errors.New("capture: truncated frame")
+	}
+	return frame, nil
+}
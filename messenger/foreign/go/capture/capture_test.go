@@ -0,0 +1,86 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package capture_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/apache/messenger/foreign/go/capture"
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengercli"
+	"github.com/apache/messenger/foreign/go/messengertest"
+	"github.com/apache/messenger/foreign/go/tcp"
+)
+
+func TestCaptureAndReplay(t *testing.T) {
+	server := messengertest.StartServer(t)
+	var file bytes.Buffer
+	client, err := messengercli.NewMessengerClient(messengercli.WithTcp(
+		tcp.WithServerAddress(server.Addr()),
+		tcp.WithFrameCapture(capture.NewWriter(&file)),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = client.LoginUser("messenger", "messenger"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = client.CreateStream("orders", nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = client.GetStreams(); err != nil {
+		t.Fatal(err)
+	}
+
+	var exchanges []capture.Exchange
+	if err = capture.Replay(capture.NewReader(&file), iggcon.MESSAGE_COMPRESSION_NONE, func(exchange capture.Exchange) {
+		exchanges = append(exchanges, exchange)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(exchanges) != 3 {
+		t.Fatalf("expected 3 exchanges, got %d", len(exchanges))
+	}
+	for _, exchange := range exchanges {
+		if exchange.Response == nil || exchange.Err != nil || exchange.Request.Dialect != iggcon.MessengerDialect.Name {
+			t.Fatalf("unexpected exchange %+v", exchange)
+		}
+	}
+	if stream, ok := exchanges[1].Value.(*iggcon.StreamDetails); exchanges[1].Request.Command != iggcon.CreateStreamCode || !ok || stream.Name != "orders" {
+		t.Fatalf("expected the created stream to be decoded, got %+v", exchanges[1].Value)
+	}
+	if streams, ok := exchanges[2].Value.([]iggcon.Stream); !ok || len(streams) != 1 {
+		t.Fatalf("expected the streams to be decoded, got %+v", exchanges[2].Value)
+	}
+}
+
+func TestDecode_MalformedPayload(t *testing.T) {
+	if _, err := capture.Decode(iggcon.GetOffsetCode, iggcon.MessengerDialect, iggcon.MESSAGE_COMPRESSION_NONE, []byte{1, 2}); err == nil {
+		t.Fatal("expected a truncated payload to fail to decode")
+	}
+	if value, err := capture.Decode(iggcon.PingCode, iggcon.MessengerDialect, iggcon.MESSAGE_COMPRESSION_NONE, nil); value != nil || err != nil {
+		t.Fatalf("expected no value for a command without response payload, got %v, %v", value, err)
+	}
+}
+
+func TestReader_RejectsOtherFiles(t *testing.T) {
+	if _, err := capture.NewReader(bytes.NewReader([]byte("not a capture"))).Next(); err == nil {
+		t.Fatal("expected an error for a file without the capture header")
+	}
+}
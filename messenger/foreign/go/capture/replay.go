@@ -0,0 +1,160 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package capture
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	binaryserialization "github.com/apache/messenger/foreign/go/binary_serialization"
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+)
+
+// Exchange is a request replayed along with its response.
+type Exchange struct {
+	Request Frame
+	// Response is nil when the request got no response, e.g. after a transport error.
+	Response *Frame
+	// Status is the status code of the response, 0 on success.
+	Status int
+	// Value is the response payload deserialized according to the command, nil for the commands
+	// without response payload.
+	Value any
+	// Err is the error the deserializer returned, or panicked with.
+	Err error
+}
+
+// Replay reads the frames of the capture and passes every request, along with its response
+// deserialized the way the client would, to fn. Polled messages are decompressed with the
+// given compression.
+func Replay(r *Reader, compression iggcon.MessengerMessageCompression, fn func(exchange Exchange)) error {
+	var pending *Frame
+	flush := func() {
+		if pending != nil {
+			fn(Exchange{Request: *pending})
+			pending = nil
+		}
+	}
+	for {
+		frame, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			flush()
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch frame.Direction {
+		case Request:
+			flush()
+			pending = &frame
+		case Response:
+			if pending == nil || pending.Command != frame.Command {
+				return fmt.Errorf("capture: response of command %d without request", frame.Command)
+			}
+			fn(replay(*pending, frame, compression))
+			pending = nil
+		default:
+			return fmt.Errorf("capture: unknown frame direction %d", frame.Direction)
+		}
+	}
+}
+
+func replay(request, response Frame, compression iggcon.MessengerMessageCompression) Exchange {
+	exchange := Exchange{Request: request, Response: &response}
+	if len(response.Data) < 8 {
+		exchange.Err = fmt.Errorf("response header truncated to %d bytes", len(response.Data))
+		return exchange
+	}
+	exchange.Status = int(binary.LittleEndian.Uint32(response.Data[:4]))
+	length := int(binary.LittleEndian.Uint32(response.Data[4:8]))
+	payload := response.Data[8:]
+	if exchange.Status != 0 {
+		return exchange
+	}
+	if len(payload) != length {
+		exchange.Err = fmt.Errorf("response payload of %d bytes, %d announced", len(payload), length)
+		return exchange
+	}
+	exchange.Value, exchange.Err = Decode(request.Command, dialect(response.Dialect), compression, payload)
+	return exchange
+}
+
+// dialect returns the built-in dialect of the given name, the Messenger one when unknown.
+func dialect(name string) *iggcon.Dialect {
+	switch name {
+	case iggcon.IggyDialect.Name:
+		return iggcon.IggyDialect
+	case iggcon.MessengerExtendedDialect.Name:
+		return iggcon.MessengerExtendedDialect
+	default:
+		return iggcon.MessengerDialect
+	}
+}
+
+// Decode deserializes the response payload of a command. It returns nil for the commands without
+// response payload, and turns the panics of the deserializers into errors.
+func Decode(command iggcon.CommandCode, dialect *iggcon.Dialect, compression iggcon.MessengerMessageCompression, payload []byte) (value any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			value, err = nil, fmt.Errorf("deserializer panicked: %v", r)
+		}
+	}()
+
+	switch command {
+	case iggcon.LoginUserCode, iggcon.LoginWithAccessTokenCode:
+		return binaryserialization.DeserializeLogInResponse(payload), nil
+	case iggcon.GetStatsCode:
+		stats := &binaryserialization.TcpStats{}
+		err := stats.Deserialize(payload)
+		return &stats.Stats, err
+	case iggcon.GetMeCode, iggcon.GetClientCode:
+		return binaryserialization.DeserializeClientWithDialect(payload, dialect), nil
+	case iggcon.GetClientsCode:
+		return binaryserialization.DeserializeClientsWithDialect(payload, dialect)
+	case iggcon.GetClusterMetadataCode:
+		return binaryserialization.DeserializeClusterMetadata(payload)
+	case iggcon.GetUserCode, iggcon.CreateUserCode:
+		return binaryserialization.DeserializeUser(payload)
+	case iggcon.GetUsersCode:
+		return binaryserialization.DeserializeUsers(payload)
+	case iggcon.CreateAccessTokenCode:
+		return binaryserialization.DeserializeAccessToken(payload)
+	case iggcon.GetAccessTokensCode:
+		return binaryserialization.DeserializeAccessTokens(payload)
+	case iggcon.PollMessagesCode:
+		return binaryserialization.DeserializeFetchMessagesResponseWithDialect(payload, compression, dialect)
+	case iggcon.GetOffsetCode:
+		return binaryserialization.DeserializeOffset(payload), nil
+	case iggcon.GetStreamCode, iggcon.CreateStreamCode:
+		return binaryserialization.DeserializeStream(payload)
+	case iggcon.GetStreamsCode:
+		return binaryserialization.DeserializeStreams(payload), nil
+	case iggcon.GetTopicCode, iggcon.CreateTopicCode:
+		return binaryserialization.DeserializeTopic(payload)
+	case iggcon.GetTopicsCode:
+		return binaryserialization.DeserializeTopics(payload)
+	case iggcon.GetGroupCode, iggcon.CreateGroupCode:
+		return binaryserialization.DeserializeConsumerGroup(payload), nil
+	case iggcon.GetGroupsCode:
+		return binaryserialization.DeserializeConsumerGroups(payload), nil
+	}
+	return nil, nil
+}
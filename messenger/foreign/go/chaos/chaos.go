@@ -0,0 +1,171 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package chaos injects transport faults - latency, refused and dropped connections, partial
+// writes and corrupted frames - between the client and the server, to validate how an
+// application copes with an unreliable network:
+//
+//	cli, err := messengercli.NewMessengerClient(messengercli.WithTcp(
+//		tcp.WithDialFunc(chaos.Dial(nil, chaos.Policy{Seed: 42, DropProbability: 0.01})),
+//	))
+//
+// The faults are drawn from a random source seeded by the Policy, so a failing run can be replayed.
+package chaos
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/apache/messenger/foreign/go/tcp"
+)
+
+var (
+	// ErrRefused is returned by the dial function when a connection is refused.
+	ErrRefused = errors.New("chaos: connection refused")
+	// ErrDropped is returned by the reads and writes of a dropped connection.
+	ErrDropped = errors.New("chaos: connection dropped")
+)
+
+// Policy describes the faults to inject. The probabilities range from 0 (never) to 1 (always).
+type Policy struct {
+	// Seed seeds the random source the faults are drawn from.
+	Seed int64
+	// Latency delays every read and write, plus a random duration up to Jitter.
+	Latency time.Duration
+	Jitter  time.Duration
+	// RefuseProbability is the probability that dialing fails with ErrRefused.
+	RefuseProbability float64
+	// DropProbability is the probability that a read or write closes the connection and fails with ErrDropped.
+	DropProbability float64
+	// PartialWriteProbability is the probability that a write only sends a random prefix of its
+	// data before the connection is dropped.
+	PartialWriteProbability float64
+	// CorruptProbability is the probability that a read or write flips a random byte of its data.
+	CorruptProbability float64
+}
+
+// injector draws the faults of a policy, it is shared by the connections of a dial function.
+type injector struct {
+	policy Policy
+
+	mtx  sync.Mutex
+	rand *rand.Rand
+}
+
+func newInjector(policy Policy) *injector {
+	return &injector{policy: policy, rand: rand.New(rand.NewSource(policy.Seed))}
+}
+
+func (i *injector) happens(probability float64) bool {
+	if probability <= 0 {
+		return false
+	}
+	i.mtx.Lock()
+	defer i.mtx.Unlock()
+	return i.rand.Float64() < probability
+}
+
+func (i *injector) intn(n int) int {
+	i.mtx.Lock()
+	defer i.mtx.Unlock()
+	return i.rand.Intn(n)
+}
+
+func (i *injector) delay() {
+	latency := i.policy.Latency
+	if i.policy.Jitter > 0 {
+		latency += time.Duration(i.intn(int(i.policy.Jitter)))
+	}
+	if latency > 0 {
+		time.Sleep(latency)
+	}
+}
+
+func (i *injector) corrupt(b []byte) {
+	if len(b) > 0 && i.happens(i.policy.CorruptProbability) {
+		b[i.intn(len(b))] ^= 0xff
+	}
+}
+
+// Dial returns a tcp.DialFunc opening connections with dial, nil meaning a plain TCP dialer,
+// and injecting the faults of the policy into them.
+func Dial(dial tcp.DialFunc, policy Policy) tcp.DialFunc {
+	if dial == nil {
+		var d net.Dialer
+		dial = d.DialContext
+	}
+	faults := newInjector(policy)
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		if faults.happens(policy.RefuseProbability) {
+			return nil, ErrRefused
+		}
+		conn, err := dial(ctx, network, address)
+		if err != nil {
+			return nil, err
+		}
+		return &chaosConn{Conn: conn, faults: faults}, nil
+	}
+}
+
+// Wrap injects the faults of the policy into an established connection.
+func Wrap(conn net.Conn, policy Policy) net.Conn {
+	return &chaosConn{Conn: conn, faults: newInjector(policy)}
+}
+
+type chaosConn struct {
+	net.Conn
+	faults *injector
+}
+
+func (c *chaosConn) drop() error {
+	_ = c.Conn.Close()
+	return ErrDropped
+}
+
+func (c *chaosConn) Read(b []byte) (int, error) {
+	c.faults.delay()
+	if c.faults.happens(c.faults.policy.DropProbability) {
+		return 0, c.drop()
+	}
+	n, err := c.Conn.Read(b)
+	c.faults.corrupt(b[:n])
+	return n, err
+}
+
+func (c *chaosConn) Write(b []byte) (int, error) {
+	c.faults.delay()
+	if c.faults.happens(c.faults.policy.DropProbability) {
+		return 0, c.drop()
+	}
+	if len(b) > 1 && c.faults.happens(c.faults.policy.PartialWriteProbability) {
+		n, err := c.Conn.Write(b[:1+c.faults.intn(len(b)-1)])
+		if err != nil {
+			return n, err
+		}
+		return n, c.drop()
+	}
+	if c.faults.policy.CorruptProbability > 0 {
+		// corrupt a copy, the caller may reuse its buffer
+		b = append([]byte(nil), b...)
+		c.faults.corrupt(b)
+	}
+	return c.Conn.Write(b)
+}
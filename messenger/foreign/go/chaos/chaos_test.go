@@ -0,0 +1,117 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package chaos
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+)
+
+func pipeDial(server func(conn net.Conn)) func(context.Context, string, string) (net.Conn, error) {
+	return func(context.Context, string, string) (net.Conn, error) {
+		serverConn, clientConn := net.Pipe()
+		go server(serverConn)
+		return clientConn, nil
+	}
+}
+
+func TestDial_RefusesWithSeededProbability(t *testing.T) {
+	outcomes := func() []bool {
+		dial := Dial(pipeDial(func(conn net.Conn) { conn.Close() }), Policy{Seed: 7, RefuseProbability: 0.5})
+		var refused []bool
+		for i := 0; i < 20; i++ {
+			conn, err := dial(context.Background(), "tcp", "server")
+			if err == nil {
+				conn.Close()
+			} else if !errors.Is(err, ErrRefused) {
+				t.Fatal(err)
+			}
+			refused = append(refused, err != nil)
+		}
+		return refused
+	}
+	first, second := outcomes(), outcomes()
+	var count int
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatal("expected the same seed to refuse the same dials")
+		}
+		if first[i] {
+			count++
+		}
+	}
+	if count == 0 || count == len(first) {
+		t.Fatalf("expected some dials to be refused, got %d of %d", count, len(first))
+	}
+}
+
+func TestConn_PartialWriteDropsConnection(t *testing.T) {
+	received := make(chan []byte, 1)
+	dial := Dial(pipeDial(func(conn net.Conn) {
+		data, _ := io.ReadAll(conn)
+		received <- data
+	}), Policy{PartialWriteProbability: 1})
+	conn, err := dial(context.Background(), "tcp", "server")
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := []byte("0123456789")
+	n, err := conn.Write(payload)
+	if !errors.Is(err, ErrDropped) || n == 0 || n >= len(payload) {
+		t.Fatalf("expected a partial write, wrote %d bytes: %v", n, err)
+	}
+	if data := <-received; !bytes.Equal(data, payload[:n]) {
+		t.Fatalf("expected the server to receive %q, got %q", payload[:n], data)
+	}
+}
+
+func TestConn_CorruptsFramesWithoutTouchingCallerBuffer(t *testing.T) {
+	serverConn, clientConn := net.Pipe()
+	defer serverConn.Close()
+	conn := Wrap(clientConn, Policy{Seed: 1, CorruptProbability: 1})
+	payload := []byte("frame")
+	go func() {
+		_, _ = conn.Write(payload)
+	}()
+	received := make([]byte, len(payload))
+	if _, err := io.ReadFull(serverConn, received); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(received, payload) {
+		t.Fatal("expected the frame to be corrupted")
+	}
+	if string(payload) != "frame" {
+		t.Fatal("expected the caller buffer to be left intact")
+	}
+}
+
+func TestConn_DropFailsReadsAndWrites(t *testing.T) {
+	serverConn, clientConn := net.Pipe()
+	defer serverConn.Close()
+	conn := Wrap(clientConn, Policy{DropProbability: 1})
+	if _, err := conn.Write([]byte("x")); !errors.Is(err, ErrDropped) {
+		t.Fatalf("expected ErrDropped, got %v", err)
+	}
+	if _, err := conn.Read(make([]byte, 1)); err == nil {
+		t.Fatal("expected the read of a dropped connection to fail")
+	}
+}
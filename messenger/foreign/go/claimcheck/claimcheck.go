@@ -0,0 +1,207 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package claimcheck offloads the large payloads to a blob store, as an alternative to chunking
+// them: a ClaimCheck uploads the payloads over a threshold to a BlobStore and sends a reference
+// instead, which it resolves back to the payload once polled. It implements both
+// messengercli.ProducerInterceptor and messengercli.ConsumerInterceptor:
+//
+//	check := claimcheck.NewClaimCheck(store, claimcheck.WithThreshold(256*1024))
+//	cli, err := messengercli.NewMessengerClient(
+//		messengercli.WithProducerInterceptors(check),
+//		messengercli.WithConsumerInterceptors(check),
+//	)
+//
+// NewMessage creates the messages of the payloads larger than iggcon.MaxPayloadSize.
+package claimcheck
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	ierror "github.com/apache/messenger/foreign/go/errors"
+	"github.com/google/uuid"
+)
+
+// User headers describing an offloaded payload.
+const (
+	// ReferenceHeader is the user header carrying the reference of the payload in the BlobStore,
+	// also sent as the payload of the message.
+	ReferenceHeader = "messenger-claim-check"
+	// DigestHeader is the user header carrying the SHA-256 digest of the payload.
+	DigestHeader = "messenger-claim-check-digest"
+)
+
+// ClaimCheck offloads the payloads larger than the threshold to a BlobStore.
+type ClaimCheck struct {
+	store     BlobStore
+	threshold int
+	timeout   time.Duration
+}
+
+type Option func(check *ClaimCheck)
+
+// WithThreshold offloads the payloads larger than threshold bytes, iggcon.MaxPayloadSize by default.
+func WithThreshold(threshold int) Option {
+	return func(check *ClaimCheck) {
+		check.threshold = threshold
+	}
+}
+
+// WithTimeout bounds the calls to the BlobStore, 30 seconds by default.
+func WithTimeout(timeout time.Duration) Option {
+	return func(check *ClaimCheck) {
+		check.timeout = timeout
+	}
+}
+
+// NewClaimCheck creates a ClaimCheck offloading the payloads to the store.
+func NewClaimCheck(store BlobStore, options ...Option) *ClaimCheck {
+	check := &ClaimCheck{store: store, threshold: iggcon.MaxPayloadSize, timeout: 30 * time.Second}
+	for _, opt := range options {
+		if opt != nil {
+			opt(check)
+		}
+	}
+	return check
+}
+
+// NewMessage creates a message of a payload of any size, the payloads larger than
+// iggcon.MaxPayloadSize being only sent through a ClaimCheck offloading them.
+func NewMessage(payload []byte, opts ...iggcon.MessengerMessageOpt) (iggcon.MessengerMessage, error) {
+	if len(payload) <= iggcon.MaxPayloadSize {
+		return iggcon.NewMessengerMessage(payload, opts...)
+	}
+	message, err := iggcon.NewMessengerMessage(payload[:1], opts...)
+	if err != nil {
+		return iggcon.MessengerMessage{}, err
+	}
+	message.Payload = payload
+	message.Header.PayloadLength = uint32(len(payload))
+	return message, nil
+}
+
+// Offload uploads the payload of the message to the store and replaces it with its reference,
+// when it is larger than the threshold.
+func (c *ClaimCheck) Offload(ctx context.Context, message *iggcon.MessengerMessage) error {
+	if len(message.Payload) <= c.threshold {
+		return nil
+	}
+	reference := uuid.NewString()
+	digest := sha256.Sum256(message.Payload)
+	err := message.SetUserHeaders(map[iggcon.HeaderKey]iggcon.HeaderValue{
+		{Value: ReferenceHeader}: iggcon.NewStringHeaderValue(reference),
+		{Value: DigestHeader}:    {Kind: iggcon.Raw, Value: digest[:]},
+	})
+	if err != nil {
+		return err
+	}
+	if err = c.store.Put(ctx, reference, message.Payload); err != nil {
+		return fmt.Errorf("claimcheck: failed to store the payload: %w", err)
+	}
+	message.Payload = []byte(reference)
+	message.Header.PayloadLength = uint32(len(reference))
+	return nil
+}
+
+// Resolve replaces the reference of an offloaded payload with the payload read from the store.
+// Messages without a reference are left untouched.
+func (c *ClaimCheck) Resolve(ctx context.Context, message *iggcon.MessengerMessage) error {
+	referenceHeader, ok := message.UserHeader(ReferenceHeader)
+	if !ok {
+		return nil
+	}
+	reference, err := referenceHeader.AsString()
+	if err != nil {
+		return err
+	}
+	digest, ok := message.UserHeader(DigestHeader)
+	if !ok {
+		return errors.New("claimcheck: missing digest header")
+	}
+	payload, err := c.store.Get(ctx, reference)
+	if err != nil {
+		return fmt.Errorf("claimcheck: failed to load the payload: %w", err)
+	}
+	if sum := sha256.Sum256(payload); !bytes.Equal(sum[:], digest.Value) {
+		return fmt.Errorf("claimcheck: the payload %s does not match its digest", reference)
+	}
+	if err = message.DeleteUserHeaders(ReferenceHeader, DigestHeader); err != nil {
+		return err
+	}
+	message.Payload = payload
+	message.Header.PayloadLength = uint32(len(payload))
+	return nil
+}
+
+func (c *ClaimCheck) OnSend(_, _ iggcon.Identifier, messages []iggcon.MessengerMessage) ([]iggcon.MessengerMessage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+	offloaded := make([]iggcon.MessengerMessage, len(messages))
+	for i, message := range messages {
+		if err := c.Offload(ctx, &message); err != nil {
+			c.discard(ctx, offloaded[:i])
+			return nil, err
+		}
+		offloaded[i] = message
+	}
+	return offloaded, nil
+}
+
+// OnAcknowledgement deletes the offloaded payloads of the messages the server rejected. The
+// payloads of the messages whose send failed otherwise, like on a lost connection, are kept
+// since the server may have appended them.
+func (c *ClaimCheck) OnAcknowledgement(_, _ iggcon.Identifier, messages []iggcon.MessengerMessage, err error) {
+	var rejected *ierror.MessengerError
+	if !errors.As(err, &rejected) {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+	c.discard(ctx, messages)
+}
+
+func (c *ClaimCheck) OnConsume(_, _ iggcon.Identifier, polled *iggcon.PolledMessage) (*iggcon.PolledMessage, error) {
+	if polled == nil {
+		return nil, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+	for i := range polled.Messages {
+		if err := c.Resolve(ctx, &polled.Messages[i]); err != nil {
+			return nil, fmt.Errorf("%w (message at offset %d)", err, polled.Messages[i].Header.Offset)
+		}
+	}
+	return polled, nil
+}
+
+func (c *ClaimCheck) OnCommit(iggcon.Consumer, iggcon.Identifier, iggcon.Identifier, uint64, *uint32, error) {
+}
+
+// discard deletes the offloaded payloads of the messages, on a best effort basis.
+func (c *ClaimCheck) discard(ctx context.Context, messages []iggcon.MessengerMessage) {
+	for _, message := range messages {
+		if reference, ok := message.UserHeader(ReferenceHeader); ok {
+			_ = c.store.Delete(ctx, string(reference.Value))
+		}
+	}
+}
@@ -0,0 +1,105 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package claimcheck_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/apache/messenger/foreign/go/claimcheck"
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengercli"
+	"github.com/apache/messenger/foreign/go/messengertest"
+)
+
+func TestClaimCheck(t *testing.T) {
+	dir := t.TempDir()
+	store, err := claimcheck.NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check := claimcheck.NewClaimCheck(store, claimcheck.WithThreshold(1024))
+	server := messengertest.NewClient()
+	client := messengercli.InterceptClient(server, messengercli.ProducerInterceptors{check}, messengercli.ConsumerInterceptors{check})
+	if _, err = client.CreateStream("media", nil); err != nil {
+		t.Fatal(err)
+	}
+	streamId := iggcon.MustIdentifier("media")
+	if _, err = client.CreateTopic(streamId, "uploads", 1, iggcon.CompressionAlgorithmNone, 0, 0, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	topicId := iggcon.MustIdentifier("uploads")
+
+	huge := bytes.Repeat([]byte("x"), iggcon.MaxPayloadSize+1)
+	large, err := claimcheck.NewMessage(huge, iggcon.WithKey([]byte("video")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	small, err := claimcheck.NewMessage([]byte("thumbnail"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = client.SendMessages(streamId, topicId, iggcon.PartitionId(1), []iggcon.MessengerMessage{large, small}); err != nil {
+		t.Fatal(err)
+	}
+
+	partitionId := uint32(1)
+	raw, err := server.PollMessages(streamId, topicId, iggcon.DefaultConsumer(), iggcon.FirstPollingStrategy(), 10, false, &partitionId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(raw.Messages) != 2 || len(raw.Messages[0].Payload) > 64 {
+		t.Fatalf("expected the large payload to be replaced by its reference, got %d messages", len(raw.Messages))
+	}
+	polled, err := client.PollMessages(streamId, topicId, iggcon.DefaultConsumer(), iggcon.FirstPollingStrategy(), 10, false, &partitionId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(polled.Messages[0].Payload, huge) || string(polled.Messages[1].Payload) != "thumbnail" {
+		t.Fatal("expected the payloads to be resolved")
+	}
+	if _, ok := polled.Messages[0].UserHeader(claimcheck.ReferenceHeader); ok {
+		t.Fatal("expected the reference header to be removed")
+	}
+	if string(polled.Messages[0].Key()) != "video" {
+		t.Fatalf("expected the key to be kept, got %q", polled.Messages[0].Key())
+	}
+
+	// a tampered payload fails the poll
+	if err = os.WriteFile(filepath.Join(dir, string(raw.Messages[0].Payload)), []byte("tampered"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = client.PollMessages(streamId, topicId, iggcon.DefaultConsumer(), iggcon.FirstPollingStrategy(), 10, false, &partitionId); err == nil {
+		t.Fatal("expected the tampered payload to fail the poll")
+	}
+
+	// the payloads of the rejected messages are deleted
+	rejected, _ := claimcheck.NewMessage(bytes.Repeat([]byte("y"), 2048))
+	if err = client.SendMessages(streamId, iggcon.MustIdentifier("missing"), iggcon.PartitionId(1), []iggcon.MessengerMessage{rejected}); err == nil {
+		t.Fatal("expected the topic to be missing")
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected only the first payload to be stored, got %d files", len(entries))
+	}
+}
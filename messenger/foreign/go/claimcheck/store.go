@@ -0,0 +1,109 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package claimcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrNotFound is returned by a BlobStore getting a blob it does not hold.
+var ErrNotFound = errors.New("claimcheck: blob not found")
+
+// BlobStore stores the payloads offloaded by a ClaimCheck under the references it generates.
+// Implementations backed by S3, GCS or any object storage map the reference to the key of an
+// object, and usually expire the objects after the retention of the topics, since the blobs are
+// not deleted once their messages expire.
+type BlobStore interface {
+	// Put stores the blob under the reference.
+	Put(ctx context.Context, reference string, blob []byte) error
+	// Get returns the blob stored under the reference, or an error wrapping ErrNotFound.
+	Get(ctx context.Context, reference string) ([]byte, error)
+	// Delete removes the blob stored under the reference, if any.
+	Delete(ctx context.Context, reference string) error
+}
+
+// FileStore is a BlobStore keeping every blob in a file of a directory, which may be shared by
+// the producers and the consumers over a network file system.
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore creates a FileStore in the directory, created when missing.
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+func (s *FileStore) Put(_ context.Context, reference string, blob []byte) error {
+	path, err := s.path(reference)
+	if err != nil {
+		return err
+	}
+	// the blob is written to a temporary file first, so a reader never sees a partial blob
+	file, err := os.CreateTemp(s.dir, ".put-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	if _, err = file.Write(blob); err != nil {
+		file.Close()
+		return err
+	}
+	if err = file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
+
+func (s *FileStore) Get(_ context.Context, reference string) ([]byte, error) {
+	path, err := s.path(reference)
+	if err != nil {
+		return nil, err
+	}
+	blob, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, reference)
+	}
+	return blob, err
+}
+
+func (s *FileStore) Delete(_ context.Context, reference string) error {
+	path, err := s.path(reference)
+	if err != nil {
+		return err
+	}
+	if err = os.Remove(path); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// path returns the file of the reference, which must not escape the directory.
+func (s *FileStore) path(reference string) (string, error) {
+	if reference == "" || strings.ContainsAny(reference, `/\`) || reference == "." || reference == ".." {
+		return "", fmt.Errorf("claimcheck: invalid reference %q", reference)
+	}
+	return filepath.Join(s.dir, reference), nil
+}
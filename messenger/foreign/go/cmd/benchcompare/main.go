@@ -0,0 +1,89 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Command benchcompare compares two outputs of go test -bench and exits with status 1 when a
+// benchmark regressed more than the threshold, to gate performance regressions in CI.
+//
+//	benchcompare [-threshold 0.1] [-units ns/op,allocs/op] <base> <head>
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/apache/messenger/foreign/go/benchmarks"
+)
+
+func main() {
+	threshold := flag.Float64("threshold", 0.1, "relative regression failing the comparison, 0.1 for 10%")
+	units := flag.String("units", "ns/op,allocs/op,MB/s,msgs/s", "comma-separated units checked for regressions")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] <base> <head>\n\nFlags:\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	base, err := parse(flag.Arg(0))
+	if err != nil {
+		fail(err)
+	}
+	head, err := parse(flag.Arg(1))
+	if err != nil {
+		fail(err)
+	}
+	checked := map[string]bool{}
+	for _, unit := range strings.Split(*units, ",") {
+		checked[strings.TrimSpace(unit)] = true
+	}
+
+	regressions := 0
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "benchmark\tunit\tbase\thead\tdelta\t")
+	for _, c := range benchmarks.Compare(base, head) {
+		status := ""
+		if checked[c.Unit] && c.Regressed(*threshold) {
+			status = "REGRESSION"
+			regressions++
+		}
+		fmt.Fprintf(w, "%s\t%s\t%.2f\t%.2f\t%+.1f%%\t%s\n", c.Name, c.Unit, c.Base, c.Head, c.Delta*100, status)
+	}
+	_ = w.Flush()
+	if regressions > 0 {
+		fmt.Fprintf(os.Stderr, "%d metric(s) regressed more than %.1f%%\n", regressions, *threshold*100)
+		os.Exit(1)
+	}
+}
+
+func parse(path string) (map[string]benchmarks.Result, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return benchmarks.ParseResults(file)
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "error:", err)
+	os.Exit(1)
+}
@@ -0,0 +1,163 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/messenger/foreign/go/admin"
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengercli"
+)
+
+type groupFlags struct {
+	set    *flag.FlagSet
+	stream *string
+	topic  *string
+	group  *string
+}
+
+func newGroupFlags(name string) groupFlags {
+	set := flag.NewFlagSet(name, flag.ExitOnError)
+	return groupFlags{
+		set:    set,
+		stream: set.String("stream", "", "stream ID or name"),
+		topic:  set.String("topic", "", "topic ID or name"),
+		group:  set.String("group", "", "consumer group ID or name"),
+	}
+}
+
+func (f groupFlags) identifiers() (streamId, topicId, groupId iggcon.Identifier, err error) {
+	if streamId, err = parseIdentifier(*f.stream); err != nil {
+		return streamId, topicId, groupId, fmt.Errorf("invalid stream: %w", err)
+	}
+	if topicId, err = parseIdentifier(*f.topic); err != nil {
+		return streamId, topicId, groupId, fmt.Errorf("invalid topic: %w", err)
+	}
+	if groupId, err = parseIdentifier(*f.group); err != nil {
+		return streamId, topicId, groupId, fmt.Errorf("invalid group: %w", err)
+	}
+	return streamId, topicId, groupId, nil
+}
+
+func deleteGroup(cli messengercli.Client, args []string) error {
+	flags := newGroupFlags("group delete")
+	_ = flags.set.Parse(args)
+	streamId, topicId, groupId, err := flags.identifiers()
+	if err != nil {
+		return err
+	}
+
+	if err = cli.DeleteConsumerGroup(streamId, topicId, groupId); err != nil {
+		return err
+	}
+	fmt.Printf("Consumer group %s was deleted.\n", *flags.group)
+	return nil
+}
+
+func groupLag(cli messengercli.Client, args []string) error {
+	flags := newGroupFlags("group lag")
+	_ = flags.set.Parse(args)
+	streamId, topicId, groupId, err := flags.identifiers()
+	if err != nil {
+		return err
+	}
+
+	lag, err := admin.GetConsumerLag(cli, streamId, topicId, iggcon.NewGroupConsumer(groupId))
+	if err != nil {
+		return err
+	}
+	for _, partition := range lag.Partitions {
+		fmt.Printf("partition %d: offset %d / %d, lag %d\n", partition.PartitionId, partition.ConsumerOffset, partition.EndOffset, partition.Lag)
+	}
+	fmt.Printf("total lag: %d\n", lag.TotalLag)
+	return nil
+}
+
+func resetGroupOffsets(cli messengercli.Client, args []string) error {
+	flags := newGroupFlags("group reset-offsets")
+	toEarliest := flags.set.Bool("to-earliest", false, "consume from the first message of every partition")
+	toLatest := flags.set.Bool("to-latest", false, "skip every message already in the partitions")
+	toTimestamp := flags.set.String("to-timestamp", "", "consume from the first message appended at or after an RFC 3339 timestamp")
+	toOffsets := flags.set.String("to-offsets", "", "consume from specific offsets, e.g. 1:100,2:250 (partition:offset)")
+	dryRun := flags.set.Bool("dry-run", false, "only print the offsets without storing them")
+	_ = flags.set.Parse(args)
+	streamId, topicId, groupId, err := flags.identifiers()
+	if err != nil {
+		return err
+	}
+
+	var reset admin.OffsetReset
+	switch {
+	case *toEarliest:
+		reset.Kind = admin.ResetToEarliest
+	case *toLatest:
+		reset.Kind = admin.ResetToLatest
+	case *toTimestamp != "":
+		reset.Kind = admin.ResetToTimestamp
+		if reset.Timestamp, err = time.Parse(time.RFC3339, *toTimestamp); err != nil {
+			return fmt.Errorf("invalid timestamp: %w", err)
+		}
+	case *toOffsets != "":
+		reset.Kind = admin.ResetToOffsets
+		if reset.Offsets, err = parsePartitionOffsets(*toOffsets); err != nil {
+			return err
+		}
+	default:
+		return errors.New("one of -to-earliest, -to-latest, -to-timestamp or -to-offsets is required")
+	}
+
+	resets, err := admin.ResetConsumerGroupOffsets(cli, streamId, topicId, groupId, reset, *dryRun)
+	if err != nil {
+		return err
+	}
+	if *dryRun {
+		fmt.Println("Dry run, no offset was stored. Next offsets to consume:")
+	} else {
+		fmt.Println("Next offsets to consume:")
+	}
+	for _, reset := range resets {
+		fmt.Println(" ", reset)
+	}
+	return nil
+}
+
+func parsePartitionOffsets(value string) (map[uint32]uint64, error) {
+	offsets := make(map[uint32]uint64)
+	for _, pair := range strings.Split(value, ",") {
+		partition, offset, ok := strings.Cut(pair, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid partition offset %q, expected partition:offset", pair)
+		}
+		partitionId, err := strconv.ParseUint(partition, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid partition %q: %w", partition, err)
+		}
+		offsetValue, err := strconv.ParseUint(offset, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid offset %q: %w", offset, err)
+		}
+		offsets[uint32(partitionId)] = offsetValue
+	}
+	return offsets, nil
+}
@@ -0,0 +1,117 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package main
+
+import (
+	"maps"
+	"testing"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengertest"
+)
+
+func TestParsePartitionOffsets(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected map[uint32]uint64
+	}{
+		{value: "1:100", expected: map[uint32]uint64{1: 100}},
+		{value: "1:100,2:250", expected: map[uint32]uint64{1: 100, 2: 250}},
+		{value: "1"},
+		{value: "one:100"},
+		{value: "1:-1"},
+		{value: "1:100,"},
+	}
+	for _, tt := range tests {
+		offsets, err := parsePartitionOffsets(tt.value)
+		if tt.expected == nil {
+			if err == nil {
+				t.Errorf("expected %q to be rejected, got %v", tt.value, offsets)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("expected %q to be parsed, got %v", tt.value, err)
+		} else if !maps.Equal(offsets, tt.expected) {
+			t.Errorf("expected %q to be parsed as %v, got %v", tt.value, tt.expected, offsets)
+		}
+	}
+}
+
+func TestResetGroupOffsets(t *testing.T) {
+	cli := messengertest.NewClient()
+	streamId, topicId := iggcon.MustIdentifier("stream"), iggcon.MustIdentifier("topic")
+	if _, err := cli.CreateStream("stream", nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cli.CreateTopic(streamId, "topic", 1, iggcon.CompressionAlgorithmNone, 0, 0, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cli.CreateConsumerGroup(streamId, topicId, "group", nil); err != nil {
+		t.Fatal(err)
+	}
+	messages := make([]iggcon.MessengerMessage, 10)
+	for i := range messages {
+		messages[i], _ = iggcon.NewMessengerMessage([]byte("message"))
+	}
+	if err := cli.SendMessages(streamId, topicId, iggcon.PartitionId(1), messages); err != nil {
+		t.Fatal(err)
+	}
+	group := iggcon.NewGroupConsumer(iggcon.MustIdentifier("group"))
+	partitionId := uint32(1)
+	storedOffset := func() *uint64 {
+		offset, err := cli.GetConsumerOffset(group, streamId, topicId, &partitionId)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if offset == nil {
+			return nil
+		}
+		return &offset.StoredOffset
+	}
+
+	flags := []string{"-stream", "stream", "-topic", "topic", "-group", "group"}
+	if err := resetGroupOffsets(cli, append(flags, "-to-offsets", "1:4", "-dry-run")); err != nil {
+		t.Fatal(err)
+	}
+	if offset := storedOffset(); offset != nil {
+		t.Fatalf("expected the dry run to store nothing, got offset %d", *offset)
+	}
+	if err := resetGroupOffsets(cli, append(flags, "-to-offsets", "1:4")); err != nil {
+		t.Fatal(err)
+	}
+	if offset := storedOffset(); offset == nil || *offset != 3 {
+		t.Fatalf("expected the group to consume from offset 4, got %v", offset)
+	}
+	if err := resetGroupOffsets(cli, append(flags, "-to-timestamp", "2026-01-01T00:00:00Z")); err != nil {
+		t.Fatal(err)
+	}
+	if offset := storedOffset(); offset != nil {
+		t.Fatalf("expected the group to consume from the first message, got offset %d", *offset)
+	}
+
+	if err := resetGroupOffsets(cli, flags); err == nil {
+		t.Error("expected a missing reset to be rejected")
+	}
+	if err := resetGroupOffsets(cli, append(flags, "-to-timestamp", "yesterday")); err == nil {
+		t.Error("expected an invalid timestamp to be rejected")
+	}
+	if err := resetGroupOffsets(cli, []string{"-stream", "stream", "-topic", "topic", "-group", "other", "-to-latest"}); err == nil {
+		t.Error("expected a missing group to be reported")
+	}
+}
@@ -0,0 +1,100 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Command messenger-cli performs administrative operations against a Messenger server.
+//
+//	messenger-cli [-address host:port] [-username user] [-password pass] <command> <subcommand> [flags]
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengercli"
+	"github.com/apache/messenger/foreign/go/tcp"
+)
+
+type command func(cli messengercli.Client, args []string) error
+
+var commands = map[string]map[string]command{
+	"group": {
+		"delete":        deleteGroup,
+		"lag":           groupLag,
+		"reset-offsets": resetGroupOffsets,
+	},
+	"topic": {
+		"offsets": topicOffsets,
+		"tail":    tailTopic,
+	},
+}
+
+func main() {
+	address := flag.String("address", "127.0.0.1:8090", "TCP server address")
+	username := flag.String("username", "messenger", "username")
+	password := flag.String("password", "messenger", "password")
+	flag.Usage = usage
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	cmd, ok := commands[args[0]][args[1]]
+	if !ok {
+		usage()
+		os.Exit(2)
+	}
+
+	cli, err := messengercli.NewMessengerClient(messengercli.WithTcp(tcp.WithServerAddress(*address)))
+	if err != nil {
+		fail(err)
+	}
+	if _, err = cli.LoginUser(*username, *password); err != nil {
+		fail(err)
+	}
+	if err = cmd(cli, args[2:]); err != nil {
+		fail(err)
+	}
+}
+
+func usage() {
+	fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] <command> <subcommand> [flags]\n\nCommands:\n", os.Args[0])
+	for name, subcommands := range commands {
+		for subname := range subcommands {
+			fmt.Fprintf(flag.CommandLine.Output(), "  %s %s\n", name, subname)
+		}
+	}
+	fmt.Fprintln(flag.CommandLine.Output(), "\nFlags:")
+	flag.PrintDefaults()
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "error:", err)
+	os.Exit(1)
+}
+
+// parseIdentifier parses a numeric ID or, failing that, a name.
+func parseIdentifier(value string) (iggcon.Identifier, error) {
+	if id, err := strconv.ParseUint(value, 10, 32); err == nil {
+		return iggcon.NewIdentifier(uint32(id))
+	}
+	return iggcon.NewIdentifier(value)
+}
@@ -0,0 +1,81 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/apache/messenger/foreign/go/admin"
+	"github.com/apache/messenger/foreign/go/messengercli"
+)
+
+func topicOffsets(cli messengercli.Client, args []string) error {
+	set := flag.NewFlagSet("topic offsets", flag.ExitOnError)
+	stream := set.String("stream", "", "stream ID or name")
+	topic := set.String("topic", "", "topic ID or name")
+	_ = set.Parse(args)
+	streamId, err := parseIdentifier(*stream)
+	if err != nil {
+		return fmt.Errorf("invalid stream: %w", err)
+	}
+	topicId, err := parseIdentifier(*topic)
+	if err != nil {
+		return fmt.Errorf("invalid topic: %w", err)
+	}
+
+	offsets, err := admin.GetTopicOffsets(cli, streamId, topicId)
+	if err != nil {
+		return err
+	}
+	for _, partition := range offsets {
+		fmt.Printf("partition %d: end offset %d, %d messages, %d bytes\n", partition.PartitionId, partition.EndOffset, partition.MessagesCount, partition.SizeBytes)
+	}
+	return nil
+}
+
+func tailTopic(cli messengercli.Client, args []string) error {
+	set := flag.NewFlagSet("topic tail", flag.ExitOnError)
+	stream := set.String("stream", "", "stream ID or name")
+	topic := set.String("topic", "", "topic ID or name")
+	partition := set.Uint("partition", 1, "partition ID")
+	n := set.Uint("n", 10, "number of messages to print before following")
+	follow := set.Bool("f", false, "keep printing the messages appended to the partition")
+	_ = set.Parse(args)
+	streamId, err := parseIdentifier(*stream)
+	if err != nil {
+		return fmt.Errorf("invalid stream: %w", err)
+	}
+	topicId, err := parseIdentifier(*topic)
+	if err != nil {
+		return fmt.Errorf("invalid topic: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	messages, errs := messengercli.Tail(ctx, cli, streamId, topicId, uint32(*partition), uint32(*n), *follow)
+	for message := range messages {
+		header := message.Message.Header
+		fmt.Printf("%d\t%s\t%s\n", header.Offset, time.UnixMicro(int64(header.Timestamp)).Format(time.RFC3339Nano), message.Message.Payload)
+	}
+	return <-errs
+}
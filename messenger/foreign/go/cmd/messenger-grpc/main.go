@@ -0,0 +1,74 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Command messenger-grpc serves the Messenger gRPC service of the grpcapi package, calling a
+// Messenger server with the client logged in as the user.
+//
+//	messenger-grpc [-address host:port] [-username user] [-password pass] [-listen host:port]
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/apache/messenger/foreign/go/grpcapi"
+	"github.com/apache/messenger/foreign/go/messengercli"
+	"github.com/apache/messenger/foreign/go/tcp"
+	"google.golang.org/grpc"
+)
+
+func main() {
+	address := flag.String("address", "127.0.0.1:8090", "TCP server address")
+	username := flag.String("username", "messenger", "username")
+	password := flag.String("password", "messenger", "password")
+	listen := flag.String("listen", "127.0.0.1:50051", "gRPC listen address")
+	flag.Parse()
+
+	cli, err := messengercli.NewMessengerClient(messengercli.WithTcp(tcp.WithServerAddress(*address)))
+	if err != nil {
+		fail(err)
+	}
+	if _, err = cli.LoginUser(*username, *password); err != nil {
+		fail(err)
+	}
+	listener, err := net.Listen("tcp", *listen)
+	if err != nil {
+		fail(err)
+	}
+
+	server := grpc.NewServer()
+	grpcapi.Register(server, cli)
+	go func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		<-signals
+		server.GracefulStop()
+	}()
+	fmt.Println("serving gRPC on", listener.Addr())
+	if err = server.Serve(listener); err != nil {
+		fail(err)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "error:", err)
+	os.Exit(1)
+}
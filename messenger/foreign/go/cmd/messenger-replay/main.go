@@ -0,0 +1,92 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Command messenger-replay feeds the frames captured with tcp.WithFrameCapture back through the
+// deserializers of the SDK, printing every exchange and the responses which failed to decode.
+//
+//	messenger-replay [-compression none] [-verbose] <capture file>
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/apache/messenger/foreign/go/capture"
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+)
+
+// maxValueLength is the length the decoded values are truncated to, unless verbose.
+const maxValueLength = 160
+
+func main() {
+	compression := flag.String("compression", string(iggcon.MESSAGE_COMPRESSION_NONE), "compression of the polled messages")
+	verbose := flag.Bool("verbose", false, "print the decoded values in full")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] <capture file>\n\nFlags:\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	file, err := os.Open(flag.Arg(0))
+	if err != nil {
+		fail(err)
+	}
+	defer file.Close()
+
+	failures := 0
+	err = capture.Replay(capture.NewReader(file), iggcon.MessengerMessageCompression(*compression), func(exchange capture.Exchange) {
+		request := exchange.Request
+		line := fmt.Sprintf("%s command=%d dialect=%s request=%dB",
+			request.Time.Format(time.RFC3339Nano), request.Command, request.Dialect, len(request.Data))
+		switch response := exchange.Response; {
+		case response == nil:
+			line += " no response"
+		default:
+			line += fmt.Sprintf(" status=%d response=%dB latency=%s", exchange.Status, len(response.Data), response.Time.Sub(request.Time))
+		}
+		switch {
+		case exchange.Err != nil:
+			failures++
+			line += " DECODE ERROR: " + exchange.Err.Error()
+		case exchange.Value != nil:
+			value := fmt.Sprintf("%+v", exchange.Value)
+			if !*verbose && len(value) > maxValueLength {
+				value = value[:maxValueLength] + "..."
+			}
+			line += " " + value
+		}
+		fmt.Println(line)
+	})
+	if err != nil {
+		fail(err)
+	}
+	if failures > 0 {
+		fmt.Fprintf(os.Stderr, "%d response(s) failed to decode\n", failures)
+		os.Exit(1)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "error:", err)
+	os.Exit(1)
+}
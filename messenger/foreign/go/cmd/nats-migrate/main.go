@@ -0,0 +1,97 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Command nats-migrate mirrors JetStream streams to Messenger streams and topics, a topic per
+// subject filter, so the services can move to the natsbridge package. The stream configurations
+// are read from JSON files, like the output of `nats stream info --json <stream>`.
+//
+//	nats-migrate [-address host:port] [-username user] [-password pass] [-dry-run] <stream file>...
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/apache/messenger/foreign/go/admin"
+	"github.com/apache/messenger/foreign/go/messengercli"
+	"github.com/apache/messenger/foreign/go/natsbridge"
+	"github.com/apache/messenger/foreign/go/tcp"
+)
+
+func main() {
+	address := flag.String("address", "127.0.0.1:8090", "TCP server address")
+	username := flag.String("username", "messenger", "username")
+	password := flag.String("password", "messenger", "password")
+	dryRun := flag.Bool("dry-run", false, "print the changes without applying them")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] <stream file>...\n\nFlags:\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var streams []natsbridge.StreamConfig
+	for _, path := range flag.Args() {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			fail(err)
+		}
+		stream, err := natsbridge.ParseStreamConfig(data)
+		if err != nil {
+			fail(fmt.Errorf("%s: %w", path, err))
+		}
+		streams = append(streams, stream)
+	}
+	spec, err := natsbridge.Spec(streams...)
+	if err != nil {
+		fail(err)
+	}
+
+	cli, err := messengercli.NewMessengerClient(messengercli.WithTcp(tcp.WithServerAddress(*address)))
+	if err != nil {
+		fail(err)
+	}
+	if _, err = cli.LoginUser(*username, *password); err != nil {
+		fail(err)
+	}
+
+	var changes []admin.Change
+	if *dryRun {
+		changes, err = admin.Plan(cli, spec)
+	} else {
+		changes, err = admin.Provision(context.Background(), cli, spec)
+	}
+	for _, change := range changes {
+		fmt.Println(change)
+	}
+	if err != nil {
+		fail(err)
+	}
+	if len(changes) == 0 {
+		fmt.Println("the streams are already mirrored")
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "error:", err)
+	os.Exit(1)
+}
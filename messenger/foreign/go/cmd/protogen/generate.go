@@ -0,0 +1,220 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package main
+
+import (
+	"fmt"
+	"go/format"
+	"strings"
+)
+
+const licenseHeader = `// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Code generated by protogen from protocol.json. DO NOT EDIT.
+
+`
+
+type generatedFiles struct {
+	commandCodes []byte
+	contracts    []byte
+	serializers  []byte
+}
+
+func generate(spec *Spec) (*generatedFiles, error) {
+	var files generatedFiles
+	var err error
+	if files.commandCodes, err = render(generateCommandCodes(spec)); err != nil {
+		return nil, err
+	}
+	if files.contracts, err = render(generateContracts(spec)); err != nil {
+		return nil, err
+	}
+	if files.serializers, err = render(generateSerializers(spec)); err != nil {
+		return nil, err
+	}
+	return &files, nil
+}
+
+func render(source string) ([]byte, error) {
+	formatted, err := format.Source([]byte(licenseHeader + source))
+	if err != nil {
+		return nil, fmt.Errorf("invalid generated code: %w\n%s", err, source)
+	}
+	return formatted, nil
+}
+
+func generateCommandCodes(spec *Spec) string {
+	var b strings.Builder
+	b.WriteString("package iggcon\n\ntype CommandCode int\n\nconst (\n")
+	for _, command := range spec.Commands {
+		fmt.Fprintf(&b, "%sCode CommandCode = %d\n", command.Name, command.Code)
+	}
+	b.WriteString(")\n\nvar commands = map[CommandCode]commandInfo{\n")
+	for _, command := range spec.Commands {
+		fmt.Fprintf(&b, "%sCode: {name: %q", command.Name, command.Name)
+		if command.Permission != "" {
+			fmt.Fprintf(&b, ", permission: %q", command.Permission)
+		}
+		if len(command.Resources) > 0 {
+			fmt.Fprintf(&b, ", resources: %#v", command.Resources)
+		}
+		b.WriteString("},\n")
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func generateContracts(spec *Spec) string {
+	var b strings.Builder
+	b.WriteString("package iggcon\n")
+	for _, t := range spec.Types {
+		b.WriteString("\n")
+		writeDoc(&b, t.Doc)
+		fmt.Fprintf(&b, "type %s struct {\n", t.Name)
+		for _, field := range t.Fields {
+			fmt.Fprintf(&b, "%s %s `json:\"%s\"`\n", field.Name, goTypes[field.Type], field.jsonName())
+		}
+		b.WriteString("}\n")
+	}
+	return b.String()
+}
+
+func generateSerializers(spec *Spec) string {
+	var b strings.Builder
+	b.WriteString("package binaryserialization\n")
+	if imports := serializerImports(spec); len(imports) > 0 {
+		fmt.Fprintf(&b, "\nimport (\n%s)\n", strings.Join(imports, ""))
+	}
+	for _, t := range spec.Types {
+		if t.Serializer != "" {
+			b.WriteString("\n")
+			writeSerializer(&b, t)
+		}
+		if t.Deserializer != "" {
+			b.WriteString("\n")
+			writeDeserializer(&b, t)
+		}
+	}
+	return b.String()
+}
+
+func serializerImports(spec *Spec) []string {
+	var usesContracts, usesBinary bool
+	for _, t := range spec.Types {
+		if t.Serializer == "" && t.Deserializer == "" {
+			continue
+		}
+		usesContracts = true
+		for _, field := range t.Fields {
+			usesBinary = usesBinary || field.Type == U32 || field.Type == OptionalU32 || field.Type == U64
+		}
+	}
+	var imports []string
+	if usesBinary {
+		imports = append(imports, "\"encoding/binary\"\n\n")
+	}
+	if usesContracts {
+		imports = append(imports, "iggcon \"github.com/apache/messenger/foreign/go/contracts\"\n")
+	}
+	return imports
+}
+
+func writeSerializer(b *strings.Builder, t Type) {
+	fmt.Fprintf(b, "func %s(request iggcon.%s) []byte {\nsize := 0\n", t.Serializer, t.Name)
+	for _, field := range t.Fields {
+		value := "request." + field.Name
+		switch field.Type {
+		case U8:
+			b.WriteString("size += 1\n")
+		case U32, OptionalU32:
+			b.WriteString("size += 4\n")
+		case U64:
+			b.WriteString("size += 8\n")
+		case String:
+			fmt.Fprintf(b, "size += 1 + len(%s)\n", value)
+		case Identifier:
+			fmt.Fprintf(b, "size += 2 + %s.Length\n", value)
+		case Consumer:
+			fmt.Fprintf(b, "size += 3 + %s.Id.Length\n", value)
+		}
+	}
+
+	b.WriteString("\nbytes := make([]byte, size)\nposition := 0\n")
+	for _, field := range t.Fields {
+		value := "request." + field.Name
+		switch field.Type {
+		case U8:
+			fmt.Fprintf(b, "bytes[position] = %s\nposition += 1\n", value)
+		case U32:
+			fmt.Fprintf(b, "binary.LittleEndian.PutUint32(bytes[position:position+4], %s)\nposition += 4\n", value)
+		case OptionalU32:
+			fmt.Fprintf(b, "if %[1]s != nil {\nbinary.LittleEndian.PutUint32(bytes[position:position+4], *%[1]s)\n}\nposition += 4\n", value)
+		case U64:
+			fmt.Fprintf(b, "binary.LittleEndian.PutUint64(bytes[position:position+8], %s)\nposition += 8\n", value)
+		case String:
+			fmt.Fprintf(b, "bytes[position] = byte(len(%[1]s))\nposition += 1 + copy(bytes[position+1:], %[1]s)\n", value)
+		case Identifier:
+			fmt.Fprintf(b, "position += copy(bytes[position:], SerializeIdentifier(%s))\n", value)
+		case Consumer:
+			fmt.Fprintf(b, "bytes[position] = byte(%[1]s.Kind)\nposition += 1 + copy(bytes[position+1:], SerializeIdentifier(%[1]s.Id))\n", value)
+		}
+	}
+	b.WriteString("return bytes\n}\n")
+}
+
+func writeDeserializer(b *strings.Builder, t Type) {
+	fmt.Fprintf(b, "func %s(payload []byte) *iggcon.%s {\nif len(payload) == 0 {\nreturn nil\n}\n\nresult := &iggcon.%[2]s{}\nposition := 0\n", t.Deserializer, t.Name)
+	for _, field := range t.Fields {
+		value := "result." + field.Name
+		switch field.Type {
+		case U8:
+			fmt.Fprintf(b, "%s = payload[position]\nposition += 1\n", value)
+		case U32:
+			fmt.Fprintf(b, "%s = binary.LittleEndian.Uint32(payload[position : position+4])\nposition += 4\n", value)
+		case U64:
+			fmt.Fprintf(b, "%s = binary.LittleEndian.Uint64(payload[position : position+8])\nposition += 8\n", value)
+		case String:
+			fmt.Fprintf(b, "%s = string(payload[position+1 : position+1+int(payload[position])])\nposition += 1 + int(payload[position])\n", value)
+		}
+	}
+	b.WriteString("return result\n}\n")
+}
+
+func writeDoc(b *strings.Builder, doc string) {
+	if doc == "" {
+		return
+	}
+	for _, line := range strings.Split(strings.TrimSpace(doc), "\n") {
+		fmt.Fprintf(b, "// %s\n", line)
+	}
+}
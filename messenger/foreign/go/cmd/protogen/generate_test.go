@@ -0,0 +1,76 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestGeneratedFilesUpToDate fails when protocol.json was edited without running go generate:
+// it runs the generator of the go:generate directive into temporary directories and compares
+// every file it writes with the one of the tree.
+func TestGeneratedFilesUpToDate(t *testing.T) {
+	dirs := map[string]string{
+		"../../contracts":            t.TempDir(),
+		"../../binary_serialization": t.TempDir(),
+	}
+	if err := run("../../contracts/protocol.json", dirs["../../contracts"], dirs["../../binary_serialization"]); err != nil {
+		t.Fatal(err)
+	}
+
+	for dir, generatedDir := range dirs {
+		entries, err := os.ReadDir(generatedDir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, entry := range entries {
+			expected, err := os.ReadFile(filepath.Join(generatedDir, entry.Name()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			path := filepath.Join(dir, entry.Name())
+			actual, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(actual, expected) {
+				t.Errorf("%s is out of date, run go generate ./contracts", path)
+			}
+		}
+	}
+}
+
+func TestSpecValidation(t *testing.T) {
+	spec := &Spec{Commands: []Command{{Name: "Ping", Code: 1}, {Name: "Pong", Code: 1}}}
+	if err := spec.validate(); err == nil {
+		t.Error("expected duplicate command codes to be rejected")
+	}
+
+	spec = &Spec{Commands: []Command{{Name: "GetTopic", Code: 300, Resources: []string{"stream", "partition"}}}}
+	if err := spec.validate(); err == nil {
+		t.Error("expected an unknown resource to be rejected")
+	}
+
+	spec = &Spec{Types: []Type{{Name: "Info", Deserializer: "DeserializeInfo", Fields: []Field{{Name: "StreamId", Type: Identifier}}}}}
+	if err := spec.validate(); err == nil {
+		t.Error("expected an identifier field to be rejected in a deserializer")
+	}
+}
@@ -0,0 +1,71 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Command protogen generates the command codes, the contracts and the binary serializers
+// from the protocol definition, so the contracts and binary_serialization packages cannot
+// drift apart. It is run through go:generate from the contracts package:
+//
+//	protogen -spec protocol.json -contracts . -serializers ../binary_serialization
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Names of the generated files.
+const (
+	commandCodesFile = "command_codes.go"
+	contractsFile    = "protocol_gen.go"
+	serializersFile  = "protocol_gen.go"
+)
+
+func main() {
+	specPath := flag.String("spec", "protocol.json", "protocol definition file")
+	contractsDir := flag.String("contracts", ".", "directory of the contracts package")
+	serializersDir := flag.String("serializers", "../binary_serialization", "directory of the binary_serialization package")
+	flag.Parse()
+
+	if err := run(*specPath, *contractsDir, *serializersDir); err != nil {
+		fmt.Fprintf(os.Stderr, "protogen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(specPath, contractsDir, serializersDir string) error {
+	spec, err := loadSpec(specPath)
+	if err != nil {
+		return err
+	}
+	files, err := generate(spec)
+	if err != nil {
+		return err
+	}
+	outputs := map[string][]byte{
+		filepath.Join(contractsDir, commandCodesFile):  files.commandCodes,
+		filepath.Join(contractsDir, contractsFile):     files.contracts,
+		filepath.Join(serializersDir, serializersFile): files.serializers,
+	}
+	for path, content := range outputs {
+		if err = os.WriteFile(path, content, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
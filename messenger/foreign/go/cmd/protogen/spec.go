@@ -0,0 +1,153 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"unicode"
+)
+
+// Spec is the protocol definition.
+type Spec struct {
+	// Commands are the command codes, in the order they are emitted.
+	Commands []Command `json:"commands"`
+	// Types are the requests and responses whose wire layout is generated.
+	Types []Type `json:"types"`
+}
+
+type Command struct {
+	Name string `json:"name"`
+	Code int    `json:"code"`
+	// Permission is the global permission granting the command, if any.
+	Permission string `json:"permission"`
+	// Resources are the kinds of the identifiers the payload of the command starts with.
+	Resources []string `json:"resources"`
+}
+
+// resourceKinds are the kinds of the resources a command payload can start with: a consumer is
+// written as its kind followed by its identifier, the others as an identifier.
+var resourceKinds = map[string]bool{"consumer": true, "stream": true, "topic": true, "group": true, "user": true}
+
+// Type is a struct of the contracts package with its wire layout, the fields are listed
+// in the order they are written on the wire.
+type Type struct {
+	Name string `json:"name"`
+	Doc  string `json:"doc"`
+	// Serializer is the name of the generated serializer function, if any.
+	Serializer string `json:"serializer"`
+	// Deserializer is the name of the generated deserializer function, if any.
+	// It returns nil for an empty payload.
+	Deserializer string  `json:"deserializer"`
+	Fields       []Field `json:"fields"`
+}
+
+type Field struct {
+	Name string    `json:"name"`
+	Type FieldType `json:"type"`
+	// Json is the JSON name of the field, the lower camel case name by default.
+	Json string `json:"json"`
+}
+
+// FieldType is the wire type of a field.
+type FieldType string
+
+const (
+	U8          FieldType = "u8"
+	U32         FieldType = "u32"
+	U64         FieldType = "u64"
+	String      FieldType = "string"       // u8 length followed by the bytes
+	Identifier  FieldType = "identifier"   // kind, length and value
+	Consumer    FieldType = "consumer"     // kind followed by the identifier
+	OptionalU32 FieldType = "optional_u32" // a nil value is written as 0
+)
+
+var goTypes = map[FieldType]string{
+	U8:          "uint8",
+	U32:         "uint32",
+	U64:         "uint64",
+	String:      "string",
+	Identifier:  "Identifier",
+	Consumer:    "Consumer",
+	OptionalU32: "*uint32",
+}
+
+// deserializable are the field types a deserializer can read.
+var deserializable = map[FieldType]bool{U8: true, U32: true, U64: true, String: true}
+
+func loadSpec(path string) (*Spec, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var spec Spec
+	if err = json.Unmarshal(content, &spec); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err = spec.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &spec, nil
+}
+
+func (s *Spec) validate() error {
+	names := make(map[string]bool)
+	codes := make(map[int]string)
+	for _, command := range s.Commands {
+		if names[command.Name] {
+			return fmt.Errorf("duplicate command %s", command.Name)
+		}
+		if other, ok := codes[command.Code]; ok {
+			return fmt.Errorf("commands %s and %s share the code %d", other, command.Name, command.Code)
+		}
+		names[command.Name] = true
+		codes[command.Code] = command.Name
+		for _, resource := range command.Resources {
+			if !resourceKinds[resource] {
+				return fmt.Errorf("%s: unknown resource %q", command.Name, resource)
+			}
+		}
+	}
+
+	types := make(map[string]bool)
+	for _, t := range s.Types {
+		if types[t.Name] {
+			return fmt.Errorf("duplicate type %s", t.Name)
+		}
+		types[t.Name] = true
+		for _, field := range t.Fields {
+			if _, ok := goTypes[field.Type]; !ok {
+				return fmt.Errorf("%s.%s: unknown type %q", t.Name, field.Name, field.Type)
+			}
+			if t.Deserializer != "" && !deserializable[field.Type] {
+				return fmt.Errorf("%s.%s: type %q cannot be deserialized", t.Name, field.Name, field.Type)
+			}
+		}
+	}
+	return nil
+}
+
+func (f Field) jsonName() string {
+	if f.Json != "" {
+		return f.Json
+	}
+	name := []rune(f.Name)
+	name[0] = unicode.ToLower(name[0])
+	return string(name)
+}
@@ -0,0 +1,99 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package codec encodes and decodes the payloads of the messages, so the call sites send and poll
+// typed values. The encoding of a payload is recorded in the ContentTypeHeader of its message,
+// which selects the codec decoding it. A Serde encodes every topic with its own codec, JSON, CBOR
+// or Protobuf, while SendProto and PollProto handle the Protobuf messages of any topic.
+package codec
+
+import (
+	"fmt"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+)
+
+// ContentTypeHeader is the user header carrying the content type of the payload.
+const ContentTypeHeader = "content-type"
+
+// Content types of the payloads.
+const (
+	ContentTypeProtobuf = "application/x-protobuf"
+	ContentTypeJSON     = "application/json"
+	ContentTypeCBOR     = "application/cbor"
+)
+
+// DecodeMode tells how strictly the payloads are decoded.
+type DecodeMode uint8
+
+const (
+	// Lenient ignores the unknown fields of the payloads, and decodes the payloads without
+	// content type with the codec of their topic.
+	Lenient DecodeMode = iota
+	// Strict rejects the payloads with unknown fields, and the messages without content type.
+	Strict
+)
+
+// Codec encodes values as the payloads of a content type.
+type Codec interface {
+	ContentType() string
+	Marshal(v any) ([]byte, error)
+	// Unmarshal decodes data into v, which must be a pointer.
+	Unmarshal(data []byte, v any, mode DecodeMode) error
+}
+
+// Received is a value decoded from a polled message.
+type Received[T any] struct {
+	Value T
+	iggcon.ReceivedMessage
+}
+
+// ContentTypeError reports a message whose content type is not the one expected.
+type ContentTypeError struct {
+	Expected string
+	Actual   string
+	Offset   uint64
+}
+
+func (e *ContentTypeError) Error() string {
+	return fmt.Sprintf("codec: message %d has content type %q, expected %q", e.Offset, e.Actual, e.Expected)
+}
+
+// newMessage creates a message with the payload and its content type.
+func newMessage(payload []byte, contentType string) (iggcon.MessengerMessage, error) {
+	return iggcon.NewMessengerMessage(payload, iggcon.WithUserHeaders(map[iggcon.HeaderKey]iggcon.HeaderValue{
+		{Value: ContentTypeHeader}: iggcon.NewStringHeaderValue(contentType),
+	}))
+}
+
+// ContentType returns the content type of a message, empty when it has none.
+func ContentType(message iggcon.MessengerMessage) string {
+	value, ok := message.UserHeader(ContentTypeHeader)
+	if !ok {
+		return ""
+	}
+	contentType, _ := value.AsString()
+	return contentType
+}
+
+// checkContentType checks that a message has the expected content type, or none.
+func checkContentType(message iggcon.MessengerMessage, expected string) error {
+	if actual := ContentType(message); actual != "" && actual != expected {
+		return &ContentTypeError{Expected: expected, Actual: actual, Offset: message.Header.Offset}
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package codec
+
+import (
+	"context"
+	"fmt"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengercli"
+	"google.golang.org/protobuf/proto"
+)
+
+// NewProtoMessage creates a message with the Protobuf encoding of msg as its payload.
+func NewProtoMessage(msg proto.Message) (iggcon.MessengerMessage, error) {
+	payload, err := proto.Marshal(msg)
+	if err != nil {
+		return iggcon.MessengerMessage{}, fmt.Errorf("codec: %w", err)
+	}
+	return newMessage(payload, ContentTypeProtobuf)
+}
+
+// DecodeProto decodes the Protobuf payload of a message into a new T, failing with a
+// *ContentTypeError when the message has another content type.
+func DecodeProto[T proto.Message](message iggcon.MessengerMessage) (T, error) {
+	var zero T
+	if err := checkContentType(message, ContentTypeProtobuf); err != nil {
+		return zero, err
+	}
+	value := zero.ProtoReflect().Type().New().Interface().(T)
+	if err := proto.Unmarshal(message.Payload, value); err != nil {
+		return zero, fmt.Errorf("codec: message %d: %w", message.Header.Offset, err)
+	}
+	return value, nil
+}
+
+// SendProto sends the Protobuf encodings of the messages. An empty message, encoded as an empty
+// payload, cannot be sent.
+func SendProto(
+	client messengercli.DataClient,
+	streamId iggcon.Identifier,
+	topicId iggcon.Identifier,
+	partitioning iggcon.Partitioning,
+	msgs ...proto.Message,
+) error {
+	messages := make([]iggcon.MessengerMessage, len(msgs))
+	for i, msg := range msgs {
+		message, err := NewProtoMessage(msg)
+		if err != nil {
+			return err
+		}
+		messages[i] = message
+	}
+	return client.SendMessages(streamId, topicId, partitioning, messages)
+}
+
+// PollProto polls the messages described by the request, like messengercli.PollMessages, and
+// decodes their Protobuf payloads. The first message that cannot be decoded fails the poll.
+func PollProto[T proto.Message](ctx context.Context, client messengercli.DataClient, request iggcon.PollMessageRequest) ([]Received[T], error) {
+	polled, err := messengercli.PollMessages(ctx, client, request)
+	if err != nil || polled == nil {
+		return nil, err
+	}
+	received := make([]Received[T], len(polled.Messages))
+	for i, message := range polled.Messages {
+		value, err := DecodeProto[T](message)
+		if err != nil {
+			return nil, err
+		}
+		received[i] = Received[T]{
+			Value:           value,
+			ReceivedMessage: iggcon.ReceivedMessage{Message: message, CurrentOffset: polled.CurrentOffset, PartitionId: polled.PartitionId},
+		}
+	}
+	return received, nil
+}
@@ -0,0 +1,69 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package codec
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengertest"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestSendPollProto(t *testing.T) {
+	client := messengertest.NewClient()
+	if _, err := client.CreateStream("orders", nil); err != nil {
+		t.Fatal(err)
+	}
+	streamId, topicId := iggcon.MustIdentifier("orders"), iggcon.MustIdentifier("created")
+	if _, err := client.CreateTopic(streamId, "created", 1, iggcon.CompressionAlgorithmNone, 0, 0, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	err := SendProto(client, streamId, topicId, iggcon.None(), wrapperspb.String("first"), wrapperspb.String("second"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := iggcon.PollMessageRequest{StreamId: streamId, TopicId: topicId, PollingStrategy: iggcon.FirstPollingStrategy(), Count: 10}
+	received, err := PollProto[*wrapperspb.StringValue](context.Background(), client, request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(received) != 2 || received[0].Value.GetValue() != "first" || received[1].Value.GetValue() != "second" {
+		t.Fatalf("expected the 2 values sent, got %v", received)
+	}
+	if contentType := ContentType(received[0].Message); contentType != ContentTypeProtobuf {
+		t.Fatalf("expected the content type %s, got %q", ContentTypeProtobuf, contentType)
+	}
+
+	if err = SendProto(client, streamId, topicId, iggcon.None(), wrapperspb.String("")); err == nil {
+		t.Fatal("expected an empty message to be rejected")
+	}
+
+	message, _ := newMessage([]byte(`{"value":"third"}`), "application/json")
+	if err = client.SendMessages(streamId, topicId, iggcon.None(), []iggcon.MessengerMessage{message}); err != nil {
+		t.Fatal(err)
+	}
+	request.PollingStrategy = iggcon.OffsetPollingStrategy(2)
+	var contentTypeErr *ContentTypeError
+	if _, err = PollProto[*wrapperspb.StringValue](context.Background(), client, request); !errors.As(err, &contentTypeErr) {
+		t.Fatalf("expected a ContentTypeError, got %v", err)
+	}
+}
@@ -0,0 +1,193 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package codec
+
+import (
+	"context"
+	"fmt"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengercli"
+	"google.golang.org/protobuf/proto"
+)
+
+type topicKey struct {
+	stream string
+	topic  string
+}
+
+func newTopicKey(streamId, topicId iggcon.Identifier) topicKey {
+	return topicKey{
+		stream: string(append([]byte{byte(streamId.Kind)}, streamId.Value...)),
+		topic:  string(append([]byte{byte(topicId.Kind)}, topicId.Value...)),
+	}
+}
+
+// Serde encodes the values sent to each topic with the codec of the topic, and decodes the
+// polled messages with the codec of their content type.
+type Serde struct {
+	defaultCodec Codec
+	topics       map[topicKey]Codec
+	codecs       map[string]Codec
+	mode         DecodeMode
+}
+
+type Option func(serde *Serde)
+
+// WithDefaultCodec sets the codec of the topics without one of their own, JSON by default.
+func WithDefaultCodec(codec Codec) Option {
+	return func(serde *Serde) {
+		serde.defaultCodec = codec
+		serde.codecs[codec.ContentType()] = codec
+	}
+}
+
+// WithTopicCodec sets the codec of a topic. A topic is matched by the identifiers it is sent to
+// or polled from, so a topic used by both its name and its ID must be configured under both.
+func WithTopicCodec(streamId, topicId iggcon.Identifier, codec Codec) Option {
+	return func(serde *Serde) {
+		serde.topics[newTopicKey(streamId, topicId)] = codec
+		serde.codecs[codec.ContentType()] = codec
+	}
+}
+
+// WithCodec adds a codec the polled messages of its content type are decoded with, in addition
+// to JSON, CBOR and Protobuf.
+func WithCodec(codec Codec) Option {
+	return func(serde *Serde) {
+		serde.codecs[codec.ContentType()] = codec
+	}
+}
+
+// WithDecodeMode sets how strictly the polled messages are decoded, Lenient by default.
+func WithDecodeMode(mode DecodeMode) Option {
+	return func(serde *Serde) {
+		serde.mode = mode
+	}
+}
+
+// NewSerde creates a Serde encoding every topic as JSON unless configured otherwise.
+func NewSerde(options ...Option) *Serde {
+	serde := &Serde{
+		defaultCodec: JSON,
+		topics:       make(map[topicKey]Codec),
+		codecs: map[string]Codec{
+			ContentTypeJSON:     JSON,
+			ContentTypeCBOR:     CBOR,
+			ContentTypeProtobuf: Protobuf,
+		},
+	}
+	for _, option := range options {
+		option(serde)
+	}
+	return serde
+}
+
+// Codec returns the codec of a topic.
+func (s *Serde) Codec(streamId, topicId iggcon.Identifier) Codec {
+	if codec, ok := s.topics[newTopicKey(streamId, topicId)]; ok {
+		return codec
+	}
+	return s.defaultCodec
+}
+
+// NewMessage creates a message with the encoding of v by the codec of the topic as its payload.
+func (s *Serde) NewMessage(streamId, topicId iggcon.Identifier, v any) (iggcon.MessengerMessage, error) {
+	codec := s.Codec(streamId, topicId)
+	payload, err := codec.Marshal(v)
+	if err != nil {
+		return iggcon.MessengerMessage{}, fmt.Errorf("codec: %w", err)
+	}
+	return newMessage(payload, codec.ContentType())
+}
+
+// Send sends the encodings of the values by the codec of the topic.
+func (s *Serde) Send(
+	client messengercli.DataClient,
+	streamId iggcon.Identifier,
+	topicId iggcon.Identifier,
+	partitioning iggcon.Partitioning,
+	values ...any,
+) error {
+	messages := make([]iggcon.MessengerMessage, len(values))
+	for i, value := range values {
+		message, err := s.NewMessage(streamId, topicId, value)
+		if err != nil {
+			return err
+		}
+		messages[i] = message
+	}
+	return client.SendMessages(streamId, topicId, partitioning, messages)
+}
+
+// decoder returns the codec of the content type of a message polled from a topic.
+func (s *Serde) decoder(streamId, topicId iggcon.Identifier, message iggcon.MessengerMessage) (Codec, error) {
+	contentType := ContentType(message)
+	if contentType == "" {
+		if s.mode == Strict {
+			return nil, fmt.Errorf("codec: message %d has no content type", message.Header.Offset)
+		}
+		return s.Codec(streamId, topicId), nil
+	}
+	codec, ok := s.codecs[contentType]
+	if !ok {
+		return nil, fmt.Errorf("codec: message %d has the unsupported content type %q", message.Header.Offset, contentType)
+	}
+	return codec, nil
+}
+
+// Decode decodes the payload of a message polled from a topic with the codec of its content type.
+// A T implementing proto.Message is a pointer to the message it is decoded into.
+func Decode[T any](s *Serde, streamId, topicId iggcon.Identifier, message iggcon.MessengerMessage) (T, error) {
+	var value T
+	codec, err := s.decoder(streamId, topicId, message)
+	if err != nil {
+		return value, err
+	}
+	var target any = &value
+	if msg, ok := any(value).(proto.Message); ok {
+		value = msg.ProtoReflect().Type().New().Interface().(T)
+		target = value
+	}
+	if err = codec.Unmarshal(message.Payload, target, s.mode); err != nil {
+		var zero T
+		return zero, fmt.Errorf("codec: message %d: %w", message.Header.Offset, err)
+	}
+	return value, nil
+}
+
+// Poll polls the messages described by the request, like messengercli.PollMessages, and decodes
+// their payloads with Decode. The first message that cannot be decoded fails the poll.
+func Poll[T any](ctx context.Context, s *Serde, client messengercli.DataClient, request iggcon.PollMessageRequest) ([]Received[T], error) {
+	polled, err := messengercli.PollMessages(ctx, client, request)
+	if err != nil || polled == nil {
+		return nil, err
+	}
+	received := make([]Received[T], len(polled.Messages))
+	for i, message := range polled.Messages {
+		value, err := Decode[T](s, request.StreamId, request.TopicId, message)
+		if err != nil {
+			return nil, err
+		}
+		received[i] = Received[T]{
+			Value:           value,
+			ReceivedMessage: iggcon.ReceivedMessage{Message: message, CurrentOffset: polled.CurrentOffset, PartitionId: polled.PartitionId},
+		}
+	}
+	return received, nil
+}
@@ -0,0 +1,96 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package codec
+
+import (
+	"context"
+	"testing"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengertest"
+)
+
+type order struct {
+	Id     int    `json:"id" cbor:"id"`
+	Status string `json:"status" cbor:"status"`
+}
+
+func TestSerde(t *testing.T) {
+	client := messengertest.NewClient()
+	if _, err := client.CreateStream("orders", nil); err != nil {
+		t.Fatal(err)
+	}
+	streamId := iggcon.MustIdentifier("orders")
+	jsonTopic, cborTopic := iggcon.MustIdentifier("created"), iggcon.MustIdentifier("shipped")
+	for _, name := range []string{"created", "shipped"} {
+		if _, err := client.CreateTopic(streamId, name, 1, iggcon.CompressionAlgorithmNone, 0, 0, nil, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	serde := NewSerde(WithTopicCodec(streamId, cborTopic, CBOR))
+	if err := serde.Send(client, streamId, jsonTopic, iggcon.None(), order{Id: 1, Status: "created"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := serde.Send(client, streamId, cborTopic, iggcon.None(), order{Id: 1, Status: "shipped"}); err != nil {
+		t.Fatal(err)
+	}
+	// a message of another producer, encoded as CBOR on the JSON topic
+	cborMessage, _ := NewSerde(WithDefaultCodec(CBOR)).NewMessage(streamId, jsonTopic, order{Id: 2, Status: "created"})
+	// and a message without content type, with an unknown field
+	rawMessage, _ := iggcon.NewMessengerMessage([]byte(`{"id":3,"status":"created","priority":1}`))
+	if err := client.SendMessages(streamId, jsonTopic, iggcon.None(), []iggcon.MessengerMessage{cborMessage, rawMessage}); err != nil {
+		t.Fatal(err)
+	}
+
+	request := iggcon.PollMessageRequest{StreamId: streamId, TopicId: jsonTopic, PollingStrategy: iggcon.FirstPollingStrategy(), Count: 10}
+	received, err := Poll[order](context.Background(), serde, client, request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(received) != 3 || received[0].Value.Id != 1 || received[1].Value.Id != 2 || received[2].Value.Id != 3 {
+		t.Fatalf("expected the 3 orders of the JSON topic, got %+v", received)
+	}
+	if ContentType(received[1].Message) != ContentTypeCBOR {
+		t.Fatalf("expected the second message to be CBOR, got %q", ContentType(received[1].Message))
+	}
+
+	request.TopicId = cborTopic
+	shipped, err := Poll[order](context.Background(), serde, client, request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shipped) != 1 || shipped[0].Value.Status != "shipped" || ContentType(shipped[0].Message) != ContentTypeCBOR {
+		t.Fatalf("expected the order of the CBOR topic, got %+v", shipped)
+	}
+
+	strict := NewSerde(WithDecodeMode(Strict))
+	if _, err = Decode[order](strict, streamId, jsonTopic, received[2].Message); err == nil {
+		t.Fatal("expected a strict serde to reject a message without content type")
+	}
+	unknownField, _ := newMessage(rawMessage.Payload, ContentTypeJSON)
+	if _, err = Decode[order](strict, streamId, jsonTopic, unknownField); err == nil {
+		t.Fatal("expected a strict serde to reject an unknown field")
+	}
+	if _, err = Decode[order](strict, streamId, jsonTopic, received[0].Message); err != nil {
+		t.Fatal(err)
+	}
+	unsupported, _ := newMessage([]byte("id=4"), "text/plain")
+	if _, err = Decode[order](serde, streamId, jsonTopic, unsupported); err == nil {
+		t.Fatal("expected an unsupported content type to be rejected")
+	}
+}
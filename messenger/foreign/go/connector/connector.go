@@ -0,0 +1,110 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package connector moves data between Messenger topics and external systems. A SinkConnector
+// consumes a topic into a Sink, e.g. files, object storage or a search index, and a
+// SourceConnector produces the records of a Source, e.g. a database change log, to a topic. The
+// connectors manage the offsets, the retries and the metrics, so a Sink or a Source only deals
+// with its external system.
+package connector
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+)
+
+// Sink writes the messages of a topic to an external system. A SinkConnector writes the polled
+// messages with Write and periodically commits them with Commit, per its commit policy. The sink
+// stores the offsets of the messages along with them, and the connector resumes every partition
+// after the offset returned by Open, so a sink committing the messages and their offsets
+// atomically receives every message exactly once.
+//
+// Write and Commit may be called concurrently for different partitions.
+type Sink interface {
+	// Open prepares the partition, discarding the messages written and not committed, and
+	// returns the offset of the last committed message, false when none was committed.
+	Open(ctx context.Context, partitionId uint32) (uint64, bool, error)
+	// Write writes a message, which becomes durable once committed. A failed Write must not keep
+	// the message, since it is written again on retry.
+	Write(ctx context.Context, message iggcon.ReceivedMessage) error
+	// Commit makes the messages written to the partitions durable, along with the offsets. The
+	// offset of a partition is the one of its last handled message, and may be given for a
+	// partition without written message, e.g. once its offset was reset.
+	Commit(ctx context.Context, offsets map[uint32]uint64) error
+}
+
+// Record is the JSON representation of a message written by the sinks of this package.
+type Record struct {
+	PartitionId     uint32 `json:"partition_id"`
+	Offset          uint64 `json:"offset"`
+	Id              string `json:"id"`
+	Timestamp       uint64 `json:"timestamp"`
+	OriginTimestamp uint64 `json:"origin_timestamp"`
+	UserHeaders     []byte `json:"user_headers,omitempty"`
+	Payload         []byte `json:"payload"`
+}
+
+// NewRecord returns the Record of a polled message.
+func NewRecord(message iggcon.ReceivedMessage) Record {
+	header := message.Message.Header
+	return Record{
+		PartitionId:     message.PartitionId,
+		Offset:          header.Offset,
+		Id:              hex.EncodeToString(header.Id[:]),
+		Timestamp:       header.Timestamp,
+		OriginTimestamp: header.OriginTimestamp,
+		UserHeaders:     message.Message.UserHeaders,
+		Payload:         message.Message.Payload,
+	}
+}
+
+// permanentError is an error which retrying does not resolve.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// withRetries calls f until it succeeds, up to retries times more, doubling the backoff between
+// the attempts, and reports the number of retries. The permanent errors are not retried.
+func withRetries(ctx context.Context, retries int, backoff time.Duration, f func() error) (int, error) {
+	for attempt := 0; ; attempt++ {
+		err := f()
+		var permanent *permanentError
+		if err == nil || attempt >= retries || errors.As(err, &permanent) || ctx.Err() != nil {
+			return attempt, err
+		}
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return attempt, errors.Join(err, ctx.Err())
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
@@ -0,0 +1,206 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package connector
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+)
+
+// FileSink is the reference Sink, writing the messages of every partition as JSON Records, one
+// per line, to files of a directory per partition. Every commit creates a file named after the
+// offsets of its first and last messages, e.g. partition-1/00000000000000000100-00000000000000000199.jsonl,
+// written to a temporary file renamed once complete, so a file holds whole commits and the
+// offset of the last committed message is the one in the name of the last file. The messages
+// written and not committed are buffered in memory.
+type FileSink struct {
+	dir string
+
+	// commitMtx serializes the commits.
+	commitMtx  sync.Mutex
+	mtx        sync.Mutex
+	partitions map[uint32]*filePartition
+}
+
+// filePartition is the state of a partition of a FileSink.
+type filePartition struct {
+	// committed is the offset of the last committed message, valid when ok.
+	committed uint64
+	ok        bool
+	// buffered are the messages written and not committed, by offset.
+	buffered []bufferedLine
+}
+
+type bufferedLine struct {
+	offset uint64
+	line   []byte
+}
+
+// fileSuffix is the extension of the files of a FileSink.
+const fileSuffix = ".jsonl"
+
+// NewFileSink creates a FileSink writing to the directory, created when missing.
+func NewFileSink(dir string) (*FileSink, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileSink{dir: dir, partitions: map[uint32]*filePartition{}}, nil
+}
+
+func (s *FileSink) Open(_ context.Context, partitionId uint32) (uint64, bool, error) {
+	dir := s.partitionDir(partitionId)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return 0, false, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, false, err
+	}
+	partition := &filePartition{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") {
+			// a temporary file left by a commit which did not complete
+			if err := os.Remove(filepath.Join(dir, name)); err != nil {
+				return 0, false, err
+			}
+			continue
+		}
+		last, ok := lastOffset(name, fileSuffix)
+		if ok && (!partition.ok || last > partition.committed) {
+			partition.committed, partition.ok = last, true
+		}
+	}
+	s.mtx.Lock()
+	s.partitions[partitionId] = partition
+	s.mtx.Unlock()
+	return partition.committed, partition.ok, nil
+}
+
+func (s *FileSink) Write(_ context.Context, message iggcon.ReceivedMessage) error {
+	line, err := json.Marshal(NewRecord(message))
+	if err != nil {
+		return err
+	}
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	partition, ok := s.partitions[message.PartitionId]
+	if !ok {
+		return fmt.Errorf("connector: partition %d is not open", message.PartitionId)
+	}
+	partition.buffered = append(partition.buffered, bufferedLine{offset: message.Message.Header.Offset, line: append(line, '\n')})
+	return nil
+}
+
+func (s *FileSink) Commit(_ context.Context, offsets map[uint32]uint64) error {
+	s.commitMtx.Lock()
+	defer s.commitMtx.Unlock()
+	var errs []error
+	for partitionId, offset := range offsets {
+		if err := s.commit(partitionId, offset); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// commit writes the buffered messages of the partition to a new file, or an empty file when the
+// offset moved without message, e.g. once it was reset.
+func (s *FileSink) commit(partitionId uint32, offset uint64) error {
+	s.mtx.Lock()
+	partition, ok := s.partitions[partitionId]
+	if !ok {
+		s.mtx.Unlock()
+		return fmt.Errorf("connector: partition %d is not open", partitionId)
+	}
+	// the messages written after the offset are left for the next commit
+	count := 0
+	for count < len(partition.buffered) && partition.buffered[count].offset <= offset {
+		count++
+	}
+	if partition.ok && partition.committed >= offset && count == 0 {
+		s.mtx.Unlock()
+		return nil
+	}
+	first := offset + 1
+	var contents bytes.Buffer
+	for i, buffered := range partition.buffered[:count] {
+		if i == 0 {
+			first = buffered.offset
+		}
+		contents.Write(buffered.line)
+	}
+	s.mtx.Unlock()
+
+	dir := s.partitionDir(partitionId)
+	file, err := os.CreateTemp(dir, ".commit-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	if _, err = file.Write(contents.Bytes()); err != nil {
+		file.Close()
+		return err
+	}
+	if err = file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err = file.Close(); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%020d-%020d%s", first, offset, fileSuffix)
+	if err = os.Rename(file.Name(), filepath.Join(dir, name)); err != nil {
+		return err
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	partition.buffered = partition.buffered[count:]
+	partition.committed, partition.ok = offset, true
+	return nil
+}
+
+func (s *FileSink) partitionDir(partitionId uint32) string {
+	return filepath.Join(s.dir, "partition-"+strconv.FormatUint(uint64(partitionId), 10))
+}
+
+// lastOffset parses the offset of the last message of a file or object from its name, made of
+// the offsets of its first and last messages and the suffix.
+func lastOffset(name, suffix string) (uint64, bool) {
+	name, ok := strings.CutSuffix(name, suffix)
+	if !ok {
+		return 0, false
+	}
+	_, last, ok := strings.Cut(name, "-")
+	if !ok {
+		return 0, false
+	}
+	offset, err := strconv.ParseUint(last, 10, 64)
+	return offset, err == nil
+}
@@ -0,0 +1,191 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package connector
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/apache/messenger/foreign/go/consumer"
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengertest"
+)
+
+func newTestClient(t *testing.T) (*messengertest.Client, iggcon.Identifier, iggcon.Identifier) {
+	client := messengertest.NewClient()
+	if _, err := client.CreateStream("logs", nil); err != nil {
+		t.Fatal(err)
+	}
+	streamId, _ := iggcon.NewIdentifier("logs")
+	if _, err := client.CreateTopic(streamId, "events", 1, iggcon.CompressionAlgorithmNone, 0, 0, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	topicId, _ := iggcon.NewIdentifier("events")
+	return client, streamId, topicId
+}
+
+func sendPayloads(t *testing.T, client *messengertest.Client, streamId, topicId iggcon.Identifier, payloads ...string) {
+	for _, payload := range payloads {
+		message, err := iggcon.NewMessengerMessage([]byte(payload))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := client.SendMessages(streamId, topicId, iggcon.None(), []iggcon.MessengerMessage{message}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// pollTopic returns the payloads of the messages of the first partition of the topic.
+func pollTopic(t *testing.T, client *messengertest.Client, streamId, topicId iggcon.Identifier) []string {
+	partitionId := uint32(1)
+	polled, err := client.PollMessages(streamId, topicId, iggcon.DefaultConsumer(), iggcon.FirstPollingStrategy(), 100, false, &partitionId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var payloads []string
+	for _, message := range polled.Messages {
+		payloads = append(payloads, string(message.Payload))
+	}
+	return payloads
+}
+
+// runSink runs the connector until it wrote the given number of messages.
+func runSink(t *testing.T, connector *SinkConnector, written uint64) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- connector.Run(ctx) }()
+	deadline := time.Now().Add(5 * time.Second)
+	for connector.Stats().Written < written && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+// readRecords returns the files of the partition and the payloads of their records.
+func readRecords(t *testing.T, dir string) ([]string, []string) {
+	entries, err := os.ReadDir(filepath.Join(dir, "partition-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var files, payloads []string
+	for _, entry := range entries {
+		files = append(files, entry.Name())
+		file, err := os.Open(filepath.Join(dir, "partition-1", entry.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			var record Record
+			if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+				t.Fatal(err)
+			}
+			payloads = append(payloads, string(record.Payload))
+		}
+		file.Close()
+	}
+	return files, payloads
+}
+
+// failingCommit fails the first commit of the wrapped sink.
+type failingCommit struct {
+	Sink
+	failed bool
+}
+
+func (s *failingCommit) Commit(ctx context.Context, offsets map[uint32]uint64) error {
+	if !s.failed {
+		s.failed = true
+		return errors.New("unavailable")
+	}
+	return s.Sink.Commit(ctx, offsets)
+}
+
+func TestSinkConnector_FileSink(t *testing.T) {
+	client, streamId, topicId := newTestClient(t)
+	dir := t.TempDir()
+	sendPayloads(t, client, streamId, topicId, "a", "b", "c", "d", "e")
+
+	sink, err := NewFileSink(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	options := []Option{
+		WithCommitPolicy(consumer.CommitEvery(2)),
+		WithRetries(1, time.Millisecond),
+		WithConsumerOptions(consumer.WithPollInterval(5 * time.Millisecond)),
+	}
+	connector, err := NewSinkConnector(client, streamId, topicId, sink, options...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	runSink(t, connector, 5)
+
+	files, payloads := readRecords(t, dir)
+	wantFiles := []string{
+		"00000000000000000000-00000000000000000001.jsonl",
+		"00000000000000000002-00000000000000000003.jsonl",
+		"00000000000000000004-00000000000000000004.jsonl",
+	}
+	if !reflect.DeepEqual(files, wantFiles) {
+		t.Fatalf("expected files %v, got %v", wantFiles, files)
+	}
+	if want := []string{"a", "b", "c", "d", "e"}; !reflect.DeepEqual(payloads, want) {
+		t.Fatalf("expected records %v, got %v", want, payloads)
+	}
+	if stats := connector.Stats(); stats.Written != 5 || stats.Committed != 5 || stats.Commits != 3 {
+		t.Fatalf("unexpected sink stats: %+v", stats)
+	}
+
+	// a restarted sink discards the commit left incomplete and resumes after the committed offsets
+	if err := os.WriteFile(filepath.Join(dir, "partition-1", ".commit-1"), []byte("partial"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	sendPayloads(t, client, streamId, topicId, "f", "g")
+	sink, err = NewFileSink(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	connector, err = NewSinkConnector(client, streamId, topicId, &failingCommit{Sink: sink}, options...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	runSink(t, connector, 2)
+
+	files, payloads = readRecords(t, dir)
+	if len(files) != 4 || files[3] != "00000000000000000005-00000000000000000006.jsonl" {
+		t.Fatalf("unexpected files %v", files)
+	}
+	if want := []string{"a", "b", "c", "d", "e", "f", "g"}; !reflect.DeepEqual(payloads, want) {
+		t.Fatalf("expected records %v, got %v", want, payloads)
+	}
+	if stats := connector.Stats(); stats.Committed != 2 || stats.Retries != 1 || stats.Failures != 0 {
+		t.Fatalf("unexpected sink stats: %+v", stats)
+	}
+}
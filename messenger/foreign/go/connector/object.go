@@ -0,0 +1,250 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package connector
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"sync"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+)
+
+// ObjectStore stores the objects of an ObjectSink, e.g. an S3 bucket.
+type ObjectStore interface {
+	// Put stores the object under the key, atomically: the object is either stored whole or not at
+	// all.
+	Put(ctx context.Context, key string, body []byte, contentType string) error
+	// List returns the keys of the objects starting with the prefix.
+	List(ctx context.Context, prefix string) ([]string, error)
+}
+
+// ObjectFormat encodes the messages of an object.
+type ObjectFormat interface {
+	// Extension is the suffix of the keys of the objects, e.g. ".jsonl".
+	Extension() string
+	// ContentType is the media type of the objects.
+	ContentType() string
+	// Encode encodes the messages into an object, possibly none.
+	Encode(messages []iggcon.ReceivedMessage) ([]byte, error)
+}
+
+// JSONLines encodes the messages as JSON Records, one per line.
+func JSONLines() ObjectFormat {
+	return jsonLines{}
+}
+
+type jsonLines struct{}
+
+func (jsonLines) Extension() string {
+	return ".jsonl"
+}
+
+func (jsonLines) ContentType() string {
+	return "application/x-ndjson"
+}
+
+func (jsonLines) Encode(messages []iggcon.ReceivedMessage) ([]byte, error) {
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	for _, message := range messages {
+		if err := encoder.Encode(NewRecord(message)); err != nil {
+			return nil, err
+		}
+	}
+	return buffer.Bytes(), nil
+}
+
+// ObjectConfig configures an ObjectSink.
+type ObjectConfig struct {
+	// Prefix is prepended to the keys of the objects, e.g. "archive/orders/".
+	Prefix string
+	// Format encodes the objects, JSONLines by default.
+	Format ObjectFormat
+	// MaxObjectBytes bounds the size of the messages of an object, 64 MiB by default.
+	MaxObjectBytes int
+	// MaxObjectAge bounds the time between the writes of the first and the last messages of an
+	// object, 5 minutes by default.
+	MaxObjectAge time.Duration
+}
+
+func (c *ObjectConfig) setDefaults() {
+	if c.Format == nil {
+		c.Format = JSONLines()
+	}
+	if c.MaxObjectBytes <= 0 {
+		c.MaxObjectBytes = 64 << 20
+	}
+	if c.MaxObjectAge <= 0 {
+		c.MaxObjectAge = 5 * time.Minute
+	}
+}
+
+// ObjectSink is a Sink archiving the messages of every partition into the objects of an
+// ObjectStore, keyed by the partition and the offsets of their first and last messages, e.g.
+// archive/partition-1/00000000000000000100-00000000000000000199.jsonl. The messages are
+// buffered in memory and stored as an object once they reach MaxObjectBytes or MaxObjectAge, and
+// on every commit of the connector, so the commit policy should commit less often than
+// MaxObjectAge, e.g. consumer.CommitInterval(MaxObjectAge), for the objects to be bounded by the
+// configuration only.
+//
+// Every object holds the messages following the previous one, so the offset of the last stored
+// message is the one in the key of the last object, and a restarted connector neither skips nor
+// archives a message twice.
+type ObjectSink struct {
+	store  ObjectStore
+	config ObjectConfig
+
+	mtx        sync.Mutex
+	partitions map[uint32]*objectPartition
+}
+
+// objectPartition is the state of a partition of an ObjectSink.
+type objectPartition struct {
+	mtx sync.Mutex
+	// committed is the offset of the last stored message, valid when ok.
+	committed uint64
+	ok        bool
+	buffered  []iggcon.ReceivedMessage
+	size      int
+	// since is when the first buffered message was written.
+	since time.Time
+}
+
+// NewObjectSink creates an ObjectSink storing the objects in the store.
+func NewObjectSink(store ObjectStore, config ObjectConfig) *ObjectSink {
+	config.setDefaults()
+	return &ObjectSink{store: store, config: config, partitions: map[uint32]*objectPartition{}}
+}
+
+func (s *ObjectSink) Open(ctx context.Context, partitionId uint32) (uint64, bool, error) {
+	keys, err := s.store.List(ctx, s.partitionPrefix(partitionId))
+	if err != nil {
+		return 0, false, err
+	}
+	partition := &objectPartition{}
+	for _, key := range keys {
+		last, ok := lastOffset(path.Base(key), s.config.Format.Extension())
+		if ok && (!partition.ok || last > partition.committed) {
+			partition.committed, partition.ok = last, true
+		}
+	}
+	s.mtx.Lock()
+	s.partitions[partitionId] = partition
+	s.mtx.Unlock()
+	return partition.committed, partition.ok, nil
+}
+
+func (s *ObjectSink) Write(ctx context.Context, message iggcon.ReceivedMessage) error {
+	partition, err := s.partition(message.PartitionId)
+	if err != nil {
+		return err
+	}
+	partition.mtx.Lock()
+	defer partition.mtx.Unlock()
+	if len(partition.buffered) == 0 {
+		partition.since = time.Now()
+	}
+	partition.buffered = append(partition.buffered, message)
+	partition.size += len(message.Message.Payload) + len(message.Message.UserHeaders)
+	if partition.size < s.config.MaxObjectBytes && time.Since(partition.since) < s.config.MaxObjectAge {
+		return nil
+	}
+	count := len(partition.buffered)
+	if err := s.put(ctx, message.PartitionId, partition, count, message.Message.Header.Offset); err != nil {
+		// the message is written again on retry
+		partition.buffered = partition.buffered[:count-1]
+		partition.size -= len(message.Message.Payload) + len(message.Message.UserHeaders)
+		return err
+	}
+	return nil
+}
+
+func (s *ObjectSink) Commit(ctx context.Context, offsets map[uint32]uint64) error {
+	var errs []error
+	for partitionId, offset := range offsets {
+		if err := s.commit(ctx, partitionId, offset); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// commit stores the buffered messages of the partition up to the offset as an object, or an empty
+// object when the offset moved without message, e.g. once it was reset.
+func (s *ObjectSink) commit(ctx context.Context, partitionId uint32, offset uint64) error {
+	partition, err := s.partition(partitionId)
+	if err != nil {
+		return err
+	}
+	partition.mtx.Lock()
+	defer partition.mtx.Unlock()
+	count := 0
+	for count < len(partition.buffered) && partition.buffered[count].Message.Header.Offset <= offset {
+		count++
+	}
+	if count == 0 && partition.ok && partition.committed >= offset {
+		return nil
+	}
+	return s.put(ctx, partitionId, partition, count, offset)
+}
+
+// put stores the first count buffered messages of the partition as an object ending at the
+// offset. Must hold partition.mtx.
+func (s *ObjectSink) put(ctx context.Context, partitionId uint32, partition *objectPartition, count int, offset uint64) error {
+	messages := partition.buffered[:count]
+	first := offset + 1
+	if count > 0 {
+		first = messages[0].Message.Header.Offset
+	}
+	body, err := s.config.Format.Encode(messages)
+	if err != nil {
+		return err
+	}
+	key := fmt.Sprintf("%s%020d-%020d%s", s.partitionPrefix(partitionId), first, offset, s.config.Format.Extension())
+	if err := s.store.Put(ctx, key, body, s.config.Format.ContentType()); err != nil {
+		return err
+	}
+	partition.buffered = append([]iggcon.ReceivedMessage(nil), partition.buffered[count:]...)
+	partition.size = 0
+	for _, message := range partition.buffered {
+		partition.size += len(message.Message.Payload) + len(message.Message.UserHeaders)
+	}
+	partition.since = time.Now()
+	partition.committed, partition.ok = offset, true
+	return nil
+}
+
+func (s *ObjectSink) partition(partitionId uint32) (*objectPartition, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	partition, ok := s.partitions[partitionId]
+	if !ok {
+		return nil, fmt.Errorf("connector: partition %d is not open", partitionId)
+	}
+	return partition, nil
+}
+
+func (s *ObjectSink) partitionPrefix(partitionId uint32) string {
+	return fmt.Sprintf("%spartition-%d/", s.config.Prefix, partitionId)
+}
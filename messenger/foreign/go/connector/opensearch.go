@@ -0,0 +1,382 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package connector
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/apache/messenger/foreign/go/consumer"
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+)
+
+// OpenSearchConfig configures an OpenSearchSink.
+type OpenSearchConfig struct {
+	// URL of the cluster, e.g. https://localhost:9200.
+	URL string
+	// Index names the index of the documents. The Go time layouts between braces are replaced by
+	// the origin timestamp of the message, in UTC, e.g. "logs-{2006.01.02}" indexes into a daily
+	// index.
+	Index string
+	// Username and Password, when set, authenticate the requests with HTTP basic authentication.
+	Username string
+	Password string
+	// BulkActions is the maximum number of documents of a bulk request, 1000 by default.
+	BulkActions int
+	// BulkBytes is the maximum size of the documents of a bulk request, 5 MiB by default.
+	BulkBytes int
+	// Retries is the number of times the documents rejected by a busy cluster are retried, 8 by
+	// default and none when negative.
+	Retries int
+	// RetryBackoff is the pause before the first retry, doubled on every retry, 500ms by default.
+	RetryBackoff time.Duration
+	// DeadLetter, when set, receives the messages the cluster fails to index permanently, e.g.
+	// invalid JSON or a mapping conflict. Otherwise such a message stops the connector.
+	DeadLetter consumer.QuarantineHandler
+	// Offsets stores the offsets of the indexed messages, e.g. consumer.ServerOffsets.
+	Offsets consumer.OffsetStore
+	// HTTPClient sends the requests, http.DefaultClient by default.
+	HTTPClient *http.Client
+}
+
+// OpenSearchStats reports the activity of an OpenSearchSink.
+type OpenSearchStats struct {
+	// Indexed is the number of documents indexed.
+	Indexed uint64
+	// Requests is the number of bulk requests sent.
+	Requests uint64
+	// Rejected is the number of documents rejected by a busy cluster, and retried.
+	Rejected uint64
+	// DeadLettered is the number of messages passed to the DeadLetter handler.
+	DeadLettered uint64
+}
+
+// OpenSearchSink is a Sink indexing the JSON payloads of the messages into OpenSearch or
+// Elasticsearch with bulk requests. A document is identified by the key of its message, by the ID
+// of the message when it has no key, or by its partition and offset, so indexing a redelivered
+// message again overwrites its document, and the index holds every message once.
+//
+// The documents are sent once BulkActions or BulkBytes are buffered, and on every commit, which
+// then stores the offsets in the offset store. The documents rejected because the cluster is
+// overloaded, with status 429, are retried with a growing backoff, holding back the consumer
+// until the cluster catches up.
+type OpenSearchSink struct {
+	config OpenSearchConfig
+	index  []indexPart
+
+	mtx      sync.Mutex
+	buffered []bulkDocument
+	size     int
+
+	stats struct {
+		indexed      atomic.Uint64
+		requests     atomic.Uint64
+		rejected     atomic.Uint64
+		deadLettered atomic.Uint64
+	}
+}
+
+// indexPart is a literal part of an index name, or a time layout.
+type indexPart struct {
+	text   string
+	layout bool
+}
+
+// bulkDocument is a document waiting to be indexed.
+type bulkDocument struct {
+	message iggcon.ReceivedMessage
+	action  []byte
+	// source is the payload on a single line.
+	source []byte
+}
+
+// bulkResponse is the response of the bulk API.
+type bulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []map[string]struct {
+		Status int             `json:"status"`
+		Error  json.RawMessage `json:"error"`
+	} `json:"items"`
+}
+
+// NewOpenSearchSink creates an OpenSearchSink.
+func NewOpenSearchSink(config OpenSearchConfig) (*OpenSearchSink, error) {
+	if config.URL == "" || config.Index == "" {
+		return nil, errors.New("connector: opensearch url and index are required")
+	}
+	if config.Offsets == nil {
+		return nil, errors.New("connector: opensearch offset store is required")
+	}
+	index, err := parseIndex(config.Index)
+	if err != nil {
+		return nil, err
+	}
+	config.URL = strings.TrimSuffix(config.URL, "/")
+	if config.BulkActions <= 0 {
+		config.BulkActions = 1000
+	}
+	if config.BulkBytes <= 0 {
+		config.BulkBytes = 5 << 20
+	}
+	if config.Retries == 0 {
+		config.Retries = 8
+	} else if config.Retries < 0 {
+		config.Retries = 0
+	}
+	if config.RetryBackoff <= 0 {
+		config.RetryBackoff = 500 * time.Millisecond
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = http.DefaultClient
+	}
+	return &OpenSearchSink{config: config, index: index}, nil
+}
+
+// Stats returns the activity of the sink.
+func (s *OpenSearchSink) Stats() OpenSearchStats {
+	return OpenSearchStats{
+		Indexed:      s.stats.indexed.Load(),
+		Requests:     s.stats.requests.Load(),
+		Rejected:     s.stats.rejected.Load(),
+		DeadLettered: s.stats.deadLettered.Load(),
+	}
+}
+
+// Open discards the documents of the partition not sent yet, and loads its offset from the offset
+// store.
+func (s *OpenSearchSink) Open(_ context.Context, partitionId uint32) (uint64, bool, error) {
+	s.mtx.Lock()
+	kept := s.buffered[:0]
+	s.size = 0
+	for _, document := range s.buffered {
+		if document.message.PartitionId != partitionId {
+			kept = append(kept, document)
+			s.size += document.size()
+		}
+	}
+	s.buffered = kept
+	s.mtx.Unlock()
+	return s.config.Offsets.Load(partitionId)
+}
+
+func (s *OpenSearchSink) Write(ctx context.Context, message iggcon.ReceivedMessage) error {
+	var source bytes.Buffer
+	if err := json.Compact(&source, message.Message.Payload); err != nil {
+		cause := fmt.Errorf("connector: the payload is not a JSON document: %w", err)
+		if s.config.DeadLetter == nil {
+			return &permanentError{cause}
+		}
+		if err := s.config.DeadLetter(ctx, message, 1, cause); err != nil {
+			return err
+		}
+		s.stats.deadLettered.Add(1)
+		return nil
+	}
+	action, err := json.Marshal(map[string]map[string]string{"index": {
+		"_index": s.indexName(message),
+		"_id":    documentId(message),
+	}})
+	if err != nil {
+		return err
+	}
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	document := bulkDocument{message: message, action: action, source: source.Bytes()}
+	s.buffered = append(s.buffered, document)
+	s.size += document.size()
+	if len(s.buffered) < s.config.BulkActions && s.size < s.config.BulkBytes {
+		return nil
+	}
+	if err := s.flush(ctx); err != nil {
+		// the message is written again on retry
+		if last := len(s.buffered) - 1; last >= 0 && s.buffered[last].message.Message.Header.Offset == message.Message.Header.Offset &&
+			s.buffered[last].message.PartitionId == message.PartitionId {
+			s.size -= s.buffered[last].size()
+			s.buffered = s.buffered[:last]
+		}
+		return err
+	}
+	return nil
+}
+
+// Commit indexes the buffered documents, then stores the offsets.
+func (s *OpenSearchSink) Commit(ctx context.Context, offsets map[uint32]uint64) error {
+	s.mtx.Lock()
+	err := s.flush(ctx)
+	s.mtx.Unlock()
+	if err != nil {
+		return err
+	}
+	return s.config.Offsets.Store(offsets)
+}
+
+// flush indexes the buffered documents with bulk requests of at most BulkActions documents and
+// BulkBytes, retrying the rejected ones. The documents failing are kept buffered. Must hold s.mtx.
+func (s *OpenSearchSink) flush(ctx context.Context) error {
+	for len(s.buffered) > 0 {
+		count, size := 0, 0
+		for count < len(s.buffered) && (count == 0 || size+s.buffered[count].size() <= s.config.BulkBytes) && count < s.config.BulkActions {
+			size += s.buffered[count].size()
+			count++
+		}
+		pending := s.buffered[:count]
+		_, err := withRetries(ctx, s.config.Retries, s.config.RetryBackoff, func() (err error) {
+			pending, err = s.bulk(ctx, pending)
+			return err
+		})
+		if err != nil {
+			s.buffered = append(pending, s.buffered[count:]...)
+			s.size = 0
+			for _, document := range s.buffered {
+				s.size += document.size()
+			}
+			return err
+		}
+		s.buffered = s.buffered[count:]
+		s.size -= size
+	}
+	s.buffered = nil
+	return nil
+}
+
+// errBulkRejected is the error of a bulk request whose documents were rejected by a busy cluster.
+var errBulkRejected = errors.New("connector: opensearch rejected documents, retrying")
+
+// bulk sends a bulk request for the documents, returning the documents to retry. The documents
+// failing permanently are passed to the DeadLetter handler.
+func (s *OpenSearchSink) bulk(ctx context.Context, documents []bulkDocument) ([]bulkDocument, error) {
+	var body bytes.Buffer
+	for _, document := range documents {
+		body.Write(document.action)
+		body.WriteByte('\n')
+		body.Write(document.source)
+		body.WriteByte('\n')
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, s.config.URL+"/_bulk", &body)
+	if err != nil {
+		return documents, &permanentError{err}
+	}
+	request.Header.Set("Content-Type", "application/x-ndjson")
+	if s.config.Username != "" || s.config.Password != "" {
+		request.SetBasicAuth(s.config.Username, s.config.Password)
+	}
+	s.stats.requests.Add(1)
+	response, err := s.config.HTTPClient.Do(request)
+	if err != nil {
+		return documents, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode/100 != 2 {
+		message, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+		err := fmt.Errorf("connector: opensearch bulk responded %d: %s", response.StatusCode, message)
+		if response.StatusCode == http.StatusTooManyRequests || response.StatusCode/100 == 5 {
+			s.stats.rejected.Add(uint64(len(documents)))
+			return documents, err
+		}
+		return documents, &permanentError{err}
+	}
+	var result bulkResponse
+	if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
+		return documents, fmt.Errorf("connector: invalid opensearch bulk response: %w", err)
+	}
+	if len(result.Items) != len(documents) {
+		return documents, fmt.Errorf("connector: opensearch bulk response holds %d items for %d documents", len(result.Items), len(documents))
+	}
+
+	var retried []bulkDocument
+	for i, item := range result.Items {
+		for _, outcome := range item {
+			switch {
+			case outcome.Status/100 == 2:
+				s.stats.indexed.Add(1)
+			case outcome.Status == http.StatusTooManyRequests:
+				s.stats.rejected.Add(1)
+				retried = append(retried, documents[i])
+			default:
+				cause := fmt.Errorf("connector: opensearch failed to index the document with status %d: %s", outcome.Status, outcome.Error)
+				if s.config.DeadLetter == nil {
+					return append(retried, documents[i:]...), &permanentError{cause}
+				}
+				if err := s.config.DeadLetter(ctx, documents[i].message, 1, cause); err != nil {
+					return append(retried, documents[i:]...), err
+				}
+				s.stats.deadLettered.Add(1)
+			}
+		}
+	}
+	if len(retried) > 0 {
+		return retried, errBulkRejected
+	}
+	return nil, nil
+}
+
+func (d bulkDocument) size() int {
+	return len(d.action) + len(d.source) + 2
+}
+
+// indexName returns the index of the message.
+func (s *OpenSearchSink) indexName(message iggcon.ReceivedMessage) string {
+	timestamp := time.UnixMicro(int64(message.Message.Header.OriginTimestamp)).UTC()
+	var name strings.Builder
+	for _, part := range s.index {
+		if part.layout {
+			name.WriteString(timestamp.Format(part.text))
+		} else {
+			name.WriteString(part.text)
+		}
+	}
+	return name.String()
+}
+
+// parseIndex splits an index name into its literal parts and time layouts.
+func parseIndex(index string) ([]indexPart, error) {
+	var parts []indexPart
+	for index != "" {
+		before, rest, found := strings.Cut(index, "{")
+		if before != "" {
+			parts = append(parts, indexPart{text: before})
+		}
+		if !found {
+			break
+		}
+		layout, after, closed := strings.Cut(rest, "}")
+		if !closed || layout == "" {
+			return nil, fmt.Errorf("connector: invalid opensearch index %q", index)
+		}
+		parts = append(parts, indexPart{text: layout, layout: true})
+		index = after
+	}
+	return parts, nil
+}
+
+// documentId identifies the document of a message by its key, its ID or its partition and offset.
+func documentId(message iggcon.ReceivedMessage) string {
+	if key := message.Message.Key(); len(key) > 0 {
+		return string(key)
+	}
+	return idempotencyKey(message)
+}
@@ -0,0 +1,137 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package connector
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/apache/messenger/foreign/go/consumer"
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+)
+
+// fakeOpenSearch serves the bulk API, rejecting the documents holding "busy" once and failing
+// the ones holding "conflict".
+type fakeOpenSearch struct {
+	mtx       sync.Mutex
+	documents map[string]string
+	rejected  map[string]bool
+}
+
+func (s *fakeOpenSearch) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	var items []string
+	scanner := bufio.NewScanner(r.Body)
+	for scanner.Scan() {
+		var action struct {
+			Index struct {
+				Index string `json:"_index"`
+				Id    string `json:"_id"`
+			} `json:"index"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &action); err != nil || !scanner.Scan() {
+			http.Error(w, "invalid bulk request", http.StatusBadRequest)
+			return
+		}
+		document, name := scanner.Text(), action.Index.Index+"/"+action.Index.Id
+		status := http.StatusCreated
+		switch {
+		case strings.Contains(document, "busy") && !s.rejected[name]:
+			s.rejected[name] = true
+			status = http.StatusTooManyRequests
+		case strings.Contains(document, "conflict"):
+			status = http.StatusBadRequest
+		default:
+			s.documents[name] = document
+		}
+		items = append(items, fmt.Sprintf(`{"index":{"status":%d}}`, status))
+	}
+	fmt.Fprintf(w, `{"errors":true,"items":[%s]}`, strings.Join(items, ","))
+}
+
+func TestOpenSearchSink(t *testing.T) {
+	client, streamId, topicId := newTestClient(t)
+	if _, err := client.CreateTopic(streamId, "dead-letters", 1, iggcon.CompressionAlgorithmNone, 0, 0, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	deadLettersId, _ := iggcon.NewIdentifier("dead-letters")
+	cluster := &fakeOpenSearch{documents: map[string]string{}, rejected: map[string]bool{}}
+	server := httptest.NewServer(cluster)
+	defer server.Close()
+
+	timestamp := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, payload := range []string{`{"order": 1, "busy": true}`, "{\n  \"order\": 2\n}", `{"conflict": true}`, "not json"} {
+		options := []iggcon.MessengerMessageOpt{iggcon.WithTimestamp(timestamp)}
+		if strings.Contains(payload, `"order": 1`) {
+			options = append(options, iggcon.WithKey([]byte("order-1")))
+		}
+		message, err := iggcon.NewMessengerMessage([]byte(payload), options...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := client.SendMessages(streamId, topicId, iggcon.None(), []iggcon.MessengerMessage{message}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sink, err := NewOpenSearchSink(OpenSearchConfig{
+		URL:          server.URL,
+		Index:        "orders-{2006.01.02}",
+		BulkActions:  2,
+		RetryBackoff: time.Millisecond,
+		DeadLetter:   consumer.DeadLetter(client, streamId, deadLettersId),
+		Offsets:      consumer.ServerOffsets(client, iggcon.DefaultConsumer(), streamId, topicId),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	connector, err := NewSinkConnector(client, streamId, topicId, sink,
+		WithConsumerOptions(consumer.WithPollInterval(5*time.Millisecond)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	runSink(t, connector, 4)
+
+	want := map[string]string{
+		"orders-2024.03.01/order-1": `{"order":1,"busy":true}`,
+		"orders-2024.03.01/1-1":     `{"order":2}`,
+	}
+	if !reflect.DeepEqual(cluster.documents, want) {
+		t.Fatalf("expected documents %v, got %v", want, cluster.documents)
+	}
+	if stats := sink.Stats(); stats.Indexed != 2 || stats.Requests != 3 || stats.Rejected != 1 || stats.DeadLettered != 2 {
+		t.Fatalf("unexpected opensearch stats: %+v", stats)
+	}
+	// the invalid JSON is dead-lettered when written, the conflict once indexed by the commit
+	if got := pollTopic(t, client, streamId, deadLettersId); !reflect.DeepEqual(got, []string{"not json", `{"conflict": true}`}) {
+		t.Fatalf("expected the failed messages to be dead-lettered, got %v", got)
+	}
+	partitionId := uint32(1)
+	if stored, err := client.GetConsumerOffset(iggcon.DefaultConsumer(), streamId, topicId, &partitionId); err != nil || stored == nil || stored.StoredOffset != 3 {
+		t.Fatalf("expected offset 3 to be stored, got %+v, %v", stored, err)
+	}
+}
@@ -0,0 +1,94 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package connector
+
+import (
+	"time"
+
+	"github.com/apache/messenger/foreign/go/consumer"
+)
+
+type Option func(opts *Options)
+
+type Options struct {
+	// Commit decides when a SinkConnector commits the written messages, which bounds the
+	// messages a sink buffers and written again after a crash.
+	Commit consumer.CommitPolicy
+	// Retries is the number of times a failed Write, Commit, Read or send is retried before the
+	// connector stops with the error.
+	Retries int
+	// RetryBackoff is the pause before the first retry, doubled on every retry.
+	RetryBackoff time.Duration
+	// ConsumerOptions are the options of the consumer of a SinkConnector.
+	ConsumerOptions []consumer.Option
+	// BatchSize is the maximum number of records a SourceConnector sends in a single request.
+	BatchSize int
+	// PollInterval is the pause of a SourceConnector after a Read returning no record.
+	PollInterval time.Duration
+}
+
+func GetDefaultOptions() Options {
+	return Options{
+		Commit:       consumer.CommitInterval(5 * time.Second),
+		Retries:      3,
+		RetryBackoff: 100 * time.Millisecond,
+		BatchSize:    100,
+		PollInterval: time.Second,
+	}
+}
+
+// WithCommitPolicy sets when a SinkConnector commits the written messages, every 5 seconds by
+// default. The policy must store the offsets after the handler, e.g. consumer.CommitEvery or
+// consumer.CommitInterval.
+func WithCommitPolicy(policy consumer.CommitPolicy) Option {
+	return func(opts *Options) {
+		opts.Commit = policy
+	}
+}
+
+// WithRetries retries the failed calls to the external system up to retries times, pausing
+// backoff before the first retry and doubling the pause on every retry.
+func WithRetries(retries int, backoff time.Duration) Option {
+	return func(opts *Options) {
+		opts.Retries = retries
+		opts.RetryBackoff = backoff
+	}
+}
+
+// WithConsumerOptions sets the options of the consumer of a SinkConnector, e.g. its partitions
+// or middlewares. The delivery semantics, commit policy and offset store are set by the
+// connector.
+func WithConsumerOptions(options ...consumer.Option) Option {
+	return func(opts *Options) {
+		opts.ConsumerOptions = append(opts.ConsumerOptions, options...)
+	}
+}
+
+// WithBatchSize sets the maximum number of records a SourceConnector sends in a single request.
+func WithBatchSize(size int) Option {
+	return func(opts *Options) {
+		opts.BatchSize = size
+	}
+}
+
+// WithPollInterval sets the pause of a SourceConnector after a Read returning no record.
+func WithPollInterval(interval time.Duration) Option {
+	return func(opts *Options) {
+		opts.PollInterval = interval
+	}
+}
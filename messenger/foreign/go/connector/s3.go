@@ -0,0 +1,270 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package connector
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// S3Config configures an S3 sink.
+type S3Config struct {
+	ObjectConfig
+	// Bucket receives the objects.
+	Bucket string
+	// Region of the bucket, AWS_REGION by default.
+	Region string
+	// Endpoint is the URL of the S3 API, https://s3.<Region>.amazonaws.com by default. It is set
+	// for the S3 compatible stores, e.g. MinIO, usually along with PathStyle.
+	Endpoint string
+	// PathStyle addresses the bucket in the path of the URLs rather than in the host name.
+	PathStyle bool
+	// AccessKeyId, SecretAccessKey and SessionToken are the credentials signing the requests,
+	// AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN by default.
+	AccessKeyId     string
+	SecretAccessKey string
+	SessionToken    string
+	// HTTPClient sends the requests, http.DefaultClient by default.
+	HTTPClient *http.Client
+}
+
+// NewS3Sink creates an ObjectSink archiving the messages to an S3 bucket.
+func NewS3Sink(config S3Config) (*ObjectSink, error) {
+	store, err := NewS3Store(config)
+	if err != nil {
+		return nil, err
+	}
+	return NewObjectSink(store, config.ObjectConfig), nil
+}
+
+// S3Store is an ObjectStore backed by an S3 bucket, signing the requests with AWS Signature
+// Version 4.
+type S3Store struct {
+	config   S3Config
+	endpoint *url.URL
+	now      func() time.Time
+}
+
+// NewS3Store creates an S3Store for the bucket of the configuration.
+func NewS3Store(config S3Config) (*S3Store, error) {
+	if config.Bucket == "" {
+		return nil, errors.New("connector: s3 bucket is required")
+	}
+	if config.Region == "" {
+		config.Region = os.Getenv("AWS_REGION")
+	}
+	if config.Region == "" {
+		return nil, errors.New("connector: s3 region is required")
+	}
+	if config.AccessKeyId == "" && config.SecretAccessKey == "" {
+		config.AccessKeyId = os.Getenv("AWS_ACCESS_KEY_ID")
+		config.SecretAccessKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+		config.SessionToken = os.Getenv("AWS_SESSION_TOKEN")
+	}
+	if config.AccessKeyId == "" || config.SecretAccessKey == "" {
+		return nil, errors.New("connector: s3 credentials are required")
+	}
+	if config.Endpoint == "" {
+		config.Endpoint = "https://s3." + config.Region + ".amazonaws.com"
+	}
+	endpoint, err := url.Parse(config.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("connector: invalid s3 endpoint: %w", err)
+	}
+	if !config.PathStyle {
+		endpoint.Host = config.Bucket + "." + endpoint.Host
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = http.DefaultClient
+	}
+	return &S3Store{config: config, endpoint: endpoint, now: time.Now}, nil
+}
+
+func (s *S3Store) Put(ctx context.Context, key string, body []byte, contentType string) error {
+	header := http.Header{}
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	response, err := s.do(ctx, http.MethodPut, key, nil, header, body)
+	if err != nil {
+		return err
+	}
+	return response.Body.Close()
+}
+
+// listBucketResult is the response of ListObjectsV2.
+type listBucketResult struct {
+	Contents []struct {
+		Key string `xml:"Key"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+func (s *S3Store) List(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+	for {
+		response, err := s.do(ctx, http.MethodGet, "", query, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		var result listBucketResult
+		err = xml.NewDecoder(response.Body).Decode(&result)
+		response.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("connector: s3 list %s: %w", prefix, err)
+		}
+		for _, content := range result.Contents {
+			keys = append(keys, content.Key)
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return keys, nil
+		}
+		query.Set("continuation-token", result.NextContinuationToken)
+	}
+}
+
+// do sends a signed request for the object, or the bucket when key is empty, returning the
+// response of a successful request.
+func (s *S3Store) do(ctx context.Context, method, key string, query url.Values, header http.Header, body []byte) (*http.Response, error) {
+	target := *s.endpoint
+	target.Path = "/" + key
+	if s.config.PathStyle {
+		target.Path = "/" + s.config.Bucket + target.Path
+	}
+	target.RawPath = uriEncode(target.Path, false)
+	target.RawQuery = canonicalQuery(query)
+	request, err := http.NewRequestWithContext(ctx, method, target.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range header {
+		request.Header[name] = values
+	}
+	s.sign(request, body)
+	response, err := s.config.HTTPClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode/100 != 2 {
+		message, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+		response.Body.Close()
+		return nil, fmt.Errorf("connector: s3 %s %s: %s: %s", method, target.Path, response.Status, message)
+	}
+	return response, nil
+}
+
+// sign signs the request with AWS Signature Version 4.
+func (s *S3Store) sign(request *http.Request, body []byte) {
+	now := s.now().UTC()
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+	request.Header.Set("X-Amz-Date", now.Format("20060102T150405Z"))
+	request.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if s.config.SessionToken != "" {
+		request.Header.Set("X-Amz-Security-Token", s.config.SessionToken)
+	}
+
+	names := []string{"host"}
+	values := map[string]string{"host": request.URL.Host}
+	for name := range request.Header {
+		if lower := strings.ToLower(name); strings.HasPrefix(lower, "x-amz-") || lower == "content-type" {
+			names = append(names, lower)
+			values[lower] = strings.TrimSpace(request.Header.Get(name))
+		}
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + values[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+	canonicalRequest := strings.Join([]string{
+		request.Method,
+		request.URL.EscapedPath(),
+		request.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.config.Region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + now.Format("20060102T150405Z") + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+	key := hmacSHA256([]byte("AWS4"+s.config.SecretAccessKey), date)
+	key = hmacSHA256(key, s.config.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	request.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.config.AccessKeyId, scope, signedHeaders, signature))
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// canonicalQuery encodes the query with its parameters sorted, as signed by Signature Version 4.
+func canonicalQuery(query url.Values) string {
+	var parameters []string
+	for name, values := range query {
+		for _, value := range values {
+			parameters = append(parameters, uriEncode(name, true)+"="+uriEncode(value, true))
+		}
+	}
+	sort.Strings(parameters)
+	return strings.Join(parameters, "&")
+}
+
+// uriEncode percent-encodes every byte but the unreserved characters, and the slashes unless
+// encodeSlash is set.
+func uriEncode(value string, encodeSlash bool) string {
+	var encoded strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-', c == '_', c == '.', c == '~':
+			encoded.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			encoded.WriteByte(c)
+		default:
+			fmt.Fprintf(&encoded, "%%%02X", c)
+		}
+	}
+	return encoded.String()
+}
@@ -0,0 +1,210 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package connector
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"github.com/apache/messenger/foreign/go/consumer"
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengercli"
+)
+
+// SinkStats reports the activity of a SinkConnector.
+type SinkStats struct {
+	// Written is the number of messages written to the sink.
+	Written uint64
+	// Committed is the number of written messages the sink committed.
+	Committed uint64
+	// Commits is the number of successful commits.
+	Commits uint64
+	// Retries is the number of retried calls to the sink.
+	Retries uint64
+	// Failures is the number of calls to the sink which failed after their retries.
+	Failures uint64
+}
+
+// SinkConnector consumes a topic into a Sink. The sink is the offset store of the consumer: Run
+// resumes every partition after the offset the sink committed last, and every commit of the
+// consumer commits the written messages to the sink along with their offsets. A Write or Commit
+// failing after its retries stops Run, and the next Run discards the messages written and not
+// committed, resuming from the committed offsets, so no message is lost nor committed twice.
+//
+// The offsets are not stored on the server, which requires the partitions of a group consumer to
+// be set with consumer.WithPartitions.
+type SinkConnector struct {
+	sink Sink
+	opts Options
+
+	consumer *consumer.Consumer
+
+	mtx sync.Mutex
+	// ctx is the context of the running Run.
+	ctx context.Context
+	// opened holds the offsets committed to the partitions opened by the running Run.
+	opened map[uint32]committedOffset
+	// written counts the messages written to every partition since its last commit.
+	written map[uint32]uint64
+
+	stats struct {
+		written   atomic.Uint64
+		committed atomic.Uint64
+		commits   atomic.Uint64
+		retries   atomic.Uint64
+		failures  atomic.Uint64
+	}
+}
+
+type committedOffset struct {
+	offset uint64
+	ok     bool
+}
+
+// NewSinkConnector creates a SinkConnector consuming the given stream and topic into the sink.
+func NewSinkConnector(
+	client messengercli.Client,
+	streamId iggcon.Identifier,
+	topicId iggcon.Identifier,
+	sink Sink,
+	options ...Option,
+) (*SinkConnector, error) {
+	if sink == nil {
+		return nil, errors.New("connector: sink is required")
+	}
+	opts := GetDefaultOptions()
+	for _, opt := range options {
+		if opt != nil {
+			opt(&opts)
+		}
+	}
+	if opts.Retries < 0 {
+		return nil, errors.New("connector: retries must not be negative")
+	}
+	c := &SinkConnector{sink: sink, opts: opts}
+	consumerOptions := append(append([]consumer.Option{}, opts.ConsumerOptions...),
+		consumer.WithCommitPolicy(opts.Commit),
+		consumer.WithOffsetStore(sinkOffsets{c}),
+	)
+	var err error
+	c.consumer, err = consumer.NewConsumer(client, streamId, topicId, c.write, consumerOptions...)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Run consumes the topic into the sink until ctx is cancelled or an error occurs, committing
+// the written messages before returning. It must not be called concurrently.
+func (c *SinkConnector) Run(ctx context.Context) error {
+	c.mtx.Lock()
+	c.ctx = ctx
+	c.opened = map[uint32]committedOffset{}
+	c.written = map[uint32]uint64{}
+	c.mtx.Unlock()
+	return c.consumer.Run(ctx)
+}
+
+// Stats returns the activity of the connector.
+func (c *SinkConnector) Stats() SinkStats {
+	return SinkStats{
+		Written:   c.stats.written.Load(),
+		Committed: c.stats.committed.Load(),
+		Commits:   c.stats.commits.Load(),
+		Retries:   c.stats.retries.Load(),
+		Failures:  c.stats.failures.Load(),
+	}
+}
+
+func (c *SinkConnector) write(ctx context.Context, message iggcon.ReceivedMessage) error {
+	if err := c.retry(ctx, func() error { return c.sink.Write(ctx, message) }); err != nil {
+		return err
+	}
+	c.stats.written.Add(1)
+	c.mtx.Lock()
+	c.written[message.PartitionId]++
+	c.mtx.Unlock()
+	return nil
+}
+
+func (c *SinkConnector) retry(ctx context.Context, f func() error) error {
+	retries, err := withRetries(ctx, c.opts.Retries, c.opts.RetryBackoff, f)
+	c.stats.retries.Add(uint64(retries))
+	if err != nil {
+		c.stats.failures.Add(1)
+	}
+	return err
+}
+
+// sinkOffsets is the consumer.OffsetStore of a SinkConnector.
+type sinkOffsets struct {
+	c *SinkConnector
+}
+
+// Load opens the partition the first time it is loaded by a Run, then returns the offset
+// committed since, so the messages written to the partition are only discarded when Run starts.
+func (s sinkOffsets) Load(partitionId uint32) (uint64, bool, error) {
+	c := s.c
+	c.mtx.Lock()
+	ctx := c.ctx
+	opened, ok := c.opened[partitionId]
+	c.mtx.Unlock()
+	if ok {
+		return opened.offset, opened.ok, nil
+	}
+	err := c.retry(ctx, func() (err error) {
+		opened.offset, opened.ok, err = c.sink.Open(ctx, partitionId)
+		return err
+	})
+	if err != nil {
+		return 0, false, err
+	}
+	c.mtx.Lock()
+	c.opened[partitionId] = opened
+	delete(c.written, partitionId)
+	c.mtx.Unlock()
+	return opened.offset, opened.ok, nil
+}
+
+func (s sinkOffsets) Store(offsets map[uint32]uint64) error {
+	c := s.c
+	c.mtx.Lock()
+	// the final commit of Run happens once its context is done
+	ctx := context.WithoutCancel(c.ctx)
+	c.mtx.Unlock()
+	if err := c.retry(ctx, func() error { return c.sink.Commit(ctx, offsets) }); err != nil {
+		return err
+	}
+	c.stats.commits.Add(1)
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	for partitionId, offset := range offsets {
+		if opened := c.opened[partitionId]; !opened.ok || opened.offset < offset {
+			c.opened[partitionId] = committedOffset{offset: offset, ok: true}
+		}
+		c.stats.committed.Add(c.written[partitionId])
+		delete(c.written, partitionId)
+	}
+	return nil
+}
+
+func (s sinkOffsets) Delete(uint32) error {
+	return errors.New("connector: the offsets committed to a sink cannot be deleted")
+}
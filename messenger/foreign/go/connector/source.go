@@ -0,0 +1,269 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package connector
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/apache/messenger/foreign/go/consumer"
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengercli"
+	"github.com/google/uuid"
+)
+
+// SourceRecord is a record read from a Source.
+type SourceRecord struct {
+	// Message is the message produced for the record.
+	Message iggcon.MessengerMessage
+	// Position identifies the record in the source, which resumes reading after it when opened
+	// with it, e.g. a log sequence number or a file name and line.
+	Position []byte
+}
+
+// Source reads the records of an external system, in order.
+type Source interface {
+	// Open starts reading the records after the position, from the first record when nil.
+	Open(ctx context.Context, position []byte) error
+	// Read returns the next records, none when no record is available yet.
+	Read(ctx context.Context) ([]SourceRecord, error)
+	// Close releases the resources of the source.
+	Close() error
+}
+
+// PositionStore stores the position of a SourceConnector in its source.
+type PositionStore interface {
+	// Load returns the stored position, nil when none is stored.
+	Load() ([]byte, error)
+	// Save stores the position.
+	Save(position []byte) error
+}
+
+// FilePositionStore stores the position in a file, replaced atomically on every Save.
+type FilePositionStore struct {
+	path string
+}
+
+// NewFilePositionStore creates a FilePositionStore keeping the position in the file at path,
+// created on the first Save.
+func NewFilePositionStore(path string) *FilePositionStore {
+	return &FilePositionStore{path: path}
+}
+
+func (s *FilePositionStore) Load() ([]byte, error) {
+	position, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return position, err
+}
+
+func (s *FilePositionStore) Save(position []byte) error {
+	file, err := os.CreateTemp(filepath.Dir(s.path), "."+filepath.Base(s.path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	if _, err = file.Write(position); err != nil {
+		file.Close()
+		return err
+	}
+	if err = file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), s.path)
+}
+
+// SourceStats reports the activity of a SourceConnector.
+type SourceStats struct {
+	// Read is the number of records read from the source.
+	Read uint64
+	// Produced is the number of records sent to the topic.
+	Produced uint64
+	// Retries is the number of retried reads and sends.
+	Retries uint64
+	// Failures is the number of reads and sends which failed after their retries.
+	Failures uint64
+}
+
+// sourceNamespace derives the IDs of the messages produced by a SourceConnector.
+var sourceNamespace = uuid.MustParse("3e8f1c5a-6b2d-4f97-a0c4-9d7e2b1f8a63")
+
+// SourceConnector produces the records of a Source to a topic, saving the position of the last
+// record sent to a PositionStore after every request.
+//
+// The sends and the saves of the position are not atomic: a crash between them sends the records
+// again once the source resumes from the saved position. The messages therefore get IDs derived
+// from the name of the connector and the positions of the records, identical on every attempt,
+// so their consumers can drop the duplicates by ID and obtain exactly-once delivery end to end.
+// The IDs set on the messages by the source are kept.
+type SourceConnector struct {
+	client    messengercli.Client
+	name      string
+	source    Source
+	positions PositionStore
+	sink      consumer.Sink
+	opts      Options
+
+	stats struct {
+		read     atomic.Uint64
+		produced atomic.Uint64
+		retries  atomic.Uint64
+		failures atomic.Uint64
+	}
+}
+
+// NewSourceConnector creates a SourceConnector producing the records of the source to the sink.
+// The name identifies the connector in the IDs of the messages, and must stay the same across
+// restarts.
+func NewSourceConnector(
+	client messengercli.Client,
+	name string,
+	source Source,
+	positions PositionStore,
+	sink consumer.Sink,
+	options ...Option,
+) (*SourceConnector, error) {
+	if client == nil {
+		return nil, errors.New("connector: client is required")
+	}
+	if name == "" {
+		return nil, errors.New("connector: name is required")
+	}
+	if source == nil || positions == nil {
+		return nil, errors.New("connector: source and position store are required")
+	}
+	opts := GetDefaultOptions()
+	for _, opt := range options {
+		if opt != nil {
+			opt(&opts)
+		}
+	}
+	if opts.BatchSize <= 0 {
+		return nil, errors.New("connector: batch size must be greater than zero")
+	}
+	if opts.Retries < 0 {
+		return nil, errors.New("connector: retries must not be negative")
+	}
+	if sink.Partitioning.Kind == 0 {
+		sink.Partitioning = iggcon.None()
+	}
+	return &SourceConnector{
+		client:    client,
+		name:      name,
+		source:    source,
+		positions: positions,
+		sink:      sink,
+		opts:      opts,
+	}, nil
+}
+
+// Run opens the source at the saved position and produces its records until ctx is cancelled or
+// an error occurs, closing the source before returning. It returns nil when stopped through ctx.
+func (c *SourceConnector) Run(ctx context.Context) error {
+	position, err := c.positions.Load()
+	if err != nil {
+		return err
+	}
+	if err := c.source.Open(ctx, position); err != nil {
+		return err
+	}
+	err = c.run(ctx)
+	err = errors.Join(err, c.source.Close())
+	if ctx.Err() != nil && errors.Is(err, ctx.Err()) {
+		return nil
+	}
+	return err
+}
+
+// Stats returns the activity of the connector.
+func (c *SourceConnector) Stats() SourceStats {
+	return SourceStats{
+		Read:     c.stats.read.Load(),
+		Produced: c.stats.produced.Load(),
+		Retries:  c.stats.retries.Load(),
+		Failures: c.stats.failures.Load(),
+	}
+}
+
+func (c *SourceConnector) run(ctx context.Context) error {
+	for {
+		var records []SourceRecord
+		err := c.retry(ctx, func() (err error) {
+			records, err = c.source.Read(ctx)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		c.stats.read.Add(uint64(len(records)))
+		if len(records) == 0 {
+			timer := time.NewTimer(c.opts.PollInterval)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-timer.C:
+			}
+			continue
+		}
+		for start := 0; start < len(records); start += c.opts.BatchSize {
+			if err := c.send(ctx, records[start:min(start+c.opts.BatchSize, len(records))]); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// send sends a batch of records and saves the position of the last one.
+func (c *SourceConnector) send(ctx context.Context, records []SourceRecord) error {
+	messages := make([]iggcon.MessengerMessage, len(records))
+	for i, record := range records {
+		messages[i] = record.Message
+		if messages[i].Header.Id == (iggcon.MessageID{}) {
+			source := binary.LittleEndian.AppendUint32(nil, uint32(len(c.name)))
+			source = append(append(source, c.name...), record.Position...)
+			messages[i].Header.Id = iggcon.MessageID(uuid.NewSHA1(sourceNamespace, source))
+		}
+	}
+	err := c.retry(ctx, func() error {
+		if c.sink.Confirmation != iggcon.ConfirmationDefault {
+			return c.client.SendMessagesWithConfirmation(c.sink.StreamId, c.sink.TopicId, c.sink.Partitioning, messages, c.sink.Confirmation)
+		}
+		return c.client.SendMessages(c.sink.StreamId, c.sink.TopicId, c.sink.Partitioning, messages)
+	})
+	if err != nil {
+		return err
+	}
+	c.stats.produced.Add(uint64(len(records)))
+	return c.positions.Save(records[len(records)-1].Position)
+}
+
+func (c *SourceConnector) retry(ctx context.Context, f func() error) error {
+	retries, err := withRetries(ctx, c.opts.Retries, c.opts.RetryBackoff, f)
+	c.stats.retries.Add(uint64(retries))
+	if err != nil {
+		c.stats.failures.Add(1)
+	}
+	return err
+}
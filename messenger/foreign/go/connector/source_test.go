@@ -0,0 +1,117 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package connector
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/apache/messenger/foreign/go/consumer"
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+)
+
+// lineSource reads lines, positioned by their index.
+type lineSource struct {
+	lines []string
+	next  int
+}
+
+func (s *lineSource) Open(_ context.Context, position []byte) error {
+	s.next = 0
+	if position != nil {
+		index, err := strconv.Atoi(string(position))
+		if err != nil {
+			return err
+		}
+		s.next = index + 1
+	}
+	return nil
+}
+
+func (s *lineSource) Read(context.Context) ([]SourceRecord, error) {
+	var records []SourceRecord
+	for ; s.next < len(s.lines); s.next++ {
+		message, err := iggcon.NewMessengerMessage([]byte(s.lines[s.next]))
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, SourceRecord{Message: message, Position: []byte(strconv.Itoa(s.next))})
+	}
+	return records, nil
+}
+
+func (s *lineSource) Close() error {
+	return nil
+}
+
+func TestSourceConnector(t *testing.T) {
+	client, streamId, topicId := newTestClient(t)
+	positions := NewFilePositionStore(filepath.Join(t.TempDir(), "position"))
+	source := &lineSource{lines: []string{"a", "b", "c"}}
+
+	run := func() *SourceConnector {
+		connector, err := NewSourceConnector(client, "lines", source, positions, consumer.Sink{StreamId: streamId, TopicId: topicId},
+			WithBatchSize(2), WithPollInterval(5*time.Millisecond))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() { done <- connector.Run(ctx) }()
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+		if err := <-done; err != nil {
+			t.Fatal(err)
+		}
+		return connector
+	}
+
+	if stats := run().Stats(); stats.Read != 3 || stats.Produced != 3 {
+		t.Fatalf("unexpected source stats: %+v", stats)
+	}
+	if position, err := positions.Load(); err != nil || string(position) != "2" {
+		t.Fatalf("expected position 2 to be saved, got %q, %v", position, err)
+	}
+
+	// a restart from a stale position sends the records again with the same IDs
+	if err := positions.Save([]byte("0")); err != nil {
+		t.Fatal(err)
+	}
+	if stats := run().Stats(); stats.Produced != 2 {
+		t.Fatalf("expected 2 records sent again, got %+v", stats)
+	}
+	partitionId := uint32(1)
+	polled, err := client.PollMessages(streamId, topicId, iggcon.DefaultConsumer(), iggcon.FirstPollingStrategy(), 10, false, &partitionId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var payloads []string
+	for _, message := range polled.Messages {
+		payloads = append(payloads, string(message.Payload))
+	}
+	if want := []string{"a", "b", "c", "b", "c"}; !reflect.DeepEqual(payloads, want) {
+		t.Fatalf("expected %v, got %v", want, payloads)
+	}
+	if polled.Messages[1].Header.Id != polled.Messages[3].Header.Id || polled.Messages[1].Header.Id == polled.Messages[2].Header.Id {
+		t.Fatal("expected the IDs to be derived from the positions")
+	}
+}
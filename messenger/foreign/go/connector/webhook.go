@@ -0,0 +1,388 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package connector
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"text/template"
+	"time"
+
+	"github.com/apache/messenger/foreign/go/consumer"
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+)
+
+// IdempotencyKeyHeader is the request header identifying a message, identical on every delivery
+// of the message, for the endpoints to drop the duplicates.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// WebhookConfig configures a WebhookSink.
+type WebhookConfig struct {
+	// URL is the endpoint, a text/template executed with the WebhookMessage, e.g.
+	// "https://hooks.example.com/tenants/{{index .Headers \"tenant\"}}/orders".
+	URL string
+	// Method of the requests, POST by default.
+	Method string
+	// Headers are the request headers, whose values are templates like URL.
+	Headers map[string]string
+	// ContentType of the payloads, application/json by default.
+	ContentType string
+	// Concurrency is the maximum number of requests in flight, 8 by default. The messages of a
+	// partition are delivered in order only with a concurrency of 1.
+	Concurrency int
+	// Retries is the number of times a request failing transiently is retried, 5 by default and
+	// none when negative.
+	Retries int
+	// RetryBackoff is the pause before the first retry, doubled on every retry, 500ms by default.
+	RetryBackoff time.Duration
+	// Timeout bounds every request, 10 seconds by default.
+	Timeout time.Duration
+	// DeadLetter, when set, receives the messages failing permanently, e.g. consumer.DeadLetter.
+	// Otherwise such a message stops the connector.
+	DeadLetter consumer.QuarantineHandler
+	// Offsets stores the offsets of the delivered messages, e.g. consumer.ServerOffsets.
+	Offsets consumer.OffsetStore
+	// HTTPClient sends the requests, http.DefaultClient by default.
+	HTTPClient *http.Client
+}
+
+// WebhookMessage is the data the templates of a WebhookConfig are executed with.
+type WebhookMessage struct {
+	PartitionId uint32
+	Offset      uint64
+	// Id is the hexadecimal ID of the message.
+	Id        string
+	Key       string
+	Timestamp time.Time
+	// Headers are the user headers of kind String.
+	Headers map[string]string
+}
+
+// WebhookStats reports the activity of a WebhookSink.
+type WebhookStats struct {
+	// Delivered is the number of messages the endpoints accepted.
+	Delivered uint64
+	// Retries is the number of retried requests.
+	Retries uint64
+	// DeadLettered is the number of messages passed to the DeadLetter handler.
+	DeadLettered uint64
+}
+
+// WebhookError is the error of a request the endpoint rejected.
+type WebhookError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *WebhookError) Error() string {
+	return fmt.Sprintf("connector: webhook responded %d: %s", e.StatusCode, e.Body)
+}
+
+// Permanent reports whether the endpoint rejected the request itself, with a 4xx status other
+// than 408 Request Timeout and 429 Too Many Requests.
+func (e *WebhookError) Permanent() bool {
+	return e.StatusCode/100 == 4 && e.StatusCode != http.StatusRequestTimeout && e.StatusCode != http.StatusTooManyRequests
+}
+
+// WebhookSink is a Sink pushing every message to an HTTP endpoint, with its payload as the body of
+// the request. The requests failing transiently are retried, and the messages failing
+// permanently are passed to the DeadLetter handler. A commit waits for the requests in flight
+// and stores the offsets in the offset store, so a message is delivered at least once, with the
+// same IdempotencyKeyHeader on every delivery.
+type WebhookSink struct {
+	config  WebhookConfig
+	url     *template.Template
+	headers map[string]*template.Template
+	slots   chan struct{}
+
+	mtx        sync.Mutex
+	partitions map[uint32]*webhookPartition
+
+	stats struct {
+		delivered    atomic.Uint64
+		retries      atomic.Uint64
+		deadLettered atomic.Uint64
+	}
+}
+
+// webhookPartition tracks the requests in flight of a partition.
+type webhookPartition struct {
+	inFlight sync.WaitGroup
+	mtx      sync.Mutex
+	// err is the first failure since the last commit.
+	err error
+}
+
+// NewWebhookSink creates a WebhookSink.
+func NewWebhookSink(config WebhookConfig) (*WebhookSink, error) {
+	if config.URL == "" {
+		return nil, errors.New("connector: webhook url is required")
+	}
+	if config.Offsets == nil {
+		return nil, errors.New("connector: webhook offset store is required")
+	}
+	if config.Method == "" {
+		config.Method = http.MethodPost
+	}
+	if config.ContentType == "" {
+		config.ContentType = "application/json"
+	}
+	if config.Concurrency <= 0 {
+		config.Concurrency = 8
+	}
+	if config.Retries == 0 {
+		config.Retries = 5
+	} else if config.Retries < 0 {
+		config.Retries = 0
+	}
+	if config.RetryBackoff <= 0 {
+		config.RetryBackoff = 500 * time.Millisecond
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = http.DefaultClient
+	}
+	url, err := template.New("url").Parse(config.URL)
+	if err != nil {
+		return nil, fmt.Errorf("connector: invalid webhook url: %w", err)
+	}
+	headers := map[string]*template.Template{}
+	for name, value := range config.Headers {
+		if headers[name], err = template.New(name).Parse(value); err != nil {
+			return nil, fmt.Errorf("connector: invalid webhook header %s: %w", name, err)
+		}
+	}
+	return &WebhookSink{
+		config:     config,
+		url:        url,
+		headers:    headers,
+		slots:      make(chan struct{}, config.Concurrency),
+		partitions: map[uint32]*webhookPartition{},
+	}, nil
+}
+
+// Stats returns the activity of the sink.
+func (s *WebhookSink) Stats() WebhookStats {
+	return WebhookStats{
+		Delivered:    s.stats.delivered.Load(),
+		Retries:      s.stats.retries.Load(),
+		DeadLettered: s.stats.deadLettered.Load(),
+	}
+}
+
+// Open waits for the requests in flight of the partition, then loads its offset from the offset
+// store.
+func (s *WebhookSink) Open(_ context.Context, partitionId uint32) (uint64, bool, error) {
+	s.mtx.Lock()
+	partition, ok := s.partitions[partitionId]
+	if !ok {
+		partition = &webhookPartition{}
+		s.partitions[partitionId] = partition
+	}
+	s.mtx.Unlock()
+	partition.inFlight.Wait()
+	partition.mtx.Lock()
+	partition.err = nil
+	partition.mtx.Unlock()
+	return s.config.Offsets.Load(partitionId)
+}
+
+// Write starts the delivery of the message once fewer than Concurrency requests are in flight,
+// and returns the failure of a previous delivery of the partition, if any.
+func (s *WebhookSink) Write(ctx context.Context, message iggcon.ReceivedMessage) error {
+	partition, err := s.partition(message.PartitionId)
+	if err != nil {
+		return err
+	}
+	if err := partition.failure(); err != nil {
+		return &permanentError{err}
+	}
+	select {
+	case s.slots <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	partition.inFlight.Add(1)
+	// the deliveries in flight complete when Run stops, for its final commit
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		defer partition.inFlight.Done()
+		defer func() { <-s.slots }()
+		if err := s.deliver(ctx, message); err != nil {
+			partition.fail(err)
+		}
+	}()
+	return nil
+}
+
+// Commit waits for the requests in flight of the partitions, then stores the offsets unless a
+// delivery failed.
+func (s *WebhookSink) Commit(_ context.Context, offsets map[uint32]uint64) error {
+	for partitionId := range offsets {
+		partition, err := s.partition(partitionId)
+		if err != nil {
+			return err
+		}
+		partition.inFlight.Wait()
+		if err := partition.failure(); err != nil {
+			return &permanentError{err}
+		}
+	}
+	return s.config.Offsets.Store(offsets)
+}
+
+// deliver sends the message with retries, passing it to the DeadLetter handler when it fails
+// permanently.
+func (s *WebhookSink) deliver(ctx context.Context, message iggcon.ReceivedMessage) error {
+	attempts := 0
+	retries, err := withRetries(ctx, s.config.Retries, s.config.RetryBackoff, func() error {
+		attempts++
+		return s.send(ctx, message)
+	})
+	s.stats.retries.Add(uint64(retries))
+	var permanent *permanentError
+	switch {
+	case err == nil:
+		s.stats.delivered.Add(1)
+		return nil
+	case errors.As(err, &permanent) && s.config.DeadLetter != nil:
+		if err := s.config.DeadLetter(ctx, message, attempts, permanent.err); err != nil {
+			return err
+		}
+		s.stats.deadLettered.Add(1)
+		return nil
+	}
+	return err
+}
+
+// send sends a single request for the message.
+func (s *WebhookSink) send(ctx context.Context, message iggcon.ReceivedMessage) error {
+	data, err := newWebhookMessage(message)
+	if err != nil {
+		return &permanentError{err}
+	}
+	url, err := execute(s.url, data)
+	if err != nil {
+		return &permanentError{err}
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.config.Timeout)
+	defer cancel()
+	request, err := http.NewRequestWithContext(ctx, s.config.Method, url, bytes.NewReader(message.Message.Payload))
+	if err != nil {
+		return &permanentError{err}
+	}
+	request.Header.Set("Content-Type", s.config.ContentType)
+	request.Header.Set(IdempotencyKeyHeader, idempotencyKey(message))
+	for name, header := range s.headers {
+		value, err := execute(header, data)
+		if err != nil {
+			return &permanentError{err}
+		}
+		request.Header.Set(name, value)
+	}
+	response, err := s.config.HTTPClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+	if response.StatusCode/100 == 2 {
+		return nil
+	}
+	rejected := &WebhookError{StatusCode: response.StatusCode, Body: string(body)}
+	if rejected.Permanent() {
+		return &permanentError{rejected}
+	}
+	return rejected
+}
+
+func (s *WebhookSink) partition(partitionId uint32) (*webhookPartition, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	partition, ok := s.partitions[partitionId]
+	if !ok {
+		return nil, fmt.Errorf("connector: partition %d is not open", partitionId)
+	}
+	return partition, nil
+}
+
+func (p *webhookPartition) fail(err error) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	if p.err == nil {
+		p.err = err
+	}
+}
+
+func (p *webhookPartition) failure() error {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	return p.err
+}
+
+func newWebhookMessage(message iggcon.ReceivedMessage) (WebhookMessage, error) {
+	header := message.Message.Header
+	data := WebhookMessage{
+		PartitionId: message.PartitionId,
+		Offset:      header.Offset,
+		Id:          hex.EncodeToString(header.Id[:]),
+		Key:         string(message.Message.Key()),
+		Timestamp:   time.UnixMicro(int64(header.OriginTimestamp)),
+		Headers:     map[string]string{},
+	}
+	if len(message.Message.UserHeaders) == 0 {
+		return data, nil
+	}
+	headers, err := iggcon.DeserializeHeaders(message.Message.UserHeaders)
+	if err != nil {
+		return data, err
+	}
+	for key, value := range headers {
+		if value.Kind == iggcon.String {
+			data.Headers[key.Value] = string(value.Value)
+		}
+	}
+	return data, nil
+}
+
+func execute(tmpl *template.Template, data WebhookMessage) (string, error) {
+	var rendered strings.Builder
+	if err := tmpl.Execute(&rendered, data); err != nil {
+		return "", err
+	}
+	return rendered.String(), nil
+}
+
+// idempotencyKey identifies the message by ID, or by partition and offset when it has none.
+func idempotencyKey(message iggcon.ReceivedMessage) string {
+	if id := message.Message.Header.Id; id != (iggcon.MessageID{}) {
+		return hex.EncodeToString(id[:])
+	}
+	return strconv.FormatUint(uint64(message.PartitionId), 10) + "-" + strconv.FormatUint(message.Message.Header.Offset, 10)
+}
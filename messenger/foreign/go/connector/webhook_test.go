@@ -0,0 +1,104 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package connector
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/apache/messenger/foreign/go/consumer"
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+)
+
+func TestWebhookSink(t *testing.T) {
+	client, streamId, topicId := newTestClient(t)
+	if _, err := client.CreateTopic(streamId, "dead-letters", 1, iggcon.CompressionAlgorithmNone, 0, 0, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	deadLettersId, _ := iggcon.NewIdentifier("dead-letters")
+
+	var mtx sync.Mutex
+	var received []string
+	failed := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mtx.Lock()
+		defer mtx.Unlock()
+		received = append(received, r.URL.Path+" "+r.Header.Get("X-Offset")+" "+string(body))
+		switch {
+		case string(body) == "retried" && !failed:
+			failed = true
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case string(body) == "rejected":
+			w.WriteHeader(http.StatusBadRequest)
+		case r.Header.Get(IdempotencyKeyHeader) == "":
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	for _, payload := range []string{"accepted", "retried", "rejected"} {
+		message, err := iggcon.NewMessengerMessage([]byte(payload), iggcon.WithUserHeaders(map[iggcon.HeaderKey]iggcon.HeaderValue{
+			{Value: "tenant"}: iggcon.NewStringHeaderValue("acme"),
+		}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := client.SendMessages(streamId, topicId, iggcon.None(), []iggcon.MessengerMessage{message}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sink, err := NewWebhookSink(WebhookConfig{
+		URL:          server.URL + `/tenants/{{index .Headers "tenant"}}`,
+		Headers:      map[string]string{"X-Offset": "{{.Offset}}"},
+		Concurrency:  1,
+		RetryBackoff: time.Millisecond,
+		DeadLetter:   consumer.DeadLetter(client, streamId, deadLettersId),
+		Offsets:      consumer.ServerOffsets(client, iggcon.DefaultConsumer(), streamId, topicId),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	connector, err := NewSinkConnector(client, streamId, topicId, sink,
+		WithConsumerOptions(consumer.WithPollInterval(5*time.Millisecond)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	runSink(t, connector, 3)
+
+	want := []string{"/tenants/acme 0 accepted", "/tenants/acme 1 retried", "/tenants/acme 1 retried", "/tenants/acme 2 rejected"}
+	if !reflect.DeepEqual(received, want) {
+		t.Fatalf("expected requests %v, got %v", want, received)
+	}
+	if stats := sink.Stats(); stats.Delivered != 2 || stats.Retries != 1 || stats.DeadLettered != 1 {
+		t.Fatalf("unexpected webhook stats: %+v", stats)
+	}
+	if got := pollTopic(t, client, streamId, deadLettersId); !reflect.DeepEqual(got, []string{"rejected"}) {
+		t.Fatalf("expected the rejected message to be dead-lettered, got %v", got)
+	}
+	partitionId := uint32(1)
+	if stored, err := client.GetConsumerOffset(iggcon.DefaultConsumer(), streamId, topicId, &partitionId); err != nil || stored == nil || stored.StoredOffset != 2 {
+		t.Fatalf("expected offset 2 to be stored, got %+v, %v", stored, err)
+	}
+}
@@ -0,0 +1,172 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package consumer
+
+import (
+	"context"
+	"sync"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+)
+
+// Checkpoint is the offsets of a consumer along with the state derived from the messages up to them.
+type Checkpoint struct {
+	Offsets map[uint32]uint64
+	State   []byte
+}
+
+// CheckpointStore is an OffsetStore able to store a state blob along with the offsets, in a single
+// atomic write, like FileOffsetStore.
+type CheckpointStore interface {
+	OffsetStore
+	// LoadCheckpoint returns the stored checkpoint, false when none is stored.
+	LoadCheckpoint() (Checkpoint, bool, error)
+	// StoreCheckpoint replaces the stored offsets and state with the checkpoint.
+	StoreCheckpoint(checkpoint Checkpoint) error
+}
+
+// State is the state of a stateful processor, checkpointed along with the offsets.
+type State interface {
+	// Snapshot serializes the state. It is never called while a message is being handled.
+	Snapshot() ([]byte, error)
+	// Restore replaces the state with a snapshot.
+	Restore(snapshot []byte) error
+}
+
+// Checkpointer persists the state of a stateful processor along with the offsets of the messages
+// it reflects, and restores both on startup, so a restarted processor resumes from a consistent
+// state. It is the OffsetStore of a consumer created WithCheckpointer: every commit of the
+// consumer, e.g. once per interval with CommitInterval, checkpoints the state.
+type Checkpointer struct {
+	store CheckpointStore
+	state State
+
+	// handling is held for reading while handling a message and for writing while checkpointing.
+	handling sync.RWMutex
+	mtx      sync.Mutex
+	offsets  map[uint32]uint64
+	restored bool
+}
+
+// NewCheckpointer creates a Checkpointer of state to store.
+func NewCheckpointer(store CheckpointStore, state State) *Checkpointer {
+	return &Checkpointer{store: store, state: state, offsets: map[uint32]uint64{}}
+}
+
+// Restore restores the state and the offsets of the stored checkpoint, if any. It is called by the
+// consumer when it starts, and only restores the checkpoint once.
+func (c *Checkpointer) Restore() error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.restoreLocked()
+}
+
+func (c *Checkpointer) restoreLocked() error {
+	if c.restored {
+		return nil
+	}
+	checkpoint, ok, err := c.store.LoadCheckpoint()
+	if err != nil {
+		return err
+	}
+	if ok {
+		if err := c.state.Restore(checkpoint.State); err != nil {
+			return err
+		}
+		for partitionId, offset := range checkpoint.Offsets {
+			c.offsets[partitionId] = offset
+		}
+	}
+	c.restored = true
+	return nil
+}
+
+// Checkpoint stores the state along with the offsets of the messages handled so far, waiting for
+// the messages being handled.
+func (c *Checkpointer) Checkpoint() error {
+	return c.checkpoint(nil, nil)
+}
+
+// checkpoint stores the state, the offsets being raised to offsets and the one of the deleted
+// partition being removed.
+func (c *Checkpointer) checkpoint(offsets map[uint32]uint64, deleted *uint32) error {
+	c.handling.Lock()
+	defer c.handling.Unlock()
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if err := c.restoreLocked(); err != nil {
+		return err
+	}
+	for partitionId, offset := range offsets {
+		if current, ok := c.offsets[partitionId]; !ok || current < offset {
+			c.offsets[partitionId] = offset
+		}
+	}
+	if deleted != nil {
+		delete(c.offsets, *deleted)
+	}
+
+	snapshot, err := c.state.Snapshot()
+	if err != nil {
+		return err
+	}
+	checkpoint := Checkpoint{Offsets: make(map[uint32]uint64, len(c.offsets)), State: snapshot}
+	for partitionId, offset := range c.offsets {
+		checkpoint.Offsets[partitionId] = offset
+	}
+	return c.store.StoreCheckpoint(checkpoint)
+}
+
+func (c *Checkpointer) Load(partitionId uint32) (uint64, bool, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if err := c.restoreLocked(); err != nil {
+		return 0, false, err
+	}
+	offset, ok := c.offsets[partitionId]
+	return offset, ok, nil
+}
+
+// Store checkpoints the state. The offsets of the messages handled meanwhile by the other
+// partitions are stored too, since the state reflects them.
+func (c *Checkpointer) Store(offsets map[uint32]uint64) error {
+	return c.checkpoint(offsets, nil)
+}
+
+// Delete checkpoints the state without the offset of the partition.
+func (c *Checkpointer) Delete(partitionId uint32) error {
+	return c.checkpoint(nil, &partitionId)
+}
+
+// middleware records the offsets of the handled messages, keeping the checkpoints from snapshotting
+// the state while a message is handled.
+func (c *Checkpointer) middleware(next Handler) Handler {
+	return func(ctx context.Context, message iggcon.ReceivedMessage) error {
+		c.handling.RLock()
+		defer c.handling.RUnlock()
+		if err := next(ctx, message); err != nil {
+			return err
+		}
+		c.mtx.Lock()
+		defer c.mtx.Unlock()
+		if current, ok := c.offsets[message.PartitionId]; !ok || current < message.Message.Header.Offset {
+			c.offsets[message.PartitionId] = message.Message.Header.Offset
+		}
+		return nil
+	}
+}
@@ -0,0 +1,89 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package consumer
+
+import (
+	"context"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+)
+
+// countState counts the handled messages.
+type countState struct {
+	count int
+}
+
+func (s *countState) Snapshot() ([]byte, error) {
+	return []byte(strconv.Itoa(s.count)), nil
+}
+
+func (s *countState) Restore(snapshot []byte) error {
+	count, err := strconv.Atoi(string(snapshot))
+	s.count = count
+	return err
+}
+
+func TestConsumer_Checkpointer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	state := &countState{}
+	ctx, cancel := context.WithCancel(context.Background())
+	client, c, streamId, topicId := newTestConsumer(t, func(_ context.Context, _ iggcon.ReceivedMessage) error {
+		state.count++
+		if state.count == 3 {
+			cancel()
+		}
+		return nil
+	}, WithAutoCommit(false), WithCheckpointer(NewCheckpointer(NewFileOffsetStore(path), state)))
+	if err := c.Run(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	checkpoint, ok, err := NewFileOffsetStore(path).LoadCheckpoint()
+	if err != nil || !ok {
+		t.Fatalf("expected a checkpoint, got %v, %v", ok, err)
+	}
+	if checkpoint.Offsets[1] != 2 || string(checkpoint.State) != "3" {
+		t.Fatalf("expected offset 2 with a count of 3, got %v and %q", checkpoint.Offsets, checkpoint.State)
+	}
+
+	d, _ := iggcon.NewMessengerMessage([]byte("d"))
+	if err := client.SendMessages(streamId, topicId, iggcon.PartitionId(1), []iggcon.MessengerMessage{d}); err != nil {
+		t.Fatal(err)
+	}
+	restored := &countState{}
+	var handled []string
+	ctx, cancel = context.WithCancel(context.Background())
+	c, err = NewConsumer(client, streamId, topicId, func(_ context.Context, message iggcon.ReceivedMessage) error {
+		restored.count++
+		handled = append(handled, string(message.Message.Payload))
+		cancel()
+		return nil
+	}, WithAutoCommit(false), WithCheckpointer(NewCheckpointer(NewFileOffsetStore(path), restored)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Run(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if restored.count != 4 || len(handled) != 1 || handled[0] != "d" {
+		t.Fatalf("expected to resume from the checkpoint, got a count of %d after handling %v", restored.count, handled)
+	}
+}
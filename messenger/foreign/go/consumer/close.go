@@ -0,0 +1,94 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package consumer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// errStopped stops the polling loops once Close was called.
+var errStopped = errors.New("consumer: stopped")
+
+// DrainError reports what a Consumer could not drain while closing.
+type DrainError struct {
+	// Unhandled is the number of polled messages which were not handled because the consumer
+	// was cancelled when the context of Close was done. Their offsets were not stored unless
+	// auto commit is enabled.
+	Unhandled int
+	// Err joins the error of the context, if done, and the failures to store the pending offsets
+	// and to leave the consumer group.
+	Err error
+}
+
+func (e *DrainError) Error() string {
+	return fmt.Sprintf("consumer: %d polled message(s) unhandled while closing: %v", e.Unhandled, e.Err)
+}
+
+func (e *DrainError) Unwrap() error {
+	return e.Err
+}
+
+// Close stops polling and waits for Run to handle the messages already polled, storing their
+// offsets and leaving the consumer group. When ctx is done first, Run is cancelled and Close
+// waits for the handler to return, so handlers must honour their context. It returns a
+// *DrainError when polled messages were left unhandled, their offsets could not be stored or the
+// group could not be left.
+func (c *Consumer) Close(ctx context.Context) error {
+	c.stopOnce.Do(func() {
+		c.cancelStopped()
+	})
+	c.mtx.Lock()
+	running, cancel := c.running, c.cancel
+	c.mtx.Unlock()
+	if running == nil {
+		return nil
+	}
+
+	var ctxErr error
+	select {
+	case <-running:
+	case <-ctx.Done():
+		ctxErr = ctx.Err()
+		cancel()
+		<-running
+	}
+
+	c.mtx.Lock()
+	commitErr, leaveErr := c.commitErr, c.leaveErr
+	c.mtx.Unlock()
+	unhandled := int(c.unhandled.Load())
+	if ctxErr == nil && commitErr == nil && leaveErr == nil && unhandled == 0 {
+		return nil
+	}
+	return &DrainError{Unhandled: unhandled, Err: errors.Join(ctxErr, commitErr, leaveErr)}
+}
+
+// checkStopped returns the error stopping the polling loops, if any.
+func (c *Consumer) checkStopped(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	select {
+	case <-c.stopped.Done():
+		return errStopped
+	default:
+		return nil
+	}
+}
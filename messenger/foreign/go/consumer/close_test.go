@@ -0,0 +1,165 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package consumer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengertest"
+)
+
+// newTestConsumer creates a consumer of a topic holding 3 messages in a single partition, with a
+// "billing" consumer group.
+func newTestConsumer(t *testing.T, handler Handler, options ...Option) (*messengertest.Client, *Consumer, iggcon.Identifier, iggcon.Identifier) {
+	client := messengertest.NewClient()
+	if _, err := client.CreateStream("orders", nil); err != nil {
+		t.Fatal(err)
+	}
+	streamId, _ := iggcon.NewIdentifier("orders")
+	topicId, _ := iggcon.NewIdentifier("created")
+	if _, err := client.CreateTopic(streamId, "created", 1, iggcon.CompressionAlgorithm(1), 0, 0, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.CreateConsumerGroup(streamId, topicId, "billing", nil); err != nil {
+		t.Fatal(err)
+	}
+	var messages []iggcon.MessengerMessage
+	for _, payload := range []string{"a", "b", "c"} {
+		message, err := iggcon.NewMessengerMessage([]byte(payload))
+		if err != nil {
+			t.Fatal(err)
+		}
+		messages = append(messages, message)
+	}
+	if err := client.SendMessages(streamId, topicId, iggcon.PartitionId(1), messages); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := NewConsumer(client, streamId, topicId, handler, options...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client, c, streamId, topicId
+}
+
+func groupMembership() []Option {
+	groupId, _ := iggcon.NewIdentifier("billing")
+	return []Option{
+		WithConsumer(iggcon.NewGroupConsumer(groupId)),
+		WithGroupMembership(),
+		WithAutoCommit(false),
+	}
+}
+
+func TestConsumer_CloseDrainsPolledMessages(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	var handled []string
+	client, c, streamId, topicId := newTestConsumer(t, func(ctx context.Context, message iggcon.ReceivedMessage) error {
+		if len(handled) == 0 {
+			close(started)
+			<-release
+		}
+		handled = append(handled, string(message.Message.Payload))
+		return nil
+	}, groupMembership()...)
+	ran := make(chan error, 1)
+	go func() {
+		ran <- c.Run(context.Background())
+	}()
+
+	<-started
+	closed := make(chan error, 1)
+	go func() {
+		closed <- c.Close(context.Background())
+	}()
+	close(release)
+	if err := <-closed; err != nil {
+		t.Fatalf("expected Close to drain the consumer, got %v", err)
+	}
+	if err := <-ran; err != nil {
+		t.Fatalf("expected Run to return nil once closed, got %v", err)
+	}
+	if len(handled) != 3 {
+		t.Fatalf("expected the polled messages to be handled, got %v", handled)
+	}
+
+	groupId, _ := iggcon.NewIdentifier("billing")
+	group, err := client.GetConsumerGroup(streamId, topicId, groupId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if group.MembersCount != 0 {
+		t.Fatalf("expected the consumer to leave the group, got %d members", group.MembersCount)
+	}
+}
+
+func TestConsumer_CloseDeadline(t *testing.T) {
+	started := make(chan struct{})
+	_, c, _, _ := newTestConsumer(t, func(ctx context.Context, message iggcon.ReceivedMessage) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	}, groupMembership()...)
+	go func() {
+		_ = c.Run(context.Background())
+	}()
+
+	<-started
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := c.Close(ctx)
+	var drainErr *DrainError
+	if !errors.As(err, &drainErr) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected a DrainError for the deadline, got %v", err)
+	}
+	if drainErr.Unhandled != 3 {
+		t.Fatalf("expected the interrupted and remaining messages to be unhandled, got %d", drainErr.Unhandled)
+	}
+}
+
+func TestConsumer_CloseInterruptsLongPoll(t *testing.T) {
+	handled := make(chan struct{}, 3)
+	_, c, _, _ := newTestConsumer(t, func(ctx context.Context, message iggcon.ReceivedMessage) error {
+		handled <- struct{}{}
+		return nil
+	}, WithLongPolling(time.Minute))
+	ran := make(chan error, 1)
+	go func() {
+		ran <- c.Run(context.Background())
+	}()
+	for i := 0; i < 3; i++ {
+		<-handled
+	}
+
+	// the consumer is waiting for the next messages
+	time.Sleep(20 * time.Millisecond)
+	start := time.Now()
+	if err := c.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-ran; err != nil {
+		t.Fatalf("expected Run to return nil once closed, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected Close to interrupt the long poll, took %s", elapsed)
+	}
+}
@@ -0,0 +1,219 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package consumer
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DeliverySemantics orders storing the offsets relative to handling the messages.
+type DeliverySemantics uint8
+
+const (
+	// AtMostOnce stores the offsets before handling the messages (commit-then-process): a
+	// crash never redelivers a message, but loses the messages polled and not yet handled.
+	AtMostOnce DeliverySemantics = iota
+	// AtLeastOnce stores the offsets once the handler succeeded (process-then-commit): a crash
+	// never loses a message, but redelivers the messages handled since the last commit, so the
+	// handler should be idempotent.
+	AtLeastOnce
+)
+
+func (s DeliverySemantics) String() string {
+	switch s {
+	case AtMostOnce:
+		return "at-most-once"
+	case AtLeastOnce:
+		return "at-least-once"
+	}
+	return "unknown"
+}
+
+type commitMode uint8
+
+const (
+	commitOnPoll commitMode = iota
+	commitAfterHandler
+	commitEvery
+	commitInterval
+	commitManual
+)
+
+// CommitPolicy decides when the offsets of the polled messages are stored, trading the number
+// of requests against the messages processed again, or lost, when the consumer crashes.
+type CommitPolicy struct {
+	mode     commitMode
+	messages int
+	interval time.Duration
+}
+
+// CommitOnPoll lets the server store the offset as soon as the messages are polled. It costs no
+// request, but the messages polled and not yet handled are lost when the consumer crashes
+// (at-most-once).
+func CommitOnPoll() CommitPolicy {
+	return CommitPolicy{mode: commitOnPoll}
+}
+
+// CommitAfterHandler stores the offset of every message the handler succeeded for. A crash only
+// redelivers the message being handled (at-least-once), at the cost of a request per message.
+func CommitAfterHandler() CommitPolicy {
+	return CommitPolicy{mode: commitAfterHandler}
+}
+
+// CommitEvery stores the offsets of the handled messages once every n handled messages and when
+// Run returns. A crash redelivers up to n-1 handled messages (at-least-once).
+func CommitEvery(n int) CommitPolicy {
+	return CommitPolicy{mode: commitEvery, messages: n}
+}
+
+// CommitInterval stores the offsets of the handled messages at most once per interval and when
+// Run returns. A crash redelivers the messages handled during the last interval (at-least-once).
+func CommitInterval(interval time.Duration) CommitPolicy {
+	return CommitPolicy{mode: commitInterval, interval: interval}
+}
+
+// CommitManual only stores the offsets of the handled messages when Consumer.Commit is called,
+// e.g. once the results of the handler were persisted elsewhere. A crash redelivers every
+// message handled since the last commit, and so does a Run returning without a commit.
+func CommitManual() CommitPolicy {
+	return CommitPolicy{mode: commitManual}
+}
+
+// delivery returns the delivery semantics the policy provides.
+func (p CommitPolicy) delivery() DeliverySemantics {
+	if p.mode == commitOnPoll {
+		return AtMostOnce
+	}
+	return AtLeastOnce
+}
+
+func (p CommitPolicy) validate(delivery DeliverySemantics) error {
+	switch {
+	case p.delivery() != delivery:
+		return fmt.Errorf("consumer: commit policy does not provide %s delivery", delivery)
+	case p.mode == commitEvery && p.messages <= 0:
+		return errors.New("consumer: commit message count must be greater than zero")
+	case p.mode == commitInterval && p.interval <= 0:
+		return errors.New("consumer: commit interval must be greater than zero")
+	}
+	return nil
+}
+
+// commits tracks the offsets of the handled messages which are not stored yet.
+type commits struct {
+	mtx     sync.Mutex
+	pending map[uint32]uint64
+	handled int
+	last    time.Time
+}
+
+// record records the offset of a handled message, reporting whether the policy requires
+// committing the pending offsets.
+func (c *commits) record(policy CommitPolicy, partitionId uint32, offset uint64) bool {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if c.last.IsZero() {
+		c.last = time.Now()
+	}
+	if c.pending == nil {
+		c.pending = map[uint32]uint64{}
+	}
+	c.pending[partitionId] = offset
+	c.handled++
+	return c.dueLocked(policy)
+}
+
+// due reports whether the policy requires committing the pending offsets.
+func (c *commits) due(policy CommitPolicy) bool {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.dueLocked(policy)
+}
+
+func (c *commits) dueLocked(policy CommitPolicy) bool {
+	if len(c.pending) == 0 {
+		return false
+	}
+	switch policy.mode {
+	case commitAfterHandler:
+		return true
+	case commitEvery:
+		return c.handled >= policy.messages
+	case commitInterval:
+		return time.Since(c.last) >= policy.interval
+	}
+	return false
+}
+
+// take returns the pending offsets and resets the counters.
+func (c *commits) take() map[uint32]uint64 {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	pending := c.pending
+	c.pending = nil
+	c.handled = 0
+	c.last = time.Now()
+	return pending
+}
+
+// restore puts back the offsets which failed to be stored, unless newer ones were handled since.
+func (c *commits) restore(offsets map[uint32]uint64) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if c.pending == nil {
+		c.pending = map[uint32]uint64{}
+	}
+	for partitionId, offset := range offsets {
+		if pending, ok := c.pending[partitionId]; !ok || pending < offset {
+			c.pending[partitionId] = offset
+		}
+	}
+}
+
+// drop discards the pending offset of the partition.
+func (c *commits) drop(partitionId uint32) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	delete(c.pending, partitionId)
+}
+
+// Commit stores the offsets of the messages handled so far which are not stored yet. The message
+// being handled is only recorded once its handler returned. It may be called concurrently with
+// Run and after Run returned, and is mostly useful with CommitManual.
+func (c *Consumer) Commit() error {
+	pending := c.commits.take()
+	if len(pending) == 0 {
+		return nil
+	}
+	if err := c.offsets.Store(pending); err != nil {
+		c.commits.restore(pending)
+		return err
+	}
+	return nil
+}
+
+// commitIfDue commits the pending offsets when the policy requires it.
+func (c *Consumer) commitIfDue() error {
+	if !c.commits.due(c.opts.Commit) {
+		return nil
+	}
+	return c.Commit()
+}
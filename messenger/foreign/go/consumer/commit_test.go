@@ -0,0 +1,136 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package consumer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengertest"
+)
+
+// runCommitPolicy handles the 3 test messages with the given policy, recording the stored offset
+// seen by the handler of each message, and returns the consumer once all were handled.
+func runCommitPolicy(t *testing.T, policy CommitPolicy) (*Consumer, []int64, func() int64) {
+	var client *messengertest.Client
+	var streamId, topicId iggcon.Identifier
+	stored := func() int64 {
+		partition := uint32(1)
+		offset, err := client.GetConsumerOffset(iggcon.DefaultConsumer(), streamId, topicId, &partition)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if offset == nil {
+			return -1
+		}
+		return int64(offset.StoredOffset)
+	}
+	var seen []int64
+	handled := make(chan struct{})
+	client, c, streamId, topicId := newTestConsumer(t, func(ctx context.Context, message iggcon.ReceivedMessage) error {
+		seen = append(seen, stored())
+		if len(seen) == 3 {
+			close(handled)
+		}
+		return nil
+	}, WithCommitPolicy(policy))
+
+	ran := make(chan error, 1)
+	go func() {
+		ran <- c.Run(context.Background())
+	}()
+	<-handled
+	if err := c.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-ran; err != nil {
+		t.Fatal(err)
+	}
+	return c, seen, stored
+}
+
+func TestConsumer_CommitEvery(t *testing.T) {
+	_, seen, stored := runCommitPolicy(t, CommitEvery(2))
+	if seen[0] != -1 || seen[1] != -1 || seen[2] != 1 {
+		t.Fatalf("expected the offset to be stored after 2 messages, got %v", seen)
+	}
+	if offset := stored(); offset != 2 {
+		t.Fatalf("expected the pending offset to be stored when Run returns, got %d", offset)
+	}
+}
+
+func TestConsumer_CommitManual(t *testing.T) {
+	c, seen, stored := runCommitPolicy(t, CommitManual())
+	if offset := stored(); seen[2] != -1 || offset != -1 {
+		t.Fatalf("expected no offset to be stored without Commit, got %v and %d", seen, offset)
+	}
+	if err := c.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if offset := stored(); offset != 2 {
+		t.Fatalf("expected Commit to store the handled offset, got %d", offset)
+	}
+}
+
+func TestWithDeliverySemantics(t *testing.T) {
+	opts := GetDefaultOptions()
+	if opts.Delivery != AtMostOnce || opts.Commit.mode != commitOnPoll {
+		t.Fatalf("expected at-most-once delivery by default, got %s", opts.Delivery)
+	}
+	WithDeliverySemantics(AtLeastOnce)(&opts)
+	if opts.Commit.mode != commitAfterHandler {
+		t.Fatalf("expected at-least-once delivery to commit after the handler, got %v", opts.Commit)
+	}
+	WithCommitPolicy(CommitEvery(10))(&opts)
+	WithDeliverySemantics(AtLeastOnce)(&opts)
+	if opts.Commit.mode != commitEvery {
+		t.Fatalf("expected the commit policy to be kept, got %v", opts.Commit)
+	}
+	WithDeliverySemantics(AtMostOnce)(&opts)
+	if opts.Commit.mode != commitOnPoll {
+		t.Fatalf("expected at-most-once delivery to commit on poll, got %v", opts.Commit)
+	}
+
+	opts.Delivery = AtLeastOnce
+	if err := opts.Commit.validate(opts.Delivery); err == nil {
+		t.Fatal("expected committing on poll not to provide at-least-once delivery")
+	}
+}
+
+func TestConsumer_AtLeastOnceStopsOnFailedMessage(t *testing.T) {
+	failure := errors.New("handler failed")
+	client, c, streamId, topicId := newTestConsumer(t, func(ctx context.Context, message iggcon.ReceivedMessage) error {
+		if message.Message.Header.Offset == 1 {
+			return failure
+		}
+		return nil
+	}, WithDeliverySemantics(AtLeastOnce))
+	if err := c.Run(context.Background()); !errors.Is(err, failure) {
+		t.Fatalf("expected the handler error, got %v", err)
+	}
+	partition := uint32(1)
+	offset, err := client.GetConsumerOffset(iggcon.DefaultConsumer(), streamId, topicId, &partition)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if offset == nil || offset.StoredOffset != 0 {
+		t.Fatalf("expected only the handled message to be committed, got %+v", offset)
+	}
+}
@@ -0,0 +1,378 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package consumer
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengercli"
+)
+
+// Handler processes a single polled message. Returning an error stops the consumer.
+type Handler func(ctx context.Context, message iggcon.ReceivedMessage) error
+
+// Middleware decorates a Handler, e.g. to skip, enrich or observe messages.
+type Middleware func(next Handler) Handler
+
+// Consumer continuously polls a topic and passes every message to a Handler.
+type Consumer struct {
+	client   messengercli.Client
+	streamId iggcon.Identifier
+	topicId  iggcon.Identifier
+	handler  Handler
+	opts     Options
+	progress progress
+	commits  commits
+	paused   paused
+	// offsets stores the offsets, the OffsetStore option or the server.
+	offsets   OffsetStore
+	positions positions
+
+	// stopped is cancelled by Close to stop polling, interrupting the long polls.
+	stopped       context.Context
+	cancelStopped context.CancelFunc
+	stopOnce      sync.Once
+	mtx           sync.Mutex
+	// running is closed when the current Run returns, cancel cancelling its context.
+	running   chan struct{}
+	cancel    context.CancelFunc
+	commitErr error
+	leaveErr  error
+	// unhandled counts the polled messages which were not handled because Run was cancelled.
+	unhandled atomic.Int64
+}
+
+// NewConsumer creates a Consumer reading the given stream and topic by unique IDs or names.
+func NewConsumer(
+	client messengercli.Client,
+	streamId iggcon.Identifier,
+	topicId iggcon.Identifier,
+	handler Handler,
+	options ...Option,
+) (*Consumer, error) {
+	if client == nil {
+		return nil, errors.New("consumer: client is required")
+	}
+	if handler == nil {
+		return nil, errors.New("consumer: handler is required")
+	}
+	opts := GetDefaultOptions()
+	for _, opt := range options {
+		if opt != nil {
+			opt(&opts)
+		}
+	}
+	if opts.BatchSize == 0 {
+		return nil, errors.New("consumer: batch size must be greater than zero")
+	}
+	if err := opts.Commit.validate(opts.Delivery); err != nil {
+		return nil, err
+	}
+	if opts.GroupMembership && opts.Consumer.Kind != iggcon.ConsumerKindGroup {
+		return nil, errors.New("consumer: group membership requires a group consumer")
+	}
+	if opts.OffsetStore != nil && opts.Commit.mode == commitOnPoll {
+		return nil, errors.New("consumer: an offset store requires a commit policy storing the offsets after the handler")
+	}
+	if opts.Heartbeat != nil && opts.Heartbeat.Interval <= 0 {
+		return nil, errors.New("consumer: heartbeat interval must be greater than zero")
+	}
+	if opts.Checkpointer != nil {
+		handler = opts.Checkpointer.middleware(handler)
+	}
+	for i := len(opts.Middlewares) - 1; i >= 0; i-- {
+		handler = opts.Middlewares[i](handler)
+	}
+
+	offsets := opts.OffsetStore
+	if offsets == nil {
+		offsets = ServerOffsets(client, opts.Consumer, streamId, topicId)
+	}
+
+	stopped, cancelStopped := context.WithCancel(context.Background())
+	return &Consumer{
+		client:        client,
+		streamId:      streamId,
+		topicId:       topicId,
+		handler:       handler,
+		opts:          opts,
+		offsets:       offsets,
+		stopped:       stopped,
+		cancelStopped: cancelStopped,
+	}, nil
+}
+
+// Run polls and handles messages until ctx is cancelled, Close is called or an error occurs.
+// It returns nil when stopped through ctx or Close, otherwise the first poll, commit, handler
+// or group membership error.
+func (c *Consumer) Run(ctx context.Context) error {
+	return c.run(ctx, func(ctx context.Context, partitions []*uint32) error {
+		if c.opts.StructuredConcurrency {
+			return c.runScoped(ctx, partitions)
+		}
+		return c.runSequential(ctx, partitions)
+	})
+}
+
+// run runs the polling loop over the resolved partitions, managing the group membership, the
+// final commit and the heartbeats around it.
+func (c *Consumer) run(ctx context.Context, loop func(ctx context.Context, partitions []*uint32) error) (err error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	running := make(chan struct{})
+	defer close(running)
+	c.mtx.Lock()
+	c.running, c.cancel = running, cancel
+	c.mtx.Unlock()
+
+	partitions, err := c.partitions()
+	if err != nil {
+		return err
+	}
+
+	if c.opts.GroupMembership {
+		if err := c.client.JoinConsumerGroup(c.streamId, c.topicId, c.opts.Consumer.Id); err != nil {
+			return err
+		}
+		defer func() {
+			leaveErr := c.client.LeaveConsumerGroup(c.streamId, c.topicId, c.opts.Consumer.Id)
+			c.mtx.Lock()
+			c.leaveErr = leaveErr
+			c.mtx.Unlock()
+			err = errors.Join(err, leaveErr)
+		}()
+	}
+
+	if err := c.checkOffsets(partitions); err != nil {
+		return err
+	}
+	if err := c.loadPositions(partitions); err != nil {
+		return err
+	}
+
+	if mode := c.opts.Commit.mode; mode == commitEvery || mode == commitInterval {
+		defer func() {
+			commitErr := c.Commit()
+			c.mtx.Lock()
+			c.commitErr = commitErr
+			c.mtx.Unlock()
+			err = errors.Join(err, commitErr)
+		}()
+	}
+
+	if c.opts.Heartbeat != nil {
+		heartbeatCtx, cancel := context.WithCancel(ctx)
+		stopped := make(chan struct{})
+		go func() {
+			defer close(stopped)
+			c.reportHeartbeats(heartbeatCtx)
+		}()
+		defer func() {
+			cancel()
+			<-stopped
+		}()
+	}
+
+	err = loop(ctx, partitions)
+	if errors.Is(err, errStopped) || ctx.Err() != nil && errors.Is(err, ctx.Err()) {
+		return nil
+	}
+	return err
+}
+
+func (c *Consumer) runSequential(ctx context.Context, partitions []*uint32) error {
+	for {
+		started := time.Now()
+		polledAny := false
+		for _, partition := range partitions {
+			if err := c.checkStopped(ctx); err != nil {
+				return err
+			}
+			polled, err := c.pollOnce(ctx, partition)
+			if err != nil {
+				return err
+			}
+			polledAny = polledAny || polled
+		}
+		if !polledAny {
+			if err := c.idle(ctx, started); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (c *Consumer) runScoped(ctx context.Context, partitions []*uint32) error {
+	s, scopeCtx := newScope(ctx)
+	for _, partition := range partitions {
+		s.goFunc(func() error {
+			for {
+				if err := c.checkStopped(scopeCtx); err != nil {
+					return err
+				}
+				started := time.Now()
+				polled, err := c.pollOnce(scopeCtx, partition)
+				if err != nil {
+					return err
+				}
+				if !polled {
+					if err := c.idle(scopeCtx, started); err != nil {
+						return err
+					}
+				}
+			}
+		})
+	}
+	return s.wait()
+}
+
+// pollOnce polls a single batch from the partition and handles it, reporting whether any
+// message was received.
+func (c *Consumer) pollOnce(ctx context.Context, partitionId *uint32) (bool, error) {
+	if err := c.commitIfDue(); err != nil {
+		return false, err
+	}
+	if c.paused.isPaused(partitionId) {
+		return false, nil
+	}
+	polled, err := c.poll(ctx, partitionId, c.pollingStrategy(partitionId))
+	if err != nil {
+		if partitionId != nil && isInvalidOffset(err) {
+			_, err = c.resetOffset(*partitionId, err)
+		}
+		return false, err
+	}
+	if polled == nil || len(polled.Messages) == 0 {
+		return false, nil
+	}
+
+	for i, message := range polled.Messages {
+		if err := ctx.Err(); err != nil {
+			c.unhandled.Add(int64(len(polled.Messages) - i))
+			return true, err
+		}
+		received := iggcon.ReceivedMessage{
+			Message:       message,
+			CurrentOffset: polled.CurrentOffset,
+			PartitionId:   polled.PartitionId,
+		}
+		expired := c.opts.DropExpired && isExpired(&message)
+		if expired {
+			if c.opts.OnExpired != nil {
+				c.opts.OnExpired(ctx, received)
+			}
+		} else if err := c.handler(ctx, received); err != nil {
+			if ctx.Err() != nil {
+				// the handler was interrupted, so the message counts as unhandled
+				c.unhandled.Add(int64(len(polled.Messages) - i))
+			}
+			return true, err
+		}
+		c.progress.update(polled.PartitionId, polled.CurrentOffset, message.Header.Offset)
+		if c.opts.OffsetStore != nil {
+			c.positions.set(polled.PartitionId, message.Header.Offset+1)
+		}
+		if c.opts.Commit.mode != commitOnPoll {
+			due := c.commits.record(c.opts.Commit, polled.PartitionId, message.Header.Offset)
+			if due || expired && c.opts.CommitExpired {
+				if err := c.Commit(); err != nil {
+					return true, err
+				}
+			}
+		}
+	}
+	return true, nil
+}
+
+// poll polls a batch from the partition with the strategy. The long polls are interrupted when
+// ctx is done or Close is called, if the client is a messengercli.ContextPoller.
+func (c *Consumer) poll(ctx context.Context, partitionId *uint32, strategy iggcon.PollingStrategy) (*iggcon.PolledMessage, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	defer context.AfterFunc(c.stopped, cancel)()
+	request := iggcon.PollMessageRequest{
+		StreamId:        c.streamId,
+		TopicId:         c.topicId,
+		Consumer:        c.opts.Consumer,
+		PollingStrategy: strategy,
+		Count:           int(c.opts.BatchSize),
+		AutoCommit:      c.opts.Commit.mode == commitOnPoll,
+		MaxWait:         c.opts.MaxWait,
+	}
+	if partitionId != nil {
+		request.PartitionId = *partitionId
+	}
+	polled, err := messengercli.PollMessages(ctx, c.client, request)
+	if err != nil && c.stopped.Err() != nil && errors.Is(err, context.Canceled) {
+		return nil, errStopped
+	}
+	return polled, err
+}
+
+// isExpired reports whether the TTL of the message elapsed.
+func isExpired(message *iggcon.MessengerMessage) bool {
+	expiresAt, ok := iggcon.MessageExpiresAt(message)
+	return ok && !time.Now().Before(expiresAt)
+}
+
+// idle pauses after polls started at the given time returned no messages. Long polls which
+// were held for MaxWait already waited on the server, so the pause only applies when the
+// server answered early without messages, e.g. without support for long polling.
+func (c *Consumer) idle(ctx context.Context, started time.Time) error {
+	if c.opts.MaxWait > 0 && time.Since(started) >= c.opts.MaxWait {
+		return nil
+	}
+	timer := time.NewTimer(c.opts.PollInterval)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-c.stopped.Done():
+		return errStopped
+	case <-timer.C:
+		return nil
+	}
+}
+
+// partitions resolves the partitions to poll; a nil entry lets the server pick the partition.
+func (c *Consumer) partitions() ([]*uint32, error) {
+	ids := c.opts.Partitions
+	if len(ids) == 0 {
+		if c.opts.Consumer.Kind == iggcon.ConsumerKindGroup {
+			return []*uint32{nil}, nil
+		}
+		topic, err := c.client.GetTopic(c.streamId, c.topicId)
+		if err != nil {
+			return nil, err
+		}
+		for id := uint32(1); id <= topic.PartitionsCount; id++ {
+			ids = append(ids, id)
+		}
+	}
+
+	partitions := make([]*uint32, 0, len(ids))
+	for _, id := range ids {
+		partitions = append(partitions, &id)
+	}
+	return partitions, nil
+}
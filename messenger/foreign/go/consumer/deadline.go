@@ -0,0 +1,59 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package consumer
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+)
+
+// HonorDeadline is a middleware honoring the deadline propagated by the producer in the
+// iggcon.DeadlineHeader. Messages whose deadline has passed are skipped, after notifying
+// onExpired when it is not nil, and the others are handled with a context bound to it.
+// A handler failing with context.DeadlineExceeded because of that deadline is skipped as well.
+func HonorDeadline(onExpired func(ctx context.Context, message iggcon.ReceivedMessage)) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, message iggcon.ReceivedMessage) error {
+			deadline, ok := iggcon.MessageDeadline(&message.Message)
+			if !ok {
+				return next(ctx, message)
+			}
+			if !time.Now().Before(deadline) {
+				if onExpired != nil {
+					onExpired(ctx, message)
+				}
+				return nil
+			}
+
+			deadlineCtx, cancel := context.WithDeadline(ctx, deadline)
+			defer cancel()
+			err := next(deadlineCtx, message)
+			if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+				// the upstream deadline passed while handling the message
+				if onExpired != nil {
+					onExpired(ctx, message)
+				}
+				return nil
+			}
+			return err
+		}
+	}
+}
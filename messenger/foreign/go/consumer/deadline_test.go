@@ -0,0 +1,105 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package consumer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+)
+
+func TestHonorDeadline(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []iggcon.MessengerMessageOpt
+		wantHandled bool
+		wantExpired bool
+	}{
+		{name: "no deadline", wantHandled: true},
+		{name: "future deadline", opts: []iggcon.MessengerMessageOpt{iggcon.WithDeadline(time.Now().Add(time.Hour))}, wantHandled: true},
+		{name: "past deadline", opts: []iggcon.MessengerMessageOpt{iggcon.WithDeadline(time.Now().Add(-time.Second))}, wantExpired: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, err := iggcon.NewMessengerMessage([]byte("payload"), tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			handled, expired := false, false
+			handler := HonorDeadline(func(context.Context, iggcon.ReceivedMessage) {
+				expired = true
+			})(func(ctx context.Context, message iggcon.ReceivedMessage) error {
+				handled = true
+				return nil
+			})
+
+			if err := handler(context.Background(), iggcon.ReceivedMessage{Message: message}); err != nil {
+				t.Fatal(err)
+			}
+			if handled != tt.wantHandled || expired != tt.wantExpired {
+				t.Fatalf("expected handled=%v expired=%v, got handled=%v expired=%v", tt.wantHandled, tt.wantExpired, handled, expired)
+			}
+		})
+	}
+}
+
+func TestConsumer_DropExpired(t *testing.T) {
+	var handled, expired []string
+	done := make(chan struct{})
+	client, c, streamId, topicId := newTestConsumer(t, func(ctx context.Context, message iggcon.ReceivedMessage) error {
+		handled = append(handled, string(message.Message.Payload))
+		if len(handled) == 4 {
+			close(done)
+		}
+		return nil
+	}, WithCommitPolicy(CommitManual()), WithDropExpired(true, func(_ context.Context, message iggcon.ReceivedMessage) {
+		expired = append(expired, string(message.Message.Payload))
+	}))
+	stale, _ := iggcon.NewMessengerMessage([]byte("stale"), iggcon.WithExpiresAt(time.Now().Add(-time.Second)))
+	fresh, _ := iggcon.NewMessengerMessage([]byte("fresh"), iggcon.WithTTL(time.Hour))
+	if err := client.SendMessages(streamId, topicId, iggcon.PartitionId(1), []iggcon.MessengerMessage{stale, fresh}); err != nil {
+		t.Fatal(err)
+	}
+
+	ran := make(chan error, 1)
+	go func() {
+		ran <- c.Run(context.Background())
+	}()
+	<-done
+	if err := c.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-ran; err != nil {
+		t.Fatal(err)
+	}
+	if len(expired) != 1 || expired[0] != "stale" || handled[3] != "fresh" {
+		t.Fatalf("expected the stale message to be dropped, handled %v and dropped %v", handled, expired)
+	}
+	partitionId := uint32(1)
+	offset, err := client.GetConsumerOffset(iggcon.DefaultConsumer(), streamId, topicId, &partitionId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if offset == nil || offset.StoredOffset != 3 {
+		t.Fatalf("expected the offset of the dropped message to be stored, got %+v", offset)
+	}
+}
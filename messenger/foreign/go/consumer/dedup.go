@@ -0,0 +1,145 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package consumer
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+)
+
+// DedupStore remembers the IDs of the handled messages for Deduplicate. A durable implementation,
+// e.g. backed by Redis, keeps suppressing the duplicates across restarts and instances.
+type DedupStore interface {
+	// Seen reports whether the message ID was marked.
+	Seen(ctx context.Context, id iggcon.MessageID) (bool, error)
+	// Mark remembers the ID of a handled message.
+	Mark(ctx context.Context, id iggcon.MessageID) error
+}
+
+// Deduplicate is a middleware skipping the messages whose ID is already marked in store, after
+// notifying onDuplicate when it is not nil, so the at-least-once redeliveries, e.g. after a
+// rebalance or a restart before the offsets were stored, do not reach the next handler twice.
+// The IDs are marked once the next handler succeeded, and the messages without an ID are always
+// handled. A failing store stops the consumer.
+func Deduplicate(store DedupStore, onDuplicate func(ctx context.Context, message iggcon.ReceivedMessage)) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, message iggcon.ReceivedMessage) error {
+			id := message.Message.Header.Id
+			if id == (iggcon.MessageID{}) {
+				return next(ctx, message)
+			}
+			seen, err := store.Seen(ctx, id)
+			if err != nil {
+				return err
+			}
+			if seen {
+				if onDuplicate != nil {
+					onDuplicate(ctx, message)
+				}
+				return nil
+			}
+			if err := next(ctx, message); err != nil {
+				return err
+			}
+			return store.Mark(ctx, id)
+		}
+	}
+}
+
+// MemoryDedupStore is a DedupStore keeping the IDs in memory, for a bounded time and number of
+// IDs, the least recently marked ones being evicted first.
+type MemoryDedupStore struct {
+	mtx        sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	// order lists the entries from the least to the most recently marked.
+	order   *list.List
+	entries map[iggcon.MessageID]*list.Element
+	now     func() time.Time
+}
+
+type dedupEntry struct {
+	id       iggcon.MessageID
+	markedAt time.Time
+}
+
+// NewMemoryDedupStore creates a MemoryDedupStore remembering up to maxEntries IDs, 100000 when 0,
+// for ttl, forever when 0.
+func NewMemoryDedupStore(maxEntries int, ttl time.Duration) *MemoryDedupStore {
+	if maxEntries <= 0 {
+		maxEntries = 100_000
+	}
+	return &MemoryDedupStore{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		order:      list.New(),
+		entries:    map[iggcon.MessageID]*list.Element{},
+		now:        time.Now,
+	}
+}
+
+func (s *MemoryDedupStore) Seen(_ context.Context, id iggcon.MessageID) (bool, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	element, ok := s.entries[id]
+	if !ok {
+		return false, nil
+	}
+	if s.expired(element.Value.(dedupEntry), s.now()) {
+		s.order.Remove(element)
+		delete(s.entries, id)
+		return false, nil
+	}
+	return true, nil
+}
+
+func (s *MemoryDedupStore) Mark(_ context.Context, id iggcon.MessageID) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	now := s.now()
+	if element, ok := s.entries[id]; ok {
+		element.Value = dedupEntry{id: id, markedAt: now}
+		s.order.MoveToBack(element)
+		return nil
+	}
+	// the oldest entries are at the front, evict the expired ones and then the least recent
+	for front := s.order.Front(); front != nil; front = s.order.Front() {
+		if len(s.entries) < s.maxEntries && !s.expired(front.Value.(dedupEntry), now) {
+			break
+		}
+		s.order.Remove(front)
+		delete(s.entries, front.Value.(dedupEntry).id)
+	}
+	s.entries[id] = s.order.PushBack(dedupEntry{id: id, markedAt: now})
+	return nil
+}
+
+// Len returns the number of IDs remembered, including the expired ones not evicted yet.
+func (s *MemoryDedupStore) Len() int {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return len(s.entries)
+}
+
+func (s *MemoryDedupStore) expired(entry dedupEntry, now time.Time) bool {
+	return s.ttl > 0 && !now.Before(entry.markedAt.Add(s.ttl))
+}
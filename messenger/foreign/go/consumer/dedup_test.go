@@ -0,0 +1,92 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package consumer
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+)
+
+func TestDeduplicate(t *testing.T) {
+	store := NewMemoryDedupStore(0, 0)
+	var handled, duplicates []string
+	failing := true
+	handler := Deduplicate(store, func(_ context.Context, message iggcon.ReceivedMessage) {
+		duplicates = append(duplicates, string(message.Message.Payload))
+	})(func(_ context.Context, message iggcon.ReceivedMessage) error {
+		payload := string(message.Message.Payload)
+		if payload == "b" && failing {
+			failing = false
+			return errors.New("transient failure")
+		}
+		handled = append(handled, payload)
+		return nil
+	})
+
+	received := func(payload string, id byte) iggcon.ReceivedMessage {
+		message, err := iggcon.NewMessengerMessage([]byte(payload), iggcon.WithID([16]byte{id}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return iggcon.ReceivedMessage{Message: message}
+	}
+	for _, message := range []iggcon.ReceivedMessage{received("a", 1), received("b", 2)} {
+		_ = handler(context.Background(), message)
+	}
+	// redelivered after a rebalance
+	for _, message := range []iggcon.ReceivedMessage{received("a", 1), received("b", 2), received("c", 3)} {
+		if err := handler(context.Background(), message); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !reflect.DeepEqual(handled, []string{"a", "b", "c"}) || !reflect.DeepEqual(duplicates, []string{"a"}) {
+		t.Fatalf("expected a, b and c handled once and a suppressed, got %v and %v", handled, duplicates)
+	}
+}
+
+func TestMemoryDedupStore_EvictsLeastRecentAndExpired(t *testing.T) {
+	now := time.Now()
+	store := NewMemoryDedupStore(2, time.Minute)
+	store.now = func() time.Time { return now }
+	ctx := context.Background()
+	seen := func(id byte) bool {
+		ok, _ := store.Seen(ctx, iggcon.MessageID{id})
+		return ok
+	}
+
+	_ = store.Mark(ctx, iggcon.MessageID{1})
+	_ = store.Mark(ctx, iggcon.MessageID{2})
+	_ = store.Mark(ctx, iggcon.MessageID{1})
+	_ = store.Mark(ctx, iggcon.MessageID{3})
+	if !seen(1) || seen(2) || !seen(3) {
+		t.Fatal("expected the least recently marked ID to be evicted")
+	}
+
+	now = now.Add(time.Minute)
+	if seen(1) || seen(3) {
+		t.Fatal("expected the IDs to expire after the TTL")
+	}
+	if store.Len() != 0 {
+		t.Fatalf("expected the expired IDs to be evicted, %d left", store.Len())
+	}
+}
@@ -0,0 +1,169 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package consumer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+)
+
+// Enricher computes an annotation (a geo lookup, account metadata...) attached to the messages
+// before they reach the handler, which reads it back with Annotation.
+type Enricher struct {
+	// Name identifies the annotation.
+	Name string
+	// Enrich computes the annotation of a message.
+	Enrich func(ctx context.Context, message iggcon.ReceivedMessage) (any, error)
+	// Key returns the key the annotation is cached under, messages sharing a key share the annotation.
+	// A nil Key or an empty key disables the cache.
+	Key func(message iggcon.ReceivedMessage) string
+	// TTL is how long a cached annotation is reused, 0 disables the cache.
+	TTL time.Duration
+	// MaxEntries bounds the number of cached annotations, 10000 when 0.
+	MaxEntries int
+	// Optional makes a failing enricher leave the annotation unset instead of failing the message.
+	Optional bool
+}
+
+type annotationsKey struct{}
+
+// Annotation returns the annotation computed by the enricher with the given name for the
+// message being handled.
+func Annotation(ctx context.Context, name string) (any, bool) {
+	annotations, _ := ctx.Value(annotationsKey{}).(map[string]any)
+	value, ok := annotations[name]
+	return value, ok
+}
+
+// Enrich is a middleware running the enrichers, in order, before the handler and exposing their
+// annotations through the handler context. Annotations are cached locally per enricher.
+func Enrich(enrichers ...Enricher) Middleware {
+	caches := make([]*annotationCache, len(enrichers))
+	for i, enricher := range enrichers {
+		if enricher.Key != nil && enricher.TTL > 0 {
+			caches[i] = newAnnotationCache(enricher.TTL, enricher.MaxEntries)
+		}
+	}
+
+	return func(next Handler) Handler {
+		return func(ctx context.Context, message iggcon.ReceivedMessage) error {
+			annotations := make(map[string]any, len(enrichers))
+			if parent, ok := ctx.Value(annotationsKey{}).(map[string]any); ok {
+				for name, value := range parent {
+					annotations[name] = value
+				}
+			}
+
+			for i, enricher := range enrichers {
+				value, err := enrichWithCache(ctx, enricher, caches[i], message)
+				if err != nil {
+					if enricher.Optional {
+						continue
+					}
+					return fmt.Errorf("enricher %s: %w", enricher.Name, err)
+				}
+				annotations[enricher.Name] = value
+			}
+			return next(context.WithValue(ctx, annotationsKey{}, annotations), message)
+		}
+	}
+}
+
+func enrichWithCache(ctx context.Context, enricher Enricher, cache *annotationCache, message iggcon.ReceivedMessage) (any, error) {
+	if cache == nil {
+		return enricher.Enrich(ctx, message)
+	}
+	key := enricher.Key(message)
+	if key == "" {
+		return enricher.Enrich(ctx, message)
+	}
+	if value, ok := cache.get(key); ok {
+		return value, nil
+	}
+	value, err := enricher.Enrich(ctx, message)
+	if err != nil {
+		return nil, err
+	}
+	cache.set(key, value)
+	return value, nil
+}
+
+type cachedAnnotation struct {
+	value     any
+	expiresAt time.Time
+}
+
+// annotationCache is a TTL cache shared by the partitions consumed concurrently.
+type annotationCache struct {
+	mtx        sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	entries    map[string]cachedAnnotation
+	now        func() time.Time
+}
+
+func newAnnotationCache(ttl time.Duration, maxEntries int) *annotationCache {
+	if maxEntries <= 0 {
+		maxEntries = 10_000
+	}
+	return &annotationCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    make(map[string]cachedAnnotation),
+		now:        time.Now,
+	}
+}
+
+func (c *annotationCache) get(key string) (any, bool) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !c.now().Before(entry.expiresAt) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return entry.value, true
+}
+
+func (c *annotationCache) set(key string, value any) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	now := c.now()
+	if len(c.entries) >= c.maxEntries {
+		for k, entry := range c.entries {
+			if !now.Before(entry.expiresAt) {
+				delete(c.entries, k)
+			}
+		}
+	}
+	if len(c.entries) >= c.maxEntries {
+		// still full, evict an arbitrary entry
+		for k := range c.entries {
+			delete(c.entries, k)
+			break
+		}
+	}
+	c.entries[key] = cachedAnnotation{value: value, expiresAt: now.Add(c.ttl)}
+}
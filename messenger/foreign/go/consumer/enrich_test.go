@@ -0,0 +1,88 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package consumer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+)
+
+func TestEnrich_CachesAnnotations(t *testing.T) {
+	lookups := 0
+	accounts := Enricher{
+		Name: "account",
+		Enrich: func(_ context.Context, message iggcon.ReceivedMessage) (any, error) {
+			lookups++
+			return "account-" + string(message.Message.Payload), nil
+		},
+		Key: func(message iggcon.ReceivedMessage) string { return string(message.Message.Payload) },
+		TTL: time.Minute,
+	}
+	failing := Enricher{
+		Name:     "geo",
+		Enrich:   func(context.Context, iggcon.ReceivedMessage) (any, error) { return nil, errors.New("unavailable") },
+		Optional: true,
+	}
+
+	var annotations []any
+	handler := Enrich(accounts, failing)(func(ctx context.Context, message iggcon.ReceivedMessage) error {
+		account, _ := Annotation(ctx, "account")
+		annotations = append(annotations, account)
+		if _, ok := Annotation(ctx, "geo"); ok {
+			t.Error("a failing optional enricher should leave its annotation unset")
+		}
+		return nil
+	})
+
+	for _, payload := range []string{"42", "42", "7"} {
+		message, _ := iggcon.NewMessengerMessage([]byte(payload))
+		if err := handler(context.Background(), iggcon.ReceivedMessage{Message: message}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if lookups != 2 {
+		t.Errorf("expected 2 lookups, got %d", lookups)
+	}
+	if len(annotations) != 3 || annotations[0] != "account-42" || annotations[1] != "account-42" || annotations[2] != "account-7" {
+		t.Errorf("unexpected annotations %v", annotations)
+	}
+}
+
+func TestAnnotationCache_Expiry(t *testing.T) {
+	now := time.Now()
+	cache := newAnnotationCache(time.Second, 1)
+	cache.now = func() time.Time { return now }
+
+	cache.set("a", 1)
+	if value, ok := cache.get("a"); !ok || value != 1 {
+		t.Fatalf("expected the cached value, got %v %v", value, ok)
+	}
+	now = now.Add(time.Second)
+	if _, ok := cache.get("a"); ok {
+		t.Fatal("expected the value to expire")
+	}
+	cache.set("a", 1)
+	cache.set("b", 2)
+	if _, ok := cache.get("a"); ok || len(cache.entries) != 1 {
+		t.Fatal("expected the cache to stay bounded")
+	}
+}
@@ -0,0 +1,132 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package consumer
+
+import (
+	"context"
+	"log"
+	"runtime"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/health"
+)
+
+// HeartbeatOptions configures the heartbeats a consumer publishes to an ops topic.
+type HeartbeatOptions struct {
+	// StreamId and TopicId identify the ops topic the heartbeats are sent to.
+	StreamId iggcon.Identifier
+	TopicId  iggcon.Identifier
+	// Interval is the pause between two heartbeats.
+	Interval time.Duration
+	// InstanceId identifies the consumer instance in the heartbeats.
+	InstanceId string
+}
+
+// progress tracks the partitions a consumer polled and its lag in each of them.
+type progress struct {
+	mtx sync.Mutex
+	lag map[uint32]uint64
+}
+
+func (p *progress) update(partitionId uint32, currentOffset, offset uint64) {
+	lag := uint64(0)
+	if currentOffset > offset {
+		lag = currentOffset - offset
+	}
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	if p.lag == nil {
+		p.lag = map[uint32]uint64{}
+	}
+	p.lag[partitionId] = lag
+}
+
+func (p *progress) snapshot() ([]uint32, map[uint32]uint64) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	partitions := make([]uint32, 0, len(p.lag))
+	lag := make(map[uint32]uint64, len(p.lag))
+	for partition, l := range p.lag {
+		partitions = append(partitions, partition)
+		lag[partition] = l
+	}
+	sort.Slice(partitions, func(i, j int) bool { return partitions[i] < partitions[j] })
+	return partitions, lag
+}
+
+// heartbeat builds the heartbeat reporting the current state of the consumer.
+func (c *Consumer) heartbeat() health.Heartbeat {
+	var memory runtime.MemStats
+	runtime.ReadMemStats(&memory)
+	partitions, lag := c.progress.snapshot()
+	return health.Heartbeat{
+		InstanceId:  c.opts.Heartbeat.InstanceId,
+		Consumer:    identifierName(c.opts.Consumer.Id),
+		Stream:      identifierName(c.streamId),
+		Topic:       identifierName(c.topicId),
+		Partitions:  partitions,
+		Lag:         lag,
+		Paused:      c.Paused(partitions...),
+		MemoryBytes: memory.HeapAlloc,
+		Timestamp:   time.Now(),
+	}
+}
+
+// reportHeartbeats publishes a heartbeat every interval until ctx is cancelled. Failing to
+// publish a heartbeat is logged without stopping the consumer.
+func (c *Consumer) reportHeartbeats(ctx context.Context) {
+	ticker := time.NewTicker(c.opts.Heartbeat.Interval)
+	defer ticker.Stop()
+	for {
+		if err := c.sendHeartbeat(); err != nil {
+			log.Printf("[WARN] consumer %s: failed to send heartbeat: %v", c.opts.Heartbeat.InstanceId, err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *Consumer) sendHeartbeat() error {
+	message, err := c.heartbeat().Message()
+	if err != nil {
+		return err
+	}
+	return c.client.SendMessages(
+		c.opts.Heartbeat.StreamId,
+		c.opts.Heartbeat.TopicId,
+		iggcon.None(),
+		[]iggcon.MessengerMessage{message},
+	)
+}
+
+func identifierName(id iggcon.Identifier) string {
+	if name, err := id.String(); err == nil {
+		return name
+	}
+	if value, err := id.Uint32(); err == nil {
+		return strconv.FormatUint(uint64(value), 10)
+	}
+	return ""
+}
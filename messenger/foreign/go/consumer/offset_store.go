@@ -0,0 +1,290 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package consumer
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengercli"
+)
+
+// OffsetStore stores the offsets of a consumer, the offset of the last handled message of every
+// partition. The server stores them by default; another store, e.g. a table updated in the same
+// transaction as the state derived from the messages, lets the offsets be committed along with
+// that state.
+type OffsetStore interface {
+	// Load returns the stored offset of the partition, false when none is stored.
+	Load(partitionId uint32) (uint64, bool, error)
+	// Store stores the offsets of the partitions, atomically when the backend allows it.
+	Store(offsets map[uint32]uint64) error
+	// Delete removes the stored offset of the partition, which is then read from its start.
+	Delete(partitionId uint32) error
+}
+
+// serverOffsets stores the offsets as the consumer offsets of the server.
+type serverOffsets struct {
+	client   messengercli.Client
+	consumer iggcon.Consumer
+	streamId iggcon.Identifier
+	topicId  iggcon.Identifier
+}
+
+// ServerOffsets returns the OffsetStore storing the offsets as the consumer offsets of the server,
+// the default store of a consumer, e.g. for the sinks of a connector storing their offsets apart
+// from the data.
+func ServerOffsets(client messengercli.Client, consumer iggcon.Consumer, streamId, topicId iggcon.Identifier) OffsetStore {
+	return serverOffsets{client: client, consumer: consumer, streamId: streamId, topicId: topicId}
+}
+
+func (s serverOffsets) Load(partitionId uint32) (uint64, bool, error) {
+	stored, err := s.client.GetConsumerOffset(s.consumer, s.streamId, s.topicId, &partitionId)
+	if err != nil || stored == nil {
+		return 0, false, err
+	}
+	return stored.StoredOffset, true, nil
+}
+
+func (s serverOffsets) Store(offsets map[uint32]uint64) error {
+	var errs []error
+	for partitionId, offset := range offsets {
+		partition := partitionId
+		if err := s.client.StoreConsumerOffset(s.consumer, s.streamId, s.topicId, offset, &partition); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s serverOffsets) Delete(partitionId uint32) error {
+	return s.client.DeleteConsumerOffset(s.consumer, s.streamId, s.topicId, &partitionId)
+}
+
+// FileOffsetStore stores the offsets in a JSON file, replaced atomically on every Store. It suits
+// a single consumer process owning the file. It is a CheckpointStore, keeping the state of a
+// Checkpointer in the same file.
+type FileOffsetStore struct {
+	path string
+
+	mtx      sync.Mutex
+	contents *offsetsFile
+}
+
+// offsetsFile is the contents of the file of a FileOffsetStore.
+type offsetsFile struct {
+	// Offsets are keyed by partition ID.
+	Offsets map[string]uint64 `json:"offsets"`
+	State   []byte            `json:"state,omitempty"`
+}
+
+// NewFileOffsetStore creates a FileOffsetStore keeping the offsets in the file at path, created on
+// the first Store.
+func NewFileOffsetStore(path string) *FileOffsetStore {
+	return &FileOffsetStore{path: path}
+}
+
+func (s *FileOffsetStore) Load(partitionId uint32) (uint64, bool, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if err := s.read(); err != nil {
+		return 0, false, err
+	}
+	offset, ok := s.contents.Offsets[partitionKey(partitionId)]
+	return offset, ok, nil
+}
+
+func (s *FileOffsetStore) Store(offsets map[uint32]uint64) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if err := s.read(); err != nil {
+		return err
+	}
+	updated := s.contents.clone()
+	for partitionId, offset := range offsets {
+		updated.Offsets[partitionKey(partitionId)] = offset
+	}
+	return s.write(updated)
+}
+
+func (s *FileOffsetStore) Delete(partitionId uint32) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if err := s.read(); err != nil {
+		return err
+	}
+	if _, ok := s.contents.Offsets[partitionKey(partitionId)]; !ok {
+		return nil
+	}
+	updated := s.contents.clone()
+	delete(updated.Offsets, partitionKey(partitionId))
+	return s.write(updated)
+}
+
+func (s *FileOffsetStore) LoadCheckpoint() (Checkpoint, bool, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if err := s.read(); err != nil {
+		return Checkpoint{}, false, err
+	}
+	if len(s.contents.Offsets) == 0 && s.contents.State == nil {
+		return Checkpoint{}, false, nil
+	}
+	checkpoint := Checkpoint{Offsets: make(map[uint32]uint64, len(s.contents.Offsets)), State: s.contents.State}
+	for key, offset := range s.contents.Offsets {
+		partitionId, err := strconv.ParseUint(key, 10, 32)
+		if err != nil {
+			return Checkpoint{}, false, err
+		}
+		checkpoint.Offsets[uint32(partitionId)] = offset
+	}
+	return checkpoint, true, nil
+}
+
+func (s *FileOffsetStore) StoreCheckpoint(checkpoint Checkpoint) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	contents := &offsetsFile{Offsets: make(map[string]uint64, len(checkpoint.Offsets)), State: checkpoint.State}
+	for partitionId, offset := range checkpoint.Offsets {
+		contents.Offsets[partitionKey(partitionId)] = offset
+	}
+	return s.write(contents)
+}
+
+// read loads the contents of the file once. Must hold s.mtx.
+func (s *FileOffsetStore) read() error {
+	if s.contents != nil {
+		return nil
+	}
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		s.contents = &offsetsFile{Offsets: map[string]uint64{}}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	contents := &offsetsFile{}
+	if err := json.Unmarshal(data, contents); err != nil {
+		return err
+	}
+	if contents.Offsets == nil {
+		contents.Offsets = map[string]uint64{}
+	}
+	s.contents = contents
+	return nil
+}
+
+// write replaces the file with the contents, through a temporary file so a crash never leaves a
+// partial file. Must hold s.mtx.
+func (s *FileOffsetStore) write(contents *offsetsFile) error {
+	data, err := json.Marshal(contents)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return err
+	}
+	s.contents = contents
+	return nil
+}
+
+func (f *offsetsFile) clone() *offsetsFile {
+	cloned := &offsetsFile{Offsets: make(map[string]uint64, len(f.Offsets)), State: f.State}
+	for key, offset := range f.Offsets {
+		cloned.Offsets[key] = offset
+	}
+	return cloned
+}
+
+func partitionKey(partitionId uint32) string {
+	return strconv.FormatUint(uint64(partitionId), 10)
+}
+
+// positions tracks the offset of the next message to poll from every partition, when the offsets
+// are stored outside the server.
+type positions struct {
+	mtx  sync.Mutex
+	next map[uint32]uint64
+}
+
+func (p *positions) get(partitionId uint32) uint64 {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	return p.next[partitionId]
+}
+
+func (p *positions) set(partitionId uint32, offset uint64) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	if p.next == nil {
+		p.next = map[uint32]uint64{}
+	}
+	p.next[partitionId] = offset
+}
+
+// loadPositions reads the offsets of the partitions from the OffsetStore set with
+// WithOffsetStore, if any, to poll them from there.
+func (c *Consumer) loadPositions(partitions []*uint32) error {
+	if c.opts.OffsetStore == nil {
+		return nil
+	}
+	for _, partition := range partitions {
+		if partition == nil {
+			return errors.New("consumer: an offset store requires the partitions of a group consumer")
+		}
+		offset, ok, err := c.offsets.Load(*partition)
+		if err != nil {
+			return err
+		}
+		next := uint64(0)
+		if ok {
+			next = offset + 1
+		}
+		c.positions.set(*partition, next)
+	}
+	return nil
+}
+
+// pollingStrategy returns the strategy polling the next messages of the partition.
+func (c *Consumer) pollingStrategy(partitionId *uint32) iggcon.PollingStrategy {
+	if c.opts.OffsetStore == nil || partitionId == nil {
+		return iggcon.NextPollingStrategy()
+	}
+	return iggcon.OffsetPollingStrategy(c.positions.get(*partitionId))
+}
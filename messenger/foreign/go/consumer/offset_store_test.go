@@ -0,0 +1,78 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package consumer
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+)
+
+func TestConsumer_OffsetStore(t *testing.T) {
+	store := NewFileOffsetStore(filepath.Join(t.TempDir(), "offsets.json"))
+	var handled []string
+	ctx, cancel := context.WithCancel(context.Background())
+	client, c, streamId, topicId := newTestConsumer(t, func(_ context.Context, message iggcon.ReceivedMessage) error {
+		handled = append(handled, string(message.Message.Payload))
+		if len(handled) == 3 {
+			cancel()
+		}
+		return nil
+	}, WithAutoCommit(false), WithOffsetStore(store))
+	if err := c.Run(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	partitionId := uint32(1)
+	if stored, err := client.GetConsumerOffset(iggcon.DefaultConsumer(), streamId, topicId, &partitionId); err != nil || stored != nil {
+		t.Fatalf("expected no offset stored by the server, got %v, %v", stored, err)
+	}
+	// a new store reads the offsets back from the file
+	store = NewFileOffsetStore(store.path)
+	if offset, ok, err := store.Load(partitionId); err != nil || !ok || offset != 2 {
+		t.Fatalf("expected offset 2 in the file, got %d, %v, %v", offset, ok, err)
+	}
+
+	d, _ := iggcon.NewMessengerMessage([]byte("d"))
+	if err := client.SendMessages(streamId, topicId, iggcon.PartitionId(partitionId), []iggcon.MessengerMessage{d}); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel = context.WithCancel(context.Background())
+	handled = nil
+	c, err := NewConsumer(client, streamId, topicId, func(_ context.Context, message iggcon.ReceivedMessage) error {
+		handled = append(handled, string(message.Message.Payload))
+		cancel()
+		return nil
+	}, WithAutoCommit(false), WithOffsetStore(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Run(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(handled, []string{"d"}) {
+		t.Fatalf("expected to resume from the stored offset, got %v", handled)
+	}
+
+	if _, err := NewConsumer(client, streamId, topicId, c.handler, WithOffsetStore(store)); err == nil {
+		t.Fatal("expected an offset store to require a commit after the handler")
+	}
+}
@@ -0,0 +1,247 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package consumer
+
+import (
+	"context"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+)
+
+type Option func(opts *Options)
+
+type Options struct {
+	// Consumer identifies the consumer (single or group) offsets are stored for.
+	Consumer iggcon.Consumer
+	// Partitions restricts consumption to the given partitions. When empty, a consumer group
+	// lets the server assign partitions, and a single consumer reads all partitions of the topic.
+	Partitions []uint32
+	// BatchSize is the number of messages requested by a single poll.
+	BatchSize uint32
+	// PollInterval is the pause between polls returning no messages.
+	PollInterval time.Duration
+	// MaxWait, when positive, makes the server hold the polls until messages are available or
+	// MaxWait elapsed, replacing the pause between polls.
+	MaxWait time.Duration
+	// Delivery orders storing the offsets relative to handling the messages, it must match
+	// the Commit policy.
+	Delivery DeliverySemantics
+	// Commit decides when the offsets of the polled messages are stored.
+	Commit CommitPolicy
+	// StructuredConcurrency runs each partition in its own goroutine scoped to Run.
+	StructuredConcurrency bool
+	// Middlewares wrap the handler, the first one being the outermost.
+	Middlewares []Middleware
+	// GroupMembership makes Run join the consumer group before polling and leave it on return.
+	GroupMembership bool
+	// Heartbeat, when set, makes the consumer publish its state to an ops topic while running.
+	Heartbeat *HeartbeatOptions
+	// DropExpired skips the messages whose TTL, set with iggcon.WithTTL, elapsed.
+	DropExpired bool
+	// CommitExpired stores the offset past the dropped messages right away, whatever the Commit
+	// policy.
+	CommitExpired bool
+	// OnExpired, when set, is called with the dropped messages.
+	OnExpired func(ctx context.Context, message iggcon.ReceivedMessage)
+	// OffsetReset decides where the consumer resumes the partitions whose stored offset is out of
+	// range.
+	OffsetReset OffsetResetPolicy
+	// OnOffsetReset, when set, is called with every reset of the offset of a partition.
+	OnOffsetReset func(reset OffsetReset)
+	// OffsetStore, when set, stores the offsets instead of the server.
+	OffsetStore OffsetStore
+	// Checkpointer, when set, checkpoints the state of the handler along with the offsets.
+	Checkpointer *Checkpointer
+}
+
+func GetDefaultOptions() Options {
+	return Options{
+		Consumer:     iggcon.DefaultConsumer(),
+		BatchSize:    100,
+		PollInterval: 100 * time.Millisecond,
+		Delivery:     AtMostOnce,
+		Commit:       CommitOnPoll(),
+	}
+}
+
+// WithConsumer sets the consumer (single or group) used to poll and store offsets.
+func WithConsumer(consumer iggcon.Consumer) Option {
+	return func(opts *Options) {
+		opts.Consumer = consumer
+	}
+}
+
+// WithPartitions restricts consumption to the given partitions.
+func WithPartitions(partitions ...uint32) Option {
+	return func(opts *Options) {
+		opts.Partitions = partitions
+	}
+}
+
+// WithBatchSize sets the number of messages requested by a single poll.
+func WithBatchSize(size uint32) Option {
+	return func(opts *Options) {
+		opts.BatchSize = size
+	}
+}
+
+// WithPollInterval sets the pause between polls returning no messages.
+func WithPollInterval(interval time.Duration) Option {
+	return func(opts *Options) {
+		opts.PollInterval = interval
+	}
+}
+
+// WithLongPolling lets the server hold every poll for up to maxWait until messages are available,
+// so the consumer neither busy-loops on nor lags behind the empty partitions. The consumer
+// falls back to the poll interval against servers without long polling. Without a consumer
+// group, the partitions are polled one after the other, so long polling is best combined with
+// WithStructuredConcurrency. Close and the cancellation of the context of Run interrupt the long
+// polls of the clients implementing messengercli.ContextPoller.
+func WithLongPolling(maxWait time.Duration) Option {
+	return func(opts *Options) {
+		opts.MaxWait = maxWait
+	}
+}
+
+// WithAutoCommit sets whether the server stores the offset when the messages are polled,
+// otherwise the offset is stored after the handler processed each message. It is a shorthand
+// for WithCommitPolicy with CommitOnPoll or CommitAfterHandler.
+func WithAutoCommit(autoCommit bool) Option {
+	return func(opts *Options) {
+		if autoCommit {
+			WithCommitPolicy(CommitOnPoll())(opts)
+		} else {
+			WithCommitPolicy(CommitAfterHandler())(opts)
+		}
+	}
+}
+
+// WithCommitPolicy sets when the offsets of the polled messages are stored, and the delivery
+// semantics it provides: at-most-once for CommitOnPoll, at-least-once for the others.
+func WithCommitPolicy(policy CommitPolicy) Option {
+	return func(opts *Options) {
+		opts.Commit = policy
+		opts.Delivery = policy.delivery()
+	}
+}
+
+// WithDeliverySemantics sets whether the offsets are stored before (AtMostOnce) or after
+// (AtLeastOnce) handling the messages. A commit policy not providing the semantics is replaced,
+// by CommitOnPoll for AtMostOnce and by CommitAfterHandler for AtLeastOnce.
+func WithDeliverySemantics(delivery DeliverySemantics) Option {
+	return func(opts *Options) {
+		opts.Delivery = delivery
+		if opts.Commit.delivery() == delivery {
+			return
+		}
+		switch delivery {
+		case AtMostOnce:
+			opts.Commit = CommitOnPoll()
+		case AtLeastOnce:
+			opts.Commit = CommitAfterHandler()
+		}
+	}
+}
+
+// WithStructuredConcurrency processes every partition in its own goroutine. All goroutines
+// belong to a scope tied to the context passed to Run: the first failing partition cancels
+// the others, and Run returns only once every goroutine has exited, with that first error.
+func WithStructuredConcurrency() Option {
+	return func(opts *Options) {
+		opts.StructuredConcurrency = true
+	}
+}
+
+// WithGroupMembership makes Run join the consumer group before polling and leave it on
+// return, which requires a group consumer.
+func WithGroupMembership() Option {
+	return func(opts *Options) {
+		opts.GroupMembership = true
+	}
+}
+
+// WithMiddleware appends middlewares wrapping the handler, the first one being the outermost.
+func WithMiddleware(middlewares ...Middleware) Option {
+	return func(opts *Options) {
+		opts.Middlewares = append(opts.Middlewares, middlewares...)
+	}
+}
+
+// WithHeartbeat publishes, every interval while Run is running, a heartbeat reporting the
+// instance ID, the consumed partitions, the lag and the memory usage to the given ops topic.
+// The heartbeats are read back with health.Decode, e.g. by a health.Aggregator.
+func WithHeartbeat(streamId, topicId iggcon.Identifier, interval time.Duration, instanceId string) Option {
+	return func(opts *Options) {
+		opts.Heartbeat = &HeartbeatOptions{
+			StreamId:   streamId,
+			TopicId:    topicId,
+			Interval:   interval,
+			InstanceId: instanceId,
+		}
+	}
+}
+
+// WithDropExpired skips the messages whose TTL, set by the producer with iggcon.WithTTL, elapsed
+// before they were polled, so stale commands are never processed. The dropped messages are passed
+// to onExpired, when not nil, instead of the handler and its middlewares, and count as handled for
+// the Commit policy. With commit, the offset past a dropped message is stored right away, along
+// with the ones of the messages handled before it, so an outage does not redeliver the expired
+// messages again.
+func WithDropExpired(commit bool, onExpired func(ctx context.Context, message iggcon.ReceivedMessage)) Option {
+	return func(opts *Options) {
+		opts.DropExpired = true
+		opts.CommitExpired = commit
+		opts.OnExpired = onExpired
+	}
+}
+
+// WithOffsetReset sets where the consumer resumes the partitions whose stored offset is out of
+// their range: past their end when Run starts, e.g. once they were purged, or rejected by the
+// server while polling. onReset, when not nil, is called with every reset. A group consumer
+// polling the partitions assigned by the server, without WithPartitions, cannot tell the partition
+// of an offset rejected by the server, so it stops with the error of the server.
+func WithOffsetReset(policy OffsetResetPolicy, onReset func(reset OffsetReset)) Option {
+	return func(opts *Options) {
+		opts.OffsetReset = policy
+		opts.OnOffsetReset = onReset
+	}
+}
+
+// WithOffsetStore stores the offsets in store instead of the server, e.g. along with the state
+// derived from the messages. The consumer then polls the partitions from the offsets loaded when
+// Run starts, which requires a commit policy storing the offsets after the handler, and the
+// partitions of a group consumer, set with WithPartitions.
+func WithOffsetStore(store OffsetStore) Option {
+	return func(opts *Options) {
+		opts.OffsetStore = store
+	}
+}
+
+// WithCheckpointer checkpoints the state of a stateful handler along with the offsets with
+// checkpointer, which becomes the OffsetStore of the consumer: every commit stores a checkpoint,
+// and Run resumes from the last one. The state is never snapshotted while the handler runs, the
+// checkpointer wrapping the handler inside the middlewares, so the handler must not call Commit.
+// Not supported by a WorkerPoolConsumer.
+func WithCheckpointer(checkpointer *Checkpointer) Option {
+	return func(opts *Options) {
+		opts.Checkpointer = checkpointer
+		opts.OffsetStore = checkpointer
+	}
+}
@@ -0,0 +1,108 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package consumer
+
+import (
+	"sort"
+	"sync"
+)
+
+// paused tracks the partitions the consumer stopped polling.
+type paused struct {
+	mtx sync.Mutex
+	// all pauses every partition but the resumed ones.
+	all        bool
+	partitions map[uint32]bool
+	resumed    map[uint32]bool
+}
+
+// Pause stops polling the given partitions, or every partition when none is given, without
+// leaving the consumer group, e.g. while a downstream system is unavailable. The messages already
+// polled are still handled. A group consumer polling the partitions assigned by the server, without
+// WithPartitions, can only be paused as a whole. The paused partitions are reported in the
+// heartbeats.
+func (c *Consumer) Pause(partitions ...uint32) {
+	c.paused.mtx.Lock()
+	defer c.paused.mtx.Unlock()
+	if len(partitions) == 0 {
+		c.paused.all = true
+		c.paused.resumed = nil
+		return
+	}
+	if c.paused.partitions == nil {
+		c.paused.partitions = map[uint32]bool{}
+	}
+	for _, partition := range partitions {
+		c.paused.partitions[partition] = true
+		delete(c.paused.resumed, partition)
+	}
+}
+
+// Resume resumes polling the given partitions, or every partition when none is given, from the
+// offset the consumer stopped at.
+func (c *Consumer) Resume(partitions ...uint32) {
+	c.paused.mtx.Lock()
+	defer c.paused.mtx.Unlock()
+	if len(partitions) == 0 {
+		c.paused.all = false
+		c.paused.partitions = nil
+		c.paused.resumed = nil
+		return
+	}
+	for _, partition := range partitions {
+		delete(c.paused.partitions, partition)
+		if c.paused.all {
+			if c.paused.resumed == nil {
+				c.paused.resumed = map[uint32]bool{}
+			}
+			c.paused.resumed[partition] = true
+		}
+	}
+}
+
+// Paused returns the paused partitions among the given ones, or among the partitions the
+// consumer polled since it started when none is given.
+func (c *Consumer) Paused(partitions ...uint32) []uint32 {
+	if len(partitions) == 0 {
+		partitions, _ = c.progress.snapshot()
+	}
+	c.paused.mtx.Lock()
+	defer c.paused.mtx.Unlock()
+	var paused []uint32
+	for _, partition := range partitions {
+		if c.paused.isPausedLocked(&partition) {
+			paused = append(paused, partition)
+		}
+	}
+	sort.Slice(paused, func(i, j int) bool { return paused[i] < paused[j] })
+	return paused
+}
+
+// isPaused reports whether the partition, nil for the partitions assigned by the server, is paused.
+func (p *paused) isPaused(partitionId *uint32) bool {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	return p.isPausedLocked(partitionId)
+}
+
+func (p *paused) isPausedLocked(partitionId *uint32) bool {
+	if partitionId == nil {
+		return p.all
+	}
+	return p.all && !p.resumed[*partitionId] || p.partitions[*partitionId]
+}
@@ -0,0 +1,105 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package consumer
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengertest"
+)
+
+func TestConsumer_PauseResume(t *testing.T) {
+	client := messengertest.NewClient()
+	if _, err := client.CreateStream("orders", nil); err != nil {
+		t.Fatal(err)
+	}
+	streamId, topicId := iggcon.MustIdentifier("orders"), iggcon.MustIdentifier("created")
+	if _, err := client.CreateTopic(streamId, "created", 2, iggcon.CompressionAlgorithmNone, 0, 0, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	for partition := uint32(1); partition <= 2; partition++ {
+		message, err := iggcon.NewMessengerMessage([]byte("order"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = client.SendMessages(streamId, topicId, iggcon.PartitionId(partition), []iggcon.MessengerMessage{message}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var mtx sync.Mutex
+	var handled []uint32
+	handledPartitions := func() []uint32 {
+		mtx.Lock()
+		defer mtx.Unlock()
+		return slices.Clone(handled)
+	}
+	c, err := NewConsumer(client, streamId, topicId, func(ctx context.Context, message iggcon.ReceivedMessage) error {
+		mtx.Lock()
+		defer mtx.Unlock()
+		handled = append(handled, message.PartitionId)
+		return nil
+	}, WithPollInterval(5*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Pause(2)
+	if paused := c.Paused(1, 2); !slices.Equal(paused, []uint32{2}) {
+		t.Fatalf("expected the partition 2 to be paused, got %v", paused)
+	}
+	ran := make(chan error, 1)
+	go func() {
+		ran <- c.Run(context.Background())
+	}()
+	waitHandled := func(want []uint32) {
+		deadline := time.Now().Add(5 * time.Second)
+		for !slices.Equal(handledPartitions(), want) {
+			if time.Now().After(deadline) {
+				t.Fatalf("expected the partitions %v to be handled, got %v", want, handledPartitions())
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+	waitHandled([]uint32{1})
+	time.Sleep(20 * time.Millisecond)
+	waitHandled([]uint32{1})
+
+	c.Resume(2)
+	waitHandled([]uint32{1, 2})
+	if err = c.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err = <-ran; err != nil {
+		t.Fatal(err)
+	}
+
+	c.Pause()
+	c.Resume(1)
+	if paused := c.Paused(1, 2); !slices.Equal(paused, []uint32{2}) {
+		t.Fatalf("expected every partition but the resumed one to be paused, got %v", paused)
+	}
+	c.Resume()
+	if paused := c.Paused(1, 2); len(paused) != 0 {
+		t.Fatalf("expected no partition to be paused, got %v", paused)
+	}
+}
@@ -0,0 +1,153 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package consumer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengercli"
+)
+
+// Headers set on the messages quarantined to a dead letter topic.
+const (
+	// PoisonFailuresHeader is the number of times the message failed to be handled.
+	PoisonFailuresHeader = "poison-failures"
+	// PoisonErrorHeader is the error the last handling attempt failed with.
+	PoisonErrorHeader = "poison-error"
+	// PoisonPartitionHeader is the partition the message was consumed from.
+	PoisonPartitionHeader = "poison-partition"
+	// PoisonOffsetHeader is the offset the message was consumed at.
+	PoisonOffsetHeader = "poison-offset"
+)
+
+// maxTrackedFailures bounds the number of messages whose failures are counted.
+const maxTrackedFailures = 10_000
+
+// QuarantineHandler takes a poison message out of the way, e.g. to a dead letter topic. cause is
+// the error of the last failure and failures the number of times the message failed.
+type QuarantineHandler func(ctx context.Context, message iggcon.ReceivedMessage, failures int, cause error) error
+
+// QuarantinePoison is a middleware counting the failures of the next handler per message ID,
+// across the redeliveries of the message after the consumer stopped with its error and was run
+// again. Once a message failed maxFailures times, it is passed to quarantine and counts as handled,
+// so a poison message does not wedge its partition. Only the failure to quarantine a message
+// stops the consumer then. The counts are kept in memory, for the messages without an ID by
+// partition and offset.
+func QuarantinePoison(maxFailures int, quarantine QuarantineHandler) Middleware {
+	failures := &failureCounts{counts: map[poisonKey]int{}}
+	return func(next Handler) Handler {
+		return func(ctx context.Context, message iggcon.ReceivedMessage) error {
+			err := next(ctx, message)
+			key := poisonKeyOf(message)
+			if err == nil {
+				failures.reset(key)
+				return nil
+			}
+			if ctx.Err() != nil {
+				// the handler was interrupted rather than failing
+				return err
+			}
+			count := failures.increment(key)
+			if count < maxFailures {
+				return err
+			}
+			if quarantineErr := quarantine(ctx, message, count, err); quarantineErr != nil {
+				return fmt.Errorf("consumer: failed to quarantine message at offset %d: %w", message.Message.Header.Offset, quarantineErr)
+			}
+			failures.reset(key)
+			return nil
+		}
+	}
+}
+
+// DeadLetter quarantines the poison messages to a dead letter topic, along with the headers
+// describing their failures. The message ID is kept, while the partitioning is not.
+func DeadLetter(client messengercli.DataClient, streamId, topicId iggcon.Identifier) QuarantineHandler {
+	return func(ctx context.Context, received iggcon.ReceivedMessage, failures int, cause error) error {
+		message := received.Message
+		quarantined, err := iggcon.NewMessengerMessage(message.Payload, iggcon.WithID(message.Header.Id))
+		if err != nil {
+			return err
+		}
+		headers := map[iggcon.HeaderKey]iggcon.HeaderValue{}
+		if len(message.UserHeaders) > 0 {
+			if headers, err = iggcon.DeserializeHeaders(message.UserHeaders); err != nil {
+				return err
+			}
+		}
+		headers[iggcon.HeaderKey{Value: PoisonFailuresHeader}] = iggcon.NewUint64HeaderValue(uint64(failures))
+		headers[iggcon.HeaderKey{Value: PoisonPartitionHeader}] = iggcon.NewUint64HeaderValue(uint64(received.PartitionId))
+		headers[iggcon.HeaderKey{Value: PoisonOffsetHeader}] = iggcon.NewUint64HeaderValue(message.Header.Offset)
+		if cause != nil {
+			reason := cause.Error()
+			if len(reason) > 255 {
+				reason = reason[:255]
+			}
+			headers[iggcon.HeaderKey{Value: PoisonErrorHeader}] = iggcon.NewStringHeaderValue(reason)
+		}
+		if err := quarantined.SetUserHeaders(headers); err != nil {
+			return err
+		}
+		return client.SendMessages(streamId, topicId, iggcon.None(), []iggcon.MessengerMessage{quarantined})
+	}
+}
+
+// poisonKey identifies a message by ID, or by partition and offset when it has none.
+type poisonKey struct {
+	id        iggcon.MessageID
+	partition uint32
+	offset    uint64
+}
+
+func poisonKeyOf(message iggcon.ReceivedMessage) poisonKey {
+	if message.Message.Header.Id != (iggcon.MessageID{}) {
+		return poisonKey{id: message.Message.Header.Id}
+	}
+	return poisonKey{partition: message.PartitionId, offset: message.Message.Header.Offset}
+}
+
+// failureCounts counts the failures per message, shared by the partitions consumed concurrently.
+type failureCounts struct {
+	mtx    sync.Mutex
+	counts map[poisonKey]int
+}
+
+func (f *failureCounts) increment(key poisonKey) int {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	count, ok := f.counts[key]
+	if !ok && len(f.counts) >= maxTrackedFailures {
+		// full, evict an arbitrary entry
+		for k := range f.counts {
+			delete(f.counts, k)
+			break
+		}
+	}
+	count++
+	f.counts[key] = count
+	return count
+}
+
+func (f *failureCounts) reset(key poisonKey) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	delete(f.counts, key)
+}
@@ -0,0 +1,88 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package consumer
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengertest"
+)
+
+func TestQuarantinePoison(t *testing.T) {
+	errPoison := errors.New("cannot parse b")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var handled []string
+	// the client is created along with the consumer, so the dead letter handler is bound lazily
+	var client *messengertest.Client
+	deadLetter := func(ctx context.Context, message iggcon.ReceivedMessage, failures int, cause error) error {
+		streamId, _ := iggcon.NewIdentifier("orders")
+		topicId, _ := iggcon.NewIdentifier("dead")
+		return DeadLetter(client, streamId, topicId)(ctx, message, failures, cause)
+	}
+	client, c, streamId, _ := newTestConsumer(t, func(ctx context.Context, message iggcon.ReceivedMessage) error {
+		payload := string(message.Message.Payload)
+		handled = append(handled, payload)
+		switch payload {
+		case "b":
+			return errPoison
+		case "c":
+			cancel()
+		}
+		return nil
+	}, WithAutoCommit(false), WithMiddleware(QuarantinePoison(3, deadLetter)))
+	deadTopicId, _ := iggcon.NewIdentifier("dead")
+	if _, err := client.CreateTopic(streamId, "dead", 1, iggcon.CompressionAlgorithmNone, 0, 0, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := c.Run(ctx); !errors.Is(err, errPoison) {
+			t.Fatalf("expected run %d to stop on the poison message, got %v", i, err)
+		}
+	}
+	if err := c.Run(ctx); err != nil {
+		t.Fatalf("expected the poison message to be quarantined, got %v", err)
+	}
+	if want := []string{"a", "b", "b", "b", "c"}; !reflect.DeepEqual(handled, want) {
+		t.Fatalf("expected %v handled, got %v", want, handled)
+	}
+
+	partitionId := uint32(1)
+	polled, err := client.PollMessages(streamId, deadTopicId, iggcon.DefaultConsumer(), iggcon.FirstPollingStrategy(), 10, false, &partitionId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(polled.Messages) != 1 || string(polled.Messages[0].Payload) != "b" {
+		t.Fatalf("expected the poison message in the dead letter topic, got %v", polled.Messages)
+	}
+	quarantined := polled.Messages[0]
+	failures, _ := quarantined.UserHeader(PoisonFailuresHeader)
+	if count, _ := failures.AsUint64(); count != 3 {
+		t.Fatalf("expected 3 failures in the headers, got %d", count)
+	}
+	reason, _ := quarantined.UserHeader(PoisonErrorHeader)
+	if s, _ := reason.AsString(); s != errPoison.Error() {
+		t.Fatalf("expected the cause in the headers, got %q", s)
+	}
+}
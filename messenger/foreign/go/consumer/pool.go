@@ -0,0 +1,286 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package consumer
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengercli"
+)
+
+// WorkerPoolConsumer polls a topic like a Consumer and handles the messages concurrently with a
+// pool of workers. The messages sharing a key, set with iggcon.WithKey, are dispatched to the
+// same worker, so they are handled in order, while the messages without a key are spread over
+// the workers in turn. The stored offset of a partition only advances past the messages which
+// were all handled, so a crash never skips a message still being handled by a slower worker. The
+// offsets are stored by the polling loop, between polls, when the Commit policy requires it.
+type WorkerPoolConsumer struct {
+	*Consumer
+	workers int
+}
+
+// NewWorkerPoolConsumer creates a WorkerPoolConsumer handling the messages of the given stream
+// and topic with the given number of workers. The options are the ones of a Consumer, but for
+// StructuredConcurrency, the partitions being polled one after the other. A group consumer
+// requires the partitions to consume, set with WithPartitions.
+func NewWorkerPoolConsumer(
+	client messengercli.Client,
+	streamId iggcon.Identifier,
+	topicId iggcon.Identifier,
+	handler Handler,
+	workers int,
+	options ...Option,
+) (*WorkerPoolConsumer, error) {
+	if workers <= 0 {
+		return nil, errors.New("consumer: worker count must be greater than zero")
+	}
+	c, err := NewConsumer(client, streamId, topicId, handler, options...)
+	if err != nil {
+		return nil, err
+	}
+	if c.opts.Checkpointer != nil {
+		return nil, errors.New("consumer: a worker pool cannot checkpoint the state of its handler")
+	}
+	if c.opts.Consumer.Kind == iggcon.ConsumerKindGroup && len(c.opts.Partitions) == 0 {
+		return nil, errors.New("consumer: a worker pool with a group consumer requires the partitions")
+	}
+	return &WorkerPoolConsumer{Consumer: c, workers: workers}, nil
+}
+
+// dispatched is a polled message handed to a worker.
+type dispatched struct {
+	message  iggcon.ReceivedMessage
+	complete func()
+}
+
+// Run polls the messages and dispatches them to the workers until ctx is cancelled, Close is
+// called or an error occurs. Once polling stopped, it waits for the workers to handle the
+// messages already dispatched, unless ctx is cancelled. It returns nil when stopped through ctx
+// or Close, otherwise the first poll, commit, handler or group membership error.
+func (p *WorkerPoolConsumer) Run(ctx context.Context) error {
+	return p.run(ctx, func(ctx context.Context, partitions []*uint32) error {
+		s, scopeCtx := newScope(ctx)
+		queues := make([]chan dispatched, p.workers)
+		for i := range queues {
+			queue := make(chan dispatched, p.opts.BatchSize)
+			queues[i] = queue
+			s.goFunc(func() error {
+				return p.work(scopeCtx, queue)
+			})
+		}
+		err := p.dispatch(scopeCtx, partitions, queues)
+		if !errors.Is(err, errStopped) {
+			// the messages left in the queues are only handled when Close drains the consumer
+			s.cancel()
+		}
+		for _, queue := range queues {
+			close(queue)
+		}
+		if workerErr := s.wait(); workerErr != nil {
+			return workerErr
+		}
+		// the offsets of the messages handled while draining are stored like the others
+		return errors.Join(err, p.commitIfDue())
+	})
+}
+
+// work handles the messages of a queue until it is closed, only counting them as unhandled once
+// ctx is done.
+func (p *WorkerPoolConsumer) work(ctx context.Context, queue <-chan dispatched) error {
+	for job := range queue {
+		if ctx.Err() != nil {
+			p.unhandled.Add(1)
+			continue
+		}
+		if err := p.handler(ctx, job.message); err != nil {
+			if ctx.Err() != nil {
+				p.unhandled.Add(1)
+			}
+			return err
+		}
+		job.complete()
+	}
+	return nil
+}
+
+// dispatch polls the partitions in turn and dispatches the messages until polling stops.
+func (p *WorkerPoolConsumer) dispatch(ctx context.Context, partitions []*uint32, queues []chan dispatched) error {
+	offsets := make(map[uint32]uint64, len(partitions))
+	for _, partition := range partitions {
+		offset, ok, err := p.offsets.Load(*partition)
+		if err != nil {
+			return err
+		}
+		if ok {
+			offsets[*partition] = offset + 1
+		}
+	}
+	var tracker inflight
+	next := 0
+	for {
+		started := time.Now()
+		polledAny := false
+		for _, partition := range partitions {
+			if err := p.checkStopped(ctx); err != nil {
+				return err
+			}
+			if err := p.commitIfDue(); err != nil {
+				return err
+			}
+			if p.paused.isPaused(partition) {
+				continue
+			}
+			polled, err := p.poll(ctx, partition, iggcon.OffsetPollingStrategy(offsets[*partition]))
+			if err != nil && isInvalidOffset(err) {
+				offsets[*partition], err = p.resetOffset(*partition, err)
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if polled == nil || len(polled.Messages) == 0 {
+				continue
+			}
+			polledAny = true
+			for i, message := range polled.Messages {
+				job := dispatched{
+					message: iggcon.ReceivedMessage{
+						Message:       message,
+						CurrentOffset: polled.CurrentOffset,
+						PartitionId:   polled.PartitionId,
+					},
+					complete: p.completion(&tracker, polled.PartitionId, polled.CurrentOffset, message.Header.Offset),
+				}
+				tracker.add(polled.PartitionId, message.Header.Offset)
+				offsets[polled.PartitionId] = message.Header.Offset + 1
+				if p.opts.DropExpired && isExpired(&message) {
+					if p.opts.OnExpired != nil {
+						p.opts.OnExpired(ctx, job.message)
+					}
+					job.complete()
+					if p.opts.CommitExpired {
+						if err := p.Commit(); err != nil {
+							return err
+						}
+					}
+					continue
+				}
+				var worker int
+				if key := message.Key(); key != nil {
+					worker = jumpHash(key, len(queues))
+				} else {
+					worker = next % len(queues)
+					next++
+				}
+				select {
+				case queues[worker] <- job:
+				case <-ctx.Done():
+					p.unhandled.Add(int64(len(polled.Messages) - i))
+					return ctx.Err()
+				}
+			}
+		}
+		if !polledAny {
+			if err := p.idle(ctx, started); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// completion returns the function called once the message at the offset was handled, recording
+// the offsets of the partition below which every message was handled for the Commit policy. The
+// polling loop stores them when the policy requires it.
+func (p *WorkerPoolConsumer) completion(tracker *inflight, partitionId uint32, currentOffset, offset uint64) func() {
+	return func() {
+		handled := tracker.complete(partitionId, offset)
+		if len(handled) == 0 {
+			return
+		}
+		p.progress.update(partitionId, currentOffset, handled[len(handled)-1])
+		if p.opts.Commit.mode == commitOnPoll {
+			return
+		}
+		for _, offset := range handled {
+			p.commits.record(p.opts.Commit, partitionId, offset)
+		}
+	}
+}
+
+// inflight tracks the offsets dispatched to the workers in each partition, which complete in
+// any order, to find the ones below which every message was handled.
+type inflight struct {
+	mtx        sync.Mutex
+	partitions map[uint32]*window
+}
+
+// window holds the dispatched offsets of a partition not yet passed by the handled prefix, in
+// ascending order, and the handled ones among them.
+type window struct {
+	offsets []uint64
+	handled map[uint64]bool
+}
+
+func (t *inflight) add(partitionId uint32, offset uint64) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	if t.partitions == nil {
+		t.partitions = map[uint32]*window{}
+	}
+	w, ok := t.partitions[partitionId]
+	if !ok {
+		w = &window{handled: map[uint64]bool{}}
+		t.partitions[partitionId] = w
+	}
+	w.offsets = append(w.offsets, offset)
+}
+
+// complete marks the offset as handled and returns the offsets the handled prefix advanced past.
+func (t *inflight) complete(partitionId uint32, offset uint64) []uint64 {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	w := t.partitions[partitionId]
+	w.handled[offset] = true
+	var handled []uint64
+	for len(w.offsets) > 0 && w.handled[w.offsets[0]] {
+		delete(w.handled, w.offsets[0])
+		handled = append(handled, w.offsets[0])
+		w.offsets = w.offsets[1:]
+	}
+	return handled
+}
+
+// jumpHash maps the key to one of n buckets with the jump consistent hash of Lamping and Veach,
+// so changing the number of workers between runs moves the fewest keys.
+func jumpHash(key []byte, n int) int {
+	hash := fnv.New64a()
+	hash.Write(key)
+	k := hash.Sum64()
+	b, j := int64(-1), int64(0)
+	for j < int64(n) {
+		b = j
+		k = k*2862933555777941757 + 1
+		j = int64(float64(b+1) * (float64(int64(1)<<31) / float64((k>>33)+1)))
+	}
+	return int(b)
+}
@@ -0,0 +1,130 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package consumer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengertest"
+)
+
+func TestWorkerPoolConsumer(t *testing.T) {
+	client := messengertest.NewClient()
+	if _, err := client.CreateStream("orders", nil); err != nil {
+		t.Fatal(err)
+	}
+	streamId, topicId := iggcon.MustIdentifier("orders"), iggcon.MustIdentifier("created")
+	if _, err := client.CreateTopic(streamId, "created", 1, iggcon.CompressionAlgorithmNone, 0, 0, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	const workers = 4
+	// two keys handled by different workers
+	keys := []string{"key-0"}
+	for i := 1; len(keys) < 2; i++ {
+		if key := fmt.Sprintf("key-%d", i); jumpHash([]byte(key), workers) != jumpHash([]byte(keys[0]), workers) {
+			keys = append(keys, key)
+		}
+	}
+	var messages []iggcon.MessengerMessage
+	for i := 0; i < 10; i++ {
+		message, err := iggcon.NewMessengerMessage([]byte(fmt.Sprint(i)), iggcon.WithKey([]byte(keys[i%2])))
+		if err != nil {
+			t.Fatal(err)
+		}
+		messages = append(messages, message)
+	}
+	if err := client.SendMessages(streamId, topicId, iggcon.PartitionId(1), messages); err != nil {
+		t.Fatal(err)
+	}
+
+	var mtx sync.Mutex
+	handled := map[string][]uint64{}
+	count := func(key string) int {
+		mtx.Lock()
+		defer mtx.Unlock()
+		return len(handled[key])
+	}
+	release := make(chan struct{})
+	c, err := NewWorkerPoolConsumer(client, streamId, topicId, func(ctx context.Context, message iggcon.ReceivedMessage) error {
+		if message.Message.Header.Offset == 0 {
+			<-release
+		}
+		mtx.Lock()
+		defer mtx.Unlock()
+		key := string(message.Message.Key())
+		handled[key] = append(handled[key], message.Message.Header.Offset)
+		return nil
+	}, workers, WithCommitPolicy(CommitAfterHandler()), WithPollInterval(5*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored := func() int64 {
+		partition := uint32(1)
+		offset, err := client.GetConsumerOffset(iggcon.DefaultConsumer(), streamId, topicId, &partition)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if offset == nil {
+			return -1
+		}
+		return int64(offset.StoredOffset)
+	}
+	waitFor := func(condition func() bool) {
+		deadline := time.Now().Add(5 * time.Second)
+		for !condition() {
+			if time.Now().After(deadline) {
+				t.Fatal("timed out")
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	ran := make(chan error, 1)
+	go func() {
+		ran <- c.Run(context.Background())
+	}()
+	waitFor(func() bool { return count(keys[1]) == 5 })
+	time.Sleep(20 * time.Millisecond)
+	if count(keys[0]) != 0 || stored() != -1 {
+		t.Fatalf("expected the first key to wait for the first message and no offset to be stored, got %v and %d", handled, stored())
+	}
+
+	close(release)
+	waitFor(func() bool { return count(keys[0]) == 5 })
+	if err = c.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err = <-ran; err != nil {
+		t.Fatal(err)
+	}
+	if offset := stored(); offset != 9 {
+		t.Fatalf("expected the offset of the last message to be stored, got %d", offset)
+	}
+	for i, key := range keys {
+		for j, offset := range handled[key] {
+			if offset != uint64(2*j+i) {
+				t.Fatalf("expected the messages of %s in order, got %v", key, handled[key])
+			}
+		}
+	}
+}
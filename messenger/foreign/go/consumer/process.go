@@ -0,0 +1,107 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package consumer
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengercli"
+	"github.com/google/uuid"
+)
+
+// Transform maps a polled message to the messages produced for it, none to skip it.
+type Transform func(ctx context.Context, message iggcon.ReceivedMessage) ([]iggcon.MessengerMessage, error)
+
+// Sink is the topic a ProcessLoop produces to.
+type Sink struct {
+	StreamId iggcon.Identifier
+	TopicId  iggcon.Identifier
+	// Partitioning of the produced messages, balanced by default.
+	Partitioning iggcon.Partitioning
+	// Confirmation, when set, is the level the server reaches before acknowledging the sends.
+	Confirmation iggcon.Confirmation
+}
+
+// processNamespace derives the IDs of the messages produced by a ProcessLoop.
+var processNamespace = uuid.MustParse("5b2b4a7e-8d0a-4c5e-9f3e-2f1c6a9d7b10")
+
+// ProcessLoop consumes the topic, passes every message to transform and produces the results
+// to the sink before storing the offset of the message, until ctx is done or an error occurs.
+//
+// The server has no transactions, so the sends and the offset commit are not atomic: a crash
+// between them produces the results of a message again when it is redelivered. The produced
+// messages therefore get IDs derived from the source stream, topic, partition and offset and
+// their position in the results, identical on every attempt, so the consumers of the sink can
+// drop duplicates by ID and obtain exactly-once processing end to end. The options are those of
+// NewConsumer, with at-least-once delivery.
+func ProcessLoop(
+	ctx context.Context,
+	client messengercli.Client,
+	streamId iggcon.Identifier,
+	topicId iggcon.Identifier,
+	sink Sink,
+	transform Transform,
+	options ...Option,
+) error {
+	if transform == nil {
+		return errors.New("consumer: transform is required")
+	}
+	if sink.Partitioning.Kind == 0 {
+		sink.Partitioning = iggcon.None()
+	}
+	handler := func(ctx context.Context, message iggcon.ReceivedMessage) error {
+		results, err := transform(ctx, message)
+		if err != nil || len(results) == 0 {
+			return err
+		}
+		for i := range results {
+			results[i].Header.Id = processedMessageId(streamId, topicId, message, i)
+		}
+		if sink.Confirmation != iggcon.ConfirmationDefault {
+			return client.SendMessagesWithConfirmation(sink.StreamId, sink.TopicId, sink.Partitioning, results, sink.Confirmation)
+		}
+		return client.SendMessages(sink.StreamId, sink.TopicId, sink.Partitioning, results)
+	}
+	c, err := NewConsumer(client, streamId, topicId, handler, append(options, WithDeliverySemantics(AtLeastOnce))...)
+	if err != nil {
+		return err
+	}
+	return c.Run(ctx)
+}
+
+// processedMessageId derives the ID of the index-th message produced for a source message.
+func processedMessageId(streamId, topicId iggcon.Identifier, message iggcon.ReceivedMessage, index int) iggcon.MessageID {
+	source := make([]byte, 0, len(streamId.Value)+len(topicId.Value)+30)
+	source = appendIdentifier(source, streamId)
+	source = appendIdentifier(source, topicId)
+	source = binary.LittleEndian.AppendUint32(source, message.PartitionId)
+	source = binary.LittleEndian.AppendUint64(source, message.Message.Header.Offset)
+	source = binary.LittleEndian.AppendUint64(source, uint64(index))
+	return iggcon.MessageID(uuid.NewSHA1(processNamespace, source))
+}
+
+// appendIdentifier appends the kind and length of an identifier before its value, so the
+// boundary between the consecutive identifiers cannot shift.
+func appendIdentifier(source []byte, id iggcon.Identifier) []byte {
+	source = append(source, byte(id.Kind))
+	source = binary.LittleEndian.AppendUint32(source, uint32(len(id.Value)))
+	return append(source, id.Value...)
+}
@@ -0,0 +1,91 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package consumer
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+)
+
+func TestProcessLoop(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	processed := 0
+	client, _, streamId, topicId := newTestConsumer(t, func(context.Context, iggcon.ReceivedMessage) error {
+		return nil
+	})
+	sinkId, _ := iggcon.NewIdentifier("enriched")
+	if _, err := client.CreateTopic(streamId, "enriched", 1, iggcon.CompressionAlgorithm(1), 0, 0, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	err := ProcessLoop(ctx, client, streamId, topicId, Sink{StreamId: streamId, TopicId: sinkId},
+		func(ctx context.Context, message iggcon.ReceivedMessage) ([]iggcon.MessengerMessage, error) {
+			if processed++; processed == 3 {
+				cancel()
+			}
+			result, err := iggcon.NewMessengerMessage(bytes.ToUpper(message.Message.Payload))
+			return []iggcon.MessengerMessage{result}, err
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	partition := uint32(1)
+	polled, err := client.PollMessages(streamId, sinkId, iggcon.DefaultConsumer(), iggcon.NextPollingStrategy(), 10, true, &partition)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(polled.Messages) != 3 || string(polled.Messages[2].Payload) != "C" {
+		t.Fatalf("expected the transformed messages in the sink, got %d", len(polled.Messages))
+	}
+	source := iggcon.ReceivedMessage{PartitionId: 1}
+	source.Message.Header.Offset = 2
+	if polled.Messages[2].Header.Id != processedMessageId(streamId, topicId, source, 0) {
+		t.Fatal("expected the produced message IDs to derive from the source messages")
+	}
+	offset, err := client.GetConsumerOffset(iggcon.DefaultConsumer(), streamId, topicId, &partition)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if offset == nil || offset.StoredOffset != 2 {
+		t.Fatalf("expected the source offsets to be stored after producing, got %+v", offset)
+	}
+}
+
+func TestProcessedMessageId(t *testing.T) {
+	source := iggcon.ReceivedMessage{PartitionId: 1}
+	source.Message.Header.Offset = 2
+	id := processedMessageId(iggcon.MustIdentifier("ab"), iggcon.MustIdentifier("c"), source, 0)
+
+	if id != processedMessageId(iggcon.MustIdentifier("ab"), iggcon.MustIdentifier("c"), source, 0) {
+		t.Fatal("expected the IDs of the same source message to be equal")
+	}
+	for name, other := range map[string]iggcon.MessageID{
+		"shifted names": processedMessageId(iggcon.MustIdentifier("a"), iggcon.MustIdentifier("bc"), source, 0),
+		"numeric IDs":   processedMessageId(iggcon.MustIdentifier(uint32(0x6261)), iggcon.MustIdentifier("c"), source, 0),
+		"index":         processedMessageId(iggcon.MustIdentifier("ab"), iggcon.MustIdentifier("c"), source, 1),
+	} {
+		if other == id {
+			t.Errorf("expected the %s to derive another ID", name)
+		}
+	}
+}
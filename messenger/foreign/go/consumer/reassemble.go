@@ -0,0 +1,198 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package consumer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+)
+
+// ErrPayloadTooLarge is returned for the chunks of a payload exceeding the ReassemblyLimits.
+var ErrPayloadTooLarge = errors.New("consumer: chunked payload exceeds the reassembly limits")
+
+// ReassemblyLimits bounds the chunks Reassemble buffers until their payloads are complete.
+type ReassemblyLimits struct {
+	// MaxPayloadSize is the size of the largest payload reassembled, 100 MiB when 0.
+	MaxPayloadSize int
+	// MaxPendingBytes bounds the size of the chunks buffered, 256 MiB when 0. The oldest partial
+	// payloads are discarded to make room for the new chunks.
+	MaxPendingBytes int
+	// MaxPendingPayloads bounds the number of partial payloads, 1000 when 0. The oldest one is
+	// discarded to make room for a new one.
+	MaxPendingPayloads int
+	// Timeout is how long a partial payload waits for its next chunk before being discarded,
+	// a minute when 0.
+	Timeout time.Duration
+}
+
+// Reassemble is a middleware joining the chunks of the payloads split by iggcon.SplitMessage,
+// like producer.Producer.SendPayload with producer.WithChunking does, and calling the handler
+// once per payload, with the last chunk received carrying the whole payload without the chunk
+// headers. The other messages reach the handler unchanged. The chunks are buffered in memory
+// within the limits, shared by the partitions consumed concurrently; the offsets of the chunks
+// are stored as the consumer handles them, so the chunks of a partial payload are not polled
+// again after a restart.
+func Reassemble(limits ReassemblyLimits) Middleware {
+	r := newReassembler(limits)
+	return func(next Handler) Handler {
+		return func(ctx context.Context, message iggcon.ReceivedMessage) error {
+			chunk, ok, err := message.Message.Chunk()
+			if err != nil {
+				return fmt.Errorf("consumer: message at offset %d: %w", message.Message.Header.Offset, err)
+			}
+			if !ok {
+				return next(ctx, message)
+			}
+			complete, err := r.add(chunk, message)
+			if err != nil || complete == nil {
+				return err
+			}
+			return next(ctx, *complete)
+		}
+	}
+}
+
+// partialPayload holds the chunks of a payload received so far.
+type partialPayload struct {
+	chunks   [][]byte
+	received uint64
+	size     int
+	count    uint64
+	total    uint64
+	updated  time.Time
+}
+
+type reassembler struct {
+	limits ReassemblyLimits
+	now    func() time.Time
+
+	mtx          sync.Mutex
+	pending      map[[16]byte]*partialPayload
+	pendingBytes int
+}
+
+func newReassembler(limits ReassemblyLimits) *reassembler {
+	if limits.MaxPayloadSize <= 0 {
+		limits.MaxPayloadSize = 100 * 1024 * 1024
+	}
+	if limits.MaxPendingBytes <= 0 {
+		limits.MaxPendingBytes = 256 * 1024 * 1024
+	}
+	if limits.MaxPendingPayloads <= 0 {
+		limits.MaxPendingPayloads = 1000
+	}
+	if limits.Timeout <= 0 {
+		limits.Timeout = time.Minute
+	}
+	return &reassembler{limits: limits, now: time.Now, pending: map[[16]byte]*partialPayload{}}
+}
+
+// add buffers the chunk, returning the message of the whole payload once it is complete.
+func (r *reassembler) add(chunk iggcon.MessageChunk, message iggcon.ReceivedMessage) (*iggcon.ReceivedMessage, error) {
+	data := message.Message.Payload
+	if chunk.Size > uint64(r.limits.MaxPayloadSize) || len(data) > r.limits.MaxPendingBytes {
+		return nil, ErrPayloadTooLarge
+	}
+	if chunk.Count > chunk.Size {
+		return nil, fmt.Errorf("consumer: chunk %d of %x counts more chunks than bytes", chunk.Index, chunk.Id)
+	}
+
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	now := r.now()
+	for id, partial := range r.pending {
+		if now.Sub(partial.updated) >= r.limits.Timeout {
+			r.discard(id)
+		}
+	}
+
+	partial, ok := r.pending[chunk.Id]
+	if ok && (partial.count != chunk.Count || partial.total != chunk.Size) {
+		return nil, fmt.Errorf("consumer: chunk %d of %x does not match the previous chunks", chunk.Index, chunk.Id)
+	}
+	if ok && partial.chunks[chunk.Index] != nil {
+		// redelivered chunk
+		return nil, nil
+	}
+	if !ok {
+		for len(r.pending) >= r.limits.MaxPendingPayloads {
+			r.discard(r.oldest(chunk.Id))
+		}
+		partial = &partialPayload{chunks: make([][]byte, chunk.Count), count: chunk.Count, total: chunk.Size}
+		r.pending[chunk.Id] = partial
+	}
+	for r.pendingBytes+len(data) > r.limits.MaxPendingBytes {
+		if len(r.pending) == 1 {
+			r.discard(chunk.Id)
+			return nil, ErrPayloadTooLarge
+		}
+		r.discard(r.oldest(chunk.Id))
+	}
+
+	partial.chunks[chunk.Index] = data
+	partial.received++
+	partial.size += len(data)
+	partial.updated = now
+	r.pendingBytes += len(data)
+	if partial.received < partial.count {
+		return nil, nil
+	}
+
+	r.discard(chunk.Id)
+	payload := bytes.Join(partial.chunks, nil)
+	if uint64(len(payload)) != partial.total {
+		return nil, fmt.Errorf("consumer: chunks of %x hold %d bytes instead of %d", chunk.Id, len(payload), partial.total)
+	}
+	complete := message
+	complete.Message.Payload = payload
+	complete.Message.Header.PayloadLength = uint32(len(payload))
+	if err := complete.Message.DeleteUserHeaders(iggcon.ChunkIdHeader, iggcon.ChunkIndexHeader, iggcon.ChunkCountHeader, iggcon.ChunkSizeHeader); err != nil {
+		return nil, err
+	}
+	return &complete, nil
+}
+
+// oldest returns the ID of the partial payload updated the longest ago, other than except. Must
+// hold r.mtx.
+func (r *reassembler) oldest(except [16]byte) [16]byte {
+	var oldestId [16]byte
+	var oldest *partialPayload
+	for id, partial := range r.pending {
+		if id == except {
+			continue
+		}
+		if oldest == nil || partial.updated.Before(oldest.updated) {
+			oldestId, oldest = id, partial
+		}
+	}
+	return oldestId
+}
+
+// discard drops the partial payload. Must hold r.mtx.
+func (r *reassembler) discard(id [16]byte) {
+	if partial, ok := r.pending[id]; ok {
+		r.pendingBytes -= partial.size
+		delete(r.pending, id)
+	}
+}
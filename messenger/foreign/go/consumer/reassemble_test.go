@@ -0,0 +1,122 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package consumer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+)
+
+func received(messages []iggcon.MessengerMessage) []iggcon.ReceivedMessage {
+	var result []iggcon.ReceivedMessage
+	for i, message := range messages {
+		message.Header.Offset = uint64(i)
+		result = append(result, iggcon.ReceivedMessage{Message: message, PartitionId: 1})
+	}
+	return result
+}
+
+func TestReassemble(t *testing.T) {
+	var payloads [][]byte
+	handler := func(_ context.Context, message iggcon.ReceivedMessage) error {
+		if _, ok := message.Message.UserHeader(iggcon.ChunkIdHeader); ok {
+			t.Errorf("expected the chunk headers to be removed")
+		}
+		payloads = append(payloads, message.Message.Payload)
+		return nil
+	}
+	middleware := Reassemble(ReassemblyLimits{MaxPayloadSize: 1000, MaxPendingBytes: 500})
+	handle := middleware(handler)
+
+	large := bytes.Repeat([]byte("0123456789"), 30)
+	first, err := iggcon.SplitMessage(large, 100, iggcon.WithKey([]byte("a")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(first) != 3 {
+		t.Fatalf("expected 3 chunks, got %d", len(first))
+	}
+	second, err := iggcon.SplitMessage([]byte("small"), 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the chunks are interleaved with another message and redelivered
+	messages := received(append([]iggcon.MessengerMessage{first[1], first[0]}, append(second, first[0], first[2])...))
+	for _, message := range messages {
+		if err := handle(context.Background(), message); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(payloads) != 2 || string(payloads[0]) != "small" || !bytes.Equal(payloads[1], large) {
+		t.Fatalf("expected the small and the reassembled payloads, got %q", payloads)
+	}
+
+	tooLarge, _ := iggcon.SplitMessage(bytes.Repeat([]byte("x"), 2000), 100)
+	if err := handle(context.Background(), received(tooLarge)[0]); !errors.Is(err, ErrPayloadTooLarge) {
+		t.Fatalf("expected the payload to be too large, got %v", err)
+	}
+}
+
+func TestReassemble_DiscardsPartialPayloads(t *testing.T) {
+	r := newReassembler(ReassemblyLimits{Timeout: time.Minute, MaxPendingBytes: 300})
+	now := time.Now()
+	r.now = func() time.Time { return now }
+	add := func(message iggcon.ReceivedMessage) *iggcon.ReceivedMessage {
+		chunk, _, err := message.Message.Chunk()
+		if err != nil {
+			t.Fatal(err)
+		}
+		complete, err := r.add(chunk, message)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return complete
+	}
+
+	split := func(b byte, size int) []iggcon.ReceivedMessage {
+		messages, err := iggcon.SplitMessage(bytes.Repeat([]byte{b}, size), 100)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return received(messages)
+	}
+
+	add(split('a', 200)[0])
+	now = now.Add(2 * time.Minute)
+	add(split('b', 200)[0])
+	if len(r.pending) != 1 || r.pendingBytes != 100 {
+		t.Fatalf("expected the first partial payload to expire, got %d payloads of %d bytes", len(r.pending), r.pendingBytes)
+	}
+
+	now = now.Add(time.Second)
+	kept := split('c', 300)
+	add(kept[0])
+	add(kept[1])
+	// the oldest partial payload is discarded to make room for the last chunk
+	if complete := add(kept[2]); complete == nil || len(complete.Message.Payload) != 300 {
+		t.Fatalf("expected the kept payload to complete, got %v", complete)
+	}
+	if len(r.pending) != 0 || r.pendingBytes != 0 {
+		t.Fatalf("expected nothing pending, got %d payloads of %d bytes", len(r.pending), r.pendingBytes)
+	}
+}
@@ -0,0 +1,223 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package consumer
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	ierror "github.com/apache/messenger/foreign/go/errors"
+)
+
+// invalidOffsetCode is the code of the error the server returns for an offset out of the range
+// of a partition.
+const invalidOffsetCode = 4100
+
+// ErrOffsetOutOfRange is the cause of the resets of the stored offsets past the end of their
+// partition, e.g. once the partition was purged or recreated.
+var ErrOffsetOutOfRange = errors.New("consumer: stored offset is past the end of the partition")
+
+type offsetResetMode uint8
+
+const (
+	resetFail offsetResetMode = iota
+	resetEarliest
+	resetLatest
+	resetTimestamp
+)
+
+// OffsetResetPolicy decides where the consumer resumes a partition whose stored offset is out of
+// its range, e.g. past its end once it was purged, or rejected by the server once the messages
+// were removed by the retention.
+type OffsetResetPolicy struct {
+	mode      offsetResetMode
+	timestamp time.Time
+}
+
+// ResetFail stops the consumer with an *OffsetResetError. It is the default policy.
+func ResetFail() OffsetResetPolicy {
+	return OffsetResetPolicy{mode: resetFail}
+}
+
+// ResetEarliest resumes the partition from its first message.
+func ResetEarliest() OffsetResetPolicy {
+	return OffsetResetPolicy{mode: resetEarliest}
+}
+
+// ResetLatest resumes the partition after its last message, skipping the messages already in it.
+func ResetLatest() OffsetResetPolicy {
+	return OffsetResetPolicy{mode: resetLatest}
+}
+
+// ResetToTimestamp resumes the partition from the first message appended at or after the
+// timestamp, or after its last message when there is none.
+func ResetToTimestamp(timestamp time.Time) OffsetResetPolicy {
+	return OffsetResetPolicy{mode: resetTimestamp, timestamp: timestamp}
+}
+
+func (p OffsetResetPolicy) String() string {
+	switch p.mode {
+	case resetFail:
+		return "fail"
+	case resetEarliest:
+		return "earliest"
+	case resetLatest:
+		return "latest"
+	case resetTimestamp:
+		return "timestamp " + p.timestamp.Format(time.RFC3339Nano)
+	}
+	return "unknown"
+}
+
+// OffsetReset reports the reset of the offset of a partition, the offsets being the ones of the
+// next message the consumer reads.
+type OffsetReset struct {
+	PartitionId uint32
+	Policy      OffsetResetPolicy
+	Before      uint64
+	After       uint64
+	// Cause is ErrOffsetOutOfRange or the error of the server rejecting the offset.
+	Cause error
+}
+
+// OffsetResetError stops the consumer when the stored offset of a partition is out of its range
+// and the policy is ResetFail, or the offset could not be reset.
+type OffsetResetError struct {
+	PartitionId uint32
+	Err         error
+}
+
+func (e *OffsetResetError) Error() string {
+	return fmt.Sprintf("consumer: offset of partition %d out of range: %v", e.PartitionId, e.Err)
+}
+
+func (e *OffsetResetError) Unwrap() error {
+	return e.Err
+}
+
+// isInvalidOffset reports whether err is the server rejecting an offset out of range.
+func isInvalidOffset(err error) bool {
+	var messengerErr *ierror.MessengerError
+	return errors.As(err, &messengerErr) && messengerErr.Code == invalidOffsetCode
+}
+
+// checkOffsets resets the stored offsets past the end of the given partitions, the nil ones
+// assigned by the server being skipped.
+func (c *Consumer) checkOffsets(partitions []*uint32) error {
+	explicit := false
+	for _, partition := range partitions {
+		explicit = explicit || partition != nil
+	}
+	if !explicit {
+		return nil
+	}
+	topic, err := c.client.GetTopic(c.streamId, c.topicId)
+	if err != nil {
+		return err
+	}
+	ends := make(map[uint32]uint64, len(topic.Partitions))
+	for _, partition := range topic.Partitions {
+		if partition.MessagesCount > 0 {
+			ends[partition.Id] = partition.CurrentOffset + 1
+		}
+	}
+	for _, partition := range partitions {
+		if partition == nil {
+			continue
+		}
+		stored, ok, err := c.offsets.Load(*partition)
+		if err != nil {
+			return err
+		}
+		if ok && stored+1 > ends[*partition] {
+			if _, err := c.resetOffset(*partition, ErrOffsetOutOfRange); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// resetOffset resets the offset of the partition with the OffsetReset policy, returning the
+// offset of the next message to read.
+func (c *Consumer) resetOffset(partitionId uint32, cause error) (uint64, error) {
+	policy := c.opts.OffsetReset
+	if policy.mode == resetFail {
+		return 0, &OffsetResetError{PartitionId: partitionId, Err: cause}
+	}
+	before := uint64(0)
+	stored, ok, err := c.offsets.Load(partitionId)
+	if err != nil {
+		return 0, &OffsetResetError{PartitionId: partitionId, Err: errors.Join(cause, err)}
+	}
+	if ok {
+		before = stored + 1
+	}
+	after, err := c.resetTarget(partitionId, policy)
+	if err == nil {
+		if after == 0 {
+			err = c.offsets.Delete(partitionId)
+		} else {
+			err = c.offsets.Store(map[uint32]uint64{partitionId: after - 1})
+		}
+	}
+	if err != nil {
+		return 0, &OffsetResetError{PartitionId: partitionId, Err: errors.Join(cause, err)}
+	}
+	// the offsets handled before the reset must not move the stored offset back
+	c.commits.drop(partitionId)
+	c.positions.set(partitionId, after)
+	if c.opts.OnOffsetReset != nil {
+		c.opts.OnOffsetReset(OffsetReset{
+			PartitionId: partitionId,
+			Policy:      policy,
+			Before:      before,
+			After:       after,
+			Cause:       cause,
+		})
+	}
+	return after, nil
+}
+
+// resetTarget returns the offset of the next message to read from the partition per the policy.
+func (c *Consumer) resetTarget(partitionId uint32, policy OffsetResetPolicy) (uint64, error) {
+	strategy := iggcon.LastPollingStrategy()
+	switch policy.mode {
+	case resetEarliest:
+		return 0, nil
+	case resetTimestamp:
+		strategy = iggcon.TimestampPollingStrategy(uint64(policy.timestamp.UnixMicro()))
+	}
+	polled, err := c.client.PollMessages(c.streamId, c.topicId, c.opts.Consumer, strategy, 1, false, &partitionId)
+	if err != nil {
+		return 0, err
+	}
+	if polled != nil && len(polled.Messages) > 0 {
+		offset := polled.Messages[0].Header.Offset
+		if policy.mode == resetTimestamp {
+			return offset, nil
+		}
+		return offset + 1, nil
+	}
+	if policy.mode == resetTimestamp {
+		return c.resetTarget(partitionId, ResetLatest())
+	}
+	return 0, nil
+}
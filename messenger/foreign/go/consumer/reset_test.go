@@ -0,0 +1,131 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package consumer
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	ierror "github.com/apache/messenger/foreign/go/errors"
+	"github.com/apache/messenger/foreign/go/messengertest"
+)
+
+func TestConsumer_OffsetPastEnd(t *testing.T) {
+	client, c, streamId, topicId := newTestConsumer(t, func(ctx context.Context, message iggcon.ReceivedMessage) error {
+		return nil
+	})
+	partition := uint32(1)
+	if err := client.StoreConsumerOffset(iggcon.DefaultConsumer(), streamId, topicId, 10, &partition); err != nil {
+		t.Fatal(err)
+	}
+	var resetErr *OffsetResetError
+	if err := c.Run(context.Background()); !errors.As(err, &resetErr) || !errors.Is(err, ErrOffsetOutOfRange) {
+		t.Fatalf("expected the offset to be out of range, got %v", err)
+	}
+
+	var payloads []string
+	var resets []OffsetReset
+	c, err := NewConsumer(client, streamId, topicId, func(ctx context.Context, message iggcon.ReceivedMessage) error {
+		payloads = append(payloads, string(message.Message.Payload))
+		if len(payloads) == 3 {
+			return errors.New("done")
+		}
+		return nil
+	}, WithOffsetReset(ResetEarliest(), func(reset OffsetReset) {
+		resets = append(resets, reset)
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = c.Run(context.Background()); err == nil || err.Error() != "done" {
+		t.Fatalf("expected the handler error, got %v", err)
+	}
+	if len(payloads) != 3 || payloads[0] != "a" {
+		t.Fatalf("expected every message to be handled again, got %v", payloads)
+	}
+	if len(resets) != 1 || resets[0].Before != 11 || resets[0].After != 0 || !errors.Is(resets[0].Cause, ErrOffsetOutOfRange) {
+		t.Fatalf("expected the offset to be reset to the first message, got %+v", resets)
+	}
+}
+
+func TestConsumer_OffsetRejected(t *testing.T) {
+	var mtx sync.Mutex
+	var payloads []string
+	var resets []OffsetReset
+	client, c, streamId, topicId := newTestConsumer(t, func(ctx context.Context, message iggcon.ReceivedMessage) error {
+		mtx.Lock()
+		defer mtx.Unlock()
+		payloads = append(payloads, string(message.Message.Payload))
+		return nil
+	}, WithPollInterval(5*time.Millisecond), WithOffsetReset(ResetLatest(), func(reset OffsetReset) {
+		mtx.Lock()
+		defer mtx.Unlock()
+		resets = append(resets, reset)
+	}))
+	client.InjectFault(messengertest.FailTimes("PollMessages", 1, ierror.MapFromCode(invalidOffsetCode)))
+	ran := make(chan error, 1)
+	go func() {
+		ran <- c.Run(context.Background())
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		mtx.Lock()
+		reset := len(resets) == 1
+		mtx.Unlock()
+		if reset {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected the offset to be reset")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	message, err := iggcon.NewMessengerMessage([]byte("d"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = client.SendMessages(streamId, topicId, iggcon.PartitionId(1), []iggcon.MessengerMessage{message}); err != nil {
+		t.Fatal(err)
+	}
+	for {
+		mtx.Lock()
+		handled := len(payloads) > 0
+		mtx.Unlock()
+		if handled {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected the new message to be handled")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if err = c.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err = <-ran; err != nil {
+		t.Fatal(err)
+	}
+	if len(payloads) != 1 || payloads[0] != "d" || resets[0].After != 3 {
+		t.Fatalf("expected the messages before the reset to be skipped, got %v and %+v", payloads, resets)
+	}
+}
@@ -0,0 +1,67 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package consumer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// scope is a group of goroutines sharing a context, in the spirit of errgroup.
+// The first goroutine returning an error (or panicking) cancels the scope context,
+// and wait returns that error once all goroutines have exited.
+type scope struct {
+	wg     sync.WaitGroup
+	cancel context.CancelFunc
+	once   sync.Once
+	err    error
+}
+
+func newScope(parent context.Context) (*scope, context.Context) {
+	ctx, cancel := context.WithCancel(parent)
+	return &scope{cancel: cancel}, ctx
+}
+
+func (s *scope) goFunc(fn func() error) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		if err := s.run(fn); err != nil {
+			s.once.Do(func() {
+				s.err = err
+				s.cancel()
+			})
+		}
+	}()
+}
+
+func (s *scope) run(fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("consumer: partition processing panicked: %v", r)
+		}
+	}()
+	return fn()
+}
+
+func (s *scope) wait() error {
+	s.wg.Wait()
+	s.cancel()
+	return s.err
+}
@@ -0,0 +1,55 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package consumer
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestScope_FirstErrorCancelsOthers(t *testing.T) {
+	s, ctx := newScope(context.Background())
+	failure := errors.New("partition failed")
+
+	started := make(chan struct{})
+	s.goFunc(func() error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	s.goFunc(func() error {
+		<-started
+		return failure
+	})
+
+	if err := s.wait(); !errors.Is(err, failure) {
+		t.Fatalf("expected the first error to be returned, got %v", err)
+	}
+}
+
+func TestScope_PanicIsReturnedAsError(t *testing.T) {
+	s, _ := newScope(context.Background())
+	s.goFunc(func() error {
+		panic("boom")
+	})
+
+	if err := s.wait(); err == nil {
+		t.Fatal("expected panic to be converted into an error")
+	}
+}
@@ -24,10 +24,6 @@ type CreatePersonalAccessTokenRequest struct {
 	Expiry uint32 `json:"Expiry"`
 }
 
-type DeletePersonalAccessTokenRequest struct {
-	Name string `json:"Name"`
-}
-
 type PersonalAccessTokenInfo struct {
 	Name   string     `json:"Name"`
 	Expiry *time.Time `json:"Expiry"`
@@ -0,0 +1,134 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package iggcon
+
+import (
+	ierror "github.com/apache/messenger/foreign/go/errors"
+	"github.com/google/uuid"
+)
+
+const (
+	// ChunkIdHeader is the user header carrying the 16 bytes ID shared by the chunks of a payload
+	// split by SplitMessage.
+	ChunkIdHeader = "messenger-chunk-id"
+	// ChunkIndexHeader is the user header carrying the index of a chunk, from 0.
+	ChunkIndexHeader = "messenger-chunk-index"
+	// ChunkCountHeader is the user header carrying the number of chunks of the payload.
+	ChunkCountHeader = "messenger-chunk-count"
+	// ChunkSizeHeader is the user header carrying the size in bytes of the whole payload.
+	ChunkSizeHeader = "messenger-chunk-size"
+)
+
+// MessageChunk describes a message carrying a chunk of a payload split by SplitMessage.
+type MessageChunk struct {
+	// Id is shared by the chunks of the payload.
+	Id [16]byte
+	// Index is the index of the chunk, from 0.
+	Index uint64
+	// Count is the number of chunks of the payload.
+	Count uint64
+	// Size is the size in bytes of the whole payload.
+	Size uint64
+}
+
+// SplitMessage creates the messages of the payload: a single message when the payload fits in
+// chunkSize bytes, or else the chunks of the payload, each of them with the options applied and
+// the chunk headers set, to be reassembled by the consumer. A chunkSize of 0 is MaxPayloadSize.
+// The chunks of a payload are only kept in order on a single partition, so they should share a
+// key, or be sent to a given partition.
+func SplitMessage(payload []byte, chunkSize int, opts ...MessengerMessageOpt) ([]MessengerMessage, error) {
+	if chunkSize <= 0 || chunkSize > MaxPayloadSize {
+		chunkSize = MaxPayloadSize
+	}
+	if len(payload) <= chunkSize {
+		message, err := NewMessengerMessage(payload, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return []MessengerMessage{message}, nil
+	}
+
+	id := uuid.New()
+	count := (len(payload) + chunkSize - 1) / chunkSize
+	messages := make([]MessengerMessage, 0, count)
+	for index := 0; index < count; index++ {
+		chunk := payload[index*chunkSize : min((index+1)*chunkSize, len(payload))]
+		message, err := NewMessengerMessage(chunk, opts...)
+		if err != nil {
+			return nil, err
+		}
+		err = message.SetUserHeaders(map[HeaderKey]HeaderValue{
+			{Value: ChunkIdHeader}:    {Kind: Raw, Value: id[:]},
+			{Value: ChunkIndexHeader}: NewUint64HeaderValue(uint64(index)),
+			{Value: ChunkCountHeader}: NewUint64HeaderValue(uint64(count)),
+			{Value: ChunkSizeHeader}:  NewUint64HeaderValue(uint64(len(payload))),
+		})
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+	return messages, nil
+}
+
+// Chunk returns the chunk the message carries, false when it is not a chunk. An error is
+// returned when the chunk headers are invalid.
+func (m *MessengerMessage) Chunk() (MessageChunk, bool, error) {
+	idValue, ok := m.UserHeader(ChunkIdHeader)
+	if !ok {
+		return MessageChunk{}, false, nil
+	}
+	chunk := MessageChunk{}
+	if len(idValue.Value) != len(chunk.Id) {
+		return MessageChunk{}, true, ierror.CustomError("invalid_message_chunk")
+	}
+	copy(chunk.Id[:], idValue.Value)
+	for key, field := range map[string]*uint64{
+		ChunkIndexHeader: &chunk.Index,
+		ChunkCountHeader: &chunk.Count,
+		ChunkSizeHeader:  &chunk.Size,
+	} {
+		value, ok := m.UserHeader(key)
+		if !ok {
+			return MessageChunk{}, true, ierror.CustomError("invalid_message_chunk")
+		}
+		number, err := value.AsUint64()
+		if err != nil {
+			return MessageChunk{}, true, ierror.CustomError("invalid_message_chunk")
+		}
+		*field = number
+	}
+	if chunk.Index >= chunk.Count {
+		return MessageChunk{}, true, ierror.CustomError("invalid_message_chunk")
+	}
+	return chunk, true, nil
+}
+
+// DeleteUserHeaders removes the user headers with the given keys from the message.
+func (m *MessengerMessage) DeleteUserHeaders(keys ...string) error {
+	headers, err := DeserializeHeaders(m.UserHeaders)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		delete(headers, HeaderKey{Value: key})
+	}
+	m.UserHeaders = GetHeadersBytes(headers)
+	m.Header.UserHeaderLength = uint32(len(m.UserHeaders))
+	return nil
+}
@@ -28,4 +28,21 @@ type ClientInfo struct {
 	UserID              uint32 `json:"userId"`
 	Transport           string `json:"transport"`
 	ConsumerGroupsCount uint32 `json:"consumerGroupsCount"`
+	// Labels are the labels attached by the client at connect time, servers speaking
+	// a dialect without client labels never report them.
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// Well-known client labels, any other key can be used as well.
+const (
+	LabelService     = "service"
+	LabelVersion     = "version"
+	LabelTeam        = "team"
+	LabelEnvironment = "environment"
+)
+
+// UpdateClientLabelsRequest replaces the labels of the current client, so operators can
+// attribute traffic and lag to the owning teams.
+type UpdateClientLabelsRequest struct {
+	Labels map[string]string `json:"labels"`
 }
@@ -0,0 +1,53 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package iggcon
+
+import "time"
+
+// Clock provides the origin timestamps of the messages. Injecting a Clock makes the timestamps
+// deterministic in tests, or replays historical data with its original timeline.
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc adapts a function to the Clock interface.
+type ClockFunc func() time.Time
+
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// SystemClock is the Clock reading the system time.
+var SystemClock Clock = ClockFunc(time.Now)
+
+// WithTimestamp sets the origin timestamp of the message, which is otherwise the time the
+// message was created, or the time it is sent by a client configured with a Clock.
+func WithTimestamp(t time.Time) MessengerMessageOpt {
+	return func(m *MessengerMessage) {
+		m.Header.OriginTimestamp = uint64(t.UnixMicro())
+		m.explicitTimestamp = true
+	}
+}
+
+// StampOrigin sets the origin timestamp of the message from the clock, unless it was set with WithTimestamp.
+func (m *MessengerMessage) StampOrigin(clock Clock) {
+	if m.explicitTimestamp {
+		return
+	}
+	m.Header.OriginTimestamp = uint64(clock.Now().UnixMicro())
+}
@@ -0,0 +1,39 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package iggcon
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStampOrigin(t *testing.T) {
+	clock := ClockFunc(func() time.Time { return time.UnixMicro(2000) })
+
+	stamped, _ := NewMessengerMessage([]byte("stamped"))
+	stamped.StampOrigin(clock)
+	if stamped.Header.OriginTimestamp != 2000 {
+		t.Errorf("expected the message to be stamped by the clock, got %d", stamped.Header.OriginTimestamp)
+	}
+
+	explicit, _ := NewMessengerMessage([]byte("explicit"), WithTimestamp(time.UnixMicro(1000)))
+	explicit.StampOrigin(clock)
+	if explicit.Header.OriginTimestamp != 1000 {
+		t.Errorf("expected WithTimestamp to override the clock, got %d", explicit.Header.OriginTimestamp)
+	}
+}
@@ -0,0 +1,92 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package iggcon
+
+// ClusterMetadata describes the nodes of a cluster of servers and the leader of every partition,
+// which accepts the messages sent to it.
+type ClusterMetadata struct {
+	Name       string            `json:"name"`
+	Nodes      []ClusterNode     `json:"nodes"`
+	Partitions []PartitionLeader `json:"partitions"`
+}
+
+type ClusterNodeRole uint8
+
+const (
+	ClusterNodeLeader   ClusterNodeRole = 1
+	ClusterNodeFollower ClusterNodeRole = 2
+)
+
+type ClusterNodeStatus uint8
+
+const (
+	ClusterNodeHealthy     ClusterNodeStatus = 1
+	ClusterNodeStarting    ClusterNodeStatus = 2
+	ClusterNodeStopping    ClusterNodeStatus = 3
+	ClusterNodeUnreachable ClusterNodeStatus = 4
+)
+
+type ClusterNode struct {
+	Id   uint32 `json:"id"`
+	Name string `json:"name"`
+	// Address is the address clients connect to the node at.
+	Address string            `json:"address"`
+	Role    ClusterNodeRole   `json:"role"`
+	Status  ClusterNodeStatus `json:"status"`
+}
+
+// PartitionLeader names the node leading a partition.
+type PartitionLeader struct {
+	StreamId    uint32 `json:"streamId"`
+	StreamName  string `json:"streamName"`
+	TopicId     uint32 `json:"topicId"`
+	TopicName   string `json:"topicName"`
+	PartitionId uint32 `json:"partitionId"`
+	LeaderId    uint32 `json:"leaderId"`
+}
+
+// Node returns the node of the given id, or nil when there is none.
+func (m *ClusterMetadata) Node(id uint32) *ClusterNode {
+	for i := range m.Nodes {
+		if m.Nodes[i].Id == id {
+			return &m.Nodes[i]
+		}
+	}
+	return nil
+}
+
+// Leader returns the node leading the partition of the stream and topic by unique IDs or names,
+// or nil when the metadata does not know it.
+func (m *ClusterMetadata) Leader(streamId, topicId Identifier, partitionId uint32) *ClusterNode {
+	for _, partition := range m.Partitions {
+		if partition.PartitionId == partitionId &&
+			matchesIdentifier(streamId, partition.StreamId, partition.StreamName) &&
+			matchesIdentifier(topicId, partition.TopicId, partition.TopicName) {
+			return m.Node(partition.LeaderId)
+		}
+	}
+	return nil
+}
+
+func matchesIdentifier(identifier Identifier, id uint32, name string) bool {
+	if value, err := identifier.Uint32(); err == nil {
+		return value == id
+	}
+	value, err := identifier.String()
+	return err == nil && value == name
+}
@@ -15,16 +15,20 @@
 // specific language governing permissions and limitations
 // under the License.
 
+// Code generated by protogen from protocol.json. DO NOT EDIT.
+
 package iggcon
 
 type CommandCode int
 
 const (
 	PingCode                 CommandCode = 1
+	GetClusterMetadataCode   CommandCode = 5
 	GetStatsCode             CommandCode = 10
 	GetMeCode                CommandCode = 20
 	GetClientCode            CommandCode = 21
 	GetClientsCode           CommandCode = 22
+	UpdateClientLabelsCode   CommandCode = 23
 	GetUserCode              CommandCode = 31
 	GetUsersCode             CommandCode = 32
 	CreateUserCode           CommandCode = 33
@@ -42,6 +46,7 @@ const (
 	SendMessagesCode         CommandCode = 101
 	GetOffsetCode            CommandCode = 120
 	StoreOffsetCode          CommandCode = 121
+	DeleteOffsetCode         CommandCode = 122
 	GetStreamCode            CommandCode = 200
 	GetStreamsCode           CommandCode = 201
 	CreateStreamCode         CommandCode = 202
@@ -62,7 +67,48 @@ const (
 	LeaveGroupCode           CommandCode = 605
 )
 
-//    internal const int GET_PERSONAL_ACCESS_TOKENS_CODE = 41;
-//    internal const int CREATE_PERSONAL_ACCESS_TOKEN_CODE = 42;
-//    internal const int DELETE_PERSONAL_ACCESS_TOKEN_CODE = 43;
-//    internal const int LOGIN_WITH_PERSONAL_ACCESS_TOKEN_CODE = 44;
+var commands = map[CommandCode]commandInfo{
+	PingCode:                 {name: "Ping"},
+	GetClusterMetadataCode:   {name: "GetClusterMetadata", permission: "ReadServers"},
+	GetStatsCode:             {name: "GetStats", permission: "ReadServers"},
+	GetMeCode:                {name: "GetMe"},
+	GetClientCode:            {name: "GetClient", permission: "ReadServers"},
+	GetClientsCode:           {name: "GetClients", permission: "ReadServers"},
+	UpdateClientLabelsCode:   {name: "UpdateClientLabels"},
+	GetUserCode:              {name: "GetUser", permission: "ReadUsers", resources: []string{"user"}},
+	GetUsersCode:             {name: "GetUsers", permission: "ReadUsers"},
+	CreateUserCode:           {name: "CreateUser", permission: "ManageUsers"},
+	DeleteUserCode:           {name: "DeleteUser", permission: "ManageUsers", resources: []string{"user"}},
+	UpdateUserCode:           {name: "UpdateUser", permission: "ManageUsers", resources: []string{"user"}},
+	UpdatePermissionsCode:    {name: "UpdatePermissions", permission: "ManageUsers", resources: []string{"user"}},
+	ChangePasswordCode:       {name: "ChangePassword", resources: []string{"user"}},
+	LoginUserCode:            {name: "LoginUser"},
+	LogoutUserCode:           {name: "LogoutUser"},
+	GetAccessTokensCode:      {name: "GetAccessTokens"},
+	CreateAccessTokenCode:    {name: "CreateAccessToken"},
+	DeleteAccessTokenCode:    {name: "DeleteAccessToken"},
+	LoginWithAccessTokenCode: {name: "LoginWithAccessToken"},
+	PollMessagesCode:         {name: "PollMessages", permission: "PollMessages", resources: []string{"consumer", "stream", "topic"}},
+	SendMessagesCode:         {name: "SendMessages", permission: "SendMessages", resources: []string{"stream", "topic"}},
+	GetOffsetCode:            {name: "GetOffset", permission: "PollMessages", resources: []string{"consumer", "stream", "topic"}},
+	StoreOffsetCode:          {name: "StoreOffset", permission: "PollMessages", resources: []string{"consumer", "stream", "topic"}},
+	DeleteOffsetCode:         {name: "DeleteOffset", permission: "PollMessages", resources: []string{"consumer", "stream", "topic"}},
+	GetStreamCode:            {name: "GetStream", permission: "ReadStreams", resources: []string{"stream"}},
+	GetStreamsCode:           {name: "GetStreams", permission: "ReadStreams"},
+	CreateStreamCode:         {name: "CreateStream", permission: "ManageStreams"},
+	DeleteStreamCode:         {name: "DeleteStream", permission: "ManageStreams", resources: []string{"stream"}},
+	UpdateStreamCode:         {name: "UpdateStream", permission: "ManageStreams", resources: []string{"stream"}},
+	GetTopicCode:             {name: "GetTopic", permission: "ReadTopics", resources: []string{"stream", "topic"}},
+	GetTopicsCode:            {name: "GetTopics", permission: "ReadTopics", resources: []string{"stream"}},
+	CreateTopicCode:          {name: "CreateTopic", permission: "ManageTopics", resources: []string{"stream"}},
+	DeleteTopicCode:          {name: "DeleteTopic", permission: "ManageTopics", resources: []string{"stream", "topic"}},
+	UpdateTopicCode:          {name: "UpdateTopic", permission: "ManageTopics", resources: []string{"stream", "topic"}},
+	CreatePartitionsCode:     {name: "CreatePartitions", permission: "ManageTopics", resources: []string{"stream", "topic"}},
+	DeletePartitionsCode:     {name: "DeletePartitions", permission: "ManageTopics", resources: []string{"stream", "topic"}},
+	GetGroupCode:             {name: "GetGroup", permission: "ReadTopics", resources: []string{"stream", "topic", "group"}},
+	GetGroupsCode:            {name: "GetGroups", permission: "ReadTopics", resources: []string{"stream", "topic"}},
+	CreateGroupCode:          {name: "CreateGroup", permission: "ManageTopics", resources: []string{"stream", "topic"}},
+	DeleteGroupCode:          {name: "DeleteGroup", permission: "ManageTopics", resources: []string{"stream", "topic", "group"}},
+	JoinGroupCode:            {name: "JoinGroup", permission: "PollMessages", resources: []string{"stream", "topic", "group"}},
+	LeaveGroupCode:           {name: "LeaveGroup", permission: "PollMessages", resources: []string{"stream", "topic", "group"}},
+}
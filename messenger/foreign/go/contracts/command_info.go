@@ -0,0 +1,47 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package iggcon
+
+import "fmt"
+
+// commandInfo describes a command, as generated from protocol.json.
+type commandInfo struct {
+	name       string
+	permission string
+	resources  []string
+}
+
+// String returns the name of the command.
+func (c CommandCode) String() string {
+	if info, ok := commands[c]; ok {
+		return info.name
+	}
+	return fmt.Sprintf("Command%d", int(c))
+}
+
+// Permission returns the global permission granting the command, named after the field of
+// GlobalPermissions, or an empty string for the commands any user may send.
+func (c CommandCode) Permission() string {
+	return commands[c].permission
+}
+
+// Resources returns the kinds of the identifiers the payload of the command starts with, in
+// order: "consumer", "stream", "topic", "group" or "user".
+func (c CommandCode) Resources() []string {
+	return commands[c].resources
+}
@@ -0,0 +1,50 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package iggcon
+
+// Confirmation is the durability level reached by the server before it acknowledges sent messages.
+// Higher levels trade latency for durability.
+type Confirmation byte
+
+const (
+	// ConfirmationDefault leaves the confirmation level to the server configuration.
+	ConfirmationDefault Confirmation = iota
+	// ConfirmationNoWait acknowledges as soon as the messages are accepted in memory, the lowest
+	// latency but messages may be lost if the server crashes before writing them.
+	ConfirmationNoWait
+	// ConfirmationWrite acknowledges once the messages are written to the partition segment.
+	ConfirmationWrite
+	// ConfirmationFsync acknowledges once the messages are fsynced to disk and replicated,
+	// the highest durability and latency.
+	ConfirmationFsync
+)
+
+func (c Confirmation) String() string {
+	switch c {
+	case ConfirmationDefault:
+		return "default"
+	case ConfirmationNoWait:
+		return "no_wait"
+	case ConfirmationWrite:
+		return "write"
+	case ConfirmationFsync:
+		return "fsync"
+	default:
+		return "unknown"
+	}
+}
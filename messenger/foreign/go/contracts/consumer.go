@@ -30,7 +30,7 @@ type Consumer struct {
 }
 
 func DefaultConsumer() Consumer {
-	defaultID, _ := NewIdentifier(uint32(1))
+	defaultID := MustIdentifier(uint32(1))
 	return Consumer{
 		Kind: ConsumerKindSingle,
 		Id:   defaultID,
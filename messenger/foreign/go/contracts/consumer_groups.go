@@ -35,13 +35,6 @@ type ConsumerGroupMember struct {
 	Partitions      []uint32
 }
 
-type CreateConsumerGroupRequest struct {
-	StreamId        Identifier `json:"streamId"`
-	TopicId         Identifier `json:"topicId"`
-	ConsumerGroupId *uint32    `json:"consumerGroupId"`
-	Name            string     `json:"name"`
-}
-
 type DeleteConsumerGroupRequest struct {
 	StreamId        Identifier `json:"streamId"`
 	TopicId         Identifier `json:"topicId"`
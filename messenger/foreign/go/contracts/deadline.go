@@ -0,0 +1,49 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package iggcon
+
+import "time"
+
+// DeadlineHeader is the user header carrying the deadline, in Unix microseconds, after which
+// the upstream caller is no longer waiting for the message to be processed.
+const DeadlineHeader = "messenger-deadline"
+
+// WithDeadline sets the deadline header of the message.
+func WithDeadline(deadline time.Time) MessengerMessageOpt {
+	return func(m *MessengerMessage) {
+		err := m.SetUserHeaders(map[HeaderKey]HeaderValue{
+			{Value: DeadlineHeader}: NewUint64HeaderValue(uint64(deadline.UnixMicro())),
+		})
+		if err != nil && m.err == nil {
+			m.err = err
+		}
+	}
+}
+
+// MessageDeadline returns the deadline propagated in the message headers, if any.
+func MessageDeadline(message *MessengerMessage) (time.Time, bool) {
+	value, ok := message.UserHeader(DeadlineHeader)
+	if !ok {
+		return time.Time{}, false
+	}
+	micros, err := value.AsUint64()
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.UnixMicro(int64(micros)), true
+}
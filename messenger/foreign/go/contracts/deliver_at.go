@@ -0,0 +1,51 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package iggcon
+
+import "time"
+
+// DeliverAtHeader is the user header carrying the time, in Unix microseconds, before which the
+// message must not be delivered to the consumers of its topic.
+const DeliverAtHeader = "messenger-deliver-at"
+
+// WithDeliverAt schedules the message for delivery at the given time, in the DeliverAtHeader.
+// The server does not delay the messages: they are held back by a client routing them through
+// a scheduler topic, like the one of the schedule package.
+func WithDeliverAt(deliverAt time.Time) MessengerMessageOpt {
+	return func(m *MessengerMessage) {
+		err := m.SetUserHeaders(map[HeaderKey]HeaderValue{
+			{Value: DeliverAtHeader}: NewUint64HeaderValue(uint64(deliverAt.UnixMicro())),
+		})
+		if err != nil && m.err == nil {
+			m.err = err
+		}
+	}
+}
+
+// MessageDeliverAt returns the time the message is scheduled for, if it is.
+func MessageDeliverAt(message *MessengerMessage) (time.Time, bool) {
+	value, ok := message.UserHeader(DeliverAtHeader)
+	if !ok {
+		return time.Time{}, false
+	}
+	micros, err := value.AsUint64()
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.UnixMicro(int64(micros)), true
+}
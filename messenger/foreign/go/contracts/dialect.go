@@ -0,0 +1,110 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package iggcon
+
+import "strings"
+
+// Dialect describes the flavor of the binary protocol spoken by a server.
+//
+// The Messenger server was forked from Iggy and both protocols still share the codes of their
+// commands, but they drift apart: Iggy servers pad every message header with 8 reserved bytes
+// and report their version in the stats, and newer Messenger servers accept extensions the
+// released ones do not know. A Dialect tells which extensions the server accepts and which
+// layout the (de)serializers use.
+type Dialect struct {
+	// Name is the name of the server flavor.
+	Name string
+	// MessageHeaderSize is the size of a message header on the wire, including reserved bytes.
+	MessageHeaderSize int
+	// Confirmation reports whether the server accepts a Confirmation level in the metadata
+	// of the send messages request.
+	Confirmation bool
+	// LongPolling reports whether the server accepts a max wait in the poll messages request,
+	// holding the polls of partitions without new messages until some arrive.
+	LongPolling bool
+	// ClientLabels reports whether the server stores client labels and reports them in the client
+	// info, with the UpdateClientLabels command.
+	ClientLabels bool
+	// ClusterMetadata reports whether the server runs in a cluster and reports its nodes and the
+	// leaders of the partitions with the GetClusterMetadata command. No released server does yet.
+	ClusterMetadata bool
+}
+
+// IggyMessageHeaderSize is the size of a message header sent by Iggy-era servers.
+const IggyMessageHeaderSize = MessageHeaderSize + 8
+
+var (
+	// MessengerDialect is the protocol spoken by the released Messenger servers, used by default.
+	MessengerDialect = &Dialect{
+		Name:              "messenger",
+		MessageHeaderSize: MessageHeaderSize,
+	}
+
+	// MessengerExtendedDialect is the protocol spoken by the Messenger servers accepting the
+	// confirmation levels, the long polls and the client labels. The servers do not report it,
+	// so it is only used when set with the client options.
+	MessengerExtendedDialect = &Dialect{
+		Name:              "messenger-extended",
+		MessageHeaderSize: MessageHeaderSize,
+		Confirmation:      true,
+		LongPolling:       true,
+		ClientLabels:      true,
+	}
+
+	// IggyDialect is the protocol spoken by Iggy-era servers.
+	IggyDialect = &Dialect{
+		Name:              "iggy",
+		MessageHeaderSize: IggyMessageHeaderSize,
+	}
+)
+
+// NewDialect creates a dialect for a custom server flavor without any extension, the ones the
+// server accepts are enabled by setting their fields.
+func NewDialect(name string, messageHeaderSize int) *Dialect {
+	return &Dialect{
+		Name:              name,
+		MessageHeaderSize: messageHeaderSize,
+	}
+}
+
+// Supports reports whether the server knows the command, the commands of the extensions
+// being only known by the servers accepting them.
+func (d *Dialect) Supports(code CommandCode) bool {
+	switch code {
+	case GetClusterMetadataCode:
+		return d.ClusterMetadata
+	case UpdateClientLabelsCode:
+		return d.ClientLabels
+	default:
+		return true
+	}
+}
+
+// MessageHeaderPadding returns the number of reserved bytes following each message header.
+func (d *Dialect) MessageHeaderPadding() int {
+	return d.MessageHeaderSize - MessageHeaderSize
+}
+
+// DetectDialect picks the dialect from the server stats. Iggy servers append their version
+// (e.g. "0.4.300") to the stats payload, which the Messenger server does not.
+func DetectDialect(stats *Stats) *Dialect {
+	if stats != nil && stats.ServerVersion != "" && !strings.HasPrefix(stats.ServerVersion, MessengerDialect.Name) {
+		return IggyDialect
+	}
+	return MessengerDialect
+}
@@ -0,0 +1,52 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package iggcon
+
+import "testing"
+
+func TestDialectSupports(t *testing.T) {
+	clustered := NewDialect("clustered", MessageHeaderSize)
+	clustered.ClusterMetadata = true
+	for _, tt := range []struct {
+		dialect   *Dialect
+		command   CommandCode
+		supported bool
+	}{
+		{MessengerDialect, PingCode, true},
+		{MessengerDialect, SendMessagesCode, true},
+		{MessengerDialect, GetClusterMetadataCode, false},
+		{MessengerDialect, UpdateClientLabelsCode, false},
+		{MessengerExtendedDialect, GetClusterMetadataCode, false},
+		{MessengerExtendedDialect, UpdateClientLabelsCode, true},
+		{IggyDialect, LeaveGroupCode, true},
+		{IggyDialect, GetClusterMetadataCode, false},
+		{IggyDialect, UpdateClientLabelsCode, false},
+		{clustered, GetClusterMetadataCode, true},
+		{clustered, UpdateClientLabelsCode, false},
+	} {
+		if supported := tt.dialect.Supports(tt.command); supported != tt.supported {
+			t.Errorf("%s: expected command %d to be supported: %t", tt.dialect.Name, tt.command, tt.supported)
+		}
+	}
+}
+
+func TestMessengerDialectHasNoExtension(t *testing.T) {
+	if MessengerDialect.Confirmation || MessengerDialect.LongPolling || MessengerDialect.ClientLabels || MessengerDialect.ClusterMetadata {
+		t.Errorf("expected the default dialect to keep the wire format of the released servers, got %+v", *MessengerDialect)
+	}
+}
@@ -17,20 +17,23 @@
 
 package iggcon
 
-import (
-	"math"
-)
-
 const (
 	// MessengerExpiryServerDefault use the default expiry time from the server
-	MessengerExpiryServerDefault Duration = 0
+	//
+	// Deprecated: Use ExpiryServerDefault.
+	MessengerExpiryServerDefault = ExpiryServerDefault
 	// MessengerExpiryNeverExpire never expire
-	MessengerExpiryNeverExpire Duration = math.MaxUint64
+	//
+	// Deprecated: Use ExpiryNever.
+	MessengerExpiryNeverExpire = ExpiryNever
 )
 
 // Duration represents the expiration duration in microsecond (µs).
-type Duration uint64
+//
+// Deprecated: Use Expiry, built with ExpiryAfter.
+type Duration = Expiry
 
+// Deprecated: Use ExpiryAfter with the time package durations.
 const (
 	Microsecond Duration = 1
 	Millisecond          = 1000 * Microsecond
@@ -0,0 +1,63 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package iggcon
+
+import (
+	"math"
+	"strconv"
+	"time"
+)
+
+// Expiry is how long the messages of a topic are kept before being deleted, sent to the server
+// as microseconds.
+type Expiry uint64
+
+const (
+	// ExpiryServerDefault uses the message expiry configured on the server.
+	ExpiryServerDefault Expiry = 0
+	// ExpiryNever keeps the messages until the topic size limit, if any, deletes them.
+	ExpiryNever Expiry = math.MaxUint64
+)
+
+// ExpiryAfter makes the messages expire after the given duration, rounded up to the microsecond.
+// A non-positive duration expires the messages after a microsecond.
+func ExpiryAfter(d time.Duration) Expiry {
+	microseconds := (d + time.Microsecond - 1) / time.Microsecond
+	return Expiry(max(microseconds, 1))
+}
+
+// Duration returns how long the messages are kept, 0 for ExpiryServerDefault and ExpiryNever.
+func (e Expiry) Duration() time.Duration {
+	if e == ExpiryServerDefault || e == ExpiryNever || e > math.MaxInt64/Expiry(time.Microsecond) {
+		return 0
+	}
+	return time.Duration(e) * time.Microsecond
+}
+
+func (e Expiry) String() string {
+	switch e {
+	case ExpiryServerDefault:
+		return "server_default"
+	case ExpiryNever:
+		return "never"
+	}
+	if d := e.Duration(); d > 0 {
+		return d.String()
+	}
+	return strconv.FormatUint(uint64(e), 10) + "µs"
+}
@@ -19,6 +19,8 @@ package iggcon
 
 import (
 	"encoding/binary"
+	"fmt"
+
 	ierror "github.com/apache/messenger/foreign/go/errors"
 )
 
@@ -35,6 +37,9 @@ const (
 	StringId  IdKind = 2
 )
 
+// MaxIdentifierLength is the maximum length in bytes of a string identifier.
+const MaxIdentifierLength = 255
+
 // NewIdentifier create a new identifier
 func NewIdentifier[T uint32 | string](value T) (Identifier, error) {
 	switch v := any(value).(type) {
@@ -46,6 +51,48 @@ func NewIdentifier[T uint32 | string](value T) (Identifier, error) {
 	return Identifier{}, ierror.InvalidIdentifier
 }
 
+// MustIdentifier is like NewIdentifier but panics when the value is not a valid identifier.
+// It simplifies the initialization of identifiers known to be valid, like constants.
+func MustIdentifier[T uint32 | string](value T) Identifier {
+	id, err := NewIdentifier(value)
+	if err != nil {
+		panic(fmt.Sprintf("invalid identifier %v: %v", value, err))
+	}
+	return id
+}
+
+// IdentifierFromBytes creates an identifier of the given kind from its encoded value, the
+// little endian uint32 of a numeric identifier or the bytes of a string one.
+func IdentifierFromBytes(kind IdKind, value []byte) (Identifier, error) {
+	id := Identifier{Kind: kind, Length: len(value), Value: append([]byte(nil), value...)}
+	if err := id.Validate(); err != nil {
+		return Identifier{}, err
+	}
+	return id, nil
+}
+
+// Validate checks that an identifier, possibly built by hand, can be sent to the server: a
+// numeric identifier is a non-zero uint32 and a string identifier holds 1 to
+// MaxIdentifierLength bytes, Length matching the value in both cases.
+func (id Identifier) Validate() error {
+	if id.Length != len(id.Value) {
+		return ierror.InvalidIdentifier
+	}
+	switch id.Kind {
+	case NumericId:
+		if id.Length != 4 || binary.LittleEndian.Uint32(id.Value) == 0 {
+			return ierror.InvalidIdentifier
+		}
+	case StringId:
+		if id.Length == 0 || id.Length > MaxIdentifierLength {
+			return ierror.InvalidIdentifier
+		}
+	default:
+		return ierror.InvalidIdentifier
+	}
+	return nil
+}
+
 // newNumericIdentifier creates a new identifier from the given numeric value.
 func newNumericIdentifier(value uint32) (Identifier, error) {
 	if value == 0 {
@@ -64,7 +111,7 @@ func newNumericIdentifier(value uint32) (Identifier, error) {
 // NewStringIdentifier creates a new identifier from the given string value.
 func newStringIdentifier(value string) (Identifier, error) {
 	length := len(value)
-	if length == 0 || length > 255 {
+	if length == 0 || length > MaxIdentifierLength {
 		return Identifier{}, ierror.InvalidIdentifier
 	}
 	return Identifier{
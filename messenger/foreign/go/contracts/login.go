@@ -24,10 +24,6 @@ type LoginUserRequest struct {
 	Context  string `json:"context,omitempty"`
 }
 
-type LoginWithPersonalAccessTokenRequest struct {
-	Token string `json:"token"`
-}
-
 type IdentityInfo struct {
 	// Unique identifier (numeric) of the user.
 	UserId uint32 `json:"userId"`
@@ -0,0 +1,55 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package iggcon
+
+import ierror "github.com/apache/messenger/foreign/go/errors"
+
+// KeyHeader is the user header carrying the key of the message, the input of the partitioners
+// and the identity of the entity the message is about, e.g. for compaction.
+const KeyHeader = "messenger-key"
+
+// MaxKeySize is the maximum size of a message key, the one of a user header value.
+const MaxKeySize = 255
+
+// WithKey sets the key of the message, between 1 and MaxKeySize bytes long, in the KeyHeader.
+func WithKey(key []byte) MessengerMessageOpt {
+	return func(m *MessengerMessage) {
+		if err := m.SetKey(key); err != nil && m.err == nil {
+			m.err = err
+		}
+	}
+}
+
+// SetKey sets the key of the message, between 1 and MaxKeySize bytes long, in the KeyHeader.
+func (m *MessengerMessage) SetKey(key []byte) error {
+	if len(key) == 0 || len(key) > MaxKeySize {
+		return ierror.CustomError("invalid_message_key")
+	}
+	return m.SetUserHeaders(map[HeaderKey]HeaderValue{
+		{Value: KeyHeader}: {Kind: Raw, Value: key},
+	})
+}
+
+// Key returns the key of the message, nil when it has none.
+func (m *MessengerMessage) Key() []byte {
+	value, ok := m.UserHeader(KeyHeader)
+	if !ok {
+		return nil
+	}
+	return value.Value
+}
@@ -0,0 +1,82 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package iggcon
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestMessageKey(t *testing.T) {
+	message, err := NewMessengerMessage([]byte("payload"), WithKey([]byte("order-42")), WithDeadline(time.UnixMicro(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key := message.Key(); !bytes.Equal(key, []byte("order-42")) {
+		t.Errorf("expected the key order-42, got %q", key)
+	}
+	if _, ok := MessageDeadline(&message); !ok {
+		t.Error("expected the key to be kept along the other headers")
+	}
+
+	message, err = NewMessengerMessage([]byte("payload"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key := message.Key(); key != nil {
+		t.Errorf("expected no key, got %q", key)
+	}
+	if _, err = NewMessengerMessage([]byte("payload"), WithKey(make([]byte, MaxKeySize+1))); err == nil {
+		t.Error("expected a key longer than MaxKeySize to be rejected")
+	}
+}
+
+func TestWithUserHeaders_KeepsTheHeadersOfTheOtherOptions(t *testing.T) {
+	custom := map[HeaderKey]HeaderValue{{Value: "tenant"}: NewStringHeaderValue("acme")}
+	at := time.UnixMicro(1000)
+	options := []MessengerMessageOpt{WithKey([]byte("order-42")), WithTTL(time.Hour), WithDeadline(at), WithDeliverAt(at)}
+
+	for name, opts := range map[string][]MessengerMessageOpt{
+		"before": append([]MessengerMessageOpt{WithUserHeaders(custom)}, options...),
+		"after":  append(options, WithUserHeaders(custom)),
+	} {
+		message, err := NewMessengerMessage([]byte("payload"), opts...)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if key := message.Key(); !bytes.Equal(key, []byte("order-42")) {
+			t.Errorf("%s: expected the key order-42, got %q", name, key)
+		}
+		if _, ok := MessageExpiresAt(&message); !ok {
+			t.Errorf("%s: expected the expiry to be kept", name)
+		}
+		if deadline, ok := MessageDeadline(&message); !ok || !deadline.Equal(at) {
+			t.Errorf("%s: expected the deadline to be kept, got %v", name, deadline)
+		}
+		if deliverAt, ok := MessageDeliverAt(&message); !ok || !deliverAt.Equal(at) {
+			t.Errorf("%s: expected the delivery time to be kept, got %v", name, deliverAt)
+		}
+		if value, ok := message.UserHeader("tenant"); !ok || string(value.Value) != "acme" {
+			t.Errorf("%s: expected the tenant header, got %v", name, value)
+		}
+		if int(message.Header.UserHeaderLength) != len(message.UserHeaders) {
+			t.Errorf("%s: expected the header length %d, got %d", name, len(message.UserHeaders), message.Header.UserHeaderLength)
+		}
+	}
+}
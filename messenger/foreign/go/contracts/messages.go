@@ -18,6 +18,9 @@
 package iggcon
 
 import (
+	"math"
+	"time"
+
 	ierror "github.com/apache/messenger/foreign/go/errors"
 )
 
@@ -45,14 +48,47 @@ const (
 	MaxUserHeadersSize = 100 * 1000
 )
 
+// PollMessageRequest describes a poll. The zero Consumer polls as the default consumer.
 type PollMessageRequest struct {
-	StreamId        Identifier      `json:"streamId"`
-	TopicId         Identifier      `json:"topicId"`
-	Consumer        Consumer        `json:"consumer"`
+	StreamId Identifier `json:"streamId"`
+	TopicId  Identifier `json:"topicId"`
+	Consumer Consumer   `json:"consumer"`
+	// PartitionId is the partition to poll, 0 for the partition assigned to a consumer group.
 	PartitionId     uint32          `json:"partitionId"`
 	PollingStrategy PollingStrategy `json:"pollingStrategy"`
 	Count           int             `json:"count"`
 	AutoCommit      bool            `json:"autoCommit"`
+	// MaxWait is how long the server holds the poll when there is no message to return.
+	MaxWait time.Duration `json:"maxWait"`
+}
+
+// Partition returns the partition to poll, nil for the partition assigned to a consumer group.
+func (r PollMessageRequest) Partition() *uint32 {
+	if r.PartitionId == 0 {
+		return nil
+	}
+	return &r.PartitionId
+}
+
+// WithDefaults returns the request with the default consumer when none is set.
+func (r PollMessageRequest) WithDefaults() PollMessageRequest {
+	if r.Consumer.Kind == 0 {
+		r.Consumer = DefaultConsumer()
+	}
+	return r
+}
+
+// Validate checks the request before it is sent to the server.
+func (r PollMessageRequest) Validate() error {
+	for _, id := range []Identifier{r.StreamId, r.TopicId, r.Consumer.Id} {
+		if err := id.Validate(); err != nil {
+			return err
+		}
+	}
+	if r.Count <= 0 || r.Count > math.MaxUint32 {
+		return ierror.InvalidMessagesCount
+	}
+	return nil
 }
 
 type PolledMessage struct {
@@ -79,6 +115,10 @@ type MessengerMessage struct {
 	Header      MessageHeader
 	Payload     []byte
 	UserHeaders []byte
+	// explicitTimestamp is set when the origin timestamp was given with WithTimestamp.
+	explicitTimestamp bool
+	// err is the first error of the options, returned by NewMessengerMessage.
+	err error
 }
 
 type MessengerMessageOpt func(message *MessengerMessage)
@@ -104,6 +144,9 @@ func NewMessengerMessage(payload []byte, opts ...MessengerMessageOpt) (Messenger
 			opt(&message)
 		}
 	}
+	if message.err != nil {
+		return MessengerMessage{}, message.err
+	}
 	userHeaderLength := len(message.UserHeaders)
 	if userHeaderLength > MaxUserHeadersSize {
 		return MessengerMessage{}, ierror.TooBigUserHeaders
@@ -118,9 +161,44 @@ func WithID(id [16]byte) MessengerMessageOpt {
 	}
 }
 
+// WithUserHeaders merges the given headers into the user headers of the message, so the headers
+// set by the other options, e.g. WithKey or WithTTL, are kept whatever the order of the options.
 func WithUserHeaders(userHeaders map[HeaderKey]HeaderValue) MessengerMessageOpt {
 	return func(m *MessengerMessage) {
-		userHeaderBytes := GetHeadersBytes(userHeaders)
-		m.UserHeaders = userHeaderBytes
+		if err := m.SetUserHeaders(userHeaders); err != nil && m.err == nil {
+			m.err = err
+		}
+	}
+}
+
+// SetUserHeaders merges the given headers into the user headers of the message,
+// replacing the values of existing keys.
+func (m *MessengerMessage) SetUserHeaders(headers map[HeaderKey]HeaderValue) error {
+	merged, err := DeserializeHeaders(m.UserHeaders)
+	if err != nil {
+		return err
+	}
+	for key, value := range headers {
+		merged[key] = value
+	}
+	userHeaders := GetHeadersBytes(merged)
+	if len(userHeaders) > MaxUserHeadersSize {
+		return ierror.TooBigUserHeaders
+	}
+	m.UserHeaders = userHeaders
+	m.Header.UserHeaderLength = uint32(len(userHeaders))
+	return nil
+}
+
+// UserHeader returns the value of the user header with the given key.
+func (m *MessengerMessage) UserHeader(key string) (HeaderValue, bool) {
+	if len(m.UserHeaders) == 0 {
+		return HeaderValue{}, false
+	}
+	headers, err := DeserializeHeaders(m.UserHeaders)
+	if err != nil {
+		return HeaderValue{}, false
 	}
+	value, ok := headers[HeaderKey{Value: key}]
+	return value, ok
 }
@@ -0,0 +1,94 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package iggcon
+
+import (
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+)
+
+// NameKind is the kind of resource a name is given to.
+type NameKind string
+
+const (
+	StreamName        NameKind = "stream_name"
+	TopicName         NameKind = "topic_name"
+	ConsumerGroupName NameKind = "consumer_group_name"
+)
+
+// NameError describes a name rejected by a NameValidator. Its message starts with the kind and
+// the reason, like topic_name_too_long, matching the errors of the server.
+type NameError struct {
+	Kind NameKind
+	Name string
+	// Reason is empty, too_long or invalid_character.
+	Reason string
+}
+
+func (e *NameError) Error() string {
+	return fmt.Sprintf("%s_%s: %q", e.Kind, e.Reason, e.Name)
+}
+
+// NameValidator checks the names of the streams, topics and consumer groups before they are sent
+// to the server, so invalid names fail right away with a descriptive error. Applications can use
+// it to validate user input the same way.
+type NameValidator struct {
+	// MaxLength is the maximum length of a name in bytes, MaxIdentifierLength when 0.
+	MaxLength int
+	// AllowedCharacter reports whether a name can contain a character. When nil, any character
+	// is allowed except the control characters.
+	AllowedCharacter func(r rune) bool
+}
+
+// DefaultNameValidator accepts the names accepted by the server: between 1 and
+// MaxIdentifierLength bytes of UTF-8, to which this SDK adds the rejection of control characters.
+var DefaultNameValidator = NameValidator{}
+
+// IsSimpleNameCharacter allows the ASCII letters and digits, '.', '_' and '-', a stricter
+// NameValidator.AllowedCharacter for the names which end up in URLs or file paths.
+func IsSimpleNameCharacter(r rune) bool {
+	return r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '.' || r == '_' || r == '-')
+}
+
+// Validate returns a *NameError when the name is invalid.
+func (v NameValidator) Validate(kind NameKind, name string) error {
+	maxLength := v.MaxLength
+	if maxLength == 0 {
+		maxLength = MaxIdentifierLength
+	}
+	if len(name) == 0 {
+		return &NameError{Kind: kind, Name: name, Reason: "empty"}
+	}
+	if len(name) > maxLength {
+		return &NameError{Kind: kind, Name: name, Reason: "too_long"}
+	}
+	if !utf8.ValidString(name) {
+		return &NameError{Kind: kind, Name: name, Reason: "invalid_character"}
+	}
+	for _, r := range name {
+		allowed := !unicode.IsControl(r)
+		if v.AllowedCharacter != nil {
+			allowed = v.AllowedCharacter(r)
+		}
+		if !allowed {
+			return &NameError{Kind: kind, Name: name, Reason: "invalid_character"}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package iggcon
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNameValidator(t *testing.T) {
+	strict := NameValidator{MaxLength: 16, AllowedCharacter: IsSimpleNameCharacter}
+	for _, tt := range []struct {
+		validator NameValidator
+		name      string
+		reason    string
+	}{
+		{DefaultNameValidator, "orders", ""},
+		{DefaultNameValidator, "commandes reçues", ""},
+		{DefaultNameValidator, strings.Repeat("a", MaxIdentifierLength), ""},
+		{DefaultNameValidator, "", "empty"},
+		{DefaultNameValidator, strings.Repeat("a", MaxIdentifierLength+1), "too_long"},
+		{DefaultNameValidator, "line\nbreak", "invalid_character"},
+		{DefaultNameValidator, "\xff", "invalid_character"},
+		{strict, "orders.eu-west_1", ""},
+		{strict, "orders.eu-west-1a", "too_long"},
+		{strict, "reçues", "invalid_character"},
+	} {
+		err := tt.validator.Validate(TopicName, tt.name)
+		if tt.reason == "" {
+			if err != nil {
+				t.Errorf("%q: unexpected error %v", tt.name, err)
+			}
+			continue
+		}
+		var nameErr *NameError
+		if !errors.As(err, &nameErr) || nameErr.Reason != tt.reason {
+			t.Errorf("%q: expected the reason %s, got %v", tt.name, tt.reason, err)
+		}
+	}
+
+	err := DefaultNameValidator.Validate(StreamName, strings.Repeat("a", 256))
+	if !strings.HasPrefix(err.Error(), "stream_name_too_long") {
+		t.Errorf("expected the error of the server, got %v", err)
+	}
+}
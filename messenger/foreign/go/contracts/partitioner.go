@@ -0,0 +1,226 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package iggcon
+
+import (
+	"encoding/binary"
+	"math/bits"
+	"math/rand/v2"
+	"sync/atomic"
+)
+
+// Partitioner computes on the client the partition a message is sent to, so the batches can be
+// sent with PartitionId partitioning. Implementations must be safe for concurrent use.
+type Partitioner interface {
+	// Partition returns the partition id, from 1 to partitionsCount, of a message with the given
+	// key, nil for the messages without a key.
+	Partition(key []byte, partitionsCount uint32) uint32
+}
+
+// PartitionerFunc adapts a function to a Partitioner.
+type PartitionerFunc func(key []byte, partitionsCount uint32) uint32
+
+func (f PartitionerFunc) Partition(key []byte, partitionsCount uint32) uint32 {
+	return f(key, partitionsCount)
+}
+
+// Key returns the key of a MessageKey partitioning, or nil for the other kinds.
+func (p Partitioning) Key() []byte {
+	if p.Kind != MessageKey {
+		return nil
+	}
+	return p.Value
+}
+
+// RoundRobinPartitioner returns a Partitioner spreading the messages evenly over the partitions,
+// ignoring their keys.
+func RoundRobinPartitioner() Partitioner {
+	var next atomic.Uint32
+	return PartitionerFunc(func(_ []byte, partitionsCount uint32) uint32 {
+		if partitionsCount == 0 {
+			return 1
+		}
+		return (next.Add(1)-1)%partitionsCount + 1
+	})
+}
+
+// Murmur2Partitioner returns a Partitioner hashing the keys with murmur2 like the Kafka clients
+// do, so a key lands on the same partition as with a Kafka producer given the same number of
+// partitions. The messages without a key are spread round-robin.
+func Murmur2Partitioner() Partitioner {
+	return hashPartitioner(func(key []byte) uint64 {
+		// same as the toPositive of the Kafka clients
+		return uint64(Murmur2(key) & 0x7fffffff)
+	})
+}
+
+// XXHashPartitioner returns a Partitioner hashing the keys with the 64-bit xxHash of seed 0.
+// The messages without a key are spread round-robin.
+func XXHashPartitioner() Partitioner {
+	return hashPartitioner(func(key []byte) uint64 {
+		return XXHash64(key, 0)
+	})
+}
+
+func hashPartitioner(hash func(key []byte) uint64) Partitioner {
+	roundRobin := RoundRobinPartitioner()
+	return PartitionerFunc(func(key []byte, partitionsCount uint32) uint32 {
+		if key == nil {
+			return roundRobin.Partition(key, partitionsCount)
+		}
+		if partitionsCount == 0 {
+			return 1
+		}
+		return uint32(hash(key)%uint64(partitionsCount)) + 1
+	})
+}
+
+// StickyPartitioner pins the messages without a key to a single partition until Rotate is called,
+// so that they fill up a single batch instead of as many small batches as there are partitions.
+// The keyed messages are spread by the keyed Partitioner.
+type StickyPartitioner struct {
+	keyed Partitioner
+	// sticky is the number of rotations, the pinned partition being sticky modulo the count.
+	sticky atomic.Uint32
+}
+
+// NewStickyPartitioner creates a StickyPartitioner pinned to a random partition, hashing the keys
+// with keyed, Murmur2Partitioner when nil.
+func NewStickyPartitioner(keyed Partitioner) *StickyPartitioner {
+	if keyed == nil {
+		keyed = Murmur2Partitioner()
+	}
+	s := &StickyPartitioner{keyed: keyed}
+	s.sticky.Store(rand.Uint32())
+	return s
+}
+
+func (s *StickyPartitioner) Partition(key []byte, partitionsCount uint32) uint32 {
+	if key != nil {
+		return s.keyed.Partition(key, partitionsCount)
+	}
+	if partitionsCount == 0 {
+		return 1
+	}
+	return s.sticky.Load()%partitionsCount + 1
+}
+
+// Rotate pins the messages without a key to the next partition.
+func (s *StickyPartitioner) Rotate() {
+	s.sticky.Add(1)
+}
+
+// Murmur2 returns the 32-bit murmur2 hash of data, with the seed used by the Kafka clients.
+func Murmur2(data []byte) uint32 {
+	const (
+		seed = 0x9747b28c
+		m    = 0x5bd1e995
+		r    = 24
+	)
+	length := len(data)
+	h := uint32(seed) ^ uint32(length)
+	for ; len(data) >= 4; data = data[4:] {
+		k := binary.LittleEndian.Uint32(data)
+		k *= m
+		k ^= k >> r
+		k *= m
+		h *= m
+		h ^= k
+	}
+	switch len(data) {
+	case 3:
+		h ^= uint32(data[2]) << 16
+		fallthrough
+	case 2:
+		h ^= uint32(data[1]) << 8
+		fallthrough
+	case 1:
+		h ^= uint32(data[0])
+		h *= m
+	}
+	h ^= h >> 13
+	h *= m
+	h ^= h >> 15
+	return h
+}
+
+const (
+	xxPrime1 uint64 = 11400714785074694791
+	xxPrime2 uint64 = 14029467366897019727
+	xxPrime3 uint64 = 1609587929392839161
+	xxPrime4 uint64 = 9650029242287828579
+	xxPrime5 uint64 = 2870177450012600261
+)
+
+// XXHash64 returns the 64-bit xxHash of data with the given seed.
+func XXHash64(data []byte, seed uint64) uint64 {
+	length := uint64(len(data))
+	var h uint64
+	if len(data) >= 32 {
+		v1 := seed + xxPrime1 + xxPrime2
+		v2 := seed + xxPrime2
+		v3 := seed
+		v4 := seed - xxPrime1
+		for ; len(data) >= 32; data = data[32:] {
+			v1 = xxRound(v1, binary.LittleEndian.Uint64(data[0:]))
+			v2 = xxRound(v2, binary.LittleEndian.Uint64(data[8:]))
+			v3 = xxRound(v3, binary.LittleEndian.Uint64(data[16:]))
+			v4 = xxRound(v4, binary.LittleEndian.Uint64(data[24:]))
+		}
+		h = bits.RotateLeft64(v1, 1) + bits.RotateLeft64(v2, 7) + bits.RotateLeft64(v3, 12) + bits.RotateLeft64(v4, 18)
+		h = xxMergeRound(h, v1)
+		h = xxMergeRound(h, v2)
+		h = xxMergeRound(h, v3)
+		h = xxMergeRound(h, v4)
+	} else {
+		h = seed + xxPrime5
+	}
+	h += length
+
+	for ; len(data) >= 8; data = data[8:] {
+		h ^= xxRound(0, binary.LittleEndian.Uint64(data))
+		h = bits.RotateLeft64(h, 27)*xxPrime1 + xxPrime4
+	}
+	if len(data) >= 4 {
+		h ^= uint64(binary.LittleEndian.Uint32(data)) * xxPrime1
+		h = bits.RotateLeft64(h, 23)*xxPrime2 + xxPrime3
+		data = data[4:]
+	}
+	for _, b := range data {
+		h ^= uint64(b) * xxPrime5
+		h = bits.RotateLeft64(h, 11) * xxPrime1
+	}
+
+	h ^= h >> 33
+	h *= xxPrime2
+	h ^= h >> 29
+	h *= xxPrime3
+	h ^= h >> 32
+	return h
+}
+
+func xxRound(acc, input uint64) uint64 {
+	acc += input * xxPrime2
+	acc = bits.RotateLeft64(acc, 31)
+	return acc * xxPrime1
+}
+
+func xxMergeRound(acc, value uint64) uint64 {
+	acc ^= xxRound(0, value)
+	return acc*xxPrime1 + xxPrime4
+}
@@ -0,0 +1,85 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package iggcon
+
+import "testing"
+
+func TestMurmur2_MatchesKafka(t *testing.T) {
+	// the expected values are the ones of the Kafka clients, as signed integers
+	for key, expected := range map[string]int32{
+		"21":                         -973932308,
+		"foobar":                     -790332482,
+		"a-little-bit-long-string":   -985981536,
+		"a-little-bit-longer-string": -1486304829,
+		"lkjh234lh9fiuh90y23oiuhsafujhadof229phr9h19h89h8": -58897971,
+		"abc": 479470107,
+	} {
+		if actual := int32(Murmur2([]byte(key))); actual != expected {
+			t.Errorf("murmur2(%q): expected %d, got %d", key, expected, actual)
+		}
+	}
+}
+
+func TestXXHash64(t *testing.T) {
+	for key, expected := range map[string]uint64{
+		"":    0xEF46DB3751D8E999,
+		"a":   0xD24EC4F1A98C6E5B,
+		"abc": 0x44BC2CF5AD770999,
+	} {
+		if actual := XXHash64([]byte(key), 0); actual != expected {
+			t.Errorf("xxhash64(%q): expected %x, got %x", key, expected, actual)
+		}
+	}
+}
+
+func TestPartitioners(t *testing.T) {
+	partitioner := Murmur2Partitioner()
+	for _, key := range []string{"21", "foobar", "abc"} {
+		first := partitioner.Partition([]byte(key), 7)
+		if first < 1 || first > 7 || partitioner.Partition([]byte(key), 7) != first {
+			t.Errorf("expected key %q to stick to a partition between 1 and 7, got %d", key, first)
+		}
+	}
+	if partition := partitioner.Partition([]byte("21"), 5); partition != uint32((-973932308&0x7fffffff)%5)+1 {
+		t.Errorf("expected the Kafka partition of key 21 shifted to 1-based, got %d", partition)
+	}
+
+	roundRobin := RoundRobinPartitioner()
+	for i, expected := range []uint32{1, 2, 3, 1} {
+		if actual := roundRobin.Partition([]byte("ignored"), 3); actual != expected {
+			t.Errorf("round robin message %d: expected %d, got %d", i, expected, actual)
+		}
+	}
+}
+
+func TestStickyPartitioner(t *testing.T) {
+	sticky := NewStickyPartitioner(nil)
+	pinned := sticky.Partition(nil, 3)
+	for i := 0; i < 10; i++ {
+		if partition := sticky.Partition(nil, 3); partition != pinned {
+			t.Fatalf("expected the messages without a key to stick to partition %d, got %d", pinned, partition)
+		}
+	}
+	sticky.Rotate()
+	if partition := sticky.Partition(nil, 3); partition != pinned%3+1 {
+		t.Errorf("expected the rotation to move from partition %d to the next one, got %d", pinned, partition)
+	}
+	if partition := sticky.Partition([]byte("21"), 5); partition != Murmur2Partitioner().Partition([]byte("21"), 5) {
+		t.Errorf("expected the keyed messages to be hashed, got partition %d", partition)
+	}
+}
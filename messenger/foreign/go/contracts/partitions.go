@@ -33,18 +33,6 @@ type PartitionContract struct {
 	SizeBytes     uint64 `json:"sizeBytes"`
 }
 
-type CreatePartitionsRequest struct {
-	StreamId        Identifier `json:"streamId"`
-	TopicId         Identifier `json:"topicId"`
-	PartitionsCount uint32     `json:"partitionsCount"`
-}
-
-type DeletePartitionsRequest struct {
-	StreamId        Identifier `json:"streamId"`
-	TopicId         Identifier `json:"topicId"`
-	PartitionsCount uint32     `json:"partitionsCount"`
-}
-
 type PartitioningKind int
 
 const (
@@ -17,23 +17,7 @@
 
 package iggcon
 
-type StoreConsumerOffsetRequest struct {
-	StreamId    Identifier `json:"streamId"`
-	TopicId     Identifier `json:"topicId"`
-	Consumer    Consumer   `json:"consumer"`
-	PartitionId *uint32    `json:"partitionId"`
-	Offset      uint64     `json:"offset"`
-}
-
-type GetConsumerOffsetRequest struct {
-	StreamId    Identifier `json:"streamId"`
-	TopicId     Identifier `json:"topicId"`
-	Consumer    Consumer   `json:"consumer"`
-	PartitionId *uint32    `json:"partitionId"`
-}
-
-type ConsumerOffsetInfo struct {
-	PartitionId   uint32 `json:"partitionId"`
-	CurrentOffset uint64 `json:"currentOffset"`
-	StoredOffset  uint64 `json:"storedOffset"`
-}
+// The command codes and the contracts whose wire layout is described in protocol.json are
+// generated, along with their serializers in binary_serialization. Edit protocol.json, then run
+// go generate to update them.
+//go:generate go run ../cmd/protogen -spec protocol.json -contracts . -serializers ../binary_serialization
@@ -0,0 +1,38 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package iggcon
+
+// SendResult describes where the messages of a send request were appended, as reported by the
+// servers providing it.
+type SendResult struct {
+	PartitionId uint32
+	// BaseOffset is the offset of the first message, the others following in order.
+	BaseOffset uint64
+	// Timestamps are the times the messages were appended, in microseconds since the epoch, in order.
+	Timestamps []uint64
+}
+
+// Count returns the number of appended messages.
+func (r *SendResult) Count() int {
+	return len(r.Timestamps)
+}
+
+// Offset returns the offset of the i-th message of the request.
+func (r *SendResult) Offset(i int) uint64 {
+	return r.BaseOffset + uint64(i)
+}
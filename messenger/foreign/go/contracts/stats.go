@@ -40,4 +40,5 @@ type Stats struct {
 	OsName              string  `json:"os_name"`
 	OsVersion           string  `json:"os_version"`
 	KernelVersion       string  `json:"kernel_version"`
+	ServerVersion       string  `json:"server_version,omitempty"`
 }
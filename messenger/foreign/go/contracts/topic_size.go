@@ -0,0 +1,98 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package iggcon
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// MaxTopicSize is the size in bytes a topic is allowed to grow to, the server deleting its oldest
+// segments beyond it. Sizes are written with the unit constants, like 10 * GiB.
+type MaxTopicSize uint64
+
+const (
+	// MaxTopicSizeServerDefault uses the maximum topic size configured on the server.
+	MaxTopicSizeServerDefault MaxTopicSize = 0
+	// MaxTopicSizeUnlimited lets the topic grow without limit.
+	MaxTopicSizeUnlimited MaxTopicSize = math.MaxUint64
+)
+
+const (
+	Byte MaxTopicSize = 1
+	KB                = 1000 * Byte
+	MB                = 1000 * KB
+	GB                = 1000 * MB
+	TB                = 1000 * GB
+	KiB               = 1024 * Byte
+	MiB               = 1024 * KiB
+	GiB               = 1024 * MiB
+	TiB               = 1024 * GiB
+)
+
+var topicSizeUnits = []struct {
+	name string
+	size MaxTopicSize
+}{
+	{"TiB", TiB}, {"GiB", GiB}, {"MiB", MiB}, {"KiB", KiB},
+	{"TB", TB}, {"GB", GB}, {"MB", MB}, {"KB", KB},
+	{"B", Byte},
+}
+
+// ParseMaxTopicSize parses a size like "512 MiB", "10GB" or "1.5 TiB", as well as "unlimited"
+// and "server_default". Sizes without a unit are bytes.
+func ParseMaxTopicSize(s string) (MaxTopicSize, error) {
+	number := strings.TrimSpace(s)
+	switch strings.ToLower(number) {
+	case "unlimited":
+		return MaxTopicSizeUnlimited, nil
+	case "server_default":
+		return MaxTopicSizeServerDefault, nil
+	}
+	unit := Byte
+	for _, u := range topicSizeUnits {
+		if len(number) > len(u.name) && strings.EqualFold(number[len(number)-len(u.name):], u.name) {
+			number, unit = strings.TrimSpace(number[:len(number)-len(u.name)]), u.size
+			break
+		}
+	}
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil || value < 0 || value*float64(unit) >= math.MaxUint64 {
+		return 0, fmt.Errorf("invalid topic size %q", s)
+	}
+	return MaxTopicSize(value * float64(unit)), nil
+}
+
+// String formats the size with the largest binary unit dividing it, like 10GiB.
+func (s MaxTopicSize) String() string {
+	switch s {
+	case MaxTopicSizeServerDefault:
+		return "server_default"
+	case MaxTopicSizeUnlimited:
+		return "unlimited"
+	}
+	// the binary units come first
+	for _, u := range topicSizeUnits[:4] {
+		if s%u.size == 0 {
+			return strconv.FormatUint(uint64(s/u.size), 10) + u.name
+		}
+	}
+	return strconv.FormatUint(uint64(s), 10) + "B"
+}
@@ -17,40 +17,97 @@
 
 package iggcon
 
-import "time"
+import ierror "github.com/apache/messenger/foreign/go/errors"
 
+// CreateTopicRequest describes a topic to create. The zero values of its optional fields select
+// the defaults: a single partition, no compression, and the expiry and size limit of the server.
 type CreateTopicRequest struct {
-	StreamId             Identifier    `json:"streamId"`
-	TopicId              uint32        `json:"topicId"`
-	PartitionsCount      int           `json:"partitionsCount"`
-	CompressionAlgorithm uint8         `json:"compressionAlgorithm"`
-	MessageExpiry        time.Duration `json:"messageExpiry"`
-	MaxTopicSize         uint64        `json:"maxTopicSize"`
-	ReplicationFactor    uint8         `json:"replicationFactor"`
-	Name                 string        `json:"name"`
+	StreamId Identifier `json:"streamId"`
+	Name     string     `json:"name"`
+	// TopicId is the ID of the topic, nil to let the server assign one.
+	TopicId              *uint32              `json:"topicId"`
+	PartitionsCount      uint32               `json:"partitionsCount"`
+	CompressionAlgorithm CompressionAlgorithm `json:"compressionAlgorithm"`
+	MessageExpiry        Expiry               `json:"messageExpiry"`
+	MaxTopicSize         MaxTopicSize         `json:"maxTopicSize"`
+	// ReplicationFactor is the number of replicas of the partitions, nil for the server default.
+	ReplicationFactor *uint8 `json:"replicationFactor"`
 }
 
+// WithDefaults returns the request with the defaults of its optional fields filled in.
+func (r CreateTopicRequest) WithDefaults() CreateTopicRequest {
+	if r.PartitionsCount == 0 {
+		r.PartitionsCount = 1
+	}
+	if r.CompressionAlgorithm == 0 {
+		r.CompressionAlgorithm = CompressionAlgorithmNone
+	}
+	return r
+}
+
+// Validate checks the request before it is sent to the server.
+func (r CreateTopicRequest) Validate() error {
+	if err := r.StreamId.Validate(); err != nil {
+		return err
+	}
+	if r.TopicId != nil && *r.TopicId == 0 {
+		return ierror.InvalidIdentifier
+	}
+	return validateTopic(r.Name, r.CompressionAlgorithm)
+}
+
+// UpdateTopicRequest describes the new settings of a topic, the zero values of its optional
+// fields selecting the same defaults as CreateTopicRequest.
 type UpdateTopicRequest struct {
-	StreamId             Identifier    `json:"streamId"`
-	TopicId              Identifier    `json:"topicId"`
-	CompressionAlgorithm uint8         `json:"compressionAlgorithm"`
-	MessageExpiry        time.Duration `json:"messageExpiry"`
-	MaxTopicSize         uint64        `json:"maxTopicSize"`
-	ReplicationFactor    uint8         `json:"replicationFactor"`
-	Name                 string        `json:"name"`
+	StreamId             Identifier           `json:"streamId"`
+	TopicId              Identifier           `json:"topicId"`
+	Name                 string               `json:"name"`
+	CompressionAlgorithm CompressionAlgorithm `json:"compressionAlgorithm"`
+	MessageExpiry        Expiry               `json:"messageExpiry"`
+	MaxTopicSize         MaxTopicSize         `json:"maxTopicSize"`
+	ReplicationFactor    *uint8               `json:"replicationFactor"`
+}
+
+// WithDefaults returns the request with the defaults of its optional fields filled in.
+func (r UpdateTopicRequest) WithDefaults() UpdateTopicRequest {
+	if r.CompressionAlgorithm == 0 {
+		r.CompressionAlgorithm = CompressionAlgorithmNone
+	}
+	return r
+}
+
+// Validate checks the request before it is sent to the server.
+func (r UpdateTopicRequest) Validate() error {
+	if err := r.StreamId.Validate(); err != nil {
+		return err
+	}
+	if err := r.TopicId.Validate(); err != nil {
+		return err
+	}
+	return validateTopic(r.Name, r.CompressionAlgorithm)
+}
+
+func validateTopic(name string, compressionAlgorithm CompressionAlgorithm) error {
+	if err := DefaultNameValidator.Validate(TopicName, name); err != nil {
+		return err
+	}
+	if compressionAlgorithm != CompressionAlgorithmNone && compressionAlgorithm != CompressionAlgorithmGzip {
+		return ierror.CustomError("invalid_compression_algorithm")
+	}
+	return nil
 }
 
 type Topic struct {
-	Id                   uint32   `json:"id"`
-	CreatedAt            uint64   `json:"createdAt"`
-	Name                 string   `json:"name"`
-	Size                 uint64   `json:"size"`
-	MessageExpiry        Duration `json:"messageExpiry"`
-	CompressionAlgorithm uint8    `json:"compressionAlgorithm"`
-	MaxTopicSize         uint64   `json:"maxTopicSize"`
-	ReplicationFactor    uint8    `json:"replicationFactor"`
-	MessagesCount        uint64   `json:"messagesCount"`
-	PartitionsCount      uint32   `json:"partitionsCount"`
+	Id                   uint32       `json:"id"`
+	CreatedAt            uint64       `json:"createdAt"`
+	Name                 string       `json:"name"`
+	Size                 uint64       `json:"size"`
+	MessageExpiry        Expiry       `json:"messageExpiry"`
+	CompressionAlgorithm uint8        `json:"compressionAlgorithm"`
+	MaxTopicSize         MaxTopicSize `json:"maxTopicSize"`
+	ReplicationFactor    uint8        `json:"replicationFactor"`
+	MessagesCount        uint64       `json:"messagesCount"`
+	PartitionsCount      uint32       `json:"partitionsCount"`
 }
 
 type TopicDetails struct {
@@ -0,0 +1,83 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package iggcon
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExpiry(t *testing.T) {
+	for _, tt := range []struct {
+		expiry   Expiry
+		expected Expiry
+		text     string
+	}{
+		{ExpiryAfter(time.Hour), Expiry(3_600_000_000), "1h0m0s"},
+		{ExpiryAfter(1500 * time.Nanosecond), Expiry(2), "2µs"},
+		{ExpiryAfter(0), Expiry(1), "1µs"},
+		{ExpiryServerDefault, Expiry(0), "server_default"},
+		{ExpiryNever, Expiry(1<<64 - 1), "never"},
+	} {
+		if tt.expiry != tt.expected {
+			t.Errorf("expected %d, got %d", tt.expected, tt.expiry)
+		}
+		if tt.expiry.String() != tt.text {
+			t.Errorf("expected %q, got %q", tt.text, tt.expiry.String())
+		}
+	}
+	if d := ExpiryAfter(time.Minute).Duration(); d != time.Minute {
+		t.Errorf("expected 1m, got %v", d)
+	}
+}
+
+func TestParseMaxTopicSize(t *testing.T) {
+	for text, expected := range map[string]MaxTopicSize{
+		"10 GiB":         10 * GiB,
+		"10GB":           10 * GB,
+		"1.5 tib":        TiB + TiB/2,
+		"512MiB":         512 * MiB,
+		"4096":           4 * KiB,
+		"100 B":          100 * Byte,
+		"unlimited":      MaxTopicSizeUnlimited,
+		"server_default": MaxTopicSizeServerDefault,
+	} {
+		size, err := ParseMaxTopicSize(text)
+		if err != nil {
+			t.Errorf("%q: %v", text, err)
+		} else if size != expected {
+			t.Errorf("%q: expected %d, got %d", text, expected, size)
+		}
+	}
+	for _, text := range []string{"", "GiB", "-1 MiB", "ten GB", "20000000 TiB"} {
+		if _, err := ParseMaxTopicSize(text); err == nil {
+			t.Errorf("%q: expected an error", text)
+		}
+	}
+
+	for size, expected := range map[MaxTopicSize]string{
+		10 * GiB:              "10GiB",
+		1536 * MiB:            "1536MiB",
+		10*GB + 1:             "10000000001B",
+		MaxTopicSizeUnlimited: "unlimited",
+	} {
+		if size.String() != expected {
+			t.Errorf("expected %q, got %q", expected, size.String())
+		}
+	}
+}
@@ -0,0 +1,56 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package iggcon
+
+import "time"
+
+// ExpiresAtHeader is the user header carrying the time, in Unix microseconds, after which the
+// message is stale and must not be processed anymore.
+const ExpiresAtHeader = "messenger-expires-at"
+
+// WithTTL makes the message expire ttl after now, in the ExpiresAtHeader. Unlike the Expiry of
+// a topic deleting the messages on the server, the TTL is honored by the consumers, which drop
+// the expired messages they poll.
+func WithTTL(ttl time.Duration) MessengerMessageOpt {
+	return WithExpiresAt(time.Now().Add(ttl))
+}
+
+// WithExpiresAt makes the message expire at the given time, in the ExpiresAtHeader.
+func WithExpiresAt(expiresAt time.Time) MessengerMessageOpt {
+	return func(m *MessengerMessage) {
+		err := m.SetUserHeaders(map[HeaderKey]HeaderValue{
+			{Value: ExpiresAtHeader}: NewUint64HeaderValue(uint64(expiresAt.UnixMicro())),
+		})
+		if err != nil && m.err == nil {
+			m.err = err
+		}
+	}
+}
+
+// MessageExpiresAt returns the time the message expires at, if it has a TTL.
+func MessageExpiresAt(message *MessengerMessage) (time.Time, bool) {
+	value, ok := message.UserHeader(ExpiresAtHeader)
+	if !ok {
+		return time.Time{}, false
+	}
+	micros, err := value.AsUint64()
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.UnixMicro(int64(micros)), true
+}
@@ -20,6 +20,7 @@ package iggcon
 import (
 	"encoding/binary"
 	"errors"
+	"fmt"
 )
 
 type HeaderValue struct {
@@ -58,6 +59,34 @@ const (
 	Double  HeaderKind = 15
 )
 
+// NewStringHeaderValue creates a header value of kind String.
+func NewStringHeaderValue(value string) HeaderValue {
+	return HeaderValue{Kind: String, Value: []byte(value)}
+}
+
+// NewUint64HeaderValue creates a header value of kind Uint64.
+func NewUint64HeaderValue(value uint64) HeaderValue {
+	bytes := make([]byte, 8)
+	binary.LittleEndian.PutUint64(bytes, value)
+	return HeaderValue{Kind: Uint64, Value: bytes}
+}
+
+// AsString returns the value of a String header.
+func (h HeaderValue) AsString() (string, error) {
+	if h.Kind != String {
+		return "", fmt.Errorf("header value of kind %d is not a string", h.Kind)
+	}
+	return string(h.Value), nil
+}
+
+// AsUint64 returns the value of an Uint64 header.
+func (h HeaderValue) AsUint64() (uint64, error) {
+	if h.Kind != Uint64 || len(h.Value) != 8 {
+		return 0, fmt.Errorf("header value of kind %d is not an uint64", h.Kind)
+	}
+	return binary.LittleEndian.Uint64(h.Value), nil
+}
+
 func GetHeadersBytes(headers map[HeaderKey]HeaderValue) []byte {
 	headersLength := 0
 	for key, header := range headers {
@@ -0,0 +1,55 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package iggcon
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	ierror "github.com/apache/messenger/foreign/go/errors"
+)
+
+// nearlyFullHeaders returns headers leaving less room than any header set by an option.
+func nearlyFullHeaders() map[HeaderKey]HeaderValue {
+	headers := make(map[HeaderKey]HeaderValue)
+	size := 0
+	for i := 0; ; i++ {
+		key, value := HeaderKey{Value: fmt.Sprintf("filler-%d", i)}, NewStringHeaderValue("x")
+		size += len(GetHeadersBytes(map[HeaderKey]HeaderValue{key: value}))
+		if size > MaxUserHeadersSize {
+			return headers
+		}
+		headers[key] = value
+	}
+}
+
+func TestHeaderOptions_ReportOversizedHeaders(t *testing.T) {
+	for name, option := range map[string]MessengerMessageOpt{
+		"deadline":   WithDeadline(time.Now()),
+		"ttl":        WithTTL(time.Hour),
+		"expires at": WithExpiresAt(time.Now()),
+		"deliver at": WithDeliverAt(time.Now()),
+	} {
+		_, err := NewMessengerMessage([]byte("payload"), WithUserHeaders(nearlyFullHeaders()), option)
+		if !errors.Is(err, ierror.TooBigUserHeaders) {
+			t.Errorf("%s: expected the oversized headers to be reported, got %v", name, err)
+		}
+	}
+}
@@ -17,12 +17,6 @@
 
 package iggcon
 
-type ChangePasswordRequest struct {
-	UserID          Identifier `json:"-"`
-	CurrentPassword string     `json:"CurrentPassword"`
-	NewPassword     string     `json:"NewPassword"`
-}
-
 type UpdatePermissionsRequest struct {
 	UserID      Identifier   `json:"-"`
 	Permissions *Permissions `json:"Permissions,omitempty"`
@@ -0,0 +1,173 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config is the configuration of the gateway, read from a JSON file.
+type Config struct {
+	MQTT      MQTTConfig      `json:"mqtt"`
+	Messenger MessengerConfig `json:"messenger"`
+	Routes    []Route         `json:"routes"`
+}
+
+type MQTTConfig struct {
+	// Address is the host:port of the broker.
+	Address  string `json:"address"`
+	ClientId string `json:"clientId"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	// CleanSession discards the QoS 1 messages which were not acknowledged when the gateway
+	// disconnects, instead of receiving them again once reconnected.
+	CleanSession bool `json:"cleanSession,omitempty"`
+	// KeepAlive is the interval of the pings, 30s by default.
+	KeepAlive Duration `json:"keepAlive,omitempty"`
+}
+
+type MessengerConfig struct {
+	Address  string `json:"address"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// Route maps the MQTT topics matching a filter to a Messenger topic. The stream, the topic and
+// the key are templates in which {1}, {2}... are replaced by the levels matched by the '+'
+// wildcards of the filter, in order, and {#} by the levels matched by its '#' wildcard.
+type Route struct {
+	Filter string `json:"filter"`
+	// QoS is the maximum QoS of the subscription, 0 or 1.
+	QoS    byte   `json:"qos"`
+	Stream string `json:"stream"`
+	Topic  string `json:"topic"`
+	// Key, when set, is the key of the messages, so the messages of a device, say, are kept in
+	// order on a partition.
+	Key string `json:"key,omitempty"`
+}
+
+// Duration is a time.Duration written as a string like "30s" in JSON.
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// LoadConfig reads and validates the configuration file.
+func LoadConfig(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+	config := Config{
+		MQTT:      MQTTConfig{Address: "127.0.0.1:1883", ClientId: "messenger-mqtt-gateway", KeepAlive: Duration(30 * time.Second)},
+		Messenger: MessengerConfig{Address: "127.0.0.1:8090", Username: "messenger", Password: "messenger"},
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return Config{}, err
+	}
+	return config, config.Validate()
+}
+
+// Validate checks the routes.
+func (c Config) Validate() error {
+	if len(c.Routes) == 0 {
+		return errors.New("no route configured")
+	}
+	for _, route := range c.Routes {
+		if err := validateFilter(route.Filter); err != nil {
+			return err
+		}
+		if route.QoS > 1 {
+			return fmt.Errorf("route %s: the QoS must be 0 or 1", route.Filter)
+		}
+		if route.Stream == "" || route.Topic == "" {
+			return fmt.Errorf("route %s: the stream and the topic are required", route.Filter)
+		}
+	}
+	return nil
+}
+
+func validateFilter(filter string) error {
+	levels := strings.Split(filter, "/")
+	for i, level := range levels {
+		if level == "#" && i != len(levels)-1 || level != "#" && level != "+" && strings.ContainsAny(level, "#+") {
+			return fmt.Errorf("invalid topic filter %q", filter)
+		}
+	}
+	if filter == "" {
+		return errors.New("empty topic filter")
+	}
+	return nil
+}
+
+// match returns the levels of the topic matched by the wildcards of the filter, the one of '#'
+// last, and whether the topic matches.
+func match(filter, topic string) ([]string, bool) {
+	filterLevels := strings.Split(filter, "/")
+	topicLevels := strings.Split(topic, "/")
+	var wildcards []string
+	for i, level := range filterLevels {
+		if level == "#" {
+			return append(wildcards, strings.Join(topicLevels[i:], "/")), true
+		}
+		if i >= len(topicLevels) {
+			return nil, false
+		}
+		if level == "+" {
+			wildcards = append(wildcards, topicLevels[i])
+		} else if level != topicLevels[i] {
+			return nil, false
+		}
+	}
+	return wildcards, len(filterLevels) == len(topicLevels)
+}
+
+// expand replaces the placeholders of the template by the matched levels.
+func expand(template string, filter string, wildcards []string) string {
+	plus := 0
+	for _, level := range strings.Split(filter, "/") {
+		switch level {
+		case "+":
+			plus++
+			template = strings.ReplaceAll(template, "{"+strconv.Itoa(plus)+"}", wildcards[plus-1])
+		case "#":
+			template = strings.ReplaceAll(template, "{#}", wildcards[len(wildcards)-1])
+		}
+	}
+	return template
+}
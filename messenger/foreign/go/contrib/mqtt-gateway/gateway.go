@@ -0,0 +1,260 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengercli"
+	"github.com/apache/messenger/foreign/go/producer"
+)
+
+const (
+	// TopicHeader is the user header carrying the MQTT topic of a message.
+	TopicHeader = "mqtt-topic"
+	// ackInterval is how often the QoS 1 messages are acknowledged, once the producers flushed them.
+	ackInterval = 100 * time.Millisecond
+	// maxReconnectBackoff bounds the pause before reconnecting to the broker.
+	maxReconnectBackoff = 30 * time.Second
+)
+
+// errDeliveryFailed ends a session when a message failed to be delivered, so the broker sends
+// the messages which were not acknowledged again.
+var errDeliveryFailed = errors.New("failed to deliver messages to messenger")
+
+// Gateway forwards the MQTT messages to the Messenger topics of their routes, with a Producer per
+// topic batching the messages in the background.
+type Gateway struct {
+	client messengercli.Client
+	routes []Route
+
+	mtx       sync.Mutex
+	producers map[[2]string]*producer.Producer
+	// failures counts the messages the producers failed to deliver.
+	failures atomic.Int64
+}
+
+func NewGateway(client messengercli.Client, routes []Route) *Gateway {
+	return &Gateway{
+		client:    client,
+		routes:    routes,
+		producers: map[[2]string]*producer.Producer{},
+	}
+}
+
+// Run forwards the messages of the broker until ctx is done, reconnecting with a backoff when
+// the connection fails.
+func (g *Gateway) Run(ctx context.Context, config MQTTConfig) error {
+	backoff := time.Second
+	for {
+		started := time.Now()
+		err := g.session(ctx, config)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if time.Since(started) > maxReconnectBackoff {
+			backoff = time.Second
+		}
+		log.Printf("[WARN] mqtt session ended: %v, reconnecting in %s", err, backoff)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, maxReconnectBackoff)
+	}
+}
+
+// session subscribes to the filters of the routes and forwards the messages until the connection
+// fails or ctx is done. The QoS 1 messages are acknowledged once the producers sent them.
+func (g *Gateway) session(ctx context.Context, config MQTTConfig) error {
+	conn, err := dialMQTT(config)
+	if err != nil {
+		return err
+	}
+	subscriptions := make([]Subscription, 0, len(g.routes))
+	for _, route := range g.routes {
+		subscriptions = append(subscriptions, Subscription{Filter: route.Filter, QoS: route.QoS})
+	}
+	if err := conn.subscribe(subscriptions); err != nil {
+		conn.close()
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	var ackErr error
+	var mtx sync.Mutex
+	var pending []Publish
+	acked := make(chan struct{})
+	go func() {
+		defer close(acked)
+		// closing the connection stops receive
+		defer conn.close()
+		ackErr = g.acknowledge(ctx, conn, &mtx, &pending)
+	}()
+
+	err = conn.receive(time.Duration(config.KeepAlive), func(publish Publish) error {
+		if err := g.Forward(ctx, publish); err != nil {
+			return err
+		}
+		if publish.QoS > 0 {
+			mtx.Lock()
+			pending = append(pending, publish)
+			mtx.Unlock()
+		}
+		return nil
+	})
+	cancel()
+	<-acked
+	return errors.Join(err, ackErr)
+}
+
+// acknowledge acknowledges the pending QoS 1 messages every ackInterval, once the producers sent
+// them, until ctx is done or a message failed to be delivered.
+func (g *Gateway) acknowledge(ctx context.Context, conn *mqttConn, mtx *sync.Mutex, pending *[]Publish) error {
+	failures := g.failures.Load()
+	ticker := time.NewTicker(ackInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		mtx.Lock()
+		publishes := *pending
+		*pending = nil
+		mtx.Unlock()
+		if len(publishes) == 0 {
+			continue
+		}
+		if err := g.Flush(ctx); err != nil {
+			return err
+		}
+		if g.failures.Load() != failures {
+			return errDeliveryFailed
+		}
+		for _, publish := range publishes {
+			if err := conn.ack(publish); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Forward enqueues the message to the Messenger topic of the first route matching its topic.
+// The messages without a route or without payload are dropped.
+func (g *Gateway) Forward(ctx context.Context, publish Publish) error {
+	for _, route := range g.routes {
+		wildcards, ok := match(route.Filter, publish.Topic)
+		if !ok {
+			continue
+		}
+		if len(publish.Payload) == 0 {
+			log.Printf("[WARN] dropping the empty message of %s", publish.Topic)
+			return nil
+		}
+		opts := []iggcon.MessengerMessageOpt{
+			iggcon.WithUserHeaders(map[iggcon.HeaderKey]iggcon.HeaderValue{
+				{Value: TopicHeader}: iggcon.NewStringHeaderValue(publish.Topic),
+			}),
+		}
+		if route.Key != "" {
+			if key := expand(route.Key, route.Filter, wildcards); key != "" {
+				opts = append(opts, iggcon.WithKey([]byte(key)))
+			}
+		}
+		message, err := iggcon.NewMessengerMessage(publish.Payload, opts...)
+		if err != nil {
+			log.Printf("[WARN] dropping the message of %s: %v", publish.Topic, err)
+			return nil
+		}
+		p, err := g.producer(expand(route.Stream, route.Filter, wildcards), expand(route.Topic, route.Filter, wildcards))
+		if err != nil {
+			return err
+		}
+		return p.Send(ctx, message)
+	}
+	log.Printf("[WARN] dropping the message of %s without route", publish.Topic)
+	return nil
+}
+
+// producer returns the producer of the stream and topic, created on first use.
+func (g *Gateway) producer(stream, topic string) (*producer.Producer, error) {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+	key := [2]string{stream, topic}
+	if p, ok := g.producers[key]; ok {
+		return p, nil
+	}
+	streamId, err := iggcon.NewIdentifier(stream)
+	if err != nil {
+		return nil, err
+	}
+	topicId, err := iggcon.NewIdentifier(topic)
+	if err != nil {
+		return nil, err
+	}
+	p, err := producer.NewProducer(g.client, streamId, topicId,
+		producer.WithPartitioner(iggcon.Murmur2Partitioner(), nil),
+		producer.WithErrorHandler(func(err error, messages []iggcon.MessengerMessage) {
+			g.failures.Add(int64(len(messages)))
+			log.Printf("[WARN] failed to deliver %d message(s) to %s/%s: %v", len(messages), stream, topic, err)
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	g.producers[key] = p
+	return p, nil
+}
+
+// Flush waits until the producers sent the messages enqueued so far.
+func (g *Gateway) Flush(ctx context.Context) error {
+	for _, p := range g.snapshot() {
+		if err := p.Flush(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes the producers, sending the messages they queued.
+func (g *Gateway) Close(ctx context.Context) error {
+	var errs []error
+	for _, p := range g.snapshot() {
+		errs = append(errs, p.Close(ctx))
+	}
+	return errors.Join(errs...)
+}
+
+func (g *Gateway) snapshot() []*producer.Producer {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+	producers := make([]*producer.Producer, 0, len(g.producers))
+	for _, p := range g.producers {
+		producers = append(producers, p)
+	}
+	return producers
+}
@@ -0,0 +1,146 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengertest"
+)
+
+// fakeBroker accepts a single connection, publishes the messages once subscribed and returns
+// the identifiers of the acknowledged ones.
+func fakeBroker(t *testing.T, publishes []Publish) (string, <-chan uint16) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	acks := make(chan uint16, len(publishes))
+	go func() {
+		netConn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		conn := &mqttConn{conn: netConn, reader: bufio.NewReader(netConn)}
+		defer conn.conn.Close()
+		if header, _, err := conn.read(); err != nil || header>>4 != packetConnect {
+			t.Errorf("expected a CONNECT packet, got %d (%v)", header>>4, err)
+			return
+		}
+		conn.write(packetConnack<<4, []byte{0, 0})
+		if header, _, err := conn.read(); err != nil || header>>4 != packetSubscribe {
+			t.Errorf("expected a SUBSCRIBE packet, got %d (%v)", header>>4, err)
+			return
+		}
+		conn.write(packetSuback<<4, []byte{0, 1, 1, 0})
+		for _, publish := range publishes {
+			body := appendString(nil, publish.Topic)
+			if publish.QoS > 0 {
+				body = binary.BigEndian.AppendUint16(body, publish.id)
+			}
+			conn.write(packetPublish<<4|publish.QoS<<1, append(body, publish.Payload...))
+		}
+		for {
+			header, body, err := conn.read()
+			if err != nil {
+				return
+			}
+			if header>>4 == packetPuback {
+				acks <- binary.BigEndian.Uint16(body)
+			}
+		}
+	}()
+	return listener.Addr().String(), acks
+}
+
+func TestGateway(t *testing.T) {
+	client := messengertest.NewClient()
+	if _, err := client.CreateStream("iot", nil); err != nil {
+		t.Fatal(err)
+	}
+	streamId := iggcon.MustIdentifier("iot")
+	for _, topic := range []string{"temperature", "events"} {
+		if _, err := client.CreateTopic(streamId, topic, 2, iggcon.CompressionAlgorithmNone, 0, 0, nil, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	config := Config{Routes: []Route{
+		{Filter: "sensors/+/temperature", QoS: 1, Stream: "iot", Topic: "temperature", Key: "{1}"},
+		{Filter: "sites/+/#", Stream: "iot", Topic: "{#}"},
+	}}
+	if err := config.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	address, acks := fakeBroker(t, []Publish{
+		{Topic: "sensors/device-1/temperature", QoS: 1, id: 7, Payload: []byte("21.5")},
+		{Topic: "sites/paris/events", Payload: []byte("door opened")},
+		{Topic: "sensors/device-1/humidity", Payload: []byte("40")},
+		{Topic: "sensors/device-1/temperature", QoS: 1, id: 8, Payload: []byte("21.7")},
+	})
+
+	gateway := NewGateway(client, config.Routes)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- gateway.Run(ctx, MQTTConfig{Address: address, ClientId: "test"})
+	}()
+	for _, expected := range []uint16{7, 8} {
+		select {
+		case id := <-acks:
+			if id != expected {
+				t.Fatalf("expected the acknowledgement of message %d, got %d", expected, id)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for the acknowledgements")
+		}
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if err := gateway.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	expectedPartition := iggcon.Murmur2Partitioner().Partition([]byte("device-1"), 2)
+	polled, err := client.PollMessages(streamId, iggcon.MustIdentifier("temperature"), iggcon.DefaultConsumer(), iggcon.FirstPollingStrategy(), 10, false, &expectedPartition)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(polled.Messages) != 2 || string(polled.Messages[1].Payload) != "21.7" || string(polled.Messages[1].Key()) != "device-1" {
+		t.Fatalf("expected the temperatures of device-1 in order, got %+v", polled.Messages)
+	}
+	topic, _ := polled.Messages[0].UserHeader(TopicHeader)
+	if value, _ := topic.AsString(); value != "sensors/device-1/temperature" {
+		t.Fatalf("expected the MQTT topic in the headers, got %q", value)
+	}
+	events, err := client.GetTopic(streamId, iggcon.MustIdentifier("events"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if events.MessagesCount != 1 {
+		t.Fatalf("expected the event to be forwarded, got %d message(s)", events.MessagesCount)
+	}
+}
@@ -0,0 +1,76 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Command mqtt-gateway ingests the messages of an MQTT broker into Messenger topics, as an
+// example of IoT ingestion with the producer package. The routes of its JSON configuration map
+// the MQTT topic filters to the Messenger streams and topics, see config.example.json.
+//
+//	mqtt-gateway -config config.json
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/apache/messenger/foreign/go/messengercli"
+	"github.com/apache/messenger/foreign/go/tcp"
+)
+
+// closeTimeout bounds the time spent sending the queued messages on shutdown.
+const closeTimeout = 10 * time.Second
+
+func main() {
+	configPath := flag.String("config", "config.json", "configuration file")
+	flag.Parse()
+
+	config, err := LoadConfig(*configPath)
+	if err != nil {
+		fail(err)
+	}
+	cli, err := messengercli.NewMessengerClient(messengercli.WithTcp(tcp.WithServerAddress(config.Messenger.Address)))
+	if err != nil {
+		fail(err)
+	}
+	if _, err = cli.LoginUser(config.Messenger.Username, config.Messenger.Password); err != nil {
+		fail(err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	gateway := NewGateway(cli, config.Routes)
+	log.Printf("forwarding %d route(s) from %s to %s", len(config.Routes), config.MQTT.Address, config.Messenger.Address)
+	if err = gateway.Run(ctx, config.MQTT); err != nil {
+		fail(err)
+	}
+
+	closeCtx, cancel := context.WithTimeout(context.Background(), closeTimeout)
+	defer cancel()
+	if err = gateway.Close(closeCtx); err != nil {
+		fail(err)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "error:", err)
+	os.Exit(1)
+}
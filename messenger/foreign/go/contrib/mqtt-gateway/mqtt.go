@@ -0,0 +1,275 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// The MQTT 3.1.1 packet types used by the gateway.
+const (
+	packetConnect     = 1
+	packetConnack     = 2
+	packetPublish     = 3
+	packetPuback      = 4
+	packetSubscribe   = 8
+	packetSuback      = 9
+	packetPingreq     = 12
+	packetPingresp    = 13
+	packetDisconnect  = 14
+	subscribeFailure  = 0x80
+	maxRemainingBytes = 4
+)
+
+// Subscription is a topic filter subscribed to with a maximum QoS of 0 or 1.
+type Subscription struct {
+	Filter string
+	QoS    byte
+}
+
+// Publish is a message received from the broker.
+type Publish struct {
+	Topic   string
+	Payload []byte
+	QoS     byte
+	Retain  bool
+	// id is the packet identifier the QoS 1 messages are acknowledged with.
+	id uint16
+}
+
+// mqttConn is a connection to an MQTT 3.1.1 broker, subscribing to topics and receiving their
+// messages. It implements the part of the protocol the gateway needs, the messages with QoS 2
+// being received with QoS 1 at most.
+type mqttConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+	// writeMtx serializes the packets written by the reader, the acknowledgements and the pings.
+	writeMtx sync.Mutex
+}
+
+// dialMQTT connects and authenticates to the broker.
+func dialMQTT(config MQTTConfig) (*mqttConn, error) {
+	conn, err := net.DialTimeout("tcp", config.Address, 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	c := &mqttConn{conn: conn, reader: bufio.NewReader(conn)}
+	if err := c.connect(config); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *mqttConn) connect(config MQTTConfig) error {
+	var flags byte
+	if config.CleanSession {
+		flags |= 0x02
+	}
+	if config.Username != "" {
+		flags |= 0x80
+		if config.Password != "" {
+			flags |= 0x40
+		}
+	}
+	body := appendString(nil, "MQTT")
+	body = append(body, 4, flags)
+	body = binary.BigEndian.AppendUint16(body, uint16(time.Duration(config.KeepAlive)/time.Second))
+	body = appendString(body, config.ClientId)
+	if config.Username != "" {
+		body = appendString(body, config.Username)
+		if config.Password != "" {
+			body = appendString(body, config.Password)
+		}
+	}
+	if err := c.write(packetConnect<<4, body); err != nil {
+		return err
+	}
+
+	c.conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+	defer c.conn.SetReadDeadline(time.Time{})
+	header, body, err := c.read()
+	if err != nil {
+		return err
+	}
+	if header>>4 != packetConnack || len(body) != 2 {
+		return errors.New("mqtt: expected a CONNACK packet")
+	}
+	if code := body[1]; code != 0 {
+		return fmt.Errorf("mqtt: connection refused with code %d", code)
+	}
+	return nil
+}
+
+// subscribe subscribes to the topic filters, the SUBACK being read by receive.
+func (c *mqttConn) subscribe(subscriptions []Subscription) error {
+	body := binary.BigEndian.AppendUint16(nil, 1)
+	for _, subscription := range subscriptions {
+		body = appendString(body, subscription.Filter)
+		body = append(body, min(subscription.QoS, 1))
+	}
+	return c.write(packetSubscribe<<4|0x02, body)
+}
+
+// receive reads the packets until the connection fails, calling handle with every message. The
+// pings keep the connection alive every keepAlive.
+func (c *mqttConn) receive(keepAlive time.Duration, handle func(Publish) error) error {
+	if keepAlive > 0 {
+		stop := make(chan struct{})
+		defer close(stop)
+		go c.ping(keepAlive, stop)
+	}
+	for {
+		header, body, err := c.read()
+		if err != nil {
+			return err
+		}
+		switch header >> 4 {
+		case packetPublish:
+			publish, err := parsePublish(header, body)
+			if err != nil {
+				return err
+			}
+			if err := handle(publish); err != nil {
+				return err
+			}
+		case packetSuback:
+			for _, code := range body[min(2, len(body)):] {
+				if code == subscribeFailure {
+					return errors.New("mqtt: subscription refused")
+				}
+			}
+		case packetPingresp:
+		default:
+			return fmt.Errorf("mqtt: unexpected packet type %d", header>>4)
+		}
+	}
+}
+
+func (c *mqttConn) ping(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if err := c.write(packetPingreq<<4, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// ack acknowledges a QoS 1 message.
+func (c *mqttConn) ack(publish Publish) error {
+	if publish.QoS == 0 {
+		return nil
+	}
+	return c.write(packetPuback<<4, binary.BigEndian.AppendUint16(nil, publish.id))
+}
+
+// close disconnects from the broker.
+func (c *mqttConn) close() error {
+	c.write(packetDisconnect<<4, nil)
+	return c.conn.Close()
+}
+
+func (c *mqttConn) write(header byte, body []byte) error {
+	packet := []byte{header}
+	packet = appendRemainingLength(packet, len(body))
+	packet = append(packet, body...)
+	c.writeMtx.Lock()
+	defer c.writeMtx.Unlock()
+	_, err := c.conn.Write(packet)
+	return err
+}
+
+func (c *mqttConn) read() (byte, []byte, error) {
+	header, err := c.reader.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	length, multiplier := 0, 1
+	for i := 0; ; i++ {
+		if i == maxRemainingBytes {
+			return 0, nil, errors.New("mqtt: malformed remaining length")
+		}
+		b, err := c.reader.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		length += int(b&0x7f) * multiplier
+		if b&0x80 == 0 {
+			break
+		}
+		multiplier *= 128
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(c.reader, body); err != nil {
+		return 0, nil, err
+	}
+	return header, body, nil
+}
+
+func parsePublish(header byte, body []byte) (Publish, error) {
+	publish := Publish{QoS: header >> 1 & 0x03, Retain: header&0x01 != 0}
+	if len(body) < 2 {
+		return Publish{}, errors.New("mqtt: malformed PUBLISH packet")
+	}
+	topicLength := int(binary.BigEndian.Uint16(body))
+	body = body[2:]
+	if len(body) < topicLength {
+		return Publish{}, errors.New("mqtt: malformed PUBLISH packet")
+	}
+	publish.Topic, body = string(body[:topicLength]), body[topicLength:]
+	if publish.QoS > 0 {
+		if len(body) < 2 {
+			return Publish{}, errors.New("mqtt: malformed PUBLISH packet")
+		}
+		publish.id, body = binary.BigEndian.Uint16(body), body[2:]
+	}
+	publish.Payload = body
+	return publish, nil
+}
+
+func appendString(b []byte, s string) []byte {
+	b = binary.BigEndian.AppendUint16(b, uint16(len(s)))
+	return append(b, s...)
+}
+
+func appendRemainingLength(b []byte, length int) []byte {
+	for {
+		digit := byte(length % 128)
+		length /= 128
+		if length > 0 {
+			digit |= 0x80
+		}
+		b = append(b, digit)
+		if length == 0 {
+			return b
+		}
+	}
+}
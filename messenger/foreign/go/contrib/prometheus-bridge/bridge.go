@@ -0,0 +1,227 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengercli"
+	"github.com/apache/messenger/foreign/go/producer"
+	"github.com/google/uuid"
+)
+
+const (
+	// MetricHeader is the user header carrying the metric name of a series, its __name__ label.
+	MetricHeader = "prometheus-metric"
+	// maxSamplesPerMessage bounds the samples of a series sent in a single message.
+	maxSamplesPerMessage = 1000
+	// writeRequestProto is the protobuf message of the remote-write 1.0 protocol.
+	writeRequestProto = "prometheus.WriteRequest"
+)
+
+// seriesNamespace derives the message ids from the payloads, so the series of a request which
+// Prometheus sends again get the same ids, deduplicated by the topics doing so.
+var seriesNamespace = uuid.MustParse("b7d4e1a9-2c6f-4e83-9a15-6f0c3d8b2e47")
+
+// Stats counts the remote-write requests and the samples they carried.
+type Stats struct {
+	// Requests is the number of requests answered successfully.
+	Requests int64
+	// Rejected is the number of malformed requests, answered with a 4xx status Prometheus does
+	// not retry.
+	Rejected int64
+	// Failed is the number of requests whose samples failed to be sent, answered with a 5xx
+	// status so Prometheus sends them again.
+	Failed int64
+	// Series and Samples count the series and the samples of the successful requests.
+	Series  int64
+	Samples int64
+	// Undelivered is the number of messages the producer failed to deliver.
+	Undelivered int64
+}
+
+// Bridge is the http.Handler of the remote-write endpoint. It sends every series of a request
+// as a message keyed by its labels to the metrics topic, so the samples of a series are kept in
+// order on a partition, with a Producer batching the series of the concurrent requests.
+type Bridge struct {
+	producer     *producer.Producer
+	confirmation iggcon.Confirmation
+	maxBodyBytes int
+
+	requests    atomic.Int64
+	rejected    atomic.Int64
+	failed      atomic.Int64
+	series      atomic.Int64
+	samples     atomic.Int64
+	undelivered atomic.Int64
+}
+
+func NewBridge(client messengercli.DataClient, config Config) (*Bridge, error) {
+	streamId, err := iggcon.NewIdentifier(config.Stream)
+	if err != nil {
+		return nil, err
+	}
+	topicId, err := iggcon.NewIdentifier(config.Topic)
+	if err != nil {
+		return nil, err
+	}
+	confirmation, err := parseConfirmation(config.Producer.Confirmation)
+	if err != nil {
+		return nil, err
+	}
+	b := &Bridge{confirmation: confirmation, maxBodyBytes: config.HTTP.MaxBodyBytes}
+	b.producer, err = producer.NewProducer(client, streamId, topicId,
+		producer.WithPartitioner(iggcon.Murmur2Partitioner(), nil),
+		producer.WithBatchSize(config.Producer.BatchSize),
+		producer.WithLinger(time.Duration(config.Producer.Linger)),
+		producer.WithMaxQueued(config.Producer.MaxQueuedMessages, config.Producer.MaxQueuedBytes),
+		producer.WithMaxInFlightRequests(max(config.Producer.MaxInFlightRequests, 1)),
+		producer.WithErrorHandler(func(err error, messages []iggcon.MessengerMessage) {
+			b.undelivered.Add(int64(len(messages)))
+			log.Printf("[WARN] failed to deliver %d series to %s/%s: %v", len(messages), config.Stream, config.Topic, err)
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// ServeHTTP decodes a snappy compressed prometheus.WriteRequest and sends its series, answering
+// once they are queued or acknowledged, depending on the confirmation level. When the queue of
+// the producer is full, the request waits for room, slowing down Prometheus.
+func (b *Bridge) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if encoding := r.Header.Get("Content-Encoding"); encoding != "snappy" {
+		b.reject(w, http.StatusUnsupportedMediaType, "unsupported content encoding %q, expected snappy", encoding)
+		return
+	}
+	if contentType := r.Header.Get("Content-Type"); contentType != "" {
+		_, params, err := mime.ParseMediaType(contentType)
+		if err != nil {
+			b.reject(w, http.StatusUnsupportedMediaType, "invalid content type %q", contentType)
+			return
+		}
+		if proto, ok := params["proto"]; ok && proto != writeRequestProto {
+			b.reject(w, http.StatusUnsupportedMediaType, "unsupported protobuf message %s, expected %s", proto, writeRequestProto)
+			return
+		}
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, int64(b.maxBodyBytes)))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			b.reject(w, http.StatusRequestEntityTooLarge, "the request exceeds the limit of %d bytes", b.maxBodyBytes)
+		} else {
+			b.reject(w, http.StatusBadRequest, "failed to read the request: %v", err)
+		}
+		return
+	}
+	series, err := decodeWriteRequest(body, b.maxBodyBytes)
+	if err != nil {
+		b.reject(w, http.StatusBadRequest, "%v", err)
+		return
+	}
+
+	messages, samples, err := seriesMessages(series)
+	if err != nil {
+		b.reject(w, http.StatusBadRequest, "%v", err)
+		return
+	}
+	if err := b.producer.SendWithConfirmation(r.Context(), b.confirmation, messages...); err != nil {
+		b.failed.Add(1)
+		log.Printf("[WARN] failed to send %d series: %v", len(messages), err)
+		http.Error(w, "failed to send the samples: "+err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	b.requests.Add(1)
+	b.series.Add(int64(len(series)))
+	b.samples.Add(int64(samples))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (b *Bridge) reject(w http.ResponseWriter, status int, format string, args ...any) {
+	b.rejected.Add(1)
+	http.Error(w, fmt.Sprintf(format, args...), status)
+}
+
+// seriesMessages returns the messages of the series, a series with more than maxSamplesPerMessage
+// samples being split into several messages, and the number of samples.
+func seriesMessages(series []Series) ([]iggcon.MessengerMessage, int, error) {
+	messages := make([]iggcon.MessengerMessage, 0, len(series))
+	samples := 0
+	for _, s := range series {
+		samples += len(s.Samples)
+		opts := []iggcon.MessengerMessageOpt{iggcon.WithKey([]byte(s.Fingerprint()))}
+		if name, ok := s.Labels["__name__"]; ok {
+			opts = append(opts, iggcon.WithUserHeaders(map[iggcon.HeaderKey]iggcon.HeaderValue{
+				{Value: MetricHeader}: iggcon.NewStringHeaderValue(name),
+			}))
+		}
+		all := s.Samples
+		for start := 0; start < len(all); start += maxSamplesPerMessage {
+			s.Samples = all[start:min(start+maxSamplesPerMessage, len(all))]
+			payload, err := json.Marshal(s)
+			if err != nil {
+				return nil, 0, err
+			}
+			message, err := iggcon.NewMessengerMessage(payload, append(opts, iggcon.WithID(uuid.NewSHA1(seriesNamespace, payload)))...)
+			if err != nil {
+				return nil, 0, err
+			}
+			messages = append(messages, message)
+		}
+	}
+	return messages, samples, nil
+}
+
+// Stats returns the counters of the bridge.
+func (b *Bridge) Stats() Stats {
+	return Stats{
+		Requests:    b.requests.Load(),
+		Rejected:    b.rejected.Load(),
+		Failed:      b.failed.Load(),
+		Series:      b.series.Load(),
+		Samples:     b.samples.Load(),
+		Undelivered: b.undelivered.Load(),
+	}
+}
+
+// Flush waits until the producer sent the series enqueued so far.
+func (b *Bridge) Flush(ctx context.Context) error {
+	return b.producer.Flush(ctx)
+}
+
+// Close closes the producer, sending the series it queued.
+func (b *Bridge) Close(ctx context.Context) error {
+	return b.producer.Close(ctx)
+}
@@ -0,0 +1,195 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengertest"
+	"github.com/klauspost/compress/s2"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// writeRequest encodes a snappy compressed prometheus.WriteRequest of the series.
+func writeRequest(series ...Series) []byte {
+	var request []byte
+	for _, s := range series {
+		var timeSeries []byte
+		for name, value := range s.Labels {
+			var label []byte
+			label = protowire.AppendTag(label, 1, protowire.BytesType)
+			label = protowire.AppendString(label, name)
+			label = protowire.AppendTag(label, 2, protowire.BytesType)
+			label = protowire.AppendString(label, value)
+			timeSeries = protowire.AppendTag(timeSeries, 1, protowire.BytesType)
+			timeSeries = protowire.AppendBytes(timeSeries, label)
+		}
+		for _, sample := range s.Samples {
+			var encoded []byte
+			encoded = protowire.AppendTag(encoded, 1, protowire.Fixed64Type)
+			encoded = protowire.AppendFixed64(encoded, math.Float64bits(float64(sample.Value)))
+			encoded = protowire.AppendTag(encoded, 2, protowire.VarintType)
+			encoded = protowire.AppendVarint(encoded, uint64(sample.Timestamp))
+			timeSeries = protowire.AppendTag(timeSeries, 2, protowire.BytesType)
+			timeSeries = protowire.AppendBytes(timeSeries, encoded)
+		}
+		request = protowire.AppendTag(request, 1, protowire.BytesType)
+		request = protowire.AppendBytes(request, timeSeries)
+	}
+	return s2.EncodeSnappy(nil, request)
+}
+
+func post(t *testing.T, url string, body []byte, contentType string) int {
+	request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	request.Header.Set("Content-Encoding", "snappy")
+	request.Header.Set("Content-Type", contentType)
+	request.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+	return response.StatusCode
+}
+
+func TestBridge(t *testing.T) {
+	client := messengertest.NewClient()
+	if _, err := client.CreateStream("observability", nil); err != nil {
+		t.Fatal(err)
+	}
+	streamId, topicId := iggcon.MustIdentifier("observability"), iggcon.MustIdentifier("metrics")
+	if _, err := client.CreateTopic(streamId, "metrics", 4, iggcon.CompressionAlgorithmNone, 0, 0, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	config := DefaultConfig()
+	config.Stream, config.Topic = "observability", "metrics"
+	config.Producer.BatchSize = 50
+	if err := config.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	bridge, err := NewBridge(client, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(bridge)
+	defer server.Close()
+
+	// the shards of Prometheus send the series of many instances concurrently, the samples of
+	// a series in order
+	const shards, requests, seriesPerRequest = 8, 5, 40
+	var wg sync.WaitGroup
+	for shard := range shards {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for request := range requests {
+				series := make([]Series, seriesPerRequest)
+				for i := range series {
+					series[i] = Series{
+						Labels:  map[string]string{"__name__": "http_requests_total", "instance": fmt.Sprintf("host-%d-%d", shard, i)},
+						Samples: []Sample{{Timestamp: int64(request) * 15_000, Value: Value(request)}},
+					}
+				}
+				if status := post(t, server.URL, writeRequest(series...), "application/x-protobuf"); status != http.StatusNoContent {
+					t.Errorf("expected the request to succeed, got status %d", status)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	stale := Series{
+		Labels:  map[string]string{"__name__": "up", "instance": "host-0-0"},
+		Samples: []Sample{{Timestamp: 1000, Value: 1}, {Timestamp: 2000, Value: Value(math.NaN())}},
+	}
+	if status := post(t, server.URL, writeRequest(stale), "application/x-protobuf;proto=prometheus.WriteRequest"); status != http.StatusNoContent {
+		t.Fatalf("expected the request to succeed, got status %d", status)
+	}
+	if status := post(t, server.URL, []byte("not snappy"), "application/x-protobuf"); status != http.StatusBadRequest {
+		t.Fatalf("expected a malformed request to be rejected, got status %d", status)
+	}
+	if status := post(t, server.URL, writeRequest(stale), "application/x-protobuf;proto=io.prometheus.write.v2.Request"); status != http.StatusUnsupportedMediaType {
+		t.Fatalf("expected remote-write 2.0 to be rejected, got status %d", status)
+	}
+	if err := bridge.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	stats := bridge.Stats()
+	expected := Stats{Requests: shards*requests + 1, Rejected: 2, Series: shards*requests*seriesPerRequest + 1, Samples: shards*requests*seriesPerRequest + 2}
+	if stats != expected {
+		t.Fatalf("expected %+v, got %+v", expected, stats)
+	}
+	topic, err := client.GetTopic(streamId, topicId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if topic.MessagesCount != uint64(expected.Series) {
+		t.Fatalf("expected a message per series, got %d", topic.MessagesCount)
+	}
+
+	// the samples of a series are kept in order on the partition of its labels
+	host := Series{Labels: map[string]string{"__name__": "http_requests_total", "instance": "host-3-7"}}
+	var timestamps []int64
+	for _, series := range pollSeries(t, client, host.Fingerprint()) {
+		if series.Labels["instance"] == "host-3-7" {
+			timestamps = append(timestamps, series.Samples[0].Timestamp)
+		}
+	}
+	if fmt.Sprint(timestamps) != "[0 15000 30000 45000 60000]" {
+		t.Fatalf("expected the samples of the series in order, got %v", timestamps)
+	}
+	var up []Sample
+	for _, series := range pollSeries(t, client, stale.Fingerprint()) {
+		if series.Labels["__name__"] == "up" {
+			up = append(up, series.Samples...)
+		}
+	}
+	if len(up) != 2 || !math.IsNaN(float64(up[1].Value)) {
+		t.Fatalf("expected the staleness marker to be kept, got %+v", up)
+	}
+}
+
+// pollSeries returns the series of the partition of the fingerprint.
+func pollSeries(t *testing.T, client *messengertest.Client, fingerprint string) []Series {
+	partition := iggcon.Murmur2Partitioner().Partition([]byte(fingerprint), 4)
+	polled, err := client.PollMessages(iggcon.MustIdentifier("observability"), iggcon.MustIdentifier("metrics"),
+		iggcon.DefaultConsumer(), iggcon.FirstPollingStrategy(), 1000, false, &partition)
+	if err != nil {
+		t.Fatal(err)
+	}
+	series := make([]Series, len(polled.Messages))
+	for i, message := range polled.Messages {
+		if err := json.Unmarshal(message.Payload, &series[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return series
+}
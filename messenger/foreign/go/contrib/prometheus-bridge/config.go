@@ -0,0 +1,148 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+)
+
+// Config is the configuration of the bridge, read from a JSON file.
+type Config struct {
+	HTTP      HTTPConfig      `json:"http"`
+	Messenger MessengerConfig `json:"messenger"`
+	// Stream and Topic are the Messenger topic the samples are sent to.
+	Stream   string         `json:"stream"`
+	Topic    string         `json:"topic"`
+	Producer ProducerConfig `json:"producer"`
+}
+
+type HTTPConfig struct {
+	// Address is the host:port the bridge listens on.
+	Address string `json:"address"`
+	// Path is the path of the remote-write endpoint, /api/v1/write by default.
+	Path string `json:"path"`
+	// MaxBodyBytes bounds the size of a request, once decompressed.
+	MaxBodyBytes int `json:"maxBodyBytes,omitempty"`
+}
+
+type MessengerConfig struct {
+	Address  string `json:"address"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// ProducerConfig tunes the producer batching the series of the concurrent remote-write requests.
+type ProducerConfig struct {
+	// BatchSize is the maximum number of messages sent in a request to a partition.
+	BatchSize int `json:"batchSize,omitempty"`
+	// Linger is how long the producer waits for a batch to fill up.
+	Linger Duration `json:"linger,omitempty"`
+	// MaxQueuedMessages and MaxQueuedBytes bound the messages waiting to be sent. Once reached,
+	// the remote-write requests wait for room, slowing down the Prometheus shards.
+	MaxQueuedMessages int `json:"maxQueuedMessages,omitempty"`
+	MaxQueuedBytes    int `json:"maxQueuedBytes,omitempty"`
+	// MaxInFlightRequests bounds the send requests in flight per partition.
+	MaxInFlightRequests int `json:"maxInFlightRequests,omitempty"`
+	// Confirmation is the durability level reached before a remote-write request is answered:
+	// no_wait answers once the samples are queued, write and fsync once the server acknowledged
+	// them, so Prometheus sends them again when they failed to be delivered.
+	Confirmation string `json:"confirmation,omitempty"`
+}
+
+// Duration is a time.Duration written as a string like "30s" in JSON.
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// DefaultConfig returns the configuration used for the settings missing from the file.
+func DefaultConfig() Config {
+	return Config{
+		HTTP:      HTTPConfig{Address: ":9201", Path: "/api/v1/write", MaxBodyBytes: 32 * 1024 * 1024},
+		Messenger: MessengerConfig{Address: "127.0.0.1:8090", Username: "messenger", Password: "messenger"},
+		Producer: ProducerConfig{
+			BatchSize:           1000,
+			Linger:              Duration(5 * time.Millisecond),
+			MaxQueuedMessages:   100_000,
+			MaxQueuedBytes:      64 * 1024 * 1024,
+			MaxInFlightRequests: 1,
+			Confirmation:        iggcon.ConfirmationWrite.String(),
+		},
+	}
+}
+
+// LoadConfig reads and validates the configuration file.
+func LoadConfig(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+	config := DefaultConfig()
+	if err := json.Unmarshal(data, &config); err != nil {
+		return Config{}, err
+	}
+	return config, config.Validate()
+}
+
+// Validate checks the topic and the producer settings.
+func (c Config) Validate() error {
+	if c.Stream == "" || c.Topic == "" {
+		return errors.New("the stream and the topic are required")
+	}
+	if c.HTTP.MaxBodyBytes <= 0 {
+		return errors.New("maxBodyBytes must be positive")
+	}
+	if c.Producer.BatchSize <= 0 || c.Producer.MaxQueuedMessages <= 0 || c.Producer.MaxQueuedBytes <= 0 {
+		return errors.New("the batch size and the queue bounds of the producer must be positive")
+	}
+	_, err := parseConfirmation(c.Producer.Confirmation)
+	return err
+}
+
+// parseConfirmation returns the confirmation level of its name, write when empty.
+func parseConfirmation(name string) (iggcon.Confirmation, error) {
+	if name == "" {
+		return iggcon.ConfirmationWrite, nil
+	}
+	for _, confirmation := range []iggcon.Confirmation{iggcon.ConfirmationNoWait, iggcon.ConfirmationWrite, iggcon.ConfirmationFsync} {
+		if confirmation.String() == name {
+			return confirmation, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid confirmation %q, expected no_wait, write or fsync", name)
+}
@@ -0,0 +1,96 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Command prometheus-bridge accepts the samples of Prometheus remote-write and sends them to a
+// Messenger topic, as an example of sustained high-cardinality ingestion with the producer
+// package. See config.example.json for its JSON configuration.
+//
+//	prometheus-bridge -config config.json
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/apache/messenger/foreign/go/messengercli"
+	"github.com/apache/messenger/foreign/go/tcp"
+)
+
+// closeTimeout bounds the time spent answering the pending requests and sending the queued
+// series on shutdown.
+const closeTimeout = 10 * time.Second
+
+func main() {
+	configPath := flag.String("config", "config.json", "configuration file")
+	flag.Parse()
+
+	config, err := LoadConfig(*configPath)
+	if err != nil {
+		fail(err)
+	}
+	cli, err := messengercli.NewMessengerClient(messengercli.WithTcp(tcp.WithServerAddress(config.Messenger.Address)))
+	if err != nil {
+		fail(err)
+	}
+	if _, err = cli.LoginUser(config.Messenger.Username, config.Messenger.Password); err != nil {
+		fail(err)
+	}
+	bridge, err := NewBridge(cli, config)
+	if err != nil {
+		fail(err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(config.HTTP.Path, bridge)
+	server := &http.Server{Addr: config.HTTP.Address, Handler: mux}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), closeTimeout)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+	log.Printf("forwarding the remote-write requests of %s%s to %s/%s on %s",
+		config.HTTP.Address, config.HTTP.Path, config.Stream, config.Topic, config.Messenger.Address)
+	if err = server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		fail(err)
+	}
+
+	closeCtx, cancel := context.WithTimeout(context.Background(), closeTimeout)
+	defer cancel()
+	err = bridge.Close(closeCtx)
+	stats := bridge.Stats()
+	log.Printf("forwarded %d samples of %d series in %d requests, rejected %d requests, failed %d requests, %d series undelivered",
+		stats.Samples, stats.Series, stats.Requests, stats.Rejected, stats.Failed, stats.Undelivered)
+	if err != nil {
+		fail(err)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "error:", err)
+	os.Exit(1)
+}
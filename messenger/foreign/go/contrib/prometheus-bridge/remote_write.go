@@ -0,0 +1,219 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/klauspost/compress/s2"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Series is the payload of a message: the samples of a time series received in a remote-write
+// request.
+type Series struct {
+	Labels  map[string]string `json:"labels"`
+	Samples []Sample          `json:"samples"`
+}
+
+type Sample struct {
+	// Timestamp is in milliseconds since the Unix epoch.
+	Timestamp int64 `json:"timestamp"`
+	Value     Value `json:"value"`
+}
+
+// Value is a sample value, written as a JSON number when finite and as the string "NaN",
+// "+Inf" or "-Inf" otherwise, like the staleness markers.
+type Value float64
+
+func (v Value) MarshalJSON() ([]byte, error) {
+	f := float64(v)
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return json.Marshal(strconv.FormatFloat(f, 'g', -1, 64))
+	}
+	return json.Marshal(f)
+}
+
+func (v *Value) UnmarshalJSON(data []byte) error {
+	var f float64
+	if err := json.Unmarshal(data, &f); err == nil {
+		*v = Value(f)
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return err
+	}
+	*v = Value(f)
+	return nil
+}
+
+// Fingerprint returns a hash of the labels of the series, the key of its messages.
+func (s Series) Fingerprint() string {
+	names := make([]string, 0, len(s.Labels))
+	for name := range s.Labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	h := fnv.New64a()
+	for _, name := range names {
+		h.Write([]byte(name))
+		h.Write([]byte{0xff})
+		h.Write([]byte(s.Labels[name]))
+		h.Write([]byte{0xff})
+	}
+	return strconv.FormatUint(h.Sum64(), 16)
+}
+
+var errEmptyLabels = errors.New("time series without labels")
+
+// decodeWriteRequest decompresses and decodes a prometheus.WriteRequest, returning its series
+// with samples. The metadata, the exemplars and the native histograms are skipped.
+func decodeWriteRequest(compressed []byte, maxBytes int) ([]Series, error) {
+	size, err := s2.DecodedLen(compressed)
+	if err != nil {
+		return nil, fmt.Errorf("invalid snappy body: %w", err)
+	}
+	if size > maxBytes {
+		return nil, fmt.Errorf("the request of %d bytes exceeds the limit of %d bytes", size, maxBytes)
+	}
+	data, err := s2.Decode(nil, compressed)
+	if err != nil {
+		return nil, fmt.Errorf("invalid snappy body: %w", err)
+	}
+	var series []Series
+	err = decodeFields(data, func(num protowire.Number, typ protowire.Type, value []byte) error {
+		if num != 1 || typ != protowire.BytesType {
+			return nil
+		}
+		s, err := decodeTimeSeries(value)
+		if err != nil {
+			return err
+		}
+		if len(s.Samples) > 0 {
+			series = append(series, s)
+		}
+		return nil
+	})
+	return series, err
+}
+
+// decodeTimeSeries decodes a prometheus.TimeSeries.
+func decodeTimeSeries(data []byte) (Series, error) {
+	s := Series{Labels: map[string]string{}}
+	err := decodeFields(data, func(num protowire.Number, typ protowire.Type, value []byte) error {
+		switch {
+		case num == 1 && typ == protowire.BytesType:
+			name, labelValue, err := decodeLabel(value)
+			if err != nil {
+				return err
+			}
+			s.Labels[name] = labelValue
+		case num == 2 && typ == protowire.BytesType:
+			sample, err := decodeSample(value)
+			if err != nil {
+				return err
+			}
+			s.Samples = append(s.Samples, sample)
+		}
+		return nil
+	})
+	if err == nil && len(s.Labels) == 0 {
+		err = errEmptyLabels
+	}
+	return s, err
+}
+
+// decodeLabel decodes a prometheus.Label.
+func decodeLabel(data []byte) (string, string, error) {
+	var name, value string
+	err := decodeFields(data, func(num protowire.Number, typ protowire.Type, field []byte) error {
+		if typ != protowire.BytesType {
+			return nil
+		}
+		switch num {
+		case 1:
+			name = string(field)
+		case 2:
+			value = string(field)
+		}
+		return nil
+	})
+	if err == nil && name == "" {
+		err = errors.New("label without name")
+	}
+	return name, value, err
+}
+
+// decodeSample decodes a prometheus.Sample.
+func decodeSample(data []byte) (Sample, error) {
+	var sample Sample
+	err := decodeFields(data, func(num protowire.Number, typ protowire.Type, field []byte) error {
+		switch {
+		case num == 1 && typ == protowire.Fixed64Type:
+			bits, _ := protowire.ConsumeFixed64(field)
+			sample.Value = Value(math.Float64frombits(bits))
+		case num == 2 && typ == protowire.VarintType:
+			timestamp, _ := protowire.ConsumeVarint(field)
+			sample.Timestamp = int64(timestamp)
+		}
+		return nil
+	})
+	return sample, err
+}
+
+// decodeFields calls f with every field of the protobuf message, with the content of the length
+// delimited fields and the encoded value of the others.
+func decodeFields(data []byte, f func(num protowire.Number, typ protowire.Type, value []byte) error) error {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return fmt.Errorf("invalid protobuf message: %w", protowire.ParseError(n))
+		}
+		data = data[n:]
+		value := data
+		if typ == protowire.BytesType {
+			var m int
+			value, m = protowire.ConsumeBytes(data)
+			n = m
+		} else {
+			n = protowire.ConsumeFieldValue(num, typ, data)
+			if n >= 0 {
+				value = data[:n]
+			}
+		}
+		if n < 0 {
+			return fmt.Errorf("invalid protobuf message: %w", protowire.ParseError(n))
+		}
+		data = data[n:]
+		if err := f(num, typ, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package deprecation reports the use of deprecated SDK APIs at runtime, so applications learn
+// about upcoming removals before upgrading.
+//
+// Every deprecated API reports a Notice once per process. The MESSENGER_DEPRECATIONS environment
+// variable controls what happens then:
+//
+//	warn    log a warning with the migration hint (default)
+//	silent  ignore the notices
+//	strict  panic, so CI fails as soon as a deprecated API is used
+package deprecation
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+)
+
+// EnvVar is the environment variable selecting the Mode.
+const EnvVar = "MESSENGER_DEPRECATIONS"
+
+// Mode is what happens when a deprecated API is used.
+type Mode int
+
+const (
+	ModeWarn Mode = iota
+	ModeSilent
+	ModeStrict
+)
+
+// Notice describes a deprecated API.
+type Notice struct {
+	// API is the qualified name of the deprecated API, e.g. "binaryserialization.DeserializeClients".
+	API string
+	// Replacement is the API to migrate to.
+	Replacement string
+	// Hint explains how to migrate, if the replacement is not enough.
+	Hint string
+}
+
+func (n Notice) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "api=%q replacement=%q", n.API, n.Replacement)
+	if n.Hint != "" {
+		fmt.Fprintf(&b, " hint=%q", n.Hint)
+	}
+	return b.String()
+}
+
+// Handler receives the notices in ModeWarn.
+type Handler func(notice Notice)
+
+var (
+	mtx      sync.Mutex
+	mode     = modeFromEnv()
+	handler  Handler
+	reported = make(map[string]bool)
+)
+
+func modeFromEnv() Mode {
+	switch strings.ToLower(os.Getenv(EnvVar)) {
+	case "silent":
+		return ModeSilent
+	case "strict":
+		return ModeStrict
+	default:
+		return ModeWarn
+	}
+}
+
+// SetMode overrides the mode read from the environment.
+func SetMode(m Mode) {
+	mtx.Lock()
+	defer mtx.Unlock()
+	mode = m
+}
+
+// SetHandler routes the notices to the given handler instead of the standard logger,
+// a nil handler restores the default.
+func SetHandler(h Handler) {
+	mtx.Lock()
+	defer mtx.Unlock()
+	handler = h
+}
+
+// Report records the use of a deprecated API. Only the first use of every API is reported.
+func Report(notice Notice) {
+	mtx.Lock()
+	if mode == ModeSilent || reported[notice.API] {
+		mtx.Unlock()
+		return
+	}
+	reported[notice.API] = true
+	m, h := mode, handler
+	mtx.Unlock()
+
+	switch {
+	case m == ModeStrict:
+		panic("deprecated API used: " + notice.String())
+	case h != nil:
+		h(notice)
+	default:
+		log.Printf("[WARN] deprecated API used: %s", notice)
+	}
+}
@@ -0,0 +1,46 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package deprecation
+
+import "testing"
+
+func TestReport_OncePerAPI(t *testing.T) {
+	var notices []Notice
+	SetMode(ModeWarn)
+	SetHandler(func(notice Notice) { notices = append(notices, notice) })
+	defer SetHandler(nil)
+
+	Report(Notice{API: "pkg.Old", Replacement: "pkg.New"})
+	Report(Notice{API: "pkg.Old", Replacement: "pkg.New"})
+	Report(Notice{API: "pkg.Older", Replacement: "pkg.New"})
+
+	if len(notices) != 2 || notices[0].API != "pkg.Old" || notices[1].API != "pkg.Older" {
+		t.Fatalf("unexpected notices %v", notices)
+	}
+}
+
+func TestReport_Strict(t *testing.T) {
+	SetMode(ModeStrict)
+	defer SetMode(ModeWarn)
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected strict mode to panic")
+		}
+	}()
+	Report(Notice{API: "pkg.Strict", Replacement: "pkg.New"})
+}
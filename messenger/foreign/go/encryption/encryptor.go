@@ -0,0 +1,213 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	ierror "github.com/apache/messenger/foreign/go/errors"
+)
+
+// User headers describing how a payload was encrypted.
+const (
+	KeyIdHeader   = "messenger-encryption-key-id"
+	NonceHeader   = "messenger-encryption-nonce"
+	DataKeyHeader = "messenger-encryption-data-key"
+)
+
+// Encryptor encrypts message payloads with AES-256-GCM before they are sent and decrypts
+// them once polled. It implements both messengercli.ProducerInterceptor and
+// messengercli.ConsumerInterceptor:
+//
+//	encryptor := encryption.NewEncryptor(keys)
+//	cli, err := messengercli.NewMessengerClient(
+//		messengercli.WithProducerInterceptors(encryptor),
+//		messengercli.WithConsumerInterceptors(encryptor),
+//	)
+//
+// The key ID and the nonce are stored in the user headers. With envelope encryption,
+// every message is encrypted with a fresh data key, itself encrypted (wrapped) with the
+// key from the KeyProvider and stored alongside the message, so the provider key never
+// encrypts payloads directly and can live in a KMS.
+type Encryptor struct {
+	keys     KeyProvider
+	envelope bool
+}
+
+type Option func(encryptor *Encryptor)
+
+// WithEnvelopeEncryption encrypts every message with its own data key, wrapped with the provider key.
+func WithEnvelopeEncryption() Option {
+	return func(encryptor *Encryptor) {
+		encryptor.envelope = true
+	}
+}
+
+// NewEncryptor creates an Encryptor using keys from the given provider.
+func NewEncryptor(keys KeyProvider, options ...Option) *Encryptor {
+	encryptor := &Encryptor{keys: keys}
+	for _, opt := range options {
+		if opt != nil {
+			opt(encryptor)
+		}
+	}
+	return encryptor
+}
+
+// Encrypt encrypts the payload of the message in place.
+func (e *Encryptor) Encrypt(message *iggcon.MessengerMessage) error {
+	keyId, key, err := e.keys.CurrentKey()
+	if err != nil {
+		return err
+	}
+
+	headers := map[iggcon.HeaderKey]iggcon.HeaderValue{
+		{Value: KeyIdHeader}: iggcon.NewStringHeaderValue(keyId),
+	}
+	if e.envelope {
+		dataKey := make([]byte, 32)
+		if _, err = rand.Read(dataKey); err != nil {
+			return err
+		}
+		wrappedKey, err := seal(key, dataKey)
+		if err != nil {
+			return err
+		}
+		headers[iggcon.HeaderKey{Value: DataKeyHeader}] = iggcon.HeaderValue{Kind: iggcon.Raw, Value: wrappedKey}
+		key = dataKey
+	}
+
+	sealed, err := seal(key, message.Payload)
+	if err != nil {
+		return err
+	}
+	nonce, ciphertext := sealed[:nonceSize], sealed[nonceSize:]
+	if len(ciphertext) > iggcon.MaxPayloadSize {
+		return ierror.TooBigUserMessagePayload
+	}
+	headers[iggcon.HeaderKey{Value: NonceHeader}] = iggcon.HeaderValue{Kind: iggcon.Raw, Value: nonce}
+	if err = message.SetUserHeaders(headers); err != nil {
+		return err
+	}
+
+	message.Payload = ciphertext
+	message.Header.PayloadLength = uint32(len(ciphertext))
+	return nil
+}
+
+// Decrypt decrypts the payload of the message in place. Messages without encryption headers are left untouched.
+func (e *Encryptor) Decrypt(message *iggcon.MessengerMessage) error {
+	keyIdHeader, ok := message.UserHeader(KeyIdHeader)
+	if !ok {
+		return nil
+	}
+	keyId, err := keyIdHeader.AsString()
+	if err != nil {
+		return err
+	}
+	nonce, ok := message.UserHeader(NonceHeader)
+	if !ok {
+		return errors.New("encryption: missing nonce header")
+	}
+	key, err := e.keys.Key(keyId)
+	if err != nil {
+		return err
+	}
+	if wrappedKey, ok := message.UserHeader(DataKeyHeader); ok {
+		if key, err = open(key, wrappedKey.Value); err != nil {
+			return fmt.Errorf("encryption: failed to unwrap the data key: %w", err)
+		}
+	}
+
+	payload, err := open(key, append(append([]byte{}, nonce.Value...), message.Payload...))
+	if err != nil {
+		return fmt.Errorf("encryption: failed to decrypt the payload: %w", err)
+	}
+	message.Payload = payload
+	message.Header.PayloadLength = uint32(len(payload))
+	return nil
+}
+
+func (e *Encryptor) OnSend(_, _ iggcon.Identifier, messages []iggcon.MessengerMessage) ([]iggcon.MessengerMessage, error) {
+	encrypted := make([]iggcon.MessengerMessage, len(messages))
+	for i, message := range messages {
+		if err := e.Encrypt(&message); err != nil {
+			return nil, err
+		}
+		encrypted[i] = message
+	}
+	return encrypted, nil
+}
+
+func (e *Encryptor) OnAcknowledgement(_, _ iggcon.Identifier, _ []iggcon.MessengerMessage, _ error) {
+}
+
+func (e *Encryptor) OnConsume(_, _ iggcon.Identifier, polled *iggcon.PolledMessage) (*iggcon.PolledMessage, error) {
+	if polled == nil {
+		return nil, nil
+	}
+	for i := range polled.Messages {
+		if err := e.Decrypt(&polled.Messages[i]); err != nil {
+			return nil, err
+		}
+	}
+	return polled, nil
+}
+
+func (e *Encryptor) OnCommit(iggcon.Consumer, iggcon.Identifier, iggcon.Identifier, uint64, *uint32, error) {
+}
+
+const nonceSize = 12
+
+// seal encrypts plaintext with AES-256-GCM and returns the nonce followed by the ciphertext.
+func seal(key []byte, plaintext []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, nonceSize, nonceSize+len(plaintext)+aead.Overhead())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// open decrypts the output of seal.
+func open(key []byte, sealed []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < nonceSize {
+		return nil, errors.New("ciphertext too short")
+	}
+	return aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], nil)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,79 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package encryption
+
+import (
+	"bytes"
+	"testing"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+)
+
+func TestEncryptor_RoundTrip(t *testing.T) {
+	keys, err := NewStaticKeyProvider("k1", bytes.Repeat([]byte{1}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, encryptor := range map[string]*Encryptor{
+		"direct":   NewEncryptor(keys),
+		"envelope": NewEncryptor(keys, WithEnvelopeEncryption()),
+	} {
+		t.Run(name, func(t *testing.T) {
+			payload := []byte("hello, messenger")
+			message, err := iggcon.NewMessengerMessage(append([]byte{}, payload...))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err = encryptor.Encrypt(&message); err != nil {
+				t.Fatal(err)
+			}
+			if bytes.Equal(message.Payload, payload) {
+				t.Fatal("payload was not encrypted")
+			}
+			if _, ok := message.UserHeader(DataKeyHeader); ok != encryptor.envelope {
+				t.Fatalf("data key header present: %v, envelope: %v", ok, encryptor.envelope)
+			}
+
+			// A rotated key must not prevent decrypting older messages.
+			if err = keys.Rotate("k2", bytes.Repeat([]byte{2}, 32)); err != nil {
+				t.Fatal(err)
+			}
+			if err = encryptor.Decrypt(&message); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(message.Payload, payload) || int(message.Header.PayloadLength) != len(payload) {
+				t.Fatalf("unexpected payload %q", message.Payload)
+			}
+		})
+	}
+}
+
+func TestEncryptor_TamperedPayload(t *testing.T) {
+	keys, _ := NewStaticKeyProvider("k1", bytes.Repeat([]byte{1}, 32))
+	encryptor := NewEncryptor(keys)
+	message, _ := iggcon.NewMessengerMessage([]byte("payload"))
+	if err := encryptor.Encrypt(&message); err != nil {
+		t.Fatal(err)
+	}
+	message.Payload[0] ^= 0xff
+	if err := encryptor.Decrypt(&message); err == nil {
+		t.Fatal("expected decryption of a tampered payload to fail")
+	}
+}
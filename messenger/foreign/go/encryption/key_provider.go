@@ -0,0 +1,89 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package encryption
+
+import (
+	"crypto/aes"
+	"fmt"
+	"sync"
+)
+
+// KeyProvider supplies the AES-256 keys used to encrypt and decrypt payloads.
+// Implementations backed by a KMS or a secret store can rotate keys by changing
+// the current key ID, while keeping the older keys available for decryption.
+type KeyProvider interface {
+	// CurrentKey returns the ID and the 32 bytes of the key used to encrypt new messages.
+	CurrentKey() (keyId string, key []byte, err error)
+	// Key returns the key with the given ID, used to decrypt messages.
+	Key(keyId string) ([]byte, error)
+}
+
+// StaticKeyProvider is a KeyProvider serving keys held in memory.
+type StaticKeyProvider struct {
+	mtx       sync.RWMutex
+	currentId string
+	keys      map[string][]byte
+}
+
+// NewStaticKeyProvider creates a provider encrypting with the given key.
+func NewStaticKeyProvider(keyId string, key []byte) (*StaticKeyProvider, error) {
+	provider := &StaticKeyProvider{keys: make(map[string][]byte)}
+	if err := provider.Rotate(keyId, key); err != nil {
+		return nil, err
+	}
+	return provider, nil
+}
+
+// Rotate makes the given key the one encrypting new messages, previous keys remain
+// available to decrypt older messages.
+func (p *StaticKeyProvider) Rotate(keyId string, key []byte) error {
+	if err := validateKey(keyId, key); err != nil {
+		return err
+	}
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.keys[keyId] = key
+	p.currentId = keyId
+	return nil
+}
+
+func (p *StaticKeyProvider) CurrentKey() (string, []byte, error) {
+	p.mtx.RLock()
+	defer p.mtx.RUnlock()
+	return p.currentId, p.keys[p.currentId], nil
+}
+
+func (p *StaticKeyProvider) Key(keyId string) ([]byte, error) {
+	p.mtx.RLock()
+	defer p.mtx.RUnlock()
+	key, ok := p.keys[keyId]
+	if !ok {
+		return nil, fmt.Errorf("encryption: unknown key %q", keyId)
+	}
+	return key, nil
+}
+
+func validateKey(keyId string, key []byte) error {
+	if len(keyId) == 0 || len(keyId) > 255 {
+		return fmt.Errorf("encryption: key ID must be between 1 and 255 bytes, got %d", len(keyId))
+	}
+	if len(key) != 32 {
+		return aes.KeySizeError(len(key))
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ierror
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Codes of the errors returned by the server when a command is not authenticated or not authorized.
+const (
+	UnauthenticatedCode = 40
+	UnauthorizedCode    = 41
+)
+
+// Resource is a resource a command was sent for, like the stream "orders".
+type Resource struct {
+	// Kind is the kind of the resource: consumer, stream, topic, group or user.
+	Kind string
+	// Id is the numeric ID or the quoted name the command referenced the resource with.
+	Id string
+}
+
+// AuthorizationError is an unauthenticated or unauthorized error of the server, enriched with the
+// command it answered, the resources the command was sent for and the permission granting it.
+// It unwraps to the MessengerError of the server.
+type AuthorizationError struct {
+	Err       *MessengerError
+	Command   string
+	Resources []Resource
+	// Permission is the global permission granting the command, empty when it has none.
+	Permission string
+}
+
+func (e *AuthorizationError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%v: %s", e.Err, e.Command)
+	for i, resource := range e.Resources {
+		separator := ", "
+		if i == 0 {
+			separator = " of "
+		}
+		fmt.Fprintf(&b, "%s%s %s", separator, resource.Kind, resource.Id)
+	}
+	if e.Permission != "" && e.Err.Code == UnauthorizedCode {
+		fmt.Fprintf(&b, " requires the %s permission", e.Permission)
+	}
+	return b.String()
+}
+
+func (e *AuthorizationError) Unwrap() error {
+	return e.Err
+}
@@ -54,6 +54,10 @@ var (
 		Code:    4017,
 		Message: "too_big_headers_payload",
 	}
+	InvalidMessagesSize = &MessengerError{
+		Code:    4036,
+		Message: "invalid_messages_size",
+	}
 	ConsumerGroupIdNotFound = &MessengerError{
 		Code:    5000,
 		Message: "consumer_group_not_found",
@@ -34,3 +34,16 @@ func TestMessengerError_Error(t *testing.T) {
 		t.Errorf("Error() method mismatch, expected: %s, got: %s", expectedErrorString, actualErrorString)
 	}
 }
+
+func TestAuthorizationError_Error(t *testing.T) {
+	err := &AuthorizationError{
+		Err:        MapFromCode(UnauthorizedCode).(*MessengerError),
+		Command:    "GetTopic",
+		Resources:  []Resource{{Kind: "stream", Id: `"orders"`}, {Kind: "topic", Id: "3"}},
+		Permission: "ReadTopics",
+	}
+	expected := `41: 'unauthorized': GetTopic of stream "orders", topic 3 requires the ReadTopics permission`
+	if err.Error() != expected {
+		t.Errorf("expected %s, got %s", expected, err.Error())
+	}
+}
@@ -5,6 +5,17 @@ go 1.23.0
 toolchain go1.23.1
 
 require (
+	github.com/fxamacker/cbor/v2 v2.9.2
 	github.com/google/uuid v1.6.0
 	github.com/klauspost/compress v1.17.11
+	google.golang.org/grpc v1.71.1
+	google.golang.org/protobuf v1.36.9
+)
+
+require (
+	github.com/x448/float16 v0.8.4 // indirect
+	golang.org/x/net v0.34.0 // indirect
+	golang.org/x/sys v0.29.0 // indirect
+	golang.org/x/text v0.21.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20250115164207-1a7da9e5054f // indirect
 )
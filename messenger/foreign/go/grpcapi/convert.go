@@ -0,0 +1,214 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	ierror "github.com/apache/messenger/foreign/go/errors"
+	"github.com/apache/messenger/foreign/go/grpcapi/messengerpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// statusError converts an error of the client to the status of the gRPC call.
+func statusError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	switch {
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	}
+	var messengerErr *ierror.MessengerError
+	if errors.As(err, &messengerErr) {
+		return status.Error(code(messengerErr), err.Error())
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	return status.Error(codes.Unknown, err.Error())
+}
+
+func code(err *ierror.MessengerError) codes.Code {
+	switch {
+	case err.Code == ierror.UnauthenticatedCode || err.Message == "invalid_credentials":
+		return codes.Unauthenticated
+	case err.Code == ierror.UnauthorizedCode:
+		return codes.PermissionDenied
+	case err.Message == "feature_unavailable":
+		return codes.Unimplemented
+	case strings.HasSuffix(err.Message, "_not_found"):
+		return codes.NotFound
+	case strings.HasSuffix(err.Message, "_already_exists"):
+		return codes.AlreadyExists
+	case strings.HasPrefix(err.Message, "invalid_") || strings.HasPrefix(err.Message, "too_big_") || strings.HasSuffix(err.Message, "_too_long"):
+		return codes.InvalidArgument
+	}
+	return codes.Unknown
+}
+
+func invalidArgument(message string) error {
+	return status.Error(codes.InvalidArgument, message)
+}
+
+func identifier(id *messengerpb.Identifier, name string) (iggcon.Identifier, error) {
+	switch kind := id.GetKind().(type) {
+	case *messengerpb.Identifier_Id:
+		return iggcon.NewIdentifier(kind.Id)
+	case *messengerpb.Identifier_Name:
+		return iggcon.NewIdentifier(kind.Name)
+	}
+	return iggcon.Identifier{}, invalidArgument(name + " is required")
+}
+
+// topicIdentifiers converts the identifiers of a stream and of one of its topics.
+func topicIdentifiers(stream, topic *messengerpb.Identifier) (iggcon.Identifier, iggcon.Identifier, error) {
+	streamId, err := identifier(stream, "stream")
+	if err != nil {
+		return iggcon.Identifier{}, iggcon.Identifier{}, err
+	}
+	topicId, err := identifier(topic, "topic")
+	if err != nil {
+		return iggcon.Identifier{}, iggcon.Identifier{}, err
+	}
+	return streamId, topicId, nil
+}
+
+func consumer(c *messengerpb.Consumer) (iggcon.Consumer, error) {
+	if c == nil {
+		return iggcon.DefaultConsumer(), nil
+	}
+	id, err := identifier(c.GetId(), "consumer id")
+	if err != nil {
+		return iggcon.Consumer{}, err
+	}
+	if c.GetKind() == messengerpb.ConsumerKind_CONSUMER_KIND_GROUP {
+		return iggcon.NewGroupConsumer(id), nil
+	}
+	return iggcon.NewSingleConsumer(id), nil
+}
+
+func partitioning(p *messengerpb.Partitioning) (iggcon.Partitioning, error) {
+	switch kind := p.GetKind().(type) {
+	case *messengerpb.Partitioning_PartitionId:
+		return iggcon.PartitionId(kind.PartitionId), nil
+	case *messengerpb.Partitioning_MessageKey:
+		return iggcon.EntityIdBytes(kind.MessageKey)
+	}
+	return iggcon.None(), nil
+}
+
+func pollingStrategy(s *messengerpb.PollingStrategy) iggcon.PollingStrategy {
+	switch kind := s.GetKind().(type) {
+	case *messengerpb.PollingStrategy_Offset:
+		return iggcon.OffsetPollingStrategy(kind.Offset)
+	case *messengerpb.PollingStrategy_Timestamp:
+		return iggcon.TimestampPollingStrategy(kind.Timestamp)
+	case *messengerpb.PollingStrategy_First:
+		return iggcon.FirstPollingStrategy()
+	case *messengerpb.PollingStrategy_Last:
+		return iggcon.LastPollingStrategy()
+	}
+	return iggcon.NextPollingStrategy()
+}
+
+func toMessage(m *messengerpb.OutgoingMessage) (iggcon.MessengerMessage, error) {
+	var opts []iggcon.MessengerMessageOpt
+	switch len(m.GetId()) {
+	case 0:
+	case 16:
+		opts = append(opts, iggcon.WithID([16]byte(m.GetId())))
+	default:
+		return iggcon.MessengerMessage{}, invalidArgument("the message id must be 16 bytes long")
+	}
+	if len(m.GetHeaders()) > 0 {
+		headers := make(map[iggcon.HeaderKey]iggcon.HeaderValue, len(m.GetHeaders()))
+		for key, value := range m.GetHeaders() {
+			headerKey, err := iggcon.NewHeaderKey(key)
+			if err != nil {
+				return iggcon.MessengerMessage{}, invalidArgument(err.Error())
+			}
+			kind := iggcon.HeaderKind(value.GetKind())
+			if kind == 0 {
+				kind = iggcon.Raw
+			}
+			headers[headerKey] = iggcon.HeaderValue{Kind: kind, Value: value.GetValue()}
+		}
+		opts = append(opts, iggcon.WithUserHeaders(headers))
+	}
+	return iggcon.NewMessengerMessage(m.GetPayload(), opts...)
+}
+
+func fromMessage(partitionId uint32, message iggcon.MessengerMessage) *messengerpb.Message {
+	m := &messengerpb.Message{
+		PartitionId:     partitionId,
+		Offset:          message.Header.Offset,
+		Id:              message.Header.Id[:],
+		Timestamp:       message.Header.Timestamp,
+		OriginTimestamp: message.Header.OriginTimestamp,
+		Payload:         message.Payload,
+	}
+	if headers, err := iggcon.DeserializeHeaders(message.UserHeaders); err == nil && len(headers) > 0 {
+		m.Headers = make(map[string]*messengerpb.HeaderValue, len(headers))
+		for key, value := range headers {
+			m.Headers[key.Value] = &messengerpb.HeaderValue{Kind: messengerpb.HeaderKind(value.Kind), Value: value.Value}
+		}
+	}
+	return m
+}
+
+func fromStream(stream iggcon.Stream) *messengerpb.Stream {
+	return &messengerpb.Stream{
+		Id:            stream.Id,
+		Name:          stream.Name,
+		TopicsCount:   stream.TopicsCount,
+		MessagesCount: stream.MessagesCount,
+		SizeBytes:     stream.SizeBytes,
+		CreatedAt:     stream.CreatedAt,
+	}
+}
+
+func fromTopic(topic iggcon.Topic) *messengerpb.Topic {
+	return &messengerpb.Topic{
+		Id:                topic.Id,
+		Name:              topic.Name,
+		PartitionsCount:   topic.PartitionsCount,
+		MessagesCount:     topic.MessagesCount,
+		SizeBytes:         topic.Size,
+		MessageExpiryUs:   uint64(topic.MessageExpiry),
+		MaxTopicSize:      uint64(topic.MaxTopicSize),
+		ReplicationFactor: uint32(topic.ReplicationFactor),
+		CreatedAt:         topic.CreatedAt,
+	}
+}
+
+func milliseconds(ms uint32) time.Duration {
+	return time.Duration(ms) * time.Millisecond
+}
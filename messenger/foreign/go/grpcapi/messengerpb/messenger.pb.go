@@ -0,0 +1,2343 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        v5.29.3
+// source: messenger.proto
+
+package messengerpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// HeaderKind is the kind of the value of a user header, numbered as in the binary protocol.
+type HeaderKind int32
+
+const (
+	HeaderKind_HEADER_KIND_UNSPECIFIED HeaderKind = 0
+	HeaderKind_HEADER_KIND_RAW         HeaderKind = 1
+	HeaderKind_HEADER_KIND_STRING      HeaderKind = 2
+	HeaderKind_HEADER_KIND_BOOL        HeaderKind = 3
+	HeaderKind_HEADER_KIND_INT8        HeaderKind = 4
+	HeaderKind_HEADER_KIND_INT16       HeaderKind = 5
+	HeaderKind_HEADER_KIND_INT32       HeaderKind = 6
+	HeaderKind_HEADER_KIND_INT64       HeaderKind = 7
+	HeaderKind_HEADER_KIND_INT128      HeaderKind = 8
+	HeaderKind_HEADER_KIND_UINT8       HeaderKind = 9
+	HeaderKind_HEADER_KIND_UINT16      HeaderKind = 10
+	HeaderKind_HEADER_KIND_UINT32      HeaderKind = 11
+	HeaderKind_HEADER_KIND_UINT64      HeaderKind = 12
+	HeaderKind_HEADER_KIND_UINT128     HeaderKind = 13
+	HeaderKind_HEADER_KIND_FLOAT       HeaderKind = 14
+	HeaderKind_HEADER_KIND_DOUBLE      HeaderKind = 15
+)
+
+// Enum value maps for HeaderKind.
+var (
+	HeaderKind_name = map[int32]string{
+		0:  "HEADER_KIND_UNSPECIFIED",
+		1:  "HEADER_KIND_RAW",
+		2:  "HEADER_KIND_STRING",
+		3:  "HEADER_KIND_BOOL",
+		4:  "HEADER_KIND_INT8",
+		5:  "HEADER_KIND_INT16",
+		6:  "HEADER_KIND_INT32",
+		7:  "HEADER_KIND_INT64",
+		8:  "HEADER_KIND_INT128",
+		9:  "HEADER_KIND_UINT8",
+		10: "HEADER_KIND_UINT16",
+		11: "HEADER_KIND_UINT32",
+		12: "HEADER_KIND_UINT64",
+		13: "HEADER_KIND_UINT128",
+		14: "HEADER_KIND_FLOAT",
+		15: "HEADER_KIND_DOUBLE",
+	}
+	HeaderKind_value = map[string]int32{
+		"HEADER_KIND_UNSPECIFIED": 0,
+		"HEADER_KIND_RAW":         1,
+		"HEADER_KIND_STRING":      2,
+		"HEADER_KIND_BOOL":        3,
+		"HEADER_KIND_INT8":        4,
+		"HEADER_KIND_INT16":       5,
+		"HEADER_KIND_INT32":       6,
+		"HEADER_KIND_INT64":       7,
+		"HEADER_KIND_INT128":      8,
+		"HEADER_KIND_UINT8":       9,
+		"HEADER_KIND_UINT16":      10,
+		"HEADER_KIND_UINT32":      11,
+		"HEADER_KIND_UINT64":      12,
+		"HEADER_KIND_UINT128":     13,
+		"HEADER_KIND_FLOAT":       14,
+		"HEADER_KIND_DOUBLE":      15,
+	}
+)
+
+func (x HeaderKind) Enum() *HeaderKind {
+	p := new(HeaderKind)
+	*p = x
+	return p
+}
+
+func (x HeaderKind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (HeaderKind) Descriptor() protoreflect.EnumDescriptor {
+	return file_messenger_proto_enumTypes[0].Descriptor()
+}
+
+func (HeaderKind) Type() protoreflect.EnumType {
+	return &file_messenger_proto_enumTypes[0]
+}
+
+func (x HeaderKind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use HeaderKind.Descriptor instead.
+func (HeaderKind) EnumDescriptor() ([]byte, []int) {
+	return file_messenger_proto_rawDescGZIP(), []int{0}
+}
+
+type ConsumerKind int32
+
+const (
+	ConsumerKind_CONSUMER_KIND_SINGLE ConsumerKind = 0
+	ConsumerKind_CONSUMER_KIND_GROUP  ConsumerKind = 1
+)
+
+// Enum value maps for ConsumerKind.
+var (
+	ConsumerKind_name = map[int32]string{
+		0: "CONSUMER_KIND_SINGLE",
+		1: "CONSUMER_KIND_GROUP",
+	}
+	ConsumerKind_value = map[string]int32{
+		"CONSUMER_KIND_SINGLE": 0,
+		"CONSUMER_KIND_GROUP":  1,
+	}
+)
+
+func (x ConsumerKind) Enum() *ConsumerKind {
+	p := new(ConsumerKind)
+	*p = x
+	return p
+}
+
+func (x ConsumerKind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ConsumerKind) Descriptor() protoreflect.EnumDescriptor {
+	return file_messenger_proto_enumTypes[1].Descriptor()
+}
+
+func (ConsumerKind) Type() protoreflect.EnumType {
+	return &file_messenger_proto_enumTypes[1]
+}
+
+func (x ConsumerKind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ConsumerKind.Descriptor instead.
+func (ConsumerKind) EnumDescriptor() ([]byte, []int) {
+	return file_messenger_proto_rawDescGZIP(), []int{1}
+}
+
+// Identifier identifies a stream, a topic or a consumer by numeric ID or by name.
+type Identifier struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Kind:
+	//
+	//	*Identifier_Id
+	//	*Identifier_Name
+	Kind          isIdentifier_Kind `protobuf_oneof:"kind"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Identifier) Reset() {
+	*x = Identifier{}
+	mi := &file_messenger_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Identifier) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Identifier) ProtoMessage() {}
+
+func (x *Identifier) ProtoReflect() protoreflect.Message {
+	mi := &file_messenger_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Identifier.ProtoReflect.Descriptor instead.
+func (*Identifier) Descriptor() ([]byte, []int) {
+	return file_messenger_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Identifier) GetKind() isIdentifier_Kind {
+	if x != nil {
+		return x.Kind
+	}
+	return nil
+}
+
+func (x *Identifier) GetId() uint32 {
+	if x != nil {
+		if x, ok := x.Kind.(*Identifier_Id); ok {
+			return x.Id
+		}
+	}
+	return 0
+}
+
+func (x *Identifier) GetName() string {
+	if x != nil {
+		if x, ok := x.Kind.(*Identifier_Name); ok {
+			return x.Name
+		}
+	}
+	return ""
+}
+
+type isIdentifier_Kind interface {
+	isIdentifier_Kind()
+}
+
+type Identifier_Id struct {
+	Id uint32 `protobuf:"varint,1,opt,name=id,proto3,oneof"`
+}
+
+type Identifier_Name struct {
+	Name string `protobuf:"bytes,2,opt,name=name,proto3,oneof"`
+}
+
+func (*Identifier_Id) isIdentifier_Kind() {}
+
+func (*Identifier_Name) isIdentifier_Kind() {}
+
+// HeaderValue is the value of a user header, of kind raw when unspecified.
+type HeaderValue struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Kind          HeaderKind             `protobuf:"varint,1,opt,name=kind,proto3,enum=messenger.v1.HeaderKind" json:"kind,omitempty"`
+	Value         []byte                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HeaderValue) Reset() {
+	*x = HeaderValue{}
+	mi := &file_messenger_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HeaderValue) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HeaderValue) ProtoMessage() {}
+
+func (x *HeaderValue) ProtoReflect() protoreflect.Message {
+	mi := &file_messenger_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HeaderValue.ProtoReflect.Descriptor instead.
+func (*HeaderValue) Descriptor() ([]byte, []int) {
+	return file_messenger_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *HeaderValue) GetKind() HeaderKind {
+	if x != nil {
+		return x.Kind
+	}
+	return HeaderKind_HEADER_KIND_UNSPECIFIED
+}
+
+func (x *HeaderValue) GetValue() []byte {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+// Message is a message polled from a partition.
+type Message struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	PartitionId uint32                 `protobuf:"varint,1,opt,name=partition_id,json=partitionId,proto3" json:"partition_id,omitempty"`
+	Offset      uint64                 `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"`
+	// id is the 16 bytes ID of the message.
+	Id []byte `protobuf:"bytes,3,opt,name=id,proto3" json:"id,omitempty"`
+	// timestamp is the time the message was appended, in microseconds since the epoch.
+	Timestamp uint64 `protobuf:"varint,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// origin_timestamp is the time the message was created, in microseconds since the epoch.
+	OriginTimestamp uint64                  `protobuf:"varint,5,opt,name=origin_timestamp,json=originTimestamp,proto3" json:"origin_timestamp,omitempty"`
+	Headers         map[string]*HeaderValue `protobuf:"bytes,6,rep,name=headers,proto3" json:"headers,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Payload         []byte                  `protobuf:"bytes,7,opt,name=payload,proto3" json:"payload,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Message) Reset() {
+	*x = Message{}
+	mi := &file_messenger_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Message) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Message) ProtoMessage() {}
+
+func (x *Message) ProtoReflect() protoreflect.Message {
+	mi := &file_messenger_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Message.ProtoReflect.Descriptor instead.
+func (*Message) Descriptor() ([]byte, []int) {
+	return file_messenger_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Message) GetPartitionId() uint32 {
+	if x != nil {
+		return x.PartitionId
+	}
+	return 0
+}
+
+func (x *Message) GetOffset() uint64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *Message) GetId() []byte {
+	if x != nil {
+		return x.Id
+	}
+	return nil
+}
+
+func (x *Message) GetTimestamp() uint64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *Message) GetOriginTimestamp() uint64 {
+	if x != nil {
+		return x.OriginTimestamp
+	}
+	return 0
+}
+
+func (x *Message) GetHeaders() map[string]*HeaderValue {
+	if x != nil {
+		return x.Headers
+	}
+	return nil
+}
+
+func (x *Message) GetPayload() []byte {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+// OutgoingMessage is a message to send.
+type OutgoingMessage struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// id is the 16 bytes ID of the message, empty to let the server assign one.
+	Id            []byte                  `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Headers       map[string]*HeaderValue `protobuf:"bytes,2,rep,name=headers,proto3" json:"headers,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Payload       []byte                  `protobuf:"bytes,3,opt,name=payload,proto3" json:"payload,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OutgoingMessage) Reset() {
+	*x = OutgoingMessage{}
+	mi := &file_messenger_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OutgoingMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OutgoingMessage) ProtoMessage() {}
+
+func (x *OutgoingMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_messenger_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OutgoingMessage.ProtoReflect.Descriptor instead.
+func (*OutgoingMessage) Descriptor() ([]byte, []int) {
+	return file_messenger_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *OutgoingMessage) GetId() []byte {
+	if x != nil {
+		return x.Id
+	}
+	return nil
+}
+
+func (x *OutgoingMessage) GetHeaders() map[string]*HeaderValue {
+	if x != nil {
+		return x.Headers
+	}
+	return nil
+}
+
+func (x *OutgoingMessage) GetPayload() []byte {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+// Partitioning selects the partition the messages are appended to, balanced when unset.
+type Partitioning struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Kind:
+	//
+	//	*Partitioning_PartitionId
+	//	*Partitioning_MessageKey
+	Kind          isPartitioning_Kind `protobuf_oneof:"kind"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Partitioning) Reset() {
+	*x = Partitioning{}
+	mi := &file_messenger_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Partitioning) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Partitioning) ProtoMessage() {}
+
+func (x *Partitioning) ProtoReflect() protoreflect.Message {
+	mi := &file_messenger_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Partitioning.ProtoReflect.Descriptor instead.
+func (*Partitioning) Descriptor() ([]byte, []int) {
+	return file_messenger_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Partitioning) GetKind() isPartitioning_Kind {
+	if x != nil {
+		return x.Kind
+	}
+	return nil
+}
+
+func (x *Partitioning) GetPartitionId() uint32 {
+	if x != nil {
+		if x, ok := x.Kind.(*Partitioning_PartitionId); ok {
+			return x.PartitionId
+		}
+	}
+	return 0
+}
+
+func (x *Partitioning) GetMessageKey() []byte {
+	if x != nil {
+		if x, ok := x.Kind.(*Partitioning_MessageKey); ok {
+			return x.MessageKey
+		}
+	}
+	return nil
+}
+
+type isPartitioning_Kind interface {
+	isPartitioning_Kind()
+}
+
+type Partitioning_PartitionId struct {
+	PartitionId uint32 `protobuf:"varint,1,opt,name=partition_id,json=partitionId,proto3,oneof"`
+}
+
+type Partitioning_MessageKey struct {
+	MessageKey []byte `protobuf:"bytes,2,opt,name=message_key,json=messageKey,proto3,oneof"`
+}
+
+func (*Partitioning_PartitionId) isPartitioning_Kind() {}
+
+func (*Partitioning_MessageKey) isPartitioning_Kind() {}
+
+type SendMessagesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Stream        *Identifier            `protobuf:"bytes,1,opt,name=stream,proto3" json:"stream,omitempty"`
+	Topic         *Identifier            `protobuf:"bytes,2,opt,name=topic,proto3" json:"topic,omitempty"`
+	Partitioning  *Partitioning          `protobuf:"bytes,3,opt,name=partitioning,proto3" json:"partitioning,omitempty"`
+	Messages      []*OutgoingMessage     `protobuf:"bytes,4,rep,name=messages,proto3" json:"messages,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SendMessagesRequest) Reset() {
+	*x = SendMessagesRequest{}
+	mi := &file_messenger_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SendMessagesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendMessagesRequest) ProtoMessage() {}
+
+func (x *SendMessagesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_messenger_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendMessagesRequest.ProtoReflect.Descriptor instead.
+func (*SendMessagesRequest) Descriptor() ([]byte, []int) {
+	return file_messenger_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *SendMessagesRequest) GetStream() *Identifier {
+	if x != nil {
+		return x.Stream
+	}
+	return nil
+}
+
+func (x *SendMessagesRequest) GetTopic() *Identifier {
+	if x != nil {
+		return x.Topic
+	}
+	return nil
+}
+
+func (x *SendMessagesRequest) GetPartitioning() *Partitioning {
+	if x != nil {
+		return x.Partitioning
+	}
+	return nil
+}
+
+func (x *SendMessagesRequest) GetMessages() []*OutgoingMessage {
+	if x != nil {
+		return x.Messages
+	}
+	return nil
+}
+
+type SendMessagesResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// reported is set when the server reported where the messages were appended.
+	Reported    bool   `protobuf:"varint,1,opt,name=reported,proto3" json:"reported,omitempty"`
+	PartitionId uint32 `protobuf:"varint,2,opt,name=partition_id,json=partitionId,proto3" json:"partition_id,omitempty"`
+	// base_offset is the offset of the first message, the others following in order.
+	BaseOffset    uint64 `protobuf:"varint,3,opt,name=base_offset,json=baseOffset,proto3" json:"base_offset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SendMessagesResponse) Reset() {
+	*x = SendMessagesResponse{}
+	mi := &file_messenger_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SendMessagesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendMessagesResponse) ProtoMessage() {}
+
+func (x *SendMessagesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_messenger_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendMessagesResponse.ProtoReflect.Descriptor instead.
+func (*SendMessagesResponse) Descriptor() ([]byte, []int) {
+	return file_messenger_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *SendMessagesResponse) GetReported() bool {
+	if x != nil {
+		return x.Reported
+	}
+	return false
+}
+
+func (x *SendMessagesResponse) GetPartitionId() uint32 {
+	if x != nil {
+		return x.PartitionId
+	}
+	return 0
+}
+
+func (x *SendMessagesResponse) GetBaseOffset() uint64 {
+	if x != nil {
+		return x.BaseOffset
+	}
+	return 0
+}
+
+// Consumer is the consumer offsets are stored for, the default consumer when unset.
+type Consumer struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Kind          ConsumerKind           `protobuf:"varint,1,opt,name=kind,proto3,enum=messenger.v1.ConsumerKind" json:"kind,omitempty"`
+	Id            *Identifier            `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Consumer) Reset() {
+	*x = Consumer{}
+	mi := &file_messenger_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Consumer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Consumer) ProtoMessage() {}
+
+func (x *Consumer) ProtoReflect() protoreflect.Message {
+	mi := &file_messenger_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Consumer.ProtoReflect.Descriptor instead.
+func (*Consumer) Descriptor() ([]byte, []int) {
+	return file_messenger_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Consumer) GetKind() ConsumerKind {
+	if x != nil {
+		return x.Kind
+	}
+	return ConsumerKind_CONSUMER_KIND_SINGLE
+}
+
+func (x *Consumer) GetId() *Identifier {
+	if x != nil {
+		return x.Id
+	}
+	return nil
+}
+
+// PollingStrategy selects the messages to poll, the next ones of the consumer when unset.
+type PollingStrategy struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Kind:
+	//
+	//	*PollingStrategy_Offset
+	//	*PollingStrategy_Timestamp
+	//	*PollingStrategy_First
+	//	*PollingStrategy_Last
+	Kind          isPollingStrategy_Kind `protobuf_oneof:"kind"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PollingStrategy) Reset() {
+	*x = PollingStrategy{}
+	mi := &file_messenger_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PollingStrategy) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PollingStrategy) ProtoMessage() {}
+
+func (x *PollingStrategy) ProtoReflect() protoreflect.Message {
+	mi := &file_messenger_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PollingStrategy.ProtoReflect.Descriptor instead.
+func (*PollingStrategy) Descriptor() ([]byte, []int) {
+	return file_messenger_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *PollingStrategy) GetKind() isPollingStrategy_Kind {
+	if x != nil {
+		return x.Kind
+	}
+	return nil
+}
+
+func (x *PollingStrategy) GetOffset() uint64 {
+	if x != nil {
+		if x, ok := x.Kind.(*PollingStrategy_Offset); ok {
+			return x.Offset
+		}
+	}
+	return 0
+}
+
+func (x *PollingStrategy) GetTimestamp() uint64 {
+	if x != nil {
+		if x, ok := x.Kind.(*PollingStrategy_Timestamp); ok {
+			return x.Timestamp
+		}
+	}
+	return 0
+}
+
+func (x *PollingStrategy) GetFirst() bool {
+	if x != nil {
+		if x, ok := x.Kind.(*PollingStrategy_First); ok {
+			return x.First
+		}
+	}
+	return false
+}
+
+func (x *PollingStrategy) GetLast() bool {
+	if x != nil {
+		if x, ok := x.Kind.(*PollingStrategy_Last); ok {
+			return x.Last
+		}
+	}
+	return false
+}
+
+type isPollingStrategy_Kind interface {
+	isPollingStrategy_Kind()
+}
+
+type PollingStrategy_Offset struct {
+	Offset uint64 `protobuf:"varint,1,opt,name=offset,proto3,oneof"`
+}
+
+type PollingStrategy_Timestamp struct {
+	// timestamp polls the messages appended from the time, in microseconds since the epoch.
+	Timestamp uint64 `protobuf:"varint,2,opt,name=timestamp,proto3,oneof"`
+}
+
+type PollingStrategy_First struct {
+	First bool `protobuf:"varint,3,opt,name=first,proto3,oneof"`
+}
+
+type PollingStrategy_Last struct {
+	Last bool `protobuf:"varint,4,opt,name=last,proto3,oneof"`
+}
+
+func (*PollingStrategy_Offset) isPollingStrategy_Kind() {}
+
+func (*PollingStrategy_Timestamp) isPollingStrategy_Kind() {}
+
+func (*PollingStrategy_First) isPollingStrategy_Kind() {}
+
+func (*PollingStrategy_Last) isPollingStrategy_Kind() {}
+
+type PollMessagesRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Stream   *Identifier            `protobuf:"bytes,1,opt,name=stream,proto3" json:"stream,omitempty"`
+	Topic    *Identifier            `protobuf:"bytes,2,opt,name=topic,proto3" json:"topic,omitempty"`
+	Consumer *Consumer              `protobuf:"bytes,3,opt,name=consumer,proto3" json:"consumer,omitempty"`
+	// partition_id is the partition to poll, the one assigned to a consumer group when unset.
+	PartitionId *uint32          `protobuf:"varint,4,opt,name=partition_id,json=partitionId,proto3,oneof" json:"partition_id,omitempty"`
+	Strategy    *PollingStrategy `protobuf:"bytes,5,opt,name=strategy,proto3" json:"strategy,omitempty"`
+	Count       uint32           `protobuf:"varint,6,opt,name=count,proto3" json:"count,omitempty"`
+	AutoCommit  bool             `protobuf:"varint,7,opt,name=auto_commit,json=autoCommit,proto3" json:"auto_commit,omitempty"`
+	// max_wait_ms is how long the server holds the poll when there is no message to return.
+	MaxWaitMs     uint32 `protobuf:"varint,8,opt,name=max_wait_ms,json=maxWaitMs,proto3" json:"max_wait_ms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PollMessagesRequest) Reset() {
+	*x = PollMessagesRequest{}
+	mi := &file_messenger_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PollMessagesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PollMessagesRequest) ProtoMessage() {}
+
+func (x *PollMessagesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_messenger_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PollMessagesRequest.ProtoReflect.Descriptor instead.
+func (*PollMessagesRequest) Descriptor() ([]byte, []int) {
+	return file_messenger_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *PollMessagesRequest) GetStream() *Identifier {
+	if x != nil {
+		return x.Stream
+	}
+	return nil
+}
+
+func (x *PollMessagesRequest) GetTopic() *Identifier {
+	if x != nil {
+		return x.Topic
+	}
+	return nil
+}
+
+func (x *PollMessagesRequest) GetConsumer() *Consumer {
+	if x != nil {
+		return x.Consumer
+	}
+	return nil
+}
+
+func (x *PollMessagesRequest) GetPartitionId() uint32 {
+	if x != nil && x.PartitionId != nil {
+		return *x.PartitionId
+	}
+	return 0
+}
+
+func (x *PollMessagesRequest) GetStrategy() *PollingStrategy {
+	if x != nil {
+		return x.Strategy
+	}
+	return nil
+}
+
+func (x *PollMessagesRequest) GetCount() uint32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *PollMessagesRequest) GetAutoCommit() bool {
+	if x != nil {
+		return x.AutoCommit
+	}
+	return false
+}
+
+func (x *PollMessagesRequest) GetMaxWaitMs() uint32 {
+	if x != nil {
+		return x.MaxWaitMs
+	}
+	return 0
+}
+
+type PollMessagesResponse struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	PartitionId uint32                 `protobuf:"varint,1,opt,name=partition_id,json=partitionId,proto3" json:"partition_id,omitempty"`
+	// current_offset is the offset of the last message of the partition.
+	CurrentOffset uint64     `protobuf:"varint,2,opt,name=current_offset,json=currentOffset,proto3" json:"current_offset,omitempty"`
+	Messages      []*Message `protobuf:"bytes,3,rep,name=messages,proto3" json:"messages,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PollMessagesResponse) Reset() {
+	*x = PollMessagesResponse{}
+	mi := &file_messenger_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PollMessagesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PollMessagesResponse) ProtoMessage() {}
+
+func (x *PollMessagesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_messenger_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PollMessagesResponse.ProtoReflect.Descriptor instead.
+func (*PollMessagesResponse) Descriptor() ([]byte, []int) {
+	return file_messenger_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *PollMessagesResponse) GetPartitionId() uint32 {
+	if x != nil {
+		return x.PartitionId
+	}
+	return 0
+}
+
+func (x *PollMessagesResponse) GetCurrentOffset() uint64 {
+	if x != nil {
+		return x.CurrentOffset
+	}
+	return 0
+}
+
+func (x *PollMessagesResponse) GetMessages() []*Message {
+	if x != nil {
+		return x.Messages
+	}
+	return nil
+}
+
+type StreamMessagesRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Stream      *Identifier            `protobuf:"bytes,1,opt,name=stream,proto3" json:"stream,omitempty"`
+	Topic       *Identifier            `protobuf:"bytes,2,opt,name=topic,proto3" json:"topic,omitempty"`
+	PartitionId uint32                 `protobuf:"varint,3,opt,name=partition_id,json=partitionId,proto3" json:"partition_id,omitempty"`
+	// offset is the offset of the first message to stream.
+	Offset        uint64 `protobuf:"varint,4,opt,name=offset,proto3" json:"offset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamMessagesRequest) Reset() {
+	*x = StreamMessagesRequest{}
+	mi := &file_messenger_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamMessagesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamMessagesRequest) ProtoMessage() {}
+
+func (x *StreamMessagesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_messenger_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamMessagesRequest.ProtoReflect.Descriptor instead.
+func (*StreamMessagesRequest) Descriptor() ([]byte, []int) {
+	return file_messenger_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *StreamMessagesRequest) GetStream() *Identifier {
+	if x != nil {
+		return x.Stream
+	}
+	return nil
+}
+
+func (x *StreamMessagesRequest) GetTopic() *Identifier {
+	if x != nil {
+		return x.Topic
+	}
+	return nil
+}
+
+func (x *StreamMessagesRequest) GetPartitionId() uint32 {
+	if x != nil {
+		return x.PartitionId
+	}
+	return 0
+}
+
+func (x *StreamMessagesRequest) GetOffset() uint64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type StoreConsumerOffsetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Stream        *Identifier            `protobuf:"bytes,1,opt,name=stream,proto3" json:"stream,omitempty"`
+	Topic         *Identifier            `protobuf:"bytes,2,opt,name=topic,proto3" json:"topic,omitempty"`
+	Consumer      *Consumer              `protobuf:"bytes,3,opt,name=consumer,proto3" json:"consumer,omitempty"`
+	PartitionId   *uint32                `protobuf:"varint,4,opt,name=partition_id,json=partitionId,proto3,oneof" json:"partition_id,omitempty"`
+	Offset        uint64                 `protobuf:"varint,5,opt,name=offset,proto3" json:"offset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StoreConsumerOffsetRequest) Reset() {
+	*x = StoreConsumerOffsetRequest{}
+	mi := &file_messenger_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StoreConsumerOffsetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StoreConsumerOffsetRequest) ProtoMessage() {}
+
+func (x *StoreConsumerOffsetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_messenger_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StoreConsumerOffsetRequest.ProtoReflect.Descriptor instead.
+func (*StoreConsumerOffsetRequest) Descriptor() ([]byte, []int) {
+	return file_messenger_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *StoreConsumerOffsetRequest) GetStream() *Identifier {
+	if x != nil {
+		return x.Stream
+	}
+	return nil
+}
+
+func (x *StoreConsumerOffsetRequest) GetTopic() *Identifier {
+	if x != nil {
+		return x.Topic
+	}
+	return nil
+}
+
+func (x *StoreConsumerOffsetRequest) GetConsumer() *Consumer {
+	if x != nil {
+		return x.Consumer
+	}
+	return nil
+}
+
+func (x *StoreConsumerOffsetRequest) GetPartitionId() uint32 {
+	if x != nil && x.PartitionId != nil {
+		return *x.PartitionId
+	}
+	return 0
+}
+
+func (x *StoreConsumerOffsetRequest) GetOffset() uint64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type StoreConsumerOffsetResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StoreConsumerOffsetResponse) Reset() {
+	*x = StoreConsumerOffsetResponse{}
+	mi := &file_messenger_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StoreConsumerOffsetResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StoreConsumerOffsetResponse) ProtoMessage() {}
+
+func (x *StoreConsumerOffsetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_messenger_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StoreConsumerOffsetResponse.ProtoReflect.Descriptor instead.
+func (*StoreConsumerOffsetResponse) Descriptor() ([]byte, []int) {
+	return file_messenger_proto_rawDescGZIP(), []int{13}
+}
+
+type GetConsumerOffsetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Stream        *Identifier            `protobuf:"bytes,1,opt,name=stream,proto3" json:"stream,omitempty"`
+	Topic         *Identifier            `protobuf:"bytes,2,opt,name=topic,proto3" json:"topic,omitempty"`
+	Consumer      *Consumer              `protobuf:"bytes,3,opt,name=consumer,proto3" json:"consumer,omitempty"`
+	PartitionId   *uint32                `protobuf:"varint,4,opt,name=partition_id,json=partitionId,proto3,oneof" json:"partition_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetConsumerOffsetRequest) Reset() {
+	*x = GetConsumerOffsetRequest{}
+	mi := &file_messenger_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetConsumerOffsetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetConsumerOffsetRequest) ProtoMessage() {}
+
+func (x *GetConsumerOffsetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_messenger_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetConsumerOffsetRequest.ProtoReflect.Descriptor instead.
+func (*GetConsumerOffsetRequest) Descriptor() ([]byte, []int) {
+	return file_messenger_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *GetConsumerOffsetRequest) GetStream() *Identifier {
+	if x != nil {
+		return x.Stream
+	}
+	return nil
+}
+
+func (x *GetConsumerOffsetRequest) GetTopic() *Identifier {
+	if x != nil {
+		return x.Topic
+	}
+	return nil
+}
+
+func (x *GetConsumerOffsetRequest) GetConsumer() *Consumer {
+	if x != nil {
+		return x.Consumer
+	}
+	return nil
+}
+
+func (x *GetConsumerOffsetRequest) GetPartitionId() uint32 {
+	if x != nil && x.PartitionId != nil {
+		return *x.PartitionId
+	}
+	return 0
+}
+
+type GetConsumerOffsetResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// stored is set when an offset is stored for the consumer.
+	Stored        bool   `protobuf:"varint,1,opt,name=stored,proto3" json:"stored,omitempty"`
+	PartitionId   uint32 `protobuf:"varint,2,opt,name=partition_id,json=partitionId,proto3" json:"partition_id,omitempty"`
+	CurrentOffset uint64 `protobuf:"varint,3,opt,name=current_offset,json=currentOffset,proto3" json:"current_offset,omitempty"`
+	StoredOffset  uint64 `protobuf:"varint,4,opt,name=stored_offset,json=storedOffset,proto3" json:"stored_offset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetConsumerOffsetResponse) Reset() {
+	*x = GetConsumerOffsetResponse{}
+	mi := &file_messenger_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetConsumerOffsetResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetConsumerOffsetResponse) ProtoMessage() {}
+
+func (x *GetConsumerOffsetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_messenger_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetConsumerOffsetResponse.ProtoReflect.Descriptor instead.
+func (*GetConsumerOffsetResponse) Descriptor() ([]byte, []int) {
+	return file_messenger_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *GetConsumerOffsetResponse) GetStored() bool {
+	if x != nil {
+		return x.Stored
+	}
+	return false
+}
+
+func (x *GetConsumerOffsetResponse) GetPartitionId() uint32 {
+	if x != nil {
+		return x.PartitionId
+	}
+	return 0
+}
+
+func (x *GetConsumerOffsetResponse) GetCurrentOffset() uint64 {
+	if x != nil {
+		return x.CurrentOffset
+	}
+	return 0
+}
+
+func (x *GetConsumerOffsetResponse) GetStoredOffset() uint64 {
+	if x != nil {
+		return x.StoredOffset
+	}
+	return 0
+}
+
+type Stream struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint32                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	TopicsCount   uint32                 `protobuf:"varint,3,opt,name=topics_count,json=topicsCount,proto3" json:"topics_count,omitempty"`
+	MessagesCount uint64                 `protobuf:"varint,4,opt,name=messages_count,json=messagesCount,proto3" json:"messages_count,omitempty"`
+	SizeBytes     uint64                 `protobuf:"varint,5,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`
+	// created_at is the time the stream was created, in microseconds since the epoch.
+	CreatedAt     uint64 `protobuf:"varint,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Stream) Reset() {
+	*x = Stream{}
+	mi := &file_messenger_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Stream) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Stream) ProtoMessage() {}
+
+func (x *Stream) ProtoReflect() protoreflect.Message {
+	mi := &file_messenger_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Stream.ProtoReflect.Descriptor instead.
+func (*Stream) Descriptor() ([]byte, []int) {
+	return file_messenger_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *Stream) GetId() uint32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Stream) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Stream) GetTopicsCount() uint32 {
+	if x != nil {
+		return x.TopicsCount
+	}
+	return 0
+}
+
+func (x *Stream) GetMessagesCount() uint64 {
+	if x != nil {
+		return x.MessagesCount
+	}
+	return 0
+}
+
+func (x *Stream) GetSizeBytes() uint64 {
+	if x != nil {
+		return x.SizeBytes
+	}
+	return 0
+}
+
+func (x *Stream) GetCreatedAt() uint64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+type GetStreamsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStreamsRequest) Reset() {
+	*x = GetStreamsRequest{}
+	mi := &file_messenger_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStreamsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStreamsRequest) ProtoMessage() {}
+
+func (x *GetStreamsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_messenger_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStreamsRequest.ProtoReflect.Descriptor instead.
+func (*GetStreamsRequest) Descriptor() ([]byte, []int) {
+	return file_messenger_proto_rawDescGZIP(), []int{17}
+}
+
+type GetStreamsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Streams       []*Stream              `protobuf:"bytes,1,rep,name=streams,proto3" json:"streams,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStreamsResponse) Reset() {
+	*x = GetStreamsResponse{}
+	mi := &file_messenger_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStreamsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStreamsResponse) ProtoMessage() {}
+
+func (x *GetStreamsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_messenger_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStreamsResponse.ProtoReflect.Descriptor instead.
+func (*GetStreamsResponse) Descriptor() ([]byte, []int) {
+	return file_messenger_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *GetStreamsResponse) GetStreams() []*Stream {
+	if x != nil {
+		return x.Streams
+	}
+	return nil
+}
+
+type CreateStreamRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// id is the ID of the stream, assigned by the server when unset.
+	Id            *uint32 `protobuf:"varint,2,opt,name=id,proto3,oneof" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateStreamRequest) Reset() {
+	*x = CreateStreamRequest{}
+	mi := &file_messenger_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateStreamRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateStreamRequest) ProtoMessage() {}
+
+func (x *CreateStreamRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_messenger_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateStreamRequest.ProtoReflect.Descriptor instead.
+func (*CreateStreamRequest) Descriptor() ([]byte, []int) {
+	return file_messenger_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *CreateStreamRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateStreamRequest) GetId() uint32 {
+	if x != nil && x.Id != nil {
+		return *x.Id
+	}
+	return 0
+}
+
+type DeleteStreamRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Stream        *Identifier            `protobuf:"bytes,1,opt,name=stream,proto3" json:"stream,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteStreamRequest) Reset() {
+	*x = DeleteStreamRequest{}
+	mi := &file_messenger_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteStreamRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteStreamRequest) ProtoMessage() {}
+
+func (x *DeleteStreamRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_messenger_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteStreamRequest.ProtoReflect.Descriptor instead.
+func (*DeleteStreamRequest) Descriptor() ([]byte, []int) {
+	return file_messenger_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *DeleteStreamRequest) GetStream() *Identifier {
+	if x != nil {
+		return x.Stream
+	}
+	return nil
+}
+
+type DeleteStreamResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteStreamResponse) Reset() {
+	*x = DeleteStreamResponse{}
+	mi := &file_messenger_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteStreamResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteStreamResponse) ProtoMessage() {}
+
+func (x *DeleteStreamResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_messenger_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteStreamResponse.ProtoReflect.Descriptor instead.
+func (*DeleteStreamResponse) Descriptor() ([]byte, []int) {
+	return file_messenger_proto_rawDescGZIP(), []int{21}
+}
+
+type Topic struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Id              uint32                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name            string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	PartitionsCount uint32                 `protobuf:"varint,3,opt,name=partitions_count,json=partitionsCount,proto3" json:"partitions_count,omitempty"`
+	MessagesCount   uint64                 `protobuf:"varint,4,opt,name=messages_count,json=messagesCount,proto3" json:"messages_count,omitempty"`
+	SizeBytes       uint64                 `protobuf:"varint,5,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`
+	// message_expiry_us is how long the messages are kept in microseconds, 0 for the server
+	// default and the maximum uint64 for ever.
+	MessageExpiryUs uint64 `protobuf:"varint,6,opt,name=message_expiry_us,json=messageExpiryUs,proto3" json:"message_expiry_us,omitempty"`
+	// max_topic_size is the size in bytes the topic can grow to, 0 for the server default and
+	// the maximum uint64 for no limit.
+	MaxTopicSize      uint64 `protobuf:"varint,7,opt,name=max_topic_size,json=maxTopicSize,proto3" json:"max_topic_size,omitempty"`
+	ReplicationFactor uint32 `protobuf:"varint,8,opt,name=replication_factor,json=replicationFactor,proto3" json:"replication_factor,omitempty"`
+	CreatedAt         uint64 `protobuf:"varint,9,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *Topic) Reset() {
+	*x = Topic{}
+	mi := &file_messenger_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Topic) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Topic) ProtoMessage() {}
+
+func (x *Topic) ProtoReflect() protoreflect.Message {
+	mi := &file_messenger_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Topic.ProtoReflect.Descriptor instead.
+func (*Topic) Descriptor() ([]byte, []int) {
+	return file_messenger_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *Topic) GetId() uint32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Topic) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Topic) GetPartitionsCount() uint32 {
+	if x != nil {
+		return x.PartitionsCount
+	}
+	return 0
+}
+
+func (x *Topic) GetMessagesCount() uint64 {
+	if x != nil {
+		return x.MessagesCount
+	}
+	return 0
+}
+
+func (x *Topic) GetSizeBytes() uint64 {
+	if x != nil {
+		return x.SizeBytes
+	}
+	return 0
+}
+
+func (x *Topic) GetMessageExpiryUs() uint64 {
+	if x != nil {
+		return x.MessageExpiryUs
+	}
+	return 0
+}
+
+func (x *Topic) GetMaxTopicSize() uint64 {
+	if x != nil {
+		return x.MaxTopicSize
+	}
+	return 0
+}
+
+func (x *Topic) GetReplicationFactor() uint32 {
+	if x != nil {
+		return x.ReplicationFactor
+	}
+	return 0
+}
+
+func (x *Topic) GetCreatedAt() uint64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+type GetTopicsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Stream        *Identifier            `protobuf:"bytes,1,opt,name=stream,proto3" json:"stream,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTopicsRequest) Reset() {
+	*x = GetTopicsRequest{}
+	mi := &file_messenger_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTopicsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTopicsRequest) ProtoMessage() {}
+
+func (x *GetTopicsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_messenger_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTopicsRequest.ProtoReflect.Descriptor instead.
+func (*GetTopicsRequest) Descriptor() ([]byte, []int) {
+	return file_messenger_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *GetTopicsRequest) GetStream() *Identifier {
+	if x != nil {
+		return x.Stream
+	}
+	return nil
+}
+
+type GetTopicsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Topics        []*Topic               `protobuf:"bytes,1,rep,name=topics,proto3" json:"topics,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTopicsResponse) Reset() {
+	*x = GetTopicsResponse{}
+	mi := &file_messenger_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTopicsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTopicsResponse) ProtoMessage() {}
+
+func (x *GetTopicsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_messenger_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTopicsResponse.ProtoReflect.Descriptor instead.
+func (*GetTopicsResponse) Descriptor() ([]byte, []int) {
+	return file_messenger_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *GetTopicsResponse) GetTopics() []*Topic {
+	if x != nil {
+		return x.Topics
+	}
+	return nil
+}
+
+type CreateTopicRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Stream          *Identifier            `protobuf:"bytes,1,opt,name=stream,proto3" json:"stream,omitempty"`
+	Name            string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	PartitionsCount uint32                 `protobuf:"varint,3,opt,name=partitions_count,json=partitionsCount,proto3" json:"partitions_count,omitempty"`
+	// id is the ID of the topic, assigned by the server when unset.
+	Id              *uint32 `protobuf:"varint,4,opt,name=id,proto3,oneof" json:"id,omitempty"`
+	MessageExpiryUs uint64  `protobuf:"varint,5,opt,name=message_expiry_us,json=messageExpiryUs,proto3" json:"message_expiry_us,omitempty"`
+	MaxTopicSize    uint64  `protobuf:"varint,6,opt,name=max_topic_size,json=maxTopicSize,proto3" json:"max_topic_size,omitempty"`
+	// replication_factor is left to the server when unset.
+	ReplicationFactor *uint32 `protobuf:"varint,7,opt,name=replication_factor,json=replicationFactor,proto3,oneof" json:"replication_factor,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *CreateTopicRequest) Reset() {
+	*x = CreateTopicRequest{}
+	mi := &file_messenger_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateTopicRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateTopicRequest) ProtoMessage() {}
+
+func (x *CreateTopicRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_messenger_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateTopicRequest.ProtoReflect.Descriptor instead.
+func (*CreateTopicRequest) Descriptor() ([]byte, []int) {
+	return file_messenger_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *CreateTopicRequest) GetStream() *Identifier {
+	if x != nil {
+		return x.Stream
+	}
+	return nil
+}
+
+func (x *CreateTopicRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateTopicRequest) GetPartitionsCount() uint32 {
+	if x != nil {
+		return x.PartitionsCount
+	}
+	return 0
+}
+
+func (x *CreateTopicRequest) GetId() uint32 {
+	if x != nil && x.Id != nil {
+		return *x.Id
+	}
+	return 0
+}
+
+func (x *CreateTopicRequest) GetMessageExpiryUs() uint64 {
+	if x != nil {
+		return x.MessageExpiryUs
+	}
+	return 0
+}
+
+func (x *CreateTopicRequest) GetMaxTopicSize() uint64 {
+	if x != nil {
+		return x.MaxTopicSize
+	}
+	return 0
+}
+
+func (x *CreateTopicRequest) GetReplicationFactor() uint32 {
+	if x != nil && x.ReplicationFactor != nil {
+		return *x.ReplicationFactor
+	}
+	return 0
+}
+
+type DeleteTopicRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Stream        *Identifier            `protobuf:"bytes,1,opt,name=stream,proto3" json:"stream,omitempty"`
+	Topic         *Identifier            `protobuf:"bytes,2,opt,name=topic,proto3" json:"topic,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteTopicRequest) Reset() {
+	*x = DeleteTopicRequest{}
+	mi := &file_messenger_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteTopicRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteTopicRequest) ProtoMessage() {}
+
+func (x *DeleteTopicRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_messenger_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteTopicRequest.ProtoReflect.Descriptor instead.
+func (*DeleteTopicRequest) Descriptor() ([]byte, []int) {
+	return file_messenger_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *DeleteTopicRequest) GetStream() *Identifier {
+	if x != nil {
+		return x.Stream
+	}
+	return nil
+}
+
+func (x *DeleteTopicRequest) GetTopic() *Identifier {
+	if x != nil {
+		return x.Topic
+	}
+	return nil
+}
+
+type DeleteTopicResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteTopicResponse) Reset() {
+	*x = DeleteTopicResponse{}
+	mi := &file_messenger_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteTopicResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteTopicResponse) ProtoMessage() {}
+
+func (x *DeleteTopicResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_messenger_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteTopicResponse.ProtoReflect.Descriptor instead.
+func (*DeleteTopicResponse) Descriptor() ([]byte, []int) {
+	return file_messenger_proto_rawDescGZIP(), []int{27}
+}
+
+var File_messenger_proto protoreflect.FileDescriptor
+
+const file_messenger_proto_rawDesc = "" +
+	"\n" +
+	"\x0fmessenger.proto\x12\fmessenger.v1\"<\n" +
+	"\n" +
+	"Identifier\x12\x10\n" +
+	"\x02id\x18\x01 \x01(\rH\x00R\x02id\x12\x14\n" +
+	"\x04name\x18\x02 \x01(\tH\x00R\x04nameB\x06\n" +
+	"\x04kind\"Q\n" +
+	"\vHeaderValue\x12,\n" +
+	"\x04kind\x18\x01 \x01(\x0e2\x18.messenger.v1.HeaderKindR\x04kind\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\fR\x05value\"\xcc\x02\n" +
+	"\aMessage\x12!\n" +
+	"\fpartition_id\x18\x01 \x01(\rR\vpartitionId\x12\x16\n" +
+	"\x06offset\x18\x02 \x01(\x04R\x06offset\x12\x0e\n" +
+	"\x02id\x18\x03 \x01(\fR\x02id\x12\x1c\n" +
+	"\ttimestamp\x18\x04 \x01(\x04R\ttimestamp\x12)\n" +
+	"\x10origin_timestamp\x18\x05 \x01(\x04R\x0foriginTimestamp\x12<\n" +
+	"\aheaders\x18\x06 \x03(\v2\".messenger.v1.Message.HeadersEntryR\aheaders\x12\x18\n" +
+	"\apayload\x18\a \x01(\fR\apayload\x1aU\n" +
+	"\fHeadersEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12/\n" +
+	"\x05value\x18\x02 \x01(\v2\x19.messenger.v1.HeaderValueR\x05value:\x028\x01\"\xd8\x01\n" +
+	"\x0fOutgoingMessage\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\fR\x02id\x12D\n" +
+	"\aheaders\x18\x02 \x03(\v2*.messenger.v1.OutgoingMessage.HeadersEntryR\aheaders\x12\x18\n" +
+	"\apayload\x18\x03 \x01(\fR\apayload\x1aU\n" +
+	"\fHeadersEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12/\n" +
+	"\x05value\x18\x02 \x01(\v2\x19.messenger.v1.HeaderValueR\x05value:\x028\x01\"^\n" +
+	"\fPartitioning\x12#\n" +
+	"\fpartition_id\x18\x01 \x01(\rH\x00R\vpartitionId\x12!\n" +
+	"\vmessage_key\x18\x02 \x01(\fH\x00R\n" +
+	"messageKeyB\x06\n" +
+	"\x04kind\"\xf2\x01\n" +
+	"\x13SendMessagesRequest\x120\n" +
+	"\x06stream\x18\x01 \x01(\v2\x18.messenger.v1.IdentifierR\x06stream\x12.\n" +
+	"\x05topic\x18\x02 \x01(\v2\x18.messenger.v1.IdentifierR\x05topic\x12>\n" +
+	"\fpartitioning\x18\x03 \x01(\v2\x1a.messenger.v1.PartitioningR\fpartitioning\x129\n" +
+	"\bmessages\x18\x04 \x03(\v2\x1d.messenger.v1.OutgoingMessageR\bmessages\"v\n" +
+	"\x14SendMessagesResponse\x12\x1a\n" +
+	"\breported\x18\x01 \x01(\bR\breported\x12!\n" +
+	"\fpartition_id\x18\x02 \x01(\rR\vpartitionId\x12\x1f\n" +
+	"\vbase_offset\x18\x03 \x01(\x04R\n" +
+	"baseOffset\"d\n" +
+	"\bConsumer\x12.\n" +
+	"\x04kind\x18\x01 \x01(\x0e2\x1a.messenger.v1.ConsumerKindR\x04kind\x12(\n" +
+	"\x02id\x18\x02 \x01(\v2\x18.messenger.v1.IdentifierR\x02id\"\x81\x01\n" +
+	"\x0fPollingStrategy\x12\x18\n" +
+	"\x06offset\x18\x01 \x01(\x04H\x00R\x06offset\x12\x1e\n" +
+	"\ttimestamp\x18\x02 \x01(\x04H\x00R\ttimestamp\x12\x16\n" +
+	"\x05first\x18\x03 \x01(\bH\x00R\x05first\x12\x14\n" +
+	"\x04last\x18\x04 \x01(\bH\x00R\x04lastB\x06\n" +
+	"\x04kind\"\xf6\x02\n" +
+	"\x13PollMessagesRequest\x120\n" +
+	"\x06stream\x18\x01 \x01(\v2\x18.messenger.v1.IdentifierR\x06stream\x12.\n" +
+	"\x05topic\x18\x02 \x01(\v2\x18.messenger.v1.IdentifierR\x05topic\x122\n" +
+	"\bconsumer\x18\x03 \x01(\v2\x16.messenger.v1.ConsumerR\bconsumer\x12&\n" +
+	"\fpartition_id\x18\x04 \x01(\rH\x00R\vpartitionId\x88\x01\x01\x129\n" +
+	"\bstrategy\x18\x05 \x01(\v2\x1d.messenger.v1.PollingStrategyR\bstrategy\x12\x14\n" +
+	"\x05count\x18\x06 \x01(\rR\x05count\x12\x1f\n" +
+	"\vauto_commit\x18\a \x01(\bR\n" +
+	"autoCommit\x12\x1e\n" +
+	"\vmax_wait_ms\x18\b \x01(\rR\tmaxWaitMsB\x0f\n" +
+	"\r_partition_id\"\x93\x01\n" +
+	"\x14PollMessagesResponse\x12!\n" +
+	"\fpartition_id\x18\x01 \x01(\rR\vpartitionId\x12%\n" +
+	"\x0ecurrent_offset\x18\x02 \x01(\x04R\rcurrentOffset\x121\n" +
+	"\bmessages\x18\x03 \x03(\v2\x15.messenger.v1.MessageR\bmessages\"\xb4\x01\n" +
+	"\x15StreamMessagesRequest\x120\n" +
+	"\x06stream\x18\x01 \x01(\v2\x18.messenger.v1.IdentifierR\x06stream\x12.\n" +
+	"\x05topic\x18\x02 \x01(\v2\x18.messenger.v1.IdentifierR\x05topic\x12!\n" +
+	"\fpartition_id\x18\x03 \x01(\rR\vpartitionId\x12\x16\n" +
+	"\x06offset\x18\x04 \x01(\x04R\x06offset\"\x83\x02\n" +
+	"\x1aStoreConsumerOffsetRequest\x120\n" +
+	"\x06stream\x18\x01 \x01(\v2\x18.messenger.v1.IdentifierR\x06stream\x12.\n" +
+	"\x05topic\x18\x02 \x01(\v2\x18.messenger.v1.IdentifierR\x05topic\x122\n" +
+	"\bconsumer\x18\x03 \x01(\v2\x16.messenger.v1.ConsumerR\bconsumer\x12&\n" +
+	"\fpartition_id\x18\x04 \x01(\rH\x00R\vpartitionId\x88\x01\x01\x12\x16\n" +
+	"\x06offset\x18\x05 \x01(\x04R\x06offsetB\x0f\n" +
+	"\r_partition_id\"\x1d\n" +
+	"\x1bStoreConsumerOffsetResponse\"\xe9\x01\n" +
+	"\x18GetConsumerOffsetRequest\x120\n" +
+	"\x06stream\x18\x01 \x01(\v2\x18.messenger.v1.IdentifierR\x06stream\x12.\n" +
+	"\x05topic\x18\x02 \x01(\v2\x18.messenger.v1.IdentifierR\x05topic\x122\n" +
+	"\bconsumer\x18\x03 \x01(\v2\x16.messenger.v1.ConsumerR\bconsumer\x12&\n" +
+	"\fpartition_id\x18\x04 \x01(\rH\x00R\vpartitionId\x88\x01\x01B\x0f\n" +
+	"\r_partition_id\"\xa2\x01\n" +
+	"\x19GetConsumerOffsetResponse\x12\x16\n" +
+	"\x06stored\x18\x01 \x01(\bR\x06stored\x12!\n" +
+	"\fpartition_id\x18\x02 \x01(\rR\vpartitionId\x12%\n" +
+	"\x0ecurrent_offset\x18\x03 \x01(\x04R\rcurrentOffset\x12#\n" +
+	"\rstored_offset\x18\x04 \x01(\x04R\fstoredOffset\"\xb4\x01\n" +
+	"\x06Stream\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\rR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12!\n" +
+	"\ftopics_count\x18\x03 \x01(\rR\vtopicsCount\x12%\n" +
+	"\x0emessages_count\x18\x04 \x01(\x04R\rmessagesCount\x12\x1d\n" +
+	"\n" +
+	"size_bytes\x18\x05 \x01(\x04R\tsizeBytes\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\x04R\tcreatedAt\"\x13\n" +
+	"\x11GetStreamsRequest\"D\n" +
+	"\x12GetStreamsResponse\x12.\n" +
+	"\astreams\x18\x01 \x03(\v2\x14.messenger.v1.StreamR\astreams\"E\n" +
+	"\x13CreateStreamRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x13\n" +
+	"\x02id\x18\x02 \x01(\rH\x00R\x02id\x88\x01\x01B\x05\n" +
+	"\x03_id\"G\n" +
+	"\x13DeleteStreamRequest\x120\n" +
+	"\x06stream\x18\x01 \x01(\v2\x18.messenger.v1.IdentifierR\x06stream\"\x16\n" +
+	"\x14DeleteStreamResponse\"\xbc\x02\n" +
+	"\x05Topic\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\rR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12)\n" +
+	"\x10partitions_count\x18\x03 \x01(\rR\x0fpartitionsCount\x12%\n" +
+	"\x0emessages_count\x18\x04 \x01(\x04R\rmessagesCount\x12\x1d\n" +
+	"\n" +
+	"size_bytes\x18\x05 \x01(\x04R\tsizeBytes\x12*\n" +
+	"\x11message_expiry_us\x18\x06 \x01(\x04R\x0fmessageExpiryUs\x12$\n" +
+	"\x0emax_topic_size\x18\a \x01(\x04R\fmaxTopicSize\x12-\n" +
+	"\x12replication_factor\x18\b \x01(\rR\x11replicationFactor\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\t \x01(\x04R\tcreatedAt\"D\n" +
+	"\x10GetTopicsRequest\x120\n" +
+	"\x06stream\x18\x01 \x01(\v2\x18.messenger.v1.IdentifierR\x06stream\"@\n" +
+	"\x11GetTopicsResponse\x12+\n" +
+	"\x06topics\x18\x01 \x03(\v2\x13.messenger.v1.TopicR\x06topics\"\xbe\x02\n" +
+	"\x12CreateTopicRequest\x120\n" +
+	"\x06stream\x18\x01 \x01(\v2\x18.messenger.v1.IdentifierR\x06stream\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12)\n" +
+	"\x10partitions_count\x18\x03 \x01(\rR\x0fpartitionsCount\x12\x13\n" +
+	"\x02id\x18\x04 \x01(\rH\x00R\x02id\x88\x01\x01\x12*\n" +
+	"\x11message_expiry_us\x18\x05 \x01(\x04R\x0fmessageExpiryUs\x12$\n" +
+	"\x0emax_topic_size\x18\x06 \x01(\x04R\fmaxTopicSize\x122\n" +
+	"\x12replication_factor\x18\a \x01(\rH\x01R\x11replicationFactor\x88\x01\x01B\x05\n" +
+	"\x03_idB\x15\n" +
+	"\x13_replication_factor\"v\n" +
+	"\x12DeleteTopicRequest\x120\n" +
+	"\x06stream\x18\x01 \x01(\v2\x18.messenger.v1.IdentifierR\x06stream\x12.\n" +
+	"\x05topic\x18\x02 \x01(\v2\x18.messenger.v1.IdentifierR\x05topic\"\x15\n" +
+	"\x13DeleteTopicResponse*\x86\x03\n" +
+	"\n" +
+	"HeaderKind\x12\x1b\n" +
+	"\x17HEADER_KIND_UNSPECIFIED\x10\x00\x12\x13\n" +
+	"\x0fHEADER_KIND_RAW\x10\x01\x12\x16\n" +
+	"\x12HEADER_KIND_STRING\x10\x02\x12\x14\n" +
+	"\x10HEADER_KIND_BOOL\x10\x03\x12\x14\n" +
+	"\x10HEADER_KIND_INT8\x10\x04\x12\x15\n" +
+	"\x11HEADER_KIND_INT16\x10\x05\x12\x15\n" +
+	"\x11HEADER_KIND_INT32\x10\x06\x12\x15\n" +
+	"\x11HEADER_KIND_INT64\x10\a\x12\x16\n" +
+	"\x12HEADER_KIND_INT128\x10\b\x12\x15\n" +
+	"\x11HEADER_KIND_UINT8\x10\t\x12\x16\n" +
+	"\x12HEADER_KIND_UINT16\x10\n" +
+	"\x12\x16\n" +
+	"\x12HEADER_KIND_UINT32\x10\v\x12\x16\n" +
+	"\x12HEADER_KIND_UINT64\x10\f\x12\x17\n" +
+	"\x13HEADER_KIND_UINT128\x10\r\x12\x15\n" +
+	"\x11HEADER_KIND_FLOAT\x10\x0e\x12\x16\n" +
+	"\x12HEADER_KIND_DOUBLE\x10\x0f*A\n" +
+	"\fConsumerKind\x12\x18\n" +
+	"\x14CONSUMER_KIND_SINGLE\x10\x00\x12\x17\n" +
+	"\x13CONSUMER_KIND_GROUP\x10\x012\xb4\a\n" +
+	"\tMessenger\x12U\n" +
+	"\fSendMessages\x12!.messenger.v1.SendMessagesRequest\x1a\".messenger.v1.SendMessagesResponse\x12U\n" +
+	"\fPollMessages\x12!.messenger.v1.PollMessagesRequest\x1a\".messenger.v1.PollMessagesResponse\x12N\n" +
+	"\x0eStreamMessages\x12#.messenger.v1.StreamMessagesRequest\x1a\x15.messenger.v1.Message0\x01\x12j\n" +
+	"\x13StoreConsumerOffset\x12(.messenger.v1.StoreConsumerOffsetRequest\x1a).messenger.v1.StoreConsumerOffsetResponse\x12d\n" +
+	"\x11GetConsumerOffset\x12&.messenger.v1.GetConsumerOffsetRequest\x1a'.messenger.v1.GetConsumerOffsetResponse\x12O\n" +
+	"\n" +
+	"GetStreams\x12\x1f.messenger.v1.GetStreamsRequest\x1a .messenger.v1.GetStreamsResponse\x12G\n" +
+	"\fCreateStream\x12!.messenger.v1.CreateStreamRequest\x1a\x14.messenger.v1.Stream\x12U\n" +
+	"\fDeleteStream\x12!.messenger.v1.DeleteStreamRequest\x1a\".messenger.v1.DeleteStreamResponse\x12L\n" +
+	"\tGetTopics\x12\x1e.messenger.v1.GetTopicsRequest\x1a\x1f.messenger.v1.GetTopicsResponse\x12D\n" +
+	"\vCreateTopic\x12 .messenger.v1.CreateTopicRequest\x1a\x13.messenger.v1.Topic\x12R\n" +
+	"\vDeleteTopic\x12 .messenger.v1.DeleteTopicRequest\x1a!.messenger.v1.DeleteTopicResponseB<Z:github.com/apache/messenger/foreign/go/grpcapi/messengerpbb\x06proto3"
+
+var (
+	file_messenger_proto_rawDescOnce sync.Once
+	file_messenger_proto_rawDescData []byte
+)
+
+func file_messenger_proto_rawDescGZIP() []byte {
+	file_messenger_proto_rawDescOnce.Do(func() {
+		file_messenger_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_messenger_proto_rawDesc), len(file_messenger_proto_rawDesc)))
+	})
+	return file_messenger_proto_rawDescData
+}
+
+var file_messenger_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_messenger_proto_msgTypes = make([]protoimpl.MessageInfo, 30)
+var file_messenger_proto_goTypes = []any{
+	(HeaderKind)(0),                     // 0: messenger.v1.HeaderKind
+	(ConsumerKind)(0),                   // 1: messenger.v1.ConsumerKind
+	(*Identifier)(nil),                  // 2: messenger.v1.Identifier
+	(*HeaderValue)(nil),                 // 3: messenger.v1.HeaderValue
+	(*Message)(nil),                     // 4: messenger.v1.Message
+	(*OutgoingMessage)(nil),             // 5: messenger.v1.OutgoingMessage
+	(*Partitioning)(nil),                // 6: messenger.v1.Partitioning
+	(*SendMessagesRequest)(nil),         // 7: messenger.v1.SendMessagesRequest
+	(*SendMessagesResponse)(nil),        // 8: messenger.v1.SendMessagesResponse
+	(*Consumer)(nil),                    // 9: messenger.v1.Consumer
+	(*PollingStrategy)(nil),             // 10: messenger.v1.PollingStrategy
+	(*PollMessagesRequest)(nil),         // 11: messenger.v1.PollMessagesRequest
+	(*PollMessagesResponse)(nil),        // 12: messenger.v1.PollMessagesResponse
+	(*StreamMessagesRequest)(nil),       // 13: messenger.v1.StreamMessagesRequest
+	(*StoreConsumerOffsetRequest)(nil),  // 14: messenger.v1.StoreConsumerOffsetRequest
+	(*StoreConsumerOffsetResponse)(nil), // 15: messenger.v1.StoreConsumerOffsetResponse
+	(*GetConsumerOffsetRequest)(nil),    // 16: messenger.v1.GetConsumerOffsetRequest
+	(*GetConsumerOffsetResponse)(nil),   // 17: messenger.v1.GetConsumerOffsetResponse
+	(*Stream)(nil),                      // 18: messenger.v1.Stream
+	(*GetStreamsRequest)(nil),           // 19: messenger.v1.GetStreamsRequest
+	(*GetStreamsResponse)(nil),          // 20: messenger.v1.GetStreamsResponse
+	(*CreateStreamRequest)(nil),         // 21: messenger.v1.CreateStreamRequest
+	(*DeleteStreamRequest)(nil),         // 22: messenger.v1.DeleteStreamRequest
+	(*DeleteStreamResponse)(nil),        // 23: messenger.v1.DeleteStreamResponse
+	(*Topic)(nil),                       // 24: messenger.v1.Topic
+	(*GetTopicsRequest)(nil),            // 25: messenger.v1.GetTopicsRequest
+	(*GetTopicsResponse)(nil),           // 26: messenger.v1.GetTopicsResponse
+	(*CreateTopicRequest)(nil),          // 27: messenger.v1.CreateTopicRequest
+	(*DeleteTopicRequest)(nil),          // 28: messenger.v1.DeleteTopicRequest
+	(*DeleteTopicResponse)(nil),         // 29: messenger.v1.DeleteTopicResponse
+	nil,                                 // 30: messenger.v1.Message.HeadersEntry
+	nil,                                 // 31: messenger.v1.OutgoingMessage.HeadersEntry
+}
+var file_messenger_proto_depIdxs = []int32{
+	0,  // 0: messenger.v1.HeaderValue.kind:type_name -> messenger.v1.HeaderKind
+	30, // 1: messenger.v1.Message.headers:type_name -> messenger.v1.Message.HeadersEntry
+	31, // 2: messenger.v1.OutgoingMessage.headers:type_name -> messenger.v1.OutgoingMessage.HeadersEntry
+	2,  // 3: messenger.v1.SendMessagesRequest.stream:type_name -> messenger.v1.Identifier
+	2,  // 4: messenger.v1.SendMessagesRequest.topic:type_name -> messenger.v1.Identifier
+	6,  // 5: messenger.v1.SendMessagesRequest.partitioning:type_name -> messenger.v1.Partitioning
+	5,  // 6: messenger.v1.SendMessagesRequest.messages:type_name -> messenger.v1.OutgoingMessage
+	1,  // 7: messenger.v1.Consumer.kind:type_name -> messenger.v1.ConsumerKind
+	2,  // 8: messenger.v1.Consumer.id:type_name -> messenger.v1.Identifier
+	2,  // 9: messenger.v1.PollMessagesRequest.stream:type_name -> messenger.v1.Identifier
+	2,  // 10: messenger.v1.PollMessagesRequest.topic:type_name -> messenger.v1.Identifier
+	9,  // 11: messenger.v1.PollMessagesRequest.consumer:type_name -> messenger.v1.Consumer
+	10, // 12: messenger.v1.PollMessagesRequest.strategy:type_name -> messenger.v1.PollingStrategy
+	4,  // 13: messenger.v1.PollMessagesResponse.messages:type_name -> messenger.v1.Message
+	2,  // 14: messenger.v1.StreamMessagesRequest.stream:type_name -> messenger.v1.Identifier
+	2,  // 15: messenger.v1.StreamMessagesRequest.topic:type_name -> messenger.v1.Identifier
+	2,  // 16: messenger.v1.StoreConsumerOffsetRequest.stream:type_name -> messenger.v1.Identifier
+	2,  // 17: messenger.v1.StoreConsumerOffsetRequest.topic:type_name -> messenger.v1.Identifier
+	9,  // 18: messenger.v1.StoreConsumerOffsetRequest.consumer:type_name -> messenger.v1.Consumer
+	2,  // 19: messenger.v1.GetConsumerOffsetRequest.stream:type_name -> messenger.v1.Identifier
+	2,  // 20: messenger.v1.GetConsumerOffsetRequest.topic:type_name -> messenger.v1.Identifier
+	9,  // 21: messenger.v1.GetConsumerOffsetRequest.consumer:type_name -> messenger.v1.Consumer
+	18, // 22: messenger.v1.GetStreamsResponse.streams:type_name -> messenger.v1.Stream
+	2,  // 23: messenger.v1.DeleteStreamRequest.stream:type_name -> messenger.v1.Identifier
+	2,  // 24: messenger.v1.GetTopicsRequest.stream:type_name -> messenger.v1.Identifier
+	24, // 25: messenger.v1.GetTopicsResponse.topics:type_name -> messenger.v1.Topic
+	2,  // 26: messenger.v1.CreateTopicRequest.stream:type_name -> messenger.v1.Identifier
+	2,  // 27: messenger.v1.DeleteTopicRequest.stream:type_name -> messenger.v1.Identifier
+	2,  // 28: messenger.v1.DeleteTopicRequest.topic:type_name -> messenger.v1.Identifier
+	3,  // 29: messenger.v1.Message.HeadersEntry.value:type_name -> messenger.v1.HeaderValue
+	3,  // 30: messenger.v1.OutgoingMessage.HeadersEntry.value:type_name -> messenger.v1.HeaderValue
+	7,  // 31: messenger.v1.Messenger.SendMessages:input_type -> messenger.v1.SendMessagesRequest
+	11, // 32: messenger.v1.Messenger.PollMessages:input_type -> messenger.v1.PollMessagesRequest
+	13, // 33: messenger.v1.Messenger.StreamMessages:input_type -> messenger.v1.StreamMessagesRequest
+	14, // 34: messenger.v1.Messenger.StoreConsumerOffset:input_type -> messenger.v1.StoreConsumerOffsetRequest
+	16, // 35: messenger.v1.Messenger.GetConsumerOffset:input_type -> messenger.v1.GetConsumerOffsetRequest
+	19, // 36: messenger.v1.Messenger.GetStreams:input_type -> messenger.v1.GetStreamsRequest
+	21, // 37: messenger.v1.Messenger.CreateStream:input_type -> messenger.v1.CreateStreamRequest
+	22, // 38: messenger.v1.Messenger.DeleteStream:input_type -> messenger.v1.DeleteStreamRequest
+	25, // 39: messenger.v1.Messenger.GetTopics:input_type -> messenger.v1.GetTopicsRequest
+	27, // 40: messenger.v1.Messenger.CreateTopic:input_type -> messenger.v1.CreateTopicRequest
+	28, // 41: messenger.v1.Messenger.DeleteTopic:input_type -> messenger.v1.DeleteTopicRequest
+	8,  // 42: messenger.v1.Messenger.SendMessages:output_type -> messenger.v1.SendMessagesResponse
+	12, // 43: messenger.v1.Messenger.PollMessages:output_type -> messenger.v1.PollMessagesResponse
+	4,  // 44: messenger.v1.Messenger.StreamMessages:output_type -> messenger.v1.Message
+	15, // 45: messenger.v1.Messenger.StoreConsumerOffset:output_type -> messenger.v1.StoreConsumerOffsetResponse
+	17, // 46: messenger.v1.Messenger.GetConsumerOffset:output_type -> messenger.v1.GetConsumerOffsetResponse
+	20, // 47: messenger.v1.Messenger.GetStreams:output_type -> messenger.v1.GetStreamsResponse
+	18, // 48: messenger.v1.Messenger.CreateStream:output_type -> messenger.v1.Stream
+	23, // 49: messenger.v1.Messenger.DeleteStream:output_type -> messenger.v1.DeleteStreamResponse
+	26, // 50: messenger.v1.Messenger.GetTopics:output_type -> messenger.v1.GetTopicsResponse
+	24, // 51: messenger.v1.Messenger.CreateTopic:output_type -> messenger.v1.Topic
+	29, // 52: messenger.v1.Messenger.DeleteTopic:output_type -> messenger.v1.DeleteTopicResponse
+	42, // [42:53] is the sub-list for method output_type
+	31, // [31:42] is the sub-list for method input_type
+	31, // [31:31] is the sub-list for extension type_name
+	31, // [31:31] is the sub-list for extension extendee
+	0,  // [0:31] is the sub-list for field type_name
+}
+
+func init() { file_messenger_proto_init() }
+func file_messenger_proto_init() {
+	if File_messenger_proto != nil {
+		return
+	}
+	file_messenger_proto_msgTypes[0].OneofWrappers = []any{
+		(*Identifier_Id)(nil),
+		(*Identifier_Name)(nil),
+	}
+	file_messenger_proto_msgTypes[4].OneofWrappers = []any{
+		(*Partitioning_PartitionId)(nil),
+		(*Partitioning_MessageKey)(nil),
+	}
+	file_messenger_proto_msgTypes[8].OneofWrappers = []any{
+		(*PollingStrategy_Offset)(nil),
+		(*PollingStrategy_Timestamp)(nil),
+		(*PollingStrategy_First)(nil),
+		(*PollingStrategy_Last)(nil),
+	}
+	file_messenger_proto_msgTypes[9].OneofWrappers = []any{}
+	file_messenger_proto_msgTypes[12].OneofWrappers = []any{}
+	file_messenger_proto_msgTypes[14].OneofWrappers = []any{}
+	file_messenger_proto_msgTypes[19].OneofWrappers = []any{}
+	file_messenger_proto_msgTypes[25].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_messenger_proto_rawDesc), len(file_messenger_proto_rawDesc)),
+			NumEnums:      2,
+			NumMessages:   30,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_messenger_proto_goTypes,
+		DependencyIndexes: file_messenger_proto_depIdxs,
+		EnumInfos:         file_messenger_proto_enumTypes,
+		MessageInfos:      file_messenger_proto_msgTypes,
+	}.Build()
+	File_messenger_proto = out.File
+	file_messenger_proto_goTypes = nil
+	file_messenger_proto_depIdxs = nil
+}
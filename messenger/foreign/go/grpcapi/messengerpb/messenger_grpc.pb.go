@@ -0,0 +1,538 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: messenger.proto
+
+package messengerpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Messenger_SendMessages_FullMethodName        = "/messenger.v1.Messenger/SendMessages"
+	Messenger_PollMessages_FullMethodName        = "/messenger.v1.Messenger/PollMessages"
+	Messenger_StreamMessages_FullMethodName      = "/messenger.v1.Messenger/StreamMessages"
+	Messenger_StoreConsumerOffset_FullMethodName = "/messenger.v1.Messenger/StoreConsumerOffset"
+	Messenger_GetConsumerOffset_FullMethodName   = "/messenger.v1.Messenger/GetConsumerOffset"
+	Messenger_GetStreams_FullMethodName          = "/messenger.v1.Messenger/GetStreams"
+	Messenger_CreateStream_FullMethodName        = "/messenger.v1.Messenger/CreateStream"
+	Messenger_DeleteStream_FullMethodName        = "/messenger.v1.Messenger/DeleteStream"
+	Messenger_GetTopics_FullMethodName           = "/messenger.v1.Messenger/GetTopics"
+	Messenger_CreateTopic_FullMethodName         = "/messenger.v1.Messenger/CreateTopic"
+	Messenger_DeleteTopic_FullMethodName         = "/messenger.v1.Messenger/DeleteTopic"
+)
+
+// MessengerClient is the client API for Messenger service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Messenger exposes the messaging and the administration of a Messenger server over gRPC.
+type MessengerClient interface {
+	// SendMessages appends messages to a partition of a topic.
+	SendMessages(ctx context.Context, in *SendMessagesRequest, opts ...grpc.CallOption) (*SendMessagesResponse, error)
+	// PollMessages polls messages of a partition of a topic.
+	PollMessages(ctx context.Context, in *PollMessagesRequest, opts ...grpc.CallOption) (*PollMessagesResponse, error)
+	// StreamMessages streams the messages of a partition from an offset, following the partition
+	// until the call is cancelled.
+	StreamMessages(ctx context.Context, in *StreamMessagesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Message], error)
+	// StoreConsumerOffset stores the offset of a consumer in a partition.
+	StoreConsumerOffset(ctx context.Context, in *StoreConsumerOffsetRequest, opts ...grpc.CallOption) (*StoreConsumerOffsetResponse, error)
+	// GetConsumerOffset returns the offset stored for a consumer in a partition.
+	GetConsumerOffset(ctx context.Context, in *GetConsumerOffsetRequest, opts ...grpc.CallOption) (*GetConsumerOffsetResponse, error)
+	GetStreams(ctx context.Context, in *GetStreamsRequest, opts ...grpc.CallOption) (*GetStreamsResponse, error)
+	CreateStream(ctx context.Context, in *CreateStreamRequest, opts ...grpc.CallOption) (*Stream, error)
+	DeleteStream(ctx context.Context, in *DeleteStreamRequest, opts ...grpc.CallOption) (*DeleteStreamResponse, error)
+	GetTopics(ctx context.Context, in *GetTopicsRequest, opts ...grpc.CallOption) (*GetTopicsResponse, error)
+	CreateTopic(ctx context.Context, in *CreateTopicRequest, opts ...grpc.CallOption) (*Topic, error)
+	DeleteTopic(ctx context.Context, in *DeleteTopicRequest, opts ...grpc.CallOption) (*DeleteTopicResponse, error)
+}
+
+type messengerClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewMessengerClient(cc grpc.ClientConnInterface) MessengerClient {
+	return &messengerClient{cc}
+}
+
+func (c *messengerClient) SendMessages(ctx context.Context, in *SendMessagesRequest, opts ...grpc.CallOption) (*SendMessagesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SendMessagesResponse)
+	err := c.cc.Invoke(ctx, Messenger_SendMessages_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messengerClient) PollMessages(ctx context.Context, in *PollMessagesRequest, opts ...grpc.CallOption) (*PollMessagesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PollMessagesResponse)
+	err := c.cc.Invoke(ctx, Messenger_PollMessages_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messengerClient) StreamMessages(ctx context.Context, in *StreamMessagesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Message], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Messenger_ServiceDesc.Streams[0], Messenger_StreamMessages_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamMessagesRequest, Message]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Messenger_StreamMessagesClient = grpc.ServerStreamingClient[Message]
+
+func (c *messengerClient) StoreConsumerOffset(ctx context.Context, in *StoreConsumerOffsetRequest, opts ...grpc.CallOption) (*StoreConsumerOffsetResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StoreConsumerOffsetResponse)
+	err := c.cc.Invoke(ctx, Messenger_StoreConsumerOffset_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messengerClient) GetConsumerOffset(ctx context.Context, in *GetConsumerOffsetRequest, opts ...grpc.CallOption) (*GetConsumerOffsetResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetConsumerOffsetResponse)
+	err := c.cc.Invoke(ctx, Messenger_GetConsumerOffset_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messengerClient) GetStreams(ctx context.Context, in *GetStreamsRequest, opts ...grpc.CallOption) (*GetStreamsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetStreamsResponse)
+	err := c.cc.Invoke(ctx, Messenger_GetStreams_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messengerClient) CreateStream(ctx context.Context, in *CreateStreamRequest, opts ...grpc.CallOption) (*Stream, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Stream)
+	err := c.cc.Invoke(ctx, Messenger_CreateStream_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messengerClient) DeleteStream(ctx context.Context, in *DeleteStreamRequest, opts ...grpc.CallOption) (*DeleteStreamResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteStreamResponse)
+	err := c.cc.Invoke(ctx, Messenger_DeleteStream_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messengerClient) GetTopics(ctx context.Context, in *GetTopicsRequest, opts ...grpc.CallOption) (*GetTopicsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetTopicsResponse)
+	err := c.cc.Invoke(ctx, Messenger_GetTopics_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messengerClient) CreateTopic(ctx context.Context, in *CreateTopicRequest, opts ...grpc.CallOption) (*Topic, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Topic)
+	err := c.cc.Invoke(ctx, Messenger_CreateTopic_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messengerClient) DeleteTopic(ctx context.Context, in *DeleteTopicRequest, opts ...grpc.CallOption) (*DeleteTopicResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteTopicResponse)
+	err := c.cc.Invoke(ctx, Messenger_DeleteTopic_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// MessengerServer is the server API for Messenger service.
+// All implementations must embed UnimplementedMessengerServer
+// for forward compatibility.
+//
+// Messenger exposes the messaging and the administration of a Messenger server over gRPC.
+type MessengerServer interface {
+	// SendMessages appends messages to a partition of a topic.
+	SendMessages(context.Context, *SendMessagesRequest) (*SendMessagesResponse, error)
+	// PollMessages polls messages of a partition of a topic.
+	PollMessages(context.Context, *PollMessagesRequest) (*PollMessagesResponse, error)
+	// StreamMessages streams the messages of a partition from an offset, following the partition
+	// until the call is cancelled.
+	StreamMessages(*StreamMessagesRequest, grpc.ServerStreamingServer[Message]) error
+	// StoreConsumerOffset stores the offset of a consumer in a partition.
+	StoreConsumerOffset(context.Context, *StoreConsumerOffsetRequest) (*StoreConsumerOffsetResponse, error)
+	// GetConsumerOffset returns the offset stored for a consumer in a partition.
+	GetConsumerOffset(context.Context, *GetConsumerOffsetRequest) (*GetConsumerOffsetResponse, error)
+	GetStreams(context.Context, *GetStreamsRequest) (*GetStreamsResponse, error)
+	CreateStream(context.Context, *CreateStreamRequest) (*Stream, error)
+	DeleteStream(context.Context, *DeleteStreamRequest) (*DeleteStreamResponse, error)
+	GetTopics(context.Context, *GetTopicsRequest) (*GetTopicsResponse, error)
+	CreateTopic(context.Context, *CreateTopicRequest) (*Topic, error)
+	DeleteTopic(context.Context, *DeleteTopicRequest) (*DeleteTopicResponse, error)
+	mustEmbedUnimplementedMessengerServer()
+}
+
+// UnimplementedMessengerServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedMessengerServer struct{}
+
+func (UnimplementedMessengerServer) SendMessages(context.Context, *SendMessagesRequest) (*SendMessagesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SendMessages not implemented")
+}
+func (UnimplementedMessengerServer) PollMessages(context.Context, *PollMessagesRequest) (*PollMessagesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PollMessages not implemented")
+}
+func (UnimplementedMessengerServer) StreamMessages(*StreamMessagesRequest, grpc.ServerStreamingServer[Message]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamMessages not implemented")
+}
+func (UnimplementedMessengerServer) StoreConsumerOffset(context.Context, *StoreConsumerOffsetRequest) (*StoreConsumerOffsetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StoreConsumerOffset not implemented")
+}
+func (UnimplementedMessengerServer) GetConsumerOffset(context.Context, *GetConsumerOffsetRequest) (*GetConsumerOffsetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetConsumerOffset not implemented")
+}
+func (UnimplementedMessengerServer) GetStreams(context.Context, *GetStreamsRequest) (*GetStreamsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStreams not implemented")
+}
+func (UnimplementedMessengerServer) CreateStream(context.Context, *CreateStreamRequest) (*Stream, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateStream not implemented")
+}
+func (UnimplementedMessengerServer) DeleteStream(context.Context, *DeleteStreamRequest) (*DeleteStreamResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteStream not implemented")
+}
+func (UnimplementedMessengerServer) GetTopics(context.Context, *GetTopicsRequest) (*GetTopicsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTopics not implemented")
+}
+func (UnimplementedMessengerServer) CreateTopic(context.Context, *CreateTopicRequest) (*Topic, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateTopic not implemented")
+}
+func (UnimplementedMessengerServer) DeleteTopic(context.Context, *DeleteTopicRequest) (*DeleteTopicResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteTopic not implemented")
+}
+func (UnimplementedMessengerServer) mustEmbedUnimplementedMessengerServer() {}
+func (UnimplementedMessengerServer) testEmbeddedByValue()                   {}
+
+// UnsafeMessengerServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to MessengerServer will
+// result in compilation errors.
+type UnsafeMessengerServer interface {
+	mustEmbedUnimplementedMessengerServer()
+}
+
+func RegisterMessengerServer(s grpc.ServiceRegistrar, srv MessengerServer) {
+	// If the following call pancis, it indicates UnimplementedMessengerServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Messenger_ServiceDesc, srv)
+}
+
+func _Messenger_SendMessages_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SendMessagesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessengerServer).SendMessages(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Messenger_SendMessages_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessengerServer).SendMessages(ctx, req.(*SendMessagesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Messenger_PollMessages_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PollMessagesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessengerServer).PollMessages(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Messenger_PollMessages_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessengerServer).PollMessages(ctx, req.(*PollMessagesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Messenger_StreamMessages_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamMessagesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MessengerServer).StreamMessages(m, &grpc.GenericServerStream[StreamMessagesRequest, Message]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Messenger_StreamMessagesServer = grpc.ServerStreamingServer[Message]
+
+func _Messenger_StoreConsumerOffset_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StoreConsumerOffsetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessengerServer).StoreConsumerOffset(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Messenger_StoreConsumerOffset_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessengerServer).StoreConsumerOffset(ctx, req.(*StoreConsumerOffsetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Messenger_GetConsumerOffset_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetConsumerOffsetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessengerServer).GetConsumerOffset(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Messenger_GetConsumerOffset_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessengerServer).GetConsumerOffset(ctx, req.(*GetConsumerOffsetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Messenger_GetStreams_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStreamsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessengerServer).GetStreams(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Messenger_GetStreams_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessengerServer).GetStreams(ctx, req.(*GetStreamsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Messenger_CreateStream_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateStreamRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessengerServer).CreateStream(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Messenger_CreateStream_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessengerServer).CreateStream(ctx, req.(*CreateStreamRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Messenger_DeleteStream_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteStreamRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessengerServer).DeleteStream(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Messenger_DeleteStream_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessengerServer).DeleteStream(ctx, req.(*DeleteStreamRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Messenger_GetTopics_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTopicsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessengerServer).GetTopics(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Messenger_GetTopics_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessengerServer).GetTopics(ctx, req.(*GetTopicsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Messenger_CreateTopic_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateTopicRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessengerServer).CreateTopic(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Messenger_CreateTopic_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessengerServer).CreateTopic(ctx, req.(*CreateTopicRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Messenger_DeleteTopic_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteTopicRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessengerServer).DeleteTopic(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Messenger_DeleteTopic_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessengerServer).DeleteTopic(ctx, req.(*DeleteTopicRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Messenger_ServiceDesc is the grpc.ServiceDesc for Messenger service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Messenger_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "messenger.v1.Messenger",
+	HandlerType: (*MessengerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SendMessages",
+			Handler:    _Messenger_SendMessages_Handler,
+		},
+		{
+			MethodName: "PollMessages",
+			Handler:    _Messenger_PollMessages_Handler,
+		},
+		{
+			MethodName: "StoreConsumerOffset",
+			Handler:    _Messenger_StoreConsumerOffset_Handler,
+		},
+		{
+			MethodName: "GetConsumerOffset",
+			Handler:    _Messenger_GetConsumerOffset_Handler,
+		},
+		{
+			MethodName: "GetStreams",
+			Handler:    _Messenger_GetStreams_Handler,
+		},
+		{
+			MethodName: "CreateStream",
+			Handler:    _Messenger_CreateStream_Handler,
+		},
+		{
+			MethodName: "DeleteStream",
+			Handler:    _Messenger_DeleteStream_Handler,
+		},
+		{
+			MethodName: "GetTopics",
+			Handler:    _Messenger_GetTopics_Handler,
+		},
+		{
+			MethodName: "CreateTopic",
+			Handler:    _Messenger_CreateTopic_Handler,
+		},
+		{
+			MethodName: "DeleteTopic",
+			Handler:    _Messenger_DeleteTopic_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamMessages",
+			Handler:       _Messenger_StreamMessages_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "messenger.proto",
+}
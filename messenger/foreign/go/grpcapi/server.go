@@ -0,0 +1,282 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package grpcapi serves the messaging and the administration of a Messenger server over gRPC,
+// backed by a messengercli.Client, so the services of the languages without a client of the
+// binary protocol use the broker through the unary and streaming calls of a generated gRPC
+// client. The service is described in messengerpb/messenger.proto. Every call is made with the
+// identity the client logged in with, the authentication of the gRPC callers being left to the
+// interceptors of the grpc.Server.
+package grpcapi
+
+import (
+	"context"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/grpcapi/messengerpb"
+	"github.com/apache/messenger/foreign/go/messengercli"
+	"google.golang.org/grpc"
+)
+
+const (
+	// streamBatchSize is the number of messages StreamMessages polls at once.
+	streamBatchSize = 100
+	// streamMaxWait is how long the server holds the polls of StreamMessages.
+	streamMaxWait = time.Second
+	// streamPollInterval is how long StreamMessages waits before polling again after an empty
+	// poll, for the servers without long polling.
+	streamPollInterval = 100 * time.Millisecond
+)
+
+// Server implements the Messenger gRPC service with a client.
+type Server struct {
+	messengerpb.UnimplementedMessengerServer
+	client messengercli.Client
+}
+
+// NewServer creates a Server calling the server of the client.
+func NewServer(client messengercli.Client) *Server {
+	return &Server{client: client}
+}
+
+// Register registers a Server of the client on the gRPC server.
+func Register(server grpc.ServiceRegistrar, client messengercli.Client) {
+	messengerpb.RegisterMessengerServer(server, NewServer(client))
+}
+
+func (s *Server) SendMessages(_ context.Context, request *messengerpb.SendMessagesRequest) (*messengerpb.SendMessagesResponse, error) {
+	streamId, topicId, err := topicIdentifiers(request.GetStream(), request.GetTopic())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	partitioning, err := partitioning(request.GetPartitioning())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	messages := make([]iggcon.MessengerMessage, 0, len(request.GetMessages()))
+	for _, m := range request.GetMessages() {
+		message, err := toMessage(m)
+		if err != nil {
+			return nil, statusError(err)
+		}
+		messages = append(messages, message)
+	}
+	result, err := s.client.SendMessagesWithResult(streamId, topicId, partitioning, messages, iggcon.ConfirmationDefault)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	if result == nil {
+		return &messengerpb.SendMessagesResponse{}, nil
+	}
+	return &messengerpb.SendMessagesResponse{Reported: true, PartitionId: result.PartitionId, BaseOffset: result.BaseOffset}, nil
+}
+
+func (s *Server) PollMessages(ctx context.Context, request *messengerpb.PollMessagesRequest) (*messengerpb.PollMessagesResponse, error) {
+	streamId, topicId, err := topicIdentifiers(request.GetStream(), request.GetTopic())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	consumer, err := consumer(request.GetConsumer())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	polled, err := messengercli.PollMessages(ctx, s.client, iggcon.PollMessageRequest{
+		StreamId:        streamId,
+		TopicId:         topicId,
+		Consumer:        consumer,
+		PartitionId:     request.GetPartitionId(),
+		PollingStrategy: pollingStrategy(request.GetStrategy()),
+		Count:           int(request.GetCount()),
+		AutoCommit:      request.GetAutoCommit(),
+		MaxWait:         milliseconds(request.GetMaxWaitMs()),
+	})
+	if err != nil {
+		return nil, statusError(err)
+	}
+	response := &messengerpb.PollMessagesResponse{PartitionId: polled.PartitionId, CurrentOffset: polled.CurrentOffset}
+	for _, message := range polled.Messages {
+		response.Messages = append(response.Messages, fromMessage(polled.PartitionId, message))
+	}
+	return response, nil
+}
+
+func (s *Server) StreamMessages(request *messengerpb.StreamMessagesRequest, stream grpc.ServerStreamingServer[messengerpb.Message]) error {
+	streamId, topicId, err := topicIdentifiers(request.GetStream(), request.GetTopic())
+	if err != nil {
+		return statusError(err)
+	}
+	ctx := stream.Context()
+	partitionId := request.GetPartitionId()
+	next := request.GetOffset()
+	for {
+		polled, err := messengercli.PollMessages(ctx, s.client, iggcon.PollMessageRequest{
+			StreamId:        streamId,
+			TopicId:         topicId,
+			PartitionId:     partitionId,
+			PollingStrategy: iggcon.OffsetPollingStrategy(next),
+			Count:           streamBatchSize,
+			MaxWait:         streamMaxWait,
+		})
+		if err != nil {
+			return statusError(err)
+		}
+		sent := false
+		for _, message := range polled.Messages {
+			if message.Header.Offset < next {
+				continue
+			}
+			if err := stream.Send(fromMessage(polled.PartitionId, message)); err != nil {
+				return err
+			}
+			next = message.Header.Offset + 1
+			sent = true
+		}
+		if sent {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return statusError(ctx.Err())
+		case <-time.After(streamPollInterval):
+		}
+	}
+}
+
+func (s *Server) StoreConsumerOffset(_ context.Context, request *messengerpb.StoreConsumerOffsetRequest) (*messengerpb.StoreConsumerOffsetResponse, error) {
+	streamId, topicId, err := topicIdentifiers(request.GetStream(), request.GetTopic())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	consumer, err := consumer(request.GetConsumer())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	if err := s.client.StoreConsumerOffset(consumer, streamId, topicId, request.GetOffset(), request.PartitionId); err != nil {
+		return nil, statusError(err)
+	}
+	return &messengerpb.StoreConsumerOffsetResponse{}, nil
+}
+
+func (s *Server) GetConsumerOffset(_ context.Context, request *messengerpb.GetConsumerOffsetRequest) (*messengerpb.GetConsumerOffsetResponse, error) {
+	streamId, topicId, err := topicIdentifiers(request.GetStream(), request.GetTopic())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	consumer, err := consumer(request.GetConsumer())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	offset, err := s.client.GetConsumerOffset(consumer, streamId, topicId, request.PartitionId)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	if offset == nil {
+		return &messengerpb.GetConsumerOffsetResponse{}, nil
+	}
+	return &messengerpb.GetConsumerOffsetResponse{
+		Stored:        true,
+		PartitionId:   offset.PartitionId,
+		CurrentOffset: offset.CurrentOffset,
+		StoredOffset:  offset.StoredOffset,
+	}, nil
+}
+
+func (s *Server) GetStreams(context.Context, *messengerpb.GetStreamsRequest) (*messengerpb.GetStreamsResponse, error) {
+	streams, err := s.client.GetStreams()
+	if err != nil {
+		return nil, statusError(err)
+	}
+	response := &messengerpb.GetStreamsResponse{}
+	for _, stream := range streams {
+		response.Streams = append(response.Streams, fromStream(stream))
+	}
+	return response, nil
+}
+
+func (s *Server) CreateStream(_ context.Context, request *messengerpb.CreateStreamRequest) (*messengerpb.Stream, error) {
+	stream, err := s.client.CreateStream(request.GetName(), request.Id)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return fromStream(stream.Stream), nil
+}
+
+func (s *Server) DeleteStream(_ context.Context, request *messengerpb.DeleteStreamRequest) (*messengerpb.DeleteStreamResponse, error) {
+	streamId, err := identifier(request.GetStream(), "stream")
+	if err != nil {
+		return nil, statusError(err)
+	}
+	if err := s.client.DeleteStream(streamId); err != nil {
+		return nil, statusError(err)
+	}
+	return &messengerpb.DeleteStreamResponse{}, nil
+}
+
+func (s *Server) GetTopics(_ context.Context, request *messengerpb.GetTopicsRequest) (*messengerpb.GetTopicsResponse, error) {
+	streamId, err := identifier(request.GetStream(), "stream")
+	if err != nil {
+		return nil, statusError(err)
+	}
+	topics, err := s.client.GetTopics(streamId)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	response := &messengerpb.GetTopicsResponse{}
+	for _, topic := range topics {
+		response.Topics = append(response.Topics, fromTopic(topic))
+	}
+	return response, nil
+}
+
+func (s *Server) CreateTopic(ctx context.Context, request *messengerpb.CreateTopicRequest) (*messengerpb.Topic, error) {
+	streamId, err := identifier(request.GetStream(), "stream")
+	if err != nil {
+		return nil, statusError(err)
+	}
+	createRequest := iggcon.CreateTopicRequest{
+		StreamId:        streamId,
+		Name:            request.GetName(),
+		TopicId:         request.Id,
+		PartitionsCount: request.GetPartitionsCount(),
+		MessageExpiry:   iggcon.Expiry(request.GetMessageExpiryUs()),
+		MaxTopicSize:    iggcon.MaxTopicSize(request.GetMaxTopicSize()),
+	}
+	if request.ReplicationFactor != nil {
+		if *request.ReplicationFactor > 255 {
+			return nil, invalidArgument("the replication factor must be at most 255")
+		}
+		replicationFactor := uint8(*request.ReplicationFactor)
+		createRequest.ReplicationFactor = &replicationFactor
+	}
+	topic, err := messengercli.CreateTopic(ctx, s.client, createRequest)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return fromTopic(topic.Topic), nil
+}
+
+func (s *Server) DeleteTopic(_ context.Context, request *messengerpb.DeleteTopicRequest) (*messengerpb.DeleteTopicResponse, error) {
+	streamId, topicId, err := topicIdentifiers(request.GetStream(), request.GetTopic())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	if err := s.client.DeleteTopic(streamId, topicId); err != nil {
+		return nil, statusError(err)
+	}
+	return &messengerpb.DeleteTopicResponse{}, nil
+}
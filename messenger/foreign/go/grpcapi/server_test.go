@@ -0,0 +1,148 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package grpcapi_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/apache/messenger/foreign/go/grpcapi"
+	"github.com/apache/messenger/foreign/go/grpcapi/messengerpb"
+	"github.com/apache/messenger/foreign/go/messengertest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func name(name string) *messengerpb.Identifier {
+	return &messengerpb.Identifier{Kind: &messengerpb.Identifier_Name{Name: name}}
+}
+
+func TestServer(t *testing.T) {
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	grpcapi.Register(server, messengertest.NewClient())
+	go server.Serve(listener)
+	defer server.Stop()
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := messengerpb.NewMessengerClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err = client.CreateStream(ctx, &messengerpb.CreateStreamRequest{Name: "orders"}); err != nil {
+		t.Fatal(err)
+	}
+	topic, err := client.CreateTopic(ctx, &messengerpb.CreateTopicRequest{Stream: name("orders"), Name: "created", PartitionsCount: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if topic.Name != "created" || topic.PartitionsCount != 2 {
+		t.Fatalf("expected the created topic, got %+v", topic)
+	}
+	_, err = client.GetTopics(ctx, &messengerpb.GetTopicsRequest{Stream: name("missing")})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("expected the stream not to be found, got %v", err)
+	}
+
+	_, err = client.SendMessages(ctx, &messengerpb.SendMessagesRequest{
+		Stream:       name("orders"),
+		Topic:        name("created"),
+		Partitioning: &messengerpb.Partitioning{Kind: &messengerpb.Partitioning_PartitionId{PartitionId: 1}},
+		Messages: []*messengerpb.OutgoingMessage{
+			{Payload: []byte("order 1"), Headers: map[string]*messengerpb.HeaderValue{
+				"region": {Kind: messengerpb.HeaderKind_HEADER_KIND_STRING, Value: []byte("eu")},
+			}},
+			{Payload: []byte("order 2")},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	partitionId := uint32(1)
+	polled, err := client.PollMessages(ctx, &messengerpb.PollMessagesRequest{
+		Stream:      name("orders"),
+		Topic:       name("created"),
+		PartitionId: &partitionId,
+		Strategy:    &messengerpb.PollingStrategy{Kind: &messengerpb.PollingStrategy_First{First: true}},
+		Count:       10,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(polled.Messages) != 2 || string(polled.Messages[0].Payload) != "order 1" || string(polled.Messages[1].Payload) != "order 2" {
+		t.Fatalf("expected the sent messages, got %v", polled.Messages)
+	}
+	region := polled.Messages[0].Headers["region"]
+	if region.GetKind() != messengerpb.HeaderKind_HEADER_KIND_STRING || string(region.GetValue()) != "eu" {
+		t.Fatalf("expected the region header, got %v", polled.Messages[0].Headers)
+	}
+
+	_, err = client.StoreConsumerOffset(ctx, &messengerpb.StoreConsumerOffsetRequest{
+		Stream: name("orders"), Topic: name("created"), PartitionId: &partitionId, Offset: 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	offset, err := client.GetConsumerOffset(ctx, &messengerpb.GetConsumerOffsetRequest{
+		Stream: name("orders"), Topic: name("created"), PartitionId: &partitionId,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !offset.Stored || offset.StoredOffset != 1 {
+		t.Fatalf("expected the stored offset 1, got %+v", offset)
+	}
+
+	streamCtx, stopStream := context.WithCancel(ctx)
+	defer stopStream()
+	stream, err := client.StreamMessages(streamCtx, &messengerpb.StreamMessagesRequest{
+		Stream: name("orders"), Topic: name("created"), PartitionId: 1, Offset: 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	message, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if message.Offset != 1 || string(message.Payload) != "order 2" {
+		t.Fatalf("expected the message from offset 1, got %+v", message)
+	}
+	stopStream()
+
+	if _, err = client.DeleteTopic(ctx, &messengerpb.DeleteTopicRequest{Stream: name("orders"), Topic: name("created")}); err != nil {
+		t.Fatal(err)
+	}
+	topics, err := client.GetTopics(ctx, &messengerpb.GetTopicsRequest{Stream: name("orders")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(topics.Topics) != 0 {
+		t.Fatalf("expected no topics, got %v", topics.Topics)
+	}
+}
@@ -0,0 +1,148 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package health
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+)
+
+// InstanceHealth is the last known state of a consumer instance.
+type InstanceHealth struct {
+	Heartbeat
+	// Alive reports whether the instance sent a heartbeat within the aggregator timeout.
+	Alive bool
+}
+
+// Fleet is the health of all the instances an Aggregator heard of.
+type Fleet struct {
+	Instances []InstanceHealth
+	// Alive and Dead count the instances by liveness.
+	Alive int
+	Dead  int
+	// TotalLag is the sum of the lag reported by the alive instances.
+	TotalLag uint64
+	// Unassigned are the partitions of the observed topics no alive instance consumes.
+	Unassigned map[string][]uint32
+}
+
+// Aggregator keeps the last heartbeat of every instance. It is safe for concurrent use.
+type Aggregator struct {
+	timeout time.Duration
+	now     func() time.Time
+
+	mtx       sync.Mutex
+	instances map[string]Heartbeat
+	// partitions holds every partition reported per stream and topic, to detect unassigned ones.
+	partitions map[string]map[uint32]struct{}
+}
+
+// NewAggregator creates an Aggregator considering an instance dead when its last heartbeat is
+// older than timeout, typically a few heartbeat intervals.
+func NewAggregator(timeout time.Duration) *Aggregator {
+	return &Aggregator{
+		timeout:    timeout,
+		now:        time.Now,
+		instances:  map[string]Heartbeat{},
+		partitions: map[string]map[uint32]struct{}{},
+	}
+}
+
+// Observe records a heartbeat, unless a more recent one of the same instance is already known.
+func (a *Aggregator) Observe(h Heartbeat) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	if last, ok := a.instances[h.InstanceId]; ok && last.Timestamp.After(h.Timestamp) {
+		return
+	}
+	a.instances[h.InstanceId] = h
+
+	key := topicKey(h)
+	if a.partitions[key] == nil {
+		a.partitions[key] = map[uint32]struct{}{}
+	}
+	for _, partition := range h.Partitions {
+		a.partitions[key][partition] = struct{}{}
+	}
+}
+
+// ObserveMessages records the heartbeats carried by messages polled from the ops topic, skipping
+// the messages that are not heartbeats.
+func (a *Aggregator) ObserveMessages(messages []iggcon.MessengerMessage) {
+	for _, message := range messages {
+		if h, err := Decode(message); err == nil && h.InstanceId != "" {
+			a.Observe(h)
+		}
+	}
+}
+
+// Forget drops an instance, e.g. one known to be shut down for good.
+func (a *Aggregator) Forget(instanceId string) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	delete(a.instances, instanceId)
+}
+
+// Fleet returns the health of all the instances, sorted by instance ID.
+func (a *Aggregator) Fleet() Fleet {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	now := a.now()
+	fleet := Fleet{Unassigned: map[string][]uint32{}}
+	assigned := map[string]map[uint32]struct{}{}
+	for _, h := range a.instances {
+		alive := now.Sub(h.Timestamp) <= a.timeout
+		fleet.Instances = append(fleet.Instances, InstanceHealth{Heartbeat: h, Alive: alive})
+		if !alive {
+			fleet.Dead++
+			continue
+		}
+		fleet.Alive++
+		fleet.TotalLag += h.TotalLag()
+		key := topicKey(h)
+		if assigned[key] == nil {
+			assigned[key] = map[uint32]struct{}{}
+		}
+		for _, partition := range h.Partitions {
+			assigned[key][partition] = struct{}{}
+		}
+	}
+	sort.Slice(fleet.Instances, func(i, j int) bool {
+		return fleet.Instances[i].InstanceId < fleet.Instances[j].InstanceId
+	})
+
+	for key, partitions := range a.partitions {
+		for partition := range partitions {
+			if _, ok := assigned[key][partition]; !ok {
+				fleet.Unassigned[key] = append(fleet.Unassigned[key], partition)
+			}
+		}
+		sort.Slice(fleet.Unassigned[key], func(i, j int) bool {
+			return fleet.Unassigned[key][i] < fleet.Unassigned[key][j]
+		})
+	}
+	return fleet
+}
+
+func topicKey(h Heartbeat) string {
+	return h.Stream + "/" + h.Topic
+}
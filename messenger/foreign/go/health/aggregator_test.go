@@ -0,0 +1,74 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package health
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+)
+
+func TestAggregator_Fleet(t *testing.T) {
+	now := time.Unix(1000, 0)
+	aggregator := NewAggregator(30 * time.Second)
+	aggregator.now = func() time.Time { return now }
+
+	heartbeat := func(instanceId string, age time.Duration, lag map[uint32]uint64) Heartbeat {
+		h := Heartbeat{InstanceId: instanceId, Stream: "orders", Topic: "created", Lag: lag, Timestamp: now.Add(-age)}
+		for partition := range lag {
+			h.Partitions = append(h.Partitions, partition)
+		}
+		return h
+	}
+	aggregator.Observe(heartbeat("b", 10*time.Second, map[uint32]uint64{1: 5, 2: 7}))
+	aggregator.Observe(heartbeat("a", time.Minute, map[uint32]uint64{3: 100}))
+	// an older heartbeat received late must not replace the last one
+	aggregator.Observe(heartbeat("b", 20*time.Second, map[uint32]uint64{1: 50}))
+
+	message, err := heartbeat("c", 0, map[uint32]uint64{4: 1}).Message()
+	if err != nil {
+		t.Fatal(err)
+	}
+	notHeartbeat, _ := iggcon.NewMessengerMessage([]byte("not json"))
+	aggregator.ObserveMessages([]iggcon.MessengerMessage{message, notHeartbeat})
+
+	fleet := aggregator.Fleet()
+	if fleet.Alive != 2 || fleet.Dead != 1 {
+		t.Fatalf("expected 2 alive and 1 dead instances, got %d and %d", fleet.Alive, fleet.Dead)
+	}
+	if fleet.TotalLag != 13 {
+		t.Fatalf("expected a total lag of 13, got %d", fleet.TotalLag)
+	}
+	var ids []string
+	for _, instance := range fleet.Instances {
+		ids = append(ids, instance.InstanceId)
+	}
+	if !reflect.DeepEqual(ids, []string{"a", "b", "c"}) {
+		t.Fatalf("unexpected instances %v", ids)
+	}
+	if unassigned := fleet.Unassigned["orders/created"]; !reflect.DeepEqual(unassigned, []uint32{3}) {
+		t.Fatalf("expected partition 3 to be unassigned, got %v", unassigned)
+	}
+
+	aggregator.Forget("a")
+	if fleet = aggregator.Fleet(); fleet.Dead != 0 || len(fleet.Instances) != 2 {
+		t.Fatalf("expected the forgotten instance to be dropped, got %+v", fleet)
+	}
+}
@@ -0,0 +1,73 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package health defines the heartbeats consumers publish to an ops topic and aggregates them
+// into a view of the health of the fleet.
+package health
+
+import (
+	"encoding/json"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+)
+
+// Heartbeat is the state a consumer instance periodically reports.
+type Heartbeat struct {
+	// InstanceId identifies the reporting consumer instance.
+	InstanceId string `json:"instance_id"`
+	// Consumer is the name or ID of the consumer (single or group) of the instance.
+	Consumer string `json:"consumer"`
+	// Stream and Topic are the names or IDs of the consumed stream and topic.
+	Stream string `json:"stream"`
+	Topic  string `json:"topic"`
+	// Partitions are the partitions the instance consumed since it started.
+	Partitions []uint32 `json:"partitions"`
+	// Lag is the number of messages the instance is behind in every partition, as of its last poll.
+	Lag map[uint32]uint64 `json:"lag"`
+	// Paused are the partitions the instance paused polling.
+	Paused []uint32 `json:"paused,omitempty"`
+	// MemoryBytes is the heap memory allocated by the instance process.
+	MemoryBytes uint64 `json:"memory_bytes"`
+	// Timestamp is when the heartbeat was emitted.
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// TotalLag returns the sum of the lag of all partitions.
+func (h Heartbeat) TotalLag() uint64 {
+	var total uint64
+	for _, lag := range h.Lag {
+		total += lag
+	}
+	return total
+}
+
+// Message encodes the heartbeat as the payload of a message.
+func (h Heartbeat) Message() (iggcon.MessengerMessage, error) {
+	payload, err := json.Marshal(h)
+	if err != nil {
+		return iggcon.MessengerMessage{}, err
+	}
+	return iggcon.NewMessengerMessage(payload)
+}
+
+// Decode reads the heartbeat carried by a message.
+func Decode(message iggcon.MessengerMessage) (Heartbeat, error) {
+	var h Heartbeat
+	err := json.Unmarshal(message.Payload, &h)
+	return h, err
+}
@@ -0,0 +1,229 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package kafkabridge exposes producers and consumers shaped like the ones of the sarama Kafka
+// client, backed by a messengercli.Client, so the code migrating from Kafka keeps its call sites
+// while swapping the implementation.
+//
+// The Kafka topics are the topics of a single stream, given when creating the producers and
+// consumers. The Kafka partitions, numbered from 0, are the partitions of the topics numbered
+// from 1: partition 0 is partition 1 of the topic. The offsets are the same. The key of a
+// message is carried by the iggcon.KeyHeader, its headers by raw user headers, so both are
+// limited to iggcon.MaxKeySize bytes, and the empty values of the Kafka tombstones are rejected.
+// The consumer groups of sarama are not bridged, the consumer package covers them.
+package kafkabridge
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+)
+
+const (
+	// OffsetNewest starts consuming a partition from the next message appended to it.
+	OffsetNewest int64 = -1
+	// OffsetOldest starts consuming a partition from its oldest message.
+	OffsetOldest int64 = -2
+)
+
+var (
+	// ErrClosed is returned when using a closed producer or consumer.
+	ErrClosed = errors.New("kafkabridge: closed")
+	// ErrAlreadyConsumed is returned by ConsumePartition when the partition is already consumed.
+	ErrAlreadyConsumed = errors.New("kafkabridge: partition already consumed")
+)
+
+// Encoder is the key or the value of a ProducerMessage.
+type Encoder interface {
+	Encode() ([]byte, error)
+	Length() int
+}
+
+// StringEncoder encodes a string as is.
+type StringEncoder string
+
+func (s StringEncoder) Encode() ([]byte, error) {
+	return []byte(s), nil
+}
+
+func (s StringEncoder) Length() int {
+	return len(s)
+}
+
+// ByteEncoder encodes a byte slice as is.
+type ByteEncoder []byte
+
+func (b ByteEncoder) Encode() ([]byte, error) {
+	return b, nil
+}
+
+func (b ByteEncoder) Length() int {
+	return len(b)
+}
+
+// RecordHeader is a header of a message.
+type RecordHeader struct {
+	Key   []byte
+	Value []byte
+}
+
+// ProducerMessage is a message to send, its Partition and Offset being set once it was sent.
+type ProducerMessage struct {
+	Topic string
+	// Key is the key of the message, input of the Partitioner, nil for no key.
+	Key     Encoder
+	Value   Encoder
+	Headers []RecordHeader
+	// Metadata is not sent, it is passed back along with the message to the application.
+	Metadata any
+	// Partition is the partition the message was sent to, or the one to send it to without
+	// Partitioner.
+	Partition int32
+	// Offset is the offset the message was appended at, -1 when the server does not report it.
+	Offset int64
+	// Timestamp is the origin timestamp of the message, the time it was sent when zero.
+	Timestamp time.Time
+}
+
+// ProducerError is the failure to send a message.
+type ProducerError struct {
+	Msg *ProducerMessage
+	Err error
+}
+
+func (e *ProducerError) Error() string {
+	return fmt.Sprintf("kafkabridge: failed to produce message to topic %s: %v", e.Msg.Topic, e.Err)
+}
+
+func (e *ProducerError) Unwrap() error {
+	return e.Err
+}
+
+// ProducerErrors are the failures to send several messages.
+type ProducerErrors []*ProducerError
+
+func (e ProducerErrors) Error() string {
+	return fmt.Sprintf("kafkabridge: failed to deliver %d message(s)", len(e))
+}
+
+// ConsumerMessage is a message polled from a partition.
+type ConsumerMessage struct {
+	Headers []*RecordHeader
+	// Timestamp is the origin timestamp of the message.
+	Timestamp time.Time
+	// BlockTimestamp is the time the server appended the message.
+	BlockTimestamp time.Time
+
+	Key, Value []byte
+	Topic      string
+	Partition  int32
+	Offset     int64
+}
+
+// ConsumerError is the failure to poll a partition.
+type ConsumerError struct {
+	Topic     string
+	Partition int32
+	Err       error
+}
+
+func (e *ConsumerError) Error() string {
+	return fmt.Sprintf("kafkabridge: error while consuming %s/%d: %v", e.Topic, e.Partition, e.Err)
+}
+
+func (e *ConsumerError) Unwrap() error {
+	return e.Err
+}
+
+// ConsumerErrors are the failures to poll a partition.
+type ConsumerErrors []*ConsumerError
+
+func (e ConsumerErrors) Error() string {
+	return fmt.Sprintf("kafkabridge: %d error(s) while consuming", len(e))
+}
+
+// toMessage converts a ProducerMessage to the message sent to the server.
+func toMessage(msg *ProducerMessage) (iggcon.MessengerMessage, error) {
+	if msg.Value == nil {
+		return iggcon.MessengerMessage{}, errors.New("kafkabridge: messages without a value are not supported")
+	}
+	value, err := msg.Value.Encode()
+	if err != nil {
+		return iggcon.MessengerMessage{}, err
+	}
+	var opts []iggcon.MessengerMessageOpt
+	if len(msg.Headers) > 0 {
+		headers := make(map[iggcon.HeaderKey]iggcon.HeaderValue, len(msg.Headers))
+		for _, header := range msg.Headers {
+			key, err := iggcon.NewHeaderKey(string(header.Key))
+			if err != nil {
+				return iggcon.MessengerMessage{}, err
+			}
+			headers[key] = iggcon.HeaderValue{Kind: iggcon.Raw, Value: header.Value}
+		}
+		opts = append(opts, iggcon.WithUserHeaders(headers))
+	}
+	key, err := encodeKey(msg)
+	if err != nil {
+		return iggcon.MessengerMessage{}, err
+	}
+	if key != nil {
+		opts = append(opts, iggcon.WithKey(key))
+	}
+	if !msg.Timestamp.IsZero() {
+		opts = append(opts, iggcon.WithTimestamp(msg.Timestamp))
+	}
+	return iggcon.NewMessengerMessage(value, opts...)
+}
+
+func encodeKey(msg *ProducerMessage) ([]byte, error) {
+	if msg.Key == nil {
+		return nil, nil
+	}
+	return msg.Key.Encode()
+}
+
+// fromMessage converts a message polled from a partition to a ConsumerMessage.
+func fromMessage(topic string, partition int32, message iggcon.MessengerMessage) *ConsumerMessage {
+	consumed := &ConsumerMessage{
+		Timestamp:      time.UnixMicro(int64(message.Header.OriginTimestamp)),
+		BlockTimestamp: time.UnixMicro(int64(message.Header.Timestamp)),
+		Key:            message.Key(),
+		Value:          message.Payload,
+		Topic:          topic,
+		Partition:      partition,
+		Offset:         int64(message.Header.Offset),
+	}
+	headers, err := iggcon.DeserializeHeaders(message.UserHeaders)
+	if err != nil {
+		return consumed
+	}
+	for key, value := range headers {
+		if key.Value == iggcon.KeyHeader {
+			continue
+		}
+		consumed.Headers = append(consumed.Headers, &RecordHeader{Key: []byte(key.Value), Value: value.Value})
+	}
+	// the user headers are not ordered
+	sort.Slice(consumed.Headers, func(i, j int) bool {
+		return string(consumed.Headers[i].Key) < string(consumed.Headers[j].Key)
+	})
+	return consumed
+}
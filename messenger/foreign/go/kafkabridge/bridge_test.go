@@ -0,0 +1,136 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package kafkabridge_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/kafkabridge"
+	"github.com/apache/messenger/foreign/go/messengertest"
+)
+
+func TestBridge(t *testing.T) {
+	client := messengertest.NewClient()
+	if _, err := client.CreateStream("shop", nil); err != nil {
+		t.Fatal(err)
+	}
+	streamId := iggcon.MustIdentifier("shop")
+	if _, err := client.CreateTopic(streamId, "orders", 3, iggcon.CompressionAlgorithmNone, 0, 0, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	producer, err := kafkabridge.NewSyncProducer(client, streamId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	partition, offset, err := producer.SendMessage(&kafkabridge.ProducerMessage{
+		Topic:   "orders",
+		Key:     kafkabridge.StringEncoder("customer-1"),
+		Value:   kafkabridge.StringEncoder("order 1"),
+		Headers: []kafkabridge.RecordHeader{{Key: []byte("source"), Value: []byte("web")}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := int32(iggcon.Murmur2Partitioner().Partition([]byte("customer-1"), 3)) - 1
+	if partition != expected || offset != 0 {
+		t.Fatalf("expected offset 0 of partition %d, got offset %d of partition %d", expected, offset, partition)
+	}
+	if _, _, err = producer.SendMessage(&kafkabridge.ProducerMessage{Topic: "orders", Key: kafkabridge.StringEncoder("customer-1")}); err == nil {
+		t.Fatal("expected a message without a value to be rejected")
+	}
+
+	consumer, err := kafkabridge.NewConsumer(client, streamId, kafkabridge.WithMaxWait(10*time.Millisecond), kafkabridge.WithPollInterval(time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer consumer.Close()
+	partitions, err := consumer.Partitions("orders")
+	if err != nil || fmt.Sprint(partitions) != "[0 1 2]" {
+		t.Fatalf("expected the partitions 0 to 2, got %v (%v)", partitions, err)
+	}
+	oldest, err := consumer.ConsumePartition("orders", partition, kafkabridge.OffsetOldest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = consumer.ConsumePartition("orders", partition, kafkabridge.OffsetOldest); !errors.Is(err, kafkabridge.ErrAlreadyConsumed) {
+		t.Fatalf("expected the partition to be already consumed, got %v", err)
+	}
+	if err := oldest.Close(); err != nil {
+		t.Fatal(err)
+	}
+	newest, err := consumer.ConsumePartition("orders", partition, kafkabridge.OffsetNewest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	async, err := kafkabridge.NewAsyncProducer(client, streamId, kafkabridge.WithReturnSuccesses())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 2; i <= 3; i++ {
+		async.Input() <- &kafkabridge.ProducerMessage{
+			Topic: "orders",
+			Key:   kafkabridge.StringEncoder("customer-1"),
+			Value: kafkabridge.StringEncoder(fmt.Sprintf("order %d", i)),
+		}
+	}
+	for i := 0; i < 2; i++ {
+		select {
+		case msg := <-async.Successes():
+			if msg.Partition != partition || msg.Offset != int64(i+1) {
+				t.Fatalf("expected offset %d of partition %d, got %d of %d", i+1, partition, msg.Offset, msg.Partition)
+			}
+		case err := <-async.Errors():
+			t.Fatal(err)
+		}
+	}
+	if err := async.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var values []string
+	for message := range newest.Messages() {
+		values = append(values, string(message.Value))
+		if string(message.Key) != "customer-1" || message.Topic != "orders" || message.Partition != partition {
+			t.Fatalf("unexpected message %+v", message)
+		}
+		if len(values) == 2 {
+			break
+		}
+	}
+	if fmt.Sprint(values) != "[order 2 order 3]" || newest.HighWaterMarkOffset() != 3 {
+		t.Fatalf("expected the orders sent after consuming from the newest offset, got %v up to %d", values, newest.HighWaterMarkOffset())
+	}
+
+	if err := newest.Close(); err != nil {
+		t.Fatal(err)
+	}
+	oldest, err = consumer.ConsumePartition("orders", partition, kafkabridge.OffsetOldest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := <-oldest.Messages()
+	if string(first.Value) != "order 1" || len(first.Headers) != 1 || string(first.Headers[0].Key) != "source" || string(first.Headers[0].Value) != "web" {
+		t.Fatalf("expected the first order with its header, got %+v", first)
+	}
+}
@@ -0,0 +1,331 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package kafkabridge
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	ierror "github.com/apache/messenger/foreign/go/errors"
+	"github.com/apache/messenger/foreign/go/messengercli"
+)
+
+// Consumer consumes the partitions of the topics of a stream, like sarama.Consumer. The
+// partitions are polled without storing any offset.
+type Consumer interface {
+	// Topics returns the names of the topics of the stream.
+	Topics() ([]string, error)
+	// Partitions returns the partitions of the topic, numbered from 0.
+	Partitions(topic string) ([]int32, error)
+	// ConsumePartition starts consuming the partition from the given offset, OffsetNewest or
+	// OffsetOldest. A partition is consumed by a single PartitionConsumer at a time.
+	ConsumePartition(topic string, partition int32, offset int64) (PartitionConsumer, error)
+	// Close closes the partition consumers still running, the client is left open.
+	Close() error
+}
+
+// PartitionConsumer passes the messages of a partition to its Messages channel, and the
+// failed polls to its Errors channel, like sarama.PartitionConsumer. Both channels must be
+// read, otherwise the consumer blocks.
+type PartitionConsumer interface {
+	// AsyncClose stops polling, the Messages and Errors channels are closed once stopped.
+	AsyncClose()
+	// Close stops polling and waits for the consumer to stop, returning ConsumerErrors for the
+	// failed polls which were not read from Errors.
+	Close() error
+	Messages() <-chan *ConsumerMessage
+	Errors() <-chan *ConsumerError
+	// HighWaterMarkOffset returns the offset of the next message appended to the partition, as
+	// of the last poll.
+	HighWaterMarkOffset() int64
+}
+
+type partitionKey struct {
+	topic     string
+	partition int32
+}
+
+type consumer struct {
+	client   messengercli.Client
+	streamId iggcon.Identifier
+	opts     Options
+
+	mtx      sync.Mutex
+	closed   bool
+	children map[partitionKey]*partitionConsumer
+}
+
+// NewConsumer creates a Consumer of the topics of the given stream.
+func NewConsumer(client messengercli.Client, streamId iggcon.Identifier, options ...Option) (Consumer, error) {
+	if client == nil {
+		return nil, errors.New("kafkabridge: client is required")
+	}
+	opts := getOptions(options)
+	if opts.BatchSize == 0 {
+		return nil, errors.New("kafkabridge: batch size must be greater than zero")
+	}
+	return &consumer{
+		client:   client,
+		streamId: streamId,
+		opts:     opts,
+		children: map[partitionKey]*partitionConsumer{},
+	}, nil
+}
+
+func (c *consumer) Topics() ([]string, error) {
+	topics, err := c.client.GetTopics(c.streamId)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(topics))
+	for _, topic := range topics {
+		names = append(names, topic.Name)
+	}
+	return names, nil
+}
+
+func (c *consumer) Partitions(topic string) ([]int32, error) {
+	details, err := c.topic(topic)
+	if err != nil {
+		return nil, err
+	}
+	partitions := make([]int32, 0, details.PartitionsCount)
+	for partition := int32(0); partition < int32(details.PartitionsCount); partition++ {
+		partitions = append(partitions, partition)
+	}
+	return partitions, nil
+}
+
+func (c *consumer) topic(topic string) (*iggcon.TopicDetails, error) {
+	topicId, err := iggcon.NewIdentifier(topic)
+	if err != nil {
+		return nil, err
+	}
+	return c.client.GetTopic(c.streamId, topicId)
+}
+
+func (c *consumer) ConsumePartition(topic string, partition int32, offset int64) (PartitionConsumer, error) {
+	details, err := c.topic(topic)
+	if err != nil {
+		return nil, err
+	}
+	var current *iggcon.PartitionContract
+	for i := range details.Partitions {
+		if int64(details.Partitions[i].Id) == int64(partition)+1 {
+			current = &details.Partitions[i]
+		}
+	}
+	if current == nil {
+		return nil, ierror.MapFromCode(3007)
+	}
+	highWaterMark := int64(0)
+	if current.MessagesCount > 0 {
+		highWaterMark = int64(current.CurrentOffset) + 1
+	}
+	switch {
+	case offset == OffsetNewest:
+		offset = highWaterMark
+	case offset == OffsetOldest:
+		offset = 0
+	case offset < 0:
+		return nil, errors.New("kafkabridge: invalid offset")
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if c.closed {
+		return nil, ErrClosed
+	}
+	key := partitionKey{topic, partition}
+	if _, ok := c.children[key]; ok {
+		return nil, ErrAlreadyConsumed
+	}
+	child := &partitionConsumer{
+		parent:   c,
+		key:      key,
+		topicId:  details.Id,
+		next:     uint64(offset),
+		messages: make(chan *ConsumerMessage, c.opts.ChannelBufferSize),
+		errors:   make(chan *ConsumerError, c.opts.ChannelBufferSize),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	child.highWaterMark.Store(highWaterMark)
+	c.children[key] = child
+	go child.run()
+	return child, nil
+}
+
+func (c *consumer) Close() error {
+	c.mtx.Lock()
+	c.closed = true
+	children := make([]*partitionConsumer, 0, len(c.children))
+	for _, child := range c.children {
+		children = append(children, child)
+	}
+	c.mtx.Unlock()
+
+	var errs ConsumerErrors
+	for _, child := range children {
+		var childErrs ConsumerErrors
+		if errors.As(child.Close(), &childErrs) {
+			errs = append(errs, childErrs...)
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+type partitionConsumer struct {
+	parent  *consumer
+	key     partitionKey
+	topicId uint32
+	// next is the offset of the next message to poll.
+	next          uint64
+	highWaterMark atomic.Int64
+
+	messages  chan *ConsumerMessage
+	errors    chan *ConsumerError
+	stop      chan struct{}
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+func (pc *partitionConsumer) run() {
+	defer close(pc.done)
+	defer close(pc.messages)
+	defer close(pc.errors)
+	defer func() {
+		pc.parent.mtx.Lock()
+		delete(pc.parent.children, pc.key)
+		pc.parent.mtx.Unlock()
+	}()
+
+	opts := pc.parent.opts
+	topicId := iggcon.MustIdentifier(pc.topicId)
+	partitionId := uint32(pc.key.partition) + 1
+	for {
+		select {
+		case <-pc.stop:
+			return
+		default:
+		}
+		polled, err := pc.parent.client.PollMessagesWithWait(
+			pc.parent.streamId,
+			topicId,
+			iggcon.DefaultConsumer(),
+			iggcon.OffsetPollingStrategy(pc.next),
+			opts.BatchSize,
+			false,
+			&partitionId,
+			opts.MaxWait,
+		)
+		if err != nil {
+			if !pc.send(nil, &ConsumerError{Topic: pc.key.topic, Partition: pc.key.partition, Err: err}) || !pc.pause(opts.PollInterval) {
+				return
+			}
+			continue
+		}
+		if polled == nil || len(polled.Messages) == 0 {
+			if !pc.pause(opts.PollInterval) {
+				return
+			}
+			continue
+		}
+		pc.highWaterMark.Store(int64(polled.CurrentOffset) + 1)
+		for _, message := range polled.Messages {
+			if message.Header.Offset < pc.next {
+				continue
+			}
+			if !pc.send(fromMessage(pc.key.topic, pc.key.partition, message), nil) {
+				return
+			}
+			pc.next = message.Header.Offset + 1
+		}
+	}
+}
+
+// send passes a message or an error to its channel, reporting false when the consumer stopped.
+func (pc *partitionConsumer) send(message *ConsumerMessage, err *ConsumerError) bool {
+	if err != nil {
+		select {
+		case pc.errors <- err:
+			return true
+		case <-pc.stop:
+			return false
+		}
+	}
+	select {
+	case pc.messages <- message:
+		return true
+	case <-pc.stop:
+		return false
+	}
+}
+
+// pause waits for the interval, reporting false when the consumer stopped.
+func (pc *partitionConsumer) pause(interval time.Duration) bool {
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-pc.stop:
+		return false
+	}
+}
+
+func (pc *partitionConsumer) AsyncClose() {
+	pc.closeOnce.Do(func() {
+		close(pc.stop)
+	})
+}
+
+func (pc *partitionConsumer) Close() error {
+	pc.AsyncClose()
+	go func() {
+		for range pc.messages {
+		}
+	}()
+	var errs ConsumerErrors
+	for err := range pc.errors {
+		errs = append(errs, err)
+	}
+	<-pc.done
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (pc *partitionConsumer) Messages() <-chan *ConsumerMessage {
+	return pc.messages
+}
+
+func (pc *partitionConsumer) Errors() <-chan *ConsumerError {
+	return pc.errors
+}
+
+func (pc *partitionConsumer) HighWaterMarkOffset() int64 {
+	return pc.highWaterMark.Load()
+}
@@ -0,0 +1,125 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package kafkabridge
+
+import (
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+)
+
+type Option func(opts *Options)
+
+type Options struct {
+	// Partitioner computes the partition of the messages from their key, nil to send them to
+	// the Partition set on them.
+	Partitioner iggcon.Partitioner
+	// Confirmation is the acknowledgement level of the sent messages.
+	Confirmation iggcon.Confirmation
+	// ReturnSuccesses makes an AsyncProducer pass the sent messages to its Successes channel.
+	ReturnSuccesses bool
+	// ChannelBufferSize is the size of the channels of the producers and consumers.
+	ChannelBufferSize int
+	// BatchSize is the maximum number of messages sent by an AsyncProducer or polled by a
+	// PartitionConsumer in a single request.
+	BatchSize uint32
+	// MaxWait is how long the server holds the polls of a PartitionConsumer when the partition
+	// has no new message.
+	MaxWait time.Duration
+	// PollInterval is the pause of a PartitionConsumer after an empty or failed poll.
+	PollInterval time.Duration
+}
+
+func GetDefaultOptions() Options {
+	return Options{
+		Partitioner:       iggcon.Murmur2Partitioner(),
+		ChannelBufferSize: 256,
+		BatchSize:         500,
+		MaxWait:           500 * time.Millisecond,
+		PollInterval:      100 * time.Millisecond,
+	}
+}
+
+// WithPartitioner computes the partition of the messages from their key with the partitioner,
+// iggcon.Murmur2Partitioner by default like the Kafka clients.
+func WithPartitioner(partitioner iggcon.Partitioner) Option {
+	return func(opts *Options) {
+		opts.Partitioner = partitioner
+	}
+}
+
+// WithManualPartitioning sends the messages to the Partition set on them, like the manual
+// partitioner of sarama.
+func WithManualPartitioning() Option {
+	return func(opts *Options) {
+		opts.Partitioner = nil
+	}
+}
+
+// WithConfirmation sets the acknowledgement level of the sent messages.
+func WithConfirmation(confirmation iggcon.Confirmation) Option {
+	return func(opts *Options) {
+		opts.Confirmation = confirmation
+	}
+}
+
+// WithReturnSuccesses makes an AsyncProducer pass the sent messages to its Successes channel,
+// which must then be read.
+func WithReturnSuccesses() Option {
+	return func(opts *Options) {
+		opts.ReturnSuccesses = true
+	}
+}
+
+// WithChannelBufferSize sets the size of the channels of the producers and consumers.
+func WithChannelBufferSize(size int) Option {
+	return func(opts *Options) {
+		opts.ChannelBufferSize = size
+	}
+}
+
+// WithBatchSize sets the maximum number of messages sent or polled in a single request.
+func WithBatchSize(size uint32) Option {
+	return func(opts *Options) {
+		opts.BatchSize = size
+	}
+}
+
+// WithMaxWait sets how long the server holds the polls when the partition has no new message.
+func WithMaxWait(maxWait time.Duration) Option {
+	return func(opts *Options) {
+		opts.MaxWait = maxWait
+	}
+}
+
+// WithPollInterval sets the pause of a PartitionConsumer after an empty or failed poll.
+func WithPollInterval(interval time.Duration) Option {
+	return func(opts *Options) {
+		opts.PollInterval = interval
+	}
+}
+
+func getOptions(options []Option) Options {
+	opts := GetDefaultOptions()
+	for _, opt := range options {
+		if opt != nil {
+			opt(&opts)
+		}
+	}
+	return opts
+}
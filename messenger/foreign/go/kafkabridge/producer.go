@@ -0,0 +1,311 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package kafkabridge
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengercli"
+)
+
+// SyncProducer sends messages and waits for their acknowledgement, like sarama.SyncProducer.
+type SyncProducer interface {
+	// SendMessage sends a message and returns the partition and the offset it was appended at.
+	SendMessage(msg *ProducerMessage) (partition int32, offset int64, err error)
+	// SendMessages sends the messages, returning ProducerErrors for the ones which failed.
+	SendMessages(msgs []*ProducerMessage) error
+	// Close stops the producer, the client is left open.
+	Close() error
+}
+
+// AsyncProducer sends the messages written to its Input channel in the background, like
+// sarama.AsyncProducer. The Errors channel must be read, as well as the Successes channel with
+// WithReturnSuccesses, otherwise the producer blocks.
+type AsyncProducer interface {
+	// AsyncClose stops accepting messages, Input must not be written to anymore. The Successes
+	// and Errors channels are closed once the pending messages were sent.
+	AsyncClose()
+	// Close stops accepting messages like AsyncClose and waits for the pending messages to be
+	// sent, returning ProducerErrors for the ones which failed and were not read from Errors.
+	Close() error
+	Input() chan<- *ProducerMessage
+	Successes() <-chan *ProducerMessage
+	Errors() <-chan *ProducerError
+}
+
+// producer sends the messages of a SyncProducer and of an AsyncProducer.
+type producer struct {
+	client   messengercli.Client
+	streamId iggcon.Identifier
+	opts     Options
+	closed   atomic.Bool
+
+	mtx sync.Mutex
+	// partitionsCount caches the number of partitions of the topics, the producer does not
+	// follow later partition changes.
+	partitionsCount map[string]uint32
+}
+
+// NewSyncProducer creates a SyncProducer sending to the topics of the given stream.
+func NewSyncProducer(client messengercli.Client, streamId iggcon.Identifier, options ...Option) (SyncProducer, error) {
+	return newProducer(client, streamId, options)
+}
+
+func newProducer(client messengercli.Client, streamId iggcon.Identifier, options []Option) (*producer, error) {
+	if client == nil {
+		return nil, errors.New("kafkabridge: client is required")
+	}
+	return &producer{
+		client:          client,
+		streamId:        streamId,
+		opts:            getOptions(options),
+		partitionsCount: map[string]uint32{},
+	}, nil
+}
+
+func (p *producer) SendMessage(msg *ProducerMessage) (int32, int64, error) {
+	if errs := p.send([]*ProducerMessage{msg}); len(errs) > 0 {
+		return -1, -1, errs[0].Err
+	}
+	return msg.Partition, msg.Offset, nil
+}
+
+func (p *producer) SendMessages(msgs []*ProducerMessage) error {
+	if errs := p.send(msgs); len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (p *producer) Close() error {
+	p.closed.Store(true)
+	return nil
+}
+
+// request is the messages sent to a partition of a topic in a single request.
+type request struct {
+	topic     string
+	partition int32
+	msgs      []*ProducerMessage
+	messages  []iggcon.MessengerMessage
+}
+
+// send sends the messages with a request per partition, setting the partition and the offset
+// of the sent ones, and returns the failures.
+func (p *producer) send(msgs []*ProducerMessage) ProducerErrors {
+	var errs ProducerErrors
+	if p.closed.Load() {
+		for _, msg := range msgs {
+			errs = append(errs, &ProducerError{Msg: msg, Err: ErrClosed})
+		}
+		return errs
+	}
+
+	type partitionKey struct {
+		topic     string
+		partition int32
+	}
+	var requests []*request
+	byPartition := map[partitionKey]*request{}
+	for _, msg := range msgs {
+		message, err := toMessage(msg)
+		if err != nil {
+			errs = append(errs, &ProducerError{Msg: msg, Err: err})
+			continue
+		}
+		partition, err := p.partition(msg, message)
+		if err != nil {
+			errs = append(errs, &ProducerError{Msg: msg, Err: err})
+			continue
+		}
+		key := partitionKey{msg.Topic, partition}
+		req, ok := byPartition[key]
+		if !ok {
+			req = &request{topic: msg.Topic, partition: partition}
+			byPartition[key] = req
+			requests = append(requests, req)
+		}
+		req.msgs = append(req.msgs, msg)
+		req.messages = append(req.messages, message)
+	}
+
+	for _, req := range requests {
+		if err := p.sendRequest(req); err != nil {
+			for _, msg := range req.msgs {
+				errs = append(errs, &ProducerError{Msg: msg, Err: err})
+			}
+		}
+	}
+	return errs
+}
+
+func (p *producer) sendRequest(req *request) error {
+	topicId, err := iggcon.NewIdentifier(req.topic)
+	if err != nil {
+		return err
+	}
+	partitioning := iggcon.PartitionId(uint32(req.partition) + 1)
+	result, err := p.client.SendMessagesWithResult(p.streamId, topicId, partitioning, req.messages, p.opts.Confirmation)
+	if err != nil {
+		return err
+	}
+	for i, msg := range req.msgs {
+		msg.Partition = req.partition
+		msg.Offset = -1
+		if result != nil && i < result.Count() {
+			msg.Offset = int64(result.Offset(i))
+		}
+	}
+	return nil
+}
+
+// partition returns the partition of the message, numbered from 0.
+func (p *producer) partition(msg *ProducerMessage, message iggcon.MessengerMessage) (int32, error) {
+	if p.opts.Partitioner == nil {
+		if msg.Partition < 0 {
+			return 0, errors.New("kafkabridge: the partition of the message is required with manual partitioning")
+		}
+		return msg.Partition, nil
+	}
+	count, err := p.topicPartitionsCount(msg.Topic)
+	if err != nil {
+		return 0, err
+	}
+	return int32(p.opts.Partitioner.Partition(message.Key(), count)) - 1, nil
+}
+
+func (p *producer) topicPartitionsCount(topic string) (uint32, error) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	if count, ok := p.partitionsCount[topic]; ok {
+		return count, nil
+	}
+	topicId, err := iggcon.NewIdentifier(topic)
+	if err != nil {
+		return 0, err
+	}
+	details, err := p.client.GetTopic(p.streamId, topicId)
+	if err != nil {
+		return 0, err
+	}
+	if details.PartitionsCount == 0 {
+		return 0, errors.New("kafkabridge: the topic has no partitions")
+	}
+	p.partitionsCount[topic] = details.PartitionsCount
+	return details.PartitionsCount, nil
+}
+
+type asyncProducer struct {
+	producer  *producer
+	input     chan *ProducerMessage
+	successes chan *ProducerMessage
+	errors    chan *ProducerError
+	closeOnce sync.Once
+}
+
+// NewAsyncProducer creates an AsyncProducer sending to the topics of the given stream.
+func NewAsyncProducer(client messengercli.Client, streamId iggcon.Identifier, options ...Option) (AsyncProducer, error) {
+	p, err := newProducer(client, streamId, options)
+	if err != nil {
+		return nil, err
+	}
+	if p.opts.BatchSize == 0 {
+		return nil, errors.New("kafkabridge: batch size must be greater than zero")
+	}
+	a := &asyncProducer{
+		producer:  p,
+		input:     make(chan *ProducerMessage, p.opts.ChannelBufferSize),
+		successes: make(chan *ProducerMessage, p.opts.ChannelBufferSize),
+		errors:    make(chan *ProducerError, p.opts.ChannelBufferSize),
+	}
+	go a.run()
+	return a, nil
+}
+
+// run sends the messages of the input channel, batching the ones already written to it.
+func (a *asyncProducer) run() {
+	defer close(a.successes)
+	defer close(a.errors)
+	for msg := range a.input {
+		batch := []*ProducerMessage{msg}
+	drain:
+		for len(batch) < int(a.producer.opts.BatchSize) {
+			select {
+			case msg, ok := <-a.input:
+				if !ok {
+					break drain
+				}
+				batch = append(batch, msg)
+			default:
+				break drain
+			}
+		}
+
+		failed := map[*ProducerMessage]bool{}
+		for _, err := range a.producer.send(batch) {
+			failed[err.Msg] = true
+			a.errors <- err
+		}
+		if !a.producer.opts.ReturnSuccesses {
+			continue
+		}
+		for _, msg := range batch {
+			if !failed[msg] {
+				a.successes <- msg
+			}
+		}
+	}
+}
+
+func (a *asyncProducer) AsyncClose() {
+	a.closeOnce.Do(func() {
+		close(a.input)
+	})
+}
+
+func (a *asyncProducer) Close() error {
+	a.AsyncClose()
+	go func() {
+		for range a.successes {
+		}
+	}()
+	var errs ProducerErrors
+	for err := range a.errors {
+		errs = append(errs, err)
+	}
+	a.producer.Close()
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (a *asyncProducer) Input() chan<- *ProducerMessage {
+	return a.input
+}
+
+func (a *asyncProducer) Successes() <-chan *ProducerMessage {
+	return a.successes
+}
+
+func (a *asyncProducer) Errors() <-chan *ProducerError {
+	return a.errors
+}
@@ -0,0 +1,253 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package messengercli
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+)
+
+// AuditActor is the user a command was sent as.
+type AuditActor struct {
+	UserId uint32
+	// Username is the name the user logged in with, empty when logged in with a personal access token.
+	Username string
+}
+
+// AuditEvent records an administrative command changing the server.
+type AuditEvent struct {
+	Time time.Time
+	// Actor is the user logged in through the client, nil when the client was not logged in
+	// through the audited client.
+	Actor   *AuditActor
+	Command iggcon.CommandCode
+	// Resource is the path of the resource changed: the stream, the stream and topic, or the
+	// stream, topic and consumer group separated by slashes, the user, or the name of the
+	// personal access token. The resources are named by the identifiers the command was sent
+	// with, numeric IDs as decimal numbers.
+	Resource string
+	// Err is the error of the command, nil when it succeeded.
+	Err error
+}
+
+// AuditHook receives the audit events. It is called synchronously after every audited command,
+// so it must not block, e.g. by queueing the events forwarded to a SIEM.
+type AuditHook func(event AuditEvent)
+
+// AuditClient returns a Client calling hook after every command creating, updating or deleting a
+// stream, topic, partition, consumer group, user or personal access token.
+func AuditClient(client Client, hook AuditHook) Client {
+	return &auditedClient{Client: client, hook: hook}
+}
+
+type auditedClient struct {
+	Client
+	hook AuditHook
+
+	mtx   sync.Mutex
+	actor *AuditActor
+}
+
+func (c *auditedClient) audit(command iggcon.CommandCode, resource string, err error) {
+	c.mtx.Lock()
+	actor := c.actor
+	c.mtx.Unlock()
+	c.hook(AuditEvent{Time: time.Now(), Actor: actor, Command: command, Resource: resource, Err: err})
+}
+
+func (c *auditedClient) setActor(actor *AuditActor) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.actor = actor
+}
+
+// resourcePath joins the identifiers of a resource with slashes.
+func resourcePath(ids ...iggcon.Identifier) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		if value, err := id.Uint32(); err == nil {
+			parts[i] = strconv.FormatUint(uint64(value), 10)
+		} else {
+			parts[i], _ = id.String()
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+func (c *auditedClient) LoginUser(username string, password string) (*iggcon.IdentityInfo, error) {
+	identity, err := c.Client.LoginUser(username, password)
+	if err == nil && identity != nil {
+		c.setActor(&AuditActor{UserId: identity.UserId, Username: username})
+	}
+	return identity, err
+}
+
+func (c *auditedClient) LoginWithPersonalAccessToken(token string) (*iggcon.IdentityInfo, error) {
+	identity, err := c.Client.LoginWithPersonalAccessToken(token)
+	if err == nil && identity != nil {
+		c.setActor(&AuditActor{UserId: identity.UserId})
+	}
+	return identity, err
+}
+
+func (c *auditedClient) LogoutUser() error {
+	err := c.Client.LogoutUser()
+	if err == nil {
+		c.setActor(nil)
+	}
+	return err
+}
+
+func (c *auditedClient) CreateStream(name string, streamId *uint32) (*iggcon.StreamDetails, error) {
+	stream, err := c.Client.CreateStream(name, streamId)
+	c.audit(iggcon.CreateStreamCode, name, err)
+	return stream, err
+}
+
+func (c *auditedClient) UpdateStream(streamId iggcon.Identifier, name string) error {
+	err := c.Client.UpdateStream(streamId, name)
+	c.audit(iggcon.UpdateStreamCode, resourcePath(streamId), err)
+	return err
+}
+
+func (c *auditedClient) DeleteStream(id iggcon.Identifier) error {
+	err := c.Client.DeleteStream(id)
+	c.audit(iggcon.DeleteStreamCode, resourcePath(id), err)
+	return err
+}
+
+func (c *auditedClient) CreateTopic(
+	streamId iggcon.Identifier,
+	name string,
+	partitionsCount uint32,
+	compressionAlgorithm iggcon.CompressionAlgorithm,
+	messageExpiry iggcon.Expiry,
+	maxTopicSize iggcon.MaxTopicSize,
+	replicationFactor *uint8,
+	topicId *uint32,
+) (*iggcon.TopicDetails, error) {
+	topic, err := c.Client.CreateTopic(streamId, name, partitionsCount, compressionAlgorithm, messageExpiry, maxTopicSize, replicationFactor, topicId)
+	c.audit(iggcon.CreateTopicCode, resourcePath(streamId)+"/"+name, err)
+	return topic, err
+}
+
+func (c *auditedClient) UpdateTopic(
+	streamId iggcon.Identifier,
+	topicId iggcon.Identifier,
+	name string,
+	compressionAlgorithm iggcon.CompressionAlgorithm,
+	messageExpiry iggcon.Expiry,
+	maxTopicSize iggcon.MaxTopicSize,
+	replicationFactor *uint8,
+) error {
+	err := c.Client.UpdateTopic(streamId, topicId, name, compressionAlgorithm, messageExpiry, maxTopicSize, replicationFactor)
+	c.audit(iggcon.UpdateTopicCode, resourcePath(streamId, topicId), err)
+	return err
+}
+
+func (c *auditedClient) DeleteTopic(streamId, topicId iggcon.Identifier) error {
+	err := c.Client.DeleteTopic(streamId, topicId)
+	c.audit(iggcon.DeleteTopicCode, resourcePath(streamId, topicId), err)
+	return err
+}
+
+func (c *auditedClient) CreatePartitions(streamId, topicId iggcon.Identifier, partitionsCount uint32) error {
+	err := c.Client.CreatePartitions(streamId, topicId, partitionsCount)
+	c.audit(iggcon.CreatePartitionsCode, resourcePath(streamId, topicId), err)
+	return err
+}
+
+func (c *auditedClient) DeletePartitions(streamId, topicId iggcon.Identifier, partitionsCount uint32) error {
+	err := c.Client.DeletePartitions(streamId, topicId, partitionsCount)
+	c.audit(iggcon.DeletePartitionsCode, resourcePath(streamId, topicId), err)
+	return err
+}
+
+func (c *auditedClient) CreateConsumerGroup(streamId, topicId iggcon.Identifier, name string, groupId *uint32) (*iggcon.ConsumerGroupDetails, error) {
+	group, err := c.Client.CreateConsumerGroup(streamId, topicId, name, groupId)
+	c.audit(iggcon.CreateGroupCode, resourcePath(streamId, topicId)+"/"+name, err)
+	return group, err
+}
+
+func (c *auditedClient) DeleteConsumerGroup(streamId, topicId, groupId iggcon.Identifier) error {
+	err := c.Client.DeleteConsumerGroup(streamId, topicId, groupId)
+	c.audit(iggcon.DeleteGroupCode, resourcePath(streamId, topicId, groupId), err)
+	return err
+}
+
+func (c *auditedClient) CreateUser(username string, password string, status iggcon.UserStatus, permissions *iggcon.Permissions) (*iggcon.UserInfoDetails, error) {
+	user, err := c.Client.CreateUser(username, password, status, permissions)
+	c.audit(iggcon.CreateUserCode, username, err)
+	return user, err
+}
+
+func (c *auditedClient) UpdateUser(userID iggcon.Identifier, username *string, status *iggcon.UserStatus) error {
+	err := c.Client.UpdateUser(userID, username, status)
+	c.audit(iggcon.UpdateUserCode, resourcePath(userID), err)
+	return err
+}
+
+func (c *auditedClient) UpdatePermissions(userID iggcon.Identifier, permissions *iggcon.Permissions) error {
+	err := c.Client.UpdatePermissions(userID, permissions)
+	c.audit(iggcon.UpdatePermissionsCode, resourcePath(userID), err)
+	return err
+}
+
+func (c *auditedClient) ChangePassword(userID iggcon.Identifier, currentPassword string, newPassword string) error {
+	err := c.Client.ChangePassword(userID, currentPassword, newPassword)
+	c.audit(iggcon.ChangePasswordCode, resourcePath(userID), err)
+	return err
+}
+
+func (c *auditedClient) DeleteUser(identifier iggcon.Identifier) error {
+	err := c.Client.DeleteUser(identifier)
+	c.audit(iggcon.DeleteUserCode, resourcePath(identifier), err)
+	return err
+}
+
+func (c *auditedClient) CreatePersonalAccessToken(name string, expiry uint32) (*iggcon.RawPersonalAccessToken, error) {
+	token, err := c.Client.CreatePersonalAccessToken(name, expiry)
+	c.audit(iggcon.CreateAccessTokenCode, name, err)
+	return token, err
+}
+
+func (c *auditedClient) DeletePersonalAccessToken(name string) error {
+	err := c.Client.DeletePersonalAccessToken(name)
+	c.audit(iggcon.DeleteAccessTokenCode, name, err)
+	return err
+}
+
+// PollMessagesWithContext forwards the context polls, which the embedded Client does not expose.
+func (c *auditedClient) PollMessagesWithContext(
+	ctx context.Context,
+	streamId iggcon.Identifier,
+	topicId iggcon.Identifier,
+	consumer iggcon.Consumer,
+	strategy iggcon.PollingStrategy,
+	count uint32,
+	autoCommit bool,
+	partitionId *uint32,
+	maxWait time.Duration,
+) (*iggcon.PolledMessage, error) {
+	return PollMessagesWithContext(ctx, c.Client, streamId, topicId, consumer, strategy, count, autoCommit, partitionId, maxWait)
+}
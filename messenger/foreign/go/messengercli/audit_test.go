@@ -0,0 +1,61 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package messengercli_test
+
+import (
+	"fmt"
+	"testing"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengercli"
+	"github.com/apache/messenger/foreign/go/messengertest"
+)
+
+func TestAuditClient(t *testing.T) {
+	var events []messengercli.AuditEvent
+	client := messengercli.AuditClient(messengertest.NewClient(), func(event messengercli.AuditEvent) {
+		events = append(events, event)
+	})
+	if _, err := client.LoginUser("messenger", "messenger"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.CreateStream("orders", nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.CreateTopic(iggcon.MustIdentifier("orders"), "created", 1, iggcon.CompressionAlgorithmNone, 0, 0, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetStreams(); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.DeleteStream(iggcon.MustIdentifier(uint32(42))); err == nil {
+		t.Fatal("expected deleting a missing stream to fail")
+	}
+
+	var audited []string
+	for _, event := range events {
+		if event.Actor == nil || event.Actor.Username != "messenger" {
+			t.Fatalf("expected the events to be attributed to the logged in user, got %+v", event.Actor)
+		}
+		audited = append(audited, fmt.Sprintf("%s %s %t", event.Command, event.Resource, event.Err == nil))
+	}
+	expected := "[CreateStream orders true CreateTopic orders/created true DeleteStream 42 false]"
+	if fmt.Sprint(audited) != expected {
+		t.Fatalf("expected the events %s, got %v", expected, audited)
+	}
+}
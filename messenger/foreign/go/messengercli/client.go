@@ -18,10 +18,39 @@
 package messengercli
 
 import (
+	"context"
+	"time"
+
 	iggcon "github.com/apache/messenger/foreign/go/contracts"
 )
 
+// Client is the complete API of the server: the administration of the AdminClient and the
+// messaging of the DataClient, over the same connection.
 type Client interface {
+	AdminClient
+	DataClient
+}
+
+// Session holds the methods shared by every client to authenticate and check the connection.
+type Session interface {
+	// LoginUser login a user by username and password.
+	LoginUser(username string, password string) (*iggcon.IdentityInfo, error)
+
+	// LoginWithPersonalAccessToken login the user with the provided personal access token.
+	LoginWithPersonalAccessToken(token string) (*iggcon.IdentityInfo, error)
+
+	// LogoutUser logout the currently authenticated user.
+	LogoutUser() error
+
+	// Ping the server to check if it's alive.
+	Ping() error
+}
+
+// AdminClient manages the streams, topics, partitions, consumer groups, users and personal access
+// tokens, and reads the server stats and clients.
+type AdminClient interface {
+	Session
+
 	// GetStream get the info about a specific stream by unique ID or name.
 	// Authentication is required, and the permission to read the streams.
 	GetStream(streamId iggcon.Identifier) (*iggcon.StreamDetails, error)
@@ -52,26 +81,32 @@ type Client interface {
 
 	// CreateTopic create a new topic.
 	// Authentication is required, and the permission to manage the topics.
+	//
+	// Deprecated: Use the CreateTopic function with an iggcon.CreateTopicRequest, which fills in
+	// the defaults and validates the request.
 	CreateTopic(
 		streamId iggcon.Identifier,
 		name string,
 		partitionsCount uint32,
 		compressionAlgorithm iggcon.CompressionAlgorithm,
-		messageExpiry iggcon.Duration,
-		maxTopicSize uint64,
+		messageExpiry iggcon.Expiry,
+		maxTopicSize iggcon.MaxTopicSize,
 		replicationFactor *uint8,
 		topicId *uint32,
 	) (*iggcon.TopicDetails, error)
 
 	// UpdateTopic update a topic by unique ID or name.
 	// Authentication is required, and the permission to manage the topics.
+	//
+	// Deprecated: Use the UpdateTopic function with an iggcon.UpdateTopicRequest, which fills in
+	// the defaults and validates the request.
 	UpdateTopic(
 		streamId iggcon.Identifier,
 		topicId iggcon.Identifier,
 		name string,
 		compressionAlgorithm iggcon.CompressionAlgorithm,
-		messageExpiry iggcon.Duration,
-		maxTopicSize uint64,
+		messageExpiry iggcon.Expiry,
+		maxTopicSize iggcon.MaxTopicSize,
 		replicationFactor *uint8,
 	) error
 
@@ -79,46 +114,6 @@ type Client interface {
 	// Authentication is required, and the permission to manage the topics.
 	DeleteTopic(streamId, topicId iggcon.Identifier) error
 
-	// SendMessages sends messages using specified partitioning strategy to the given stream and topic by unique IDs or names.
-	// Authentication is required, and the permission to send the messages.
-	SendMessages(
-		streamId iggcon.Identifier,
-		topicId iggcon.Identifier,
-		partitioning iggcon.Partitioning,
-		messages []iggcon.MessengerMessage,
-	) error
-
-	// PollMessages poll given amount of messages using the specified consumer and strategy from the specified stream and topic by unique IDs or names.
-	// Authentication is required, and the permission to poll the messages.
-	PollMessages(
-		streamId iggcon.Identifier,
-		topicId iggcon.Identifier,
-		consumer iggcon.Consumer,
-		strategy iggcon.PollingStrategy,
-		count uint32,
-		autoCommit bool,
-		partitionId *uint32,
-	) (*iggcon.PolledMessage, error)
-
-	// StoreConsumerOffset store the consumer offset for a specific consumer or consumer group for the given stream and topic by unique IDs or names.
-	// Authentication is required, and the permission to poll the messages.
-	StoreConsumerOffset(
-		consumer iggcon.Consumer,
-		streamId iggcon.Identifier,
-		topicId iggcon.Identifier,
-		offset uint64,
-		partitionId *uint32,
-	) error
-
-	// GetConsumerOffset get the consumer offset for a specific consumer or consumer group for the given stream and topic by unique IDs or names.
-	// Authentication is required, and the permission to poll the messages.
-	GetConsumerOffset(
-		consumer iggcon.Consumer,
-		streamId iggcon.Identifier,
-		topicId iggcon.Identifier,
-		partitionId *uint32,
-	) (*iggcon.ConsumerOffsetInfo, error)
-
 	// GetConsumerGroups get the info about all the consumer groups for the given stream and topic by unique IDs or names.
 	// Authentication is required, and the permission to read the streams or topics.
 	GetConsumerGroups(streamId iggcon.Identifier, topicId iggcon.Identifier) ([]iggcon.ConsumerGroup, error)
@@ -148,22 +143,6 @@ type Client interface {
 		groupId iggcon.Identifier,
 	) error
 
-	// JoinConsumerGroup join a consumer group by unique ID or name for the given stream and topic by unique IDs or names.
-	// Authentication is required, and the permission to read the streams or topics.
-	JoinConsumerGroup(
-		streamId iggcon.Identifier,
-		topicId iggcon.Identifier,
-		groupId iggcon.Identifier,
-	) error
-
-	// LeaveConsumerGroup leave a consumer group by unique ID or name for the given stream and topic by unique IDs or names.
-	// Authentication is required, and the permission to read the streams or topics.
-	LeaveConsumerGroup(
-		streamId iggcon.Identifier,
-		topicId iggcon.Identifier,
-		groupId iggcon.Identifier,
-	) error
-
 	// CreatePartitions create new N partitions for a topic by unique ID or name.
 	// For example, given a topic with 3 partitions, if you create 2 partitions, the topic will have 5 partitions (from 1 to 5).
 	// Authentication is required, and the permission to manage the partitions.
@@ -232,22 +211,10 @@ type Client interface {
 	// GetPersonalAccessTokens get the info about all the personal access tokens of the currently authenticated user.
 	GetPersonalAccessTokens() ([]iggcon.PersonalAccessTokenInfo, error)
 
-	// LoginWithPersonalAccessToken login the user with the provided personal access token.
-	LoginWithPersonalAccessToken(token string) (*iggcon.IdentityInfo, error)
-
-	// LoginUser login a user by username and password.
-	LoginUser(username string, password string) (*iggcon.IdentityInfo, error)
-
-	// LogoutUser logout the currently authenticated user.
-	LogoutUser() error
-
 	// GetStats get the stats of the system such as PID, memory usage, streams count etc.
 	// Authentication is required, and the permission to read the server info.
 	GetStats() (*iggcon.Stats, error)
 
-	// Ping the server to check if it's alive.
-	Ping() error
-
 	// GetClients get the info about all the currently connected clients (not to be confused with the users).
 	// Authentication is required, and the permission to read the server info.
 	GetClients() ([]iggcon.ClientInfo, error)
@@ -255,4 +222,148 @@ type Client interface {
 	// GetClient get the info about a specific client by unique ID (not to be confused with the user).
 	// Authentication is required, and the permission to read the server info.
 	GetClient(clientId uint32) (*iggcon.ClientInfoDetails, error)
+
+	// GetClusterMetadata get the nodes of the cluster the server belongs to and the leader of every partition.
+	// Authentication is required, and the permission to read the server info.
+	GetClusterMetadata() (*iggcon.ClusterMetadata, error)
+}
+
+// DataClient sends and polls messages and manages the consumer offsets, without access to the
+// administration of the server.
+type DataClient interface {
+	Session
+
+	// SendMessages sends messages using specified partitioning strategy to the given stream and topic by unique IDs or names.
+	// Authentication is required, and the permission to send the messages.
+	SendMessages(
+		streamId iggcon.Identifier,
+		topicId iggcon.Identifier,
+		partitioning iggcon.Partitioning,
+		messages []iggcon.MessengerMessage,
+	) error
+
+	// SendMessagesWithConfirmation sends messages like SendMessages, waiting for the server to reach the given confirmation level before acknowledging them.
+	// Authentication is required, and the permission to send the messages.
+	SendMessagesWithConfirmation(
+		streamId iggcon.Identifier,
+		topicId iggcon.Identifier,
+		partitioning iggcon.Partitioning,
+		messages []iggcon.MessengerMessage,
+		confirmation iggcon.Confirmation,
+	) error
+
+	// SendMessagesWithResult sends messages like SendMessagesWithConfirmation, returning where they were appended,
+	// or nil when the server does not report it.
+	// Authentication is required, and the permission to send the messages.
+	SendMessagesWithResult(
+		streamId iggcon.Identifier,
+		topicId iggcon.Identifier,
+		partitioning iggcon.Partitioning,
+		messages []iggcon.MessengerMessage,
+		confirmation iggcon.Confirmation,
+	) (*iggcon.SendResult, error)
+
+	// PollMessages poll given amount of messages using the specified consumer and strategy from the specified stream and topic by unique IDs or names.
+	// Authentication is required, and the permission to poll the messages.
+	PollMessages(
+		streamId iggcon.Identifier,
+		topicId iggcon.Identifier,
+		consumer iggcon.Consumer,
+		strategy iggcon.PollingStrategy,
+		count uint32,
+		autoCommit bool,
+		partitionId *uint32,
+	) (*iggcon.PolledMessage, error)
+
+	// PollMessagesWithWait polls messages like PollMessages, the server holding the poll for up to maxWait until
+	// messages are available. Servers without long polling answer right away.
+	// Authentication is required, and the permission to poll the messages.
+	PollMessagesWithWait(
+		streamId iggcon.Identifier,
+		topicId iggcon.Identifier,
+		consumer iggcon.Consumer,
+		strategy iggcon.PollingStrategy,
+		count uint32,
+		autoCommit bool,
+		partitionId *uint32,
+		maxWait time.Duration,
+	) (*iggcon.PolledMessage, error)
+
+	// StoreConsumerOffset store the consumer offset for a specific consumer or consumer group for the given stream and topic by unique IDs or names.
+	// Authentication is required, and the permission to poll the messages.
+	StoreConsumerOffset(
+		consumer iggcon.Consumer,
+		streamId iggcon.Identifier,
+		topicId iggcon.Identifier,
+		offset uint64,
+		partitionId *uint32,
+	) error
+
+	// GetConsumerOffset get the consumer offset for a specific consumer or consumer group for the given stream and topic by unique IDs or names.
+	// Authentication is required, and the permission to poll the messages.
+	GetConsumerOffset(
+		consumer iggcon.Consumer,
+		streamId iggcon.Identifier,
+		topicId iggcon.Identifier,
+		partitionId *uint32,
+	) (*iggcon.ConsumerOffsetInfo, error)
+
+	// DeleteConsumerOffset delete the stored consumer offset for a specific consumer or consumer group for the given stream and topic by unique IDs or names,
+	// so the consumer starts again from the beginning of the partition.
+	// Authentication is required, and the permission to poll the messages.
+	DeleteConsumerOffset(
+		consumer iggcon.Consumer,
+		streamId iggcon.Identifier,
+		topicId iggcon.Identifier,
+		partitionId *uint32,
+	) error
+
+	// JoinConsumerGroup join a consumer group by unique ID or name for the given stream and topic by unique IDs or names.
+	// Authentication is required, and the permission to read the streams or topics.
+	JoinConsumerGroup(
+		streamId iggcon.Identifier,
+		topicId iggcon.Identifier,
+		groupId iggcon.Identifier,
+	) error
+
+	// LeaveConsumerGroup leave a consumer group by unique ID or name for the given stream and topic by unique IDs or names.
+	// Authentication is required, and the permission to read the streams or topics.
+	LeaveConsumerGroup(
+		streamId iggcon.Identifier,
+		topicId iggcon.Identifier,
+		groupId iggcon.Identifier,
+	) error
+}
+
+type adminClient struct {
+	AdminClient
+}
+
+type dataClient struct {
+	DataClient
+}
+
+// PollMessagesWithContext forwards the context polls, which DataClient does not expose.
+func (c dataClient) PollMessagesWithContext(
+	ctx context.Context,
+	streamId iggcon.Identifier,
+	topicId iggcon.Identifier,
+	consumer iggcon.Consumer,
+	strategy iggcon.PollingStrategy,
+	count uint32,
+	autoCommit bool,
+	partitionId *uint32,
+	maxWait time.Duration,
+) (*iggcon.PolledMessage, error) {
+	return PollMessagesWithContext(ctx, c.DataClient, streamId, topicId, consumer, strategy, count, autoCommit, partitionId, maxWait)
+}
+
+// AsAdmin narrows client to its AdminClient methods, the returned value cannot be converted back to a Client.
+func AsAdmin(client Client) AdminClient {
+	return adminClient{client}
+}
+
+// AsData narrows client to its DataClient methods, the returned value cannot be converted back to a Client.
+func AsData(client Client) DataClient {
+	return dataClient{client}
 }
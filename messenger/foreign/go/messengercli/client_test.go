@@ -0,0 +1,70 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package messengercli_test
+
+import (
+	"testing"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengercli"
+	"github.com/apache/messenger/foreign/go/messengertest"
+)
+
+func TestAsAdmin(t *testing.T) {
+	client := messengertest.NewClient()
+	var admin any = messengercli.AsAdmin(client)
+
+	if _, ok := admin.(messengercli.Client); ok {
+		t.Error("expected the admin client not to be asserted back to a Client")
+	}
+	if _, ok := admin.(messengercli.DataClient); ok {
+		t.Error("expected the admin client not to be asserted to a DataClient")
+	}
+	if _, ok := admin.(*messengertest.Client); ok {
+		t.Error("expected the admin client not to be asserted back to its implementation")
+	}
+	if _, err := admin.(messengercli.AdminClient).CreateStream("orders", nil); err != nil {
+		t.Fatalf("expected the admin methods to be called through, got %v", err)
+	}
+}
+
+func TestAsData(t *testing.T) {
+	client := messengertest.NewClient()
+	var data any = messengercli.AsData(client)
+
+	if _, ok := data.(messengercli.Client); ok {
+		t.Error("expected the data client not to be asserted back to a Client")
+	}
+	if _, ok := data.(messengercli.AdminClient); ok {
+		t.Error("expected the data client not to be asserted to an AdminClient")
+	}
+	if _, ok := data.(*messengertest.Client); ok {
+		t.Error("expected the data client not to be asserted back to its implementation")
+	}
+	if _, err := client.CreateStream("orders", nil); err != nil {
+		t.Fatal(err)
+	}
+	streamId, topicId := iggcon.MustIdentifier("orders"), iggcon.MustIdentifier("created")
+	if _, err := client.CreateTopic(streamId, "created", 1, iggcon.CompressionAlgorithmNone, 0, 0, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	message, _ := iggcon.NewMessengerMessage([]byte("order"))
+	if err := data.(messengercli.DataClient).SendMessages(streamId, topicId, iggcon.None(), []iggcon.MessengerMessage{message}); err != nil {
+		t.Fatalf("expected the data methods to be called through, got %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package messengercli
+
+import (
+	"context"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+)
+
+// compactBatchSize is the number of messages polled at once when scanning a topic by key.
+const compactBatchSize = 1000
+
+// KeyedMessageHandler is called by ScanKeyed with the key of a message, as set by
+// iggcon.WithKey, and the message.
+type KeyedMessageHandler func(key string, message iggcon.ReceivedMessage) error
+
+// ScanKeyed calls handle for every message with a key of a topic, up to the messages appended
+// when the scan started. The partitions are scanned in turn, every message of a partition being
+// handled in offset order, so that applying the messages as upserts of a table keyed by the
+// message key leaves the latest message of every key, as long as a key is always sent to the
+// same partition. The messages are polled without storing any offset, and those without a key
+// are skipped. The error of handle stops the scan and is returned.
+func ScanKeyed(ctx context.Context, client Client, streamId, topicId iggcon.Identifier, handle KeyedMessageHandler) error {
+	topic, err := client.GetTopic(streamId, topicId)
+	if err != nil {
+		return err
+	}
+	consumer := iggcon.DefaultConsumer()
+	for _, partition := range topic.Partitions {
+		if partition.MessagesCount == 0 {
+			continue
+		}
+		partitionId := partition.Id
+		next := uint64(0)
+		for next <= partition.CurrentOffset {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			polled, err := client.PollMessages(streamId, topicId, consumer, iggcon.OffsetPollingStrategy(next), compactBatchSize, false, &partitionId)
+			if err != nil {
+				return err
+			}
+			if polled == nil || len(polled.Messages) == 0 {
+				break
+			}
+			for _, message := range polled.Messages {
+				if message.Header.Offset > partition.CurrentOffset {
+					break
+				}
+				key := message.Key()
+				if key == nil {
+					continue
+				}
+				received := iggcon.ReceivedMessage{Message: message, CurrentOffset: polled.CurrentOffset, PartitionId: polled.PartitionId}
+				if err := handle(string(key), received); err != nil {
+					return err
+				}
+			}
+			next = polled.Messages[len(polled.Messages)-1].Header.Offset + 1
+		}
+	}
+	return nil
+}
+
+// CompactedView returns the latest message of every key of a topic, scanned by ScanKeyed.
+func CompactedView(ctx context.Context, client Client, streamId, topicId iggcon.Identifier) (map[string]iggcon.MessengerMessage, error) {
+	view := make(map[string]iggcon.MessengerMessage)
+	err := ScanKeyed(ctx, client, streamId, topicId, func(key string, message iggcon.ReceivedMessage) error {
+		view[key] = message.Message
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return view, nil
+}
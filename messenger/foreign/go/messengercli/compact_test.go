@@ -0,0 +1,72 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package messengercli_test
+
+import (
+	"context"
+	"testing"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengercli"
+	"github.com/apache/messenger/foreign/go/messengertest"
+)
+
+func TestCompactedView(t *testing.T) {
+	client := messengertest.NewClient()
+	if _, err := client.CreateStream("accounts", nil); err != nil {
+		t.Fatal(err)
+	}
+	streamId := iggcon.MustIdentifier("accounts")
+	if _, err := client.CreateTopic(streamId, "balances", 2, iggcon.CompressionAlgorithmNone, 0, 0, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	topicId := iggcon.MustIdentifier("balances")
+	send := func(partitionId uint32, key, payload string) {
+		var opts []iggcon.MessengerMessageOpt
+		if key != "" {
+			opts = append(opts, iggcon.WithKey([]byte(key)))
+		}
+		message, err := iggcon.NewMessengerMessage([]byte(payload), opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = client.SendMessages(streamId, topicId, iggcon.PartitionId(partitionId), []iggcon.MessengerMessage{message}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	send(1, "alice", "10")
+	send(2, "bob", "5")
+	send(1, "alice", "25")
+	send(1, "", "unkeyed")
+	send(2, "bob", "7")
+	send(1, "carol", "1")
+
+	view, err := messengercli.CompactedView(context.Background(), client, streamId, topicId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{"alice": "25", "bob": "7", "carol": "1"}
+	if len(view) != len(expected) {
+		t.Fatalf("expected %d keys, got %d", len(expected), len(view))
+	}
+	for key, payload := range expected {
+		if string(view[key].Payload) != payload {
+			t.Fatalf("expected %s to be %s, got %s", key, payload, view[key].Payload)
+		}
+	}
+}
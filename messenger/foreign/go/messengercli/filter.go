@@ -0,0 +1,105 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package messengercli
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+)
+
+// MessagePredicate reports whether a polled message should be kept, it can inspect
+// both the user headers and the payload of the message.
+type MessagePredicate func(message iggcon.MessengerMessage) bool
+
+// filteredPollInterval is how long PollMessagesFiltered waits before polling again after an empty poll.
+const filteredPollInterval = 100 * time.Millisecond
+
+// PollMessagesFiltered polls messages like Client.PollMessages but only keeps those matching the
+// predicate, polling again until count matching messages are gathered. With a partitionId, it
+// polls again from the offset following the last polled message. Without one, the server picks
+// the partition of every poll, so the offsets of a partition do not apply to the next poll: the
+// strategy is kept as is, which moves forward with iggcon.NextPollingStrategy and autoCommit.
+// When the deadline of ctx expires first, the messages gathered so far are returned without
+// error, while a cancelled ctx returns its error. Without a deadline it polls until count
+// matching messages are found.
+func PollMessagesFiltered(
+	ctx context.Context,
+	client Client,
+	streamId iggcon.Identifier,
+	topicId iggcon.Identifier,
+	consumer iggcon.Consumer,
+	strategy iggcon.PollingStrategy,
+	count uint32,
+	autoCommit bool,
+	partitionId *uint32,
+	predicate MessagePredicate,
+) (*iggcon.PolledMessage, error) {
+	result := &iggcon.PolledMessage{}
+	var timer *time.Timer
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+	for uint32(len(result.Messages)) < count {
+		if err := ctx.Err(); err != nil {
+			return filteredResult(result, err)
+		}
+
+		polled, err := client.PollMessages(streamId, topicId, consumer, strategy, count-uint32(len(result.Messages)), autoCommit, partitionId)
+		if err != nil {
+			return nil, err
+		}
+		if polled == nil || len(polled.Messages) == 0 {
+			if timer == nil {
+				timer = time.NewTimer(filteredPollInterval)
+			} else {
+				timer.Reset(filteredPollInterval)
+			}
+			select {
+			case <-ctx.Done():
+				return filteredResult(result, ctx.Err())
+			case <-timer.C:
+			}
+			continue
+		}
+
+		result.PartitionId = polled.PartitionId
+		result.CurrentOffset = polled.CurrentOffset
+		for _, message := range polled.Messages {
+			if predicate(message) {
+				result.Messages = append(result.Messages, message)
+			}
+		}
+		if partitionId != nil {
+			strategy = iggcon.OffsetPollingStrategy(polled.Messages[len(polled.Messages)-1].Header.Offset + 1)
+		}
+	}
+	return filteredResult(result, nil)
+}
+
+func filteredResult(result *iggcon.PolledMessage, err error) (*iggcon.PolledMessage, error) {
+	if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+		return nil, err
+	}
+	result.MessageCount = uint32(len(result.Messages))
+	return result, nil
+}
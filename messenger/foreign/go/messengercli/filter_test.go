@@ -0,0 +1,128 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package messengercli_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengercli"
+	"github.com/apache/messenger/foreign/go/messengertest"
+)
+
+// newFilterTopic creates a topic of two partitions holding the messages "p<partition>-<i>".
+func newFilterTopic(t *testing.T, messages int) (*messengertest.Client, iggcon.Identifier, iggcon.Identifier) {
+	client := messengertest.NewClient()
+	if _, err := client.CreateStream("events", nil); err != nil {
+		t.Fatal(err)
+	}
+	streamId, topicId := iggcon.MustIdentifier("events"), iggcon.MustIdentifier("audit")
+	if _, err := client.CreateTopic(streamId, "audit", 2, iggcon.CompressionAlgorithmNone, 0, 0, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	for partition := uint32(1); partition <= 2; partition++ {
+		for i := range messages {
+			message, _ := iggcon.NewMessengerMessage([]byte(fmt.Sprintf("p%d-%d", partition, i)))
+			if err := client.SendMessages(streamId, topicId, iggcon.PartitionId(partition), []iggcon.MessengerMessage{message}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	return client, streamId, topicId
+}
+
+func payloads(polled *iggcon.PolledMessage) []string {
+	var payloads []string
+	for _, message := range polled.Messages {
+		payloads = append(payloads, string(message.Payload))
+	}
+	return payloads
+}
+
+func TestPollMessagesFiltered(t *testing.T) {
+	client, streamId, topicId := newFilterTopic(t, 10)
+	partitionId := uint32(1)
+	even := func(message iggcon.MessengerMessage) bool {
+		return message.Header.Offset%2 == 0
+	}
+
+	// the polls of 4 messages keep 2 of them, so it polls from the offset following the last one
+	polled, err := messengercli.PollMessagesFiltered(context.Background(), client, streamId, topicId,
+		iggcon.DefaultConsumer(), iggcon.FirstPollingStrategy(), 4, false, &partitionId, even)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(payloads(polled), ","); got != "p1-0,p1-2,p1-4,p1-6" || polled.MessageCount != 4 {
+		t.Fatalf("expected the first 4 even messages of partition 1, got %s", got)
+	}
+}
+
+func TestPollMessagesFilteredDeadline(t *testing.T) {
+	client, streamId, topicId := newFilterTopic(t, 10)
+	partitionId := uint32(1)
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	below3 := func(message iggcon.MessengerMessage) bool {
+		return message.Header.Offset < 3
+	}
+
+	polled, err := messengercli.PollMessagesFiltered(ctx, client, streamId, topicId,
+		iggcon.DefaultConsumer(), iggcon.FirstPollingStrategy(), 5, false, &partitionId, below3)
+	if err != nil {
+		t.Fatalf("expected the partial result without error, got %v", err)
+	}
+	if got := strings.Join(payloads(polled), ","); got != "p1-0,p1-1,p1-2" || polled.MessageCount != 3 {
+		t.Fatalf("expected the 3 matching messages, got %s", got)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	if _, err := messengercli.PollMessagesFiltered(ctx, client, streamId, topicId,
+		iggcon.DefaultConsumer(), iggcon.FirstPollingStrategy(), 5, false, &partitionId, below3); err != context.Canceled {
+		t.Fatalf("expected a cancelled context to fail, got %v", err)
+	}
+}
+
+func TestPollMessagesFilteredWithoutPartition(t *testing.T) {
+	client, streamId, topicId := newFilterTopic(t, 5)
+	groupId := uint32(1)
+	if _, err := client.CreateConsumerGroup(streamId, topicId, "auditors", &groupId); err != nil {
+		t.Fatal(err)
+	}
+	group := iggcon.MustIdentifier(groupId)
+	if err := client.JoinConsumerGroup(streamId, topicId, group); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	all := func(iggcon.MessengerMessage) bool { return true }
+
+	// the server alternates the partitions, the offsets of partition 1 must not be used to poll partition 2
+	polled, err := messengercli.PollMessagesFiltered(ctx, client, streamId, topicId,
+		iggcon.NewGroupConsumer(group), iggcon.NextPollingStrategy(), 10, true, nil, all)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(payloads(polled), ","); got != "p1-0,p1-1,p1-2,p1-3,p1-4,p2-0,p2-1,p2-2,p2-3,p2-4" {
+		t.Fatalf("expected every message of both partitions once, got %s", got)
+	}
+}
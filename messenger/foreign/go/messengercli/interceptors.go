@@ -0,0 +1,238 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package messengercli
+
+import (
+	"context"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+)
+
+// ProducerInterceptor intercepts the messages sent through the client, mirroring Kafka producer interceptors.
+type ProducerInterceptor interface {
+	// OnSend is called before the messages are serialized. It may mutate or filter the messages,
+	// and returning an error aborts the send. When no message is left, nothing is sent.
+	OnSend(streamId, topicId iggcon.Identifier, messages []iggcon.MessengerMessage) ([]iggcon.MessengerMessage, error)
+
+	// OnAcknowledgement is called once the server answered, with a nil err on success.
+	OnAcknowledgement(streamId, topicId iggcon.Identifier, messages []iggcon.MessengerMessage, err error)
+}
+
+// ConsumerInterceptor intercepts the messages polled through the client, mirroring Kafka consumer interceptors.
+type ConsumerInterceptor interface {
+	// OnConsume is called after the messages are deserialized and before they are returned.
+	// It may mutate or filter the messages, and returning an error fails the poll. Returning nil
+	// drops every message, the poll then returns no message.
+	OnConsume(streamId, topicId iggcon.Identifier, polled *iggcon.PolledMessage) (*iggcon.PolledMessage, error)
+
+	// OnCommit is called once a consumer offset was stored, with a nil err on success.
+	OnCommit(consumer iggcon.Consumer, streamId, topicId iggcon.Identifier, offset uint64, partitionId *uint32, err error)
+}
+
+// ProducerInterceptors chains interceptors, OnSend runs them in order and every interceptor
+// receives the messages returned by the previous one.
+type ProducerInterceptors []ProducerInterceptor
+
+func (chain ProducerInterceptors) OnSend(streamId, topicId iggcon.Identifier, messages []iggcon.MessengerMessage) ([]iggcon.MessengerMessage, error) {
+	var err error
+	for _, interceptor := range chain {
+		if messages, err = interceptor.OnSend(streamId, topicId, messages); err != nil {
+			return nil, err
+		}
+		if len(messages) == 0 {
+			return messages, nil
+		}
+	}
+	return messages, nil
+}
+
+func (chain ProducerInterceptors) OnAcknowledgement(streamId, topicId iggcon.Identifier, messages []iggcon.MessengerMessage, err error) {
+	for _, interceptor := range chain {
+		interceptor.OnAcknowledgement(streamId, topicId, messages, err)
+	}
+}
+
+// ConsumerInterceptors chains interceptors, OnConsume runs them in order and every interceptor
+// receives the messages returned by the previous one, the chain stopping once one returns nil.
+type ConsumerInterceptors []ConsumerInterceptor
+
+func (chain ConsumerInterceptors) OnConsume(streamId, topicId iggcon.Identifier, polled *iggcon.PolledMessage) (*iggcon.PolledMessage, error) {
+	var err error
+	for _, interceptor := range chain {
+		if polled, err = interceptor.OnConsume(streamId, topicId, polled); err != nil {
+			return nil, err
+		}
+		if polled == nil {
+			return nil, nil
+		}
+	}
+	return polled, nil
+}
+
+func (chain ConsumerInterceptors) OnCommit(consumer iggcon.Consumer, streamId, topicId iggcon.Identifier, offset uint64, partitionId *uint32, err error) {
+	for _, interceptor := range chain {
+		interceptor.OnCommit(consumer, streamId, topicId, offset, partitionId, err)
+	}
+}
+
+// InterceptClient wraps any Client so the interceptor chains run around its messaging calls.
+func InterceptClient(client Client, producerInterceptors ProducerInterceptors, consumerInterceptors ConsumerInterceptors) Client {
+	return &interceptedClient{
+		Client:               client,
+		producerInterceptors: producerInterceptors,
+		consumerInterceptors: consumerInterceptors,
+	}
+}
+
+// interceptedClient runs the interceptor chains around the messaging calls of a Client.
+type interceptedClient struct {
+	Client
+	producerInterceptors ProducerInterceptors
+	consumerInterceptors ConsumerInterceptors
+}
+
+func (c *interceptedClient) SendMessages(
+	streamId iggcon.Identifier,
+	topicId iggcon.Identifier,
+	partitioning iggcon.Partitioning,
+	messages []iggcon.MessengerMessage,
+) error {
+	return c.send(streamId, topicId, messages, func(messages []iggcon.MessengerMessage) error {
+		return c.Client.SendMessages(streamId, topicId, partitioning, messages)
+	})
+}
+
+func (c *interceptedClient) SendMessagesWithConfirmation(
+	streamId iggcon.Identifier,
+	topicId iggcon.Identifier,
+	partitioning iggcon.Partitioning,
+	messages []iggcon.MessengerMessage,
+	confirmation iggcon.Confirmation,
+) error {
+	return c.send(streamId, topicId, messages, func(messages []iggcon.MessengerMessage) error {
+		return c.Client.SendMessagesWithConfirmation(streamId, topicId, partitioning, messages, confirmation)
+	})
+}
+
+func (c *interceptedClient) SendMessagesWithResult(
+	streamId iggcon.Identifier,
+	topicId iggcon.Identifier,
+	partitioning iggcon.Partitioning,
+	messages []iggcon.MessengerMessage,
+	confirmation iggcon.Confirmation,
+) (*iggcon.SendResult, error) {
+	var result *iggcon.SendResult
+	err := c.send(streamId, topicId, messages, func(messages []iggcon.MessengerMessage) error {
+		var err error
+		result, err = c.Client.SendMessagesWithResult(streamId, topicId, partitioning, messages, confirmation)
+		return err
+	})
+	return result, err
+}
+
+func (c *interceptedClient) send(
+	streamId iggcon.Identifier,
+	topicId iggcon.Identifier,
+	messages []iggcon.MessengerMessage,
+	send func(messages []iggcon.MessengerMessage) error,
+) error {
+	messages, err := c.producerInterceptors.OnSend(streamId, topicId, messages)
+	if err != nil {
+		return err
+	}
+	if len(messages) == 0 {
+		return nil
+	}
+	err = send(messages)
+	c.producerInterceptors.OnAcknowledgement(streamId, topicId, messages, err)
+	return err
+}
+
+func (c *interceptedClient) PollMessages(
+	streamId iggcon.Identifier,
+	topicId iggcon.Identifier,
+	consumer iggcon.Consumer,
+	strategy iggcon.PollingStrategy,
+	count uint32,
+	autoCommit bool,
+	partitionId *uint32,
+) (*iggcon.PolledMessage, error) {
+	polled, err := c.Client.PollMessages(streamId, topicId, consumer, strategy, count, autoCommit, partitionId)
+	if err != nil {
+		return nil, err
+	}
+	return c.consume(streamId, topicId, polled)
+}
+
+func (c *interceptedClient) PollMessagesWithWait(
+	streamId iggcon.Identifier,
+	topicId iggcon.Identifier,
+	consumer iggcon.Consumer,
+	strategy iggcon.PollingStrategy,
+	count uint32,
+	autoCommit bool,
+	partitionId *uint32,
+	maxWait time.Duration,
+) (*iggcon.PolledMessage, error) {
+	polled, err := c.Client.PollMessagesWithWait(streamId, topicId, consumer, strategy, count, autoCommit, partitionId, maxWait)
+	if err != nil {
+		return nil, err
+	}
+	return c.consume(streamId, topicId, polled)
+}
+
+func (c *interceptedClient) PollMessagesWithContext(
+	ctx context.Context,
+	streamId iggcon.Identifier,
+	topicId iggcon.Identifier,
+	consumer iggcon.Consumer,
+	strategy iggcon.PollingStrategy,
+	count uint32,
+	autoCommit bool,
+	partitionId *uint32,
+	maxWait time.Duration,
+) (*iggcon.PolledMessage, error) {
+	polled, err := PollMessagesWithContext(ctx, c.Client, streamId, topicId, consumer, strategy, count, autoCommit, partitionId, maxWait)
+	if err != nil {
+		return nil, err
+	}
+	return c.consume(streamId, topicId, polled)
+}
+
+// consume runs the consumer interceptors, the messages they all dropped being returned as an
+// empty poll of the same partition.
+func (c *interceptedClient) consume(streamId, topicId iggcon.Identifier, polled *iggcon.PolledMessage) (*iggcon.PolledMessage, error) {
+	intercepted, err := c.consumerInterceptors.OnConsume(streamId, topicId, polled)
+	if err != nil || intercepted != nil || polled == nil {
+		return intercepted, err
+	}
+	return &iggcon.PolledMessage{PartitionId: polled.PartitionId, CurrentOffset: polled.CurrentOffset}, nil
+}
+
+func (c *interceptedClient) StoreConsumerOffset(
+	consumer iggcon.Consumer,
+	streamId iggcon.Identifier,
+	topicId iggcon.Identifier,
+	offset uint64,
+	partitionId *uint32,
+) error {
+	err := c.Client.StoreConsumerOffset(consumer, streamId, topicId, offset, partitionId)
+	c.consumerInterceptors.OnCommit(consumer, streamId, topicId, offset, partitionId, err)
+	return err
+}
@@ -0,0 +1,228 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package messengercli_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengercli"
+	"github.com/apache/messenger/foreign/go/messengertest"
+)
+
+// recordingInterceptor records its calls in calls, and intercepts the messages with send and consume when set.
+type recordingInterceptor struct {
+	name    string
+	calls   *[]string
+	send    func(messages []iggcon.MessengerMessage) ([]iggcon.MessengerMessage, error)
+	consume func(polled *iggcon.PolledMessage) (*iggcon.PolledMessage, error)
+}
+
+func (r recordingInterceptor) OnSend(_, _ iggcon.Identifier, messages []iggcon.MessengerMessage) ([]iggcon.MessengerMessage, error) {
+	*r.calls = append(*r.calls, r.name+".OnSend")
+	if r.send == nil {
+		return messages, nil
+	}
+	return r.send(messages)
+}
+
+func (r recordingInterceptor) OnAcknowledgement(_, _ iggcon.Identifier, _ []iggcon.MessengerMessage, err error) {
+	*r.calls = append(*r.calls, r.name+".OnAcknowledgement")
+}
+
+func (r recordingInterceptor) OnConsume(_, _ iggcon.Identifier, polled *iggcon.PolledMessage) (*iggcon.PolledMessage, error) {
+	*r.calls = append(*r.calls, r.name+".OnConsume")
+	if r.consume == nil {
+		return polled, nil
+	}
+	return r.consume(polled)
+}
+
+func (r recordingInterceptor) OnCommit(iggcon.Consumer, iggcon.Identifier, iggcon.Identifier, uint64, *uint32, error) {
+	*r.calls = append(*r.calls, r.name+".OnCommit")
+}
+
+func suffixPayloads(suffix string) func([]iggcon.MessengerMessage) ([]iggcon.MessengerMessage, error) {
+	return func(messages []iggcon.MessengerMessage) ([]iggcon.MessengerMessage, error) {
+		for i := range messages {
+			messages[i].Payload = append(messages[i].Payload, suffix...)
+		}
+		return messages, nil
+	}
+}
+
+func newInterceptedTopic(t *testing.T) (*messengertest.Client, iggcon.Identifier, iggcon.Identifier) {
+	client := messengertest.NewClient()
+	if _, err := client.CreateStream("events", nil); err != nil {
+		t.Fatal(err)
+	}
+	streamId, topicId := iggcon.MustIdentifier("events"), iggcon.MustIdentifier("orders")
+	if _, err := client.CreateTopic(streamId, "orders", 1, iggcon.CompressionAlgorithmNone, 0, 0, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	return client, streamId, topicId
+}
+
+func TestProducerInterceptors(t *testing.T) {
+	failed := errors.New("rejected")
+	tests := []struct {
+		name  string
+		first func([]iggcon.MessengerMessage) ([]iggcon.MessengerMessage, error)
+		// payload is the payload stored by the server, none when nothing is sent
+		payload string
+		err     error
+		calls   []string
+	}{
+		{
+			name:    "runs in order on the messages of the previous interceptor",
+			first:   suffixPayloads("-first"),
+			payload: "order-first-second",
+			calls:   []string{"first.OnSend", "second.OnSend", "first.OnAcknowledgement", "second.OnAcknowledgement"},
+		},
+		{
+			name: "stops once no message is left",
+			first: func([]iggcon.MessengerMessage) ([]iggcon.MessengerMessage, error) {
+				return nil, nil
+			},
+			calls: []string{"first.OnSend"},
+		},
+		{
+			name: "stops at the first error",
+			first: func([]iggcon.MessengerMessage) ([]iggcon.MessengerMessage, error) {
+				return nil, failed
+			},
+			err:   failed,
+			calls: []string{"first.OnSend"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw, streamId, topicId := newInterceptedTopic(t)
+			var calls []string
+			client := messengercli.InterceptClient(raw, messengercli.ProducerInterceptors{
+				recordingInterceptor{name: "first", calls: &calls, send: tt.first},
+				recordingInterceptor{name: "second", calls: &calls, send: suffixPayloads("-second")},
+			}, nil)
+
+			message, _ := iggcon.NewMessengerMessage([]byte("order"))
+			err := client.SendMessages(streamId, topicId, iggcon.None(), []iggcon.MessengerMessage{message})
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("expected %v, got %v", tt.err, err)
+			}
+			if !slices.Equal(calls, tt.calls) {
+				t.Fatalf("expected the calls %v, got %v", tt.calls, calls)
+			}
+
+			partitionId := uint32(1)
+			polled, err := raw.PollMessages(streamId, topicId, iggcon.DefaultConsumer(), iggcon.FirstPollingStrategy(), 10, false, &partitionId)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.payload == "" {
+				if len(polled.Messages) != 0 {
+					t.Fatalf("expected nothing to be sent, got %d messages", len(polled.Messages))
+				}
+				return
+			}
+			if len(polled.Messages) != 1 || string(polled.Messages[0].Payload) != tt.payload {
+				t.Fatalf("expected the payload %q to be sent, got %v", tt.payload, polled.Messages)
+			}
+		})
+	}
+}
+
+func TestConsumerInterceptors(t *testing.T) {
+	failed := errors.New("rejected")
+	tests := []struct {
+		name  string
+		first func(*iggcon.PolledMessage) (*iggcon.PolledMessage, error)
+		// payloads are the polled payloads
+		payloads []string
+		err      error
+		calls    []string
+	}{
+		{
+			name: "runs in order on the messages of the previous interceptor",
+			first: func(polled *iggcon.PolledMessage) (*iggcon.PolledMessage, error) {
+				polled.Messages = polled.Messages[1:]
+				return polled, nil
+			},
+			payloads: []string{"b-second"},
+			calls:    []string{"first.OnConsume", "second.OnConsume"},
+		},
+		{
+			name: "stops once the messages are dropped",
+			first: func(*iggcon.PolledMessage) (*iggcon.PolledMessage, error) {
+				return nil, nil
+			},
+			calls: []string{"first.OnConsume"},
+		},
+		{
+			name: "stops at the first error",
+			first: func(*iggcon.PolledMessage) (*iggcon.PolledMessage, error) {
+				return nil, failed
+			},
+			err:   failed,
+			calls: []string{"first.OnConsume"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw, streamId, topicId := newInterceptedTopic(t)
+			a, _ := iggcon.NewMessengerMessage([]byte("a"))
+			b, _ := iggcon.NewMessengerMessage([]byte("b"))
+			if err := raw.SendMessages(streamId, topicId, iggcon.None(), []iggcon.MessengerMessage{a, b}); err != nil {
+				t.Fatal(err)
+			}
+			var calls []string
+			client := messengercli.InterceptClient(raw, nil, messengercli.ConsumerInterceptors{
+				recordingInterceptor{name: "first", calls: &calls, consume: tt.first},
+				recordingInterceptor{name: "second", calls: &calls, consume: func(polled *iggcon.PolledMessage) (*iggcon.PolledMessage, error) {
+					for i := range polled.Messages {
+						polled.Messages[i].Payload = append(polled.Messages[i].Payload, "-second"...)
+					}
+					return polled, nil
+				}},
+			})
+
+			partitionId := uint32(1)
+			polled, err := client.PollMessages(streamId, topicId, iggcon.DefaultConsumer(), iggcon.FirstPollingStrategy(), 10, false, &partitionId)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("expected %v, got %v", tt.err, err)
+			}
+			if !slices.Equal(calls, tt.calls) {
+				t.Fatalf("expected the calls %v, got %v", tt.calls, calls)
+			}
+			if tt.err != nil {
+				return
+			}
+			if polled == nil {
+				t.Fatal("expected a poll result")
+			}
+			payloads := make([]string, 0, len(polled.Messages))
+			for _, message := range polled.Messages {
+				payloads = append(payloads, string(message.Payload))
+			}
+			if !slices.Equal(payloads, tt.payloads) && len(payloads)+len(tt.payloads) > 0 {
+				t.Fatalf("expected the payloads %v, got %v", tt.payloads, payloads)
+			}
+		})
+	}
+}
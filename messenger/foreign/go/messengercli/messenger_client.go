@@ -19,14 +19,25 @@ package messengercli
 
 import (
 	"fmt"
+	"time"
 
 	iggcon "github.com/apache/messenger/foreign/go/contracts"
 	"github.com/apache/messenger/foreign/go/tcp"
 )
 
 type Options struct {
-	protocol   iggcon.Protocol
-	tcpOptions []tcp.Option
+	protocol             iggcon.Protocol
+	tcpOptions           []tcp.Option
+	producerInterceptors ProducerInterceptors
+	consumerInterceptors ConsumerInterceptors
+	metadataCacheTTL     time.Duration
+	streamIdentities     []streamIdentity
+	auditHook            AuditHook
+}
+
+type streamIdentity struct {
+	credentials Credentials
+	streams     []iggcon.Identifier
 }
 
 func GetDefaultOptions() Options {
@@ -46,6 +57,43 @@ func WithTcp(tcpOpts ...tcp.Option) Option {
 	}
 }
 
+// WithProducerInterceptors appends interceptors invoked, in order, before every send.
+func WithProducerInterceptors(interceptors ...ProducerInterceptor) Option {
+	return func(opts *Options) {
+		opts.producerInterceptors = append(opts.producerInterceptors, interceptors...)
+	}
+}
+
+// WithConsumerInterceptors appends interceptors invoked, in order, after every poll.
+func WithConsumerInterceptors(interceptors ...ConsumerInterceptor) Option {
+	return func(opts *Options) {
+		opts.consumerInterceptors = append(opts.consumerInterceptors, interceptors...)
+	}
+}
+
+// WithMetadataCache caches the responses of the stream and topic queries for ttl, see CacheMetadata.
+func WithMetadataCache(ttl time.Duration) Option {
+	return func(opts *Options) {
+		opts.metadataCacheTTL = ttl
+	}
+}
+
+// WithAuditHook calls hook after every administrative command changing the server, see AuditClient.
+func WithAuditHook(hook AuditHook) Option {
+	return func(opts *Options) {
+		opts.auditHook = hook
+	}
+}
+
+// WithStreamIdentity sends the commands scoped to the given streams through a separate connection,
+// logged in with the credentials when the client is created, see RouteStreams. The other commands
+// go through the main connection, which is logged in as usual.
+func WithStreamIdentity(credentials Credentials, streams ...iggcon.Identifier) Option {
+	return func(opts *Options) {
+		opts.streamIdentities = append(opts.streamIdentities, streamIdentity{credentials: credentials, streams: streams})
+	}
+}
+
 // NewMessengerClient create the MessengerClient instance.
 // If no Option is provided, NewMessengerClient will create a default TCP client.
 func NewMessengerClient(options ...Option) (Client, error) {
@@ -55,6 +103,35 @@ func NewMessengerClient(options ...Option) (Client, error) {
 		opt(&opts)
 	}
 
+	cli, err := newClient(opts)
+	if err != nil || len(opts.streamIdentities) == 0 {
+		return cli, err
+	}
+
+	routes := make([]StreamRoute, 0, len(opts.streamIdentities))
+	closeAll := func() {
+		_ = Close(cli)
+		for _, route := range routes {
+			_ = Close(route.Client)
+		}
+	}
+	for _, identity := range opts.streamIdentities {
+		tenant, err := newClient(opts)
+		if err != nil {
+			closeAll()
+			return nil, err
+		}
+		routes = append(routes, StreamRoute{Streams: identity.streams, Client: tenant})
+		if err = identity.credentials.login(tenant); err != nil {
+			closeAll()
+			return nil, fmt.Errorf("failed to log in the connection of a stream identity: %w", err)
+		}
+	}
+	return RouteStreams(cli, routes...), nil
+}
+
+// newClient creates a client with a single connection.
+func newClient(opts Options) (Client, error) {
 	var err error
 	var cli Client
 	switch opts.protocol {
@@ -67,5 +144,33 @@ func NewMessengerClient(options ...Option) (Client, error) {
 		return nil, fmt.Errorf("failed to create an messenger client: %w", err)
 	}
 
+	if len(opts.producerInterceptors) > 0 || len(opts.consumerInterceptors) > 0 {
+		cli = InterceptClient(cli, opts.producerInterceptors, opts.consumerInterceptors)
+	}
+	if opts.auditHook != nil {
+		cli = AuditClient(cli, opts.auditHook)
+	}
+	if opts.metadataCacheTTL > 0 {
+		cli = CacheMetadata(cli, opts.metadataCacheTTL)
+	}
+
 	return cli, nil
 }
+
+// NewAdminClient creates a client like NewMessengerClient, restricted to the administration API.
+func NewAdminClient(options ...Option) (AdminClient, error) {
+	cli, err := NewMessengerClient(options...)
+	if err != nil {
+		return nil, err
+	}
+	return AsAdmin(cli), nil
+}
+
+// NewDataClient creates a client like NewMessengerClient, restricted to sending and polling messages.
+func NewDataClient(options ...Option) (DataClient, error) {
+	cli, err := NewMessengerClient(options...)
+	if err != nil {
+		return nil, err
+	}
+	return AsData(cli), nil
+}
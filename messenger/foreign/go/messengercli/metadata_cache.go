@@ -0,0 +1,219 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package messengercli
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+)
+
+// CacheMetadata wraps any Client so GetStream, GetStreams, GetTopic and GetTopics are answered
+// from a cache for ttl after a successful call, reducing the metadata requests of hot paths like
+// partition count lookups. Every stream, topic or partition created, updated or deleted through
+// the returned client clears the cache, while the changes made by other clients are seen once the
+// entries expire or after InvalidateMetadata. The cached values are shared between the callers,
+// which must not modify them.
+func CacheMetadata(client Client, ttl time.Duration) Client {
+	return &metadataCachedClient{Client: client, ttl: ttl, entries: make(map[metadataKey]metadataEntry)}
+}
+
+// InvalidateMetadata clears the metadata cached by a client created by CacheMetadata, or by
+// NewMessengerClient with WithMetadataCache, including by the clients of the routes of
+// RouteStreams. It does nothing for the other clients.
+func InvalidateMetadata(client any) {
+	for {
+		switch c := client.(type) {
+		case *metadataCachedClient:
+			c.invalidate()
+			return
+		case *streamRoutedClient:
+			for _, route := range c.clients {
+				InvalidateMetadata(route)
+			}
+			client = c.Client
+		case *interceptedClient:
+			client = c.Client
+		case *auditedClient:
+			client = c.Client
+		case adminClient:
+			client = c.AdminClient
+		case dataClient:
+			client = c.DataClient
+		default:
+			return
+		}
+	}
+}
+
+// metadataKey identifies a cached response: the command and the identifiers it was called with.
+type metadataKey struct {
+	command string
+	stream  string
+	topic   string
+}
+
+type metadataEntry struct {
+	value   any
+	expires time.Time
+}
+
+// metadataCachedClient caches the metadata queries of a Client.
+type metadataCachedClient struct {
+	Client
+	ttl time.Duration
+
+	mtx     sync.Mutex
+	entries map[metadataKey]metadataEntry
+	// generation is incremented by every invalidation, so a query running concurrently with a
+	// change does not cache its possibly outdated response.
+	generation uint64
+}
+
+func identifierKey(id iggcon.Identifier) string {
+	return string(append([]byte{byte(id.Kind)}, id.Value...))
+}
+
+// cachedMetadata returns the cached response of the key, or else loads and caches it.
+func cachedMetadata[T any](c *metadataCachedClient, key metadataKey, load func() (T, error)) (T, error) {
+	c.mtx.Lock()
+	entry, ok := c.entries[key]
+	generation := c.generation
+	c.mtx.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		return entry.value.(T), nil
+	}
+
+	value, err := load()
+	if err != nil {
+		return value, err
+	}
+	c.mtx.Lock()
+	if c.generation == generation {
+		c.entries[key] = metadataEntry{value: value, expires: time.Now().Add(c.ttl)}
+	}
+	c.mtx.Unlock()
+	return value, nil
+}
+
+func (c *metadataCachedClient) invalidate() {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	clear(c.entries)
+	c.generation++
+}
+
+func (c *metadataCachedClient) GetStream(streamId iggcon.Identifier) (*iggcon.StreamDetails, error) {
+	return cachedMetadata(c, metadataKey{command: "GetStream", stream: identifierKey(streamId)}, func() (*iggcon.StreamDetails, error) {
+		return c.Client.GetStream(streamId)
+	})
+}
+
+func (c *metadataCachedClient) GetStreams() ([]iggcon.Stream, error) {
+	return cachedMetadata(c, metadataKey{command: "GetStreams"}, c.Client.GetStreams)
+}
+
+func (c *metadataCachedClient) GetTopic(streamId, topicId iggcon.Identifier) (*iggcon.TopicDetails, error) {
+	key := metadataKey{command: "GetTopic", stream: identifierKey(streamId), topic: identifierKey(topicId)}
+	return cachedMetadata(c, key, func() (*iggcon.TopicDetails, error) {
+		return c.Client.GetTopic(streamId, topicId)
+	})
+}
+
+func (c *metadataCachedClient) GetTopics(streamId iggcon.Identifier) ([]iggcon.Topic, error) {
+	return cachedMetadata(c, metadataKey{command: "GetTopics", stream: identifierKey(streamId)}, func() ([]iggcon.Topic, error) {
+		return c.Client.GetTopics(streamId)
+	})
+}
+
+// The changes invalidate the whole cache, as a stream or topic may be cached under both its ID
+// and its name.
+
+func (c *metadataCachedClient) CreateStream(name string, streamId *uint32) (*iggcon.StreamDetails, error) {
+	defer c.invalidate()
+	return c.Client.CreateStream(name, streamId)
+}
+
+func (c *metadataCachedClient) UpdateStream(streamId iggcon.Identifier, name string) error {
+	defer c.invalidate()
+	return c.Client.UpdateStream(streamId, name)
+}
+
+func (c *metadataCachedClient) DeleteStream(id iggcon.Identifier) error {
+	defer c.invalidate()
+	return c.Client.DeleteStream(id)
+}
+
+func (c *metadataCachedClient) CreateTopic(
+	streamId iggcon.Identifier,
+	name string,
+	partitionsCount uint32,
+	compressionAlgorithm iggcon.CompressionAlgorithm,
+	messageExpiry iggcon.Expiry,
+	maxTopicSize iggcon.MaxTopicSize,
+	replicationFactor *uint8,
+	topicId *uint32,
+) (*iggcon.TopicDetails, error) {
+	defer c.invalidate()
+	return c.Client.CreateTopic(streamId, name, partitionsCount, compressionAlgorithm, messageExpiry, maxTopicSize, replicationFactor, topicId)
+}
+
+func (c *metadataCachedClient) UpdateTopic(
+	streamId iggcon.Identifier,
+	topicId iggcon.Identifier,
+	name string,
+	compressionAlgorithm iggcon.CompressionAlgorithm,
+	messageExpiry iggcon.Expiry,
+	maxTopicSize iggcon.MaxTopicSize,
+	replicationFactor *uint8,
+) error {
+	defer c.invalidate()
+	return c.Client.UpdateTopic(streamId, topicId, name, compressionAlgorithm, messageExpiry, maxTopicSize, replicationFactor)
+}
+
+func (c *metadataCachedClient) DeleteTopic(streamId, topicId iggcon.Identifier) error {
+	defer c.invalidate()
+	return c.Client.DeleteTopic(streamId, topicId)
+}
+
+func (c *metadataCachedClient) CreatePartitions(streamId, topicId iggcon.Identifier, partitionsCount uint32) error {
+	defer c.invalidate()
+	return c.Client.CreatePartitions(streamId, topicId, partitionsCount)
+}
+
+func (c *metadataCachedClient) DeletePartitions(streamId, topicId iggcon.Identifier, partitionsCount uint32) error {
+	defer c.invalidate()
+	return c.Client.DeletePartitions(streamId, topicId, partitionsCount)
+}
+
+// PollMessagesWithContext forwards the context polls, which the embedded Client does not expose.
+func (c *metadataCachedClient) PollMessagesWithContext(
+	ctx context.Context,
+	streamId iggcon.Identifier,
+	topicId iggcon.Identifier,
+	consumer iggcon.Consumer,
+	strategy iggcon.PollingStrategy,
+	count uint32,
+	autoCommit bool,
+	partitionId *uint32,
+	maxWait time.Duration,
+) (*iggcon.PolledMessage, error) {
+	return PollMessagesWithContext(ctx, c.Client, streamId, topicId, consumer, strategy, count, autoCommit, partitionId, maxWait)
+}
@@ -0,0 +1,100 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package messengercli_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengercli"
+	"github.com/apache/messenger/foreign/go/messengertest"
+)
+
+// countingClient counts the GetTopic calls reaching the server.
+type countingClient struct {
+	messengercli.Client
+	getTopic atomic.Int32
+}
+
+func (c *countingClient) GetTopic(streamId, topicId iggcon.Identifier) (*iggcon.TopicDetails, error) {
+	c.getTopic.Add(1)
+	return c.Client.GetTopic(streamId, topicId)
+}
+
+func TestCacheMetadata(t *testing.T) {
+	server := messengertest.NewClient()
+	if _, err := server.CreateStream("orders", nil); err != nil {
+		t.Fatal(err)
+	}
+	streamId, topicId := iggcon.MustIdentifier("orders"), iggcon.MustIdentifier("created")
+	counting := &countingClient{Client: server}
+	client := messengercli.CacheMetadata(counting, time.Hour)
+	if _, err := messengercli.CreateTopic(context.Background(), client, iggcon.CreateTopicRequest{StreamId: streamId, Name: "created"}); err != nil {
+		t.Fatal(err)
+	}
+
+	partitionsCount := func() uint32 {
+		t.Helper()
+		topic, err := client.GetTopic(streamId, topicId)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return topic.PartitionsCount
+	}
+	if partitionsCount() != 1 || partitionsCount() != 1 || counting.getTopic.Load() != 1 {
+		t.Fatalf("expected a single GetTopic to reach the server, got %d", counting.getTopic.Load())
+	}
+
+	// a change made by another client is only seen after an invalidation
+	if err := server.CreatePartitions(streamId, topicId, 1); err != nil {
+		t.Fatal(err)
+	}
+	if count := partitionsCount(); count != 1 {
+		t.Fatalf("expected the cached partitions count, got %d", count)
+	}
+	messengercli.InvalidateMetadata(client)
+	if count := partitionsCount(); count != 2 {
+		t.Fatalf("expected 2 partitions after the invalidation, got %d", count)
+	}
+
+	// a change made through the client invalidates the cache
+	if err := client.CreatePartitions(streamId, topicId, 2); err != nil {
+		t.Fatal(err)
+	}
+	if count := partitionsCount(); count != 4 {
+		t.Fatalf("expected 4 partitions after creating partitions, got %d", count)
+	}
+	if calls := counting.getTopic.Load(); calls != 3 {
+		t.Fatalf("expected 3 GetTopic to reach the server, got %d", calls)
+	}
+
+	expiring := messengercli.CacheMetadata(counting, time.Millisecond)
+	if _, err := expiring.GetTopic(streamId, topicId); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if _, err := expiring.GetTopic(streamId, topicId); err != nil {
+		t.Fatal(err)
+	}
+	if calls := counting.getTopic.Load(); calls != 5 {
+		t.Fatalf("expected the expired entry to be loaded again, got %d calls", calls)
+	}
+}
@@ -0,0 +1,98 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package messengercli
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+)
+
+// defaultPollWorkers is the number of partitions PollAllPartitions polls at once when workers is 0.
+const defaultPollWorkers = 4
+
+// PollAllPartitions polls up to request.Count messages from every partition of the topic, its
+// PartitionId being ignored, with at most workers polls in flight. The messages are merged in
+// the order of their timestamps, those appended at the same time in the order of their partition
+// and offset. The first error cancels the polls not yet sent and is returned.
+func PollAllPartitions(ctx context.Context, client Client, request iggcon.PollMessageRequest, workers int) ([]iggcon.ReceivedMessage, error) {
+	topic, err := client.GetTopic(request.StreamId, request.TopicId)
+	if err != nil {
+		return nil, err
+	}
+	if workers <= 0 {
+		workers = defaultPollWorkers
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	partitions := make(chan int)
+	results := make([]*iggcon.PolledMessage, len(topic.Partitions))
+	var wg sync.WaitGroup
+	var once sync.Once
+	var firstErr error
+	for range min(workers, len(topic.Partitions)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range partitions {
+				partitionRequest := request
+				partitionRequest.PartitionId = topic.Partitions[index].Id
+				polled, err := PollMessages(ctx, client, partitionRequest)
+				if err != nil {
+					once.Do(func() {
+						firstErr = err
+						cancel()
+					})
+					continue
+				}
+				results[index] = polled
+			}
+		}()
+	}
+	for index := range topic.Partitions {
+		select {
+		case partitions <- index:
+		case <-ctx.Done():
+		}
+	}
+	close(partitions)
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var messages []iggcon.ReceivedMessage
+	for _, polled := range results {
+		if polled == nil {
+			continue
+		}
+		for _, message := range polled.Messages {
+			messages = append(messages, iggcon.ReceivedMessage{Message: message, CurrentOffset: polled.CurrentOffset, PartitionId: polled.PartitionId})
+		}
+	}
+	sort.SliceStable(messages, func(i, j int) bool {
+		return messages[i].Message.Header.Timestamp < messages[j].Message.Header.Timestamp
+	})
+	return messages, nil
+}
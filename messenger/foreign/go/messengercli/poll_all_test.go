@@ -0,0 +1,70 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package messengercli_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengercli"
+	"github.com/apache/messenger/foreign/go/messengertest"
+)
+
+func TestPollAllPartitions(t *testing.T) {
+	client := messengertest.NewClient()
+	if _, err := client.CreateStream("orders", nil); err != nil {
+		t.Fatal(err)
+	}
+	streamId := iggcon.MustIdentifier("orders")
+	if _, err := client.CreateTopic(streamId, "created", 3, iggcon.CompressionAlgorithmNone, 0, 0, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	topicId := iggcon.MustIdentifier("created")
+	for i := 0; i < 9; i++ {
+		message, _ := iggcon.NewMessengerMessage([]byte(fmt.Sprintf("order %d", i)))
+		if err := client.SendMessages(streamId, topicId, iggcon.PartitionId(uint32(i%3+1)), []iggcon.MessengerMessage{message}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	request := iggcon.PollMessageRequest{StreamId: streamId, TopicId: topicId, PollingStrategy: iggcon.FirstPollingStrategy(), Count: 2}
+	messages, err := messengercli.PollAllPartitions(context.Background(), client, request, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 6 {
+		t.Fatalf("expected 2 messages from each of the 3 partitions, got %d", len(messages))
+	}
+	perPartition := make(map[uint32]int)
+	for i, message := range messages {
+		perPartition[message.PartitionId]++
+		if i > 0 && message.Message.Header.Timestamp < messages[i-1].Message.Header.Timestamp {
+			t.Fatal("expected the messages to be merged in the order of their timestamps")
+		}
+	}
+	if len(perPartition) != 3 {
+		t.Fatalf("expected messages from 3 partitions, got %v", perPartition)
+	}
+
+	request.TopicId = iggcon.MustIdentifier("missing")
+	if _, err = messengercli.PollAllPartitions(context.Background(), client, request, 0); err == nil {
+		t.Fatal("expected polling a missing topic to fail")
+	}
+}
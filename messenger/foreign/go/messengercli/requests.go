@@ -0,0 +1,147 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package messengercli
+
+import (
+	"context"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+)
+
+// CreateTopic creates the topic described by the request, once its defaults are filled in and
+// it is validated. The context is checked before sending the request, which is then bound by the
+// timeouts of the client.
+func CreateTopic(ctx context.Context, client AdminClient, request iggcon.CreateTopicRequest) (*iggcon.TopicDetails, error) {
+	request = request.WithDefaults()
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return client.CreateTopic(
+		request.StreamId,
+		request.Name,
+		request.PartitionsCount,
+		request.CompressionAlgorithm,
+		request.MessageExpiry,
+		request.MaxTopicSize,
+		request.ReplicationFactor,
+		request.TopicId,
+	)
+}
+
+// UpdateTopic updates a topic with the settings of the request, like CreateTopic.
+func UpdateTopic(ctx context.Context, client AdminClient, request iggcon.UpdateTopicRequest) error {
+	request = request.WithDefaults()
+	if err := request.Validate(); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return client.UpdateTopic(
+		request.StreamId,
+		request.TopicId,
+		request.Name,
+		request.CompressionAlgorithm,
+		request.MessageExpiry,
+		request.MaxTopicSize,
+		request.ReplicationFactor,
+	)
+}
+
+// ContextPoller is implemented by the clients whose long polls can be interrupted when a context
+// is done, instead of waiting for the server to answer.
+type ContextPoller interface {
+	// PollMessagesWithContext polls messages like PollMessagesWithWait, returning the error of ctx
+	// as soon as it is done.
+	PollMessagesWithContext(
+		ctx context.Context,
+		streamId iggcon.Identifier,
+		topicId iggcon.Identifier,
+		consumer iggcon.Consumer,
+		strategy iggcon.PollingStrategy,
+		count uint32,
+		autoCommit bool,
+		partitionId *uint32,
+		maxWait time.Duration,
+	) (*iggcon.PolledMessage, error)
+}
+
+// PollMessages polls the messages described by the request. With a MaxWait, the poll is held by
+// the server until messages are available, at most until the deadline of the context, and is
+// interrupted when the context is done if the client is a ContextPoller.
+func PollMessages(ctx context.Context, client DataClient, request iggcon.PollMessageRequest) (*iggcon.PolledMessage, error) {
+	request = request.WithDefaults()
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	maxWait := request.MaxWait
+	if deadline, ok := ctx.Deadline(); ok && maxWait > 0 {
+		maxWait = max(min(maxWait, time.Until(deadline)), time.Millisecond)
+	}
+	if maxWait <= 0 {
+		return client.PollMessages(
+			request.StreamId,
+			request.TopicId,
+			request.Consumer,
+			request.PollingStrategy,
+			uint32(request.Count),
+			request.AutoCommit,
+			request.Partition(),
+		)
+	}
+	return PollMessagesWithContext(
+		ctx,
+		client,
+		request.StreamId,
+		request.TopicId,
+		request.Consumer,
+		request.PollingStrategy,
+		uint32(request.Count),
+		request.AutoCommit,
+		request.Partition(),
+		maxWait,
+	)
+}
+
+// PollMessagesWithContext polls messages like PollMessagesWithWait, interrupting the poll when
+// ctx is done if client is a ContextPoller. The clients wrapping another one forward their
+// context polls through it, the other clients wait for the server to answer.
+func PollMessagesWithContext(
+	ctx context.Context,
+	client DataClient,
+	streamId iggcon.Identifier,
+	topicId iggcon.Identifier,
+	consumer iggcon.Consumer,
+	strategy iggcon.PollingStrategy,
+	count uint32,
+	autoCommit bool,
+	partitionId *uint32,
+	maxWait time.Duration,
+) (*iggcon.PolledMessage, error) {
+	if poller, ok := client.(ContextPoller); ok {
+		return poller.PollMessagesWithContext(ctx, streamId, topicId, consumer, strategy, count, autoCommit, partitionId, maxWait)
+	}
+	return client.PollMessagesWithWait(streamId, topicId, consumer, strategy, count, autoCommit, partitionId, maxWait)
+}
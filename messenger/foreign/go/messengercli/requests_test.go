@@ -0,0 +1,173 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package messengercli_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	ierror "github.com/apache/messenger/foreign/go/errors"
+	"github.com/apache/messenger/foreign/go/messengercli"
+	"github.com/apache/messenger/foreign/go/messengertest"
+)
+
+func TestCreateTopic_Request(t *testing.T) {
+	client := messengertest.NewClient()
+	if _, err := client.CreateStream("orders", nil); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	streamId := iggcon.MustIdentifier("orders")
+
+	if _, err := messengercli.CreateTopic(ctx, client, iggcon.CreateTopicRequest{StreamId: streamId}); err == nil {
+		t.Fatal("expected a topic without a name to be rejected")
+	}
+	topic, err := messengercli.CreateTopic(ctx, client, iggcon.CreateTopicRequest{
+		StreamId:     streamId,
+		Name:         "created",
+		MaxTopicSize: 10 * iggcon.GiB,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if topic.PartitionsCount != 1 || topic.CompressionAlgorithm != uint8(iggcon.CompressionAlgorithmNone) || topic.MaxTopicSize != 10*iggcon.GiB {
+		t.Fatalf("expected the defaults to be applied, got %+v", topic.Topic)
+	}
+
+	err = messengercli.UpdateTopic(ctx, client, iggcon.UpdateTopicRequest{
+		StreamId:      streamId,
+		TopicId:       iggcon.MustIdentifier("created"),
+		Name:          "created",
+		MessageExpiry: iggcon.ExpiryAfter(time.Hour),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if topic, err = client.GetTopic(streamId, iggcon.MustIdentifier("created")); err != nil {
+		t.Fatal(err)
+	}
+	if topic.MessageExpiry.Duration() != time.Hour {
+		t.Fatalf("expected an expiry of 1h, got %v", topic.MessageExpiry)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = messengercli.CreateTopic(cancelled, client, iggcon.CreateTopicRequest{StreamId: streamId, Name: "other"})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the cancelled context to stop the request, got %v", err)
+	}
+}
+
+func TestPollMessages_Request(t *testing.T) {
+	client := messengertest.NewClient()
+	if _, err := client.CreateStream("orders", nil); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	streamId, topicId := iggcon.MustIdentifier("orders"), iggcon.MustIdentifier("created")
+	if _, err := messengercli.CreateTopic(ctx, client, iggcon.CreateTopicRequest{StreamId: streamId, Name: "created"}); err != nil {
+		t.Fatal(err)
+	}
+	message, err := iggcon.NewMessengerMessage([]byte("order"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = client.SendMessages(streamId, topicId, iggcon.PartitionId(1), []iggcon.MessengerMessage{message}); err != nil {
+		t.Fatal(err)
+	}
+
+	request := iggcon.PollMessageRequest{
+		StreamId:        streamId,
+		TopicId:         topicId,
+		PartitionId:     1,
+		PollingStrategy: iggcon.FirstPollingStrategy(),
+	}
+	if _, err = messengercli.PollMessages(ctx, client, request); !errors.Is(err, ierror.InvalidMessagesCount) {
+		t.Fatalf("expected a poll without count to be rejected, got %v", err)
+	}
+	request.Count = -1
+	if _, err = messengercli.PollMessages(ctx, client, request); !errors.Is(err, ierror.InvalidMessagesCount) {
+		t.Fatalf("expected a poll with a negative count to be rejected, got %v", err)
+	}
+	request.Count = 10
+	polled, err := messengercli.PollMessages(ctx, client, request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(polled.Messages) != 1 || string(polled.Messages[0].Payload) != "order" {
+		t.Fatalf("expected the sent message, got %+v", polled.Messages)
+	}
+}
+
+func TestPollMessages_CancelledThroughWrappers(t *testing.T) {
+	wrappers := map[string]func(client messengercli.Client) messengercli.DataClient{
+		"interceptors": func(client messengercli.Client) messengercli.DataClient {
+			return messengercli.InterceptClient(client, nil, messengercli.ConsumerInterceptors{recordingInterceptor{calls: new([]string)}})
+		},
+		"audit": func(client messengercli.Client) messengercli.DataClient {
+			return messengercli.AuditClient(client, func(messengercli.AuditEvent) {})
+		},
+		"metadata cache": func(client messengercli.Client) messengercli.DataClient {
+			return messengercli.CacheMetadata(client, time.Minute)
+		},
+		"stream routes": func(client messengercli.Client) messengercli.DataClient {
+			return messengercli.RouteStreams(messengertest.NewClient(), messengercli.StreamRoute{Streams: []iggcon.Identifier{iggcon.MustIdentifier("orders")}, Client: client})
+		},
+		"data client": func(client messengercli.Client) messengercli.DataClient {
+			return messengercli.AsData(client)
+		},
+		"all of them": func(client messengercli.Client) messengercli.DataClient {
+			client = messengercli.InterceptClient(client, nil, messengercli.ConsumerInterceptors{recordingInterceptor{calls: new([]string)}})
+			client = messengercli.AuditClient(client, func(messengercli.AuditEvent) {})
+			return messengercli.AsData(messengercli.CacheMetadata(client, time.Minute))
+		},
+	}
+	for name, wrap := range wrappers {
+		t.Run(name, func(t *testing.T) {
+			raw := messengertest.NewClient()
+			if _, err := raw.CreateStream("orders", nil); err != nil {
+				t.Fatal(err)
+			}
+			streamId, topicId := iggcon.MustIdentifier("orders"), iggcon.MustIdentifier("created")
+			if _, err := raw.CreateTopic(streamId, "created", 1, iggcon.CompressionAlgorithmNone, 0, 0, nil, nil); err != nil {
+				t.Fatal(err)
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			time.AfterFunc(20*time.Millisecond, cancel)
+			start := time.Now()
+			_, err := messengercli.PollMessages(ctx, wrap(raw), iggcon.PollMessageRequest{
+				StreamId:        streamId,
+				TopicId:         topicId,
+				PartitionId:     1,
+				PollingStrategy: iggcon.NextPollingStrategy(),
+				Count:           10,
+				MaxWait:         time.Minute,
+			})
+			if !errors.Is(err, context.Canceled) {
+				t.Fatalf("expected the poll to be cancelled, got %v", err)
+			}
+			if elapsed := time.Since(start); elapsed > 5*time.Second {
+				t.Fatalf("expected the poll to be interrupted, took %s", elapsed)
+			}
+		})
+	}
+}
@@ -0,0 +1,265 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package messengercli
+
+import (
+	"context"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+)
+
+// Credentials authenticate a connection, with a personal access token when it is set, or else
+// with the username and password.
+type Credentials struct {
+	Username            string
+	Password            string
+	PersonalAccessToken string
+}
+
+func (c Credentials) login(client Session) error {
+	var err error
+	if c.PersonalAccessToken != "" {
+		_, err = client.LoginWithPersonalAccessToken(c.PersonalAccessToken)
+	} else {
+		_, err = client.LoginUser(c.Username, c.Password)
+	}
+	return err
+}
+
+// StreamRoute sends the commands scoped to its streams through its client. A stream is matched by
+// the identifier the commands reference it with, so a stream referenced by both its name and its
+// ID must be listed under both.
+type StreamRoute struct {
+	Streams []iggcon.Identifier
+	Client  Client
+}
+
+// RouteStreams returns a Client sending the commands scoped to a stream, like the topic, consumer
+// group and message commands, through the client of the route of the stream, and the other
+// commands, like the queries of all the streams or the user management, through fallback. It
+// lets a multi-tenant gateway serve every tenant from a single process, each tenant's streams
+// being accessed through a connection authenticated as the tenant.
+func RouteStreams(fallback Client, routes ...StreamRoute) Client {
+	c := &streamRoutedClient{Client: fallback, routes: make(map[string]Client)}
+	for _, route := range routes {
+		for _, stream := range route.Streams {
+			c.routes[identifierKey(stream)] = route.Client
+		}
+		c.clients = append(c.clients, route.Client)
+	}
+	return c
+}
+
+type streamRoutedClient struct {
+	Client
+	routes  map[string]Client
+	clients []Client
+}
+
+func (c *streamRoutedClient) route(streamId iggcon.Identifier) Client {
+	if client, ok := c.routes[identifierKey(streamId)]; ok {
+		return client
+	}
+	return c.Client
+}
+
+func (c *streamRoutedClient) GetStream(streamId iggcon.Identifier) (*iggcon.StreamDetails, error) {
+	return c.route(streamId).GetStream(streamId)
+}
+
+func (c *streamRoutedClient) CreateStream(name string, streamId *uint32) (*iggcon.StreamDetails, error) {
+	id, err := iggcon.NewIdentifier(name)
+	if err != nil {
+		return nil, err
+	}
+	return c.route(id).CreateStream(name, streamId)
+}
+
+func (c *streamRoutedClient) UpdateStream(streamId iggcon.Identifier, name string) error {
+	return c.route(streamId).UpdateStream(streamId, name)
+}
+
+func (c *streamRoutedClient) DeleteStream(id iggcon.Identifier) error {
+	return c.route(id).DeleteStream(id)
+}
+
+func (c *streamRoutedClient) GetTopic(streamId, topicId iggcon.Identifier) (*iggcon.TopicDetails, error) {
+	return c.route(streamId).GetTopic(streamId, topicId)
+}
+
+func (c *streamRoutedClient) GetTopics(streamId iggcon.Identifier) ([]iggcon.Topic, error) {
+	return c.route(streamId).GetTopics(streamId)
+}
+
+func (c *streamRoutedClient) CreateTopic(
+	streamId iggcon.Identifier,
+	name string,
+	partitionsCount uint32,
+	compressionAlgorithm iggcon.CompressionAlgorithm,
+	messageExpiry iggcon.Expiry,
+	maxTopicSize iggcon.MaxTopicSize,
+	replicationFactor *uint8,
+	topicId *uint32,
+) (*iggcon.TopicDetails, error) {
+	return c.route(streamId).CreateTopic(streamId, name, partitionsCount, compressionAlgorithm, messageExpiry, maxTopicSize, replicationFactor, topicId)
+}
+
+func (c *streamRoutedClient) UpdateTopic(
+	streamId iggcon.Identifier,
+	topicId iggcon.Identifier,
+	name string,
+	compressionAlgorithm iggcon.CompressionAlgorithm,
+	messageExpiry iggcon.Expiry,
+	maxTopicSize iggcon.MaxTopicSize,
+	replicationFactor *uint8,
+) error {
+	return c.route(streamId).UpdateTopic(streamId, topicId, name, compressionAlgorithm, messageExpiry, maxTopicSize, replicationFactor)
+}
+
+func (c *streamRoutedClient) DeleteTopic(streamId, topicId iggcon.Identifier) error {
+	return c.route(streamId).DeleteTopic(streamId, topicId)
+}
+
+func (c *streamRoutedClient) GetConsumerGroups(streamId iggcon.Identifier, topicId iggcon.Identifier) ([]iggcon.ConsumerGroup, error) {
+	return c.route(streamId).GetConsumerGroups(streamId, topicId)
+}
+
+func (c *streamRoutedClient) GetConsumerGroup(streamId, topicId, groupId iggcon.Identifier) (*iggcon.ConsumerGroupDetails, error) {
+	return c.route(streamId).GetConsumerGroup(streamId, topicId, groupId)
+}
+
+func (c *streamRoutedClient) CreateConsumerGroup(streamId, topicId iggcon.Identifier, name string, groupId *uint32) (*iggcon.ConsumerGroupDetails, error) {
+	return c.route(streamId).CreateConsumerGroup(streamId, topicId, name, groupId)
+}
+
+func (c *streamRoutedClient) DeleteConsumerGroup(streamId, topicId, groupId iggcon.Identifier) error {
+	return c.route(streamId).DeleteConsumerGroup(streamId, topicId, groupId)
+}
+
+func (c *streamRoutedClient) JoinConsumerGroup(streamId, topicId, groupId iggcon.Identifier) error {
+	return c.route(streamId).JoinConsumerGroup(streamId, topicId, groupId)
+}
+
+func (c *streamRoutedClient) LeaveConsumerGroup(streamId, topicId, groupId iggcon.Identifier) error {
+	return c.route(streamId).LeaveConsumerGroup(streamId, topicId, groupId)
+}
+
+func (c *streamRoutedClient) CreatePartitions(streamId, topicId iggcon.Identifier, partitionsCount uint32) error {
+	return c.route(streamId).CreatePartitions(streamId, topicId, partitionsCount)
+}
+
+func (c *streamRoutedClient) DeletePartitions(streamId, topicId iggcon.Identifier, partitionsCount uint32) error {
+	return c.route(streamId).DeletePartitions(streamId, topicId, partitionsCount)
+}
+
+func (c *streamRoutedClient) SendMessages(
+	streamId iggcon.Identifier,
+	topicId iggcon.Identifier,
+	partitioning iggcon.Partitioning,
+	messages []iggcon.MessengerMessage,
+) error {
+	return c.route(streamId).SendMessages(streamId, topicId, partitioning, messages)
+}
+
+func (c *streamRoutedClient) SendMessagesWithConfirmation(
+	streamId iggcon.Identifier,
+	topicId iggcon.Identifier,
+	partitioning iggcon.Partitioning,
+	messages []iggcon.MessengerMessage,
+	confirmation iggcon.Confirmation,
+) error {
+	return c.route(streamId).SendMessagesWithConfirmation(streamId, topicId, partitioning, messages, confirmation)
+}
+
+func (c *streamRoutedClient) SendMessagesWithResult(
+	streamId iggcon.Identifier,
+	topicId iggcon.Identifier,
+	partitioning iggcon.Partitioning,
+	messages []iggcon.MessengerMessage,
+	confirmation iggcon.Confirmation,
+) (*iggcon.SendResult, error) {
+	return c.route(streamId).SendMessagesWithResult(streamId, topicId, partitioning, messages, confirmation)
+}
+
+func (c *streamRoutedClient) PollMessages(
+	streamId iggcon.Identifier,
+	topicId iggcon.Identifier,
+	consumer iggcon.Consumer,
+	strategy iggcon.PollingStrategy,
+	count uint32,
+	autoCommit bool,
+	partitionId *uint32,
+) (*iggcon.PolledMessage, error) {
+	return c.route(streamId).PollMessages(streamId, topicId, consumer, strategy, count, autoCommit, partitionId)
+}
+
+func (c *streamRoutedClient) PollMessagesWithWait(
+	streamId iggcon.Identifier,
+	topicId iggcon.Identifier,
+	consumer iggcon.Consumer,
+	strategy iggcon.PollingStrategy,
+	count uint32,
+	autoCommit bool,
+	partitionId *uint32,
+	maxWait time.Duration,
+) (*iggcon.PolledMessage, error) {
+	return c.route(streamId).PollMessagesWithWait(streamId, topicId, consumer, strategy, count, autoCommit, partitionId, maxWait)
+}
+
+func (c *streamRoutedClient) PollMessagesWithContext(
+	ctx context.Context,
+	streamId iggcon.Identifier,
+	topicId iggcon.Identifier,
+	consumer iggcon.Consumer,
+	strategy iggcon.PollingStrategy,
+	count uint32,
+	autoCommit bool,
+	partitionId *uint32,
+	maxWait time.Duration,
+) (*iggcon.PolledMessage, error) {
+	return PollMessagesWithContext(ctx, c.route(streamId), streamId, topicId, consumer, strategy, count, autoCommit, partitionId, maxWait)
+}
+
+func (c *streamRoutedClient) StoreConsumerOffset(
+	consumer iggcon.Consumer,
+	streamId iggcon.Identifier,
+	topicId iggcon.Identifier,
+	offset uint64,
+	partitionId *uint32,
+) error {
+	return c.route(streamId).StoreConsumerOffset(consumer, streamId, topicId, offset, partitionId)
+}
+
+func (c *streamRoutedClient) GetConsumerOffset(
+	consumer iggcon.Consumer,
+	streamId iggcon.Identifier,
+	topicId iggcon.Identifier,
+	partitionId *uint32,
+) (*iggcon.ConsumerOffsetInfo, error) {
+	return c.route(streamId).GetConsumerOffset(consumer, streamId, topicId, partitionId)
+}
+
+func (c *streamRoutedClient) DeleteConsumerOffset(
+	consumer iggcon.Consumer,
+	streamId iggcon.Identifier,
+	topicId iggcon.Identifier,
+	partitionId *uint32,
+) error {
+	return c.route(streamId).DeleteConsumerOffset(consumer, streamId, topicId, partitionId)
+}
@@ -0,0 +1,67 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package messengercli_test
+
+import (
+	"testing"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengercli"
+	"github.com/apache/messenger/foreign/go/messengertest"
+)
+
+func TestRouteStreams(t *testing.T) {
+	fallback := messengertest.NewClient()
+	tenant := messengertest.NewClient()
+	client := messengercli.RouteStreams(fallback, messengercli.StreamRoute{
+		Streams: []iggcon.Identifier{iggcon.MustIdentifier("tenant-a")},
+		Client:  tenant,
+	})
+
+	for _, name := range []string{"tenant-a", "shared"} {
+		if _, err := client.CreateStream(name, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	streamId := iggcon.MustIdentifier("tenant-a")
+	if _, err := client.CreateTopic(streamId, "events", 1, iggcon.CompressionAlgorithmNone, 0, 0, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	message, _ := iggcon.NewMessengerMessage([]byte("hello"))
+	if err := client.SendMessages(streamId, iggcon.MustIdentifier("events"), iggcon.None(), []iggcon.MessengerMessage{message}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := tenant.GetTopic(streamId, iggcon.MustIdentifier("events")); err != nil {
+		t.Fatalf("expected the topic of the routed stream to be created by its client: %v", err)
+	}
+	if _, err := fallback.GetStream(streamId); err == nil {
+		t.Fatal("expected the routed stream not to be created by the fallback client")
+	}
+	if _, err := fallback.GetStream(iggcon.MustIdentifier("shared")); err != nil {
+		t.Fatalf("expected the other streams to be created by the fallback client: %v", err)
+	}
+	polled, err := client.PollMessages(streamId, iggcon.MustIdentifier("events"), iggcon.DefaultConsumer(), iggcon.FirstPollingStrategy(), 10, false, nil)
+	if err != nil || len(polled.Messages) != 1 {
+		t.Fatalf("expected the message to be polled through the client of the stream, got %v, %v", polled, err)
+	}
+	streams, err := client.GetStreams()
+	if err != nil || len(streams) != 1 {
+		t.Fatalf("expected the streams to be listed by the fallback client, got %v, %v", streams, err)
+	}
+}
@@ -0,0 +1,122 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package messengercli
+
+import (
+	"context"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+)
+
+const (
+	// tailBatchSize is the number of messages Tail polls at once when following a partition.
+	tailBatchSize = 100
+	// tailMaxWait is how long the server holds the polls of Tail following a partition.
+	tailMaxWait = time.Second
+	// tailPollInterval is how long Tail waits before polling again after an empty poll, for the
+	// servers without long polling.
+	tailPollInterval = 100 * time.Millisecond
+)
+
+// Tail streams the last n messages of a partition over the returned channel and, with follow,
+// the messages appended to it afterwards until ctx is done. The messages are polled without
+// storing any offset. The message channel is closed when the tail ends, after the error that
+// ended it, if any, was sent over the error channel; the end of ctx is not reported as an error.
+func Tail(
+	ctx context.Context,
+	client DataClient,
+	streamId iggcon.Identifier,
+	topicId iggcon.Identifier,
+	partitionId uint32,
+	n uint32,
+	follow bool,
+) (<-chan iggcon.ReceivedMessage, <-chan error) {
+	messages := make(chan iggcon.ReceivedMessage)
+	errs := make(chan error, 1)
+	go func() {
+		defer close(messages)
+		defer close(errs)
+		if err := tail(ctx, client, streamId, topicId, partitionId, n, follow, messages); err != nil && ctx.Err() == nil {
+			errs <- err
+		}
+	}()
+	return messages, errs
+}
+
+func tail(
+	ctx context.Context,
+	client DataClient,
+	streamId iggcon.Identifier,
+	topicId iggcon.Identifier,
+	partitionId uint32,
+	n uint32,
+	follow bool,
+	messages chan<- iggcon.ReceivedMessage,
+) error {
+	consumer := iggcon.DefaultConsumer()
+	// The last message is polled even when n is 0, to know where to follow the partition from.
+	polled, err := client.PollMessages(streamId, topicId, consumer, iggcon.LastPollingStrategy(), max(n, 1), false, &partitionId)
+	if err != nil {
+		return err
+	}
+	next := uint64(0)
+	if len(polled.Messages) > 0 {
+		next = polled.Messages[len(polled.Messages)-1].Header.Offset + 1
+		if n > 0 {
+			if err := sendReceived(ctx, polled, messages); err != nil {
+				return err
+			}
+		}
+	}
+
+	for follow {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		polled, err := client.PollMessagesWithWait(streamId, topicId, consumer, iggcon.OffsetPollingStrategy(next), tailBatchSize, false, &partitionId, tailMaxWait)
+		if err != nil {
+			return err
+		}
+		if polled == nil || len(polled.Messages) == 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(tailPollInterval):
+			}
+			continue
+		}
+		if err := sendReceived(ctx, polled, messages); err != nil {
+			return err
+		}
+		next = polled.Messages[len(polled.Messages)-1].Header.Offset + 1
+	}
+	return nil
+}
+
+func sendReceived(ctx context.Context, polled *iggcon.PolledMessage, messages chan<- iggcon.ReceivedMessage) error {
+	for _, message := range polled.Messages {
+		received := iggcon.ReceivedMessage{Message: message, CurrentOffset: polled.CurrentOffset, PartitionId: polled.PartitionId}
+		select {
+		case messages <- received:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package messengercli_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengercli"
+	"github.com/apache/messenger/foreign/go/messengertest"
+)
+
+func TestTail(t *testing.T) {
+	client := messengertest.NewClient()
+	if _, err := client.CreateStream("logs", nil); err != nil {
+		t.Fatal(err)
+	}
+	streamId := iggcon.MustIdentifier("logs")
+	if _, err := client.CreateTopic(streamId, "app", 1, iggcon.CompressionAlgorithmNone, 0, 0, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	topicId := iggcon.MustIdentifier("app")
+	send := func(from, to int) {
+		var messages []iggcon.MessengerMessage
+		for i := from; i < to; i++ {
+			message, _ := iggcon.NewMessengerMessage([]byte(fmt.Sprintf("line %d", i)))
+			messages = append(messages, message)
+		}
+		if err := client.SendMessages(streamId, topicId, iggcon.PartitionId(1), messages); err != nil {
+			t.Error(err)
+		}
+	}
+	send(0, 5)
+
+	var lines []string
+	messages, errs := messengercli.Tail(context.Background(), client, streamId, topicId, 1, 3, false)
+	for message := range messages {
+		lines = append(lines, string(message.Message.Payload))
+	}
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(lines) != "[line 2 line 3 line 4]" {
+		t.Fatalf("expected the last 3 lines, got %v", lines)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	messages, errs = messengercli.Tail(ctx, client, streamId, topicId, 1, 1, true)
+	lines = nil
+	for message := range messages {
+		lines = append(lines, string(message.Message.Payload))
+		if len(lines) == 1 {
+			go send(5, 7)
+		}
+		if len(lines) == 3 {
+			cancel()
+		}
+	}
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(lines) != "[line 4 line 5 line 6]" {
+		t.Fatalf("expected the last line followed by the new ones, got %v", lines)
+	}
+}
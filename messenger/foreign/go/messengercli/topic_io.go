@@ -0,0 +1,221 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package messengercli
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+)
+
+const (
+	// DefaultChunkSize is the size of the chunks a TopicWriter of chunk size 0 writes.
+	DefaultChunkSize = 64 * 1024
+	// topicWriterBatchSize is the number of chunks a TopicWriter sends at once.
+	topicWriterBatchSize = 100
+	// topicReaderBatchSize is the number of chunks a TopicReader polls at once.
+	topicReaderBatchSize = 100
+	// topicReaderMaxWait is how long the server holds the polls of a TopicReader following a
+	// partition.
+	topicReaderMaxWait = time.Second
+	// topicReaderPollInterval is how long a TopicReader following a partition waits before
+	// polling again after an empty poll, for the servers without long polling.
+	topicReaderPollInterval = 100 * time.Millisecond
+)
+
+// ErrMissingChunks is returned by a TopicReader when chunks of the partition are missing between
+// the ones it read, like when they expired before they were read.
+var ErrMissingChunks = errors.New("messengercli: chunks of the partition are missing")
+
+// TopicWriter is an io.Writer appending the data written to it to a partition, in chunks of at
+// most the chunk size sent as a message each. Every Write sends its data right away, the small
+// writes should be buffered with a bufio.Writer. The chunks are appended to a single partition
+// to be read back in order by a TopicReader.
+type TopicWriter struct {
+	client      DataClient
+	streamId    iggcon.Identifier
+	topicId     iggcon.Identifier
+	partitionId uint32
+	chunkSize   int
+}
+
+// NewTopicWriter creates a TopicWriter appending to the partition in chunks of chunkSize bytes,
+// or DefaultChunkSize when chunkSize is 0.
+func NewTopicWriter(
+	client DataClient,
+	streamId iggcon.Identifier,
+	topicId iggcon.Identifier,
+	partitionId uint32,
+	chunkSize int,
+) *TopicWriter {
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+	chunkSize = min(chunkSize, iggcon.MaxPayloadSize)
+	return &TopicWriter{client: client, streamId: streamId, topicId: topicId, partitionId: partitionId, chunkSize: chunkSize}
+}
+
+// Write appends p to the partition. When sending the chunks fails, the bytes of the chunks sent
+// before are reported as written.
+func (w *TopicWriter) Write(p []byte) (int, error) {
+	written := 0
+	for written < len(p) {
+		batch := make([]iggcon.MessengerMessage, 0, topicWriterBatchSize)
+		size := 0
+		for len(batch) < topicWriterBatchSize && written+size < len(p) {
+			end := min(written+size+w.chunkSize, len(p))
+			// The chunk is copied, the messages may be sent after Write returned by the clients
+			// batching the sends.
+			chunk := append([]byte(nil), p[written+size:end]...)
+			message, err := iggcon.NewMessengerMessage(chunk)
+			if err != nil {
+				return written, err
+			}
+			batch = append(batch, message)
+			size += len(chunk)
+		}
+		if err := w.client.SendMessages(w.streamId, w.topicId, iggcon.PartitionId(w.partitionId), batch); err != nil {
+			return written, err
+		}
+		written += size
+	}
+	return written, nil
+}
+
+// TopicReader is an io.Reader reading the chunks of a partition in order, from an offset, as a
+// single stream of bytes, like the chunks a TopicWriter appended. The chunks are polled without
+// storing any offset, Offset telling where to resume the reading from.
+type TopicReader struct {
+	ctx         context.Context
+	client      DataClient
+	streamId    iggcon.Identifier
+	topicId     iggcon.Identifier
+	partitionId uint32
+	follow      bool
+
+	// next is the offset of the next chunk to read.
+	next uint64
+	// started is set once a chunk was read, the first chunk read being after the offset the
+	// reader started from when the chunks before expired.
+	started bool
+	// pending are the polled chunks not read yet.
+	pending []iggcon.MessengerMessage
+	// chunk is the rest of the chunk being read.
+	chunk []byte
+	err   error
+}
+
+// NewTopicReader creates a TopicReader reading the partition from the offset. Without follow, the
+// reader returns io.EOF once it read the last chunk of the partition; with follow, it waits for
+// the chunks appended afterwards until ctx is done, then returns the error of ctx.
+func NewTopicReader(
+	ctx context.Context,
+	client DataClient,
+	streamId iggcon.Identifier,
+	topicId iggcon.Identifier,
+	partitionId uint32,
+	offset uint64,
+	follow bool,
+) *TopicReader {
+	return &TopicReader{
+		ctx:         ctx,
+		client:      client,
+		streamId:    streamId,
+		topicId:     topicId,
+		partitionId: partitionId,
+		follow:      follow,
+		next:        offset,
+	}
+}
+
+// Read reads the next bytes of the partition into p.
+func (r *TopicReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	for len(r.chunk) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		if len(r.pending) == 0 {
+			r.err = r.poll()
+			continue
+		}
+		message := r.pending[0]
+		r.pending = r.pending[1:]
+		offset := message.Header.Offset
+		if offset < r.next {
+			continue
+		}
+		if r.started && offset > r.next {
+			r.err = ErrMissingChunks
+			continue
+		}
+		r.chunk = message.Payload
+		r.next = offset + 1
+		r.started = true
+	}
+	n := copy(p, r.chunk)
+	r.chunk = r.chunk[n:]
+	return n, nil
+}
+
+// Offset returns the offset of the chunk being read, or of the next chunk to read when none is,
+// which is the offset to create a TopicReader from to resume the reading.
+func (r *TopicReader) Offset() uint64 {
+	if len(r.chunk) > 0 {
+		return r.next - 1
+	}
+	return r.next
+}
+
+func (r *TopicReader) poll() error {
+	for {
+		if err := r.ctx.Err(); err != nil {
+			return err
+		}
+		polled, err := r.client.PollMessagesWithWait(
+			r.streamId,
+			r.topicId,
+			iggcon.DefaultConsumer(),
+			iggcon.OffsetPollingStrategy(r.next),
+			topicReaderBatchSize,
+			false,
+			&r.partitionId,
+			topicReaderMaxWait,
+		)
+		if err != nil {
+			return err
+		}
+		if polled != nil && len(polled.Messages) > 0 {
+			r.pending = polled.Messages
+			return nil
+		}
+		if !r.follow {
+			return io.EOF
+		}
+		select {
+		case <-r.ctx.Done():
+			return r.ctx.Err()
+		case <-time.After(topicReaderPollInterval):
+		}
+	}
+}
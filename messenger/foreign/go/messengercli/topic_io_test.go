@@ -0,0 +1,80 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package messengercli_test
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengercli"
+	"github.com/apache/messenger/foreign/go/messengertest"
+)
+
+func TestTopicWriterAndReader(t *testing.T) {
+	client := messengertest.NewClient()
+	if _, err := client.CreateStream("logs", nil); err != nil {
+		t.Fatal(err)
+	}
+	streamId := iggcon.MustIdentifier("logs")
+	if _, err := client.CreateTopic(streamId, "app", 1, iggcon.CompressionAlgorithmNone, 0, 0, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	topicId := iggcon.MustIdentifier("app")
+
+	var lines strings.Builder
+	for i := 0; i < 50; i++ {
+		fmt.Fprintf(&lines, "line %d\n", i)
+	}
+	writer := messengercli.NewTopicWriter(client, streamId, topicId, 1, 16)
+	n, err := io.Copy(writer, strings.NewReader(lines.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if int(n) != lines.Len() {
+		t.Fatalf("expected %d bytes written, got %d", lines.Len(), n)
+	}
+
+	reader := messengercli.NewTopicReader(context.Background(), client, streamId, topicId, 1, 0, false)
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != lines.String() {
+		t.Fatalf("expected the written lines, got %q", data)
+	}
+	if chunks := uint64((lines.Len() + 15) / 16); reader.Offset() != chunks {
+		t.Fatalf("expected to resume from offset %d, got %d", chunks, reader.Offset())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	scanner := bufio.NewScanner(messengercli.NewTopicReader(ctx, client, streamId, topicId, 1, reader.Offset(), true))
+	go fmt.Fprintln(writer, "line 50")
+	if !scanner.Scan() {
+		t.Fatalf("expected the line written afterwards, got %v", scanner.Err())
+	}
+	if scanner.Text() != "line 50" {
+		t.Fatalf("expected line 50, got %q", scanner.Text())
+	}
+}
@@ -0,0 +1,109 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package messengercli
+
+import (
+	"errors"
+
+	"github.com/apache/messenger/foreign/go/tcp"
+)
+
+// ProtocolStats returns the per-command statistics recorded by the transport of a client created
+// by NewMessengerClient, NewAdminClient or NewDataClient, and false for the other clients.
+func ProtocolStats(client any) (tcp.ProtocolStats, bool) {
+	transport, ok := tcpTransport(client)
+	if !ok {
+		return tcp.ProtocolStats{}, false
+	}
+	return transport.ProtocolStats(), true
+}
+
+// ConnectionEvents returns the connection events of the transport of a client created by
+// NewMessengerClient, NewAdminClient or NewDataClient, and false for the other clients.
+func ConnectionEvents(client any) (<-chan tcp.ConnectionEvent, bool) {
+	transport, ok := tcpTransport(client)
+	if !ok {
+		return nil, false
+	}
+	return transport.ConnectionEvents(), true
+}
+
+// Close tears down the connections of a client created by NewMessengerClient, NewAdminClient or
+// NewDataClient, and of the clients of the routes of RouteStreams. It does nothing for the other
+// clients.
+func Close(client any) error {
+	if routed, ok := unwrapRouted(client); ok {
+		errs := []error{Close(routed.Client)}
+		for _, route := range routed.clients {
+			errs = append(errs, Close(route))
+		}
+		return errors.Join(errs...)
+	}
+	transport, ok := tcpTransport(client)
+	if !ok {
+		return nil
+	}
+	return transport.Close()
+}
+
+// tcpTransport unwraps the TCP client of a client created by NewMessengerClient, NewAdminClient
+// or NewDataClient.
+func tcpTransport(client any) (*tcp.MessengerTcpClient, bool) {
+	for {
+		switch c := client.(type) {
+		case *tcp.MessengerTcpClient:
+			return c, true
+		case *interceptedClient:
+			client = c.Client
+		case *auditedClient:
+			client = c.Client
+		case *metadataCachedClient:
+			client = c.Client
+		case *streamRoutedClient:
+			client = c.Client
+		case adminClient:
+			client = c.AdminClient
+		case dataClient:
+			client = c.DataClient
+		default:
+			return nil, false
+		}
+	}
+}
+
+// unwrapRouted unwraps the client created by RouteStreams of a client.
+func unwrapRouted(client any) (*streamRoutedClient, bool) {
+	for {
+		switch c := client.(type) {
+		case *streamRoutedClient:
+			return c, true
+		case *interceptedClient:
+			client = c.Client
+		case *auditedClient:
+			client = c.Client
+		case *metadataCachedClient:
+			client = c.Client
+		case adminClient:
+			client = c.AdminClient
+		case dataClient:
+			client = c.DataClient
+		default:
+			return nil, false
+		}
+	}
+}
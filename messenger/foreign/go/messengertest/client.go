@@ -0,0 +1,548 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package messengertest provides an in-memory implementation of messengercli.Client, so
+// applications can unit test their producers, consumers and administration code without a
+// running server.
+package messengertest
+
+import (
+	"sort"
+	"sync"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	ierror "github.com/apache/messenger/foreign/go/errors"
+	"github.com/apache/messenger/foreign/go/messengercli"
+)
+
+var _ messengercli.Client = (*Client)(nil)
+
+type Options struct {
+	// Clock provides the timestamps of the messages and resources, defaults to iggcon.SystemClock.
+	Clock iggcon.Clock
+	// Faults are consulted, in order, before every call.
+	Faults []Fault
+	// Username and Password are the credentials of the root user, messenger/messenger by default.
+	Username string
+	Password string
+	// Dialect is the protocol dialect spoken by the stub Server, iggcon.MessengerDialect by default.
+	Dialect *iggcon.Dialect
+}
+
+func GetDefaultOptions() Options {
+	return Options{
+		Clock:    iggcon.SystemClock,
+		Username: "messenger",
+		Password: "messenger",
+		Dialect:  iggcon.MessengerDialect,
+	}
+}
+
+type Option func(*Options)
+
+// WithClock sets the clock providing the timestamps of the messages and resources.
+func WithClock(clock iggcon.Clock) Option {
+	return func(opts *Options) {
+		opts.Clock = clock
+	}
+}
+
+// WithFaults appends faults consulted before every call.
+func WithFaults(faults ...Fault) Option {
+	return func(opts *Options) {
+		opts.Faults = append(opts.Faults, faults...)
+	}
+}
+
+// WithDialect sets the Messenger dialect spoken by the stub Server, which answers the commands of
+// the extensions it lacks with an invalid_command error and leaves the labels out of the client info.
+func WithDialect(dialect *iggcon.Dialect) Option {
+	return func(opts *Options) {
+		opts.Dialect = dialect
+	}
+}
+
+// WithRootUser sets the credentials of the root user.
+func WithRootUser(username, password string) Option {
+	return func(opts *Options) {
+		opts.Username = username
+		opts.Password = password
+	}
+}
+
+// Client is an in-memory messengercli.Client. It keeps the streams, topics, partitions, messages,
+// consumer groups, offsets and users like the server does, without enforcing permissions.
+// It is safe for concurrent use.
+type Client struct {
+	clock iggcon.Clock
+
+	mtx          sync.Mutex
+	faults       []Fault
+	streams      map[uint32]*stream
+	lastStreamId uint32
+	users        map[uint32]*user
+	lastUserId   uint32
+	tokens       map[string]*token
+	// userId is the ID of the logged-in user, 0 when logged out.
+	userId uint32
+	// labels are the labels attached to the client through the stub server.
+	labels map[string]string
+	// appended is closed and replaced whenever messages are appended, to wake up the long polls.
+	appended chan struct{}
+}
+
+// NewClient creates an empty in-memory client, with a single root user.
+func NewClient(options ...Option) *Client {
+	opts := GetDefaultOptions()
+	for _, opt := range options {
+		if opt != nil {
+			opt(&opts)
+		}
+	}
+	c := &Client{
+		clock:    opts.Clock,
+		faults:   opts.Faults,
+		streams:  map[uint32]*stream{},
+		users:    map[uint32]*user{},
+		tokens:   map[string]*token{},
+		appended: make(chan struct{}),
+	}
+	c.lastUserId = 1
+	c.users[1] = &user{
+		info: iggcon.UserInfoDetails{
+			UserInfo: iggcon.UserInfo{Id: 1, CreatedAt: c.now(), Status: iggcon.Active, Username: opts.Username},
+		},
+		password: opts.Password,
+	}
+	return c
+}
+
+// InjectFault appends a fault consulted before every following call.
+func (c *Client) InjectFault(fault Fault) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.faults = append(c.faults, fault)
+}
+
+// ClearFaults removes all the faults.
+func (c *Client) ClearFaults() {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.faults = nil
+}
+
+// begin locks the client, unless the method fails because of a fault. The caller unlocks the
+// client once begin succeeded.
+func (c *Client) begin(method string) error {
+	c.mtx.Lock()
+	for _, fault := range c.faults {
+		if err := fault(method); err != nil {
+			c.mtx.Unlock()
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Client) now() uint64 {
+	return uint64(c.clock.Now().UnixMicro())
+}
+
+type stream struct {
+	details     iggcon.Stream
+	topics      map[uint32]*topic
+	lastTopicId uint32
+}
+
+type topic struct {
+	details    iggcon.Topic
+	partitions []*partition
+	groups     map[uint32]*group
+	lastGroup  uint32
+	// offsets are the offsets stored by the single consumers.
+	offsets map[offsetKey]uint64
+	// balanced is the partition receiving the next balanced batch, starting at 0.
+	balanced int
+}
+
+type partition struct {
+	details  iggcon.PartitionContract
+	messages []iggcon.MessengerMessage
+}
+
+type offsetKey struct {
+	consumer    string
+	partitionId uint32
+}
+
+func (c *Client) stream(id iggcon.Identifier) (*stream, error) {
+	if value, err := id.Uint32(); err == nil {
+		if s, ok := c.streams[value]; ok {
+			return s, nil
+		}
+		return nil, ierror.StreamIdNotFound
+	}
+	name, _ := id.String()
+	for _, s := range c.streams {
+		if s.details.Name == name {
+			return s, nil
+		}
+	}
+	return nil, ierror.MapFromCode(1010)
+}
+
+func (c *Client) topic(streamId, topicId iggcon.Identifier) (*stream, *topic, error) {
+	s, err := c.stream(streamId)
+	if err != nil {
+		return nil, nil, err
+	}
+	if value, err := topicId.Uint32(); err == nil {
+		if t, ok := s.topics[value]; ok {
+			return s, t, nil
+		}
+		return nil, nil, ierror.TopicIdNotFound
+	}
+	name, _ := topicId.String()
+	for _, t := range s.topics {
+		if t.details.Name == name {
+			return s, t, nil
+		}
+	}
+	return nil, nil, ierror.MapFromCode(2011)
+}
+
+func (t *topic) partition(id uint32) (*partition, error) {
+	if id == 0 || int(id) > len(t.partitions) {
+		return nil, ierror.MapFromCode(3007)
+	}
+	return t.partitions[id-1], nil
+}
+
+func (t *topic) addPartitions(count uint32, createdAt uint64) {
+	for i := uint32(0); i < count; i++ {
+		t.partitions = append(t.partitions, &partition{details: iggcon.PartitionContract{
+			Id:            uint32(len(t.partitions)) + 1,
+			CreatedAt:     createdAt,
+			SegmentsCount: 1,
+		}})
+	}
+	t.details.PartitionsCount = uint32(len(t.partitions))
+}
+
+func (s *stream) snapshot() iggcon.Stream {
+	details := s.details
+	details.TopicsCount = uint32(len(s.topics))
+	for _, t := range s.topics {
+		topic := t.snapshot()
+		details.MessagesCount += topic.MessagesCount
+		details.SizeBytes += topic.Size
+	}
+	return details
+}
+
+func (t *topic) snapshot() iggcon.Topic {
+	details := t.details
+	for _, p := range t.partitions {
+		partition := p.snapshot()
+		details.MessagesCount += partition.MessagesCount
+		details.Size += partition.SizeBytes
+	}
+	return details
+}
+
+func (p *partition) snapshot() iggcon.PartitionContract {
+	details := p.details
+	details.MessagesCount = uint64(len(p.messages))
+	details.SizeBytes = 0
+	for _, message := range p.messages {
+		details.SizeBytes += uint64(iggcon.MessageHeaderSize + len(message.Payload) + len(message.UserHeaders))
+	}
+	if len(p.messages) > 0 {
+		details.CurrentOffset = uint64(len(p.messages) - 1)
+	}
+	return details
+}
+
+func (c *Client) GetStream(streamId iggcon.Identifier) (*iggcon.StreamDetails, error) {
+	if err := c.begin("GetStream"); err != nil {
+		return nil, err
+	}
+	defer c.mtx.Unlock()
+	s, err := c.stream(streamId)
+	if err != nil {
+		return nil, err
+	}
+	return s.detailsSnapshot(), nil
+}
+
+func (s *stream) detailsSnapshot() *iggcon.StreamDetails {
+	details := &iggcon.StreamDetails{Stream: s.snapshot()}
+	for _, id := range sortedKeys(s.topics) {
+		details.Topics = append(details.Topics, s.topics[id].snapshot())
+	}
+	return details
+}
+
+func (c *Client) GetStreams() ([]iggcon.Stream, error) {
+	if err := c.begin("GetStreams"); err != nil {
+		return nil, err
+	}
+	defer c.mtx.Unlock()
+	streams := make([]iggcon.Stream, 0, len(c.streams))
+	for _, id := range sortedKeys(c.streams) {
+		streams = append(streams, c.streams[id].snapshot())
+	}
+	return streams, nil
+}
+
+func (c *Client) CreateStream(name string, streamId *uint32) (*iggcon.StreamDetails, error) {
+	if err := c.begin("CreateStream"); err != nil {
+		return nil, err
+	}
+	defer c.mtx.Unlock()
+	if _, err := iggcon.NewIdentifier(name); err != nil {
+		return nil, err
+	}
+	for _, s := range c.streams {
+		if s.details.Name == name {
+			return nil, ierror.MapFromCode(1012)
+		}
+	}
+	id := c.lastStreamId + 1
+	if streamId != nil && *streamId != 0 {
+		id = *streamId
+	}
+	if _, ok := c.streams[id]; ok {
+		return nil, ierror.MapFromCode(1011)
+	}
+	c.lastStreamId = max(c.lastStreamId, id)
+
+	s := &stream{
+		details: iggcon.Stream{Id: id, Name: name, CreatedAt: c.now()},
+		topics:  map[uint32]*topic{},
+	}
+	c.streams[id] = s
+	return s.detailsSnapshot(), nil
+}
+
+func (c *Client) UpdateStream(streamId iggcon.Identifier, name string) error {
+	if err := c.begin("UpdateStream"); err != nil {
+		return err
+	}
+	defer c.mtx.Unlock()
+	s, err := c.stream(streamId)
+	if err != nil {
+		return err
+	}
+	for _, other := range c.streams {
+		if other != s && other.details.Name == name {
+			return ierror.MapFromCode(1012)
+		}
+	}
+	s.details.Name = name
+	return nil
+}
+
+func (c *Client) DeleteStream(streamId iggcon.Identifier) error {
+	if err := c.begin("DeleteStream"); err != nil {
+		return err
+	}
+	defer c.mtx.Unlock()
+	s, err := c.stream(streamId)
+	if err != nil {
+		return err
+	}
+	delete(c.streams, s.details.Id)
+	return nil
+}
+
+func (c *Client) GetTopic(streamId, topicId iggcon.Identifier) (*iggcon.TopicDetails, error) {
+	if err := c.begin("GetTopic"); err != nil {
+		return nil, err
+	}
+	defer c.mtx.Unlock()
+	_, t, err := c.topic(streamId, topicId)
+	if err != nil {
+		return nil, err
+	}
+	return t.detailsSnapshot(), nil
+}
+
+func (t *topic) detailsSnapshot() *iggcon.TopicDetails {
+	details := &iggcon.TopicDetails{Topic: t.snapshot()}
+	for _, p := range t.partitions {
+		details.Partitions = append(details.Partitions, p.snapshot())
+	}
+	return details
+}
+
+func (c *Client) GetTopics(streamId iggcon.Identifier) ([]iggcon.Topic, error) {
+	if err := c.begin("GetTopics"); err != nil {
+		return nil, err
+	}
+	defer c.mtx.Unlock()
+	s, err := c.stream(streamId)
+	if err != nil {
+		return nil, err
+	}
+	topics := make([]iggcon.Topic, 0, len(s.topics))
+	for _, id := range sortedKeys(s.topics) {
+		topics = append(topics, s.topics[id].snapshot())
+	}
+	return topics, nil
+}
+
+func (c *Client) CreateTopic(
+	streamId iggcon.Identifier,
+	name string,
+	partitionsCount uint32,
+	compressionAlgorithm iggcon.CompressionAlgorithm,
+	messageExpiry iggcon.Expiry,
+	maxTopicSize iggcon.MaxTopicSize,
+	replicationFactor *uint8,
+	topicId *uint32,
+) (*iggcon.TopicDetails, error) {
+	if err := c.begin("CreateTopic"); err != nil {
+		return nil, err
+	}
+	defer c.mtx.Unlock()
+	s, err := c.stream(streamId)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = iggcon.NewIdentifier(name); err != nil {
+		return nil, err
+	}
+	for _, t := range s.topics {
+		if t.details.Name == name {
+			return nil, ierror.MapFromCode(2013)
+		}
+	}
+	id := s.lastTopicId + 1
+	if topicId != nil && *topicId != 0 {
+		id = *topicId
+	}
+	if _, ok := s.topics[id]; ok {
+		return nil, ierror.MapFromCode(2012)
+	}
+	s.lastTopicId = max(s.lastTopicId, id)
+
+	t := &topic{
+		details: iggcon.Topic{
+			Id:                   id,
+			CreatedAt:            c.now(),
+			Name:                 name,
+			MessageExpiry:        messageExpiry,
+			CompressionAlgorithm: uint8(compressionAlgorithm),
+			MaxTopicSize:         maxTopicSize,
+			ReplicationFactor:    1,
+		},
+		groups:  map[uint32]*group{},
+		offsets: map[offsetKey]uint64{},
+	}
+	if replicationFactor != nil {
+		t.details.ReplicationFactor = *replicationFactor
+	}
+	t.addPartitions(partitionsCount, t.details.CreatedAt)
+	s.topics[id] = t
+	return t.detailsSnapshot(), nil
+}
+
+func (c *Client) UpdateTopic(
+	streamId iggcon.Identifier,
+	topicId iggcon.Identifier,
+	name string,
+	compressionAlgorithm iggcon.CompressionAlgorithm,
+	messageExpiry iggcon.Expiry,
+	maxTopicSize iggcon.MaxTopicSize,
+	replicationFactor *uint8,
+) error {
+	if err := c.begin("UpdateTopic"); err != nil {
+		return err
+	}
+	defer c.mtx.Unlock()
+	s, t, err := c.topic(streamId, topicId)
+	if err != nil {
+		return err
+	}
+	for _, other := range s.topics {
+		if other != t && other.details.Name == name {
+			return ierror.MapFromCode(2013)
+		}
+	}
+	t.details.Name = name
+	t.details.CompressionAlgorithm = uint8(compressionAlgorithm)
+	t.details.MessageExpiry = messageExpiry
+	t.details.MaxTopicSize = maxTopicSize
+	if replicationFactor != nil {
+		t.details.ReplicationFactor = *replicationFactor
+	}
+	return nil
+}
+
+func (c *Client) DeleteTopic(streamId, topicId iggcon.Identifier) error {
+	if err := c.begin("DeleteTopic"); err != nil {
+		return err
+	}
+	defer c.mtx.Unlock()
+	s, t, err := c.topic(streamId, topicId)
+	if err != nil {
+		return err
+	}
+	delete(s.topics, t.details.Id)
+	return nil
+}
+
+func (c *Client) CreatePartitions(streamId, topicId iggcon.Identifier, partitionsCount uint32) error {
+	if err := c.begin("CreatePartitions"); err != nil {
+		return err
+	}
+	defer c.mtx.Unlock()
+	_, t, err := c.topic(streamId, topicId)
+	if err != nil {
+		return err
+	}
+	t.addPartitions(partitionsCount, c.now())
+	return nil
+}
+
+func (c *Client) DeletePartitions(streamId, topicId iggcon.Identifier, partitionsCount uint32) error {
+	if err := c.begin("DeletePartitions"); err != nil {
+		return err
+	}
+	defer c.mtx.Unlock()
+	_, t, err := c.topic(streamId, topicId)
+	if err != nil {
+		return err
+	}
+	if int(partitionsCount) > len(t.partitions) {
+		return ierror.MapFromCode(3008)
+	}
+	t.partitions = t.partitions[:len(t.partitions)-int(partitionsCount)]
+	t.details.PartitionsCount = uint32(len(t.partitions))
+	t.balanced = 0
+	return nil
+}
+
+func sortedKeys[V any](m map[uint32]V) []uint32 {
+	keys := make([]uint32, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
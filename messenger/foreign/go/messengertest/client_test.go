@@ -0,0 +1,186 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package messengertest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	ierror "github.com/apache/messenger/foreign/go/errors"
+)
+
+func newTestTopic(t *testing.T, client *Client, partitions uint32) (iggcon.Identifier, iggcon.Identifier) {
+	if _, err := client.CreateStream("orders", nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.CreateTopic(mustIdentifier(t, "orders"), "created", partitions, iggcon.CompressionAlgorithm(1), 0, 0, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	return mustIdentifier(t, "orders"), mustIdentifier(t, "created")
+}
+
+func mustIdentifier(t *testing.T, name string) iggcon.Identifier {
+	id, err := iggcon.NewIdentifier(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+func newMessages(t *testing.T, payloads ...string) []iggcon.MessengerMessage {
+	messages := make([]iggcon.MessengerMessage, len(payloads))
+	for i, payload := range payloads {
+		message, err := iggcon.NewMessengerMessage([]byte(payload))
+		if err != nil {
+			t.Fatal(err)
+		}
+		messages[i] = message
+	}
+	return messages
+}
+
+func TestClient_SendAndPoll(t *testing.T) {
+	client := NewClient()
+	streamId, topicId := newTestTopic(t, client, 2)
+	if err := client.SendMessages(streamId, topicId, iggcon.PartitionId(2), newMessages(t, "a", "b", "c")); err != nil {
+		t.Fatal(err)
+	}
+
+	partition := uint32(2)
+	consumer := iggcon.DefaultConsumer()
+	var payloads []string
+	for {
+		polled, err := client.PollMessages(streamId, topicId, consumer, iggcon.NextPollingStrategy(), 2, true, &partition)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(polled.Messages) == 0 {
+			break
+		}
+		for _, message := range polled.Messages {
+			payloads = append(payloads, string(message.Payload))
+		}
+	}
+	if len(payloads) != 3 || payloads[0] != "a" || payloads[2] != "c" {
+		t.Fatalf("unexpected payloads %v", payloads)
+	}
+
+	offset, err := client.GetConsumerOffset(consumer, streamId, topicId, &partition)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if offset == nil || offset.StoredOffset != 2 || offset.CurrentOffset != 2 {
+		t.Fatalf("unexpected offset %+v", offset)
+	}
+
+	topic, err := client.GetTopic(streamId, topicId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if topic.MessagesCount != 3 || topic.Partitions[0].MessagesCount != 0 {
+		t.Fatalf("unexpected topic %+v", topic)
+	}
+}
+
+func TestClient_ConsumerGroup(t *testing.T) {
+	client := NewClient()
+	streamId, topicId := newTestTopic(t, client, 2)
+	if err := client.SendMessages(streamId, topicId, iggcon.None(), newMessages(t, "a")); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.SendMessages(streamId, topicId, iggcon.None(), newMessages(t, "b")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.CreateConsumerGroup(streamId, topicId, "workers", nil); err != nil {
+		t.Fatal(err)
+	}
+	group := iggcon.NewGroupConsumer(mustIdentifier(t, "workers"))
+
+	_, err := client.PollMessages(streamId, topicId, group, iggcon.NextPollingStrategy(), 10, true, nil)
+	if err == nil {
+		t.Fatal("expected polling without joining the group to fail")
+	}
+	if err = client.JoinConsumerGroup(streamId, topicId, mustIdentifier(t, "workers")); err != nil {
+		t.Fatal(err)
+	}
+	polledPartitions := map[uint32]string{}
+	for i := 0; i < 2; i++ {
+		polled, err := client.PollMessages(streamId, topicId, group, iggcon.NextPollingStrategy(), 10, true, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		polledPartitions[polled.PartitionId] = string(polled.Messages[0].Payload)
+	}
+	if polledPartitions[1] != "a" || polledPartitions[2] != "b" {
+		t.Fatalf("expected the group to poll both partitions in turn, got %v", polledPartitions)
+	}
+}
+
+func TestClient_Faults(t *testing.T) {
+	unavailable := errors.New("unavailable")
+	client := NewClient(WithFaults(FailTimes("SendMessages", 2, unavailable)))
+	streamId, topicId := newTestTopic(t, client, 1)
+
+	for i := 0; i < 2; i++ {
+		if err := client.SendMessages(streamId, topicId, iggcon.None(), newMessages(t, "a")); !errors.Is(err, unavailable) {
+			t.Fatalf("expected send %d to fail, got %v", i, err)
+		}
+	}
+	if err := client.SendMessages(streamId, topicId, iggcon.None(), newMessages(t, "a")); err != nil {
+		t.Fatal(err)
+	}
+
+	client.InjectFault(FailAlways("", ierror.MapFromCode(51)))
+	if err := client.Ping(); err == nil {
+		t.Fatal("expected ping to fail")
+	}
+	client.ClearFaults()
+	if err := client.Ping(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestClient_LongPolling(t *testing.T) {
+	client := NewClient()
+	streamId, topicId := newTestTopic(t, client, 1)
+	partition := uint32(1)
+
+	start := time.Now()
+	polled, err := client.PollMessagesWithWait(streamId, topicId, iggcon.DefaultConsumer(), iggcon.NextPollingStrategy(), 10, true, &partition, 50*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(polled.Messages) != 0 || time.Since(start) < 50*time.Millisecond {
+		t.Fatalf("expected the empty poll to wait, got %d messages after %v", len(polled.Messages), time.Since(start))
+	}
+
+	messages := newMessages(t, "a")
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		_ = client.SendMessages(streamId, topicId, iggcon.PartitionId(1), messages)
+	}()
+	polled, err = client.PollMessagesWithWait(streamId, topicId, iggcon.DefaultConsumer(), iggcon.NextPollingStrategy(), 10, true, &partition, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(polled.Messages) != 1 || string(polled.Messages[0].Payload) != "a" {
+		t.Fatalf("expected the poll to wake up with the sent message, got %+v", polled.Messages)
+	}
+}
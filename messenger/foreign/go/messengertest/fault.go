@@ -0,0 +1,72 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package messengertest
+
+import (
+	"math/rand"
+	"sync"
+)
+
+// Fault decides whether a call to the named method of the Client fails, returning the error
+// to fail with, or nil to let the call proceed.
+type Fault func(method string) error
+
+// FailAlways fails every call to method with err, or every call when method is empty.
+func FailAlways(method string, err error) Fault {
+	return func(called string) error {
+		if method == "" || method == called {
+			return err
+		}
+		return nil
+	}
+}
+
+// FailTimes fails the next n calls to method with err, or the next n calls when method is empty.
+func FailTimes(method string, n int, err error) Fault {
+	var mtx sync.Mutex
+	return func(called string) error {
+		if method != "" && method != called {
+			return nil
+		}
+		mtx.Lock()
+		defer mtx.Unlock()
+		if n <= 0 {
+			return nil
+		}
+		n--
+		return err
+	}
+}
+
+// FailRandomly fails calls to method, or to any method when empty, with err and the given
+// probability, drawing from a source seeded with seed so failures are reproducible.
+func FailRandomly(method string, probability float64, seed int64, err error) Fault {
+	var mtx sync.Mutex
+	random := rand.New(rand.NewSource(seed))
+	return func(called string) error {
+		if method != "" && method != called {
+			return nil
+		}
+		mtx.Lock()
+		defer mtx.Unlock()
+		if random.Float64() < probability {
+			return err
+		}
+		return nil
+	}
+}
@@ -0,0 +1,529 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package messengertest
+
+import (
+	"context"
+	"encoding/binary"
+	"hash/fnv"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	ierror "github.com/apache/messenger/foreign/go/errors"
+)
+
+// memberId is the ID the Client joins the consumer groups with.
+const memberId = 1
+
+type group struct {
+	details iggcon.ConsumerGroup
+	joined  bool
+	// next is the partition index polled next by the member without an explicit partition.
+	next int
+}
+
+func (c *Client) SendMessages(
+	streamId iggcon.Identifier,
+	topicId iggcon.Identifier,
+	partitioning iggcon.Partitioning,
+	messages []iggcon.MessengerMessage,
+) error {
+	_, err := c.sendMessages("SendMessages", streamId, topicId, partitioning, messages)
+	return err
+}
+
+// SendMessagesWithConfirmation sends the messages like SendMessages, every confirmation level
+// being reached as soon as the messages are stored in memory.
+func (c *Client) SendMessagesWithConfirmation(
+	streamId iggcon.Identifier,
+	topicId iggcon.Identifier,
+	partitioning iggcon.Partitioning,
+	messages []iggcon.MessengerMessage,
+	_ iggcon.Confirmation,
+) error {
+	_, err := c.sendMessages("SendMessagesWithConfirmation", streamId, topicId, partitioning, messages)
+	return err
+}
+
+// SendMessagesWithResult sends the messages like SendMessagesWithConfirmation, returning the
+// partition, offsets and timestamps they were stored with.
+func (c *Client) SendMessagesWithResult(
+	streamId iggcon.Identifier,
+	topicId iggcon.Identifier,
+	partitioning iggcon.Partitioning,
+	messages []iggcon.MessengerMessage,
+	_ iggcon.Confirmation,
+) (*iggcon.SendResult, error) {
+	return c.sendMessages("SendMessagesWithResult", streamId, topicId, partitioning, messages)
+}
+
+// sendMessages stores the messages, the given method being subject to the faults.
+func (c *Client) sendMessages(method string, streamId, topicId iggcon.Identifier, partitioning iggcon.Partitioning, messages []iggcon.MessengerMessage) (*iggcon.SendResult, error) {
+	if err := c.begin(method); err != nil {
+		return nil, err
+	}
+	defer c.mtx.Unlock()
+	return c.send(streamId, topicId, partitioning, messages)
+}
+
+func (c *Client) send(streamId, topicId iggcon.Identifier, partitioning iggcon.Partitioning, messages []iggcon.MessengerMessage) (*iggcon.SendResult, error) {
+	if len(messages) == 0 {
+		return nil, ierror.InvalidMessagesCount
+	}
+	_, t, err := c.topic(streamId, topicId)
+	if err != nil {
+		return nil, err
+	}
+	if len(t.partitions) == 0 {
+		return nil, ierror.MapFromCode(3008)
+	}
+
+	var p *partition
+	switch partitioning.Kind {
+	case iggcon.PartitionIdKind:
+		if len(partitioning.Value) != 4 {
+			return nil, ierror.MapFromCode(3007)
+		}
+		if p, err = t.partition(binary.LittleEndian.Uint32(partitioning.Value)); err != nil {
+			return nil, err
+		}
+	case iggcon.MessageKey:
+		hash := fnv.New32a()
+		hash.Write(partitioning.Value)
+		p = t.partitions[hash.Sum32()%uint32(len(t.partitions))]
+	default:
+		p = t.partitions[t.balanced%len(t.partitions)]
+		t.balanced++
+	}
+
+	now := c.now()
+	result := &iggcon.SendResult{
+		PartitionId: p.details.Id,
+		BaseOffset:  uint64(len(p.messages)),
+		Timestamps:  make([]uint64, len(messages)),
+	}
+	for i, message := range messages {
+		result.Timestamps[i] = now
+		stored := copyMessage(message)
+		stored.Header.Offset = uint64(len(p.messages))
+		stored.Header.Timestamp = now
+		stored.Header.PayloadLength = uint32(len(stored.Payload))
+		stored.Header.UserHeaderLength = uint32(len(stored.UserHeaders))
+		p.messages = append(p.messages, stored)
+	}
+	close(c.appended)
+	c.appended = make(chan struct{})
+	return result, nil
+}
+
+func (c *Client) PollMessages(
+	streamId iggcon.Identifier,
+	topicId iggcon.Identifier,
+	consumer iggcon.Consumer,
+	strategy iggcon.PollingStrategy,
+	count uint32,
+	autoCommit bool,
+	partitionId *uint32,
+) (*iggcon.PolledMessage, error) {
+	if err := c.begin("PollMessages"); err != nil {
+		return nil, err
+	}
+	defer c.mtx.Unlock()
+	return c.poll(streamId, topicId, consumer, strategy, count, autoCommit, partitionId)
+}
+
+// PollMessagesWithWait polls the messages like PollMessages, waiting up to maxWait, in real time,
+// for messages to be sent when there is none to return.
+func (c *Client) PollMessagesWithWait(
+	streamId iggcon.Identifier,
+	topicId iggcon.Identifier,
+	consumer iggcon.Consumer,
+	strategy iggcon.PollingStrategy,
+	count uint32,
+	autoCommit bool,
+	partitionId *uint32,
+	maxWait time.Duration,
+) (*iggcon.PolledMessage, error) {
+	return c.PollMessagesWithContext(context.Background(), streamId, topicId, consumer, strategy, count, autoCommit, partitionId, maxWait)
+}
+
+// PollMessagesWithContext polls the messages like PollMessagesWithWait, returning the error of
+// ctx as soon as it is done while waiting.
+func (c *Client) PollMessagesWithContext(
+	ctx context.Context,
+	streamId iggcon.Identifier,
+	topicId iggcon.Identifier,
+	consumer iggcon.Consumer,
+	strategy iggcon.PollingStrategy,
+	count uint32,
+	autoCommit bool,
+	partitionId *uint32,
+	maxWait time.Duration,
+) (*iggcon.PolledMessage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := c.begin("PollMessagesWithWait"); err != nil {
+		return nil, err
+	}
+	timer := time.NewTimer(maxWait)
+	defer timer.Stop()
+	for {
+		polled, err := c.poll(streamId, topicId, consumer, strategy, count, autoCommit, partitionId)
+		appended := c.appended
+		c.mtx.Unlock()
+		if err != nil || len(polled.Messages) > 0 || maxWait <= 0 {
+			return polled, err
+		}
+		select {
+		case <-appended:
+			c.mtx.Lock()
+		case <-timer.C:
+			return polled, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (c *Client) poll(
+	streamId iggcon.Identifier,
+	topicId iggcon.Identifier,
+	consumer iggcon.Consumer,
+	strategy iggcon.PollingStrategy,
+	count uint32,
+	autoCommit bool,
+	partitionId *uint32,
+) (*iggcon.PolledMessage, error) {
+	if count == 0 {
+		return nil, ierror.InvalidMessagesCount
+	}
+	_, t, err := c.topic(streamId, topicId)
+	if err != nil {
+		return nil, err
+	}
+	key, p, err := t.consumerPartition(consumer, partitionId, true)
+	if err != nil {
+		return nil, err
+	}
+
+	start := uint64(0)
+	switch strategy.Kind {
+	case iggcon.POLLING_OFFSET:
+		start = strategy.Value
+	case iggcon.POLLING_TIMESTAMP:
+		start = uint64(len(p.messages))
+		for i, message := range p.messages {
+			if message.Header.Timestamp >= strategy.Value {
+				start = uint64(i)
+				break
+			}
+		}
+	case iggcon.POLLING_LAST:
+		if uint64(len(p.messages)) > uint64(count) {
+			start = uint64(len(p.messages)) - uint64(count)
+		}
+	case iggcon.POLLING_NEXT:
+		if stored, ok := t.offsets[key]; ok {
+			start = stored + 1
+		}
+	}
+
+	polled := &iggcon.PolledMessage{PartitionId: p.details.Id}
+	if len(p.messages) > 0 {
+		polled.CurrentOffset = uint64(len(p.messages) - 1)
+	}
+	for offset := start; offset < uint64(len(p.messages)) && len(polled.Messages) < int(count); offset++ {
+		polled.Messages = append(polled.Messages, copyMessage(p.messages[offset]))
+	}
+	polled.MessageCount = uint32(len(polled.Messages))
+	if autoCommit && len(polled.Messages) > 0 {
+		t.offsets[key] = polled.Messages[len(polled.Messages)-1].Header.Offset
+	}
+	return polled, nil
+}
+
+// consumerPartition resolves the partition a consumer reads and the key of its offset in it.
+// Without an explicit partition, a single consumer reads the first partition and a group member
+// its partitions in turn, when next is set, or the first one. As on the server, only the group
+// members without an explicit partition must have joined the group.
+func (t *topic) consumerPartition(consumer iggcon.Consumer, partitionId *uint32, next bool) (offsetKey, *partition, error) {
+	consumerKey := "consumer:" + string(consumer.Id.Value)
+	if consumer.Kind == iggcon.ConsumerKindGroup {
+		g, err := t.group(consumer.Id)
+		if err != nil {
+			return offsetKey{}, nil, err
+		}
+		if !g.joined && partitionId == nil {
+			return offsetKey{}, nil, ierror.MapFromCode(5002)
+		}
+		consumerKey = groupKey(g)
+		if partitionId == nil && len(t.partitions) > 0 {
+			index := 0
+			if next {
+				index = g.next % len(t.partitions)
+				g.next++
+			}
+			p := t.partitions[index]
+			return offsetKey{consumer: consumerKey, partitionId: p.details.Id}, p, nil
+		}
+	}
+
+	id := uint32(1)
+	if partitionId != nil {
+		id = *partitionId
+	}
+	p, err := t.partition(id)
+	if err != nil {
+		return offsetKey{}, nil, err
+	}
+	return offsetKey{consumer: consumerKey, partitionId: id}, p, nil
+}
+
+func groupKey(g *group) string {
+	return "group:" + string(binary.LittleEndian.AppendUint32(nil, g.details.Id))
+}
+
+func (t *topic) group(id iggcon.Identifier) (*group, error) {
+	if value, err := id.Uint32(); err == nil {
+		if g, ok := t.groups[value]; ok {
+			return g, nil
+		}
+		return nil, ierror.ConsumerGroupIdNotFound
+	}
+	name, _ := id.String()
+	for _, g := range t.groups {
+		if g.details.Name == name {
+			return g, nil
+		}
+	}
+	return nil, ierror.ConsumerGroupIdNotFound
+}
+
+func (c *Client) StoreConsumerOffset(
+	consumer iggcon.Consumer,
+	streamId iggcon.Identifier,
+	topicId iggcon.Identifier,
+	offset uint64,
+	partitionId *uint32,
+) error {
+	if err := c.begin("StoreConsumerOffset"); err != nil {
+		return err
+	}
+	defer c.mtx.Unlock()
+	_, t, err := c.topic(streamId, topicId)
+	if err != nil {
+		return err
+	}
+	key, _, err := t.consumerPartition(consumer, partitionId, false)
+	if err != nil {
+		return err
+	}
+	t.offsets[key] = offset
+	return nil
+}
+
+func (c *Client) GetConsumerOffset(
+	consumer iggcon.Consumer,
+	streamId iggcon.Identifier,
+	topicId iggcon.Identifier,
+	partitionId *uint32,
+) (*iggcon.ConsumerOffsetInfo, error) {
+	if err := c.begin("GetConsumerOffset"); err != nil {
+		return nil, err
+	}
+	defer c.mtx.Unlock()
+	_, t, err := c.topic(streamId, topicId)
+	if err != nil {
+		return nil, err
+	}
+	key, p, err := t.consumerPartition(consumer, partitionId, false)
+	if err != nil {
+		return nil, err
+	}
+	stored, ok := t.offsets[key]
+	if !ok {
+		return nil, nil
+	}
+	return &iggcon.ConsumerOffsetInfo{
+		PartitionId:   p.details.Id,
+		CurrentOffset: p.snapshot().CurrentOffset,
+		StoredOffset:  stored,
+	}, nil
+}
+
+func (c *Client) DeleteConsumerOffset(
+	consumer iggcon.Consumer,
+	streamId iggcon.Identifier,
+	topicId iggcon.Identifier,
+	partitionId *uint32,
+) error {
+	if err := c.begin("DeleteConsumerOffset"); err != nil {
+		return err
+	}
+	defer c.mtx.Unlock()
+	_, t, err := c.topic(streamId, topicId)
+	if err != nil {
+		return err
+	}
+	key, _, err := t.consumerPartition(consumer, partitionId, false)
+	if err != nil {
+		return err
+	}
+	if _, ok := t.offsets[key]; !ok {
+		return ierror.ResourceNotFound
+	}
+	delete(t.offsets, key)
+	return nil
+}
+
+func (c *Client) GetConsumerGroups(streamId, topicId iggcon.Identifier) ([]iggcon.ConsumerGroup, error) {
+	if err := c.begin("GetConsumerGroups"); err != nil {
+		return nil, err
+	}
+	defer c.mtx.Unlock()
+	_, t, err := c.topic(streamId, topicId)
+	if err != nil {
+		return nil, err
+	}
+	groups := make([]iggcon.ConsumerGroup, 0, len(t.groups))
+	for _, id := range sortedKeys(t.groups) {
+		groups = append(groups, t.groupDetails(t.groups[id]).ConsumerGroup)
+	}
+	return groups, nil
+}
+
+func (c *Client) GetConsumerGroup(streamId, topicId, groupId iggcon.Identifier) (*iggcon.ConsumerGroupDetails, error) {
+	if err := c.begin("GetConsumerGroup"); err != nil {
+		return nil, err
+	}
+	defer c.mtx.Unlock()
+	_, t, err := c.topic(streamId, topicId)
+	if err != nil {
+		return nil, err
+	}
+	g, err := t.group(groupId)
+	if err != nil {
+		return nil, err
+	}
+	return t.groupDetails(g), nil
+}
+
+func (t *topic) groupDetails(g *group) *iggcon.ConsumerGroupDetails {
+	details := &iggcon.ConsumerGroupDetails{ConsumerGroup: g.details}
+	details.PartitionsCount = uint32(len(t.partitions))
+	if g.joined {
+		member := iggcon.ConsumerGroupMember{ID: memberId, PartitionsCount: uint32(len(t.partitions))}
+		for _, p := range t.partitions {
+			member.Partitions = append(member.Partitions, p.details.Id)
+		}
+		details.MembersCount = 1
+		details.Members = []iggcon.ConsumerGroupMember{member}
+	}
+	return details
+}
+
+func (c *Client) CreateConsumerGroup(streamId, topicId iggcon.Identifier, name string, groupId *uint32) (*iggcon.ConsumerGroupDetails, error) {
+	if err := c.begin("CreateConsumerGroup"); err != nil {
+		return nil, err
+	}
+	defer c.mtx.Unlock()
+	_, t, err := c.topic(streamId, topicId)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = iggcon.NewIdentifier(name); err != nil {
+		return nil, err
+	}
+	for _, g := range t.groups {
+		if g.details.Name == name {
+			// like the server, which fails to create the directory of the group named after it
+			return nil, ierror.MapFromCode(5004)
+		}
+	}
+	id := t.lastGroup + 1
+	if groupId != nil && *groupId != 0 {
+		id = *groupId
+	}
+	if _, ok := t.groups[id]; ok {
+		return nil, ierror.MapFromCode(5001)
+	}
+	t.lastGroup = max(t.lastGroup, id)
+
+	g := &group{details: iggcon.ConsumerGroup{Id: id, Name: name}}
+	t.groups[id] = g
+	return t.groupDetails(g), nil
+}
+
+func (c *Client) DeleteConsumerGroup(streamId, topicId, groupId iggcon.Identifier) error {
+	if err := c.begin("DeleteConsumerGroup"); err != nil {
+		return err
+	}
+	defer c.mtx.Unlock()
+	_, t, err := c.topic(streamId, topicId)
+	if err != nil {
+		return err
+	}
+	g, err := t.group(groupId)
+	if err != nil {
+		return err
+	}
+	delete(t.groups, g.details.Id)
+	for key := range t.offsets {
+		if key.consumer == groupKey(g) {
+			delete(t.offsets, key)
+		}
+	}
+	return nil
+}
+
+func (c *Client) JoinConsumerGroup(streamId, topicId, groupId iggcon.Identifier) error {
+	return c.setGroupMembership("JoinConsumerGroup", streamId, topicId, groupId, true)
+}
+
+func (c *Client) LeaveConsumerGroup(streamId, topicId, groupId iggcon.Identifier) error {
+	return c.setGroupMembership("LeaveConsumerGroup", streamId, topicId, groupId, false)
+}
+
+func (c *Client) setGroupMembership(method string, streamId, topicId, groupId iggcon.Identifier, joined bool) error {
+	if err := c.begin(method); err != nil {
+		return err
+	}
+	defer c.mtx.Unlock()
+	_, t, err := c.topic(streamId, topicId)
+	if err != nil {
+		return err
+	}
+	g, err := t.group(groupId)
+	if err != nil {
+		return err
+	}
+	if !joined && !g.joined {
+		return ierror.MapFromCode(5002)
+	}
+	g.joined = joined
+	return nil
+}
+
+// copyMessage copies the message, so neither the caller nor the Client see the later changes of the other.
+func copyMessage(message iggcon.MessengerMessage) iggcon.MessengerMessage {
+	copied := message
+	copied.Payload = append([]byte(nil), message.Payload...)
+	copied.UserHeaders = append([]byte{}, message.UserHeaders...)
+	return copied
+}
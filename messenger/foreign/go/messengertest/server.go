@@ -0,0 +1,199 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package messengertest
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"sync"
+	"testing"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	ierror "github.com/apache/messenger/foreign/go/errors"
+)
+
+// Server is a stub server speaking the binary protocol over TCP, backed by an in-memory Client.
+// It supports the sessions, personal access tokens, users, clients, streams, topics, partitions,
+// messages, offsets, consumer groups, stats and ping commands of the Messenger dialect set with
+// WithDialect, and answers the other commands with an invalid_command error. Each connection has to log in, but all
+// connections share the same state, including the consumer group memberships. The errors are
+// those of the server, which reports a missing stream as a missing topic or consumer group when
+// getting them, and answers an empty response when a consumer offset cannot be found.
+type Server struct {
+	client   *Client
+	dialect  *iggcon.Dialect
+	listener net.Listener
+
+	mtx    sync.Mutex
+	conns  map[net.Conn]struct{}
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// NewServer starts a stub server listening on a random local port.
+func NewServer(options ...Option) (*Server, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	opts := GetDefaultOptions()
+	for _, opt := range options {
+		if opt != nil {
+			opt(&opts)
+		}
+	}
+	s := &Server{
+		client:   NewClient(options...),
+		dialect:  opts.Dialect,
+		listener: listener,
+		conns:    map[net.Conn]struct{}{},
+	}
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+// StartServer starts a stub server closed at the end of the test.
+func StartServer(t testing.TB, options ...Option) *Server {
+	t.Helper()
+	s, err := NewServer(options...)
+	if err != nil {
+		t.Fatalf("messengertest: failed to start server: %v", err)
+	}
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Addr returns the address the server listens on, to pass to tcp.WithServerAddress.
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Client returns the in-memory client holding the state of the server, e.g. to seed data,
+// assert on it or inject faults.
+func (s *Server) Client() *Client {
+	return s.client
+}
+
+// Close stops the server and closes all its connections.
+func (s *Server) Close() {
+	s.mtx.Lock()
+	if s.closed {
+		s.mtx.Unlock()
+		return
+	}
+	s.closed = true
+	s.listener.Close()
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mtx.Unlock()
+	s.wg.Wait()
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.mtx.Lock()
+		if s.closed {
+			s.mtx.Unlock()
+			conn.Close()
+			return
+		}
+		s.conns[conn] = struct{}{}
+		s.wg.Add(1)
+		s.mtx.Unlock()
+
+		go func() {
+			defer s.wg.Done()
+			defer func() {
+				s.mtx.Lock()
+				delete(s.conns, conn)
+				s.mtx.Unlock()
+				conn.Close()
+			}()
+			if err := s.handle(conn); err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				log.Printf("[WARN] messengertest: connection %s failed: %v", conn.RemoteAddr(), err)
+			}
+		}()
+	}
+}
+
+// handle answers the commands of a connection until it is closed. Like the server, it rejects
+// the commands other than ping and login with an unauthenticated error until the connection
+// logs in.
+func (s *Server) handle(conn net.Conn) error {
+	header := make([]byte, 8)
+	authenticated := false
+	for {
+		if _, err := io.ReadFull(conn, header); err != nil {
+			return err
+		}
+		length := binary.LittleEndian.Uint32(header[:4])
+		if length < 4 {
+			return errors.New("invalid request length")
+		}
+		command := iggcon.CommandCode(binary.LittleEndian.Uint32(header[4:]))
+		request := make([]byte, length-4)
+		if _, err := io.ReadFull(conn, request); err != nil {
+			return err
+		}
+
+		var payload []byte
+		var err error
+		switch {
+		case command == iggcon.LoginUserCode || command == iggcon.LoginWithAccessTokenCode:
+			payload, err = s.dispatch(command, request)
+			authenticated = authenticated || err == nil
+		case command == iggcon.GetOffsetCode && !authenticated:
+			// the server answers an empty response rather than an unauthenticated error
+		case command != iggcon.PingCode && !authenticated:
+			err = ierror.MapFromCode(40)
+		default:
+			payload, err = s.dispatch(command, request)
+			if command == iggcon.LogoutUserCode && err == nil {
+				authenticated = false
+			}
+		}
+		response := make([]byte, 8, 8+len(payload))
+		if err != nil {
+			binary.LittleEndian.PutUint32(response, uint32(errorCode(err)))
+		} else {
+			binary.LittleEndian.PutUint32(response[4:], uint32(len(payload)))
+			response = append(response, payload...)
+		}
+		if _, err = conn.Write(response); err != nil {
+			return err
+		}
+	}
+}
+
+func errorCode(err error) int {
+	var messengerErr *ierror.MessengerError
+	if errors.As(err, &messengerErr) {
+		return messengerErr.Code
+	}
+	return 1
+}
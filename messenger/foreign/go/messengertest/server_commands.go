@@ -0,0 +1,823 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package messengertest
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"slices"
+	"sort"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	ierror "github.com/apache/messenger/foreign/go/errors"
+)
+
+// invalidFormat is returned for the requests that cannot be decoded.
+var invalidFormat = ierror.MapFromCode(4)
+
+var (
+	streamNotFoundCodes = []int{1009, 1010}
+	topicNotFoundCodes  = []int{2010, 2011}
+	// offsetNotFoundCodes are the errors of a missing offset, its stream, topic, partition or
+	// consumer group, or of an unauthenticated connection.
+	offsetNotFoundCodes = append(append([]int{40, 3007, 5000}, streamNotFoundCodes...), topicNotFoundCodes...)
+)
+
+// hasCode reports whether err is a MessengerError of one of the codes.
+func hasCode(err error, codes ...int) bool {
+	var messengerErr *ierror.MessengerError
+	if !errors.As(err, &messengerErr) {
+		return false
+	}
+	return slices.Contains(codes, messengerErr.Code)
+}
+
+// dispatch executes a command on the in-memory client and encodes its response.
+func (s *Server) dispatch(command iggcon.CommandCode, request []byte) ([]byte, error) {
+	if !s.dialect.Supports(command) {
+		return nil, ierror.MapFromCode(3)
+	}
+	r := &reader{b: request}
+	c := s.client
+	switch command {
+	case iggcon.PingCode:
+		return nil, c.Ping()
+	case iggcon.GetStatsCode:
+		stats, err := c.GetStats()
+		if err != nil {
+			return nil, err
+		}
+		stats.ServerVersion = "messenger-test"
+		return encodeStats(stats), nil
+	case iggcon.GetClusterMetadataCode:
+		metadata, err := c.GetClusterMetadata()
+		if err != nil {
+			return nil, err
+		}
+		return encodeClusterMetadata(metadata), nil
+	case iggcon.LoginUserCode:
+		username, password := r.string8(), r.string8()
+		if r.err != nil {
+			return nil, r.err
+		}
+		return encodeIdentity(c.LoginUser(username, password))
+	case iggcon.LoginWithAccessTokenCode:
+		token := r.string8()
+		if r.err != nil {
+			return nil, r.err
+		}
+		return encodeIdentity(c.LoginWithPersonalAccessToken(token))
+	case iggcon.LogoutUserCode:
+		return nil, c.LogoutUser()
+	case iggcon.CreateAccessTokenCode:
+		name := r.string8()
+		r.skip(4)
+		expiry := r.uint32()
+		if r.err != nil {
+			return nil, r.err
+		}
+		token, err := c.CreatePersonalAccessToken(name, expiry)
+		if err != nil {
+			return nil, err
+		}
+		return appendString8(nil, token.Token), nil
+	case iggcon.DeleteAccessTokenCode:
+		name := r.string8()
+		if r.err != nil {
+			return nil, r.err
+		}
+		return nil, c.DeletePersonalAccessToken(name)
+	case iggcon.GetAccessTokensCode:
+		tokens, err := c.GetPersonalAccessTokens()
+		if err != nil {
+			return nil, err
+		}
+		var payload []byte
+		for _, token := range tokens {
+			payload = appendString8(payload, token.Name)
+			expiry := uint64(0)
+			if token.Expiry != nil {
+				expiry = uint64(token.Expiry.UnixNano())
+			}
+			payload = binary.LittleEndian.AppendUint64(payload, expiry)
+		}
+		return payload, nil
+
+	case iggcon.GetStreamsCode:
+		streams, err := c.GetStreams()
+		if err != nil {
+			return nil, err
+		}
+		var payload []byte
+		for _, stream := range streams {
+			payload = appendStream(payload, stream)
+		}
+		return payload, nil
+	case iggcon.GetStreamCode:
+		streamId := r.identifier()
+		if r.err != nil {
+			return nil, r.err
+		}
+		return encodeStreamDetails(c.GetStream(streamId))
+	case iggcon.CreateStreamCode:
+		id, name := r.uint32(), r.string8()
+		if r.err != nil {
+			return nil, r.err
+		}
+		return encodeStreamDetails(c.CreateStream(name, &id))
+	case iggcon.UpdateStreamCode:
+		streamId, name := r.identifier(), r.string8()
+		if r.err != nil {
+			return nil, r.err
+		}
+		return nil, c.UpdateStream(streamId, name)
+	case iggcon.DeleteStreamCode:
+		streamId := r.identifier()
+		if r.err != nil {
+			return nil, r.err
+		}
+		return nil, c.DeleteStream(streamId)
+
+	case iggcon.GetTopicsCode:
+		streamId := r.identifier()
+		if r.err != nil {
+			return nil, r.err
+		}
+		topics, err := c.GetTopics(streamId)
+		if err != nil {
+			return nil, err
+		}
+		var payload []byte
+		for _, topic := range topics {
+			payload = appendTopic(payload, topic)
+		}
+		return payload, nil
+	case iggcon.GetTopicCode:
+		streamId, topicId := r.identifier(), r.identifier()
+		if r.err != nil {
+			return nil, r.err
+		}
+		topic, err := c.GetTopic(streamId, topicId)
+		if hasCode(err, streamNotFoundCodes...) {
+			// the server looks the topic up at once, reporting a missing stream as a missing topic
+			err = ierror.TopicIdNotFound
+		}
+		return encodeTopicDetails(topic, err)
+	case iggcon.CreateTopicCode:
+		streamId, topicId, partitionsCount := r.identifier(), r.uint32(), r.uint32()
+		compression, expiry, maxSize := r.uint8(), r.uint64(), r.uint64()
+		replicationFactor, name := r.uint8(), r.string8()
+		if r.err != nil {
+			return nil, r.err
+		}
+		return encodeTopicDetails(c.CreateTopic(streamId, name, partitionsCount, iggcon.CompressionAlgorithm(compression),
+			iggcon.Expiry(expiry), iggcon.MaxTopicSize(maxSize), &replicationFactor, &topicId))
+	case iggcon.UpdateTopicCode:
+		streamId, topicId := r.identifier(), r.identifier()
+		compression, expiry, maxSize := r.uint8(), r.uint64(), r.uint64()
+		replicationFactor, name := r.uint8(), r.string8()
+		if r.err != nil {
+			return nil, r.err
+		}
+		return nil, c.UpdateTopic(streamId, topicId, name, iggcon.CompressionAlgorithm(compression),
+			iggcon.Expiry(expiry), iggcon.MaxTopicSize(maxSize), &replicationFactor)
+	case iggcon.DeleteTopicCode:
+		streamId, topicId := r.identifier(), r.identifier()
+		if r.err != nil {
+			return nil, r.err
+		}
+		return nil, c.DeleteTopic(streamId, topicId)
+	case iggcon.CreatePartitionsCode, iggcon.DeletePartitionsCode:
+		streamId, topicId, count := r.identifier(), r.identifier(), r.uint32()
+		if r.err != nil {
+			return nil, r.err
+		}
+		if command == iggcon.CreatePartitionsCode {
+			return nil, c.CreatePartitions(streamId, topicId, count)
+		}
+		return nil, c.DeletePartitions(streamId, topicId, count)
+
+	case iggcon.SendMessagesCode:
+		result, err := s.sendMessages(request)
+		if err != nil {
+			return nil, err
+		}
+		return encodeSendResult(result), nil
+	case iggcon.PollMessagesCode:
+		consumer, streamId, topicId := r.consumer(), r.identifier(), r.identifier()
+		partitionId, kind, value := r.optionalUint32(), r.uint8(), r.uint64()
+		count, autoCommit := r.uint32(), r.uint8() == 1
+		var maxWait time.Duration
+		if r.pos < len(r.b) {
+			// long polling
+			maxWait = time.Duration(r.uint32()) * time.Millisecond
+		}
+		if r.err != nil {
+			return nil, r.err
+		}
+		strategy := iggcon.NewPollingStrategy(iggcon.MessagePolling(kind), value)
+		var polled *iggcon.PolledMessage
+		var err error
+		if maxWait > 0 {
+			polled, err = c.PollMessagesWithWait(streamId, topicId, consumer, strategy, count, autoCommit, partitionId, maxWait)
+		} else {
+			polled, err = c.PollMessages(streamId, topicId, consumer, strategy, count, autoCommit, partitionId)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return encodePolledMessages(polled), nil
+	case iggcon.GetOffsetCode:
+		consumer, streamId, topicId, partitionId := r.consumer(), r.identifier(), r.identifier(), r.optionalUint32()
+		if r.err != nil {
+			return nil, r.err
+		}
+		offset, err := c.GetConsumerOffset(consumer, streamId, topicId, partitionId)
+		if hasCode(err, offsetNotFoundCodes...) {
+			// the server answers an empty response when the offset cannot be found
+			return nil, nil
+		}
+		if err != nil || offset == nil {
+			return nil, err
+		}
+		payload := binary.LittleEndian.AppendUint32(nil, offset.PartitionId)
+		payload = binary.LittleEndian.AppendUint64(payload, offset.CurrentOffset)
+		return binary.LittleEndian.AppendUint64(payload, offset.StoredOffset), nil
+	case iggcon.StoreOffsetCode:
+		consumer, streamId, topicId, partitionId, offset := r.consumer(), r.identifier(), r.identifier(), r.optionalUint32(), r.uint64()
+		if r.err != nil {
+			return nil, r.err
+		}
+		return nil, c.StoreConsumerOffset(consumer, streamId, topicId, offset, partitionId)
+	case iggcon.DeleteOffsetCode:
+		consumer, streamId, topicId, partitionId := r.consumer(), r.identifier(), r.identifier(), r.optionalUint32()
+		if r.err != nil {
+			return nil, r.err
+		}
+		return nil, c.DeleteConsumerOffset(consumer, streamId, topicId, partitionId)
+
+	case iggcon.GetGroupsCode:
+		streamId, topicId := r.identifier(), r.identifier()
+		if r.err != nil {
+			return nil, r.err
+		}
+		groups, err := c.GetConsumerGroups(streamId, topicId)
+		if err != nil {
+			return nil, err
+		}
+		var payload []byte
+		for _, group := range groups {
+			payload = appendConsumerGroup(payload, group)
+		}
+		return payload, nil
+	case iggcon.GetGroupCode:
+		streamId, topicId, groupId := r.identifier(), r.identifier(), r.identifier()
+		if r.err != nil {
+			return nil, r.err
+		}
+		group, err := c.GetConsumerGroup(streamId, topicId, groupId)
+		if hasCode(err, append(streamNotFoundCodes, topicNotFoundCodes...)...) {
+			err = ierror.ConsumerGroupIdNotFound
+		}
+		return encodeConsumerGroupDetails(group, err)
+	case iggcon.CreateGroupCode:
+		streamId, topicId, groupId, name := r.identifier(), r.identifier(), r.uint32(), r.string8()
+		if r.err != nil {
+			return nil, r.err
+		}
+		return encodeConsumerGroupDetails(c.CreateConsumerGroup(streamId, topicId, name, &groupId))
+	case iggcon.DeleteGroupCode, iggcon.JoinGroupCode, iggcon.LeaveGroupCode:
+		streamId, topicId, groupId := r.identifier(), r.identifier(), r.identifier()
+		if r.err != nil {
+			return nil, r.err
+		}
+		switch command {
+		case iggcon.DeleteGroupCode:
+			return nil, c.DeleteConsumerGroup(streamId, topicId, groupId)
+		case iggcon.JoinGroupCode:
+			return nil, c.JoinConsumerGroup(streamId, topicId, groupId)
+		default:
+			return nil, c.LeaveConsumerGroup(streamId, topicId, groupId)
+		}
+
+	case iggcon.GetUsersCode:
+		users, err := c.GetUsers()
+		if err != nil {
+			return nil, err
+		}
+		var payload []byte
+		for _, user := range users {
+			payload = appendUser(payload, user)
+		}
+		return payload, nil
+	case iggcon.GetUserCode:
+		userId := r.identifier()
+		if r.err != nil {
+			return nil, r.err
+		}
+		return encodeUserDetails(c.GetUser(userId))
+	case iggcon.CreateUserCode:
+		username, password, status := r.string8(), r.string8(), r.userStatus()
+		permissions := r.permissions()
+		if r.err != nil {
+			return nil, r.err
+		}
+		return encodeUserDetails(c.CreateUser(username, password, status, permissions))
+	case iggcon.UpdateUserCode:
+		userId := r.identifier()
+		var username *string
+		var status *iggcon.UserStatus
+		if r.uint8() == 1 {
+			value := r.string8()
+			username = &value
+		}
+		if r.uint8() == 1 {
+			value := r.userStatus()
+			status = &value
+		}
+		if r.err != nil {
+			return nil, r.err
+		}
+		return nil, c.UpdateUser(userId, username, status)
+	case iggcon.UpdatePermissionsCode:
+		userId, permissions := r.identifier(), r.permissions()
+		if r.err != nil {
+			return nil, r.err
+		}
+		return nil, c.UpdatePermissions(userId, permissions)
+	case iggcon.ChangePasswordCode:
+		userId, currentPassword, newPassword := r.identifier(), r.string8(), r.string8()
+		if r.err != nil {
+			return nil, r.err
+		}
+		return nil, c.ChangePassword(userId, currentPassword, newPassword)
+	case iggcon.DeleteUserCode:
+		userId := r.identifier()
+		if r.err != nil {
+			return nil, r.err
+		}
+		return nil, c.DeleteUser(userId)
+
+	case iggcon.GetClientsCode:
+		clients, err := c.GetClients()
+		if err != nil {
+			return nil, err
+		}
+		var payload []byte
+		for _, client := range clients {
+			payload = appendClientInfo(payload, client, s.dialect.ClientLabels)
+		}
+		return payload, nil
+	case iggcon.GetClientCode:
+		id := r.uint32()
+		if r.err != nil {
+			return nil, r.err
+		}
+		client, err := c.GetClient(id)
+		if err != nil {
+			return nil, err
+		}
+		payload := appendClientInfo(nil, client.ClientInfo, s.dialect.ClientLabels)
+		for _, group := range client.ConsumerGroups {
+			payload = binary.LittleEndian.AppendUint32(payload, group.StreamId)
+			payload = binary.LittleEndian.AppendUint32(payload, group.TopicId)
+			payload = binary.LittleEndian.AppendUint32(payload, group.ConsumerGroupId)
+		}
+		return payload, nil
+	case iggcon.UpdateClientLabelsCode:
+		count := int(r.uint32())
+		labels := make(map[string]string, count)
+		for i := 0; i < count && r.err == nil; i++ {
+			key := r.string8()
+			labels[key] = r.string8()
+		}
+		if r.err != nil {
+			return nil, r.err
+		}
+		return nil, c.updateLabels(labels)
+	}
+	return nil, ierror.MapFromCode(3)
+}
+
+// sendMessages decodes a send messages request: its metadata, the index block, then the messages.
+func (s *Server) sendMessages(request []byte) (*iggcon.SendResult, error) {
+	r := &reader{b: request}
+	metadataLength := int(r.uint32())
+	metadata := &reader{b: r.bytes(metadataLength)}
+	streamId, topicId := metadata.identifier(), metadata.identifier()
+	partitioning := iggcon.Partitioning{Kind: iggcon.PartitioningKind(metadata.uint8())}
+	partitioning.Length = int(metadata.uint8())
+	partitioning.Value = metadata.bytes(partitioning.Length)
+	count := int(metadata.uint32())
+	r.skip(count * 16)
+	if metadata.err != nil || r.err != nil {
+		return nil, invalidFormat
+	}
+
+	headerSize := iggcon.MessengerDialect.MessageHeaderSize
+	messages := make([]iggcon.MessengerMessage, 0, count)
+	for i := 0; i < count; i++ {
+		header, err := iggcon.MessageHeaderFromBytes(r.bytes(headerSize)[:iggcon.MessageHeaderSize])
+		if r.err != nil || err != nil {
+			return nil, invalidFormat
+		}
+		message := iggcon.MessengerMessage{
+			Header:      *header,
+			Payload:     r.bytes(int(header.PayloadLength)),
+			UserHeaders: r.bytes(int(header.UserHeaderLength)),
+		}
+		if r.err != nil {
+			return nil, invalidFormat
+		}
+		messages = append(messages, message)
+	}
+	return s.client.sendMessages("SendMessages", streamId, topicId, partitioning, messages)
+}
+
+// reader decodes a request, recording the first out of bounds read in err.
+type reader struct {
+	b   []byte
+	pos int
+	err error
+}
+
+func (r *reader) bytes(n int) []byte {
+	if r.err != nil || n < 0 || r.pos+n > len(r.b) {
+		r.err = invalidFormat
+		return make([]byte, max(n, 0))
+	}
+	b := r.b[r.pos : r.pos+n]
+	r.pos += n
+	return b
+}
+
+func (r *reader) skip(n int) {
+	r.bytes(n)
+}
+
+func (r *reader) uint8() uint8 {
+	return r.bytes(1)[0]
+}
+
+func (r *reader) uint32() uint32 {
+	return binary.LittleEndian.Uint32(r.bytes(4))
+}
+
+func (r *reader) uint64() uint64 {
+	return binary.LittleEndian.Uint64(r.bytes(8))
+}
+
+// optionalUint32 reads an uint32 where 0 means none.
+func (r *reader) optionalUint32() *uint32 {
+	value := r.uint32()
+	if value == 0 {
+		return nil
+	}
+	return &value
+}
+
+func (r *reader) string8() string {
+	return string(r.bytes(int(r.uint8())))
+}
+
+func (r *reader) identifier() iggcon.Identifier {
+	kind := iggcon.IdKind(r.uint8())
+	length := int(r.uint8())
+	id, err := iggcon.IdentifierFromBytes(kind, r.bytes(length))
+	if err != nil && r.err == nil {
+		r.err = invalidFormat
+	}
+	return id
+}
+
+// userStatus reads a user status, encoded as 1 for active and 2 for inactive.
+func (r *reader) userStatus() iggcon.UserStatus {
+	if r.uint8() == 2 {
+		return iggcon.Inactive
+	}
+	return iggcon.Active
+}
+
+// permissions reads optional permissions: a presence flag, their length, then the global
+// permissions followed by the streams and their topics, each prefixed by a continuation flag.
+func (r *reader) permissions() *iggcon.Permissions {
+	if r.uint8() != 1 {
+		return nil
+	}
+	p := &reader{b: r.bytes(int(r.uint32()))}
+	flags := p.bytes(10)
+	permissions := &iggcon.Permissions{Global: iggcon.GlobalPermissions{
+		ManageServers: flags[0] == 1,
+		ReadServers:   flags[1] == 1,
+		ManageUsers:   flags[2] == 1,
+		ReadUsers:     flags[3] == 1,
+		ManageStreams: flags[4] == 1,
+		ReadStreams:   flags[5] == 1,
+		ManageTopics:  flags[6] == 1,
+		ReadTopics:    flags[7] == 1,
+		PollMessages:  flags[8] == 1,
+		SendMessages:  flags[9] == 1,
+	}}
+	if p.uint8() == 1 {
+		permissions.Streams = map[int]*iggcon.StreamPermissions{}
+		for more := true; more && p.err == nil; more = p.uint8() == 1 {
+			streamId := int(p.uint32())
+			flags := p.bytes(6)
+			stream := &iggcon.StreamPermissions{
+				ManageStream: flags[0] == 1,
+				ReadStream:   flags[1] == 1,
+				ManageTopics: flags[2] == 1,
+				ReadTopics:   flags[3] == 1,
+				PollMessages: flags[4] == 1,
+				SendMessages: flags[5] == 1,
+				Topics:       map[int]*iggcon.TopicPermissions{},
+			}
+			if p.uint8() == 1 {
+				for more := true; more && p.err == nil; more = p.uint8() == 1 {
+					topicId := int(p.uint32())
+					flags := p.bytes(4)
+					stream.Topics[topicId] = &iggcon.TopicPermissions{
+						ManageTopic:  flags[0] == 1,
+						ReadTopic:    flags[1] == 1,
+						PollMessages: flags[2] == 1,
+						SendMessages: flags[3] == 1,
+					}
+				}
+			}
+			permissions.Streams[streamId] = stream
+		}
+	}
+	if p.err != nil {
+		r.err = p.err
+	}
+	return permissions
+}
+
+func (r *reader) consumer() iggcon.Consumer {
+	kind := iggcon.ConsumerKind(r.uint8())
+	return iggcon.Consumer{Kind: kind, Id: r.identifier()}
+}
+
+func appendString8(b []byte, s string) []byte {
+	return append(append(b, byte(len(s))), s...)
+}
+
+func appendString32(b []byte, s string) []byte {
+	return append(binary.LittleEndian.AppendUint32(b, uint32(len(s))), s...)
+}
+
+func encodeIdentity(identity *iggcon.IdentityInfo, err error) ([]byte, error) {
+	if err != nil {
+		return nil, err
+	}
+	return binary.LittleEndian.AppendUint32(nil, identity.UserId), nil
+}
+
+func encodeStats(stats *iggcon.Stats) []byte {
+	b := binary.LittleEndian.AppendUint32(nil, stats.ProcessId)
+	b = binary.LittleEndian.AppendUint32(b, math.Float32bits(stats.CpuUsage))
+	b = binary.LittleEndian.AppendUint32(b, math.Float32bits(stats.TotalCpuUsage))
+	for _, value := range []uint64{stats.MemoryUsage, stats.TotalMemory, stats.AvailableMemory, stats.RunTime,
+		stats.StartTime, stats.ReadBytes, stats.WrittenBytes, stats.MessagesSizeBytes} {
+		b = binary.LittleEndian.AppendUint64(b, value)
+	}
+	for _, value := range []uint32{stats.StreamsCount, stats.TopicsCount, stats.PartitionsCount, stats.SegmentsCount} {
+		b = binary.LittleEndian.AppendUint32(b, value)
+	}
+	b = binary.LittleEndian.AppendUint64(b, stats.MessagesCount)
+	b = binary.LittleEndian.AppendUint32(b, stats.ClientsCount)
+	b = binary.LittleEndian.AppendUint32(b, stats.ConsumerGroupsCount)
+	for _, value := range []string{stats.Hostname, stats.OsName, stats.OsVersion, stats.KernelVersion, stats.ServerVersion} {
+		b = appendString32(b, value)
+	}
+	return b
+}
+
+func encodeClusterMetadata(metadata *iggcon.ClusterMetadata) []byte {
+	b := appendString8(nil, metadata.Name)
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(metadata.Nodes)))
+	for _, node := range metadata.Nodes {
+		b = binary.LittleEndian.AppendUint32(b, node.Id)
+		b = append(b, byte(node.Role), byte(node.Status))
+		b = appendString8(b, node.Name)
+		b = appendString8(b, node.Address)
+	}
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(metadata.Partitions)))
+	for _, partition := range metadata.Partitions {
+		b = binary.LittleEndian.AppendUint32(b, partition.StreamId)
+		b = appendString8(b, partition.StreamName)
+		b = binary.LittleEndian.AppendUint32(b, partition.TopicId)
+		b = appendString8(b, partition.TopicName)
+		b = binary.LittleEndian.AppendUint32(b, partition.PartitionId)
+		b = binary.LittleEndian.AppendUint32(b, partition.LeaderId)
+	}
+	return b
+}
+
+func appendStream(b []byte, stream iggcon.Stream) []byte {
+	b = binary.LittleEndian.AppendUint32(b, stream.Id)
+	b = binary.LittleEndian.AppendUint64(b, stream.CreatedAt)
+	b = binary.LittleEndian.AppendUint32(b, stream.TopicsCount)
+	b = binary.LittleEndian.AppendUint64(b, stream.SizeBytes)
+	b = binary.LittleEndian.AppendUint64(b, stream.MessagesCount)
+	return appendString8(b, stream.Name)
+}
+
+func encodeStreamDetails(stream *iggcon.StreamDetails, err error) ([]byte, error) {
+	if err != nil {
+		return nil, err
+	}
+	b := appendStream(nil, stream.Stream)
+	for _, topic := range stream.Topics {
+		b = appendTopic(b, topic)
+	}
+	return b, nil
+}
+
+func appendTopic(b []byte, topic iggcon.Topic) []byte {
+	b = binary.LittleEndian.AppendUint32(b, topic.Id)
+	b = binary.LittleEndian.AppendUint64(b, topic.CreatedAt)
+	b = binary.LittleEndian.AppendUint32(b, topic.PartitionsCount)
+	b = binary.LittleEndian.AppendUint64(b, uint64(topic.MessageExpiry))
+	b = append(b, topic.CompressionAlgorithm)
+	b = binary.LittleEndian.AppendUint64(b, uint64(topic.MaxTopicSize))
+	b = append(b, topic.ReplicationFactor)
+	b = binary.LittleEndian.AppendUint64(b, topic.Size)
+	b = binary.LittleEndian.AppendUint64(b, topic.MessagesCount)
+	return appendString8(b, topic.Name)
+}
+
+func encodeTopicDetails(topic *iggcon.TopicDetails, err error) ([]byte, error) {
+	if err != nil {
+		return nil, err
+	}
+	b := appendTopic(nil, topic.Topic)
+	for _, partition := range topic.Partitions {
+		b = binary.LittleEndian.AppendUint32(b, partition.Id)
+		b = binary.LittleEndian.AppendUint64(b, partition.CreatedAt)
+		b = binary.LittleEndian.AppendUint32(b, partition.SegmentsCount)
+		b = binary.LittleEndian.AppendUint64(b, partition.CurrentOffset)
+		b = binary.LittleEndian.AppendUint64(b, partition.SizeBytes)
+		b = binary.LittleEndian.AppendUint64(b, partition.MessagesCount)
+	}
+	return b, nil
+}
+
+func appendConsumerGroup(b []byte, group iggcon.ConsumerGroup) []byte {
+	b = binary.LittleEndian.AppendUint32(b, group.Id)
+	b = binary.LittleEndian.AppendUint32(b, group.PartitionsCount)
+	b = binary.LittleEndian.AppendUint32(b, group.MembersCount)
+	return appendString8(b, group.Name)
+}
+
+func encodeConsumerGroupDetails(group *iggcon.ConsumerGroupDetails, err error) ([]byte, error) {
+	if err != nil {
+		return nil, err
+	}
+	b := appendConsumerGroup(nil, group.ConsumerGroup)
+	for _, member := range group.Members {
+		b = binary.LittleEndian.AppendUint32(b, member.ID)
+		b = binary.LittleEndian.AppendUint32(b, member.PartitionsCount)
+		for _, partition := range member.Partitions {
+			b = binary.LittleEndian.AppendUint32(b, partition)
+		}
+	}
+	return b, nil
+}
+
+func encodeSendResult(result *iggcon.SendResult) []byte {
+	b := binary.LittleEndian.AppendUint32(nil, result.PartitionId)
+	b = binary.LittleEndian.AppendUint64(b, result.BaseOffset)
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(result.Timestamps)))
+	for _, timestamp := range result.Timestamps {
+		b = binary.LittleEndian.AppendUint64(b, timestamp)
+	}
+	return b
+}
+
+func encodePolledMessages(polled *iggcon.PolledMessage) []byte {
+	b := binary.LittleEndian.AppendUint32(nil, polled.PartitionId)
+	b = binary.LittleEndian.AppendUint64(b, polled.CurrentOffset)
+	b = binary.LittleEndian.AppendUint32(b, polled.MessageCount)
+	padding := make([]byte, iggcon.MessengerDialect.MessageHeaderSize-iggcon.MessageHeaderSize)
+	for _, message := range polled.Messages {
+		b = append(b, message.Header.ToBytes()...)
+		b = append(b, padding...)
+		b = append(b, message.Payload...)
+		b = append(b, message.UserHeaders...)
+	}
+	return b
+}
+
+func appendUser(b []byte, user iggcon.UserInfo) []byte {
+	b = binary.LittleEndian.AppendUint32(b, user.Id)
+	b = binary.LittleEndian.AppendUint64(b, user.CreatedAt)
+	status := byte(1)
+	if user.Status == iggcon.Inactive {
+		status = 2
+	}
+	b = append(b, status)
+	return appendString8(b, user.Username)
+}
+
+func encodeUserDetails(user *iggcon.UserInfoDetails, err error) ([]byte, error) {
+	if err != nil {
+		return nil, err
+	}
+	b := appendUser(nil, user.UserInfo)
+	if user.Permissions == nil {
+		return append(b, 0), nil
+	}
+	permissions := appendPermissions(nil, user.Permissions)
+	b = binary.LittleEndian.AppendUint32(append(b, 1), uint32(len(permissions)))
+	return append(b, permissions...), nil
+}
+
+// appendPermissions encodes permissions in the layout read by the permissions method of reader.
+func appendPermissions(b []byte, permissions *iggcon.Permissions) []byte {
+	global := permissions.Global
+	b = appendFlags(b, global.ManageServers, global.ReadServers, global.ManageUsers, global.ReadUsers, global.ManageStreams,
+		global.ReadStreams, global.ManageTopics, global.ReadTopics, global.PollMessages, global.SendMessages)
+	if len(permissions.Streams) == 0 {
+		return append(b, 0)
+	}
+	b = append(b, 1)
+	streamIds := sortedIntKeys(permissions.Streams)
+	for i, streamId := range streamIds {
+		stream := permissions.Streams[streamId]
+		b = binary.LittleEndian.AppendUint32(b, uint32(streamId))
+		b = appendFlags(b, stream.ManageStream, stream.ReadStream, stream.ManageTopics, stream.ReadTopics, stream.PollMessages, stream.SendMessages)
+		if len(stream.Topics) == 0 {
+			b = append(b, 0)
+		} else {
+			b = append(b, 1)
+			topicIds := sortedIntKeys(stream.Topics)
+			for j, topicId := range topicIds {
+				topic := stream.Topics[topicId]
+				b = binary.LittleEndian.AppendUint32(b, uint32(topicId))
+				b = appendFlags(b, topic.ManageTopic, topic.ReadTopic, topic.PollMessages, topic.SendMessages)
+				b = appendFlags(b, j < len(topicIds)-1)
+			}
+		}
+		b = appendFlags(b, i < len(streamIds)-1)
+	}
+	return b
+}
+
+func appendFlags(b []byte, flags ...bool) []byte {
+	for _, flag := range flags {
+		if flag {
+			b = append(b, 1)
+		} else {
+			b = append(b, 0)
+		}
+	}
+	return b
+}
+
+func sortedIntKeys[V any](m map[int]V) []int {
+	keys := make([]int, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Ints(keys)
+	return keys
+}
+
+func appendClientInfo(b []byte, client iggcon.ClientInfo, labels bool) []byte {
+	b = binary.LittleEndian.AppendUint32(b, client.ID)
+	b = binary.LittleEndian.AppendUint32(b, client.UserID)
+	b = append(b, 1) // TCP transport
+	b = appendString32(b, client.Address)
+	b = binary.LittleEndian.AppendUint32(b, client.ConsumerGroupsCount)
+	if !labels {
+		return b
+	}
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(client.Labels)))
+	keys := make([]string, 0, len(client.Labels))
+	for key := range client.Labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		b = appendString8(appendString8(b, key), client.Labels[key])
+	}
+	return b
+}
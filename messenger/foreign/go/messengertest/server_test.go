@@ -0,0 +1,151 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package messengertest
+
+import (
+	"testing"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengercli"
+	"github.com/apache/messenger/foreign/go/tcp"
+)
+
+func TestServer_TcpClient(t *testing.T) {
+	server := StartServer(t)
+	client, err := messengercli.NewMessengerClient(messengercli.WithTcp(
+		tcp.WithServerAddress(server.Addr()),
+		tcp.WithDialectDetection(),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = client.LoginUser("messenger", "wrong"); err == nil {
+		t.Fatal("expected login with a wrong password to fail")
+	}
+	if _, err = client.LoginUser("messenger", "messenger"); err != nil {
+		t.Fatal(err)
+	}
+
+	stream, err := client.CreateStream("orders", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	streamId, _ := iggcon.NewIdentifier(stream.Id)
+	topic, err := client.CreateTopic(streamId, "created", 2, iggcon.CompressionAlgorithm(1), 0, 0, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if topic.Name != "created" || len(topic.Partitions) != 2 {
+		t.Fatalf("unexpected topic %+v", topic)
+	}
+	topicId, _ := iggcon.NewIdentifier("created")
+
+	if err = client.SendMessages(streamId, topicId, iggcon.PartitionId(1), newMessages(t, "a", "b")); err != nil {
+		t.Fatal(err)
+	}
+	partition := uint32(1)
+	polled, err := client.PollMessages(streamId, topicId, iggcon.DefaultConsumer(), iggcon.FirstPollingStrategy(), 10, true, &partition)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(polled.Messages) != 2 || string(polled.Messages[1].Payload) != "b" || polled.Messages[1].Header.Offset != 1 {
+		t.Fatalf("unexpected polled messages %+v", polled)
+	}
+	offset, err := client.GetConsumerOffset(iggcon.DefaultConsumer(), streamId, topicId, &partition)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if offset == nil || offset.StoredOffset != 1 {
+		t.Fatalf("unexpected offset %+v", offset)
+	}
+
+	if _, err = client.CreateConsumerGroup(streamId, topicId, "workers", nil); err != nil {
+		t.Fatal(err)
+	}
+	groupId, _ := iggcon.NewIdentifier("workers")
+	if err = client.JoinConsumerGroup(streamId, topicId, groupId); err != nil {
+		t.Fatal(err)
+	}
+	group, err := client.GetConsumerGroup(streamId, topicId, groupId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if group.MembersCount != 1 || len(group.Members[0].Partitions) != 2 {
+		t.Fatalf("unexpected consumer group %+v", group)
+	}
+
+	stats, err := client.GetStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.StreamsCount != 1 || stats.MessagesCount != 2 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+
+	permissions := &iggcon.Permissions{
+		Global: iggcon.GlobalPermissions{ReadStreams: true},
+		Streams: map[int]*iggcon.StreamPermissions{
+			1: {PollMessages: true, Topics: map[int]*iggcon.TopicPermissions{1: {SendMessages: true}}},
+		},
+	}
+	user, err := client.CreateUser("reader", "secret", iggcon.Active, permissions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Username != "reader" || user.Permissions == nil || !user.Permissions.Global.ReadStreams ||
+		!user.Permissions.Streams[1].Topics[1].SendMessages {
+		t.Fatalf("unexpected user %+v", user)
+	}
+	users, err := client.GetUsers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 2 {
+		t.Fatalf("expected 2 users, got %d", len(users))
+	}
+}
+
+func TestServer_ClientLabelsFollowTheDialect(t *testing.T) {
+	labels := map[string]string{iggcon.LabelService: "billing"}
+	for _, dialect := range []*iggcon.Dialect{iggcon.MessengerDialect, iggcon.MessengerExtendedDialect} {
+		t.Run(dialect.Name, func(t *testing.T) {
+			server := StartServer(t, WithDialect(dialect))
+			client, err := messengercli.NewMessengerClient(messengercli.WithTcp(
+				tcp.WithServerAddress(server.Addr()),
+				tcp.WithDialect(dialect),
+				tcp.WithLabels(labels),
+			))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err = client.LoginUser("messenger", "messenger"); err != nil {
+				t.Fatal(err)
+			}
+			clients, err := client.GetClients()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(clients) != 1 {
+				t.Fatalf("expected a single client, got %+v", clients)
+			}
+			if expected := dialect.ClientLabels; (clients[0].Labels[iggcon.LabelService] == "billing") != expected {
+				t.Errorf("expected the labels to be reported: %t, got %+v", expected, clients[0].Labels)
+			}
+		})
+	}
+}
@@ -0,0 +1,392 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package messengertest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"maps"
+	"os"
+	"runtime"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	ierror "github.com/apache/messenger/foreign/go/errors"
+)
+
+// clientId is the ID the Client reports for itself in GetClients.
+const clientId = 1
+
+type user struct {
+	info     iggcon.UserInfoDetails
+	password string
+}
+
+type token struct {
+	value  string
+	userId uint32
+	expiry *time.Time
+}
+
+func (c *Client) user(id iggcon.Identifier) (*user, error) {
+	if value, err := id.Uint32(); err == nil {
+		if u, ok := c.users[value]; ok {
+			return u, nil
+		}
+		return nil, ierror.ResourceNotFound
+	}
+	name, _ := id.String()
+	for _, u := range c.users {
+		if u.info.Username == name {
+			return u, nil
+		}
+	}
+	return nil, ierror.ResourceNotFound
+}
+
+func (c *Client) GetUser(identifier iggcon.Identifier) (*iggcon.UserInfoDetails, error) {
+	if err := c.begin("GetUser"); err != nil {
+		return nil, err
+	}
+	defer c.mtx.Unlock()
+	u, err := c.user(identifier)
+	if err != nil {
+		return nil, err
+	}
+	info := u.info
+	return &info, nil
+}
+
+func (c *Client) GetUsers() ([]iggcon.UserInfo, error) {
+	if err := c.begin("GetUsers"); err != nil {
+		return nil, err
+	}
+	defer c.mtx.Unlock()
+	users := make([]iggcon.UserInfo, 0, len(c.users))
+	for _, id := range sortedKeys(c.users) {
+		users = append(users, c.users[id].info.UserInfo)
+	}
+	return users, nil
+}
+
+func (c *Client) CreateUser(username string, password string, status iggcon.UserStatus, permissions *iggcon.Permissions) (*iggcon.UserInfoDetails, error) {
+	if err := c.begin("CreateUser"); err != nil {
+		return nil, err
+	}
+	defer c.mtx.Unlock()
+	if _, err := iggcon.NewIdentifier(username); err != nil {
+		return nil, ierror.MapFromCode(43)
+	}
+	for _, u := range c.users {
+		if u.info.Username == username {
+			return nil, ierror.CustomError("user_already_exists")
+		}
+	}
+	c.lastUserId++
+	u := &user{
+		info: iggcon.UserInfoDetails{
+			UserInfo:    iggcon.UserInfo{Id: c.lastUserId, CreatedAt: c.now(), Status: status, Username: username},
+			Permissions: permissions,
+		},
+		password: password,
+	}
+	c.users[u.info.Id] = u
+	info := u.info
+	return &info, nil
+}
+
+func (c *Client) UpdateUser(userID iggcon.Identifier, username *string, status *iggcon.UserStatus) error {
+	if err := c.begin("UpdateUser"); err != nil {
+		return err
+	}
+	defer c.mtx.Unlock()
+	u, err := c.user(userID)
+	if err != nil {
+		return err
+	}
+	if username != nil {
+		u.info.Username = *username
+	}
+	if status != nil {
+		u.info.Status = *status
+	}
+	return nil
+}
+
+func (c *Client) UpdatePermissions(userID iggcon.Identifier, permissions *iggcon.Permissions) error {
+	if err := c.begin("UpdatePermissions"); err != nil {
+		return err
+	}
+	defer c.mtx.Unlock()
+	u, err := c.user(userID)
+	if err != nil {
+		return err
+	}
+	u.info.Permissions = permissions
+	return nil
+}
+
+func (c *Client) ChangePassword(userID iggcon.Identifier, currentPassword string, newPassword string) error {
+	if err := c.begin("ChangePassword"); err != nil {
+		return err
+	}
+	defer c.mtx.Unlock()
+	u, err := c.user(userID)
+	if err != nil {
+		return err
+	}
+	if u.password != currentPassword {
+		return ierror.MapFromCode(42)
+	}
+	u.password = newPassword
+	return nil
+}
+
+func (c *Client) DeleteUser(identifier iggcon.Identifier) error {
+	if err := c.begin("DeleteUser"); err != nil {
+		return err
+	}
+	defer c.mtx.Unlock()
+	u, err := c.user(identifier)
+	if err != nil {
+		return err
+	}
+	delete(c.users, u.info.Id)
+	if c.userId == u.info.Id {
+		c.userId = 0
+	}
+	return nil
+}
+
+func (c *Client) CreatePersonalAccessToken(name string, expiry uint32) (*iggcon.RawPersonalAccessToken, error) {
+	if err := c.begin("CreatePersonalAccessToken"); err != nil {
+		return nil, err
+	}
+	defer c.mtx.Unlock()
+	if c.userId == 0 {
+		return nil, ierror.MapFromCode(40)
+	}
+	if _, ok := c.tokens[name]; ok {
+		return nil, ierror.CustomError("personal_access_token_already_exists")
+	}
+	value := make([]byte, 32)
+	if _, err := rand.Read(value); err != nil {
+		return nil, err
+	}
+	t := &token{value: hex.EncodeToString(value), userId: c.userId}
+	if expiry > 0 {
+		expiresAt := c.clock.Now().Add(time.Duration(expiry) * time.Second)
+		t.expiry = &expiresAt
+	}
+	c.tokens[name] = t
+	return &iggcon.RawPersonalAccessToken{Token: t.value}, nil
+}
+
+func (c *Client) DeletePersonalAccessToken(name string) error {
+	if err := c.begin("DeletePersonalAccessToken"); err != nil {
+		return err
+	}
+	defer c.mtx.Unlock()
+	if t, ok := c.tokens[name]; !ok || t.userId != c.userId {
+		return ierror.ResourceNotFound
+	}
+	delete(c.tokens, name)
+	return nil
+}
+
+func (c *Client) GetPersonalAccessTokens() ([]iggcon.PersonalAccessTokenInfo, error) {
+	if err := c.begin("GetPersonalAccessTokens"); err != nil {
+		return nil, err
+	}
+	defer c.mtx.Unlock()
+	tokens := make([]iggcon.PersonalAccessTokenInfo, 0)
+	for name, t := range c.tokens {
+		if t.userId == c.userId {
+			tokens = append(tokens, iggcon.PersonalAccessTokenInfo{Name: name, Expiry: t.expiry})
+		}
+	}
+	return tokens, nil
+}
+
+func (c *Client) LoginWithPersonalAccessToken(value string) (*iggcon.IdentityInfo, error) {
+	if err := c.begin("LoginWithPersonalAccessToken"); err != nil {
+		return nil, err
+	}
+	defer c.mtx.Unlock()
+	for _, t := range c.tokens {
+		if t.value != value {
+			continue
+		}
+		if t.expiry != nil && c.clock.Now().After(*t.expiry) {
+			break
+		}
+		c.userId = t.userId
+		return &iggcon.IdentityInfo{UserId: t.userId}, nil
+	}
+	return nil, ierror.MapFromCode(42)
+}
+
+func (c *Client) LoginUser(username string, password string) (*iggcon.IdentityInfo, error) {
+	if err := c.begin("LoginUser"); err != nil {
+		return nil, err
+	}
+	defer c.mtx.Unlock()
+	for _, u := range c.users {
+		if u.info.Username == username && u.password == password && u.info.Status == iggcon.Active {
+			c.userId = u.info.Id
+			return &iggcon.IdentityInfo{UserId: u.info.Id}, nil
+		}
+	}
+	return nil, ierror.MapFromCode(42)
+}
+
+func (c *Client) LogoutUser() error {
+	if err := c.begin("LogoutUser"); err != nil {
+		return err
+	}
+	defer c.mtx.Unlock()
+	if c.userId == 0 {
+		return ierror.MapFromCode(40)
+	}
+	c.userId = 0
+	return nil
+}
+
+func (c *Client) GetStats() (*iggcon.Stats, error) {
+	if err := c.begin("GetStats"); err != nil {
+		return nil, err
+	}
+	defer c.mtx.Unlock()
+	var memory runtime.MemStats
+	runtime.ReadMemStats(&memory)
+	hostname, _ := os.Hostname()
+	stats := &iggcon.Stats{
+		ProcessId:    uint32(os.Getpid()),
+		MemoryUsage:  memory.HeapAlloc,
+		StreamsCount: uint32(len(c.streams)),
+		ClientsCount: 1,
+		Hostname:     hostname,
+		OsName:       runtime.GOOS,
+	}
+	for _, s := range c.streams {
+		stats.TopicsCount += uint32(len(s.topics))
+		for _, t := range s.topics {
+			topic := t.snapshot()
+			stats.PartitionsCount += topic.PartitionsCount
+			stats.SegmentsCount += topic.PartitionsCount
+			stats.MessagesCount += topic.MessagesCount
+			stats.MessagesSizeBytes += topic.Size
+			stats.ConsumerGroupsCount += uint32(len(t.groups))
+		}
+	}
+	return stats, nil
+}
+
+func (c *Client) Ping() error {
+	if err := c.begin("Ping"); err != nil {
+		return err
+	}
+	c.mtx.Unlock()
+	return nil
+}
+
+func (c *Client) GetClients() ([]iggcon.ClientInfo, error) {
+	if err := c.begin("GetClients"); err != nil {
+		return nil, err
+	}
+	defer c.mtx.Unlock()
+	return []iggcon.ClientInfo{c.clientDetails().ClientInfo}, nil
+}
+
+func (c *Client) GetClient(id uint32) (*iggcon.ClientInfoDetails, error) {
+	if err := c.begin("GetClient"); err != nil {
+		return nil, err
+	}
+	defer c.mtx.Unlock()
+	if id != clientId {
+		return nil, ierror.MapFromCode(100)
+	}
+	return c.clientDetails(), nil
+}
+
+// GetClusterMetadata describes a single node cluster leading every partition.
+func (c *Client) GetClusterMetadata() (*iggcon.ClusterMetadata, error) {
+	if err := c.begin("GetClusterMetadata"); err != nil {
+		return nil, err
+	}
+	defer c.mtx.Unlock()
+	metadata := &iggcon.ClusterMetadata{
+		Name: "messengertest",
+		Nodes: []iggcon.ClusterNode{{
+			Id:      1,
+			Name:    "messengertest",
+			Address: "in-memory",
+			Role:    iggcon.ClusterNodeLeader,
+			Status:  iggcon.ClusterNodeHealthy,
+		}},
+		Partitions: []iggcon.PartitionLeader{},
+	}
+	for _, streamId := range sortedKeys(c.streams) {
+		s := c.streams[streamId]
+		for _, topicId := range sortedKeys(s.topics) {
+			t := s.topics[topicId]
+			for _, p := range t.partitions {
+				metadata.Partitions = append(metadata.Partitions, iggcon.PartitionLeader{
+					StreamId:    streamId,
+					StreamName:  s.details.Name,
+					TopicId:     topicId,
+					TopicName:   t.details.Name,
+					PartitionId: p.details.Id,
+					LeaderId:    1,
+				})
+			}
+		}
+	}
+	return metadata, nil
+}
+
+func (c *Client) clientDetails() *iggcon.ClientInfoDetails {
+	details := &iggcon.ClientInfoDetails{
+		ClientInfo: iggcon.ClientInfo{ID: clientId, Address: "in-memory", UserID: c.userId, Transport: "in-memory", Labels: maps.Clone(c.labels)},
+	}
+	for _, s := range c.streams {
+		for _, t := range s.topics {
+			for _, g := range t.groups {
+				if g.joined {
+					details.ConsumerGroups = append(details.ConsumerGroups, iggcon.ConsumerGroupInfo{
+						StreamId:        s.details.Id,
+						TopicId:         t.details.Id,
+						ConsumerGroupId: g.details.Id,
+					})
+				}
+			}
+		}
+	}
+	details.ConsumerGroupsCount = uint32(len(details.ConsumerGroups))
+	return details
+}
+
+// updateLabels replaces the labels of the client, as UpdateClientLabels of the tcp client does.
+func (c *Client) updateLabels(labels map[string]string) error {
+	if err := c.begin("UpdateClientLabels"); err != nil {
+		return err
+	}
+	defer c.mtx.Unlock()
+	c.labels = labels
+	return nil
+}
@@ -0,0 +1,480 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package natsbridge
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengercli"
+)
+
+const (
+	// partitionId is the partition of the mirrored topics.
+	partitionId uint32 = 1
+	// pushBatchSize is the number of messages polled at once by a push subscription.
+	pushBatchSize = 100
+	// pushMaxWait is how long the server holds the polls of a push subscription.
+	pushMaxWait = time.Second
+	// pushRetryInterval is the pause of a push subscription after a failed poll.
+	pushRetryInterval = time.Second
+	// defaultFetchWait is how long Fetch waits for messages by default, like the NATS client.
+	defaultFetchWait = 5 * time.Second
+)
+
+var (
+	// ErrNoStreamResponse is returned when publishing to a subject no stream is mirrored for.
+	ErrNoStreamResponse = errors.New("natsbridge: no stream for the subject")
+	// ErrTimeout is returned by Fetch when no message arrived in time.
+	ErrTimeout = errors.New("natsbridge: timeout")
+	// ErrBadSubscription is returned when using a subscription the wrong way or once unsubscribed.
+	ErrBadSubscription = errors.New("natsbridge: invalid subscription")
+)
+
+// Header is the headers of a message. Only the first value of every key is published.
+type Header map[string][]string
+
+// Get returns the first value of the key, empty when missing.
+func (h Header) Get(key string) string {
+	if values := h[key]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// Set replaces the values of the key with the value.
+func (h Header) Set(key, value string) {
+	h[key] = []string{value}
+}
+
+// Msg is a message published to or received from a subject.
+type Msg struct {
+	Subject string
+	Header  Header
+	Data    []byte
+
+	sub      *Subscription
+	metadata MsgMetadata
+}
+
+// MsgMetadata describes where a received message is stored.
+type MsgMetadata struct {
+	Stream string
+	// Sequence is the offset of the message in its topic plus one, the sequences of JetStream
+	// starting at 1.
+	Sequence  uint64
+	Timestamp time.Time
+}
+
+// Metadata returns where a received message is stored.
+func (m *Msg) Metadata() (*MsgMetadata, error) {
+	if m.sub == nil {
+		return nil, errors.New("natsbridge: not a received message")
+	}
+	metadata := m.metadata
+	return &metadata, nil
+}
+
+// Ack stores the offset of the message for the durable consumer of its subscription, which
+// resumes after it. It does nothing for the other subscriptions.
+func (m *Msg) Ack() error {
+	if m.sub == nil {
+		return errors.New("natsbridge: not a received message")
+	}
+	if m.sub.durable == "" {
+		return nil
+	}
+	partition := partitionId
+	return m.sub.js.client.StoreConsumerOffset(m.sub.consumer, m.sub.route.streamId, m.sub.route.topicId, m.metadata.Sequence-1, &partition)
+}
+
+// PubAck is the acknowledgement of a published message.
+type PubAck struct {
+	Stream string
+	// Sequence is the offset of the message in its topic plus one, 0 when the server does not
+	// report it.
+	Sequence uint64
+}
+
+// MsgHandler is called with the messages of a push subscription.
+type MsgHandler func(msg *Msg)
+
+// route is the topic the messages of the subjects matching a filter are mirrored to.
+type route struct {
+	filter   string
+	stream   string
+	streamId iggcon.Identifier
+	topicId  iggcon.Identifier
+}
+
+// JetStream publishes and subscribes to the subjects of the mirrored JetStream streams, like
+// the JetStreamContext of the NATS client.
+type JetStream struct {
+	client messengercli.Client
+	routes []route
+}
+
+// NewJetStream creates a JetStream publishing and subscribing to the subjects of the given
+// streams, mirrored with Spec.
+func NewJetStream(client messengercli.Client, streams ...StreamConfig) (*JetStream, error) {
+	if client == nil {
+		return nil, errors.New("natsbridge: client is required")
+	}
+	js := &JetStream{client: client}
+	for _, stream := range streams {
+		streamId, err := iggcon.NewIdentifier(stream.Name)
+		if err != nil {
+			return nil, err
+		}
+		for _, subject := range stream.Subjects {
+			topic, err := TopicForSubject(subject)
+			if err != nil {
+				return nil, err
+			}
+			topicId, err := iggcon.NewIdentifier(topic)
+			if err != nil {
+				return nil, err
+			}
+			js.routes = append(js.routes, route{filter: subject, stream: stream.Name, streamId: streamId, topicId: topicId})
+		}
+	}
+	if len(js.routes) == 0 {
+		return nil, errors.New("natsbridge: no subject to route")
+	}
+	return js, nil
+}
+
+// route returns the route of the subject, or of the subscriptions to the subject filter.
+func (js *JetStream) route(subject string) (route, error) {
+	for _, r := range js.routes {
+		if subjectMatches(r.filter, subject) {
+			return r, nil
+		}
+	}
+	return route{}, ErrNoStreamResponse
+}
+
+// Publish publishes the data to the subject.
+func (js *JetStream) Publish(subject string, data []byte) (*PubAck, error) {
+	return js.PublishMsg(&Msg{Subject: subject, Data: data})
+}
+
+// PublishMsg publishes the message to its subject, along with its headers.
+func (js *JetStream) PublishMsg(msg *Msg) (*PubAck, error) {
+	r, err := js.route(msg.Subject)
+	if err != nil {
+		return nil, err
+	}
+	headers := map[iggcon.HeaderKey]iggcon.HeaderValue{
+		{Value: SubjectHeader}: iggcon.NewStringHeaderValue(msg.Subject),
+	}
+	for key, values := range msg.Header {
+		headerKey, err := iggcon.NewHeaderKey(key)
+		if err != nil {
+			return nil, err
+		}
+		if len(values) > 0 {
+			headers[headerKey] = iggcon.NewStringHeaderValue(values[0])
+		}
+	}
+	message, err := iggcon.NewMessengerMessage(msg.Data, iggcon.WithUserHeaders(headers))
+	if err != nil {
+		return nil, err
+	}
+	result, err := js.client.SendMessagesWithResult(r.streamId, r.topicId, iggcon.PartitionId(partitionId), []iggcon.MessengerMessage{message}, iggcon.ConfirmationDefault)
+	if err != nil {
+		return nil, err
+	}
+	ack := &PubAck{Stream: r.stream}
+	if result != nil && result.Count() > 0 {
+		ack.Sequence = result.Offset(0) + 1
+	}
+	return ack, nil
+}
+
+type SubOpt func(opts *subOpts)
+
+type subOpts struct {
+	durable    string
+	deliverNew bool
+}
+
+// Durable makes the subscription resume from the last message acknowledged with Msg.Ack by the
+// consumer of the given name.
+func Durable(name string) SubOpt {
+	return func(opts *subOpts) {
+		opts.durable = name
+	}
+}
+
+// DeliverNew starts the subscription with the messages published after it, unless a durable
+// consumer resumes from an acknowledged message.
+func DeliverNew() SubOpt {
+	return func(opts *subOpts) {
+		opts.deliverNew = true
+	}
+}
+
+// Subscription receives the messages of the subjects matching its filter.
+type Subscription struct {
+	js       *JetStream
+	subject  string
+	route    route
+	durable  string
+	consumer iggcon.Consumer
+
+	pull   bool
+	closed atomic.Bool
+	stop   chan struct{}
+
+	// mtx serializes the polls, next being the offset of the next message to poll.
+	mtx  sync.Mutex
+	next uint64
+}
+
+// Subscribe calls the handler with the messages of the subjects matching the filter, from a
+// background goroutine, until Unsubscribe is called.
+func (js *JetStream) Subscribe(subject string, handler MsgHandler, opts ...SubOpt) (*Subscription, error) {
+	if handler == nil {
+		return nil, errors.New("natsbridge: handler is required")
+	}
+	sub, err := js.subscribe(subject, opts)
+	if err != nil {
+		return nil, err
+	}
+	go sub.run(handler)
+	return sub, nil
+}
+
+// PullSubscribe creates a subscription whose messages are fetched with Fetch, for the durable
+// consumer of the given name.
+func (js *JetStream) PullSubscribe(subject, durable string, opts ...SubOpt) (*Subscription, error) {
+	if durable != "" {
+		opts = append(opts, Durable(durable))
+	}
+	sub, err := js.subscribe(subject, opts)
+	if err != nil {
+		return nil, err
+	}
+	sub.pull = true
+	return sub, nil
+}
+
+func (js *JetStream) subscribe(subject string, opts []SubOpt) (*Subscription, error) {
+	var options subOpts
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&options)
+		}
+	}
+	r, err := js.route(subject)
+	if err != nil {
+		return nil, err
+	}
+	sub := &Subscription{
+		js:       js,
+		subject:  subject,
+		route:    r,
+		durable:  options.durable,
+		consumer: iggcon.DefaultConsumer(),
+		stop:     make(chan struct{}),
+	}
+	partition := partitionId
+	if options.durable != "" {
+		consumerId, err := iggcon.NewIdentifier(options.durable)
+		if err != nil {
+			return nil, err
+		}
+		sub.consumer = iggcon.NewSingleConsumer(consumerId)
+		offset, err := js.client.GetConsumerOffset(sub.consumer, r.streamId, r.topicId, &partition)
+		if err != nil {
+			return nil, err
+		}
+		if offset != nil {
+			sub.next = offset.StoredOffset + 1
+			return sub, nil
+		}
+	}
+	if options.deliverNew {
+		topic, err := js.client.GetTopic(r.streamId, r.topicId)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range topic.Partitions {
+			if p.Id == partitionId && p.MessagesCount > 0 {
+				sub.next = p.CurrentOffset + 1
+			}
+		}
+	}
+	return sub, nil
+}
+
+// run calls the handler with the messages of a push subscription until it is unsubscribed.
+func (s *Subscription) run(handler MsgHandler) {
+	for {
+		select {
+		case <-s.stop:
+			return
+		default:
+		}
+		msgs, err := s.poll(pushBatchSize, pushMaxWait)
+		if err != nil {
+			log.Printf("[WARN] natsbridge subscription to %s failed to poll: %v", s.subject, err)
+			select {
+			case <-s.stop:
+				return
+			case <-time.After(pushRetryInterval):
+			}
+			continue
+		}
+		for _, msg := range msgs {
+			select {
+			case <-s.stop:
+				return
+			default:
+			}
+			handler(msg)
+		}
+	}
+}
+
+// poll polls the next messages of the topic, returning the ones matching the subject filter.
+func (s *Subscription) poll(batch uint32, maxWait time.Duration) ([]*Msg, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	partition := partitionId
+	polled, err := s.js.client.PollMessagesWithWait(
+		s.route.streamId,
+		s.route.topicId,
+		s.consumer,
+		iggcon.OffsetPollingStrategy(s.next),
+		batch,
+		false,
+		&partition,
+		maxWait,
+	)
+	if err != nil || polled == nil {
+		return nil, err
+	}
+	var msgs []*Msg
+	for _, message := range polled.Messages {
+		if message.Header.Offset < s.next {
+			continue
+		}
+		s.next = message.Header.Offset + 1
+		if msg, ok := s.toMsg(message); ok {
+			msgs = append(msgs, msg)
+		}
+	}
+	return msgs, nil
+}
+
+// toMsg converts a polled message, reporting false when its subject does not match the filter.
+func (s *Subscription) toMsg(message iggcon.MessengerMessage) (*Msg, bool) {
+	headers, err := iggcon.DeserializeHeaders(message.UserHeaders)
+	if err != nil {
+		return nil, false
+	}
+	subject, err := headers[iggcon.HeaderKey{Value: SubjectHeader}].AsString()
+	if err != nil || !subjectMatches(s.subject, subject) {
+		return nil, false
+	}
+	msg := &Msg{
+		Subject: subject,
+		Data:    message.Payload,
+		sub:     s,
+		metadata: MsgMetadata{
+			Stream:    s.route.stream,
+			Sequence:  message.Header.Offset + 1,
+			Timestamp: time.UnixMicro(int64(message.Header.Timestamp)),
+		},
+	}
+	for key, value := range headers {
+		if key.Value == SubjectHeader {
+			continue
+		}
+		if msg.Header == nil {
+			msg.Header = Header{}
+		}
+		msg.Header.Set(key.Value, string(value.Value))
+	}
+	return msg, true
+}
+
+type FetchOpt func(opts *fetchOpts)
+
+type fetchOpts struct {
+	maxWait time.Duration
+}
+
+// MaxWait sets how long Fetch waits for messages, 5 seconds by default.
+func MaxWait(maxWait time.Duration) FetchOpt {
+	return func(opts *fetchOpts) {
+		opts.maxWait = maxWait
+	}
+}
+
+// Fetch returns up to batch messages of a pull subscription, waiting for the first one until the
+// MaxWait elapsed, when it returns ErrTimeout.
+func (s *Subscription) Fetch(batch int, opts ...FetchOpt) ([]*Msg, error) {
+	options := fetchOpts{maxWait: defaultFetchWait}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&options)
+		}
+	}
+	if !s.pull || batch <= 0 {
+		return nil, ErrBadSubscription
+	}
+	deadline := time.Now().Add(options.maxWait)
+	for {
+		if s.closed.Load() {
+			return nil, ErrBadSubscription
+		}
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return nil, ErrTimeout
+		}
+		msgs, err := s.poll(uint32(batch), remaining)
+		if err != nil {
+			return nil, err
+		}
+		if len(msgs) > 0 {
+			return msgs, nil
+		}
+		// the server answered early, e.g. without long polling, or with messages of other subjects
+		select {
+		case <-s.stop:
+		case <-time.After(min(remaining, 100*time.Millisecond)):
+		}
+	}
+}
+
+// Unsubscribe stops the subscription. The handler of a push subscription is not called anymore
+// once the call it may be running returned.
+func (s *Subscription) Unsubscribe() error {
+	if !s.closed.CompareAndSwap(false, true) {
+		return ErrBadSubscription
+	}
+	close(s.stop)
+	return nil
+}
@@ -0,0 +1,129 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package natsbridge_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/apache/messenger/foreign/go/admin"
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengertest"
+	"github.com/apache/messenger/foreign/go/natsbridge"
+)
+
+func TestJetStream(t *testing.T) {
+	stream, err := natsbridge.ParseStreamConfig([]byte(`{
+		"config": {"name": "ORDERS", "subjects": ["orders.>", "orders.*", "payments.settled"], "max_age": 86400000000000, "max_bytes": -1, "num_replicas": 1},
+		"state": {"messages": 0}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec, err := natsbridge.Spec(stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	topics := spec.Streams[0].Topics
+	if len(topics) != 2 || topics[0].Name != "orders" || topics[1].Name != "payments.settled" {
+		t.Fatalf("expected the topics orders and payments.settled, got %+v", topics)
+	}
+	if topics[0].MessageExpiry != iggcon.ExpiryAfter(24*time.Hour) || topics[0].MaxTopicSize != iggcon.MaxTopicSizeUnlimited {
+		t.Fatalf("expected the retention of the stream, got %+v", topics[0])
+	}
+
+	client := messengertest.NewClient()
+	if _, err = admin.Provision(context.Background(), client, spec); err != nil {
+		t.Fatal(err)
+	}
+	js, err := natsbridge.NewJetStream(client, stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = js.Publish("invoices.sent", []byte("invoice")); !errors.Is(err, natsbridge.ErrNoStreamResponse) {
+		t.Fatalf("expected no stream for the subject, got %v", err)
+	}
+	ack, err := js.PublishMsg(&natsbridge.Msg{Subject: "orders.eu.created", Header: natsbridge.Header{"region": {"eu"}}, Data: []byte("order 1")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ack.Stream != "ORDERS" || ack.Sequence != 1 {
+		t.Fatalf("expected the first message of ORDERS, got %+v", ack)
+	}
+	for _, subject := range []string{"orders.us.created", "orders.eu.shipped"} {
+		if _, err = js.Publish(subject, []byte(subject)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	received := make(chan *natsbridge.Msg, 10)
+	sub, err := js.Subscribe("orders.eu.*", func(msg *natsbridge.Msg) { received <- msg })
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"orders.eu.created", "orders.eu.shipped"} {
+		select {
+		case msg := <-received:
+			if msg.Subject != expected {
+				t.Fatalf("expected a message of %s, got %s", expected, msg.Subject)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for a message of %s", expected)
+		}
+	}
+	if err = sub.Unsubscribe(); err != nil {
+		t.Fatal(err)
+	}
+
+	pull, err := js.PullSubscribe("orders.*.created", "billing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	msgs, err := pull.Fetch(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs) != 1 || string(msgs[0].Data) != "order 1" || msgs[0].Header.Get("region") != "eu" {
+		t.Fatalf("expected the first order, got %+v", msgs)
+	}
+	if err = msgs[0].Ack(); err != nil {
+		t.Fatal(err)
+	}
+	pull.Unsubscribe()
+
+	// the durable consumer resumes after the acknowledged message
+	pull, err = js.PullSubscribe("orders.*.created", "billing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	msgs, err = pull.Fetch(10, natsbridge.MaxWait(100*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs) != 1 || msgs[0].Subject != "orders.us.created" {
+		t.Fatalf("expected the second order, got %+v", msgs)
+	}
+	if metadata, _ := msgs[0].Metadata(); metadata.Sequence != 2 {
+		t.Fatalf("expected the sequence 2, got %+v", metadata)
+	}
+	if _, err = pull.Fetch(10, natsbridge.MaxWait(50*time.Millisecond)); !errors.Is(err, natsbridge.ErrTimeout) {
+		t.Fatalf("expected a timeout, got %v", err)
+	}
+}
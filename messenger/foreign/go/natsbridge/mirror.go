@@ -0,0 +1,147 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package natsbridge implements a subset of the JetStream API of the NATS client on top of a
+// messengercli.Client, so the services migrating from NATS keep their publish and subscribe call
+// sites, and mirrors the JetStream streams to Messenger streams and topics.
+//
+// A JetStream stream is mirrored to the stream of the same name, every subject filter of the
+// stream to a topic of a single partition, named after the tokens of the filter before its first
+// wildcard: the subjects orders.created and orders.> are mirrored to the topics orders.created
+// and orders. The subject of every message is kept in its SubjectHeader, so the subscriptions
+// filter the messages of the topics by subject.
+package natsbridge
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/apache/messenger/foreign/go/admin"
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+)
+
+// SubjectHeader is the user header carrying the NATS subject of a message.
+const SubjectHeader = "nats-subject"
+
+// StreamConfig is the configuration of a JetStream stream, as encoded in JSON by the NATS tools.
+type StreamConfig struct {
+	Name     string   `json:"name"`
+	Subjects []string `json:"subjects"`
+	// MaxAge is how long the messages are kept, 0 for ever.
+	MaxAge time.Duration `json:"max_age"`
+	// MaxBytes is the maximum size of the stream, -1 for no limit.
+	MaxBytes int64 `json:"max_bytes"`
+	Replicas int   `json:"num_replicas"`
+}
+
+// ParseStreamConfig decodes the JSON of a stream configuration, or of a stream info holding it
+// like the output of `nats stream info --json`.
+func ParseStreamConfig(data []byte) (StreamConfig, error) {
+	var info struct {
+		Config *StreamConfig `json:"config"`
+	}
+	if err := json.Unmarshal(data, &info); err != nil {
+		return StreamConfig{}, err
+	}
+	if info.Config != nil {
+		return *info.Config, nil
+	}
+	var config StreamConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return StreamConfig{}, err
+	}
+	if config.Name == "" {
+		return StreamConfig{}, errors.New("natsbridge: the stream configuration has no name")
+	}
+	return config, nil
+}
+
+// TopicForSubject returns the topic the messages matching a subject filter are mirrored to: the
+// tokens of the filter before its first wildcard.
+func TopicForSubject(subject string) (string, error) {
+	var literal []string
+	for _, token := range strings.Split(subject, ".") {
+		if token == "" {
+			return "", fmt.Errorf("natsbridge: invalid subject %q", subject)
+		}
+		if token == "*" || token == ">" {
+			break
+		}
+		literal = append(literal, token)
+	}
+	if len(literal) == 0 {
+		return "", fmt.Errorf("natsbridge: the subject %q starts with a wildcard", subject)
+	}
+	return strings.Join(literal, "."), nil
+}
+
+// Spec returns the spec mirroring the JetStream streams, to be applied with admin.Provision. The
+// size limit of a stream applies to each of its topics.
+func Spec(streams ...StreamConfig) (admin.Spec, error) {
+	var spec admin.Spec
+	for _, stream := range streams {
+		streamSpec := admin.StreamSpec{Name: stream.Name}
+		mirrored := map[string]bool{}
+		for _, subject := range stream.Subjects {
+			topic, err := TopicForSubject(subject)
+			if err != nil {
+				return admin.Spec{}, err
+			}
+			if mirrored[topic] {
+				continue
+			}
+			mirrored[topic] = true
+			streamSpec.Topics = append(streamSpec.Topics, topicSpec(stream, topic))
+		}
+		spec.Streams = append(spec.Streams, streamSpec)
+	}
+	return spec, nil
+}
+
+func topicSpec(stream StreamConfig, topic string) admin.TopicSpec {
+	spec := admin.TopicSpec{Name: topic, Partitions: 1, MessageExpiry: iggcon.ExpiryNever, MaxTopicSize: iggcon.MaxTopicSizeUnlimited}
+	if stream.MaxAge > 0 {
+		spec.MessageExpiry = iggcon.ExpiryAfter(stream.MaxAge)
+	}
+	if stream.MaxBytes > 0 {
+		spec.MaxTopicSize = iggcon.MaxTopicSize(stream.MaxBytes)
+	}
+	if stream.Replicas > 1 {
+		replicas := uint8(min(stream.Replicas, 255))
+		spec.ReplicationFactor = &replicas
+	}
+	return spec
+}
+
+// subjectMatches reports whether the subject matches the filter, in which '*' matches a token
+// and a trailing '>' one or more tokens.
+func subjectMatches(filter, subject string) bool {
+	filterTokens := strings.Split(filter, ".")
+	subjectTokens := strings.Split(subject, ".")
+	for i, token := range filterTokens {
+		if token == ">" && i == len(filterTokens)-1 {
+			return len(subjectTokens) > i
+		}
+		if i >= len(subjectTokens) || token != "*" && token != subjectTokens[i] {
+			return false
+		}
+	}
+	return len(filterTokens) == len(subjectTokens)
+}
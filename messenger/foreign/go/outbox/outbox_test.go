@@ -0,0 +1,339 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package outbox_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	ierror "github.com/apache/messenger/foreign/go/errors"
+	"github.com/apache/messenger/foreign/go/messengertest"
+	"github.com/apache/messenger/foreign/go/outbox"
+)
+
+func TestRelay(t *testing.T) {
+	client := messengertest.NewClient()
+	if _, err := client.CreateStream("shop", nil); err != nil {
+		t.Fatal(err)
+	}
+	streamId, topicId := iggcon.MustIdentifier("shop"), iggcon.MustIdentifier("orders")
+	if _, err := client.CreateTopic(streamId, "orders", 1, iggcon.CompressionAlgorithmNone, 0, 0, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	db := sql.OpenDB(&memoryConnector{table: &memoryTable{}})
+	defer db.Close()
+	var table outbox.Table
+	ctx := context.Background()
+	for _, entry := range []outbox.Entry{
+		{Stream: "shop", Topic: "orders", Key: []byte("alice"), Payload: []byte("created")},
+		{Stream: "shop", Topic: "orders", Key: []byte("alice"), Payload: []byte("paid")},
+		{Stream: "shop", Topic: "orders", Payload: []byte("audited")},
+	} {
+		if err := table.Insert(ctx, db, entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	relay, err := outbox.NewRelay(db, client, table)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the rows of alice are sent together, then sending the last row fails
+	sends := 0
+	client.InjectFault(func(method string) error {
+		if method == "SendMessagesWithConfirmation" {
+			if sends++; sends == 2 {
+				return ierror.MapFromCode(51)
+			}
+		}
+		return nil
+	})
+	published, err := relay.RelayOnce(ctx)
+	if published != 2 || err == nil {
+		t.Fatalf("expected the rows of alice to be published before the failure, got %d, %v", published, err)
+	}
+	client.ClearFaults()
+	if published, err = relay.RelayOnce(ctx); published != 1 || err != nil {
+		t.Fatalf("expected the last row to be published, got %d, %v", published, err)
+	}
+	if published, err = relay.RelayOnce(ctx); published != 0 || err != nil {
+		t.Fatalf("expected no row left, got %d, %v", published, err)
+	}
+
+	partitionId := uint32(1)
+	polled, err := client.PollMessages(streamId, topicId, iggcon.DefaultConsumer(), iggcon.FirstPollingStrategy(), 10, false, &partitionId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(polled.Messages) != 3 {
+		t.Fatalf("expected 3 messages, got %d", len(polled.Messages))
+	}
+	ids := make(map[[16]byte]bool)
+	for i, message := range polled.Messages {
+		if want := []string{"created", "paid", "audited"}[i]; string(message.Payload) != want {
+			t.Fatalf("expected %s, got %s", want, message.Payload)
+		}
+		id, ok := message.UserHeader(outbox.IdHeader)
+		if !ok {
+			t.Fatalf("expected the %s header", outbox.IdHeader)
+		}
+		if rowId, err := id.AsUint64(); err != nil || rowId != uint64(i+1) {
+			t.Fatalf("expected the row ID %d, got %d, %v", i+1, rowId, err)
+		}
+		ids[message.Header.Id] = true
+	}
+	if len(ids) != 3 {
+		t.Fatalf("expected distinct message IDs, got %v", ids)
+	}
+	if key := polled.Messages[0].Key(); string(key) != "alice" {
+		t.Fatalf("expected the key alice, got %q", key)
+	}
+
+	// a restarted relay publishes the rows it did not mark with the same message IDs
+	table2 := &memoryTable{}
+	db2 := sql.OpenDB(&memoryConnector{table: table2})
+	defer db2.Close()
+	if err = table.Insert(ctx, db2, outbox.Entry{Stream: "shop", Topic: "orders", Payload: []byte("created")}); err != nil {
+		t.Fatal(err)
+	}
+	relay2, err := outbox.NewRelay(db2, client, table, outbox.WithDeleteSent())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = relay2.RelayOnce(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(table2.rows) != 0 {
+		t.Fatalf("expected the sent rows to be deleted, got %d", len(table2.rows))
+	}
+	polled, err = client.PollMessages(streamId, topicId, iggcon.DefaultConsumer(), iggcon.OffsetPollingStrategy(3), 10, false, &partitionId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(polled.Messages) != 1 || !ids[polled.Messages[0].Header.Id] {
+		t.Fatal("expected the row 1 to be published with the same message ID")
+	}
+}
+
+func TestRelay_Run(t *testing.T) {
+	client := messengertest.NewClient()
+	if _, err := client.CreateStream("shop", nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.CreateTopic(iggcon.MustIdentifier("shop"), "orders", 1, iggcon.CompressionAlgorithmNone, 0, 0, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	memory := &memoryTable{}
+	db := sql.OpenDB(&memoryConnector{table: memory})
+	defer db.Close()
+	table := outbox.Table{Name: "events", Placeholder: outbox.DollarPlaceholder}
+	relay, err := outbox.NewRelay(db, client, table, outbox.WithBatchSize(2), outbox.WithPollInterval(10*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	ran := make(chan error, 1)
+	go func() {
+		ran <- relay.Run(ctx)
+	}()
+	for i := 0; i < 5; i++ {
+		if err = table.Insert(ctx, db, outbox.Entry{Stream: "shop", Topic: "orders", Payload: []byte("event")}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for memory.unsent() > 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the relay to publish every row")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	if err = <-ran; err != nil {
+		t.Fatal(err)
+	}
+	if err = memory.lastQuery("UPDATE events SET sent_at = $1 WHERE id IN ($2"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// memoryTable is an outbox table served by a database/sql driver understanding the queries of
+// the relay.
+type memoryTable struct {
+	mtx     sync.Mutex
+	rows    []memoryRow
+	nextId  int64
+	queries []string
+}
+
+type memoryRow struct {
+	id      int64
+	stream  string
+	topic   string
+	key     []byte
+	payload []byte
+	sent    bool
+}
+
+func (m *memoryTable) unsent() int {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	count := 0
+	for _, row := range m.rows {
+		if !row.sent {
+			count++
+		}
+	}
+	return count
+}
+
+func (m *memoryTable) lastQuery(prefix string) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	for i := len(m.queries) - 1; i >= 0; i-- {
+		if strings.HasPrefix(m.queries[i], prefix) {
+			return nil
+		}
+	}
+	return errors.New("no query starting with " + prefix)
+}
+
+func (m *memoryTable) exec(query string, args []driver.NamedValue) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.queries = append(m.queries, query)
+	switch {
+	case strings.HasPrefix(query, "INSERT"):
+		m.nextId++
+		key, _ := args[2].Value.([]byte)
+		m.rows = append(m.rows, memoryRow{
+			id:      m.nextId,
+			stream:  args[0].Value.(string),
+			topic:   args[1].Value.(string),
+			key:     key,
+			payload: args[3].Value.([]byte),
+		})
+	case strings.HasPrefix(query, "UPDATE"):
+		for _, arg := range args[1:] {
+			for i := range m.rows {
+				if m.rows[i].id == arg.Value.(int64) {
+					m.rows[i].sent = true
+				}
+			}
+		}
+	case strings.HasPrefix(query, "DELETE"):
+		for _, arg := range args {
+			for i := range m.rows {
+				if m.rows[i].id == arg.Value.(int64) {
+					m.rows = append(m.rows[:i], m.rows[i+1:]...)
+					break
+				}
+			}
+		}
+	default:
+		return errors.New("unexpected statement " + query)
+	}
+	return nil
+}
+
+func (m *memoryTable) query(query string) (driver.Rows, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.queries = append(m.queries, query)
+	if !strings.HasPrefix(query, "SELECT") {
+		return nil, errors.New("unexpected query " + query)
+	}
+	var limit int
+	if _, err := fmt.Sscanf(query[strings.LastIndex(query, "LIMIT"):], "LIMIT %d", &limit); err != nil {
+		return nil, err
+	}
+	rows := &memoryRows{}
+	for _, row := range m.rows {
+		if !row.sent && len(rows.values) < limit {
+			rows.values = append(rows.values, []driver.Value{row.id, row.stream, row.topic, row.key, row.payload})
+		}
+	}
+	return rows, nil
+}
+
+type memoryConnector struct {
+	table *memoryTable
+}
+
+func (c *memoryConnector) Connect(context.Context) (driver.Conn, error) {
+	return &memoryConn{table: c.table}, nil
+}
+
+func (c *memoryConnector) Driver() driver.Driver {
+	return nil
+}
+
+type memoryConn struct {
+	table *memoryTable
+}
+
+func (c *memoryConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepared statements are not supported")
+}
+
+func (c *memoryConn) Close() error {
+	return nil
+}
+
+func (c *memoryConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions are not supported")
+}
+
+func (c *memoryConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return driver.RowsAffected(1), c.table.exec(query, args)
+}
+
+func (c *memoryConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	return c.table.query(query)
+}
+
+type memoryRows struct {
+	values [][]driver.Value
+}
+
+func (r *memoryRows) Columns() []string {
+	return []string{"id", "stream", "topic", "message_key", "payload"}
+}
+
+func (r *memoryRows) Close() error {
+	return nil
+}
+
+func (r *memoryRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
@@ -0,0 +1,270 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengercli"
+	"github.com/google/uuid"
+)
+
+// IdHeader is the user header carrying the ID of the row a message was published from.
+const IdHeader = "outbox-id"
+
+type Option func(opts *Options)
+
+type Options struct {
+	// BatchSize is the maximum number of rows published at once.
+	BatchSize int
+	// PollInterval is the pause after the table had no row to publish.
+	PollInterval time.Duration
+	// Confirmation is the acknowledgement level the rows are published with before being marked
+	// as sent.
+	Confirmation iggcon.Confirmation
+	// DeleteSent deletes the published rows instead of setting their sent_at column.
+	DeleteSent bool
+	// ErrorHandler is called with the errors Run recovers from by retrying after PollInterval.
+	ErrorHandler func(err error)
+}
+
+func GetDefaultOptions() Options {
+	return Options{
+		BatchSize:    100,
+		PollInterval: time.Second,
+		Confirmation: iggcon.ConfirmationWrite,
+		ErrorHandler: func(err error) {
+			log.Printf("[WARN] outbox relay failed to publish: %v", err)
+		},
+	}
+}
+
+// WithBatchSize sets the maximum number of rows published at once.
+func WithBatchSize(size int) Option {
+	return func(opts *Options) {
+		opts.BatchSize = size
+	}
+}
+
+// WithPollInterval sets the pause after the table had no row to publish.
+func WithPollInterval(interval time.Duration) Option {
+	return func(opts *Options) {
+		opts.PollInterval = interval
+	}
+}
+
+// WithConfirmation sets the acknowledgement level the rows are published with before being
+// marked as sent, iggcon.ConfirmationWrite by default.
+func WithConfirmation(confirmation iggcon.Confirmation) Option {
+	return func(opts *Options) {
+		opts.Confirmation = confirmation
+	}
+}
+
+// WithDeleteSent deletes the published rows instead of setting their sent_at column.
+func WithDeleteSent() Option {
+	return func(opts *Options) {
+		opts.DeleteSent = true
+	}
+}
+
+// WithErrorHandler sets the handler of the errors Run recovers from.
+func WithErrorHandler(handler func(err error)) Option {
+	return func(opts *Options) {
+		opts.ErrorHandler = handler
+	}
+}
+
+// Relay publishes the rows of an outbox table in the order of their IDs, then marks them as sent.
+// A row is published again when the relay stops between publishing it and marking it, with the
+// same message ID, derived from the table and the row ID, for the server or the consumers to
+// deduplicate it. A single relay should run per table, e.g. behind a leader election, as the
+// relays of a table would publish the same rows.
+type Relay struct {
+	db     *sql.DB
+	client messengercli.DataClient
+	table  Table
+	opts   Options
+}
+
+// NewRelay creates a Relay publishing the rows of the table of db through the client.
+func NewRelay(db *sql.DB, client messengercli.DataClient, table Table, options ...Option) (*Relay, error) {
+	if db == nil || client == nil {
+		return nil, errors.New("outbox: the database and the client are required")
+	}
+	opts := GetDefaultOptions()
+	for _, opt := range options {
+		if opt != nil {
+			opt(&opts)
+		}
+	}
+	if opts.BatchSize <= 0 {
+		return nil, errors.New("outbox: batch size must be greater than zero")
+	}
+	return &Relay{db: db, client: client, table: table, opts: opts}, nil
+}
+
+// Run publishes the rows of the table until ctx is done, then returns nil. The failures to read,
+// publish or mark the rows are passed to the ErrorHandler and retried after PollInterval.
+func (r *Relay) Run(ctx context.Context) error {
+	for {
+		published, err := r.RelayOnce(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil && r.opts.ErrorHandler != nil {
+			r.opts.ErrorHandler(err)
+		}
+		if err == nil && published == r.opts.BatchSize {
+			// the table may hold more rows
+			continue
+		}
+		timer := time.NewTimer(r.opts.PollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+	}
+}
+
+// row is an unsent row of the outbox table.
+type row struct {
+	id    int64
+	entry Entry
+}
+
+// RelayOnce publishes a batch of the unsent rows and marks them as sent, returning the number of
+// rows published. When publishing a row fails, the rows published before it are still marked.
+func (r *Relay) RelayOnce(ctx context.Context) (int, error) {
+	rows, err := r.unsent(ctx)
+	if err != nil {
+		return 0, err
+	}
+	published := 0
+	var publishErr error
+	for published < len(rows) {
+		// the consecutive rows of the same topic and key are sent together
+		end := published + 1
+		for end < len(rows) && sameTarget(rows[published].entry, rows[end].entry) {
+			end++
+		}
+		if publishErr = r.publish(rows[published:end]); publishErr != nil {
+			break
+		}
+		published = end
+	}
+	if published > 0 {
+		if err := r.markSent(ctx, rows[:published]); err != nil {
+			return 0, errors.Join(publishErr, err)
+		}
+	}
+	return published, publishErr
+}
+
+func sameTarget(a, b Entry) bool {
+	return a.Stream == b.Stream && a.Topic == b.Topic && bytes.Equal(a.Key, b.Key)
+}
+
+func (r *Relay) unsent(ctx context.Context) ([]row, error) {
+	query := fmt.Sprintf(
+		"SELECT id, stream, topic, message_key, payload FROM %s WHERE sent_at IS NULL ORDER BY id LIMIT %d",
+		r.table.name(), r.opts.BatchSize,
+	)
+	result, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+	var rows []row
+	for result.Next() {
+		var unsent row
+		if err := result.Scan(&unsent.id, &unsent.entry.Stream, &unsent.entry.Topic, &unsent.entry.Key, &unsent.entry.Payload); err != nil {
+			return nil, err
+		}
+		rows = append(rows, unsent)
+	}
+	return rows, result.Err()
+}
+
+func (r *Relay) publish(rows []row) error {
+	target := rows[0].entry
+	streamId, err := iggcon.NewIdentifier(target.Stream)
+	if err != nil {
+		return err
+	}
+	topicId, err := iggcon.NewIdentifier(target.Topic)
+	if err != nil {
+		return err
+	}
+	partitioning := iggcon.None()
+	if target.Key != nil {
+		if partitioning, err = iggcon.EntityIdBytes(target.Key); err != nil {
+			return fmt.Errorf("outbox: row %d: %w", rows[0].id, err)
+		}
+	}
+	messages := make([]iggcon.MessengerMessage, 0, len(rows))
+	for _, unsent := range rows {
+		opts := []iggcon.MessengerMessageOpt{
+			iggcon.WithID(r.messageId(unsent.id)),
+			iggcon.WithUserHeaders(map[iggcon.HeaderKey]iggcon.HeaderValue{
+				{Value: IdHeader}: iggcon.NewUint64HeaderValue(uint64(unsent.id)),
+			}),
+		}
+		if target.Key != nil {
+			opts = append(opts, iggcon.WithKey(target.Key))
+		}
+		message, err := iggcon.NewMessengerMessage(unsent.entry.Payload, opts...)
+		if err != nil {
+			return fmt.Errorf("outbox: row %d: %w", unsent.id, err)
+		}
+		messages = append(messages, message)
+	}
+	return r.client.SendMessagesWithConfirmation(streamId, topicId, partitioning, messages, r.opts.Confirmation)
+}
+
+// messageId derives the ID of the message of a row from the table and the row ID, so a row
+// published twice is sent with the same ID.
+func (r *Relay) messageId(id int64) [16]byte {
+	return uuid.NewSHA1(uuid.NameSpaceOID, []byte(fmt.Sprintf("outbox/%s/%d", r.table.name(), id)))
+}
+
+func (r *Relay) markSent(ctx context.Context, rows []row) error {
+	args := make([]any, 0, len(rows)+1)
+	var query string
+	if r.opts.DeleteSent {
+		query = "DELETE FROM " + r.table.name() + " WHERE id IN (" + r.table.Placeholder.parameters(1, len(rows)) + ")"
+	} else {
+		args = append(args, time.Now().UTC())
+		query = "UPDATE " + r.table.name() + " SET sent_at = " + r.table.Placeholder.parameters(1, 1) +
+			" WHERE id IN (" + r.table.Placeholder.parameters(2, len(rows)) + ")"
+	}
+	for _, sent := range rows {
+		args = append(args, sent.id)
+	}
+	_, err := r.db.ExecContext(ctx, query, args...)
+	return err
+}
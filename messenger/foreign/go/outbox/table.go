@@ -0,0 +1,113 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package outbox implements the transactional outbox pattern: the services insert the messages
+// to publish into an outbox table in the transactions changing their state, and a Relay tails
+// the table and publishes its rows, so a message is published if and only if its transaction
+// committed. The table is accessed through database/sql, with the driver of the database.
+//
+// The outbox table has the following columns, e.g. for PostgreSQL:
+//
+//	CREATE TABLE outbox (
+//		id          BIGSERIAL PRIMARY KEY,
+//		stream      VARCHAR(255) NOT NULL,
+//		topic       VARCHAR(255) NOT NULL,
+//		message_key BYTEA,
+//		payload     BYTEA NOT NULL,
+//		sent_at     TIMESTAMP
+//	);
+//	CREATE INDEX outbox_unsent ON outbox (id) WHERE sent_at IS NULL;
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Placeholder is the syntax of the query parameters of a database.
+type Placeholder int
+
+const (
+	// QuestionPlaceholder is the ? syntax of MySQL and SQLite.
+	QuestionPlaceholder Placeholder = iota
+	// DollarPlaceholder is the $1 syntax of PostgreSQL.
+	DollarPlaceholder
+)
+
+// parameters returns the placeholders of count parameters, numbered from first.
+func (p Placeholder) parameters(first, count int) string {
+	placeholders := make([]string, count)
+	for i := range placeholders {
+		if p == DollarPlaceholder {
+			placeholders[i] = "$" + strconv.Itoa(first+i)
+		} else {
+			placeholders[i] = "?"
+		}
+	}
+	return strings.Join(placeholders, ", ")
+}
+
+// Execer executes a statement, like *sql.DB, *sql.Tx and *sql.Conn.
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// Table is an outbox table.
+type Table struct {
+	// Name is the name of the table, "outbox" when empty.
+	Name string
+	// Placeholder is the syntax of the query parameters of the database.
+	Placeholder Placeholder
+}
+
+// Entry is a message to publish, stored in a row of the outbox table.
+type Entry struct {
+	// Stream is the name of the stream to publish to.
+	Stream string
+	// Topic is the name of the topic to publish to.
+	Topic string
+	// Key, when not nil, is the key of the message, which is sent with a MessageKey
+	// partitioning to keep the order of the entries sharing a key.
+	Key []byte
+	// Payload is the payload of the message.
+	Payload []byte
+}
+
+func (t Table) name() string {
+	if t.Name == "" {
+		return "outbox"
+	}
+	return t.Name
+}
+
+// Insert inserts the entry into the table, within the transaction of tx.
+func (t Table) Insert(ctx context.Context, tx Execer, entry Entry) error {
+	if entry.Stream == "" || entry.Topic == "" {
+		return errors.New("outbox: the stream and the topic are required")
+	}
+	if len(entry.Payload) == 0 {
+		return errors.New("outbox: the payload is required")
+	}
+	_, err := tx.ExecContext(ctx,
+		"INSERT INTO "+t.name()+" (stream, topic, message_key, payload) VALUES ("+t.Placeholder.parameters(1, 4)+")",
+		entry.Stream, entry.Topic, entry.Key, entry.Payload,
+	)
+	return err
+}
@@ -0,0 +1,221 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package parquet
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/apache/messenger/foreign/go/codec"
+	"github.com/apache/messenger/foreign/go/connector"
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengercli"
+)
+
+// RowMapper converts a message into a row of the schema of an Exporter, nil to skip the message.
+type RowMapper func(message iggcon.ReceivedMessage) ([]any, error)
+
+// Range is a range of the messages of a partition.
+type Range struct {
+	PartitionId uint32
+	// From is the offset of the first message.
+	From uint64
+	// To is the offset following the last message, zero for the messages appended when the
+	// export starts.
+	To uint64
+}
+
+// Exporter exports the messages of a topic as Parquet files, a row per message.
+type Exporter struct {
+	schema Schema
+	rows   RowMapper
+	opts   []Option
+}
+
+// NewExporter creates an Exporter writing files of the schema, the messages being converted into
+// rows with the mapper.
+func NewExporter(schema Schema, rows RowMapper, options ...Option) (*Exporter, error) {
+	if err := schema.validate(); err != nil {
+		return nil, err
+	}
+	if rows == nil {
+		return nil, errors.New("parquet: row mapper is required")
+	}
+	return &Exporter{schema: schema, rows: rows, opts: options}, nil
+}
+
+// NewSerdeExporter creates an Exporter of the values of type T the messages of a topic are
+// encoded as by the serde, with the schema returned by InferSchema.
+func NewSerdeExporter[T any](serde *codec.Serde, streamId, topicId iggcon.Identifier, options ...Option) (*Exporter, error) {
+	schema, rows, err := inferRows[T]()
+	if err != nil {
+		return nil, err
+	}
+	return NewExporter(schema, func(message iggcon.ReceivedMessage) ([]any, error) {
+		value, err := codec.Decode[T](serde, streamId, topicId, message.Message)
+		if err != nil {
+			return nil, err
+		}
+		return rows(value)
+	}, options...)
+}
+
+// JSONRows converts the payloads, JSON objects, into rows of the schema, taking every field from
+// the member of the same name. The Binary fields are base64 strings, as encoded by encoding/json,
+// and the Timestamp fields RFC 3339 strings.
+func JSONRows(schema Schema) RowMapper {
+	return func(message iggcon.ReceivedMessage) ([]any, error) {
+		decoder := json.NewDecoder(bytes.NewReader(message.Message.Payload))
+		decoder.UseNumber()
+		var object map[string]any
+		if err := decoder.Decode(&object); err != nil {
+			return nil, fmt.Errorf("parquet: message %d: %w", message.Message.Header.Offset, err)
+		}
+		row := make([]any, len(schema.Fields))
+		for i, field := range schema.Fields {
+			value, err := jsonValue(field, object[field.Name])
+			if err != nil {
+				return nil, fmt.Errorf("parquet: message %d: %w", message.Message.Header.Offset, err)
+			}
+			row[i] = value
+		}
+		return row, nil
+	}
+}
+
+func jsonValue(field Field, value any) (any, error) {
+	invalid := fmt.Errorf("field %s of type %s cannot hold %v", field.Name, field.Type, value)
+	switch value := value.(type) {
+	case nil:
+		return nil, nil
+	case json.Number:
+		switch field.Type {
+		case Int32, Int64:
+			return value.Int64()
+		case Float32, Float64:
+			return value.Float64()
+		}
+	case string:
+		switch field.Type {
+		case Utf8:
+			return value, nil
+		case Binary:
+			return base64.StdEncoding.DecodeString(value)
+		case Timestamp:
+			return time.Parse(time.RFC3339Nano, value)
+		}
+	case bool:
+		if field.Type == Boolean {
+			return value, nil
+		}
+	}
+	return nil, invalid
+}
+
+// Export writes the messages of the range of a partition of the topic to w as a Parquet file,
+// returning the number of rows written. The messages are polled without storing any offset.
+func (e *Exporter) Export(ctx context.Context, client messengercli.DataClient, streamId, topicId iggcon.Identifier, r Range, w io.Writer) (int, error) {
+	writer, err := NewWriter(w, e.schema, e.opts...)
+	if err != nil {
+		return 0, err
+	}
+	rows := 0
+	next, end := r.From, r.To
+	partitionId := r.PartitionId
+	for end == 0 || next < end {
+		if err := ctx.Err(); err != nil {
+			return rows, err
+		}
+		polled, err := client.PollMessages(streamId, topicId, iggcon.DefaultConsumer(), iggcon.OffsetPollingStrategy(next), writer.opts.BatchSize, false, &partitionId)
+		if err != nil {
+			return rows, err
+		}
+		if polled == nil || len(polled.Messages) == 0 {
+			break
+		}
+		if end == 0 {
+			end = polled.CurrentOffset + 1
+		}
+		for _, message := range polled.Messages {
+			if message.Header.Offset >= end {
+				break
+			}
+			row, err := e.rows(iggcon.ReceivedMessage{Message: message, CurrentOffset: polled.CurrentOffset, PartitionId: polled.PartitionId})
+			if err != nil {
+				return rows, err
+			}
+			if row == nil {
+				continue
+			}
+			if err := writer.Write(row); err != nil {
+				return rows, fmt.Errorf("parquet: message %d: %w", message.Header.Offset, err)
+			}
+			rows++
+		}
+		next = polled.Messages[len(polled.Messages)-1].Header.Offset + 1
+	}
+	return rows, writer.Close()
+}
+
+// Format returns the connector.ObjectFormat storing the objects of a connector.ObjectSink as
+// Parquet files of the exporter, e.g. to archive a topic to S3 as Parquet.
+func (e *Exporter) Format() connector.ObjectFormat {
+	return objectFormat{e}
+}
+
+type objectFormat struct {
+	exporter *Exporter
+}
+
+func (objectFormat) Extension() string {
+	return ".parquet"
+}
+
+func (objectFormat) ContentType() string {
+	return "application/vnd.apache.parquet"
+}
+
+func (f objectFormat) Encode(messages []iggcon.ReceivedMessage) ([]byte, error) {
+	var file bytes.Buffer
+	writer, err := NewWriter(&file, f.exporter.schema, f.exporter.opts...)
+	if err != nil {
+		return nil, err
+	}
+	for _, message := range messages {
+		row, err := f.exporter.rows(message)
+		if err != nil {
+			return nil, err
+		}
+		if row == nil {
+			continue
+		}
+		if err := writer.Write(row); err != nil {
+			return nil, fmt.Errorf("parquet: message %d: %w", message.Message.Header.Offset, err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return file.Bytes(), nil
+}
@@ -0,0 +1,271 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package parquet
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/apache/messenger/foreign/go/codec"
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengertest"
+	"github.com/klauspost/compress/s2"
+	"github.com/klauspost/compress/zstd"
+)
+
+type order struct {
+	Id       int64     `json:"id"`
+	Customer string    `json:"customer"`
+	Total    float64   `json:"total"`
+	Paid     bool      `json:"paid"`
+	Note     *string   `json:"note,omitempty"`
+	At       time.Time `json:"at"`
+}
+
+// readThrift decodes a Thrift compact struct into its fields by ID, for the tests to check the
+// metadata written.
+func readThrift(t *testing.T, r *bufio.Reader) map[int16]any {
+	fields := map[int16]any{}
+	last := int16(0)
+	for {
+		header, err := r.ReadByte()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if header == 0 {
+			return fields
+		}
+		id := last + int16(header>>4)
+		if header>>4 == 0 {
+			value, _ := binary.ReadVarint(r)
+			id = int16(value)
+		}
+		last = id
+		fields[id] = readThriftValue(t, r, header&0x0f)
+	}
+}
+
+func readThriftValue(t *testing.T, r *bufio.Reader, kind byte) any {
+	switch kind {
+	case thriftTrue:
+		return true
+	case thriftFalse:
+		return false
+	case thriftI32, thriftI64:
+		value, err := binary.ReadVarint(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return value
+	case thriftBinary:
+		length, _ := binary.ReadUvarint(r)
+		value := make([]byte, length)
+		if _, err := r.Read(value); err != nil && length > 0 {
+			t.Fatal(err)
+		}
+		return string(value)
+	case thriftList:
+		header, _ := r.ReadByte()
+		size := uint64(header >> 4)
+		if size == 15 {
+			size, _ = binary.ReadUvarint(r)
+		}
+		list := make([]any, size)
+		for i := range list {
+			list[i] = readThriftValue(t, r, header&0x0f)
+		}
+		return list
+	case thriftStruct:
+		return readThrift(t, r)
+	}
+	t.Fatalf("unexpected thrift type %d", kind)
+	return nil
+}
+
+// readFooter checks the magic bytes of the file and returns its FileMetaData.
+func readFooter(t *testing.T, file []byte) map[int16]any {
+	if !bytes.HasPrefix(file, []byte(magic)) || !bytes.HasSuffix(file, []byte(magic)) {
+		t.Fatal("expected the file to start and end with the magic bytes")
+	}
+	length := binary.LittleEndian.Uint32(file[len(file)-8:])
+	footer := file[len(file)-8-int(length) : len(file)-8]
+	return readThrift(t, bufio.NewReader(bytes.NewReader(footer)))
+}
+
+// readPage returns the decompressed contents of the data page starting at the offset.
+func readPage(t *testing.T, file []byte, offset int64, compression Compression) []byte {
+	r := bytes.NewReader(file[offset:])
+	buffered := bufio.NewReader(r)
+	header := readThrift(t, buffered)
+	compressed := make([]byte, header[3].(int64))
+	if _, err := buffered.Read(compressed); err != nil {
+		t.Fatal(err)
+	}
+	var page []byte
+	var err error
+	switch compression {
+	case Snappy:
+		page, err = s2.Decode(nil, compressed)
+	case Zstd:
+		var decoder *zstd.Decoder
+		if decoder, err = zstd.NewReader(nil); err == nil {
+			page, err = decoder.DecodeAll(compressed, nil)
+		}
+	default:
+		page = compressed
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if int64(len(page)) != header[2].(int64) {
+		t.Fatalf("expected a page of %d bytes, got %d", header[2], len(page))
+	}
+	return page
+}
+
+// columnOffsets returns the offsets of the column chunks of the first row group.
+func columnOffsets(footer map[int16]any) []int64 {
+	var offsets []int64
+	for _, chunk := range footer[4].([]any)[0].(map[int16]any)[1].([]any) {
+		offsets = append(offsets, chunk.(map[int16]any)[2].(int64))
+	}
+	return offsets
+}
+
+func TestExporter_Serde(t *testing.T) {
+	client := messengertest.NewClient()
+	if _, err := client.CreateStream("shop", nil); err != nil {
+		t.Fatal(err)
+	}
+	streamId, topicId := iggcon.MustIdentifier("shop"), iggcon.MustIdentifier("orders")
+	if _, err := client.CreateTopic(streamId, "orders", 1, iggcon.CompressionAlgorithmNone, 0, 0, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	at := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	note := "gift"
+	serde := codec.NewSerde()
+	for i, customer := range []string{"ada", "bob", "eve", "joe"} {
+		value := order{Id: int64(i), Customer: customer, Total: float64(i) + 0.5, Paid: i%2 == 0, At: at.Add(time.Duration(i) * time.Second)}
+		if i == 2 {
+			value.Note = &note
+		}
+		if err := serde.Send(client, streamId, topicId, iggcon.None(), value); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	exporter, err := NewSerdeExporter[order](serde, streamId, topicId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantSchema := Schema{Fields: []Field{
+		{Name: "id", Type: Int64},
+		{Name: "customer", Type: Utf8},
+		{Name: "total", Type: Float64},
+		{Name: "paid", Type: Boolean},
+		{Name: "note", Type: Utf8, Nullable: true},
+		{Name: "at", Type: Timestamp},
+	}}
+	if !reflect.DeepEqual(exporter.schema, wantSchema) {
+		t.Fatalf("expected schema %+v, got %+v", wantSchema, exporter.schema)
+	}
+
+	var file bytes.Buffer
+	rows, err := exporter.Export(context.Background(), client, streamId, topicId, Range{PartitionId: 1, From: 1, To: 4}, &file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rows != 3 {
+		t.Fatalf("expected 3 rows, got %d", rows)
+	}
+
+	footer := readFooter(t, file.Bytes())
+	if footer[3].(int64) != 3 {
+		t.Fatalf("expected 3 rows in the footer, got %v", footer[3])
+	}
+	var names []string
+	for _, element := range footer[2].([]any) {
+		names = append(names, element.(map[int16]any)[4].(string))
+	}
+	if want := []string{"schema", "id", "customer", "total", "paid", "note", "at"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("expected schema elements %v, got %v", want, names)
+	}
+
+	offsets := columnOffsets(footer)
+	customers := readPage(t, file.Bytes(), offsets[1], Snappy)
+	var values []string
+	for len(customers) > 0 {
+		length := binary.LittleEndian.Uint32(customers)
+		values = append(values, string(customers[4:4+length]))
+		customers = customers[4+length:]
+	}
+	if want := []string{"bob", "eve", "joe"}; !reflect.DeepEqual(values, want) {
+		t.Fatalf("expected customers %v, got %v", want, values)
+	}
+	// the definition levels of the notes are runs of 1 null, 1 value and 1 null
+	notes := readPage(t, file.Bytes(), offsets[4], Snappy)
+	if want := append([]byte{6, 0, 0, 0, 2, 0, 2, 1, 2, 0, 4, 0, 0, 0}, "gift"...); !bytes.Equal(notes, want) {
+		t.Fatalf("expected notes %v, got %v", want, notes)
+	}
+	if paid := readPage(t, file.Bytes(), offsets[3], Snappy); !bytes.Equal(paid, []byte{0b010}) {
+		t.Fatalf("expected the paid flags to be bit-packed, got %v", paid)
+	}
+}
+
+func TestExporter_JSONRows(t *testing.T) {
+	client := messengertest.NewClient()
+	if _, err := client.CreateStream("metrics", nil); err != nil {
+		t.Fatal(err)
+	}
+	streamId, topicId := iggcon.MustIdentifier("metrics"), iggcon.MustIdentifier("cpu")
+	if _, err := client.CreateTopic(streamId, "cpu", 1, iggcon.CompressionAlgorithmNone, 0, 0, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	for _, payload := range []string{`{"host":"a","usage":0.25}`, `{"host":"b","usage":1}`} {
+		message, _ := iggcon.NewMessengerMessage([]byte(payload))
+		if err := client.SendMessages(streamId, topicId, iggcon.None(), []iggcon.MessengerMessage{message}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	schema := Schema{Fields: []Field{{Name: "host", Type: Utf8}, {Name: "usage", Type: Float32}}}
+	exporter, err := NewExporter(schema, JSONRows(schema), WithCompression(Zstd), WithRowGroupSize(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var file bytes.Buffer
+	if rows, err := exporter.Export(context.Background(), client, streamId, topicId, Range{PartitionId: 1}, &file); err != nil || rows != 2 {
+		t.Fatalf("expected 2 rows, got %d, %v", rows, err)
+	}
+
+	// every row is a row group of its own
+	footer := readFooter(t, file.Bytes())
+	groups := footer[4].([]any)
+	if len(groups) != 2 {
+		t.Fatalf("expected 2 row groups, got %d", len(groups))
+	}
+	usage := readPage(t, file.Bytes(), columnOffsets(map[int16]any{4: groups[1:]})[1], Zstd)
+	if got := math.Float32frombits(binary.LittleEndian.Uint32(usage)); got != 1 {
+		t.Fatalf("expected a usage of 1, got %v", got)
+	}
+}
@@ -0,0 +1,285 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package parquet writes Parquet files, e.g. to export the messages of a topic for the analytics
+// tools of a data lake. The files have a flat schema modeled on the Arrow schemas, a column per
+// field, written with the PLAIN encoding and optionally compressed.
+package parquet
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Type is the type of a column, named after its Arrow data type.
+type Type uint8
+
+const (
+	// Boolean values are bools.
+	Boolean Type = iota + 1
+	// Int32 values are integers fitting 32 bits.
+	Int32
+	// Int64 values are integers fitting 64 bits.
+	Int64
+	// Float32 values are float32 or float64.
+	Float32
+	// Float64 values are float32 or float64.
+	Float64
+	// Utf8 values are strings.
+	Utf8
+	// Binary values are byte slices or strings.
+	Binary
+	// Timestamp values are time.Time, stored in microseconds since the Unix epoch.
+	Timestamp
+)
+
+func (t Type) String() string {
+	switch t {
+	case Boolean:
+		return "bool"
+	case Int32:
+		return "int32"
+	case Int64:
+		return "int64"
+	case Float32:
+		return "float32"
+	case Float64:
+		return "float64"
+	case Utf8:
+		return "utf8"
+	case Binary:
+		return "binary"
+	case Timestamp:
+		return "timestamp[us, tz=UTC]"
+	}
+	return "unknown"
+}
+
+// Field is a column of a Schema.
+type Field struct {
+	Name string
+	Type Type
+	// Nullable fields accept nil values.
+	Nullable bool
+}
+
+// Schema is the list of the columns of a file.
+type Schema struct {
+	Fields []Field
+}
+
+func (s Schema) validate() error {
+	if len(s.Fields) == 0 {
+		return errors.New("parquet: schema has no field")
+	}
+	names := map[string]bool{}
+	for _, field := range s.Fields {
+		if field.Name == "" || names[field.Name] {
+			return fmt.Errorf("parquet: invalid or duplicate field name %q", field.Name)
+		}
+		if field.Type < Boolean || field.Type > Timestamp {
+			return fmt.Errorf("parquet: field %s has an invalid type", field.Name)
+		}
+		names[field.Name] = true
+	}
+	return nil
+}
+
+// InferSchema infers the schema of the values of type T, which is either a struct, or a pointer
+// to a struct or to a Protobuf message, like the values decoded by a codec.Serde. The columns of a
+// struct are its exported fields of scalar types, named after their json tags, and those of a
+// Protobuf message its scalar fields, the enums being stored as their names. The pointers and the
+// Protobuf fields with presence are nullable.
+func InferSchema[T any]() (Schema, error) {
+	schema, _, err := inferRows[T]()
+	return schema, err
+}
+
+// inferRows infers the schema of the values of type T along with the function converting a value
+// into a row.
+func inferRows[T any]() (Schema, func(value T) ([]any, error), error) {
+	var zero T
+	if message, ok := any(zero).(proto.Message); ok {
+		return inferProtoRows[T](message.ProtoReflect().Descriptor())
+	}
+	t := reflect.TypeFor[T]()
+	pointer := t.Kind() == reflect.Pointer
+	if pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return Schema{}, nil, fmt.Errorf("parquet: cannot infer the schema of %s", t)
+	}
+	var schema Schema
+	var indexes [][]int
+	for _, field := range reflect.VisibleFields(t) {
+		if !field.IsExported() || field.Anonymous {
+			continue
+		}
+		name := field.Name
+		if tag, ok := field.Tag.Lookup("json"); ok {
+			tagName, _, _ := strings.Cut(tag, ",")
+			if tagName == "-" {
+				continue
+			}
+			if tagName != "" {
+				name = tagName
+			}
+		}
+		fieldType, nullable := field.Type, false
+		if fieldType.Kind() == reflect.Pointer {
+			fieldType, nullable = fieldType.Elem(), true
+		}
+		columnType, ok := goType(fieldType)
+		if !ok {
+			return Schema{}, nil, fmt.Errorf("parquet: field %s of %s has the unsupported type %s", field.Name, t, field.Type)
+		}
+		schema.Fields = append(schema.Fields, Field{Name: name, Type: columnType, Nullable: nullable})
+		indexes = append(indexes, field.Index)
+	}
+	if err := schema.validate(); err != nil {
+		return Schema{}, nil, err
+	}
+	rows := func(value T) ([]any, error) {
+		v := reflect.ValueOf(value)
+		if pointer {
+			if v.IsNil() {
+				return nil, errors.New("parquet: nil value")
+			}
+			v = v.Elem()
+		}
+		row := make([]any, len(indexes))
+		for i, index := range indexes {
+			field, err := v.FieldByIndexErr(index)
+			if err != nil {
+				// a field promoted through a nil embedded pointer
+				continue
+			}
+			if field.Kind() == reflect.Pointer {
+				if field.IsNil() {
+					continue
+				}
+				field = field.Elem()
+			}
+			row[i] = field.Interface()
+		}
+		return row, nil
+	}
+	return schema, rows, nil
+}
+
+var timeType = reflect.TypeFor[time.Time]()
+
+// goType returns the column type of a Go type.
+func goType(t reflect.Type) (Type, bool) {
+	if t == timeType {
+		return Timestamp, true
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return Boolean, true
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return Int32, true
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return Int64, true
+	case reflect.Float32:
+		return Float32, true
+	case reflect.Float64:
+		return Float64, true
+	case reflect.String:
+		return Utf8, true
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return Binary, true
+		}
+	}
+	return 0, false
+}
+
+func inferProtoRows[T any](descriptor protoreflect.MessageDescriptor) (Schema, func(value T) ([]any, error), error) {
+	var schema Schema
+	fields := descriptor.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		field := fields.Get(i)
+		columnType, ok := protoType(field)
+		if !ok {
+			return Schema{}, nil, fmt.Errorf("parquet: field %s of %s is not a scalar", field.Name(), descriptor.FullName())
+		}
+		schema.Fields = append(schema.Fields, Field{Name: string(field.Name()), Type: columnType, Nullable: field.HasPresence()})
+	}
+	if err := schema.validate(); err != nil {
+		return Schema{}, nil, err
+	}
+	rows := func(value T) ([]any, error) {
+		message := any(value).(proto.Message).ProtoReflect()
+		row := make([]any, fields.Len())
+		for i := range row {
+			field := fields.Get(i)
+			if field.HasPresence() && !message.Has(field) {
+				continue
+			}
+			row[i] = protoValue(field, message.Get(field))
+		}
+		return row, nil
+	}
+	return schema, rows, nil
+}
+
+// protoType returns the column type of a Protobuf field.
+func protoType(field protoreflect.FieldDescriptor) (Type, bool) {
+	if field.IsList() || field.IsMap() {
+		return 0, false
+	}
+	switch field.Kind() {
+	case protoreflect.BoolKind:
+		return Boolean, true
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return Int32, true
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Int64Kind, protoreflect.Sint64Kind,
+		protoreflect.Sfixed64Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return Int64, true
+	case protoreflect.FloatKind:
+		return Float32, true
+	case protoreflect.DoubleKind:
+		return Float64, true
+	case protoreflect.StringKind, protoreflect.EnumKind:
+		return Utf8, true
+	case protoreflect.BytesKind:
+		return Binary, true
+	}
+	return 0, false
+}
+
+func protoValue(field protoreflect.FieldDescriptor, value protoreflect.Value) any {
+	switch field.Kind() {
+	case protoreflect.EnumKind:
+		number := value.Enum()
+		if enumValue := field.Enum().Values().ByNumber(number); enumValue != nil {
+			return string(enumValue.Name())
+		}
+		return fmt.Sprint(int32(number))
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return value.Uint()
+	}
+	return value.Interface()
+}
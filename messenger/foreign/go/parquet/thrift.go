@@ -0,0 +1,112 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// Types of the Thrift compact protocol encoding the metadata of the files.
+const (
+	thriftTrue   = 1
+	thriftFalse  = 2
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftWriter encodes Thrift structs with the compact protocol.
+type thriftWriter struct {
+	buffer bytes.Buffer
+	// fields is the ID of the last field written in every open struct.
+	fields []int16
+}
+
+func (w *thriftWriter) beginStruct() {
+	w.fields = append(w.fields, 0)
+}
+
+func (w *thriftWriter) endStruct() {
+	w.buffer.WriteByte(0)
+	w.fields = w.fields[:len(w.fields)-1]
+}
+
+func (w *thriftWriter) field(id int16, kind byte) {
+	last := &w.fields[len(w.fields)-1]
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		w.buffer.WriteByte(byte(delta)<<4 | kind)
+	} else {
+		w.buffer.WriteByte(kind)
+		w.varint(int64(id))
+	}
+	*last = id
+}
+
+func (w *thriftWriter) varint(value int64) {
+	w.buffer.Write(binary.AppendVarint(nil, value))
+}
+
+func (w *thriftWriter) uvarint(value uint64) {
+	w.buffer.Write(binary.AppendUvarint(nil, value))
+}
+
+func (w *thriftWriter) boolField(id int16, value bool) {
+	if value {
+		w.field(id, thriftTrue)
+	} else {
+		w.field(id, thriftFalse)
+	}
+}
+
+func (w *thriftWriter) i32Field(id int16, value int32) {
+	w.field(id, thriftI32)
+	w.varint(int64(value))
+}
+
+func (w *thriftWriter) i64Field(id int16, value int64) {
+	w.field(id, thriftI64)
+	w.varint(value)
+}
+
+func (w *thriftWriter) stringField(id int16, value string) {
+	w.field(id, thriftBinary)
+	w.string(value)
+}
+
+func (w *thriftWriter) string(value string) {
+	w.uvarint(uint64(len(value)))
+	w.buffer.WriteString(value)
+}
+
+func (w *thriftWriter) structField(id int16) {
+	w.field(id, thriftStruct)
+	w.beginStruct()
+}
+
+func (w *thriftWriter) listField(id int16, kind byte, size int) {
+	w.field(id, thriftList)
+	if size < 15 {
+		w.buffer.WriteByte(byte(size)<<4 | kind)
+	} else {
+		w.buffer.WriteByte(0xf0 | kind)
+		w.uvarint(uint64(size))
+	}
+}
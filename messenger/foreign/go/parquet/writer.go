@@ -0,0 +1,487 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"time"
+
+	"github.com/klauspost/compress/s2"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compression is the codec compressing the pages of a file.
+type Compression uint8
+
+// The values are those of the Parquet format.
+const (
+	Uncompressed Compression = 0
+	Snappy       Compression = 1
+	Zstd         Compression = 6
+)
+
+type Option func(opts *Options)
+
+type Options struct {
+	// Compression compresses the pages.
+	Compression Compression
+	// RowGroupSize is the maximum number of rows of a row group, which is buffered in memory.
+	RowGroupSize int
+	// BatchSize is the number of messages an Exporter polls in a single request.
+	BatchSize uint32
+}
+
+func GetDefaultOptions() Options {
+	return Options{
+		Compression:  Snappy,
+		RowGroupSize: 100_000,
+		BatchSize:    1000,
+	}
+}
+
+// WithCompression sets the codec compressing the pages, Snappy by default.
+func WithCompression(compression Compression) Option {
+	return func(opts *Options) {
+		opts.Compression = compression
+	}
+}
+
+// WithRowGroupSize sets the maximum number of rows of a row group, 100 000 by default.
+func WithRowGroupSize(rows int) Option {
+	return func(opts *Options) {
+		opts.RowGroupSize = rows
+	}
+}
+
+// WithBatchSize sets the number of messages an Exporter polls in a single request, 1000 by
+// default.
+func WithBatchSize(size uint32) Option {
+	return func(opts *Options) {
+		opts.BatchSize = size
+	}
+}
+
+// magic starts and ends the Parquet files.
+const magic = "PAR1"
+
+// Physical types, converted types, encodings and page types of the Parquet format.
+const (
+	physicalBoolean   = 0
+	physicalInt32     = 1
+	physicalInt64     = 2
+	physicalFloat     = 4
+	physicalDouble    = 5
+	physicalByteArray = 6
+
+	convertedUTF8            = 0
+	convertedTimestampMicros = 10
+
+	repetitionRequired = 0
+	repetitionOptional = 1
+
+	encodingPlain = 0
+	encodingRLE   = 3
+
+	pageData = 0
+)
+
+// Writer writes a Parquet file, buffering the rows of a row group in memory. Close writes the
+// footer, without which the file is unreadable.
+type Writer struct {
+	w      io.Writer
+	schema Schema
+	opts   Options
+	zstd   *zstd.Encoder
+
+	offset    int64
+	columns   []column
+	rows      int
+	numRows   int64
+	rowGroups []rowGroup
+	closed    bool
+}
+
+// column buffers the values of a column of the current row group.
+type column struct {
+	values bytes.Buffer
+	// present tells which rows have a value, for a nullable column.
+	present []bool
+	bools   []bool
+}
+
+// rowGroup is the metadata of a written row group.
+type rowGroup struct {
+	columns   []columnChunk
+	numRows   int64
+	totalSize int64
+}
+
+// columnChunk is the metadata of a written column chunk.
+type columnChunk struct {
+	offset           int64
+	numValues        int64
+	uncompressedSize int64
+	compressedSize   int64
+}
+
+// NewWriter creates a Writer writing a file of the schema to w.
+func NewWriter(w io.Writer, schema Schema, options ...Option) (*Writer, error) {
+	if err := schema.validate(); err != nil {
+		return nil, err
+	}
+	opts := GetDefaultOptions()
+	for _, opt := range options {
+		if opt != nil {
+			opt(&opts)
+		}
+	}
+	if opts.RowGroupSize <= 0 {
+		return nil, errors.New("parquet: row group size must be greater than zero")
+	}
+	writer := &Writer{w: w, schema: schema, opts: opts, columns: make([]column, len(schema.Fields))}
+	switch opts.Compression {
+	case Uncompressed, Snappy:
+	case Zstd:
+		encoder, err := zstd.NewWriter(nil)
+		if err != nil {
+			return nil, err
+		}
+		writer.zstd = encoder
+	default:
+		return nil, fmt.Errorf("parquet: unsupported compression %d", opts.Compression)
+	}
+	if err := writer.write([]byte(magic)); err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
+
+// Write appends a row, holding a value per field of the schema, nil for a null.
+func (w *Writer) Write(row []any) error {
+	if w.closed {
+		return errors.New("parquet: writer is closed")
+	}
+	if len(row) != len(w.schema.Fields) {
+		return fmt.Errorf("parquet: row has %d values for %d fields", len(row), len(w.schema.Fields))
+	}
+	// the values are checked before any is buffered, so a failed Write leaves no partial row
+	values := make([]any, len(row))
+	for i, field := range w.schema.Fields {
+		value, err := normalize(field, row[i])
+		if err != nil {
+			return err
+		}
+		values[i] = value
+	}
+	for i, field := range w.schema.Fields {
+		w.columns[i].append(field, values[i])
+	}
+	w.rows++
+	if w.rows >= w.opts.RowGroupSize {
+		return w.flush()
+	}
+	return nil
+}
+
+// Close writes the buffered rows and the footer. It does not close the underlying writer.
+func (w *Writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	if w.zstd != nil {
+		defer w.zstd.Close()
+	}
+	if w.rows > 0 {
+		if err := w.flush(); err != nil {
+			return err
+		}
+	}
+	footer := w.footer()
+	footer = binary.LittleEndian.AppendUint32(footer, uint32(len(footer)))
+	return w.write(append(footer, magic...))
+}
+
+// normalize converts a value to the Go type buffered for the type of the field: bool, int32,
+// int64, float32, float64, []byte or nil.
+func normalize(field Field, value any) (any, error) {
+	if value == nil {
+		if !field.Nullable {
+			return nil, fmt.Errorf("parquet: field %s is not nullable", field.Name)
+		}
+		return nil, nil
+	}
+	v := reflect.ValueOf(value)
+	invalid := fmt.Errorf("parquet: field %s of type %s cannot hold %T %v", field.Name, field.Type, value, value)
+	switch field.Type {
+	case Boolean:
+		if v.Kind() == reflect.Bool {
+			return v.Bool(), nil
+		}
+	case Int32, Int64:
+		var integer int64
+		switch {
+		case v.CanInt():
+			integer = v.Int()
+		case v.CanUint() && v.Uint() <= math.MaxInt64:
+			integer = int64(v.Uint())
+		default:
+			return nil, invalid
+		}
+		if field.Type == Int64 {
+			return integer, nil
+		}
+		if integer < math.MinInt32 || integer > math.MaxInt32 {
+			return nil, invalid
+		}
+		return int32(integer), nil
+	case Float32, Float64:
+		if !v.CanFloat() {
+			return nil, invalid
+		}
+		if field.Type == Float32 {
+			return float32(v.Float()), nil
+		}
+		return v.Float(), nil
+	case Utf8, Binary:
+		if v.Kind() == reflect.String {
+			return []byte(v.String()), nil
+		}
+		if field.Type == Binary && v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+			return v.Bytes(), nil
+		}
+	case Timestamp:
+		if timestamp, ok := value.(time.Time); ok {
+			return timestamp.UnixMicro(), nil
+		}
+	}
+	return nil, invalid
+}
+
+func (c *column) append(field Field, value any) {
+	if field.Nullable {
+		c.present = append(c.present, value != nil)
+	}
+	switch value := value.(type) {
+	case bool:
+		c.bools = append(c.bools, value)
+	case int32:
+		c.values.Write(binary.LittleEndian.AppendUint32(nil, uint32(value)))
+	case int64:
+		c.values.Write(binary.LittleEndian.AppendUint64(nil, uint64(value)))
+	case float32:
+		c.values.Write(binary.LittleEndian.AppendUint32(nil, math.Float32bits(value)))
+	case float64:
+		c.values.Write(binary.LittleEndian.AppendUint64(nil, math.Float64bits(value)))
+	case []byte:
+		c.values.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(value))))
+		c.values.Write(value)
+	}
+}
+
+// flush writes the buffered rows as a row group, a data page per column.
+func (w *Writer) flush() error {
+	group := rowGroup{numRows: int64(w.rows)}
+	for i, field := range w.schema.Fields {
+		page := w.columns[i].page(field)
+		compressed := w.compress(page)
+		header := pageHeader(w.rows, len(page), len(compressed))
+		chunk := columnChunk{
+			offset:           w.offset,
+			numValues:        int64(w.rows),
+			uncompressedSize: int64(len(header) + len(page)),
+			compressedSize:   int64(len(header) + len(compressed)),
+		}
+		if err := w.write(header); err != nil {
+			return err
+		}
+		if err := w.write(compressed); err != nil {
+			return err
+		}
+		group.columns = append(group.columns, chunk)
+		group.totalSize += chunk.uncompressedSize
+		w.columns[i] = column{}
+	}
+	w.rowGroups = append(w.rowGroups, group)
+	w.numRows += int64(w.rows)
+	w.rows = 0
+	return nil
+}
+
+// page returns the contents of the data page of the column: the definition levels of a nullable
+// column, then the values.
+func (c *column) page(field Field) []byte {
+	var page []byte
+	if field.Nullable {
+		levels := rleLevels(c.present)
+		page = binary.LittleEndian.AppendUint32(page, uint32(len(levels)))
+		page = append(page, levels...)
+	}
+	if field.Type == Boolean {
+		packed := make([]byte, (len(c.bools)+7)/8)
+		for i, value := range c.bools {
+			if value {
+				packed[i/8] |= 1 << (i % 8)
+			}
+		}
+		return append(page, packed...)
+	}
+	return append(page, c.values.Bytes()...)
+}
+
+// rleLevels encodes the definition levels, 1 for a value and 0 for a null, as runs of the RLE /
+// bit-packing hybrid encoding with a bit width of 1.
+func rleLevels(present []bool) []byte {
+	var levels []byte
+	for start := 0; start < len(present); {
+		end := start + 1
+		for end < len(present) && present[end] == present[start] {
+			end++
+		}
+		levels = binary.AppendUvarint(levels, uint64(end-start)<<1)
+		if present[start] {
+			levels = append(levels, 1)
+		} else {
+			levels = append(levels, 0)
+		}
+		start = end
+	}
+	return levels
+}
+
+func (w *Writer) compress(page []byte) []byte {
+	switch w.opts.Compression {
+	case Snappy:
+		return s2.EncodeSnappy(nil, page)
+	case Zstd:
+		return w.zstd.EncodeAll(page, nil)
+	}
+	return page
+}
+
+func (w *Writer) write(data []byte) error {
+	n, err := w.w.Write(data)
+	w.offset += int64(n)
+	return err
+}
+
+// pageHeader encodes the PageHeader of a data page.
+func pageHeader(values, uncompressedSize, compressedSize int) []byte {
+	var t thriftWriter
+	t.beginStruct()
+	t.i32Field(1, pageData)
+	t.i32Field(2, int32(uncompressedSize))
+	t.i32Field(3, int32(compressedSize))
+	t.structField(5)
+	t.i32Field(1, int32(values))
+	t.i32Field(2, encodingPlain)
+	t.i32Field(3, encodingRLE)
+	t.i32Field(4, encodingRLE)
+	t.endStruct()
+	t.endStruct()
+	return t.buffer.Bytes()
+}
+
+// footer encodes the FileMetaData of the file.
+func (w *Writer) footer() []byte {
+	var t thriftWriter
+	t.beginStruct()
+	t.i32Field(1, 1)
+
+	t.listField(2, thriftStruct, len(w.schema.Fields)+1)
+	t.beginStruct()
+	t.stringField(4, "schema")
+	t.i32Field(5, int32(len(w.schema.Fields)))
+	t.endStruct()
+	for _, field := range w.schema.Fields {
+		physical, converted := field.Type.physical()
+		t.beginStruct()
+		t.i32Field(1, physical)
+		repetition := int32(repetitionRequired)
+		if field.Nullable {
+			repetition = repetitionOptional
+		}
+		t.i32Field(3, repetition)
+		t.stringField(4, field.Name)
+		if converted >= 0 {
+			t.i32Field(6, converted)
+		}
+		t.endStruct()
+	}
+
+	t.i64Field(3, w.numRows)
+	t.listField(4, thriftStruct, len(w.rowGroups))
+	for _, group := range w.rowGroups {
+		t.beginStruct()
+		t.listField(1, thriftStruct, len(group.columns))
+		for i, chunk := range group.columns {
+			field := w.schema.Fields[i]
+			physical, _ := field.Type.physical()
+			t.beginStruct()
+			t.i64Field(2, chunk.offset)
+			t.structField(3)
+			t.i32Field(1, physical)
+			t.listField(2, thriftI32, 2)
+			t.varint(encodingPlain)
+			t.varint(encodingRLE)
+			t.listField(3, thriftBinary, 1)
+			t.string(field.Name)
+			t.i32Field(4, int32(w.opts.Compression))
+			t.i64Field(5, chunk.numValues)
+			t.i64Field(6, chunk.uncompressedSize)
+			t.i64Field(7, chunk.compressedSize)
+			t.i64Field(9, chunk.offset)
+			t.endStruct()
+			t.endStruct()
+		}
+		t.i64Field(2, group.totalSize)
+		t.i64Field(3, group.numRows)
+		t.endStruct()
+	}
+	t.stringField(6, "messenger-go")
+	t.endStruct()
+	return t.buffer.Bytes()
+}
+
+// physical returns the physical and converted types of the column type, -1 when it has no
+// converted type.
+func (t Type) physical() (int32, int32) {
+	switch t {
+	case Boolean:
+		return physicalBoolean, -1
+	case Int32:
+		return physicalInt32, -1
+	case Int64:
+		return physicalInt64, -1
+	case Float32:
+		return physicalFloat, -1
+	case Float64:
+		return physicalDouble, -1
+	case Utf8:
+		return physicalByteArray, convertedUTF8
+	case Timestamp:
+		return physicalInt64, convertedTimestampMicros
+	}
+	return physicalByteArray, -1
+}
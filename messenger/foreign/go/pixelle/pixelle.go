@@ -0,0 +1,210 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package pixelle is a fluent facade over the SDK, wiring the client, producers, consumers,
+// protocol statistics and shutdown in a few lines:
+//
+//	conn := pixelle.Connect(pixelle.Config{Username: "messenger", Password: "messenger"})
+//	defer conn.Close(context.Background())
+//	orders := conn.Stream("orders").Topic("created")
+//	p, err := orders.Producer(producer.WithBatchSize(100))
+//	...
+//	err = orders.ConsumerGroup("billing").Consume(ctx, handler)
+//
+// It delegates to the messengercli, producer and consumer packages, whose options it accepts,
+// and the lower-level modules remain available through Client.
+package pixelle
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/apache/messenger/foreign/go/consumer"
+	"github.com/apache/messenger/foreign/go/messengercli"
+	"github.com/apache/messenger/foreign/go/producer"
+	"github.com/apache/messenger/foreign/go/tcp"
+)
+
+// ErrClosed is returned when using a closed Connection.
+var ErrClosed = errors.New("pixelle: connection closed")
+
+// Config describes how to connect and log in to the server.
+type Config struct {
+	// Address is the TCP address of the server, 127.0.0.1:8090 by default.
+	Address string
+	// Username and Password log in the user, unless AccessToken is set.
+	Username string
+	Password string
+	// AccessToken logs in with a personal access token.
+	AccessToken string
+	// ProtocolObserver receives every request sent to the server, e.g. to feed a metrics system.
+	ProtocolObserver tcp.ProtocolObserver
+	// RequestObserver receives the lifecycle events of the requests and of the connection.
+	RequestObserver tcp.RequestObserver
+	// TcpOptions are applied after the options derived from the other fields.
+	TcpOptions []tcp.Option
+}
+
+// Connection is a logged-in client, tracking the producers and consumers created through it so
+// Close shuts them down.
+type Connection struct {
+	client messengercli.Client
+	err    error
+	// ctx is canceled by Close to stop the consumers.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mtx       sync.Mutex
+	closed    bool
+	producers []*producer.Producer
+	consumers map[*consumer.Consumer]struct{}
+	running   sync.WaitGroup
+}
+
+// Connect connects and logs in to the server. A failure is reported by Err and by every
+// operation of the Connection, so the calls can be chained.
+func Connect(cfg Config) *Connection {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &Connection{ctx: ctx, cancel: cancel, consumers: make(map[*consumer.Consumer]struct{})}
+	c.client, c.err = connect(cfg)
+	return c
+}
+
+func connect(cfg Config) (messengercli.Client, error) {
+	var tcpOptions []tcp.Option
+	if cfg.Address != "" {
+		tcpOptions = append(tcpOptions, tcp.WithServerAddress(cfg.Address))
+	}
+	if cfg.ProtocolObserver != nil {
+		tcpOptions = append(tcpOptions, tcp.WithProtocolObserver(cfg.ProtocolObserver))
+	}
+	if cfg.RequestObserver != nil {
+		tcpOptions = append(tcpOptions, tcp.WithRequestObserver(cfg.RequestObserver))
+	}
+	client, err := messengercli.NewMessengerClient(messengercli.WithTcp(append(tcpOptions, cfg.TcpOptions...)...))
+	if err != nil {
+		return nil, err
+	}
+	if cfg.AccessToken != "" {
+		_, err = client.LoginWithPersonalAccessToken(cfg.AccessToken)
+	} else {
+		_, err = client.LoginUser(cfg.Username, cfg.Password)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// Err returns the error the connection failed with, if any.
+func (c *Connection) Err() error {
+	return c.err
+}
+
+// Client returns the underlying client, nil if the connection failed.
+func (c *Connection) Client() messengercli.Client {
+	return c.client
+}
+
+// ProtocolStats returns the per-command statistics of the requests sent by the connection.
+func (c *Connection) ProtocolStats() tcp.ProtocolStats {
+	stats, _ := messengercli.ProtocolStats(c.client)
+	return stats
+}
+
+// ConnectionEvents returns the events of the connection, starting with its current state, or nil
+// if the connection failed.
+func (c *Connection) ConnectionEvents() <-chan tcp.ConnectionEvent {
+	events, _ := messengercli.ConnectionEvents(c.client)
+	return events
+}
+
+// Stream selects a stream by name.
+func (c *Connection) Stream(name string) *Stream {
+	return newStream(c, name)
+}
+
+// Close drains the consumers, which handle the messages already polled and leave their group,
+// flushes and closes the producers, then logs out and closes the connection. Whatever could not
+// be drained before the context is done is reported by the *consumer.DrainError and
+// *producer.DrainError joined in the returned error.
+func (c *Connection) Close(ctx context.Context) error {
+	c.mtx.Lock()
+	if c.closed {
+		c.mtx.Unlock()
+		return nil
+	}
+	c.closed = true
+	producers := c.producers
+	consumers := make([]*consumer.Consumer, 0, len(c.consumers))
+	for cs := range c.consumers {
+		consumers = append(consumers, cs)
+	}
+	c.mtx.Unlock()
+	if c.err != nil {
+		return nil
+	}
+
+	var errs []error
+	for _, cs := range consumers {
+		errs = append(errs, cs.Close(ctx))
+	}
+	c.cancel()
+	c.running.Wait()
+
+	for _, p := range producers {
+		errs = append(errs, p.Close(ctx))
+	}
+	errs = append(errs, c.client.LogoutUser(), messengercli.Close(c.client))
+	return errors.Join(errs...)
+}
+
+// track registers a producer closed by Close.
+func (c *Connection) track(p *producer.Producer) error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if c.closed {
+		return ErrClosed
+	}
+	c.producers = append(c.producers, p)
+	return nil
+}
+
+// run runs a consumer until ctx is done or the connection is closed.
+func (c *Connection) run(ctx context.Context, cs *consumer.Consumer) error {
+	c.mtx.Lock()
+	if c.closed {
+		c.mtx.Unlock()
+		return ErrClosed
+	}
+	c.consumers[cs] = struct{}{}
+	c.running.Add(1)
+	c.mtx.Unlock()
+	defer func() {
+		c.mtx.Lock()
+		delete(c.consumers, cs)
+		c.mtx.Unlock()
+		c.running.Done()
+	}()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stop := context.AfterFunc(c.ctx, cancel)
+	defer stop()
+	return cs.Run(ctx)
+}
@@ -0,0 +1,103 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package pixelle
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengertest"
+	"github.com/apache/messenger/foreign/go/producer"
+)
+
+func TestConnection_ProduceAndConsume(t *testing.T) {
+	server := messengertest.StartServer(t)
+	if _, err := server.Client().CreateStream("orders", nil); err != nil {
+		t.Fatal(err)
+	}
+	streamId, _ := iggcon.NewIdentifier("orders")
+	if _, err := server.Client().CreateTopic(streamId, "created", 2, iggcon.CompressionAlgorithm(1), 0, 0, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	conn := Connect(Config{Address: server.Addr(), Username: "messenger", Password: "messenger"})
+	if err := conn.Err(); err != nil {
+		t.Fatal(err)
+	}
+	orders := conn.Stream("orders").Topic("created")
+	p, err := orders.Producer(producer.WithLinger(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, payload := range []string{"a", "b", "c"} {
+		message, _ := iggcon.NewMessengerMessage([]byte(payload))
+		if err = p.Send(context.Background(), message); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = p.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	received := make(chan string, 3)
+	consumed := make(chan error, 1)
+	go func() {
+		consumed <- orders.ConsumerGroup("billing").Consume(context.Background(), func(_ context.Context, message iggcon.ReceivedMessage) error {
+			received <- string(message.Message.Payload)
+			return nil
+		})
+	}()
+	for i := 0; i < 3; i++ {
+		select {
+		case <-received:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("received %d messages, expected 3", i)
+		}
+	}
+
+	if err = conn.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err = <-consumed; err != nil {
+		t.Fatal(err)
+	}
+	if _, err = orders.Producer(); !errors.Is(err, ErrClosed) {
+		t.Fatalf("expected ErrClosed, got %v", err)
+	}
+	if stats := conn.ProtocolStats(); stats.Commands[iggcon.SendMessagesCode].Requests == 0 {
+		t.Fatalf("expected the sends to be recorded, got %+v", stats)
+	}
+}
+
+func TestConnect_ReportsFailureOnEveryOperation(t *testing.T) {
+	server := messengertest.StartServer(t)
+	conn := Connect(Config{Address: server.Addr(), Username: "messenger", Password: "wrong"})
+	if conn.Err() == nil {
+		t.Fatal("expected the login to fail")
+	}
+	topic := conn.Stream("orders").Topic("created")
+	if _, err := topic.Producer(); !errors.Is(err, conn.Err()) {
+		t.Fatalf("expected the connection error, got %v", err)
+	}
+	if err := topic.ConsumerGroup("billing").Consume(context.Background(), nil); !errors.Is(err, conn.Err()) {
+		t.Fatalf("expected the connection error, got %v", err)
+	}
+}
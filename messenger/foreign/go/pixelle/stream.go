@@ -0,0 +1,130 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package pixelle
+
+import (
+	"context"
+	"errors"
+
+	"github.com/apache/messenger/foreign/go/consumer"
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	ierror "github.com/apache/messenger/foreign/go/errors"
+	"github.com/apache/messenger/foreign/go/messengercli"
+	"github.com/apache/messenger/foreign/go/producer"
+)
+
+const consumerGroupAlreadyExists = 5001
+
+// Stream is a stream selected by name.
+type Stream struct {
+	conn *Connection
+	id   iggcon.Identifier
+	err  error
+}
+
+func newStream(conn *Connection, name string) *Stream {
+	id, err := iggcon.NewIdentifier(name)
+	return &Stream{conn: conn, id: id, err: errors.Join(conn.err, err)}
+}
+
+// Topic selects a topic of the stream by name.
+func (s *Stream) Topic(name string) *Topic {
+	id, err := iggcon.NewIdentifier(name)
+	return &Topic{stream: s, id: id, err: errors.Join(s.err, err)}
+}
+
+// Topic is a topic selected by name.
+type Topic struct {
+	stream *Stream
+	id     iggcon.Identifier
+	err    error
+}
+
+// Producer creates a producer sending to the topic, flushed and closed by Connection.Close.
+func (t *Topic) Producer(options ...producer.Option) (*producer.Producer, error) {
+	if t.err != nil {
+		return nil, t.err
+	}
+	conn := t.stream.conn
+	p, err := producer.NewProducer(messengercli.AsData(conn.client), t.stream.id, t.id, options...)
+	if err != nil {
+		return nil, err
+	}
+	if err = conn.track(p); err != nil {
+		_ = p.Close(context.Background())
+		return nil, err
+	}
+	return p, nil
+}
+
+// ConsumerGroup selects a consumer group of the topic by name.
+func (t *Topic) ConsumerGroup(name string) *Group {
+	return &Group{topic: t, name: name}
+}
+
+// Group is a consumer group selected by name.
+type Group struct {
+	topic *Topic
+	name  string
+}
+
+// Consume creates the consumer group if it does not exist, joins it and passes the messages of
+// the partitions assigned to the member to the handler, until ctx is done, the handler fails or
+// the connection is closed. The group is left before returning.
+func (g *Group) Consume(ctx context.Context, handler consumer.Handler, options ...consumer.Option) error {
+	t := g.topic
+	if t.err != nil {
+		return t.err
+	}
+	groupId, err := iggcon.NewIdentifier(g.name)
+	if err != nil {
+		return err
+	}
+	client, streamId := t.stream.conn.client, t.stream.id
+	if err = ensureGroup(client, streamId, t.id, g.name, groupId); err != nil {
+		return err
+	}
+	c, err := consumer.NewConsumer(
+		client,
+		streamId,
+		t.id,
+		handler,
+		append([]consumer.Option{
+			consumer.WithConsumer(iggcon.NewGroupConsumer(groupId)),
+			consumer.WithGroupMembership(),
+		}, options...)...,
+	)
+	if err != nil {
+		return err
+	}
+	return t.stream.conn.run(ctx, c)
+}
+
+func ensureGroup(client messengercli.Client, streamId, topicId iggcon.Identifier, name string, groupId iggcon.Identifier) error {
+	_, err := client.GetConsumerGroup(streamId, topicId, groupId)
+	var messengerErr *ierror.MessengerError
+	if !errors.As(err, &messengerErr) || messengerErr.Code != ierror.ConsumerGroupIdNotFound.Code {
+		return err
+	}
+	_, err = client.CreateConsumerGroup(streamId, topicId, name, nil)
+	if errors.As(err, &messengerErr) && messengerErr.Code == consumerGroupAlreadyExists {
+		// another member created it in the meantime
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,253 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package priority
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/apache/messenger/foreign/go/consumer"
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengercli"
+)
+
+type priorityKey struct{}
+
+// Priority returns the priority of the message being handled by the handler of a
+// PriorityConsumer, from the context of the handler.
+func Priority(ctx context.Context) (int, bool) {
+	priority, ok := ctx.Value(priorityKey{}).(int)
+	return priority, ok
+}
+
+// PriorityConsumer polls the topics of the priorities, in the order of its Strategy, and passes
+// every message to a handler. The priorities are reconsidered after every batch handled.
+type PriorityConsumer struct {
+	client   messengercli.Client
+	streamId iggcon.Identifier
+	levels   []*level
+	handler  consumer.Handler
+	opts     Options
+	// current are the current weights of the smooth weighted round robin of WeightedFair.
+	current []int
+}
+
+// level is the topic of a priority.
+type level struct {
+	topicId iggcon.Identifier
+	// partitions are the partitions to poll, a nil entry letting the server pick the partition.
+	partitions []*uint32
+	// next is the index of the partition polled first next time.
+	next int
+}
+
+// NewPriorityConsumer creates a PriorityConsumer of the topics of streamId, the topic of priority
+// 0 first.
+func NewPriorityConsumer(
+	client messengercli.Client,
+	streamId iggcon.Identifier,
+	topicIds []iggcon.Identifier,
+	handler consumer.Handler,
+	options ...Option,
+) (*PriorityConsumer, error) {
+	if client == nil {
+		return nil, errors.New("priority: client is required")
+	}
+	if handler == nil {
+		return nil, errors.New("priority: handler is required")
+	}
+	if len(topicIds) == 0 {
+		return nil, errors.New("priority: at least one topic is required")
+	}
+	opts := GetDefaultOptions()
+	for _, opt := range options {
+		if opt != nil {
+			opt(&opts)
+		}
+	}
+	if opts.BatchSize == 0 {
+		return nil, errors.New("priority: batch size must be greater than zero")
+	}
+	if weights := opts.Strategy.weights; weights != nil {
+		if len(weights) != len(topicIds) {
+			return nil, errors.New("priority: a weight is required per topic")
+		}
+		for _, weight := range weights {
+			if weight <= 0 {
+				return nil, errors.New("priority: weights must be greater than zero")
+			}
+		}
+	}
+
+	c := &PriorityConsumer{
+		client:   client,
+		streamId: streamId,
+		handler:  handler,
+		opts:     opts,
+		current:  make([]int, len(topicIds)),
+	}
+	for _, topicId := range topicIds {
+		c.levels = append(c.levels, &level{topicId: topicId})
+	}
+	return c, nil
+}
+
+// Run polls and handles messages until ctx is cancelled or an error occurs. It returns nil when
+// stopped through ctx, otherwise the first poll, commit or handler error.
+func (c *PriorityConsumer) Run(ctx context.Context) error {
+	err := c.run(ctx)
+	if ctx.Err() != nil && errors.Is(err, ctx.Err()) {
+		return nil
+	}
+	return err
+}
+
+func (c *PriorityConsumer) run(ctx context.Context) error {
+	for _, level := range c.levels {
+		partitions, err := c.partitions(level.topicId)
+		if err != nil {
+			return err
+		}
+		level.partitions = partitions
+	}
+
+	for {
+		empty := make([]bool, len(c.levels))
+		polled := false
+		for !polled {
+			priority := c.pick(empty)
+			if priority < 0 {
+				break
+			}
+			var err error
+			if polled, err = c.poll(ctx, priority); err != nil {
+				return err
+			}
+			empty[priority] = !polled
+		}
+		if polled {
+			continue
+		}
+		timer := time.NewTimer(c.opts.PollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// pick returns the priority to poll next among the ones not empty, -1 when all are.
+func (c *PriorityConsumer) pick(empty []bool) int {
+	weights := c.opts.Strategy.weights
+	if weights == nil {
+		for priority := range c.levels {
+			if !empty[priority] {
+				return priority
+			}
+		}
+		return -1
+	}
+
+	// smooth weighted round robin, interleaving the priorities in proportion of their weights
+	picked, total := -1, 0
+	for priority, weight := range weights {
+		if empty[priority] {
+			continue
+		}
+		c.current[priority] += weight
+		total += weight
+		if picked < 0 || c.current[priority] > c.current[picked] {
+			picked = priority
+		}
+	}
+	if picked >= 0 {
+		c.current[picked] -= total
+	}
+	return picked
+}
+
+// poll polls a batch of the topic of the priority and handles it, reporting whether any message
+// was received. The partitions are polled in turn, from the one after the last one having
+// messages.
+func (c *PriorityConsumer) poll(ctx context.Context, priority int) (bool, error) {
+	level := c.levels[priority]
+	for i := range level.partitions {
+		index := (level.next + i) % len(level.partitions)
+		polled, err := c.client.PollMessages(
+			c.streamId,
+			level.topicId,
+			c.opts.Consumer,
+			iggcon.NextPollingStrategy(),
+			c.opts.BatchSize,
+			c.opts.AutoCommit,
+			level.partitions[index],
+		)
+		if err != nil {
+			return false, err
+		}
+		if polled == nil || len(polled.Messages) == 0 {
+			continue
+		}
+		level.next = index + 1
+		return true, c.handle(context.WithValue(ctx, priorityKey{}, priority), level.topicId, polled)
+	}
+	return false, nil
+}
+
+func (c *PriorityConsumer) handle(ctx context.Context, topicId iggcon.Identifier, polled *iggcon.PolledMessage) error {
+	for _, message := range polled.Messages {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		received := iggcon.ReceivedMessage{
+			Message:       message,
+			CurrentOffset: polled.CurrentOffset,
+			PartitionId:   polled.PartitionId,
+		}
+		if err := c.handler(ctx, received); err != nil {
+			return err
+		}
+		if !c.opts.AutoCommit {
+			partitionId := polled.PartitionId
+			if err := c.client.StoreConsumerOffset(c.opts.Consumer, c.streamId, topicId, message.Header.Offset, &partitionId); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// partitions resolves the partitions of the topic to poll; a nil entry lets the server pick the
+// partition.
+func (c *PriorityConsumer) partitions(topicId iggcon.Identifier) ([]*uint32, error) {
+	if c.opts.Consumer.Kind == iggcon.ConsumerKindGroup {
+		return []*uint32{nil}, nil
+	}
+	topic, err := c.client.GetTopic(c.streamId, topicId)
+	if err != nil {
+		return nil, err
+	}
+	partitions := make([]*uint32, 0, topic.PartitionsCount)
+	for id := uint32(1); id <= topic.PartitionsCount; id++ {
+		partitions = append(partitions, &id)
+	}
+	return partitions, nil
+}
@@ -0,0 +1,106 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package priority
+
+import (
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+)
+
+// Strategy decides which priority a PriorityConsumer polls next.
+type Strategy struct {
+	// weights are the shares of the polls of every priority, nil for strict priority.
+	weights []int
+}
+
+// StrictPriority polls a priority only when the higher ones have no message, so a steady flow
+// of urgent messages starves the others.
+func StrictPriority() Strategy {
+	return Strategy{}
+}
+
+// WeightedFair polls the priorities having messages in proportion of their weights, the weight of
+// priority 0 first: with the weights 6, 3 and 1, six batches of priority 0 are handled for every
+// three of priority 1 and one of priority 2 while they all have messages.
+func WeightedFair(weights ...int) Strategy {
+	return Strategy{weights: weights}
+}
+
+type Option func(opts *Options)
+
+type Options struct {
+	// Consumer identifies the consumer (single or group) offsets are stored for.
+	Consumer iggcon.Consumer
+	// Strategy decides which priority is polled next.
+	Strategy Strategy
+	// BatchSize is the number of messages requested by a single poll.
+	BatchSize uint32
+	// PollInterval is the pause after no priority had messages.
+	PollInterval time.Duration
+	// AutoCommit lets the server store the offset when the messages are polled, otherwise the
+	// offset is stored after the handler processed each message.
+	AutoCommit bool
+}
+
+func GetDefaultOptions() Options {
+	return Options{
+		Consumer:     iggcon.DefaultConsumer(),
+		Strategy:     StrictPriority(),
+		BatchSize:    100,
+		PollInterval: 100 * time.Millisecond,
+		AutoCommit:   true,
+	}
+}
+
+// WithConsumer sets the consumer (single or group) used to poll and store offsets.
+func WithConsumer(consumer iggcon.Consumer) Option {
+	return func(opts *Options) {
+		opts.Consumer = consumer
+	}
+}
+
+// WithStrategy sets which priority is polled next, StrictPriority by default.
+func WithStrategy(strategy Strategy) Option {
+	return func(opts *Options) {
+		opts.Strategy = strategy
+	}
+}
+
+// WithBatchSize sets the number of messages requested by a single poll. The priorities are only
+// reconsidered between batches, so smaller batches let the urgent messages overtake sooner.
+func WithBatchSize(size uint32) Option {
+	return func(opts *Options) {
+		opts.BatchSize = size
+	}
+}
+
+// WithPollInterval sets the pause after no priority had messages.
+func WithPollInterval(interval time.Duration) Option {
+	return func(opts *Options) {
+		opts.PollInterval = interval
+	}
+}
+
+// WithAutoCommit sets whether the server stores the offset when the messages are polled,
+// otherwise the offset is stored after the handler processed each message.
+func WithAutoCommit(autoCommit bool) Option {
+	return func(opts *Options) {
+		opts.AutoCommit = autoCommit
+	}
+}
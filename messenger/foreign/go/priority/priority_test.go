@@ -0,0 +1,105 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package priority_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengertest"
+	"github.com/apache/messenger/foreign/go/priority"
+)
+
+// consume sends count messages of every priority, then returns the priorities of the messages
+// in the order the consumer handled them.
+func consume(t *testing.T, count int, options ...priority.Option) string {
+	client := messengertest.NewClient()
+	if _, err := client.CreateStream("jobs", nil); err != nil {
+		t.Fatal(err)
+	}
+	streamId := iggcon.MustIdentifier("jobs")
+	var topicIds []iggcon.Identifier
+	for _, name := range []string{"high", "low"} {
+		if _, err := client.CreateTopic(streamId, name, 2, iggcon.CompressionAlgorithmNone, 0, 0, nil, nil); err != nil {
+			t.Fatal(err)
+		}
+		topicIds = append(topicIds, iggcon.MustIdentifier(name))
+	}
+
+	p, err := priority.NewPriorityProducer(client, streamId, topicIds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = p.Send(context.Background(), 2, iggcon.MessengerMessage{}); !errors.Is(err, priority.ErrInvalidPriority) {
+		t.Fatalf("expected the priority to be invalid, got %v", err)
+	}
+	for i := 0; i < count; i++ {
+		for level := 1; level >= 0; level-- {
+			message, err := iggcon.NewMessengerMessage([]byte(fmt.Sprint(level)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err = p.Send(context.Background(), level, message); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err = p.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var handled strings.Builder
+	c, err := priority.NewPriorityConsumer(client, streamId, topicIds, func(ctx context.Context, message iggcon.ReceivedMessage) error {
+		if level, ok := priority.Priority(ctx); !ok || fmt.Sprint(level) != string(message.Message.Payload) {
+			t.Errorf("expected the priority of the message %s, got %d", message.Message.Payload, level)
+		}
+		handled.Write(message.Message.Payload)
+		if handled.Len() == 2*count {
+			cancel()
+		}
+		return nil
+	}, options...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = c.Run(ctx); err != nil {
+		t.Fatal(err)
+	}
+	return handled.String()
+}
+
+func TestPriorityConsumer(t *testing.T) {
+	if handled := consume(t, 8, priority.WithBatchSize(2)); handled != "0000000011111111" {
+		t.Fatalf("expected the high priority messages first, got %s", handled)
+	}
+	handled := consume(t, 8, priority.WithBatchSize(1), priority.WithStrategy(priority.WeightedFair(3, 1)))
+	if handled[:8] != "00100010" {
+		t.Fatalf("expected 3 high priority messages for every low priority one, got %s", handled)
+	}
+	if _, err := priority.NewPriorityConsumer(messengertest.NewClient(), iggcon.MustIdentifier("jobs"), []iggcon.Identifier{iggcon.MustIdentifier("high")},
+		func(context.Context, iggcon.ReceivedMessage) error { return nil }, priority.WithStrategy(priority.WeightedFair(1, 1))); err == nil {
+		t.Fatal("expected a weight per topic to be required")
+	}
+}
@@ -0,0 +1,92 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package priority implements the priority queue pattern over a topic per priority: a
+// PriorityProducer sends every message to the topic of its priority, and a PriorityConsumer
+// consumes the topics either in strict priority order or weighted-fair, so the urgent messages
+// overtake the backlog of the others without starving them.
+//
+// Priorities are numbered from 0, the highest, to the number of topics minus one.
+package priority
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengercli"
+	"github.com/apache/messenger/foreign/go/producer"
+)
+
+// ErrInvalidPriority is returned when sending with a priority without a topic.
+var ErrInvalidPriority = errors.New("priority: invalid priority")
+
+// PriorityProducer sends the messages to the topic of their priority, through a producer.Producer
+// per topic.
+type PriorityProducer struct {
+	producers []*producer.Producer
+}
+
+// NewPriorityProducer creates a PriorityProducer sending to the topics of streamId, the topic of
+// priority 0 first. The options configure the producer of every topic.
+func NewPriorityProducer(
+	client messengercli.DataClient,
+	streamId iggcon.Identifier,
+	topicIds []iggcon.Identifier,
+	options ...producer.Option,
+) (*PriorityProducer, error) {
+	if len(topicIds) == 0 {
+		return nil, errors.New("priority: at least one topic is required")
+	}
+	p := &PriorityProducer{}
+	for _, topicId := range topicIds {
+		topicProducer, err := producer.NewProducer(client, streamId, topicId, options...)
+		if err != nil {
+			_ = p.Close(context.Background())
+			return nil, err
+		}
+		p.producers = append(p.producers, topicProducer)
+	}
+	return p, nil
+}
+
+// Send enqueues the messages to the topic of the priority, like producer.Producer.Send.
+func (p *PriorityProducer) Send(ctx context.Context, priority int, messages ...iggcon.MessengerMessage) error {
+	if priority < 0 || priority >= len(p.producers) {
+		return fmt.Errorf("%w: %d", ErrInvalidPriority, priority)
+	}
+	return p.producers[priority].Send(ctx, messages...)
+}
+
+// Flush blocks until every message enqueued so far has been sent or ctx is done.
+func (p *PriorityProducer) Flush(ctx context.Context) error {
+	var errs []error
+	for _, topicProducer := range p.producers {
+		errs = append(errs, topicProducer.Flush(ctx))
+	}
+	return errors.Join(errs...)
+}
+
+// Close closes the producers of all the topics, sending the queued messages.
+func (p *PriorityProducer) Close(ctx context.Context) error {
+	var errs []error
+	for _, topicProducer := range p.producers {
+		errs = append(errs, topicProducer.Close(ctx))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,83 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package producer
+
+import (
+	"fmt"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+)
+
+// BatchError is returned by Send when only part of its messages were delivered, the batches
+// being sent in several requests of which some failed. Sending the messages of Failed again,
+// rather than all of them, avoids duplicating the delivered ones.
+type BatchError struct {
+	// Failures groups the failed messages by the request they were sent with, in order.
+	Failures []BatchFailure
+	// Delivered is the number of messages delivered.
+	Delivered int
+}
+
+// BatchFailure is a request of a batch which failed, along with its messages.
+type BatchFailure struct {
+	Messages []iggcon.MessengerMessage
+	Err      error
+}
+
+func (e *BatchError) Error() string {
+	failed := 0
+	for _, failure := range e.Failures {
+		failed += len(failure.Messages)
+	}
+	return fmt.Sprintf("producer: %d message(s) failed and %d delivered: %v", failed, e.Delivered, e.Failures[0].Err)
+}
+
+// Unwrap returns the errors of the failed requests.
+func (e *BatchError) Unwrap() []error {
+	errs := make([]error, len(e.Failures))
+	for i, failure := range e.Failures {
+		errs[i] = failure.Err
+	}
+	return errs
+}
+
+// Failed returns the messages which were not delivered, in order.
+func (e *BatchError) Failed() []iggcon.MessengerMessage {
+	var messages []iggcon.MessengerMessage
+	for _, failure := range e.Failures {
+		messages = append(messages, failure.Messages...)
+	}
+	return messages
+}
+
+// requestFailure is a failed request of a batch, covering the messages from start to end.
+type requestFailure struct {
+	start int
+	end   int
+	err   error
+}
+
+// failureAt returns the failure of the message at index i of the batch, nil when it was delivered.
+func failureAt(failures []*requestFailure, i int) *requestFailure {
+	for _, failure := range failures {
+		if i >= failure.start && i < failure.end {
+			return failure
+		}
+	}
+	return nil
+}
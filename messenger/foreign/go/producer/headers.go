@@ -0,0 +1,113 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package producer
+
+import (
+	"context"
+	"os"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+)
+
+// HeaderProvider returns the user headers attached to every message sent with ctx, like the
+// host the producer runs on or the trace ids carried by ctx. It is called on every Send, so it
+// must be cheap, and it may return nil to attach nothing.
+type HeaderProvider func(ctx context.Context) map[iggcon.HeaderKey]iggcon.HeaderValue
+
+// StaticHeaders provides the same string headers to every message, e.g. the region or the
+// build version of the application.
+func StaticHeaders(headers map[string]string) HeaderProvider {
+	provided := make(map[iggcon.HeaderKey]iggcon.HeaderValue, len(headers))
+	for key, value := range headers {
+		provided[iggcon.HeaderKey{Value: key}] = iggcon.NewStringHeaderValue(value)
+	}
+	return func(context.Context) map[iggcon.HeaderKey]iggcon.HeaderValue {
+		return provided
+	}
+}
+
+// EnvHeaders provides headers read from environment variables once, keyed by header key, e.g.
+// {"pod": "POD_NAME"} as set by the Kubernetes downward API. Unset variables are skipped.
+func EnvHeaders(variables map[string]string) HeaderProvider {
+	headers := make(map[string]string, len(variables))
+	for key, variable := range variables {
+		if value, ok := os.LookupEnv(variable); ok && value != "" {
+			headers[key] = value
+		}
+	}
+	return StaticHeaders(headers)
+}
+
+// HostnameHeader provides the host name of the machine under the given header key.
+func HostnameHeader(key string) HeaderProvider {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		return StaticHeaders(nil)
+	}
+	return StaticHeaders(map[string]string{key: hostname})
+}
+
+// ContextHeader provides the value returned by value for the Send context under the given
+// header key, e.g. the trace id of the span carried by ctx. Nothing is attached when value
+// returns false.
+func ContextHeader(key string, value func(ctx context.Context) (string, bool)) HeaderProvider {
+	headerKey := iggcon.HeaderKey{Value: key}
+	return func(ctx context.Context) map[iggcon.HeaderKey]iggcon.HeaderValue {
+		provided, ok := value(ctx)
+		if !ok {
+			return nil
+		}
+		return map[iggcon.HeaderKey]iggcon.HeaderValue{headerKey: iggcon.NewStringHeaderValue(provided)}
+	}
+}
+
+// enrich returns the messages with the headers of the providers attached, the headers already
+// set on a message taking precedence.
+func (p *Producer) enrich(ctx context.Context, messages []iggcon.MessengerMessage) ([]iggcon.MessengerMessage, error) {
+	if len(p.opts.HeaderProviders) == 0 {
+		return messages, nil
+	}
+	provided := map[iggcon.HeaderKey]iggcon.HeaderValue{}
+	for _, provider := range p.opts.HeaderProviders {
+		for key, value := range provider(ctx) {
+			provided[key] = value
+		}
+	}
+	if len(provided) == 0 {
+		return messages, nil
+	}
+
+	enriched := make([]iggcon.MessengerMessage, len(messages))
+	for i, message := range messages {
+		existing, err := iggcon.DeserializeHeaders(message.UserHeaders)
+		if err != nil {
+			return nil, err
+		}
+		headers := make(map[iggcon.HeaderKey]iggcon.HeaderValue, len(provided))
+		for key, value := range provided {
+			if _, ok := existing[key]; !ok {
+				headers[key] = value
+			}
+		}
+		if err := message.SetUserHeaders(headers); err != nil {
+			return nil, err
+		}
+		enriched[i] = message
+	}
+	return enriched, nil
+}
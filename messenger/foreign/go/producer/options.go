@@ -0,0 +1,290 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package producer
+
+import (
+	"log"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+)
+
+// OverflowPolicy decides what Send does when the in-memory queue is full.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock blocks Send until there is room in the queue or the context is done.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropOldest evicts the oldest queued messages to make room for the new ones.
+	OverflowDropOldest
+	// OverflowDropNewest discards the messages which do not fit into the queue.
+	OverflowDropNewest
+	// OverflowFail makes Send return ErrQueueFull.
+	OverflowFail
+)
+
+// ErrorHandler is called with the messages which could not be delivered, either because
+// sending them failed or because they were dropped by the overflow policy.
+type ErrorHandler func(err error, messages []iggcon.MessengerMessage)
+
+// ResultHandler is called with the messages of every request the server appended, along with
+// the partition and the offsets they were appended at.
+type ResultHandler func(result iggcon.SendResult, messages []iggcon.MessengerMessage)
+
+type Option func(opts *Options)
+
+type Options struct {
+	// Partitioning is the partitioning used for every batch.
+	Partitioning iggcon.Partitioning
+	// Partitioner, when set, computes the partition of every message on the client instead.
+	// A Partitioner with a Rotate method, like iggcon.StickyPartitioner, is rotated whenever
+	// a batch is taken from the queue.
+	Partitioner iggcon.Partitioner
+	// PartitionKey returns the key of a message passed to the Partitioner, nil for no key.
+	// By default, it is the key set with iggcon.WithKey, or else the key of the MessageKey
+	// Partitioning, if any.
+	PartitionKey func(message iggcon.MessengerMessage) []byte
+	// PartitionsCount is the number of partitions of the topic used by the Partitioner. When 0,
+	// it is read with GetTopic if the client is an AdminClient.
+	PartitionsCount uint32
+	// BatchSize is the maximum number of messages sent in a single request.
+	BatchSize int
+	// Linger is how long a partial batch waits for more messages before being sent.
+	Linger time.Duration
+	// MaxQueuedMessages bounds the number of messages waiting to be sent, 0 means unbounded.
+	MaxQueuedMessages int
+	// MaxQueuedBytes bounds the size of the messages waiting to be sent, 0 means unbounded.
+	MaxQueuedBytes int
+	// Overflow is applied when a message does not fit into the queue.
+	Overflow OverflowPolicy
+	// ErrorHandler receives the messages which could not be delivered.
+	ErrorHandler ErrorHandler
+	// ResultHandler, when set, receives where the delivered messages were appended.
+	ResultHandler ResultHandler
+	// PropagateDeadline stamps the deadline of the Send context into the message headers.
+	PropagateDeadline bool
+	// Confirmation is the acknowledgement level used by Send.
+	Confirmation iggcon.Confirmation
+	// MaxRequestSize is the maximum size in bytes of a send request accepted by the server, larger
+	// batches are split into several requests. When 0, the limit is discovered from the size errors
+	// returned by the server.
+	MaxRequestSize int
+	// MaxInFlightRequests bounds the send requests in flight per partition, the batches of
+	// different partitions being sent concurrently. 1 by default, which keeps each partition in
+	// order even when failed requests are retried.
+	MaxInFlightRequests int
+	// Retries is the number of times a request failing with a transport error is sent again.
+	Retries int
+	// RetryBackoff is the pause before the first retry of a request, doubled by every retry.
+	RetryBackoff time.Duration
+	// ChunkSize is the size of the chunks SendPayload splits the larger payloads into, 0 disables
+	// the chunking.
+	ChunkSize int
+	// Quotas, when set, throttles Send to the send quota of the topic.
+	Quotas *Quotas
+	// HeaderProviders provide the user headers attached to every message.
+	HeaderProviders []HeaderProvider
+	// Validators check every message before it is enqueued.
+	Validators []Validator
+	// Validation is applied to the messages rejected by a Validator.
+	Validation ValidationPolicy
+}
+
+func GetDefaultOptions() Options {
+	return Options{
+		Partitioning:        iggcon.None(),
+		BatchSize:           1000,
+		Linger:              5 * time.Millisecond,
+		MaxQueuedMessages:   100_000,
+		MaxQueuedBytes:      64 * 1024 * 1024,
+		Overflow:            OverflowBlock,
+		MaxInFlightRequests: 1,
+		RetryBackoff:        100 * time.Millisecond,
+		ErrorHandler: func(err error, messages []iggcon.MessengerMessage) {
+			log.Printf("[WARN] producer failed to deliver %d message(s): %v", len(messages), err)
+		},
+	}
+}
+
+// WithPartitioning sets the partitioning used for every batch.
+func WithPartitioning(partitioning iggcon.Partitioning) Option {
+	return func(opts *Options) {
+		opts.Partitioning = partitioning
+	}
+}
+
+// WithPartitioner computes the partition of every message on the client with the partitioner, on
+// the key returned by key, which may be nil to use the message key set with iggcon.WithKey, or
+// else the key of the Partitioning. The messages are
+// batched per partition and sent with PartitionId partitioning, so a key is mapped to the same
+// partition as by the other clients using the same hash, e.g. iggcon.Murmur2Partitioner for Kafka.
+func WithPartitioner(partitioner iggcon.Partitioner, key func(message iggcon.MessengerMessage) []byte) Option {
+	return func(opts *Options) {
+		opts.Partitioner = partitioner
+		opts.PartitionKey = key
+	}
+}
+
+// WithStickyPartitioning partitions the messages on the client with an iggcon.StickyPartitioner,
+// rotated whenever a batch is sent: the messages without a key fill up a single partition per
+// batch interval, while the messages with a key, returned by key as for WithPartitioner, are
+// spread by murmur2. This suits high-frequency small messages best, the batches holding up to
+// BatchSize messages instead of BatchSize divided by the partitions count.
+func WithStickyPartitioning(key func(message iggcon.MessengerMessage) []byte) Option {
+	return WithPartitioner(iggcon.NewStickyPartitioner(nil), key)
+}
+
+// WithPartitionsCount sets the number of partitions of the topic used by the Partitioner, for
+// clients without access to GetTopic. The producer does not follow later partition changes.
+func WithPartitionsCount(count uint32) Option {
+	return func(opts *Options) {
+		opts.PartitionsCount = count
+	}
+}
+
+// WithBatchSize sets the maximum number of messages sent in a single request.
+func WithBatchSize(size int) Option {
+	return func(opts *Options) {
+		opts.BatchSize = size
+	}
+}
+
+// WithLinger sets how long a partial batch waits for more messages before being sent.
+func WithLinger(linger time.Duration) Option {
+	return func(opts *Options) {
+		opts.Linger = linger
+	}
+}
+
+// WithMaxQueued bounds the queue of messages waiting to be sent, by count and by size in bytes.
+// A zero value leaves the corresponding dimension unbounded.
+func WithMaxQueued(messages int, bytes int) Option {
+	return func(opts *Options) {
+		opts.MaxQueuedMessages = messages
+		opts.MaxQueuedBytes = bytes
+	}
+}
+
+// WithOverflowPolicy sets what Send does when the queue is full.
+func WithOverflowPolicy(policy OverflowPolicy) Option {
+	return func(opts *Options) {
+		opts.Overflow = policy
+	}
+}
+
+// WithErrorHandler sets the handler receiving the messages which could not be delivered.
+func WithErrorHandler(handler ErrorHandler) Option {
+	return func(opts *Options) {
+		opts.ErrorHandler = handler
+	}
+}
+
+// WithResultHandler sets the handler receiving the partition, offsets and timestamps of the
+// delivered messages. It is called from the goroutines sending the batches, so it must not block,
+// and it is not called when the server does not report the results of the requests.
+func WithResultHandler(handler ResultHandler) Option {
+	return func(opts *Options) {
+		opts.ResultHandler = handler
+	}
+}
+
+// WithDeadlinePropagation stamps the deadline of the context passed to Send into the
+// iggcon.DeadlineHeader of every message, so consumers can skip the messages nobody waits for anymore.
+func WithDeadlinePropagation() Option {
+	return func(opts *Options) {
+		opts.PropagateDeadline = true
+	}
+}
+
+// WithConfirmation sets the acknowledgement level used by Send. iggcon.ConfirmationNoWait gives
+// the lowest latency, while iggcon.ConfirmationWrite and iggcon.ConfirmationFsync make Send wait
+// until the server wrote, or fsynced and replicated, the messages.
+func WithConfirmation(confirmation iggcon.Confirmation) Option {
+	return func(opts *Options) {
+		opts.Confirmation = confirmation
+	}
+}
+
+// WithMaxRequestSize sets the maximum size in bytes of a send request accepted by the server.
+// Batches exceeding it are split into several requests sent in order, which preserves the
+// ordering of the messages sharing a key.
+func WithMaxRequestSize(bytes int) Option {
+	return func(opts *Options) {
+		opts.MaxRequestSize = bytes
+	}
+}
+
+// WithMaxInFlightRequests bounds the send requests in flight per partition. With more than one
+// request in flight, a partition is sent faster over a slow link, but a failed request may be
+// retried after the following ones succeeded, reordering the partition. Keep the default of 1
+// with WithRetries to guarantee the ordering per partition.
+func WithMaxInFlightRequests(requests int) Option {
+	return func(opts *Options) {
+		opts.MaxInFlightRequests = requests
+	}
+}
+
+// WithRetries sends the requests failing with a transport error, like a lost connection, up to
+// retries more times, pausing for backoff before the first retry and twice as long before every
+// next one. The errors returned by the server, like a missing topic, are not retried. A retried
+// request may be delivered twice, when the server processed it but the response was lost.
+func WithRetries(retries int, backoff time.Duration) Option {
+	return func(opts *Options) {
+		opts.Retries = retries
+		opts.RetryBackoff = backoff
+	}
+}
+
+// WithChunking makes SendPayload split the payloads larger than chunkSize bytes, up to
+// iggcon.MaxPayloadSize, into chunks sent as separate messages, so payloads larger than
+// iggcon.MaxPayloadSize can be sent. The consumers join the chunks back with consumer.Reassemble.
+func WithChunking(chunkSize int) Option {
+	return func(opts *Options) {
+		opts.ChunkSize = chunkSize
+	}
+}
+
+// WithQuotas throttles Send to the send quota of the topic held by quotas, shared with the other
+// producers of the topic created with the same Quotas. Send waits until the quota allows the
+// messages, or until ctx is done, before enqueuing them, so a topic over its quota only holds
+// back its own senders.
+func WithQuotas(quotas *Quotas) Option {
+	return func(opts *Options) {
+		opts.Quotas = quotas
+	}
+}
+
+// WithHeaderProviders attaches the headers of the providers to every message sent, centralizing
+// the metadata conventions, like the host, pod and build version of the application or the trace
+// ids of the Send context. The headers already set on a message are left untouched, and a later
+// provider overrides the headers of an earlier one.
+func WithHeaderProviders(providers ...HeaderProvider) Option {
+	return func(opts *Options) {
+		opts.HeaderProviders = append(opts.HeaderProviders, providers...)
+	}
+}
+
+// WithValidators checks every message with the validators, after the headers of the
+// HeaderProviders are attached, applying policy to the rejected messages. The rejections are
+// counted by Producer.ValidationStats.
+func WithValidators(policy ValidationPolicy, validators ...Validator) Option {
+	return func(opts *Options) {
+		opts.Validation = policy
+		opts.Validators = append(opts.Validators, validators...)
+	}
+}
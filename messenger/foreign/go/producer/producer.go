@@ -0,0 +1,685 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package producer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	ierror "github.com/apache/messenger/foreign/go/errors"
+	"github.com/apache/messenger/foreign/go/messengercli"
+)
+
+var (
+	// ErrQueueFull is returned by Send when the queue is full and the overflow policy is OverflowFail.
+	ErrQueueFull = errors.New("producer: queue is full")
+	// ErrClosed is returned when sending through a closed Producer.
+	ErrClosed = errors.New("producer: closed")
+)
+
+// Producer batches messages in a bounded in-memory queue and sends them in the background,
+// so a slow broker applies backpressure to the application instead of exhausting its memory.
+type Producer struct {
+	client   messengercli.DataClient
+	streamId iggcon.Identifier
+	topicId  iggcon.Identifier
+	opts     Options
+	// quota is the send quota of the topic, nil without Quotas.
+	quota      *topicQuota
+	validation validationStats
+
+	// maxRequestSize is the maximum size of a request, discovered when MaxRequestSize is 0.
+	maxRequestSize atomic.Int64
+
+	mtx           sync.Mutex
+	queue         []queuedMessage
+	queuedBytes   int
+	inFlight      int
+	flushRequests int
+	closed        bool
+	// partitionRequests counts the requests in flight per partition, 0 without a Partitioner.
+	partitionRequests map[uint32]int
+	// drainFailed counts the messages which failed to be delivered once closed, drainErr being
+	// the first failure.
+	drainFailed int
+	drainErr    error
+	// changed is closed and replaced on every state change to wake up the waiters.
+	changed chan struct{}
+	done    chan struct{}
+	// abandoned is closed once a Close gives up draining, ending the retries waiting for their backoff.
+	abandoned     chan struct{}
+	abandonedOnce sync.Once
+}
+
+// NewProducer creates a Producer sending to the given stream and topic by unique IDs or names.
+func NewProducer(
+	client messengercli.DataClient,
+	streamId iggcon.Identifier,
+	topicId iggcon.Identifier,
+	options ...Option,
+) (*Producer, error) {
+	if client == nil {
+		return nil, errors.New("producer: client is required")
+	}
+	opts := GetDefaultOptions()
+	for _, opt := range options {
+		if opt != nil {
+			opt(&opts)
+		}
+	}
+	if opts.BatchSize <= 0 {
+		return nil, errors.New("producer: batch size must be greater than zero")
+	}
+	if opts.MaxInFlightRequests <= 0 {
+		return nil, errors.New("producer: max in-flight requests must be greater than zero")
+	}
+	if opts.Partitioner != nil && opts.PartitionsCount == 0 {
+		count, err := partitionsCount(client, streamId, topicId)
+		if err != nil {
+			return nil, err
+		}
+		opts.PartitionsCount = count
+	}
+
+	p := &Producer{
+		client:   client,
+		streamId: streamId,
+		topicId:  topicId,
+		opts:     opts,
+		changed:  make(chan struct{}),
+		done:     make(chan struct{}),
+
+		abandoned: make(chan struct{}),
+
+		partitionRequests: map[uint32]int{},
+	}
+	if opts.Quotas != nil {
+		p.quota = opts.Quotas.topic(streamId, topicId)
+	}
+	p.maxRequestSize.Store(int64(opts.MaxRequestSize))
+	go p.run()
+	return p, nil
+}
+
+// partitionsCount reads the number of partitions of the topic, when the client can.
+func partitionsCount(client messengercli.DataClient, streamId, topicId iggcon.Identifier) (uint32, error) {
+	admin, ok := client.(interface {
+		GetTopic(streamId, topicId iggcon.Identifier) (*iggcon.TopicDetails, error)
+	})
+	if !ok {
+		return 0, errors.New("producer: the partitions count is required to partition with a data client")
+	}
+	topic, err := admin.GetTopic(streamId, topicId)
+	if err != nil {
+		return 0, err
+	}
+	if topic.PartitionsCount == 0 {
+		return 0, errors.New("producer: the topic has no partitions")
+	}
+	return topic.PartitionsCount, nil
+}
+
+// queuedMessage is a message waiting to be sent along with how its delivery is confirmed.
+type queuedMessage struct {
+	message      iggcon.MessengerMessage
+	confirmation iggcon.Confirmation
+	// partition is the partition computed by the Partitioner, 0 without one.
+	partition uint32
+	// delivery is nil when nobody waits for the acknowledgement.
+	delivery *delivery
+}
+
+// delivery tracks the acknowledgement of the messages of a single Send call, guarded by Producer.mtx.
+type delivery struct {
+	pending   int
+	delivered int
+	// failures are the failed requests the messages were part of, with their failed messages.
+	failures []*requestFailure
+	failed   map[*requestFailure][]iggcon.MessengerMessage
+	err      error
+	done     chan struct{}
+}
+
+// settle records the outcome of a message, the failed request it was part of or nil when it was
+// delivered. A nil message releases the extra pending count held while enqueuing.
+func (d *delivery) settle(message *iggcon.MessengerMessage, failure *requestFailure) {
+	if message != nil {
+		if failure == nil {
+			d.delivered++
+		} else {
+			if d.failed == nil {
+				d.failed = map[*requestFailure][]iggcon.MessengerMessage{}
+			}
+			if _, ok := d.failed[failure]; !ok {
+				d.failures = append(d.failures, failure)
+			}
+			d.failed[failure] = append(d.failed[failure], *message)
+		}
+	}
+	d.pending--
+	if d.pending == 0 {
+		d.err = d.result()
+		close(d.done)
+	}
+}
+
+// result returns the error of the Send call: nil when every message was delivered, the error of
+// the request when all of them failed in a single one, and a *BatchError otherwise.
+func (d *delivery) result() error {
+	if len(d.failures) == 0 {
+		return nil
+	}
+	if d.delivered == 0 && len(d.failures) == 1 {
+		return d.failures[0].err
+	}
+	batchErr := &BatchError{Delivered: d.delivered}
+	for _, failure := range d.failures {
+		batchErr.Failures = append(batchErr.Failures, BatchFailure{Messages: d.failed[failure], Err: failure.err})
+	}
+	return batchErr
+}
+
+// Send enqueues the messages to be sent in the background, using the confirmation level of the
+// producer. When the queue is full the configured OverflowPolicy applies; with OverflowBlock,
+// Send waits until ctx is done.
+func (p *Producer) Send(ctx context.Context, messages ...iggcon.MessengerMessage) error {
+	return p.SendWithConfirmation(ctx, p.opts.Confirmation, messages...)
+}
+
+// SendWithConfirmation enqueues the messages like Send, using the given confirmation level.
+// With iggcon.ConfirmationDefault and iggcon.ConfirmationNoWait it returns once the messages are
+// queued (fire-and-forget, failures go to the ErrorHandler). With higher levels it waits until
+// the server acknowledged the messages and returns the delivery error, or until ctx is done.
+func (p *Producer) SendWithConfirmation(ctx context.Context, confirmation iggcon.Confirmation, messages ...iggcon.MessengerMessage) error {
+	return p.enqueueAll(ctx, confirmation, false, messages)
+}
+
+// SendPayload enqueues a message of the payload like Send. With WithChunking, a payload larger
+// than the chunk size is split by iggcon.SplitMessage into chunks sent in order to the partition
+// the Partitioner computes for the first one, for a consumer using consumer.Reassemble to join
+// them back. Without a Partitioner, the chunks are only kept on a single partition by a
+// PartitionId or MessageKey Partitioning.
+func (p *Producer) SendPayload(ctx context.Context, payload []byte, opts ...iggcon.MessengerMessageOpt) error {
+	chunkSize := p.opts.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = iggcon.MaxPayloadSize
+		if len(payload) > chunkSize {
+			return ierror.TooBigUserMessagePayload
+		}
+	}
+	messages, err := iggcon.SplitMessage(payload, chunkSize, opts...)
+	if err != nil {
+		return err
+	}
+	return p.enqueueAll(ctx, p.opts.Confirmation, true, messages)
+}
+
+// enqueueAll enqueues the messages, all of them to the partition of the first one with samePartition.
+func (p *Producer) enqueueAll(ctx context.Context, confirmation iggcon.Confirmation, samePartition bool, messages []iggcon.MessengerMessage) error {
+	messages, err := p.enrich(ctx, messages)
+	if err != nil {
+		return err
+	}
+	if messages, err = p.validate(messages, samePartition); err != nil {
+		return err
+	}
+	if len(messages) == 0 {
+		return nil
+	}
+	if p.quota != nil {
+		size := 0
+		for _, message := range messages {
+			size += messageSize(message)
+		}
+		if err := p.quota.wait(ctx, len(messages), size); err != nil {
+			return err
+		}
+	}
+
+	deadline, hasDeadline := ctx.Deadline()
+	hasDeadline = hasDeadline && p.opts.PropagateDeadline
+
+	var d *delivery
+	if confirmation > iggcon.ConfirmationNoWait {
+		// the extra pending count keeps the delivery open until every message is enqueued
+		d = &delivery{pending: 1, done: make(chan struct{})}
+	}
+
+	p.mtx.Lock()
+	var partition uint32
+	for i, message := range messages {
+		if hasDeadline {
+			iggcon.WithDeadline(deadline)(&message)
+		}
+		if i == 0 || !samePartition {
+			partition = p.partition(message)
+		}
+		queued := queuedMessage{message: message, confirmation: confirmation, partition: partition, delivery: d}
+		if err := p.enqueue(ctx, queued); err != nil {
+			p.mtx.Unlock()
+			return err
+		}
+	}
+	if d == nil {
+		p.mtx.Unlock()
+		return nil
+	}
+	d.settle(nil, nil)
+	p.mtx.Unlock()
+
+	select {
+	case <-d.done:
+		p.mtx.Lock()
+		defer p.mtx.Unlock()
+		return d.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// QuotaStats returns how the producers of the topic were throttled by its quota, zero
+// without WithQuotas.
+func (p *Producer) QuotaStats() QuotaStats {
+	if p.quota == nil {
+		return QuotaStats{}
+	}
+	return p.quota.stats()
+}
+
+// partition computes the partition of a message with the Partitioner, if any.
+func (p *Producer) partition(message iggcon.MessengerMessage) uint32 {
+	if p.opts.Partitioner == nil {
+		return 0
+	}
+	var key []byte
+	if p.opts.PartitionKey != nil {
+		key = p.opts.PartitionKey(message)
+	} else if key = message.Key(); key == nil {
+		key = p.opts.Partitioning.Key()
+	}
+	return p.opts.Partitioner.Partition(key, p.opts.PartitionsCount)
+}
+
+// Flush blocks until every message enqueued so far has been sent or ctx is done.
+func (p *Producer) Flush(ctx context.Context) error {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	p.flushRequests++
+	p.broadcast()
+	defer func() {
+		p.flushRequests--
+	}()
+
+	for len(p.queue) > 0 || p.inFlight > 0 {
+		if err := p.wait(ctx, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DrainError reports the messages a Producer could not deliver while closing.
+type DrainError struct {
+	// Pending is the number of messages still queued or in flight when the context was done.
+	// They are still sent in the background, a later Close waits for them again.
+	Pending int
+	// Failed is the number of messages whose delivery failed while closing, also passed to
+	// the ErrorHandler.
+	Failed int
+	// Err joins the error of the context, if done, and the first delivery failure.
+	Err error
+}
+
+func (e *DrainError) Error() string {
+	return fmt.Sprintf("producer: %d message(s) pending and %d failed while closing: %v", e.Pending, e.Failed, e.Err)
+}
+
+func (e *DrainError) Unwrap() error {
+	return e.Err
+}
+
+// Close stops accepting messages, sends the queued ones and waits for the background
+// sender to exit, or until ctx is done. It returns a *DrainError when messages could not
+// be delivered in time or failed to be delivered while closing. Once ctx is done, the requests
+// waiting to be retried fail at once instead of waiting for their backoff.
+func (p *Producer) Close(ctx context.Context) error {
+	p.mtx.Lock()
+	if !p.closed {
+		p.closed = true
+		p.broadcast()
+	}
+	p.mtx.Unlock()
+
+	var ctxErr error
+	select {
+	case <-p.done:
+	case <-ctx.Done():
+		ctxErr = ctx.Err()
+		p.abandonedOnce.Do(func() { close(p.abandoned) })
+	}
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	drainErr := &DrainError{Failed: p.drainFailed}
+	if ctxErr != nil {
+		drainErr.Pending = len(p.queue) + p.inFlight
+	}
+	if drainErr.Pending == 0 && drainErr.Failed == 0 {
+		return nil
+	}
+	drainErr.Err = errors.Join(ctxErr, p.drainErr)
+	return drainErr
+}
+
+// enqueue adds a message to the queue, applying the overflow policy. Must hold p.mtx.
+func (p *Producer) enqueue(ctx context.Context, queued queuedMessage) error {
+	size := messageSize(queued.message)
+	for {
+		if p.closed {
+			return ErrClosed
+		}
+		if p.fits(size) {
+			if queued.delivery != nil {
+				queued.delivery.pending++
+			}
+			p.queue = append(p.queue, queued)
+			p.queuedBytes += size
+			p.broadcast()
+			return nil
+		}
+
+		switch p.opts.Overflow {
+		case OverflowFail:
+			return ErrQueueFull
+		case OverflowDropNewest:
+			if queued.delivery != nil {
+				return ErrQueueFull
+			}
+			p.opts.ErrorHandler(ErrQueueFull, []iggcon.MessengerMessage{queued.message})
+			return nil
+		case OverflowDropOldest:
+			if len(p.queue) == 0 {
+				// the message alone exceeds the queue bounds
+				return ErrQueueFull
+			}
+			dropped := p.queue[0]
+			p.queue = p.queue[1:]
+			p.queuedBytes -= messageSize(dropped.message)
+			if dropped.delivery != nil {
+				dropped.delivery.settle(&dropped.message, &requestFailure{err: ErrQueueFull})
+			}
+			p.opts.ErrorHandler(ErrQueueFull, []iggcon.MessengerMessage{dropped.message})
+		default:
+			if len(p.queue) == 0 && p.inFlight == 0 {
+				return ErrQueueFull
+			}
+			if err := p.wait(ctx, nil); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (p *Producer) fits(size int) bool {
+	if p.opts.MaxQueuedMessages > 0 && len(p.queue)+1 > p.opts.MaxQueuedMessages {
+		return false
+	}
+	if p.opts.MaxQueuedBytes > 0 && p.queuedBytes+size > p.opts.MaxQueuedBytes {
+		return false
+	}
+	return true
+}
+
+// run is the background sender loop, dispatching the batches of the partitions with less than
+// MaxInFlightRequests requests in flight.
+func (p *Producer) run() {
+	defer close(p.done)
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	for {
+		for !p.closed && !p.sendable() {
+			_ = p.wait(context.Background(), nil)
+		}
+		if p.closed && !p.sendable() {
+			if len(p.queue) == 0 && p.inFlight == 0 {
+				return
+			}
+			_ = p.wait(context.Background(), nil)
+			continue
+		}
+		p.linger()
+		if !p.sendable() {
+			continue
+		}
+
+		confirmation, partition, batch, deliveries := p.takeBatch()
+		if rotator, ok := p.opts.Partitioner.(interface{ Rotate() }); ok {
+			// the next messages without a key start a batch on another partition
+			rotator.Rotate()
+		}
+		partitioning := p.opts.Partitioning
+		if partition != 0 {
+			partitioning = iggcon.PartitionId(partition)
+		}
+		p.partitionRequests[partition]++
+		go func() {
+			failures := p.sendBatch(confirmation, partitioning, batch)
+			p.mtx.Lock()
+			defer p.mtx.Unlock()
+			for i, d := range deliveries {
+				if d != nil {
+					d.settle(&batch[i], failureAt(failures, i))
+				}
+			}
+			if len(failures) > 0 && p.closed {
+				for _, failure := range failures {
+					p.drainFailed += failure.end - failure.start
+				}
+				if p.drainErr == nil {
+					p.drainErr = failures[0].err
+				}
+			}
+			p.inFlight -= len(batch)
+			if p.partitionRequests[partition]--; p.partitionRequests[partition] == 0 {
+				delete(p.partitionRequests, partition)
+			}
+			p.broadcast()
+		}()
+	}
+}
+
+// sendable reports whether a queued message belongs to a partition accepting one more request
+// in flight. Must hold p.mtx.
+func (p *Producer) sendable() bool {
+	return p.nextSendable() >= 0
+}
+
+// nextSendable returns the index of the oldest queued message of a partition accepting one more
+// request in flight, -1 when there is none. Must hold p.mtx.
+func (p *Producer) nextSendable() int {
+	for i, queued := range p.queue {
+		if p.partitionRequests[queued.partition] < p.opts.MaxInFlightRequests {
+			return i
+		}
+	}
+	return -1
+}
+
+// sendBatch sends the batch in as many requests as the maximum request size requires, in order,
+// and returns the failed requests. When the server rejects a request as too large, the limit is
+// halved and the request split again. Only the failed requests are retried, so the messages of
+// the other requests are not duplicated. Called by run in a goroutine per batch.
+func (p *Producer) sendBatch(confirmation iggcon.Confirmation, partitioning iggcon.Partitioning, batch []iggcon.MessengerMessage) []*requestFailure {
+	var failures []*requestFailure
+	for start := 0; start < len(batch); {
+		count, size := p.requestLength(partitioning, batch[start:])
+		err := p.sendWithRetries(confirmation, partitioning, batch[start:start+count])
+		if isRequestTooLarge(err) && count > 1 {
+			// bisect the limit until the server accepts the requests
+			p.maxRequestSize.Store(int64(size / 2))
+			continue
+		}
+		if err != nil {
+			p.opts.ErrorHandler(err, batch[start:start+count])
+			failures = append(failures, &requestFailure{start: start, end: start + count, err: err})
+		}
+		start += count
+	}
+	return failures
+}
+
+// sendWithRetries sends a request, retrying the transport errors up to Retries times, until a
+// Close gives up draining.
+func (p *Producer) sendWithRetries(confirmation iggcon.Confirmation, partitioning iggcon.Partitioning, messages []iggcon.MessengerMessage) error {
+	backoff := p.opts.RetryBackoff
+	var timer *time.Timer
+	for attempt := 0; ; attempt++ {
+		err := p.send(confirmation, partitioning, messages)
+		var messengerErr *ierror.MessengerError
+		if err == nil || errors.As(err, &messengerErr) || attempt >= p.opts.Retries {
+			return err
+		}
+		if timer == nil {
+			timer = time.NewTimer(backoff)
+			defer timer.Stop()
+		} else {
+			timer.Reset(backoff)
+		}
+		select {
+		case <-timer.C:
+		case <-p.abandoned:
+			return err
+		}
+		backoff *= 2
+	}
+}
+
+func (p *Producer) send(confirmation iggcon.Confirmation, partitioning iggcon.Partitioning, messages []iggcon.MessengerMessage) error {
+	if p.opts.ResultHandler != nil {
+		result, err := p.client.SendMessagesWithResult(p.streamId, p.topicId, partitioning, messages, confirmation)
+		if err == nil && result != nil {
+			p.opts.ResultHandler(*result, messages)
+		}
+		return err
+	}
+	if confirmation == iggcon.ConfirmationDefault {
+		return p.client.SendMessages(p.streamId, p.topicId, partitioning, messages)
+	}
+	return p.client.SendMessagesWithConfirmation(p.streamId, p.topicId, partitioning, messages, confirmation)
+}
+
+// requestLength returns how many of the messages fit into a request and the size of that request.
+// A request holds at least one message.
+func (p *Producer) requestLength(partitioning iggcon.Partitioning, messages []iggcon.MessengerMessage) (int, int) {
+	size := 4 + (2 + p.streamId.Length) + (2 + p.topicId.Length) + (2 + partitioning.Length) + 4 + 1
+	for i, message := range messages {
+		// indexes are 16 bytes, and the header size leaves room for the padding of any dialect
+		messageSize := 16 + iggcon.IggyMessageHeaderSize + len(message.Payload) + len(message.UserHeaders)
+		if maxRequestSize := int(p.maxRequestSize.Load()); i > 0 && maxRequestSize > 0 && size+messageSize > maxRequestSize {
+			return i, size
+		}
+		size += messageSize
+	}
+	return len(messages), size
+}
+
+func isRequestTooLarge(err error) bool {
+	var messengerErr *ierror.MessengerError
+	return errors.As(err, &messengerErr) && messengerErr.Code == ierror.InvalidMessagesSize.Code
+}
+
+// linger waits for a partial batch to fill up, unless a flush or close is requested. Must hold p.mtx.
+func (p *Producer) linger() {
+	if p.opts.Linger <= 0 {
+		return
+	}
+	timer := time.NewTimer(p.opts.Linger)
+	defer timer.Stop()
+	for len(p.queue) < p.opts.BatchSize && !p.closed && p.flushRequests == 0 {
+		if p.wait(context.Background(), timer.C) != nil {
+			return
+		}
+	}
+}
+
+// takeBatch removes up to BatchSize messages sharing the confirmation level and the partition of
+// the oldest sendable one from the queue, along with the deliveries waiting for each of them, nil
+// for the messages nobody waits for. The messages
+// of the other partitions are skipped, but not those of the same partition with another
+// confirmation level, which ends the batch to keep the partition in order. Must hold p.mtx and
+// have a sendable message.
+func (p *Producer) takeBatch() (iggcon.Confirmation, uint32, []iggcon.MessengerMessage, []*delivery) {
+	first := p.queue[p.nextSendable()]
+	confirmation, partition := first.confirmation, first.partition
+	var batch []iggcon.MessengerMessage
+	var deliveries []*delivery
+	remaining := p.queue[:0]
+	for i, queued := range p.queue {
+		if queued.partition != partition || len(batch) == p.opts.BatchSize {
+			remaining = append(remaining, queued)
+			continue
+		}
+		if queued.confirmation != confirmation {
+			remaining = append(remaining, p.queue[i:]...)
+			break
+		}
+		batch = append(batch, queued.message)
+		p.queuedBytes -= messageSize(queued.message)
+		deliveries = append(deliveries, queued.delivery)
+	}
+	clear(p.queue[len(remaining):])
+	p.queue = remaining
+	p.inFlight += len(batch)
+	p.broadcast()
+	return confirmation, partition, batch, deliveries
+}
+
+var errTimeout = errors.New("producer: timeout")
+
+// wait releases p.mtx until the state changes, ctx is done or timeout fires. Must hold p.mtx.
+func (p *Producer) wait(ctx context.Context, timeout <-chan time.Time) error {
+	changed := p.changed
+	p.mtx.Unlock()
+	defer p.mtx.Lock()
+
+	select {
+	case <-changed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timeout:
+		return errTimeout
+	}
+}
+
+// broadcast wakes up every waiter. Must hold p.mtx.
+func (p *Producer) broadcast() {
+	close(p.changed)
+	p.changed = make(chan struct{})
+}
+
+func messageSize(message iggcon.MessengerMessage) int {
+	return iggcon.MessageHeaderSize + len(message.Payload) + len(message.UserHeaders)
+}
@@ -0,0 +1,824 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package producer
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	ierror "github.com/apache/messenger/foreign/go/errors"
+	"github.com/apache/messenger/foreign/go/messengercli"
+	"github.com/apache/messenger/foreign/go/ratelimit"
+)
+
+type fakeClient struct {
+	messengercli.Client
+	mtx     sync.Mutex
+	release chan struct{}
+	sent    [][]iggcon.MessengerMessage
+	// partitionings records the partitioning of every batch.
+	partitionings []iggcon.Partitioning
+	// confirmed records the confirmation level of every batch sent with one.
+	confirmed []iggcon.Confirmation
+	err       error
+}
+
+func (c *fakeClient) SendMessagesWithConfirmation(streamId, topicId iggcon.Identifier, partitioning iggcon.Partitioning, messages []iggcon.MessengerMessage, confirmation iggcon.Confirmation) error {
+	c.mtx.Lock()
+	c.confirmed = append(c.confirmed, confirmation)
+	err := c.err
+	c.mtx.Unlock()
+	if err != nil {
+		return err
+	}
+	return c.SendMessages(streamId, topicId, partitioning, messages)
+}
+
+func (c *fakeClient) SendMessages(_, _ iggcon.Identifier, partitioning iggcon.Partitioning, messages []iggcon.MessengerMessage) error {
+	if c.release != nil {
+		<-c.release
+	}
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.sent = append(c.sent, messages)
+	c.partitionings = append(c.partitionings, partitioning)
+	return nil
+}
+
+func (c *fakeClient) SendMessagesWithResult(streamId, topicId iggcon.Identifier, partitioning iggcon.Partitioning, messages []iggcon.MessengerMessage, confirmation iggcon.Confirmation) (*iggcon.SendResult, error) {
+	if err := c.SendMessagesWithConfirmation(streamId, topicId, partitioning, messages, confirmation); err != nil {
+		return nil, err
+	}
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	result := &iggcon.SendResult{Timestamps: make([]uint64, len(messages))}
+	for _, batch := range c.sent[:len(c.sent)-1] {
+		result.BaseOffset += uint64(len(batch))
+	}
+	return result, nil
+}
+
+func (c *fakeClient) sentCount() int {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	count := 0
+	for _, batch := range c.sent {
+		count += len(batch)
+	}
+	return count
+}
+
+func newTestMessage(t *testing.T, payload string) iggcon.MessengerMessage {
+	message, err := iggcon.NewMessengerMessage([]byte(payload))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return message
+}
+
+func newTestProducer(t *testing.T, client *fakeClient, options ...Option) *Producer {
+	streamId, _ := iggcon.NewIdentifier("stream")
+	topicId, _ := iggcon.NewIdentifier("topic")
+	p, err := NewProducer(client, streamId, topicId, options...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestProducer_FlushSendsEverything(t *testing.T) {
+	client := &fakeClient{}
+	p := newTestProducer(t, client, WithBatchSize(2), WithLinger(time.Hour))
+
+	for i := 0; i < 5; i++ {
+		if err := p.Send(context.Background(), newTestMessage(t, "message")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := p.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if count := client.sentCount(); count != 5 {
+		t.Fatalf("expected 5 messages to be sent, got %d", count)
+	}
+	if err := p.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Send(context.Background(), newTestMessage(t, "late")); !errors.Is(err, ErrClosed) {
+		t.Fatalf("expected ErrClosed after Close, got %v", err)
+	}
+}
+
+func TestProducer_OverflowPolicies(t *testing.T) {
+	tests := []struct {
+		name      string
+		policy    OverflowPolicy
+		wantErr   error
+		wantDrops int
+	}{
+		{name: "fail", policy: OverflowFail, wantErr: ErrQueueFull},
+		{name: "drop newest", policy: OverflowDropNewest, wantDrops: 1},
+		{name: "drop oldest", policy: OverflowDropOldest, wantDrops: 1},
+		{name: "block", policy: OverflowBlock, wantErr: context.DeadlineExceeded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeClient{release: make(chan struct{})}
+			var dropped []iggcon.MessengerMessage
+			p := newTestProducer(t, client,
+				WithBatchSize(1),
+				WithLinger(0),
+				WithMaxQueued(1, 0),
+				WithOverflowPolicy(tt.policy),
+				WithErrorHandler(func(err error, messages []iggcon.MessengerMessage) {
+					dropped = append(dropped, messages...)
+				}))
+
+			// the first message is picked up by the sender, which blocks until released
+			_ = p.Send(context.Background(), newTestMessage(t, "in-flight"))
+			waitFor(t, func() bool {
+				p.mtx.Lock()
+				defer p.mtx.Unlock()
+				return p.inFlight == 1
+			})
+			_ = p.Send(context.Background(), newTestMessage(t, "queued"))
+
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+			err := p.Send(ctx, newTestMessage(t, "overflow"))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if len(dropped) != tt.wantDrops {
+				t.Fatalf("expected %d dropped message(s), got %d", tt.wantDrops, len(dropped))
+			}
+
+			close(client.release)
+			_ = p.Close(context.Background())
+		})
+	}
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	deadline := time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestProducer_SendWithConfirmation(t *testing.T) {
+	client := &fakeClient{}
+	handled := make(chan struct{}, 1)
+	p := newTestProducer(t, client, WithLinger(time.Millisecond), WithErrorHandler(func(error, []iggcon.MessengerMessage) {
+		handled <- struct{}{}
+	}))
+
+	if err := p.Send(context.Background(), newTestMessage(t, "fire-and-forget")); err != nil {
+		t.Fatal(err)
+	}
+	// waits for the acknowledgement, the fire-and-forget message is sent in its own batch
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := p.SendWithConfirmation(ctx, iggcon.ConfirmationFsync, newTestMessage(t, "durable")); err != nil {
+		t.Fatal(err)
+	}
+	client.mtx.Lock()
+	if len(client.sent) != 2 || len(client.confirmed) != 1 || client.confirmed[0] != iggcon.ConfirmationFsync {
+		t.Fatalf("unexpected batches %v with confirmations %v", client.sent, client.confirmed)
+	}
+	sendErr := errors.New("not replicated")
+	client.err = sendErr
+	client.mtx.Unlock()
+
+	if err := p.SendWithConfirmation(ctx, iggcon.ConfirmationWrite, newTestMessage(t, "lost")); !errors.Is(err, sendErr) {
+		t.Fatalf("expected the delivery error, got %v", err)
+	}
+	<-handled
+	if err := p.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// sizeLimitedClient rejects the requests carrying more than maxMessages messages, like a server
+// rejecting requests above its maximum size.
+type sizeLimitedClient struct {
+	fakeClient
+	maxMessages int
+	rejected    int
+}
+
+func (c *sizeLimitedClient) SendMessages(streamId, topicId iggcon.Identifier, partitioning iggcon.Partitioning, messages []iggcon.MessengerMessage) error {
+	if len(messages) > c.maxMessages {
+		c.rejected++
+		return ierror.MapFromCode(ierror.InvalidMessagesSize.Code)
+	}
+	return c.fakeClient.SendMessages(streamId, topicId, partitioning, messages)
+}
+
+func TestProducer_SplitsOversizedBatches(t *testing.T) {
+	client := &sizeLimitedClient{maxMessages: 3}
+	streamId, _ := iggcon.NewIdentifier("stream")
+	topicId, _ := iggcon.NewIdentifier("topic")
+	p, err := NewProducer(client, streamId, topicId, WithBatchSize(10), WithLinger(time.Hour), WithErrorHandler(func(err error, _ []iggcon.MessengerMessage) {
+		t.Errorf("unexpected delivery failure: %v", err)
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 10; i++ {
+		if err := p.Send(context.Background(), newTestMessage(t, strconv.Itoa(i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := p.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	var payloads []string
+	for _, batch := range client.sent {
+		if len(batch) > 3 {
+			t.Errorf("batch of %d messages exceeds the limit", len(batch))
+		}
+		for _, message := range batch {
+			payloads = append(payloads, string(message.Payload))
+		}
+	}
+	if strings.Join(payloads, ",") != "0,1,2,3,4,5,6,7,8,9" {
+		t.Errorf("messages were not delivered in order: %v", payloads)
+	}
+	if client.rejected == 0 || client.rejected > 3 {
+		t.Errorf("expected the limit to be discovered in a few attempts, got %d rejections", client.rejected)
+	}
+}
+
+func TestProducer_Partitioner(t *testing.T) {
+	client := &fakeClient{}
+	partitioner := iggcon.Murmur2Partitioner()
+	p := newTestProducer(t, client,
+		WithPartitioner(partitioner, func(message iggcon.MessengerMessage) []byte {
+			key, _, _ := strings.Cut(string(message.Payload), "-")
+			return []byte(key)
+		}),
+		WithPartitionsCount(4),
+		WithLinger(time.Hour),
+	)
+	keys := []string{"alice", "bob", "carol", "dave", "erin"}
+	for i := 0; i < 20; i++ {
+		key := keys[i%len(keys)]
+		if err := p.Send(context.Background(), newTestMessage(t, key+"-"+strconv.Itoa(i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := p.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	client.mtx.Lock()
+	defer client.mtx.Unlock()
+	last := map[string]int{}
+	for i, batch := range client.sent {
+		partitioning := client.partitionings[i]
+		if partitioning.Kind != iggcon.PartitionIdKind {
+			t.Fatalf("expected the batches to be sent to a partition, got %+v", partitioning)
+		}
+		for _, message := range batch {
+			key, sequence, _ := strings.Cut(string(message.Payload), "-")
+			if expected := iggcon.PartitionId(partitioner.Partition([]byte(key), 4)); !reflect.DeepEqual(partitioning, expected) {
+				t.Errorf("message %s: expected partitioning %+v, got %+v", message.Payload, expected, partitioning)
+			}
+			n, _ := strconv.Atoi(sequence)
+			if previous, ok := last[key]; ok && previous > n {
+				t.Errorf("message %s sent after message %d of the same key", message.Payload, previous)
+			}
+			last[key] = n
+		}
+	}
+	if len(client.sent) > 4 {
+		t.Errorf("expected a batch per partition, got %d batches", len(client.sent))
+	}
+}
+
+func TestProducer_SendPayload(t *testing.T) {
+	client := &fakeClient{}
+	p := newTestProducer(t, client,
+		WithPartitioner(iggcon.RoundRobinPartitioner(), nil),
+		WithPartitionsCount(3),
+		WithChunking(100),
+		WithLinger(time.Hour),
+	)
+	payload := []byte(strings.Repeat("0123456789", 25))
+	if err := p.SendPayload(context.Background(), payload); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.SendPayload(context.Background(), []byte("small")); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	client.mtx.Lock()
+	defer client.mtx.Unlock()
+	var chunks []iggcon.MessengerMessage
+	var partitionings []iggcon.Partitioning
+	for i, batch := range client.sent {
+		for _, message := range batch {
+			if _, ok, _ := message.Chunk(); ok {
+				chunks = append(chunks, message)
+				partitionings = append(partitionings, client.partitionings[i])
+			}
+		}
+	}
+	if len(chunks) != 3 {
+		t.Fatalf("expected 3 chunks, got %d", len(chunks))
+	}
+	var joined []byte
+	for i, chunk := range chunks {
+		if !reflect.DeepEqual(partitionings[i], partitionings[0]) {
+			t.Errorf("chunk %d: expected partitioning %+v, got %+v", i, partitionings[0], partitionings[i])
+		}
+		joined = append(joined, chunk.Payload...)
+	}
+	if string(joined) != string(payload) {
+		t.Fatalf("expected the chunks to hold the payload, got %q", joined)
+	}
+
+	unchunked := newTestProducer(t, &fakeClient{})
+	err := unchunked.SendPayload(context.Background(), make([]byte, iggcon.MaxPayloadSize+1))
+	if !errors.Is(err, ierror.TooBigUserMessagePayload) {
+		t.Fatalf("expected the payload to be too big without chunking, got %v", err)
+	}
+}
+
+func TestProducer_StickyPartitioning(t *testing.T) {
+	client := &fakeClient{}
+	p := newTestProducer(t, client, WithStickyPartitioning(nil), WithPartitionsCount(3), WithLinger(time.Hour))
+	for round := 0; round < 2; round++ {
+		for i := 0; i < 10; i++ {
+			if err := p.Send(context.Background(), newTestMessage(t, strconv.Itoa(i))); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := p.Flush(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	client.mtx.Lock()
+	defer client.mtx.Unlock()
+	if len(client.sent) != 2 || len(client.sent[0]) != 10 || len(client.sent[1]) != 10 {
+		t.Fatalf("expected a full batch per flush, got %d batches", len(client.sent))
+	}
+	if reflect.DeepEqual(client.partitionings[0], client.partitionings[1]) {
+		t.Errorf("expected the flush to rotate the partition, both batches went to %+v", client.partitionings[0])
+	}
+}
+
+func TestProducer_PartitionerUsesMessageKeys(t *testing.T) {
+	client := &fakeClient{}
+	partitioner := iggcon.XXHashPartitioner()
+	p := newTestProducer(t, client, WithPartitioner(partitioner, nil), WithPartitionsCount(8))
+	message, err := iggcon.NewMessengerMessage([]byte("payload"), iggcon.WithKey([]byte("order-42")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = p.Send(context.Background(), message); err != nil {
+		t.Fatal(err)
+	}
+	if err = p.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	client.mtx.Lock()
+	defer client.mtx.Unlock()
+	expected := iggcon.PartitionId(partitioner.Partition([]byte("order-42"), 8))
+	if len(client.partitionings) != 1 || !reflect.DeepEqual(client.partitionings[0], expected) {
+		t.Errorf("expected the message to be sent with %+v, got %+v", expected, client.partitionings)
+	}
+}
+
+// flakyClient fails the first sends with a transport error and records the concurrent sends.
+type flakyClient struct {
+	*fakeClient
+	failures    int
+	inFlight    map[uint32]int
+	maxInFlight map[uint32]int
+	maxTotal    int
+	total       int
+}
+
+func (c *flakyClient) SendMessages(streamId, topicId iggcon.Identifier, partitioning iggcon.Partitioning, messages []iggcon.MessengerMessage) error {
+	partition := binary.LittleEndian.Uint32(partitioning.Value)
+	c.mtx.Lock()
+	c.inFlight[partition]++
+	c.total++
+	c.maxInFlight[partition] = max(c.maxInFlight[partition], c.inFlight[partition])
+	c.maxTotal = max(c.maxTotal, c.total)
+	fail := c.failures > 0
+	if fail {
+		c.failures--
+	}
+	c.mtx.Unlock()
+
+	time.Sleep(5 * time.Millisecond)
+	var err error
+	if fail {
+		err = errors.New("connection reset by peer")
+	} else {
+		err = c.fakeClient.SendMessages(streamId, topicId, partitioning, messages)
+	}
+	c.mtx.Lock()
+	c.inFlight[partition]--
+	c.total--
+	c.mtx.Unlock()
+	return err
+}
+
+func TestProducer_InFlightRequestsPerPartition(t *testing.T) {
+	client := &flakyClient{fakeClient: &fakeClient{}, failures: 3, inFlight: map[uint32]int{}, maxInFlight: map[uint32]int{}}
+	streamId, _ := iggcon.NewIdentifier("stream")
+	topicId, _ := iggcon.NewIdentifier("topic")
+	p, err := NewProducer(client, streamId, topicId,
+		WithPartitioner(iggcon.RoundRobinPartitioner(), nil),
+		WithPartitionsCount(2),
+		WithBatchSize(1),
+		WithLinger(0),
+		WithRetries(5, time.Millisecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		if err = p.Send(context.Background(), newTestMessage(t, strconv.Itoa(i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = p.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	client.mtx.Lock()
+	defer client.mtx.Unlock()
+	if client.maxInFlight[1] != 1 || client.maxInFlight[2] != 1 || client.maxTotal != 2 {
+		t.Errorf("expected a request in flight per partition, got %v and %d in total", client.maxInFlight, client.maxTotal)
+	}
+	last := map[uint32]int{}
+	for i, batch := range client.sent {
+		partition := binary.LittleEndian.Uint32(client.partitionings[i].Value)
+		n, _ := strconv.Atoi(string(batch[0].Payload))
+		if previous, ok := last[partition]; ok && previous > n {
+			t.Errorf("partition %d: message %d sent after message %d", partition, n, previous)
+		}
+		last[partition] = n
+	}
+	if len(client.sent) != 20 {
+		t.Errorf("expected the failed requests to be retried, got %d delivered", len(client.sent))
+	}
+}
+
+func TestProducer_CloseReportsUndrainedMessages(t *testing.T) {
+	client := &fakeClient{release: make(chan struct{})}
+	p := newTestProducer(t, client, WithBatchSize(1), WithLinger(0))
+	for i := 0; i < 3; i++ {
+		if err := p.Send(context.Background(), newTestMessage(t, "message")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := p.Close(ctx)
+	var drainErr *DrainError
+	if !errors.As(err, &drainErr) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected a DrainError for the deadline, got %v", err)
+	}
+	if drainErr.Pending != 3 || drainErr.Failed != 0 {
+		t.Fatalf("expected 3 pending messages, got %+v", drainErr)
+	}
+
+	close(client.release)
+	if err = p.Close(context.Background()); err != nil {
+		t.Fatalf("expected the second Close to drain the producer, got %v", err)
+	}
+}
+
+func TestProducer_CloseEndsRetryBackoff(t *testing.T) {
+	client := &fakeClient{err: errors.New("connection reset")}
+	p := newTestProducer(t, client, WithLinger(0), WithRetries(5, time.Hour), WithErrorHandler(func(error, []iggcon.MessengerMessage) {}))
+	if err := p.SendWithConfirmation(context.Background(), iggcon.ConfirmationNoWait, newTestMessage(t, "message")); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	var drainErr *DrainError
+	if err := p.Close(ctx); !errors.As(err, &drainErr) || drainErr.Pending != 1 {
+		t.Fatalf("expected the retried message to be pending, got %v", err)
+	}
+	select {
+	case <-p.done:
+	case <-time.After(time.Second):
+		t.Fatal("expected the sender to stop retrying once Close gave up")
+	}
+	if err := p.Close(context.Background()); !errors.As(err, &drainErr) || drainErr.Failed != 1 || !strings.Contains(err.Error(), "connection reset") {
+		t.Fatalf("expected the message to fail with the transport error, got %v", err)
+	}
+}
+
+func TestProducer_ResultHandler(t *testing.T) {
+	client := &fakeClient{}
+	var mtx sync.Mutex
+	var offsets []uint64
+	p := newTestProducer(t, client, WithBatchSize(2), WithLinger(time.Hour),
+		WithResultHandler(func(result iggcon.SendResult, messages []iggcon.MessengerMessage) {
+			mtx.Lock()
+			defer mtx.Unlock()
+			if result.Count() != len(messages) {
+				t.Errorf("expected a result for %d message(s), got %d", len(messages), result.Count())
+			}
+			for i := range messages {
+				offsets = append(offsets, result.Offset(i))
+			}
+		}))
+
+	for i := 0; i < 5; i++ {
+		if err := p.Send(context.Background(), newTestMessage(t, "message")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := p.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if expected := []uint64{0, 1, 2, 3, 4}; !reflect.DeepEqual(offsets, expected) {
+		t.Fatalf("expected offsets %v, got %v", expected, offsets)
+	}
+}
+
+func TestProducer_Quotas(t *testing.T) {
+	streamId, _ := iggcon.NewIdentifier("stream")
+	topicId, _ := iggcon.NewIdentifier("topic")
+	quotas := NewQuotas(ratelimit.Config{})
+	quotas.SetQuota(streamId, topicId, ratelimit.Config{MessagesPerSecond: 100, MessagesBurst: 10})
+
+	client := &fakeClient{}
+	p := newTestProducer(t, client, WithQuotas(quotas))
+	defer p.Close(context.Background())
+	otherTopicId, _ := iggcon.NewIdentifier("other")
+	other, err := NewProducer(client, streamId, otherTopicId, WithQuotas(quotas))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer other.Close(context.Background())
+
+	ctx := context.Background()
+	for i := 0; i < 10; i++ {
+		if err := p.Send(ctx, newTestMessage(t, strconv.Itoa(i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if stats := p.QuotaStats(); stats != (QuotaStats{}) {
+		t.Fatalf("expected the burst to pass unthrottled, got %+v", stats)
+	}
+
+	start := time.Now()
+	if err := p.Send(ctx, newTestMessage(t, "a"), newTestMessage(t, "b"), newTestMessage(t, "c")); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Fatalf("expected the send over the quota to be delayed, took %v", elapsed)
+	}
+	stats := quotas.Stats(streamId, topicId)
+	if stats.ThrottledSends != 1 || stats.ThrottledMessages != 3 || stats.ThrottledTime <= 0 {
+		t.Fatalf("unexpected throttling stats: %+v", stats)
+	}
+
+	// the other topic is not held back by the exhausted quota
+	for i := 0; i < 100; i++ {
+		if err := other.Send(ctx, newTestMessage(t, strconv.Itoa(i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if stats := other.QuotaStats(); stats != (QuotaStats{}) {
+		t.Fatalf("expected the other topic to be unthrottled, got %+v", stats)
+	}
+}
+
+type traceIdKey struct{}
+
+func TestProducer_HeaderProviders(t *testing.T) {
+	t.Setenv("TEST_POD_NAME", "pod-1")
+	client := &fakeClient{}
+	p := newTestProducer(t, client, WithHeaderProviders(
+		StaticHeaders(map[string]string{"region": "eu-west-1", "version": "1.2.3"}),
+		EnvHeaders(map[string]string{"pod": "TEST_POD_NAME", "node": "TEST_NODE_NAME"}),
+		ContextHeader("trace-id", func(ctx context.Context) (string, bool) {
+			traceId, ok := ctx.Value(traceIdKey{}).(string)
+			return traceId, ok
+		}),
+	))
+
+	ctx := context.WithValue(context.Background(), traceIdKey{}, "trace-1")
+	explicit, err := iggcon.NewMessengerMessage([]byte("explicit"), iggcon.WithUserHeaders(map[iggcon.HeaderKey]iggcon.HeaderValue{
+		{Value: "version"}: iggcon.NewStringHeaderValue("2.0.0"),
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Send(ctx, newTestMessage(t, "plain"), explicit); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Send(context.Background(), newTestMessage(t, "untraced")); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	header := func(message iggcon.MessengerMessage, key string) string {
+		value, ok := message.UserHeader(key)
+		if !ok {
+			return ""
+		}
+		s, _ := value.AsString()
+		return s
+	}
+	var messages []iggcon.MessengerMessage
+	for _, batch := range client.sent {
+		messages = append(messages, batch...)
+	}
+	if len(messages) != 3 {
+		t.Fatalf("expected 3 messages, got %d", len(messages))
+	}
+	for _, message := range messages {
+		if header(message, "region") != "eu-west-1" || header(message, "pod") != "pod-1" {
+			t.Fatalf("expected the static and environment headers on %q", message.Payload)
+		}
+		if _, ok := message.UserHeader("node"); ok {
+			t.Fatalf("expected the unset variable to be skipped on %q", message.Payload)
+		}
+	}
+	if header(messages[0], "version") != "1.2.3" || header(messages[0], "trace-id") != "trace-1" {
+		t.Fatal("expected the version and trace id on the plain message")
+	}
+	if header(messages[1], "version") != "2.0.0" {
+		t.Fatal("expected the header set on the message to take precedence")
+	}
+	if _, ok := messages[2].UserHeader("trace-id"); ok {
+		t.Fatal("expected no trace id without one in the context")
+	}
+}
+
+func TestProducer_Validators(t *testing.T) {
+	noDigits := ValidatorFunc(func(message iggcon.MessengerMessage) error {
+		if strings.ContainsAny(string(message.Payload), "0123456789") {
+			return errors.New("payload contains digits")
+		}
+		return nil
+	})
+
+	client := &fakeClient{}
+	p := newTestProducer(t, client, WithValidators(ValidationFailFast, MaxMessageSize(8), noDigits))
+	err := p.Send(context.Background(), newTestMessage(t, "valid"), newTestMessage(t, "card 4242"))
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) || validationErr.Index != 1 {
+		t.Fatalf("expected the second message to be rejected, got %v", err)
+	}
+	if err := p.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if client.sentCount() != 0 {
+		t.Fatalf("expected nothing sent on a rejection, got %d", client.sentCount())
+	}
+	if stats := p.ValidationStats(); stats != (ValidationStats{Validated: 2, Rejected: 1}) {
+		t.Fatalf("unexpected validation stats: %+v", stats)
+	}
+	_ = p.Close(context.Background())
+
+	var mtx sync.Mutex
+	var dropped []string
+	client = &fakeClient{}
+	p = newTestProducer(t, client,
+		WithValidators(ValidationDropAndReport, RequiredHeaders("tenant")),
+		WithErrorHandler(func(err error, messages []iggcon.MessengerMessage) {
+			mtx.Lock()
+			defer mtx.Unlock()
+			if errors.As(err, &validationErr) {
+				for _, message := range messages {
+					dropped = append(dropped, string(message.Payload))
+				}
+			}
+		}),
+	)
+	tagged, err := iggcon.NewMessengerMessage([]byte("tagged"), iggcon.WithUserHeaders(map[iggcon.HeaderKey]iggcon.HeaderValue{
+		{Value: "tenant"}: iggcon.NewStringHeaderValue("acme"),
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Send(context.Background(), newTestMessage(t, "untagged"), tagged); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if client.sentCount() != 1 || string(client.sent[0][0].Payload) != "tagged" {
+		t.Fatalf("expected only the tagged message to be sent, got %v", client.sent)
+	}
+	if !reflect.DeepEqual(dropped, []string{"untagged"}) {
+		t.Fatalf("expected the untagged message to be reported, got %v", dropped)
+	}
+	if stats := p.ValidationStats(); stats != (ValidationStats{Validated: 2, Rejected: 1, Dropped: 1}) {
+		t.Fatalf("unexpected validation stats: %+v", stats)
+	}
+}
+
+// rejectingClient rejects the requests carrying a message with the payload bad.
+type rejectingClient struct {
+	fakeClient
+	bad string
+}
+
+func (c *rejectingClient) SendMessagesWithConfirmation(streamId, topicId iggcon.Identifier, partitioning iggcon.Partitioning, messages []iggcon.MessengerMessage, confirmation iggcon.Confirmation) error {
+	for _, message := range messages {
+		if string(message.Payload) == c.bad {
+			return ierror.InvalidMessagePayloadLength
+		}
+	}
+	return c.fakeClient.SendMessagesWithConfirmation(streamId, topicId, partitioning, messages, confirmation)
+}
+
+func TestProducer_PartialBatchFailure(t *testing.T) {
+	client := &rejectingClient{bad: "x"}
+	streamId, _ := iggcon.NewIdentifier("stream")
+	topicId, _ := iggcon.NewIdentifier("topic")
+	// a request holds two messages of a single byte
+	p, err := NewProducer(client, streamId, topicId, WithConfirmation(iggcon.ConfirmationWrite), WithMaxRequestSize(250),
+		WithRetries(3, time.Millisecond), WithErrorHandler(func(error, []iggcon.MessengerMessage) {}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close(context.Background())
+
+	messages := []iggcon.MessengerMessage{newTestMessage(t, "0"), newTestMessage(t, "1"), newTestMessage(t, "x"), newTestMessage(t, "3")}
+	err = p.Send(context.Background(), messages...)
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("expected a batch error, got %v", err)
+	}
+	if !errors.Is(err, ierror.InvalidMessagePayloadLength) {
+		t.Fatalf("expected the batch error to wrap the error of the server, got %v", err)
+	}
+	var failed []string
+	for _, message := range batchErr.Failed() {
+		failed = append(failed, string(message.Payload))
+	}
+	if batchErr.Delivered != 2 || len(batchErr.Failures) != 1 || !reflect.DeepEqual(failed, []string{"x", "3"}) {
+		t.Fatalf("expected the second request to fail alone, got %d delivered and %v failed", batchErr.Delivered, failed)
+	}
+	if client.sentCount() != 2 {
+		t.Fatalf("expected only the failed request to be retried, got %d messages sent", client.sentCount())
+	}
+
+	// resending the failed subset does not duplicate the delivered messages
+	client.bad = ""
+	if err := p.Send(context.Background(), batchErr.Failed()...); err != nil {
+		t.Fatal(err)
+	}
+	var payloads []string
+	for _, batch := range client.sent {
+		for _, message := range batch {
+			payloads = append(payloads, string(message.Payload))
+		}
+	}
+	if !reflect.DeepEqual(payloads, []string{"0", "1", "x", "3"}) {
+		t.Fatalf("expected every message delivered once, got %v", payloads)
+	}
+}
@@ -0,0 +1,123 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package producer
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/ratelimit"
+)
+
+// Quotas holds the send quotas of the topics, in messages and bytes per second. It is shared by
+// the producers created WithQuotas, possibly over different clients, so the producers of a topic
+// share its quota and a busy topic cannot starve the others. A topic is told apart by the
+// identifiers of its stream and itself, so the producers of a topic have to refer to it
+// consistently, either by IDs or by names.
+type Quotas struct {
+	mtx          sync.Mutex
+	defaultQuota ratelimit.Config
+	topics       map[topicKey]*topicQuota
+}
+
+// QuotaStats reports how the producers of a topic were throttled by its quota.
+type QuotaStats struct {
+	// ThrottledSends is the number of Send calls delayed by the quota.
+	ThrottledSends uint64
+	// ThrottledMessages is the number of messages of the delayed Send calls.
+	ThrottledMessages uint64
+	// ThrottledTime is the total time the Send calls were delayed.
+	ThrottledTime time.Duration
+}
+
+type topicKey struct {
+	streamKind iggcon.IdKind
+	stream     string
+	topicKind  iggcon.IdKind
+	topic      string
+}
+
+type topicQuota struct {
+	limiter atomic.Pointer[ratelimit.Limiter]
+
+	throttledSends    atomic.Uint64
+	throttledMessages atomic.Uint64
+	throttledTime     atomic.Int64
+}
+
+// NewQuotas creates the quotas of the topics, defaultQuota applying to the topics without a
+// quota of their own. A zero rate means unlimited.
+func NewQuotas(defaultQuota ratelimit.Config) *Quotas {
+	return &Quotas{
+		defaultQuota: defaultQuota,
+		topics:       map[topicKey]*topicQuota{},
+	}
+}
+
+// SetQuota sets the quota of a topic, replacing the default one. It applies to the following
+// Send calls of the producers already created.
+func (q *Quotas) SetQuota(streamId, topicId iggcon.Identifier, quota ratelimit.Config) {
+	q.topic(streamId, topicId).limiter.Store(ratelimit.NewLimiter(quota))
+}
+
+// Stats returns how the producers of a topic were throttled so far.
+func (q *Quotas) Stats(streamId, topicId iggcon.Identifier) QuotaStats {
+	return q.topic(streamId, topicId).stats()
+}
+
+// topic returns the quota of a topic, created from the default quota on first use.
+func (q *Quotas) topic(streamId, topicId iggcon.Identifier) *topicQuota {
+	key := topicKey{
+		streamKind: streamId.Kind,
+		stream:     string(streamId.Value),
+		topicKind:  topicId.Kind,
+		topic:      string(topicId.Value),
+	}
+
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+	quota, ok := q.topics[key]
+	if !ok {
+		quota = &topicQuota{}
+		quota.limiter.Store(ratelimit.NewLimiter(q.defaultQuota))
+		q.topics[key] = quota
+	}
+	return quota
+}
+
+// wait blocks until the quota allows count messages of total size bytes, or ctx is done.
+func (tq *topicQuota) wait(ctx context.Context, count int, size int) error {
+	delay, err := tq.limiter.Load().Throttle(ctx, count, size)
+	if delay > 0 {
+		tq.throttledSends.Add(1)
+		tq.throttledMessages.Add(uint64(count))
+		tq.throttledTime.Add(int64(delay))
+	}
+	return err
+}
+
+func (tq *topicQuota) stats() QuotaStats {
+	return QuotaStats{
+		ThrottledSends:    tq.throttledSends.Load(),
+		ThrottledMessages: tq.throttledMessages.Load(),
+		ThrottledTime:     time.Duration(tq.throttledTime.Load()),
+	}
+}
@@ -0,0 +1,166 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package producer
+
+import (
+	"fmt"
+	"strconv"
+	"sync/atomic"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+)
+
+// Validator checks a message before it is enqueued, like its size, its schema, its required
+// headers or the personal data of its payload, returning an error to reject it.
+type Validator interface {
+	Validate(message iggcon.MessengerMessage) error
+}
+
+// ValidatorFunc adapts a function to a Validator, e.g. the Check method of a schema.Rule.
+type ValidatorFunc func(message iggcon.MessengerMessage) error
+
+func (f ValidatorFunc) Validate(message iggcon.MessengerMessage) error {
+	return f(message)
+}
+
+// ValidationPolicy decides what Send does with the messages rejected by a Validator.
+type ValidationPolicy int
+
+const (
+	// ValidationFailFast makes Send return a *ValidationError without enqueuing any message.
+	ValidationFailFast ValidationPolicy = iota
+	// ValidationDropAndReport drops the rejected messages, passing them to the ErrorHandler with
+	// their *ValidationError, and enqueues the others.
+	ValidationDropAndReport
+)
+
+// ValidationError is returned for a message rejected by a Validator.
+type ValidationError struct {
+	// Index is the position of the message among the messages passed to Send.
+	Index int
+	Err   error
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("producer: message %d is invalid: %v", e.Index, e.Err)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationStats reports the messages checked by the validators of a Producer.
+type ValidationStats struct {
+	// Validated is the number of messages checked.
+	Validated uint64
+	// Rejected is the number of messages rejected by a Validator.
+	Rejected uint64
+	// Dropped is the number of messages dropped by ValidationDropAndReport, including the chunks
+	// dropped along with a rejected one.
+	Dropped uint64
+}
+
+type validationStats struct {
+	validated atomic.Uint64
+	rejected  atomic.Uint64
+	dropped   atomic.Uint64
+}
+
+// MaxMessageSize rejects the messages whose payload and user headers exceed bytes.
+func MaxMessageSize(bytes int) Validator {
+	return ValidatorFunc(func(message iggcon.MessengerMessage) error {
+		if size := len(message.Payload) + len(message.UserHeaders); size > bytes {
+			return fmt.Errorf("producer: message of %d bytes exceeds %d bytes", size, bytes)
+		}
+		return nil
+	})
+}
+
+// RequiredHeaders rejects the messages missing any of the given user headers.
+func RequiredHeaders(keys ...string) Validator {
+	return ValidatorFunc(func(message iggcon.MessengerMessage) error {
+		headers, err := iggcon.DeserializeHeaders(message.UserHeaders)
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			if _, ok := headers[iggcon.HeaderKey{Value: key}]; !ok {
+				return fmt.Errorf("producer: missing header %s", strconv.Quote(key))
+			}
+		}
+		return nil
+	})
+}
+
+// ValidationStats returns the messages checked and rejected by the validators so far.
+func (p *Producer) ValidationStats() ValidationStats {
+	return ValidationStats{
+		Validated: p.validation.validated.Load(),
+		Rejected:  p.validation.rejected.Load(),
+		Dropped:   p.validation.dropped.Load(),
+	}
+}
+
+// validate runs the validators over the messages and returns the valid ones, applying the
+// ValidationPolicy to the others. With samePartition, the messages are the chunks of a single
+// payload and are all dropped along with a rejected one.
+func (p *Producer) validate(messages []iggcon.MessengerMessage, samePartition bool) ([]iggcon.MessengerMessage, error) {
+	if len(p.opts.Validators) == 0 {
+		return messages, nil
+	}
+
+	var valid []iggcon.MessengerMessage
+	var rejected []*ValidationError
+	for i, message := range messages {
+		p.validation.validated.Add(1)
+		if err := p.check(message); err != nil {
+			p.validation.rejected.Add(1)
+			validationErr := &ValidationError{Index: i, Err: err}
+			if p.opts.Validation == ValidationFailFast {
+				return nil, validationErr
+			}
+			rejected = append(rejected, validationErr)
+			continue
+		}
+		valid = append(valid, message)
+	}
+	if len(rejected) == 0 {
+		return messages, nil
+	}
+
+	if samePartition {
+		p.validation.dropped.Add(uint64(len(messages)))
+		p.opts.ErrorHandler(rejected[0], messages)
+		return nil, nil
+	}
+	p.validation.dropped.Add(uint64(len(rejected)))
+	for _, validationErr := range rejected {
+		p.opts.ErrorHandler(validationErr, []iggcon.MessengerMessage{messages[validationErr.Index]})
+	}
+	return valid, nil
+}
+
+// check returns the error of the first Validator rejecting the message.
+func (p *Producer) check(message iggcon.MessengerMessage) error {
+	for _, validator := range p.opts.Validators {
+		if err := validator.Validate(message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Config describes the throughput a Limiter allows. A zero rate means unlimited.
+type Config struct {
+	// MessagesPerSecond is the sustained number of messages allowed per second.
+	MessagesPerSecond float64
+	// BytesPerSecond is the sustained number of bytes allowed per second.
+	BytesPerSecond float64
+	// MessagesBurst is the maximum number of messages allowed at once, defaults to MessagesPerSecond.
+	MessagesBurst float64
+	// BytesBurst is the maximum number of bytes allowed at once, defaults to BytesPerSecond.
+	BytesBurst float64
+}
+
+// Limiter shapes the client throughput in messages and bytes per second.
+// It is safe for concurrent use, and the same Limiter can be shared by several clients
+// to enforce a common quota.
+type Limiter struct {
+	messages *TokenBucket
+	bytes    *TokenBucket
+}
+
+// NewLimiter creates a Limiter from the given config.
+func NewLimiter(config Config) *Limiter {
+	limiter := &Limiter{}
+	if config.MessagesPerSecond > 0 {
+		limiter.messages = NewTokenBucket(config.MessagesPerSecond, config.MessagesBurst)
+	}
+	if config.BytesPerSecond > 0 {
+		limiter.bytes = NewTokenBucket(config.BytesPerSecond, config.BytesBurst)
+	}
+	return limiter
+}
+
+// WaitMessages blocks until count messages are allowed.
+func (l *Limiter) WaitMessages(ctx context.Context, count int) error {
+	if l == nil || l.messages == nil || count <= 0 {
+		return nil
+	}
+	return l.messages.Wait(ctx, float64(count))
+}
+
+// WaitBytes blocks until size bytes are allowed.
+func (l *Limiter) WaitBytes(ctx context.Context, size int) error {
+	if l == nil || l.bytes == nil || size <= 0 {
+		return nil
+	}
+	return l.bytes.Wait(ctx, float64(size))
+}
+
+// Wait blocks until count messages of total size bytes are allowed. When ctx is done, neither
+// the messages nor the bytes are taken from the budget.
+func (l *Limiter) Wait(ctx context.Context, count int, size int) error {
+	if err := l.WaitMessages(ctx, count); err != nil {
+		return err
+	}
+	if err := l.WaitBytes(ctx, size); err != nil {
+		l.refundMessages(count)
+		return err
+	}
+	return nil
+}
+
+// WaitRepaid blocks until the debt of the charged messages and bytes is repaid, without taking
+// anything from the budget. It delays the calls whose cost is only known after the fact.
+func (l *Limiter) WaitRepaid(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	for _, bucket := range []*TokenBucket{l.messages, l.bytes} {
+		if bucket == nil {
+			continue
+		}
+		if err := bucket.Wait(ctx, 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Throttle blocks like Wait and returns how long the call was delayed by the limits, 0 when
+// count messages of total size bytes were allowed right away.
+func (l *Limiter) Throttle(ctx context.Context, count int, size int) (time.Duration, error) {
+	if l == nil {
+		return 0, nil
+	}
+	var delayed time.Duration
+	if l.messages != nil && count > 0 {
+		delay, err := l.messages.wait(ctx, float64(count))
+		if err != nil {
+			return 0, err
+		}
+		delayed += delay
+	}
+	if l.bytes != nil && size > 0 {
+		delay, err := l.bytes.wait(ctx, float64(size))
+		if err != nil {
+			l.refundMessages(count)
+			return delayed, err
+		}
+		delayed += delay
+	}
+	return delayed, nil
+}
+
+// ChargeMessages takes count messages from the budget without waiting, like ChargeBytes.
+func (l *Limiter) ChargeMessages(count int) {
+	if l == nil || l.messages == nil || count <= 0 {
+		return
+	}
+	l.messages.Reserve(float64(count))
+}
+
+// refundMessages gives back the count messages taken by a call which then failed.
+func (l *Limiter) refundMessages(count int) {
+	if l == nil || l.messages == nil || count <= 0 {
+		return
+	}
+	l.messages.giveBack(float64(count))
+}
+
+// ChargeBytes takes size bytes from the budget without waiting. It is used when the size is
+// only known after the fact, e.g. for polled messages; the debt delays subsequent calls.
+func (l *Limiter) ChargeBytes(size int) {
+	if l == nil || l.bytes == nil || size <= 0 {
+		return
+	}
+	l.bytes.Reserve(float64(size))
+}
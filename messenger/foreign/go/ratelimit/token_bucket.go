@@ -0,0 +1,123 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// TokenBucket is a token bucket which refills at a constant rate up to its capacity.
+//
+// Acquiring more tokens than currently available puts the bucket into debt, and the caller
+// waits until the debt has been refilled. This allows requests larger than the capacity
+// (e.g. a single big batch) to pass while still keeping the long-term rate.
+type TokenBucket struct {
+	mtx      sync.Mutex
+	rate     float64
+	capacity float64
+	tokens   float64
+	last     time.Time
+	now      func() time.Time
+}
+
+// NewTokenBucket creates a full bucket refilling at rate tokens per second.
+// When burst is lower than 1 the capacity defaults to one second worth of tokens.
+func NewTokenBucket(rate float64, burst float64) *TokenBucket {
+	if burst < 1 {
+		burst = rate
+	}
+	return &TokenBucket{
+		rate:     rate,
+		capacity: burst,
+		tokens:   burst,
+		last:     time.Now(),
+		now:      time.Now,
+	}
+}
+
+// Reserve takes n tokens from the bucket and returns how long the caller has to wait
+// before the reservation is covered.
+func (tb *TokenBucket) Reserve(n float64) time.Duration {
+	tb.mtx.Lock()
+	defer tb.mtx.Unlock()
+
+	tb.refill()
+	tb.tokens -= n
+	if tb.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-tb.tokens / tb.rate * float64(time.Second))
+}
+
+// Wait takes n tokens from the bucket and blocks until they are available or ctx is done.
+func (tb *TokenBucket) Wait(ctx context.Context, n float64) error {
+	_, err := tb.wait(ctx, n)
+	return err
+}
+
+// wait is Wait returning how long the caller was delayed.
+func (tb *TokenBucket) wait(ctx context.Context, n float64) (time.Duration, error) {
+	delay := tb.Reserve(n)
+	if delay <= 0 {
+		return 0, nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		tb.giveBack(n)
+		return 0, ctx.Err()
+	case <-timer.C:
+		return delay, nil
+	}
+}
+
+// Available returns the number of tokens currently in the bucket, negative when in debt.
+func (tb *TokenBucket) Available() float64 {
+	tb.mtx.Lock()
+	defer tb.mtx.Unlock()
+
+	tb.refill()
+	return tb.tokens
+}
+
+func (tb *TokenBucket) giveBack(n float64) {
+	tb.mtx.Lock()
+	defer tb.mtx.Unlock()
+
+	tb.tokens += n
+	if tb.tokens > tb.capacity {
+		tb.tokens = tb.capacity
+	}
+}
+
+func (tb *TokenBucket) refill() {
+	now := tb.now()
+	elapsed := now.Sub(tb.last).Seconds()
+	tb.last = now
+	if elapsed <= 0 {
+		return
+	}
+	tb.tokens += elapsed * tb.rate
+	if tb.tokens > tb.capacity {
+		tb.tokens = tb.capacity
+	}
+}
@@ -0,0 +1,99 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTokenBucket_Reserve(t *testing.T) {
+	now := time.Unix(0, 0)
+	bucket := NewTokenBucket(10, 10)
+	bucket.now = func() time.Time { return now }
+	bucket.last = now
+
+	if delay := bucket.Reserve(10); delay != 0 {
+		t.Fatalf("expected full bucket to grant burst without delay, got %v", delay)
+	}
+	if delay := bucket.Reserve(5); delay != 500*time.Millisecond {
+		t.Fatalf("expected 500ms delay for 5 tokens in debt, got %v", delay)
+	}
+
+	now = now.Add(time.Second)
+	if available := bucket.Available(); available != 5 {
+		t.Fatalf("expected 5 tokens after paying back the debt, got %v", available)
+	}
+
+	now = now.Add(time.Hour)
+	if available := bucket.Available(); available != 10 {
+		t.Fatalf("expected bucket to be capped at its capacity, got %v", available)
+	}
+}
+
+func TestLimiter_Unlimited(t *testing.T) {
+	var limiter *Limiter
+	if err := limiter.Wait(context.Background(), 1000, 1000); err != nil {
+		t.Fatalf("nil limiter should never block, got %v", err)
+	}
+
+	limiter = NewLimiter(Config{})
+	if err := limiter.Wait(context.Background(), 1000, 1000); err != nil {
+		t.Fatalf("zero config limiter should never block, got %v", err)
+	}
+}
+
+func TestLimiter_ChargedMessagesDelayTheNextCalls(t *testing.T) {
+	limiter := NewLimiter(Config{MessagesPerSecond: 100, MessagesBurst: 10})
+	if err := limiter.WaitRepaid(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// 15 polled messages put the budget 5 messages, 50ms, in debt
+	limiter.ChargeMessages(15)
+	start := time.Now()
+	if err := limiter.WaitRepaid(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Fatalf("expected to wait for the debt to be repaid, waited %s", elapsed)
+	}
+	if available := limiter.messages.Available(); available < 0 || available > 1 {
+		t.Fatalf("expected WaitRepaid not to take messages, got %v available", available)
+	}
+}
+
+func TestLimiter_WaitRefundsTheMessagesWhenTheBytesWaitFails(t *testing.T) {
+	limiter := NewLimiter(Config{MessagesPerSecond: 10, BytesPerSecond: 10})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := limiter.Wait(ctx, 5, 1000); err != context.DeadlineExceeded {
+		t.Fatalf("expected the bytes wait to time out, got %v", err)
+	}
+	if available := limiter.messages.Available(); available < 10 {
+		t.Fatalf("expected the messages to be refunded, got %v available", available)
+	}
+	if _, err := limiter.Throttle(ctx, 5, 1000); err == nil {
+		t.Fatal("expected the bytes wait to fail")
+	}
+	if available := limiter.messages.Available(); available < 10 {
+		t.Fatalf("expected the messages to be refunded by Throttle, got %v available", available)
+	}
+}
@@ -0,0 +1,245 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package retry implements the retry-topic pattern: messages a consumer fails to handle are
+// routed to retry topics of increasing delays, then to a dead letter topic, while redelivery
+// workers move them back to their original topic once their delay elapsed.
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/apache/messenger/foreign/go/consumer"
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengercli"
+)
+
+// Headers set on the routed messages.
+const (
+	// AttemptHeader is the number of times the message failed to be handled.
+	AttemptHeader = "retry-attempt"
+	// DueHeader is when the message is redelivered, in microseconds since the Unix epoch.
+	DueHeader = "retry-due"
+	// OriginTopicHeader identifies the topic the message is redelivered to.
+	OriginTopicHeader = "retry-origin-topic"
+	// ErrorHeader is the error the last handling attempt failed with.
+	ErrorHeader = "retry-error"
+)
+
+// Tier is a retry topic, whose messages are redelivered after Delay.
+type Tier struct {
+	TopicId iggcon.Identifier
+	Delay   time.Duration
+}
+
+// Policy describes where failed messages are routed. The first failure routes a message to the
+// first tier, the second failure to the second tier and so on, and a message failing once more
+// after the last tier goes to the dead letter topic.
+type Policy struct {
+	// StreamId is the stream of the retry and dead letter topics.
+	StreamId iggcon.Identifier
+	// Tiers are the retry topics, typically of increasing delays, e.g. 5s, 1m and 10m.
+	Tiers []Tier
+	// DeadLetterTopicId is the topic of the messages that exhausted all tiers.
+	DeadLetterTopicId iggcon.Identifier
+	// Clock provides the time the delays start from, defaults to iggcon.SystemClock.
+	Clock iggcon.Clock
+}
+
+func (p Policy) now() time.Time {
+	if p.Clock == nil {
+		return iggcon.SystemClock.Now()
+	}
+	return p.Clock.Now()
+}
+
+// Attempt returns the number of times the message already failed to be handled.
+func Attempt(message iggcon.MessengerMessage) uint64 {
+	value, ok := message.UserHeader(AttemptHeader)
+	if !ok {
+		return 0
+	}
+	attempt, _ := value.AsUint64()
+	return attempt
+}
+
+// Route sends a message that failed to be handled with cause to the next retry tier, or to the
+// dead letter topic when all tiers are exhausted. originTopicId is the topic the message was
+// consumed from; for a message consumed from a retry tier, its original topic is kept.
+// The routed message loses its partitioning: it is redelivered to a balanced partition.
+func (p Policy) Route(client messengercli.DataClient, originTopicId iggcon.Identifier, message iggcon.MessengerMessage, cause error) error {
+	attempt := Attempt(message)
+	if origin, ok := message.UserHeader(OriginTopicHeader); ok {
+		id, err := decodeIdentifier(origin.Value)
+		if err != nil {
+			return err
+		}
+		originTopicId = id
+	}
+
+	headers := map[string]iggcon.HeaderValue{
+		AttemptHeader:     iggcon.NewUint64HeaderValue(attempt + 1),
+		OriginTopicHeader: {Kind: iggcon.Raw, Value: encodeIdentifier(originTopicId)},
+	}
+	if cause != nil {
+		headers[ErrorHeader] = iggcon.NewStringHeaderValue(truncate(cause.Error(), 255))
+	}
+	topicId := p.DeadLetterTopicId
+	if attempt < uint64(len(p.Tiers)) {
+		tier := p.Tiers[attempt]
+		topicId = tier.TopicId
+		headers[DueHeader] = iggcon.NewUint64HeaderValue(uint64(p.now().Add(tier.Delay).UnixMicro()))
+	}
+
+	routed, err := copyMessage(message, headers)
+	if err != nil {
+		return err
+	}
+	return client.SendMessages(p.StreamId, topicId, iggcon.None(), []iggcon.MessengerMessage{routed})
+}
+
+// Redeliver returns the handler of the consumer of a retry tier: it waits until the due time of
+// every message, then sends it back to its original topic in streamId. Since all messages of a
+// tier share the same delay, they become due in the order they are consumed. The consumer
+// should not auto commit, so a message is never lost between its poll and its redelivery.
+func (p Policy) Redeliver(client messengercli.DataClient, streamId iggcon.Identifier) consumer.Handler {
+	return func(ctx context.Context, received iggcon.ReceivedMessage) error {
+		message := received.Message
+		origin, ok := message.UserHeader(OriginTopicHeader)
+		if !ok {
+			return errors.New("retry: message has no origin topic")
+		}
+		originTopicId, err := decodeIdentifier(origin.Value)
+		if err != nil {
+			return err
+		}
+		if due, ok := message.UserHeader(DueHeader); ok {
+			dueMicros, err := due.AsUint64()
+			if err != nil {
+				return err
+			}
+			if err := p.wait(ctx, time.UnixMicro(int64(dueMicros))); err != nil {
+				return err
+			}
+		}
+
+		redelivered, err := copyMessage(message, nil)
+		if err != nil {
+			return err
+		}
+		return client.SendMessages(streamId, originTopicId, iggcon.None(), []iggcon.MessengerMessage{redelivered})
+	}
+}
+
+// Middleware routes the messages the next handler fails to handle with Route, instead of
+// stopping the consumer of originTopicId. Only a failure to route a message stops the consumer.
+func (p Policy) Middleware(client messengercli.DataClient, originTopicId iggcon.Identifier) consumer.Middleware {
+	return func(next consumer.Handler) consumer.Handler {
+		return func(ctx context.Context, message iggcon.ReceivedMessage) error {
+			err := next(ctx, message)
+			if err == nil || ctx.Err() != nil {
+				return err
+			}
+			if routeErr := p.Route(client, originTopicId, message.Message, err); routeErr != nil {
+				return fmt.Errorf("retry: failed to route message at offset %d: %w", message.Message.Header.Offset, errors.Join(routeErr, err))
+			}
+			return nil
+		}
+	}
+}
+
+// NewRedeliveryWorkers creates a consumer per tier, redelivering its messages to their original
+// topic in streamId. Offsets are stored once each message is redelivered, and the options are
+// applied after this default, e.g. to set the consumer group.
+func (p Policy) NewRedeliveryWorkers(client messengercli.Client, streamId iggcon.Identifier, options ...consumer.Option) ([]*consumer.Consumer, error) {
+	workers := make([]*consumer.Consumer, 0, len(p.Tiers))
+	for _, tier := range p.Tiers {
+		worker, err := consumer.NewConsumer(
+			client,
+			p.StreamId,
+			tier.TopicId,
+			p.Redeliver(client, streamId),
+			append([]consumer.Option{consumer.WithAutoCommit(false)}, options...)...,
+		)
+		if err != nil {
+			return nil, err
+		}
+		workers = append(workers, worker)
+	}
+	return workers, nil
+}
+
+func (p Policy) wait(ctx context.Context, due time.Time) error {
+	delay := due.Sub(p.now())
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// copyMessage creates a new message with the payload and user headers of message, merged with headers.
+func copyMessage(message iggcon.MessengerMessage, headers map[string]iggcon.HeaderValue) (iggcon.MessengerMessage, error) {
+	copied, err := iggcon.NewMessengerMessage(message.Payload)
+	if err != nil {
+		return iggcon.MessengerMessage{}, err
+	}
+	userHeaders := map[iggcon.HeaderKey]iggcon.HeaderValue{}
+	if len(message.UserHeaders) > 0 {
+		if userHeaders, err = iggcon.DeserializeHeaders(message.UserHeaders); err != nil {
+			return iggcon.MessengerMessage{}, err
+		}
+	}
+	for key, value := range headers {
+		userHeaders[iggcon.HeaderKey{Value: key}] = value
+	}
+	if err = copied.SetUserHeaders(userHeaders); err != nil {
+		return iggcon.MessengerMessage{}, err
+	}
+	return copied, nil
+}
+
+func encodeIdentifier(id iggcon.Identifier) []byte {
+	return append([]byte{byte(id.Kind)}, id.Value...)
+}
+
+func decodeIdentifier(b []byte) (iggcon.Identifier, error) {
+	if len(b) < 2 {
+		return iggcon.Identifier{}, fmt.Errorf("retry: invalid origin topic %x", b)
+	}
+	id, err := iggcon.IdentifierFromBytes(iggcon.IdKind(b[0]), b[1:])
+	if err != nil {
+		return iggcon.Identifier{}, fmt.Errorf("retry: invalid origin topic %x: %w", b, err)
+	}
+	return id, nil
+}
+
+func truncate(s string, length int) string {
+	if len(s) <= length {
+		return s
+	}
+	return s[:length]
+}
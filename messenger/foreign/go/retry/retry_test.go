@@ -0,0 +1,139 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengercli"
+)
+
+type sentMessage struct {
+	topicId iggcon.Identifier
+	message iggcon.MessengerMessage
+}
+
+type recordingClient struct {
+	messengercli.DataClient
+	sent []sentMessage
+}
+
+func (c *recordingClient) SendMessages(_, topicId iggcon.Identifier, _ iggcon.Partitioning, messages []iggcon.MessengerMessage) error {
+	for _, message := range messages {
+		c.sent = append(c.sent, sentMessage{topicId: topicId, message: message})
+	}
+	return nil
+}
+
+func (c *recordingClient) last() sentMessage {
+	return c.sent[len(c.sent)-1]
+}
+
+func mustIdentifier(t *testing.T, name string) iggcon.Identifier {
+	id, err := iggcon.NewIdentifier(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+func TestPolicy_RoutesThroughTiersToDeadLetterTopic(t *testing.T) {
+	now := time.UnixMicro(1_000_000)
+	policy := Policy{
+		StreamId: mustIdentifier(t, "orders"),
+		Tiers: []Tier{
+			{TopicId: mustIdentifier(t, "retry-5s"), Delay: 5 * time.Second},
+			{TopicId: mustIdentifier(t, "retry-1m"), Delay: time.Minute},
+		},
+		DeadLetterTopicId: mustIdentifier(t, "dlq"),
+		Clock:             iggcon.ClockFunc(func() time.Time { return now }),
+	}
+	client := &recordingClient{}
+	origin := mustIdentifier(t, "created")
+	failing := policy.Middleware(client, origin)(func(context.Context, iggcon.ReceivedMessage) error {
+		return errors.New("boom")
+	})
+
+	message, err := iggcon.NewMessengerMessage([]byte("order"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expectedTopic := range []string{"retry-5s", "retry-1m", "dlq"} {
+		if err := failing(context.Background(), iggcon.ReceivedMessage{Message: message}); err != nil {
+			t.Fatal(err)
+		}
+		routed := client.last()
+		if name, _ := routed.topicId.String(); name != expectedTopic {
+			t.Fatalf("expected the message to be routed to %s, got %s", expectedTopic, name)
+		}
+		if string(routed.message.Payload) != "order" {
+			t.Fatalf("unexpected payload %q", routed.message.Payload)
+		}
+		if cause, _ := routed.message.UserHeader(ErrorHeader); string(cause.Value) != "boom" {
+			t.Fatalf("expected the error header to be set, got %q", cause.Value)
+		}
+		message = routed.message
+	}
+	if attempt := Attempt(message); attempt != 3 {
+		t.Fatalf("expected 3 attempts, got %d", attempt)
+	}
+
+	// a message routed to the first tier is redelivered to its origin once its delay elapsed
+	message, _ = iggcon.NewMessengerMessage([]byte("order"))
+	if err = policy.Route(client, origin, message, errors.New("boom")); err != nil {
+		t.Fatal(err)
+	}
+	retried := client.last().message
+	now = now.Add(5 * time.Second)
+	if err = policy.Redeliver(client, policy.StreamId)(context.Background(), iggcon.ReceivedMessage{Message: retried}); err != nil {
+		t.Fatal(err)
+	}
+	redelivered := client.last()
+	if name, _ := redelivered.topicId.String(); name != "created" {
+		t.Fatalf("expected the message to be redelivered to created, got %s", name)
+	}
+	if Attempt(redelivered.message) != 1 {
+		t.Fatalf("expected the redelivered message to keep its attempt count, got %d", Attempt(redelivered.message))
+	}
+}
+
+func TestPolicy_RedeliverWaitsForDueTime(t *testing.T) {
+	policy := Policy{
+		StreamId: mustIdentifier(t, "orders"),
+		Tiers:    []Tier{{TopicId: mustIdentifier(t, "retry-1h"), Delay: time.Hour}},
+	}
+	client := &recordingClient{}
+	message, _ := iggcon.NewMessengerMessage([]byte("order"))
+	if err := policy.Route(client, mustIdentifier(t, "created"), message, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := policy.Redeliver(client, policy.StreamId)(ctx, iggcon.ReceivedMessage{Message: client.last().message})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the redelivery to wait for the due time, got %v", err)
+	}
+	if len(client.sent) != 1 {
+		t.Fatalf("expected the message not to be redelivered yet, got %d sends", len(client.sent))
+	}
+}
@@ -0,0 +1,302 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package rpc implements the request/reply pattern over topics: a Requester sends a request with
+// a correlation ID and the topic to reply to in its headers, and waits for the reply, which the
+// handler returned by Respond sends back from the consumer of the requests.
+//
+//	requester, err := rpc.NewRequester(client, streamId, repliesTopicId)
+//	reply, err := requester.Request(ctx, streamId, requestsTopicId, request, 5*time.Second)
+//
+//	c, err := consumer.NewConsumer(client, streamId, requestsTopicId, rpc.Respond(client, handler))
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengercli"
+	"github.com/google/uuid"
+)
+
+// Headers of the requests and of the replies.
+const (
+	// CorrelationIdHeader is the ID of the request, set on its reply.
+	CorrelationIdHeader = "rpc-correlation-id"
+	// ReplyStreamHeader identifies the stream of the topic to reply to.
+	ReplyStreamHeader = "rpc-reply-stream"
+	// ReplyTopicHeader identifies the topic to reply to.
+	ReplyTopicHeader = "rpc-reply-topic"
+	// ErrorHeader is set on the replies of the requests the handler failed, the payload holding
+	// the error.
+	ErrorHeader = "rpc-error"
+)
+
+const (
+	// replyBatchSize is the number of replies a Requester polls at once.
+	replyBatchSize = 100
+	// replyMaxWait is how long the server holds the polls of a Requester.
+	replyMaxWait = time.Second
+	// replyPollInterval is how long a Requester waits before polling again after an empty poll,
+	// for the servers without long polling.
+	replyPollInterval = 20 * time.Millisecond
+)
+
+var (
+	// ErrTimeout is returned when no reply arrived before the timeout of the request.
+	ErrTimeout = errors.New("rpc: no reply before the timeout")
+	// ErrClosed is returned when requesting through a closed Requester.
+	ErrClosed = errors.New("rpc: requester closed")
+)
+
+// RemoteError is returned for a request the handler of the responder failed.
+type RemoteError struct {
+	Message string
+}
+
+func (e *RemoteError) Error() string {
+	return "rpc: request failed: " + e.Message
+}
+
+// Requester sends requests and waits for their replies on its reply topic, which it follows from
+// its creation on. A reply topic is best used by a single Requester, the replies of the others
+// being polled and ignored.
+type Requester struct {
+	client        messengercli.Client
+	replyStreamId iggcon.Identifier
+	replyTopicId  iggcon.Identifier
+
+	mtx     sync.Mutex
+	pending map[string]chan reply
+	closed  bool
+	err     error
+
+	cancel  context.CancelFunc
+	stopped sync.WaitGroup
+}
+
+type reply struct {
+	message iggcon.MessengerMessage
+	err     error
+}
+
+// NewRequester creates a Requester receiving the replies on the topic identified by replyStreamId
+// and replyTopicId.
+func NewRequester(client messengercli.Client, replyStreamId, replyTopicId iggcon.Identifier) (*Requester, error) {
+	topic, err := client.GetTopic(replyStreamId, replyTopicId)
+	if err != nil {
+		return nil, err
+	}
+	// the replies are followed from the end of every partition as of now, so the replies of the
+	// requests sent once NewRequester returned are not missed
+	next := make([]uint64, topic.PartitionsCount)
+	for i := range next {
+		partitionId := uint32(i + 1)
+		polled, err := client.PollMessages(replyStreamId, replyTopicId, iggcon.DefaultConsumer(), iggcon.LastPollingStrategy(), 1, false, &partitionId)
+		if err != nil {
+			return nil, err
+		}
+		if polled != nil && len(polled.Messages) > 0 {
+			next[i] = polled.Messages[len(polled.Messages)-1].Header.Offset + 1
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &Requester{
+		client:        client,
+		replyStreamId: replyStreamId,
+		replyTopicId:  replyTopicId,
+		pending:       map[string]chan reply{},
+		cancel:        cancel,
+	}
+	for i, offset := range next {
+		r.stopped.Add(1)
+		go func() {
+			defer r.stopped.Done()
+			if err := r.follow(ctx, uint32(i+1), offset); err != nil && ctx.Err() == nil {
+				r.fail(err)
+			}
+		}()
+	}
+	return r, nil
+}
+
+// Request sends the message to the topic and waits for its reply, until ctx is done or, with a
+// positive timeout, until the timeout elapsed. The deadline of the request is propagated in the
+// iggcon.DeadlineHeader, for the responders to skip the requests nobody waits for anymore.
+func (r *Requester) Request(
+	ctx context.Context,
+	streamId iggcon.Identifier,
+	topicId iggcon.Identifier,
+	message iggcon.MessengerMessage,
+	timeout time.Duration,
+) (iggcon.MessengerMessage, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, timeout, ErrTimeout)
+		defer cancel()
+	}
+	correlationId := uuid.NewString()
+	err := message.SetUserHeaders(map[iggcon.HeaderKey]iggcon.HeaderValue{
+		{Value: CorrelationIdHeader}: iggcon.NewStringHeaderValue(correlationId),
+		{Value: ReplyStreamHeader}:   {Kind: iggcon.Raw, Value: encodeIdentifier(r.replyStreamId)},
+		{Value: ReplyTopicHeader}:    {Kind: iggcon.Raw, Value: encodeIdentifier(r.replyTopicId)},
+	})
+	if err != nil {
+		return iggcon.MessengerMessage{}, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		iggcon.WithDeadline(deadline)(&message)
+	}
+
+	replies := make(chan reply, 1)
+	r.mtx.Lock()
+	if r.closed || r.err != nil {
+		err := r.err
+		r.mtx.Unlock()
+		if err == nil {
+			err = ErrClosed
+		}
+		return iggcon.MessengerMessage{}, err
+	}
+	r.pending[correlationId] = replies
+	r.mtx.Unlock()
+	defer func() {
+		r.mtx.Lock()
+		delete(r.pending, correlationId)
+		r.mtx.Unlock()
+	}()
+
+	if err = r.client.SendMessages(streamId, topicId, iggcon.None(), []iggcon.MessengerMessage{message}); err != nil {
+		return iggcon.MessengerMessage{}, err
+	}
+	select {
+	case reply := <-replies:
+		return reply.message, reply.err
+	case <-ctx.Done():
+		return iggcon.MessengerMessage{}, context.Cause(ctx)
+	}
+}
+
+// Close stops following the reply topic, failing the requests waiting for their reply.
+func (r *Requester) Close() error {
+	r.mtx.Lock()
+	r.closed = true
+	r.mtx.Unlock()
+	r.cancel()
+	r.stopped.Wait()
+	r.fail(ErrClosed)
+
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if errors.Is(r.err, ErrClosed) {
+		return nil
+	}
+	return r.err
+}
+
+// fail fails the requests waiting for their reply, and the next ones with the first err.
+func (r *Requester) fail(err error) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if r.err == nil {
+		r.err = err
+	}
+	for correlationId, replies := range r.pending {
+		replies <- reply{err: err}
+		delete(r.pending, correlationId)
+	}
+}
+
+// follow polls the replies of the partition from the offset until ctx is done.
+func (r *Requester) follow(ctx context.Context, partitionId uint32, next uint64) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		polled, err := r.client.PollMessagesWithWait(
+			r.replyStreamId,
+			r.replyTopicId,
+			iggcon.DefaultConsumer(),
+			iggcon.OffsetPollingStrategy(next),
+			replyBatchSize,
+			false,
+			&partitionId,
+			replyMaxWait,
+		)
+		if err != nil {
+			return err
+		}
+		if polled == nil || len(polled.Messages) == 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(replyPollInterval):
+			}
+			continue
+		}
+		for _, message := range polled.Messages {
+			if message.Header.Offset >= next {
+				r.dispatch(message)
+				next = message.Header.Offset + 1
+			}
+		}
+	}
+}
+
+// dispatch passes the reply to the request waiting for it, if any.
+func (r *Requester) dispatch(message iggcon.MessengerMessage) {
+	value, ok := message.UserHeader(CorrelationIdHeader)
+	if !ok {
+		return
+	}
+	correlationId, err := value.AsString()
+	if err != nil {
+		return
+	}
+	r.mtx.Lock()
+	replies, ok := r.pending[correlationId]
+	delete(r.pending, correlationId)
+	r.mtx.Unlock()
+	if !ok {
+		return
+	}
+	if _, failed := message.UserHeader(ErrorHeader); failed {
+		replies <- reply{err: &RemoteError{Message: string(message.Payload)}}
+		return
+	}
+	replies <- reply{message: message}
+}
+
+func encodeIdentifier(id iggcon.Identifier) []byte {
+	return append([]byte{byte(id.Kind)}, id.Value...)
+}
+
+func decodeIdentifier(b []byte) (iggcon.Identifier, error) {
+	if len(b) < 2 {
+		return iggcon.Identifier{}, fmt.Errorf("rpc: invalid reply topic %x", b)
+	}
+	id, err := iggcon.IdentifierFromBytes(iggcon.IdKind(b[0]), b[1:])
+	if err != nil {
+		return iggcon.Identifier{}, fmt.Errorf("rpc: invalid reply topic %x: %w", b, err)
+	}
+	return id, nil
+}
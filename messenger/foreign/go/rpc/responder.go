@@ -0,0 +1,81 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package rpc
+
+import (
+	"context"
+	"errors"
+
+	"github.com/apache/messenger/foreign/go/consumer"
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengercli"
+)
+
+// ReplyHandler handles a request and returns its reply. When it fails, the error is sent back to
+// the requester instead, as a RemoteError.
+type ReplyHandler func(ctx context.Context, request iggcon.ReceivedMessage) (iggcon.MessengerMessage, error)
+
+// Respond returns the handler of the consumer of the requests: it calls handler and sends the
+// reply, or the error, to the reply topic of the request, with the correlation ID of the request.
+// The messages without a reply topic are handled and their replies dropped. Only a failure to
+// send a reply stops the consumer.
+func Respond(client messengercli.DataClient, handler ReplyHandler) consumer.Handler {
+	return func(ctx context.Context, request iggcon.ReceivedMessage) error {
+		reply, err := handler(ctx, request)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		correlationId, ok := request.Message.UserHeader(CorrelationIdHeader)
+		if !ok {
+			return nil
+		}
+		replyStream, streamOk := request.Message.UserHeader(ReplyStreamHeader)
+		replyTopic, topicOk := request.Message.UserHeader(ReplyTopicHeader)
+		if !streamOk || !topicOk {
+			return nil
+		}
+		streamId, decodeErr := decodeIdentifier(replyStream.Value)
+		if decodeErr != nil {
+			return decodeErr
+		}
+		topicId, decodeErr := decodeIdentifier(replyTopic.Value)
+		if decodeErr != nil {
+			return decodeErr
+		}
+
+		headers := map[iggcon.HeaderKey]iggcon.HeaderValue{
+			{Value: CorrelationIdHeader}: correlationId,
+		}
+		if err != nil {
+			headers[iggcon.HeaderKey{Value: ErrorHeader}] = iggcon.HeaderValue{Kind: iggcon.Bool, Value: []byte{1}}
+			message := err.Error()
+			if message == "" {
+				message = "unknown error"
+			}
+			if reply, err = iggcon.NewMessengerMessage([]byte(message)); err != nil {
+				return err
+			}
+		} else if len(reply.Payload) == 0 {
+			return errors.New("rpc: the reply has no payload")
+		}
+		if err = reply.SetUserHeaders(headers); err != nil {
+			return err
+		}
+		return client.SendMessages(streamId, topicId, iggcon.None(), []iggcon.MessengerMessage{reply})
+	}
+}
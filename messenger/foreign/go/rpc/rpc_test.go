@@ -0,0 +1,106 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package rpc_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apache/messenger/foreign/go/consumer"
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengertest"
+	"github.com/apache/messenger/foreign/go/rpc"
+)
+
+func TestRequestReply(t *testing.T) {
+	client := messengertest.NewClient()
+	if _, err := client.CreateStream("rpc", nil); err != nil {
+		t.Fatal(err)
+	}
+	streamId := iggcon.MustIdentifier("rpc")
+	for _, name := range []string{"requests", "replies"} {
+		if _, err := client.CreateTopic(streamId, name, 2, iggcon.CompressionAlgorithmNone, 0, 0, nil, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	requestsId, repliesId := iggcon.MustIdentifier("requests"), iggcon.MustIdentifier("replies")
+
+	responder, err := consumer.NewConsumer(client, streamId, requestsId, rpc.Respond(client, func(ctx context.Context, request iggcon.ReceivedMessage) (iggcon.MessengerMessage, error) {
+		payload := string(request.Message.Payload)
+		switch payload {
+		case "fail":
+			return iggcon.MessengerMessage{}, errors.New("cannot handle the request")
+		case "slow":
+			<-ctx.Done()
+		}
+		return iggcon.NewMessengerMessage([]byte(strings.ToUpper(payload)))
+	}), consumer.WithPollInterval(10*time.Millisecond), consumer.WithMiddleware(consumer.HonorDeadline(nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ran := make(chan error, 1)
+	go func() {
+		ran <- responder.Run(ctx)
+	}()
+
+	requester, err := rpc.NewRequester(client, streamId, repliesId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := func(payload string, timeout time.Duration) (string, error) {
+		message, err := iggcon.NewMessengerMessage([]byte(payload))
+		if err != nil {
+			t.Fatal(err)
+		}
+		reply, err := requester.Request(context.Background(), streamId, requestsId, message, timeout)
+		return string(reply.Payload), err
+	}
+
+	for _, payload := range []string{"ping", "pong"} {
+		reply, err := request(payload, 5*time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if reply != strings.ToUpper(payload) {
+			t.Fatalf("expected the reply to %s, got %s", payload, reply)
+		}
+	}
+	var remote *rpc.RemoteError
+	if _, err = request("fail", 5*time.Second); !errors.As(err, &remote) || remote.Message != "cannot handle the request" {
+		t.Fatalf("expected the error of the responder, got %v", err)
+	}
+	if _, err = request("slow", 100*time.Millisecond); !errors.Is(err, rpc.ErrTimeout) {
+		t.Fatalf("expected the request to time out, got %v", err)
+	}
+
+	if err = requester.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = request("ping", time.Second); !errors.Is(err, rpc.ErrClosed) {
+		t.Fatalf("expected the requester to be closed, got %v", err)
+	}
+	cancel()
+	if err = <-ran; err != nil {
+		t.Fatal(err)
+	}
+}
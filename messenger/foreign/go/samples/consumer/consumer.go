@@ -18,6 +18,7 @@
 package main
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
@@ -62,7 +63,7 @@ func main() {
 }
 
 func EnsureInfrastructureIsInitialized(cli messengercli.Client) error {
-	streamIdentifier, _ := iggcon.NewIdentifier(DefaultStreamId)
+	streamIdentifier := iggcon.MustIdentifier(DefaultStreamId)
 	if _, streamErr := cli.GetStream(streamIdentifier); streamErr != nil {
 		uint32DefaultStreamId := DefaultStreamId
 		_, streamErr = cli.CreateStream("Test Producer Stream", &uint32DefaultStreamId)
@@ -76,18 +77,15 @@ func EnsureInfrastructureIsInitialized(cli messengercli.Client) error {
 
 	fmt.Printf("Stream with ID: %d exists.\n", DefaultStreamId)
 
-	topicIdentifier, _ := iggcon.NewIdentifier(TopicId)
+	topicIdentifier := iggcon.MustIdentifier(TopicId)
 	if _, topicErr := cli.GetTopic(streamIdentifier, topicIdentifier); topicErr != nil {
 		uint32TopicId := TopicId
-		_, topicErr = cli.CreateTopic(
-			streamIdentifier,
-			"Test Topic From Producer Sample",
-			12,
-			0,
-			0,
-			0,
-			nil,
-			&uint32TopicId)
+		_, topicErr = messengercli.CreateTopic(context.Background(), cli, iggcon.CreateTopicRequest{
+			StreamId:        streamIdentifier,
+			Name:            "Test Topic From Producer Sample",
+			TopicId:         &uint32TopicId,
+			PartitionsCount: 12,
+		})
 
 		if topicErr != nil {
 			panic(topicErr)
@@ -105,9 +103,9 @@ func ConsumeMessages(cli messengercli.Client) error {
 	fmt.Printf("Messages will be polled from stream '%d', topic '%d', partition '%d' with interval %d ms.\n", DefaultStreamId, TopicId, Partition, Interval)
 
 	for {
-		streamIdentifier, _ := iggcon.NewIdentifier(DefaultStreamId)
-		topicIdentifier, _ := iggcon.NewIdentifier(TopicId)
-		consumerIdentifier, _ := iggcon.NewIdentifier(ConsumerId)
+		streamIdentifier := iggcon.MustIdentifier(DefaultStreamId)
+		topicIdentifier := iggcon.MustIdentifier(TopicId)
+		consumerIdentifier := iggcon.MustIdentifier(ConsumerId)
 		partionId := uint32(Partition)
 		messagesWrapper, err := cli.PollMessages(
 			streamIdentifier,
@@ -18,6 +18,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -61,7 +62,7 @@ func main() {
 }
 
 func EnsureInfrastructureIsInitialized(cli messengercli.Client) error {
-	streamIdentifier, _ := iggcon.NewIdentifier(StreamId)
+	streamIdentifier := iggcon.MustIdentifier(StreamId)
 	if _, streamErr := cli.GetStream(streamIdentifier); streamErr != nil {
 		uint32StreamId := uint32(StreamId)
 		_, streamErr = cli.CreateStream("Test Producer Stream", &uint32StreamId)
@@ -77,18 +78,15 @@ func EnsureInfrastructureIsInitialized(cli messengercli.Client) error {
 
 	fmt.Printf("Stream with ID: %d exists.\n", StreamId)
 
-	topicIdentifier, _ := iggcon.NewIdentifier(TopicId)
+	topicIdentifier := iggcon.MustIdentifier(TopicId)
 	if _, topicErr := cli.GetTopic(streamIdentifier, topicIdentifier); topicErr != nil {
 		refStreamId := StreamId
-		_, topicErr = cli.CreateTopic(
-			streamIdentifier,
-			"Test Topic From Producer Sample",
-			12,
-			0,
-			0,
-			0,
-			nil,
-			&refStreamId)
+		_, topicErr = messengercli.CreateTopic(context.Background(), cli, iggcon.CreateTopicRequest{
+			StreamId:        streamIdentifier,
+			Name:            "Test Topic From Producer Sample",
+			TopicId:         &refStreamId,
+			PartitionsCount: 12,
+		})
 
 		if topicErr != nil {
 			panic(topicErr)
@@ -126,8 +124,8 @@ func PublishMessages(messageStream messengercli.Client) error {
 			})
 		}
 
-		streamIdentifier, _ := iggcon.NewIdentifier(StreamId)
-		topicIdentifier, _ := iggcon.NewIdentifier(TopicId)
+		streamIdentifier := iggcon.MustIdentifier(StreamId)
+		topicIdentifier := iggcon.MustIdentifier(TopicId)
 		err := messageStream.SendMessages(
 			streamIdentifier,
 			topicIdentifier,
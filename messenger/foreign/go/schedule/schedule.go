@@ -0,0 +1,334 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package schedule implements the delayed delivery of messages over a scheduler topic, without
+// the support of the server: the messages scheduled with iggcon.WithDeliverAt are sent to the
+// scheduler topic by a Client returned by NewClient, and a Scheduler worker consuming it moves
+// them to their target topic once due, re-enqueuing the others to the end of the scheduler topic.
+//
+//	cli := schedule.NewClient(client, streamId, schedulerTopicId)
+//	p, err := producer.NewProducer(cli, streamId, topicId)
+//	...
+//	message, err := iggcon.NewMessengerMessage(payload, iggcon.WithDeliverAt(time.Now().Add(time.Hour)))
+//	err = p.Send(ctx, message)
+//
+// The messages due within the interval of the Scheduler are delivered on time, while the ones
+// scheduled further are re-enqueued and may be delivered late by up to the time the worker takes
+// to go through the scheduler topic.
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/apache/messenger/foreign/go/consumer"
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengercli"
+)
+
+// Headers set on the messages held in the scheduler topic.
+const (
+	// TargetStreamHeader identifies the stream the message is delivered to.
+	TargetStreamHeader = "schedule-target-stream"
+	// TargetTopicHeader identifies the topic the message is delivered to.
+	TargetTopicHeader = "schedule-target-topic"
+	// TargetPartitioningHeader is the partitioning the message is delivered with.
+	TargetPartitioningHeader = "schedule-target-partitioning"
+	// RequeuedAtHeader is when the message was last re-enqueued, in microseconds since the Unix
+	// epoch.
+	RequeuedAtHeader = "schedule-requeued-at"
+)
+
+// NewClient returns a Client sending the messages scheduled in the future with
+// iggcon.WithDeliverAt to the scheduler topic, identified by streamId and topicId, instead of the
+// topic they are sent to, which a Scheduler delivers them to once due. The other messages are
+// sent as usual. SendMessagesWithResult returns a nil result when messages were held back.
+func NewClient(client messengercli.Client, streamId, topicId iggcon.Identifier) messengercli.Client {
+	return &scheduledClient{Client: client, streamId: streamId, topicId: topicId}
+}
+
+type scheduledClient struct {
+	messengercli.Client
+	streamId iggcon.Identifier
+	topicId  iggcon.Identifier
+}
+
+func (c *scheduledClient) SendMessages(
+	streamId iggcon.Identifier,
+	topicId iggcon.Identifier,
+	partitioning iggcon.Partitioning,
+	messages []iggcon.MessengerMessage,
+) error {
+	due, err := c.hold(streamId, topicId, partitioning, messages, iggcon.ConfirmationDefault)
+	if err != nil || len(due) == 0 {
+		return err
+	}
+	return c.Client.SendMessages(streamId, topicId, partitioning, due)
+}
+
+func (c *scheduledClient) SendMessagesWithConfirmation(
+	streamId iggcon.Identifier,
+	topicId iggcon.Identifier,
+	partitioning iggcon.Partitioning,
+	messages []iggcon.MessengerMessage,
+	confirmation iggcon.Confirmation,
+) error {
+	due, err := c.hold(streamId, topicId, partitioning, messages, confirmation)
+	if err != nil || len(due) == 0 {
+		return err
+	}
+	return c.Client.SendMessagesWithConfirmation(streamId, topicId, partitioning, due, confirmation)
+}
+
+func (c *scheduledClient) SendMessagesWithResult(
+	streamId iggcon.Identifier,
+	topicId iggcon.Identifier,
+	partitioning iggcon.Partitioning,
+	messages []iggcon.MessengerMessage,
+	confirmation iggcon.Confirmation,
+) (*iggcon.SendResult, error) {
+	due, err := c.hold(streamId, topicId, partitioning, messages, confirmation)
+	if err != nil || len(due) == 0 {
+		return nil, err
+	}
+	if len(due) < len(messages) {
+		// the result would not describe the messages held back
+		return nil, c.Client.SendMessagesWithConfirmation(streamId, topicId, partitioning, due, confirmation)
+	}
+	return c.Client.SendMessagesWithResult(streamId, topicId, partitioning, due, confirmation)
+}
+
+// PollMessagesWithContext forwards the context polls, which the embedded Client does not expose.
+func (c *scheduledClient) PollMessagesWithContext(
+	ctx context.Context,
+	streamId iggcon.Identifier,
+	topicId iggcon.Identifier,
+	consumer iggcon.Consumer,
+	strategy iggcon.PollingStrategy,
+	count uint32,
+	autoCommit bool,
+	partitionId *uint32,
+	maxWait time.Duration,
+) (*iggcon.PolledMessage, error) {
+	return messengercli.PollMessagesWithContext(ctx, c.Client, streamId, topicId, consumer, strategy, count, autoCommit, partitionId, maxWait)
+}
+
+// hold sends the messages scheduled in the future to the scheduler topic and returns the others.
+func (c *scheduledClient) hold(
+	streamId iggcon.Identifier,
+	topicId iggcon.Identifier,
+	partitioning iggcon.Partitioning,
+	messages []iggcon.MessengerMessage,
+	confirmation iggcon.Confirmation,
+) ([]iggcon.MessengerMessage, error) {
+	now := time.Now()
+	var due, held []iggcon.MessengerMessage
+	for i, message := range messages {
+		deliverAt, ok := iggcon.MessageDeliverAt(&message)
+		if !ok || !now.Before(deliverAt) {
+			if held != nil {
+				due = append(due, message)
+			}
+			continue
+		}
+		if held == nil {
+			due = append(due, messages[:i]...)
+		}
+		err := message.SetUserHeaders(map[iggcon.HeaderKey]iggcon.HeaderValue{
+			{Value: TargetStreamHeader}:       {Kind: iggcon.Raw, Value: encodeIdentifier(streamId)},
+			{Value: TargetTopicHeader}:        {Kind: iggcon.Raw, Value: encodeIdentifier(topicId)},
+			{Value: TargetPartitioningHeader}: {Kind: iggcon.Raw, Value: encodePartitioning(partitioning)},
+		})
+		if err != nil {
+			return nil, err
+		}
+		held = append(held, message)
+	}
+	if held == nil {
+		return messages, nil
+	}
+	if err := c.Client.SendMessagesWithConfirmation(c.streamId, c.topicId, iggcon.None(), held, confirmation); err != nil {
+		return nil, err
+	}
+	return due, nil
+}
+
+// Scheduler delivers the messages of a scheduler topic to their target topic once due.
+type Scheduler struct {
+	client   messengercli.Client
+	streamId iggcon.Identifier
+	topicId  iggcon.Identifier
+	interval time.Duration
+	clock    iggcon.Clock
+}
+
+type Option func(scheduler *Scheduler)
+
+// WithInterval sets how often a message is re-enqueued at most, one second by default. The
+// messages due within the interval are waited for instead, holding back the ones after them.
+func WithInterval(interval time.Duration) Option {
+	return func(scheduler *Scheduler) {
+		scheduler.interval = interval
+	}
+}
+
+// WithClock sets the clock the messages become due by, iggcon.SystemClock by default.
+func WithClock(clock iggcon.Clock) Option {
+	return func(scheduler *Scheduler) {
+		scheduler.clock = clock
+	}
+}
+
+// NewScheduler creates a Scheduler of the scheduler topic identified by streamId and topicId.
+func NewScheduler(client messengercli.Client, streamId, topicId iggcon.Identifier, options ...Option) *Scheduler {
+	scheduler := &Scheduler{client: client, streamId: streamId, topicId: topicId, interval: time.Second, clock: iggcon.SystemClock}
+	for _, opt := range options {
+		if opt != nil {
+			opt(scheduler)
+		}
+	}
+	return scheduler
+}
+
+// NewWorker creates the consumer of the scheduler topic running Handle. Offsets are stored once
+// each message is delivered or re-enqueued, and the options are applied after this default,
+// e.g. to set the consumer group.
+func (s *Scheduler) NewWorker(options ...consumer.Option) (*consumer.Consumer, error) {
+	return consumer.NewConsumer(
+		s.client,
+		s.streamId,
+		s.topicId,
+		s.Handle,
+		append([]consumer.Option{consumer.WithAutoCommit(false)}, options...)...,
+	)
+}
+
+// Handle is the handler of the consumer of the scheduler topic: it delivers the message to its
+// target topic when due, waits for it when it is due within the interval, and otherwise
+// re-enqueues it to the end of the scheduler topic, at most once per interval.
+func (s *Scheduler) Handle(ctx context.Context, received iggcon.ReceivedMessage) error {
+	message := received.Message
+	deliverAt, _ := iggcon.MessageDeliverAt(&message)
+	var requeuedAt time.Time
+	if value, ok := message.UserHeader(RequeuedAtHeader); ok {
+		micros, err := value.AsUint64()
+		if err != nil {
+			return err
+		}
+		requeuedAt = time.UnixMicro(int64(micros))
+	}
+
+	for {
+		now := s.clock.Now()
+		if !now.Before(deliverAt) {
+			return s.deliver(message)
+		}
+		wake := deliverAt
+		if deliverAt.Sub(now) > s.interval {
+			if wake = requeuedAt.Add(s.interval); !now.Before(wake) {
+				return s.requeue(message, now)
+			}
+		}
+		timer := time.NewTimer(wake.Sub(now))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (s *Scheduler) deliver(message iggcon.MessengerMessage) error {
+	headers, err := iggcon.DeserializeHeaders(message.UserHeaders)
+	if err != nil {
+		return err
+	}
+	streamId, err := decodeIdentifier(headers[iggcon.HeaderKey{Value: TargetStreamHeader}].Value)
+	if err != nil {
+		return err
+	}
+	topicId, err := decodeIdentifier(headers[iggcon.HeaderKey{Value: TargetTopicHeader}].Value)
+	if err != nil {
+		return err
+	}
+	partitioning, err := decodePartitioning(headers[iggcon.HeaderKey{Value: TargetPartitioningHeader}].Value)
+	if err != nil {
+		return err
+	}
+	for _, key := range []string{TargetStreamHeader, TargetTopicHeader, TargetPartitioningHeader, RequeuedAtHeader} {
+		delete(headers, iggcon.HeaderKey{Value: key})
+	}
+	delivered, err := copyMessage(message, headers)
+	if err != nil {
+		return err
+	}
+	return s.client.SendMessages(streamId, topicId, partitioning, []iggcon.MessengerMessage{delivered})
+}
+
+func (s *Scheduler) requeue(message iggcon.MessengerMessage, now time.Time) error {
+	headers, err := iggcon.DeserializeHeaders(message.UserHeaders)
+	if err != nil {
+		return err
+	}
+	headers[iggcon.HeaderKey{Value: RequeuedAtHeader}] = iggcon.NewUint64HeaderValue(uint64(now.UnixMicro()))
+	requeued, err := copyMessage(message, headers)
+	if err != nil {
+		return err
+	}
+	return s.client.SendMessages(s.streamId, s.topicId, iggcon.None(), []iggcon.MessengerMessage{requeued})
+}
+
+// copyMessage creates a new message with the payload and origin timestamp of message, and the
+// given user headers.
+func copyMessage(message iggcon.MessengerMessage, headers map[iggcon.HeaderKey]iggcon.HeaderValue) (iggcon.MessengerMessage, error) {
+	copied, err := iggcon.NewMessengerMessage(message.Payload, iggcon.WithTimestamp(time.UnixMicro(int64(message.Header.OriginTimestamp))))
+	if err != nil {
+		return iggcon.MessengerMessage{}, err
+	}
+	if err = copied.SetUserHeaders(headers); err != nil {
+		return iggcon.MessengerMessage{}, err
+	}
+	return copied, nil
+}
+
+func encodeIdentifier(id iggcon.Identifier) []byte {
+	return append([]byte{byte(id.Kind)}, id.Value...)
+}
+
+func decodeIdentifier(b []byte) (iggcon.Identifier, error) {
+	if len(b) < 2 {
+		return iggcon.Identifier{}, fmt.Errorf("schedule: invalid target %x", b)
+	}
+	id, err := iggcon.IdentifierFromBytes(iggcon.IdKind(b[0]), b[1:])
+	if err != nil {
+		return iggcon.Identifier{}, fmt.Errorf("schedule: invalid target %x: %w", b, err)
+	}
+	return id, nil
+}
+
+func encodePartitioning(partitioning iggcon.Partitioning) []byte {
+	return append([]byte{byte(partitioning.Kind)}, partitioning.Value...)
+}
+
+func decodePartitioning(b []byte) (iggcon.Partitioning, error) {
+	if len(b) == 0 {
+		return iggcon.Partitioning{}, fmt.Errorf("schedule: invalid target partitioning %x", b)
+	}
+	return iggcon.Partitioning{Kind: iggcon.PartitioningKind(b[0]), Length: len(b) - 1, Value: b[1:]}, nil
+}
@@ -0,0 +1,109 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schedule_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/apache/messenger/foreign/go/consumer"
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengertest"
+	"github.com/apache/messenger/foreign/go/producer"
+	"github.com/apache/messenger/foreign/go/schedule"
+)
+
+func TestScheduler(t *testing.T) {
+	server := messengertest.NewClient()
+	if _, err := server.CreateStream("app", nil); err != nil {
+		t.Fatal(err)
+	}
+	streamId := iggcon.MustIdentifier("app")
+	for _, name := range []string{"jobs", "scheduled"} {
+		if _, err := server.CreateTopic(streamId, name, 1, iggcon.CompressionAlgorithmNone, 0, 0, nil, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	jobsId, scheduledId := iggcon.MustIdentifier("jobs"), iggcon.MustIdentifier("scheduled")
+
+	p, err := producer.NewProducer(schedule.NewClient(server, streamId, scheduledId), streamId, jobsId, producer.WithPartitioning(iggcon.PartitionId(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sent := time.Now()
+	deliverAt := map[string]time.Time{"now": {}, "soon": sent.Add(50 * time.Millisecond), "later": sent.Add(300 * time.Millisecond)}
+	for _, payload := range []string{"later", "soon", "now"} {
+		message, err := iggcon.NewMessengerMessage([]byte(payload), iggcon.WithDeliverAt(deliverAt[payload]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = p.Send(context.Background(), message); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = p.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	partitionId := uint32(1)
+	poll := func() []iggcon.MessengerMessage {
+		polled, err := server.PollMessages(streamId, jobsId, iggcon.DefaultConsumer(), iggcon.FirstPollingStrategy(), 10, false, &partitionId)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return polled.Messages
+	}
+	if messages := poll(); len(messages) != 1 || string(messages[0].Payload) != "now" {
+		t.Fatalf("expected only the message due now to be delivered, got %d messages", len(messages))
+	}
+
+	scheduler := schedule.NewScheduler(server, streamId, scheduledId, schedule.WithInterval(100*time.Millisecond))
+	worker, err := scheduler.NewWorker(consumer.WithPollInterval(10 * time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ran := make(chan error, 1)
+	go func() {
+		ran <- worker.Run(ctx)
+	}()
+
+	delivered := map[string]time.Time{}
+	for len(delivered) < 3 && ctx.Err() == nil {
+		for _, message := range poll() {
+			if _, ok := delivered[string(message.Payload)]; !ok {
+				delivered[string(message.Payload)] = time.Now()
+				if _, ok := message.UserHeader(schedule.TargetTopicHeader); ok {
+					t.Errorf("expected the scheduling headers to be removed from %s", message.Payload)
+				}
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	if err = <-ran; err != nil {
+		t.Fatal(err)
+	}
+	for payload, at := range deliverAt {
+		if delivered[payload].IsZero() || delivered[payload].Before(at) {
+			t.Errorf("expected %s to be delivered after %v, got %v", payload, at, delivered[payload])
+		}
+	}
+}
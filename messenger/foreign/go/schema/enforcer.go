@@ -0,0 +1,251 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengercli"
+)
+
+// Headers set on the quarantined messages.
+const (
+	// ViolationHeader is the reason the message was quarantined.
+	ViolationHeader = "schema-violation"
+	// OriginTopicHeader is the name or ID of the topic the message was sent to.
+	OriginTopicHeader = "schema-origin-topic"
+)
+
+// controlPartitionId is the partition the rules are read from, the control topic should have a
+// single partition so the rules keep their publication order.
+const controlPartitionId = 1
+
+// syncBatchSize is the number of rules polled by a single request of Sync.
+const syncBatchSize = 100
+
+type topicKey struct {
+	stream string
+	topic  string
+}
+
+// Enforcer caches the rules published on a control topic and applies them to the messages sent
+// through the client. It implements messengercli.ProducerInterceptor:
+//
+//	enforcer := schema.NewEnforcer(schema.WithQuarantine(raw))
+//	go enforcer.Watch(ctx, raw, controlStreamId, controlTopicId, 10*time.Second)
+//	cli := messengercli.InterceptClient(raw, messengercli.ProducerInterceptors{enforcer}, nil)
+//
+// A send containing a non-conforming message fails with a *ViolationError, unless the rule has a
+// quarantine topic: the message is then sent there and the other messages go on.
+type Enforcer struct {
+	quarantine messengercli.DataClient
+
+	mtx   sync.RWMutex
+	rules map[topicKey]Rule
+	// next is the offset of the next rule to read from the control topic.
+	next uint64
+}
+
+type Option func(enforcer *Enforcer)
+
+// WithQuarantine sets the client the quarantined messages are sent with. It should not be
+// intercepted by the enforcer itself. Without it, the rules with a quarantine topic refuse the
+// non-conforming messages.
+func WithQuarantine(client messengercli.DataClient) Option {
+	return func(enforcer *Enforcer) {
+		enforcer.quarantine = client
+	}
+}
+
+// NewEnforcer creates an Enforcer without rules.
+func NewEnforcer(options ...Option) *Enforcer {
+	e := &Enforcer{rules: map[topicKey]Rule{}}
+	for _, opt := range options {
+		if opt != nil {
+			opt(e)
+		}
+	}
+	return e
+}
+
+// Apply adds, replaces or removes the rule of a topic.
+func (e *Enforcer) Apply(rule Rule) {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	key := topicKey{stream: rule.Stream, topic: rule.Topic}
+	if rule.Removed {
+		delete(e.rules, key)
+		return
+	}
+	e.rules[key] = rule
+}
+
+// ApplyMessages applies the rules carried by control topic messages, skipping the undecodable ones.
+func (e *Enforcer) ApplyMessages(messages []iggcon.MessengerMessage) {
+	for _, message := range messages {
+		rule, err := Decode(message)
+		if err != nil {
+			log.Printf("[WARN] schema: skipping undecodable rule at offset %d: %v", message.Header.Offset, err)
+			continue
+		}
+		e.Apply(rule)
+	}
+}
+
+// Rule returns the rule of a topic, if any.
+func (e *Enforcer) Rule(streamId, topicId iggcon.Identifier) (Rule, bool) {
+	e.mtx.RLock()
+	defer e.mtx.RUnlock()
+	rule, ok := e.rules[topicKey{stream: identifierName(streamId), topic: identifierName(topicId)}]
+	return rule, ok
+}
+
+// Sync reads the rules published on the control topic since the previous Sync.
+func (e *Enforcer) Sync(client messengercli.DataClient, streamId, topicId iggcon.Identifier) error {
+	partitionId := uint32(controlPartitionId)
+	for {
+		e.mtx.RLock()
+		next := e.next
+		e.mtx.RUnlock()
+
+		polled, err := client.PollMessages(
+			streamId,
+			topicId,
+			iggcon.DefaultConsumer(),
+			iggcon.OffsetPollingStrategy(next),
+			syncBatchSize,
+			false,
+			&partitionId,
+		)
+		if err != nil {
+			return err
+		}
+		if polled == nil || len(polled.Messages) == 0 {
+			return nil
+		}
+		e.ApplyMessages(polled.Messages)
+		e.mtx.Lock()
+		e.next = polled.Messages[len(polled.Messages)-1].Header.Offset + 1
+		e.mtx.Unlock()
+	}
+}
+
+// Watch syncs the rules every interval until the context is done. The failed syncs are logged,
+// and the cached rules keep being enforced until the control topic is reachable again.
+func (e *Enforcer) Watch(ctx context.Context, client messengercli.DataClient, streamId, topicId iggcon.Identifier, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := e.Sync(client, streamId, topicId); err != nil {
+			log.Printf("[WARN] schema: failed to sync the rules: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// OnSend refuses or quarantines the messages not conforming to the rule of the topic.
+func (e *Enforcer) OnSend(streamId, topicId iggcon.Identifier, messages []iggcon.MessengerMessage) ([]iggcon.MessengerMessage, error) {
+	rule, ok := e.Rule(streamId, topicId)
+	if !ok {
+		return messages, nil
+	}
+	conforming := make([]iggcon.MessengerMessage, 0, len(messages))
+	var quarantined []iggcon.MessengerMessage
+	for _, message := range messages {
+		err := rule.Check(message)
+		if err == nil {
+			conforming = append(conforming, message)
+			continue
+		}
+		if rule.QuarantineTopic == "" || e.quarantine == nil {
+			return nil, err
+		}
+		message, err = quarantine(message, rule.Topic, err.(*ViolationError).Reason)
+		if err != nil {
+			return nil, err
+		}
+		quarantined = append(quarantined, message)
+	}
+	if len(quarantined) > 0 {
+		quarantineTopicId, err := parseIdentifier(rule.QuarantineTopic)
+		if err != nil {
+			return nil, err
+		}
+		if err = e.quarantine.SendMessages(streamId, quarantineTopicId, iggcon.None(), quarantined); err != nil {
+			return nil, err
+		}
+	}
+	return conforming, nil
+}
+
+func (e *Enforcer) OnAcknowledgement(_, _ iggcon.Identifier, _ []iggcon.MessengerMessage, _ error) {
+}
+
+// quarantine creates a copy of message carrying the reason of its quarantine.
+func quarantine(message iggcon.MessengerMessage, originTopic string, reason string) (iggcon.MessengerMessage, error) {
+	copied, err := iggcon.NewMessengerMessage(message.Payload)
+	if err != nil {
+		return iggcon.MessengerMessage{}, err
+	}
+	headers := map[iggcon.HeaderKey]iggcon.HeaderValue{}
+	if len(message.UserHeaders) > 0 {
+		// the headers may be the reason of the quarantine, they are dropped if unreadable
+		if decoded, err := iggcon.DeserializeHeaders(message.UserHeaders); err == nil {
+			headers = decoded
+		}
+	}
+	headers[iggcon.HeaderKey{Value: ViolationHeader}] = iggcon.NewStringHeaderValue(truncate(reason, 255))
+	headers[iggcon.HeaderKey{Value: OriginTopicHeader}] = iggcon.NewStringHeaderValue(originTopic)
+	if err = copied.SetUserHeaders(headers); err != nil {
+		return iggcon.MessengerMessage{}, err
+	}
+	return copied, nil
+}
+
+func identifierName(id iggcon.Identifier) string {
+	if name, err := id.String(); err == nil {
+		return name
+	}
+	if value, err := id.Uint32(); err == nil {
+		return strconv.FormatUint(uint64(value), 10)
+	}
+	return ""
+}
+
+func parseIdentifier(name string) (iggcon.Identifier, error) {
+	if value, err := strconv.ParseUint(name, 10, 32); err == nil {
+		return iggcon.NewIdentifier(uint32(value))
+	}
+	return iggcon.NewIdentifier(name)
+}
+
+func truncate(s string, length int) string {
+	if len(s) <= length {
+		return s
+	}
+	return s[:length]
+}
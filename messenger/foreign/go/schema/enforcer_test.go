@@ -0,0 +1,142 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package schema
+
+import (
+	"errors"
+	"testing"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengercli"
+	"github.com/apache/messenger/foreign/go/messengertest"
+)
+
+func mustIdentifier(t *testing.T, name string) iggcon.Identifier {
+	id, err := iggcon.NewIdentifier(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+func mustMessage(t *testing.T, payload string, headers map[iggcon.HeaderKey]iggcon.HeaderValue) iggcon.MessengerMessage {
+	message, err := iggcon.NewMessengerMessage([]byte(payload), iggcon.WithUserHeaders(headers))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return message
+}
+
+func pollPayloads(t *testing.T, client *messengertest.Client, streamId, topicId iggcon.Identifier) []string {
+	partitionId := uint32(1)
+	polled, err := client.PollMessages(streamId, topicId, iggcon.DefaultConsumer(), iggcon.FirstPollingStrategy(), 100, false, &partitionId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var payloads []string
+	for _, message := range polled.Messages {
+		payloads = append(payloads, string(message.Payload))
+	}
+	return payloads
+}
+
+func TestEnforcer_SyncAndEnforce(t *testing.T) {
+	raw := messengertest.NewClient()
+	if _, err := raw.CreateStream("shop", nil); err != nil {
+		t.Fatal(err)
+	}
+	streamId := mustIdentifier(t, "shop")
+	for _, topic := range []string{"schemas", "orders", "orders-quarantine", "payments"} {
+		if _, err := raw.CreateTopic(streamId, topic, 1, iggcon.CompressionAlgorithm(1), 0, 0, nil, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var rules []iggcon.MessengerMessage
+	for _, rule := range []Rule{
+		{Stream: "shop", Topic: "orders", RequiredFields: []string{"id"}, QuarantineTopic: "orders-quarantine"},
+		{Stream: "shop", Topic: "payments", RequiredHeaders: []string{"currency"}},
+		{Stream: "shop", Topic: "refunds", MaxPayloadBytes: 10},
+		{Stream: "shop", Topic: "refunds", Removed: true},
+	} {
+		message, err := rule.Message()
+		if err != nil {
+			t.Fatal(err)
+		}
+		rules = append(rules, message)
+	}
+	controlTopicId := mustIdentifier(t, "schemas")
+	if err := raw.SendMessages(streamId, controlTopicId, iggcon.None(), rules); err != nil {
+		t.Fatal(err)
+	}
+
+	enforcer := NewEnforcer(WithQuarantine(raw))
+	if err := enforcer.Sync(raw, streamId, controlTopicId); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := enforcer.Rule(streamId, mustIdentifier(t, "refunds")); ok {
+		t.Fatal("expected the removed rule to be lifted")
+	}
+	client := messengercli.InterceptClient(raw, messengercli.ProducerInterceptors{enforcer}, nil)
+
+	ordersId := mustIdentifier(t, "orders")
+	orders := []iggcon.MessengerMessage{mustMessage(t, `{"id":1}`, nil), mustMessage(t, `{"name":"x"}`, nil)}
+	if err := client.SendMessages(streamId, ordersId, iggcon.None(), orders); err != nil {
+		t.Fatal(err)
+	}
+	if payloads := pollPayloads(t, raw, streamId, ordersId); len(payloads) != 1 || payloads[0] != `{"id":1}` {
+		t.Fatalf("unexpected orders %v", payloads)
+	}
+	quarantineId := mustIdentifier(t, "orders-quarantine")
+	partitionId := uint32(1)
+	polled, err := raw.PollMessages(streamId, quarantineId, iggcon.DefaultConsumer(), iggcon.FirstPollingStrategy(), 10, false, &partitionId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(polled.Messages) != 1 {
+		t.Fatalf("expected 1 quarantined message, got %d", len(polled.Messages))
+	}
+	if reason, ok := polled.Messages[0].UserHeader(ViolationHeader); !ok || string(reason.Value) != `missing field "id"` {
+		t.Fatalf("unexpected violation header %+v", reason)
+	}
+
+	paymentsId := mustIdentifier(t, "payments")
+	err = client.SendMessages(streamId, paymentsId, iggcon.None(), []iggcon.MessengerMessage{mustMessage(t, "10", nil)})
+	var violation *ViolationError
+	if !errors.As(err, &violation) || violation.Rule.Topic != "payments" {
+		t.Fatalf("expected a violation of the payments rule, got %v", err)
+	}
+	headers := map[iggcon.HeaderKey]iggcon.HeaderValue{{Value: "currency"}: iggcon.NewStringHeaderValue("EUR")}
+	if err = client.SendMessages(streamId, paymentsId, iggcon.None(), []iggcon.MessengerMessage{mustMessage(t, "10", headers)}); err != nil {
+		t.Fatal(err)
+	}
+
+	removal, err := Rule{Stream: "shop", Topic: "payments", Removed: true}.Message()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = raw.SendMessages(streamId, controlTopicId, iggcon.None(), []iggcon.MessengerMessage{removal}); err != nil {
+		t.Fatal(err)
+	}
+	if err = enforcer.Sync(raw, streamId, controlTopicId); err != nil {
+		t.Fatal(err)
+	}
+	if err = client.SendMessages(streamId, paymentsId, iggcon.None(), []iggcon.MessengerMessage{mustMessage(t, "10", nil)}); err != nil {
+		t.Fatal(err)
+	}
+}
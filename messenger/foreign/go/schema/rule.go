@@ -0,0 +1,114 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package schema enforces topic schema requirements on the producer side. The requirements are
+// Rules published on a control topic; producers load them into an Enforcer, which refuses or
+// quarantines the non-conforming messages before they are sent, keeping bad data out of the
+// topics without a central gateway.
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+)
+
+// Rule is the schema requirement of a topic, published as a message of the control topic.
+// A later rule of the same stream and topic replaces the earlier one.
+type Rule struct {
+	// Stream and Topic are the names or IDs of the topic the rule applies to, as the producers
+	// identify it: a rule for a topic name does not apply to sends using its numeric ID.
+	Stream string `json:"stream"`
+	Topic  string `json:"topic"`
+	// RequiredHeaders are the user headers every message must carry.
+	RequiredHeaders []string `json:"required_headers,omitempty"`
+	// RequiredFields are the top-level fields the payload, a JSON object, must contain.
+	// When empty, the payload is not required to be JSON.
+	RequiredFields []string `json:"required_fields,omitempty"`
+	// MaxPayloadBytes bounds the payload size, 0 means unbounded.
+	MaxPayloadBytes int `json:"max_payload_bytes,omitempty"`
+	// QuarantineTopic is the name or ID of the topic of the same stream the non-conforming
+	// messages are sent to. When empty, the sends containing such messages are refused.
+	QuarantineTopic string `json:"quarantine_topic,omitempty"`
+	// Removed lifts the requirements of the topic.
+	Removed bool `json:"removed,omitempty"`
+}
+
+// Message encodes the rule as the payload of a control topic message.
+func (r Rule) Message() (iggcon.MessengerMessage, error) {
+	payload, err := json.Marshal(r)
+	if err != nil {
+		return iggcon.MessengerMessage{}, err
+	}
+	return iggcon.NewMessengerMessage(payload)
+}
+
+// Decode reads the rule carried by a control topic message.
+func Decode(message iggcon.MessengerMessage) (Rule, error) {
+	var r Rule
+	err := json.Unmarshal(message.Payload, &r)
+	return r, err
+}
+
+// ViolationError is returned for a message not conforming to the rule of its topic.
+type ViolationError struct {
+	Rule   Rule
+	Reason string
+}
+
+func (e *ViolationError) Error() string {
+	return fmt.Sprintf("schema: message violates the rule of topic %s/%s: %s", e.Rule.Stream, e.Rule.Topic, e.Reason)
+}
+
+// Check returns a *ViolationError if the message does not conform to the rule.
+func (r Rule) Check(message iggcon.MessengerMessage) error {
+	if r.MaxPayloadBytes > 0 && len(message.Payload) > r.MaxPayloadBytes {
+		return r.violation(fmt.Sprintf("payload of %d bytes exceeds %d bytes", len(message.Payload), r.MaxPayloadBytes))
+	}
+	if len(r.RequiredHeaders) > 0 {
+		headers := map[iggcon.HeaderKey]iggcon.HeaderValue{}
+		if len(message.UserHeaders) > 0 {
+			var err error
+			if headers, err = iggcon.DeserializeHeaders(message.UserHeaders); err != nil {
+				return r.violation("invalid user headers: " + err.Error())
+			}
+		}
+		for _, header := range r.RequiredHeaders {
+			if _, ok := headers[iggcon.HeaderKey{Value: header}]; !ok {
+				return r.violation("missing header " + strconv.Quote(header))
+			}
+		}
+	}
+	if len(r.RequiredFields) > 0 {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(message.Payload, &fields); err != nil {
+			return r.violation("payload is not a JSON object")
+		}
+		for _, field := range r.RequiredFields {
+			if _, ok := fields[field]; !ok {
+				return r.violation("missing field " + strconv.Quote(field))
+			}
+		}
+	}
+	return nil
+}
+
+func (r Rule) violation(reason string) error {
+	return &ViolationError{Rule: r, Reason: reason}
+}
@@ -0,0 +1,78 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tcp
+
+import (
+	"encoding/binary"
+	"errors"
+	"strconv"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	ierror "github.com/apache/messenger/foreign/go/errors"
+)
+
+// authorizationError enriches an unauthenticated or unauthorized error of the server with the
+// command, the resources identified at the start of its message and the permission granting it.
+// The other errors are returned as is.
+func authorizationError(command iggcon.CommandCode, message []byte, err error) error {
+	var messengerErr *ierror.MessengerError
+	if !errors.As(err, &messengerErr) || (messengerErr.Code != ierror.UnauthenticatedCode && messengerErr.Code != ierror.UnauthorizedCode) {
+		return err
+	}
+	return &ierror.AuthorizationError{
+		Err:        messengerErr,
+		Command:    command.String(),
+		Resources:  decodeResources(command.Resources(), message),
+		Permission: command.Permission(),
+	}
+}
+
+// decodeResources reads the identifiers of the given kinds at the start of a message, stopping at
+// the first one that cannot be read.
+func decodeResources(kinds []string, message []byte) []ierror.Resource {
+	var resources []ierror.Resource
+	position := 0
+	for _, kind := range kinds {
+		if kind == "consumer" {
+			// the consumer kind precedes its identifier
+			position++
+		}
+		if position+2 > len(message) {
+			break
+		}
+		idKind, length := iggcon.IdKind(message[position]), int(message[position+1])
+		position += 2
+		if position+length > len(message) {
+			break
+		}
+		value := message[position : position+length]
+		position += length
+
+		resource := ierror.Resource{Kind: kind}
+		switch {
+		case idKind == iggcon.NumericId && length == 4:
+			resource.Id = strconv.FormatUint(uint64(binary.LittleEndian.Uint32(value)), 10)
+		case idKind == iggcon.StringId:
+			resource.Id = strconv.Quote(string(value))
+		default:
+			return resources
+		}
+		resources = append(resources, resource)
+	}
+	return resources
+}
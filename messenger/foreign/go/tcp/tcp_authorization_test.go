@@ -0,0 +1,102 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tcp
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"testing"
+
+	binaryserialization "github.com/apache/messenger/foreign/go/binary_serialization"
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	ierror "github.com/apache/messenger/foreign/go/errors"
+)
+
+func TestAuthorizationError(t *testing.T) {
+	server, client := net.Pipe()
+	defer server.Close()
+	go func() {
+		// answer every request with the unauthorized error
+		header := make([]byte, 4)
+		for {
+			if _, err := io.ReadFull(server, header); err != nil {
+				return
+			}
+			if _, err := io.CopyN(io.Discard, server, int64(binary.LittleEndian.Uint32(header))); err != nil {
+				return
+			}
+			response := make([]byte, 8)
+			binary.LittleEndian.PutUint32(response, ierror.UnauthorizedCode)
+			if _, err := server.Write(response); err != nil {
+				return
+			}
+		}
+	}()
+	cli, err := NewMessengerTcpClient(
+		WithDialFunc(func(context.Context, string, string) (net.Conn, error) { return client, nil }),
+		func(opts *Options) { opts.HeartbeatInterval = 0 },
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = cli.GetTopic(iggcon.MustIdentifier("orders"), iggcon.MustIdentifier(uint32(3)))
+	var authorizationErr *ierror.AuthorizationError
+	if !errors.As(err, &authorizationErr) {
+		t.Fatalf("expected an AuthorizationError, got %v", err)
+	}
+	expected := `41: 'unauthorized': GetTopic of stream "orders", topic 3 requires the ReadTopics permission`
+	if err.Error() != expected {
+		t.Fatalf("expected %s, got %s", expected, err)
+	}
+	var messengerErr *ierror.MessengerError
+	if !errors.As(err, &messengerErr) || messengerErr.Code != ierror.UnauthorizedCode {
+		t.Fatalf("expected the error to unwrap to the error of the server, got %v", err)
+	}
+
+	message, _ := iggcon.NewMessengerMessage([]byte("hello"))
+	err = cli.SendMessages(iggcon.MustIdentifier("orders"), iggcon.MustIdentifier("created"), iggcon.None(), []iggcon.MessengerMessage{message})
+	expected = `41: 'unauthorized': SendMessages of stream "orders", topic "created" requires the SendMessages permission`
+	if err == nil || err.Error() != expected {
+		t.Fatalf("expected %s, got %v", expected, err)
+	}
+}
+
+func TestDecodeResources(t *testing.T) {
+	message := binaryserialization.GetOffset(iggcon.GetConsumerOffsetRequest{
+		Consumer: iggcon.NewGroupConsumer(iggcon.MustIdentifier("billing")),
+		StreamId: iggcon.MustIdentifier(uint32(1)),
+		TopicId:  iggcon.MustIdentifier("created"),
+	})
+	resources := decodeResources(iggcon.GetOffsetCode.Resources(), message)
+	expected := []ierror.Resource{{Kind: "consumer", Id: `"billing"`}, {Kind: "stream", Id: "1"}, {Kind: "topic", Id: `"created"`}}
+	if len(resources) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, resources)
+	}
+	for i := range expected {
+		if resources[i] != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, resources)
+		}
+	}
+	if resources = decodeResources(iggcon.GetOffsetCode.Resources(), message[:5]); len(resources) != 0 {
+		t.Fatalf("expected a truncated message to yield no resource, got %v", resources)
+	}
+}
@@ -0,0 +1,97 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tcp
+
+import (
+	"bytes"
+	"log"
+	"net"
+	"slices"
+	"time"
+
+	"github.com/apache/messenger/foreign/go/capture"
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+)
+
+// WithFrameCapture records the raw request and response frames to the writer, with their command
+// codes and timestamps, to be replayed through the deserializers by the messenger-replay command.
+// It is a debug mode: frames are copied and written synchronously, and include the credentials
+// sent to log in.
+func WithFrameCapture(writer *capture.Writer) Option {
+	return func(opts *Options) {
+		opts.FrameCapture = writer
+	}
+}
+
+// captureConn records the bytes written to and read from a connection.
+type captureConn struct {
+	net.Conn
+	written bytes.Buffer
+	read    bytes.Buffer
+}
+
+func (c *captureConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	c.written.Write(b[:n])
+	return n, err
+}
+
+func (c *captureConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	c.read.Write(b[:n])
+	return n, err
+}
+
+// startCapture wraps the connection to record the frames of a request. Must hold tms.mtx.
+func (tms *MessengerTcpClient) startCapture() *captureConn {
+	if tms.capture == nil {
+		return nil
+	}
+	conn := &captureConn{Conn: tms.conn}
+	tms.conn = conn
+	return conn
+}
+
+// endCapture restores the connection and writes the frames recorded since startCapture, the
+// request started at the given time. Must hold tms.mtx.
+func (tms *MessengerTcpClient) endCapture(conn *captureConn, command iggcon.CommandCode, start time.Time) {
+	if conn == nil {
+		return
+	}
+	tms.conn = conn.Conn
+	dialect := tms.Dialect().Name
+	frames := []capture.Frame{{
+		Direction: capture.Request,
+		Time:      start,
+		Command:   command,
+		Dialect:   dialect,
+		Data:      slices.Clone(conn.written.Bytes()),
+	}}
+	if conn.read.Len() > 0 {
+		frames = append(frames, capture.Frame{
+			Direction: capture.Response,
+			Time:      time.Now(),
+			Command:   command,
+			Dialect:   dialect,
+			Data:      slices.Clone(conn.read.Bytes()),
+		})
+	}
+	if err := tms.capture.Write(frames...); err != nil {
+		log.Printf("[WARN] failed to capture the frames of command %d: %v", command, err)
+	}
+}
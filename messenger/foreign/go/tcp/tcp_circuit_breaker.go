@@ -0,0 +1,262 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tcp
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"sync"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	ierror "github.com/apache/messenger/foreign/go/errors"
+)
+
+// ErrCircuitOpen is returned without sending the request while the circuit of its command class
+// is open.
+var ErrCircuitOpen = errors.New("tcp: circuit open")
+
+// CommandClass groups the commands sharing a circuit breaker.
+type CommandClass uint8
+
+const (
+	// CommandClassSession holds the ping, login and logout commands.
+	CommandClassSession CommandClass = iota + 1
+	// CommandClassSend holds the commands sending messages.
+	CommandClassSend
+	// CommandClassPoll holds the commands polling messages.
+	CommandClassPoll
+	// CommandClassConsumer holds the consumer offset and consumer group commands.
+	CommandClassConsumer
+	// CommandClassAdmin holds the other commands, managing the streams, topics, users and so on.
+	CommandClassAdmin
+)
+
+func (c CommandClass) String() string {
+	switch c {
+	case CommandClassSession:
+		return "session"
+	case CommandClassSend:
+		return "send"
+	case CommandClassPoll:
+		return "poll"
+	case CommandClassConsumer:
+		return "consumer"
+	case CommandClassAdmin:
+		return "admin"
+	}
+	return "unknown"
+}
+
+// commandClass returns the class of a command.
+func commandClass(command iggcon.CommandCode) CommandClass {
+	switch command {
+	case iggcon.PingCode, iggcon.LoginUserCode, iggcon.LoginWithAccessTokenCode, iggcon.LogoutUserCode:
+		return CommandClassSession
+	case iggcon.SendMessagesCode:
+		return CommandClassSend
+	case iggcon.PollMessagesCode:
+		return CommandClassPoll
+	case iggcon.GetOffsetCode, iggcon.StoreOffsetCode, iggcon.DeleteOffsetCode,
+		iggcon.GetGroupCode, iggcon.GetGroupsCode, iggcon.JoinGroupCode, iggcon.LeaveGroupCode:
+		return CommandClassConsumer
+	}
+	return CommandClassAdmin
+}
+
+// CircuitState is the state of a circuit breaker.
+type CircuitState uint8
+
+const (
+	// CircuitClosed lets every request through.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen fails the requests fast, without sending them.
+	CircuitOpen
+	// CircuitHalfOpen lets probe requests through, closing the circuit once they succeeded or
+	// opening it again as soon as one failed.
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// CircuitBreakerConfig configures the circuit breaker of a command class. The transport errors,
+// the timeouts and the slow requests count as failures, while the errors returned by the server
+// count as successes, the server being able to answer.
+type CircuitBreakerConfig struct {
+	// FailureRate, between 0 and 1, opens the circuit once reached by the failed requests of a
+	// window.
+	FailureRate float64
+	// MinRequests is the number of requests of a window below which the circuit stays closed.
+	MinRequests int
+	// Window is the period the failure rate is computed over.
+	Window time.Duration
+	// SlowRequest, when positive, counts the requests slower than it as failures, the time the
+	// server holds a long poll excluded.
+	SlowRequest time.Duration
+	// OpenDuration is the time the open circuit fails the requests before letting probes through.
+	OpenDuration time.Duration
+	// Probes is the number of probe requests which must succeed to close the half-open circuit.
+	Probes int
+}
+
+// DefaultCircuitBreakerConfig returns the circuit breaker configuration used by
+// WithCircuitBreaker unless configured otherwise.
+func DefaultCircuitBreakerConfig() CircuitBreakerConfig {
+	return CircuitBreakerConfig{
+		FailureRate:  0.5,
+		MinRequests:  20,
+		Window:       10 * time.Second,
+		OpenDuration: 30 * time.Second,
+		Probes:       3,
+	}
+}
+
+// WithCircuitBreaker fails the requests of the given command classes fast once too many of them
+// failed or were slow, so a cascade of timeouts does not pile up behind an unavailable server,
+// and lets probe requests through after a while to recover. Without classes, it applies to every
+// class but CommandClassSession, the login of a reconnection being sent anyway. It can be used
+// several times to configure the classes differently.
+func WithCircuitBreaker(config CircuitBreakerConfig, classes ...CommandClass) Option {
+	return func(opts *Options) {
+		if len(classes) == 0 {
+			classes = []CommandClass{CommandClassSend, CommandClassPoll, CommandClassConsumer, CommandClassAdmin}
+		}
+		opts.CircuitBreakers = maps.Clone(opts.CircuitBreakers)
+		if opts.CircuitBreakers == nil {
+			opts.CircuitBreakers = map[CommandClass]CircuitBreakerConfig{}
+		}
+		for _, class := range classes {
+			opts.CircuitBreakers[class] = config
+		}
+	}
+}
+
+// CircuitState returns the state of the circuit breaker of a command class, CircuitClosed when
+// the class has none.
+func (tms *MessengerTcpClient) CircuitState(class CommandClass) CircuitState {
+	breaker, ok := tms.breakers[class]
+	if !ok {
+		return CircuitClosed
+	}
+	breaker.mtx.Lock()
+	defer breaker.mtx.Unlock()
+	if breaker.state == CircuitOpen && breaker.now().Sub(breaker.openedAt) >= breaker.config.OpenDuration {
+		return CircuitHalfOpen
+	}
+	return breaker.state
+}
+
+// newCircuitBreakers creates the circuit breakers of the configured command classes.
+func newCircuitBreakers(configs map[CommandClass]CircuitBreakerConfig) map[CommandClass]*circuitBreaker {
+	breakers := make(map[CommandClass]*circuitBreaker, len(configs))
+	for class, config := range configs {
+		breakers[class] = &circuitBreaker{class: class, config: config, now: time.Now}
+	}
+	return breakers
+}
+
+// circuitBreaker tracks the failures of the requests of a command class.
+type circuitBreaker struct {
+	class  CommandClass
+	config CircuitBreakerConfig
+	now    func() time.Time
+
+	mtx         sync.Mutex
+	state       CircuitState
+	windowStart time.Time
+	requests    int
+	failures    int
+	openedAt    time.Time
+	// probing counts the probes in flight, and probed the probes which succeeded.
+	probing int
+	probed  int
+}
+
+// allow reports whether a request can be sent, returning ErrCircuitOpen otherwise.
+func (b *circuitBreaker) allow() error {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	now := b.now()
+	switch b.state {
+	case CircuitOpen:
+		if now.Sub(b.openedAt) < b.config.OpenDuration {
+			return fmt.Errorf("%w: the %s requests are failing", ErrCircuitOpen, b.class)
+		}
+		b.state, b.probing, b.probed = CircuitHalfOpen, 0, 0
+		fallthrough
+	case CircuitHalfOpen:
+		if b.probing+b.probed >= max(b.config.Probes, 1) {
+			return fmt.Errorf("%w: the %s requests are being probed", ErrCircuitOpen, b.class)
+		}
+		b.probing++
+	case CircuitClosed:
+		if now.Sub(b.windowStart) >= b.config.Window {
+			b.windowStart, b.requests, b.failures = now, 0, 0
+		}
+	}
+	return nil
+}
+
+// record records the outcome of a request allowed through.
+func (b *circuitBreaker) record(latency time.Duration, err error) {
+	var messengerErr *ierror.MessengerError
+	failed := err != nil && !errors.As(err, &messengerErr) && !errors.Is(err, ErrClosed) ||
+		b.config.SlowRequest > 0 && latency > b.config.SlowRequest
+
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	switch b.state {
+	case CircuitHalfOpen:
+		b.probing--
+		if failed {
+			b.open()
+			return
+		}
+		b.probed++
+		if b.probed >= max(b.config.Probes, 1) {
+			b.state, b.windowStart, b.requests, b.failures = CircuitClosed, b.now(), 0, 0
+			log.Printf("[WARN] circuit of the %s requests closed", b.class)
+		}
+	case CircuitClosed:
+		b.requests++
+		if failed {
+			b.failures++
+		}
+		if b.requests >= b.config.MinRequests && float64(b.failures) >= b.config.FailureRate*float64(b.requests) {
+			b.open()
+		}
+	}
+}
+
+// open opens the circuit. Must hold b.mtx.
+func (b *circuitBreaker) open() {
+	b.state, b.openedAt = CircuitOpen, b.now()
+	log.Printf("[WARN] circuit of the %s requests opened for %s", b.class, b.config.OpenDuration)
+}
@@ -0,0 +1,125 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tcp
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	ierror "github.com/apache/messenger/foreign/go/errors"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Unix(0, 0)
+	breaker := newCircuitBreakers(map[CommandClass]CircuitBreakerConfig{CommandClassSend: {
+		FailureRate:  0.5,
+		MinRequests:  4,
+		Window:       time.Minute,
+		SlowRequest:  time.Second,
+		OpenDuration: 10 * time.Second,
+		Probes:       2,
+	}})[CommandClassSend]
+	breaker.now = func() time.Time { return now }
+	send := func(latency time.Duration, err error) error {
+		if err := breaker.allow(); err != nil {
+			return err
+		}
+		breaker.record(latency, err)
+		return nil
+	}
+
+	transportErr := errors.New("connection reset")
+	for _, err := range []error{nil, ierror.MapFromCode(1009), transportErr} {
+		if err := send(time.Millisecond, err); err != nil {
+			t.Fatalf("expected the circuit to be closed, got %v", err)
+		}
+	}
+	// the slow request is the second failure out of 4
+	if err := send(2*time.Second, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := send(time.Millisecond, nil); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected the circuit to be open, got %v", err)
+	}
+
+	now = now.Add(10 * time.Second)
+	if err := send(time.Millisecond, transportErr); err != nil {
+		t.Fatalf("expected a probe to be let through, got %v", err)
+	}
+	if err := send(time.Millisecond, nil); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected the failed probe to open the circuit again, got %v", err)
+	}
+
+	now = now.Add(10 * time.Second)
+	if err := breaker.allow(); err != nil {
+		t.Fatal(err)
+	}
+	if err := send(time.Millisecond, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := send(time.Millisecond, nil); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected no more probes than configured, got %v", err)
+	}
+	breaker.record(time.Millisecond, nil)
+	if breaker.state != CircuitClosed {
+		t.Fatalf("expected the probes to close the circuit, got %s", breaker.state)
+	}
+}
+
+func TestCircuitBreaker_FailsRequestsFast(t *testing.T) {
+	var mtx sync.Mutex
+	var server net.Conn
+	cli, err := NewMessengerTcpClient(
+		WithDialFunc(func(context.Context, string, string) (net.Conn, error) {
+			mtx.Lock()
+			defer mtx.Unlock()
+			var client net.Conn
+			server, client = net.Pipe()
+			go serveRequests(server, &mtx, &[]iggcon.CommandCode{}, nil)
+			return client, nil
+		}),
+		WithCircuitBreaker(CircuitBreakerConfig{FailureRate: 1, MinRequests: 2, Window: time.Minute, OpenDuration: time.Minute, Probes: 1}),
+		func(opts *Options) { opts.HeartbeatInterval = 0 },
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mtx.Lock()
+	_ = server.Close()
+	mtx.Unlock()
+
+	for i := 0; i < 2; i++ {
+		if err = cli.DeleteStream(iggcon.MustIdentifier("orders")); err == nil || errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("expected the request to fail, got %v", err)
+		}
+	}
+	if err = cli.DeleteStream(iggcon.MustIdentifier("orders")); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected the request to fail fast, got %v", err)
+	}
+	if state := cli.CircuitState(CommandClassAdmin); state != CircuitOpen {
+		t.Fatalf("expected the circuit of the admin requests to be open, got %s", state)
+	}
+	if err = cli.Ping(); err == nil || errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected the session requests not to be guarded, got %v", err)
+	}
+}
@@ -18,8 +18,12 @@
 package tcp
 
 import (
+	"log"
+	"maps"
+
 	binaryserialization "github.com/apache/messenger/foreign/go/binary_serialization"
 	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	ierror "github.com/apache/messenger/foreign/go/errors"
 )
 
 func (tms *MessengerTcpClient) GetClients() ([]iggcon.ClientInfo, error) {
@@ -28,7 +32,7 @@ func (tms *MessengerTcpClient) GetClients() ([]iggcon.ClientInfo, error) {
 		return nil, err
 	}
 
-	return binaryserialization.DeserializeClients(buffer)
+	return binaryserialization.DeserializeClientsWithDialect(buffer, tms.Dialect())
 }
 
 func (tms *MessengerTcpClient) GetClient(clientId uint32) (*iggcon.ClientInfoDetails, error) {
@@ -38,5 +42,50 @@ func (tms *MessengerTcpClient) GetClient(clientId uint32) (*iggcon.ClientInfoDet
 		return nil, err
 	}
 
-	return binaryserialization.DeserializeClient(buffer), nil
+	return binaryserialization.DeserializeClientWithDialect(buffer, tms.Dialect()), nil
+}
+
+// Labels returns the labels attached to this client.
+func (tms *MessengerTcpClient) Labels() map[string]string {
+	tms.labelsMtx.RLock()
+	defer tms.labelsMtx.RUnlock()
+	return maps.Clone(tms.labels)
+}
+
+// UpdateClientLabels replaces the labels of this client on the server.
+func (tms *MessengerTcpClient) UpdateClientLabels(labels map[string]string) error {
+	if err := validateLabels(labels); err != nil {
+		return err
+	}
+	if !tms.Dialect().ClientLabels {
+		return ierror.CustomError("client_labels_not_supported_by_server")
+	}
+	message := binaryserialization.SerializeUpdateClientLabels(iggcon.UpdateClientLabelsRequest{Labels: labels})
+	if _, err := tms.sendAndFetchResponse(message, iggcon.UpdateClientLabelsCode); err != nil {
+		return err
+	}
+	tms.labelsMtx.Lock()
+	tms.labels = maps.Clone(labels)
+	tms.labelsMtx.Unlock()
+	return nil
+}
+
+// sendLabels attaches the labels set at connect time once the session is authenticated.
+func (tms *MessengerTcpClient) sendLabels() {
+	labels := tms.Labels()
+	if len(labels) == 0 {
+		return
+	}
+	if err := tms.UpdateClientLabels(labels); err != nil {
+		log.Printf("[WARN] failed to attach the client labels: %v", err)
+	}
+}
+
+func validateLabels(labels map[string]string) error {
+	for key, value := range labels {
+		if len(key) == 0 || len(key) > MaxStringLength || len(value) > MaxStringLength {
+			return ierror.CustomError("invalid_client_label")
+		}
+	}
+	return nil
 }
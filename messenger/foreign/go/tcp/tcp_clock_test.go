@@ -0,0 +1,72 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tcp_test
+
+import (
+	"testing"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengertest"
+	"github.com/apache/messenger/foreign/go/tcp"
+)
+
+func TestWithClock_StampsTheSentMessages(t *testing.T) {
+	server := messengertest.StartServer(t)
+	stampedAt := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	client, err := tcp.NewMessengerTcpClient(
+		tcp.WithServerAddress(server.Addr()),
+		tcp.WithClock(iggcon.ClockFunc(func() time.Time { return stampedAt })),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	if _, err = client.LoginUser("messenger", "messenger"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = client.CreateStream("orders", nil); err != nil {
+		t.Fatal(err)
+	}
+	streamId, topicId := iggcon.MustIdentifier("orders"), iggcon.MustIdentifier("created")
+	if _, err = client.CreateTopic(streamId, "created", 1, iggcon.CompressionAlgorithmNone, 0, 0, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	sentAt := time.Date(2010, 1, 2, 3, 4, 5, 0, time.UTC)
+	stamped, _ := iggcon.NewMessengerMessage([]byte("stamped"))
+	explicit, _ := iggcon.NewMessengerMessage([]byte("explicit"), iggcon.WithTimestamp(sentAt))
+	if err = client.SendMessages(streamId, topicId, iggcon.PartitionId(1), []iggcon.MessengerMessage{stamped, explicit}); err != nil {
+		t.Fatal(err)
+	}
+
+	partitionId := uint32(1)
+	polled, err := client.PollMessages(streamId, topicId, iggcon.DefaultConsumer(), iggcon.FirstPollingStrategy(), 10, false, &partitionId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(polled.Messages) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(polled.Messages))
+	}
+	if origin := polled.Messages[0].Header.OriginTimestamp; origin != uint64(stampedAt.UnixMicro()) {
+		t.Errorf("expected the message to be stamped by the clock at %d, got %d", stampedAt.UnixMicro(), origin)
+	}
+	if origin := polled.Messages[1].Header.OriginTimestamp; origin != uint64(sentAt.UnixMicro()) {
+		t.Errorf("expected WithTimestamp to override the clock with %d, got %d", sentAt.UnixMicro(), origin)
+	}
+}
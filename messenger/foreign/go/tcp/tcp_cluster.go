@@ -0,0 +1,163 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tcp
+
+import (
+	"encoding/binary"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	binaryserialization "github.com/apache/messenger/foreign/go/binary_serialization"
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	ierror "github.com/apache/messenger/foreign/go/errors"
+)
+
+// GetClusterMetadata returns the nodes of the cluster the server belongs to and the leader of
+// every partition, when the dialect of the server reports them.
+func (tms *MessengerTcpClient) GetClusterMetadata() (*iggcon.ClusterMetadata, error) {
+	if !tms.Dialect().ClusterMetadata {
+		return nil, ierror.CustomError("cluster_metadata_not_supported_by_server")
+	}
+	buffer, err := tms.sendAndFetchResponse([]byte{}, iggcon.GetClusterMetadataCode)
+	if err != nil {
+		return nil, err
+	}
+
+	return binaryserialization.DeserializeClusterMetadata(buffer)
+}
+
+// WithLeaderRouting sends the messages for a given partition straight to the node leading it,
+// per the cluster metadata refreshed every refresh interval, instead of through the node the
+// client is connected to. The connections to the other nodes log in with the credentials of
+// the client. Messages are sent through the client connection when the leader is unknown,
+// e.g. for balanced partitioning or against servers not reporting the cluster metadata.
+func WithLeaderRouting(refresh time.Duration) Option {
+	return func(opts *Options) {
+		opts.LeaderRouting = refresh
+	}
+}
+
+// leaderRouter keeps the cluster metadata and a connection to each partition leader.
+type leaderRouter struct {
+	refresh time.Duration
+
+	mtx      sync.Mutex
+	metadata *iggcon.ClusterMetadata
+	fetched  time.Time
+	nodes    map[uint32]*MessengerTcpClient
+}
+
+func newLeaderRouter(refresh time.Duration) *leaderRouter {
+	if refresh <= 0 {
+		return nil
+	}
+	return &leaderRouter{refresh: refresh, nodes: map[uint32]*MessengerTcpClient{}}
+}
+
+// leader returns the client connected to the leader of the partition the messages are sent
+// to, or nil to send them through tms.
+func (tms *MessengerTcpClient) leader(streamId, topicId iggcon.Identifier, partitioning iggcon.Partitioning) *MessengerTcpClient {
+	r := tms.router
+	if r == nil || partitioning.Kind != iggcon.PartitionIdKind || len(partitioning.Value) != 4 {
+		return nil
+	}
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if time.Since(r.fetched) >= r.refresh {
+		r.fetched = time.Now()
+		metadata, err := tms.GetClusterMetadata()
+		if err != nil {
+			log.Printf("[WARN] failed to refresh the cluster metadata: %v", err)
+		} else {
+			r.metadata = metadata
+		}
+	}
+	if r.metadata == nil {
+		return nil
+	}
+	node := r.metadata.Leader(streamId, topicId, binary.LittleEndian.Uint32(partitioning.Value))
+	if node == nil || node.Address == tms.address {
+		return nil
+	}
+	if client, ok := r.nodes[node.Id]; ok && client.address == node.Address {
+		return client
+	}
+
+	client, err := tms.connectNode(node.Address)
+	if err != nil {
+		log.Printf("[WARN] failed to connect to the leader %s at %s: %v", node.Name, node.Address, err)
+		return nil
+	}
+	r.nodes[node.Id] = client
+	return client
+}
+
+// connectNode connects to another node of the cluster with the options and the login of tms.
+func (tms *MessengerTcpClient) connectNode(address string) (*MessengerTcpClient, error) {
+	opts := tms.connectOptions
+	opts.ServerAddress = address
+	opts.ServerAddresses = nil
+	opts.LeaderRouting = 0
+	// the connection is dropped by the first failed send instead
+	opts.HeartbeatInterval = 0
+	opts.DetectDialect = false
+	opts.Dialect = tms.Dialect()
+	client, err := NewMessengerTcpClient(func(o *Options) { *o = opts })
+	if err != nil {
+		return nil, err
+	}
+	client.MessageCompression = tms.MessageCompression
+
+	tms.mtx.Lock()
+	login := tms.login
+	tms.mtx.Unlock()
+	if login == nil {
+		return client, nil
+	}
+	client.mtx.Lock()
+	defer client.mtx.Unlock()
+	client.login = login
+	if _, err = client.exchangeMessage(login.command, login.message); err != nil {
+		_ = client.conn.Close()
+		return nil, err
+	}
+	return client, nil
+}
+
+// leaderFailed drops the connection to a leader after a transport error, and refreshes the
+// cluster metadata with the next send, as the leadership may have moved.
+func (tms *MessengerTcpClient) leaderFailed(client *MessengerTcpClient, err error) {
+	var messengerErr *ierror.MessengerError
+	if errors.As(err, &messengerErr) {
+		return
+	}
+	r := tms.router
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	for id, node := range r.nodes {
+		if node == client {
+			delete(r.nodes, id)
+		}
+	}
+	r.fetched = time.Time{}
+	client.mtx.Lock()
+	_ = client.conn.Close()
+	client.mtx.Unlock()
+}
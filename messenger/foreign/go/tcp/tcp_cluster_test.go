@@ -0,0 +1,121 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tcp
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+)
+
+func appendString8(b []byte, s string) []byte {
+	return append(append(b, byte(len(s))), s...)
+}
+
+// clusterMetadata encodes a cluster of the nodes at the given addresses, with ids from 1, the
+// partition n of stream 1 and topic 1 being led by the node n.
+func clusterMetadata(addresses ...string) []byte {
+	b := appendString8(nil, "test")
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(addresses)))
+	for i, address := range addresses {
+		b = binary.LittleEndian.AppendUint32(b, uint32(i+1))
+		b = append(b, byte(iggcon.ClusterNodeFollower), byte(iggcon.ClusterNodeHealthy))
+		b = appendString8(b, address)
+		b = appendString8(b, address)
+	}
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(addresses)))
+	for i := range addresses {
+		b = binary.LittleEndian.AppendUint32(b, 1)
+		b = appendString8(b, "orders")
+		b = binary.LittleEndian.AppendUint32(b, 1)
+		b = appendString8(b, "created")
+		b = binary.LittleEndian.AppendUint32(b, uint32(i+1))
+		b = binary.LittleEndian.AppendUint32(b, uint32(i+1))
+	}
+	return b
+}
+
+func TestLeaderRouting_SendsToPartitionLeader(t *testing.T) {
+	var mtx sync.Mutex
+	commands := map[string]*[]iggcon.CommandCode{"a:1": {}, "b:1": {}}
+	respond := func(command iggcon.CommandCode) []byte {
+		if command == iggcon.GetClusterMetadataCode {
+			return clusterMetadata("a:1", "b:1")
+		}
+		return nil
+	}
+	dial := func(_ context.Context, _, address string) (net.Conn, error) {
+		server, client := net.Pipe()
+		go serveRequests(server, &mtx, commands[address], respond)
+		return client, nil
+	}
+	dialect := iggcon.NewDialect("clustered", iggcon.MessageHeaderSize)
+	dialect.ClusterMetadata = true
+
+	cli, err := NewMessengerTcpClient(
+		WithServerAddress("a:1"),
+		WithDialFunc(dial),
+		WithDialect(dialect),
+		WithLeaderRouting(time.Minute),
+		func(opts *Options) { opts.HeartbeatInterval = 0 },
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = cli.LoginUser("user", "secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	metadata, err := cli.GetClusterMetadata()
+	if err != nil {
+		t.Fatal(err)
+	}
+	topicId, _ := iggcon.NewIdentifier("created")
+	streamId, _ := iggcon.NewIdentifier(uint32(1))
+	if leader := metadata.Leader(streamId, topicId, 2); leader == nil || leader.Address != "b:1" {
+		t.Fatalf("unexpected leader %+v of %+v", leader, metadata)
+	}
+
+	message, err := iggcon.NewMessengerMessage([]byte("a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, partition := range []uint32{1, 2, 2} {
+		if err = cli.SendMessages(streamId, topicId, iggcon.PartitionId(partition), []iggcon.MessengerMessage{message}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mtx.Lock()
+	defer mtx.Unlock()
+	expected := map[string][]iggcon.CommandCode{
+		"a:1": {iggcon.LoginUserCode, iggcon.GetClusterMetadataCode, iggcon.GetClusterMetadataCode, iggcon.SendMessagesCode},
+		"b:1": {iggcon.LoginUserCode, iggcon.SendMessagesCode, iggcon.SendMessagesCode},
+	}
+	for address, commands := range commands {
+		if !reflect.DeepEqual(*commands, expected[address]) {
+			t.Errorf("%s: expected the commands %v, got %v", address, expected[address], *commands)
+		}
+	}
+}
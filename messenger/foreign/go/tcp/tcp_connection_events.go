@@ -0,0 +1,134 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tcp
+
+import (
+	"sync"
+	"time"
+)
+
+// ConnectionEventKind is the kind of a ConnectionEvent.
+type ConnectionEventKind uint8
+
+const (
+	// ConnectionEventConnected is emitted once a connection is established, including after
+	// reconnecting.
+	ConnectionEventConnected ConnectionEventKind = iota + 1
+	// ConnectionEventDisconnected is emitted when the connection is broken by a transport error,
+	// or when the client is closed.
+	ConnectionEventDisconnected
+	// ConnectionEventReconnecting is emitted before connecting again after the connection broke.
+	ConnectionEventReconnecting
+	// ConnectionEventAuthenticated is emitted once the client logged in, including when the login
+	// is replayed after reconnecting.
+	ConnectionEventAuthenticated
+)
+
+func (k ConnectionEventKind) String() string {
+	switch k {
+	case ConnectionEventConnected:
+		return "connected"
+	case ConnectionEventDisconnected:
+		return "disconnected"
+	case ConnectionEventReconnecting:
+		return "reconnecting"
+	case ConnectionEventAuthenticated:
+		return "authenticated"
+	}
+	return "unknown"
+}
+
+// ConnectionEvent is a change of the state of the client connection.
+type ConnectionEvent struct {
+	Kind ConnectionEventKind
+	// Address is the address of the server the event relates to.
+	Address string
+	// Err is the transport error which broke the connection, nil for the other events.
+	Err  error
+	Time time.Time
+}
+
+// connectionEventsBuffer is the number of events buffered for every subscriber.
+const connectionEventsBuffer = 16
+
+// connectionEvents fans the connection events out to the subscribers. It has its own lock so
+// subscribing does not wait for the request in flight.
+type connectionEvents struct {
+	mtx         sync.Mutex
+	subscribers []chan ConnectionEvent
+	// current is the last connected, disconnected or reconnecting event, and authenticated the
+	// login since, replayed to the new subscribers.
+	current       *ConnectionEvent
+	authenticated *ConnectionEvent
+	closed        bool
+}
+
+// ConnectionEvents returns a channel receiving the connection events, starting with the current
+// state of the connection, e.g. to drive readiness probes or circuit breakers. The events are
+// dropped while the channel is full, so the receiver should keep up with them. The channel is
+// closed once the client is closed.
+func (tms *MessengerTcpClient) ConnectionEvents() <-chan ConnectionEvent {
+	e := &tms.events
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	events := make(chan ConnectionEvent, connectionEventsBuffer)
+	for _, event := range []*ConnectionEvent{e.current, e.authenticated} {
+		if event != nil {
+			events <- *event
+		}
+	}
+	if e.closed {
+		close(events)
+	} else {
+		e.subscribers = append(e.subscribers, events)
+	}
+	return events
+}
+
+// emit sends the event to the subscribers.
+func (e *connectionEvents) emit(kind ConnectionEventKind, address string, err error) {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	if e.closed {
+		return
+	}
+	event := ConnectionEvent{Kind: kind, Address: address, Err: err, Time: time.Now()}
+	if kind == ConnectionEventAuthenticated {
+		e.authenticated = &event
+	} else {
+		e.current, e.authenticated = &event, nil
+	}
+	for _, subscriber := range e.subscribers {
+		select {
+		case subscriber <- event:
+		default:
+		}
+	}
+}
+
+// close emits the disconnected event of the closed client and closes the channels.
+func (e *connectionEvents) close(address string) {
+	e.emit(ConnectionEventDisconnected, address, nil)
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	e.closed = true
+	for _, subscriber := range e.subscribers {
+		close(subscriber)
+	}
+	e.subscribers = nil
+}
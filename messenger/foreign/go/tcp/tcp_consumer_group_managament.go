@@ -48,8 +48,8 @@ func (tms *MessengerTcpClient) GetConsumerGroup(streamId, topicId, groupId iggco
 }
 
 func (tms *MessengerTcpClient) CreateConsumerGroup(streamId iggcon.Identifier, topicId iggcon.Identifier, name string, groupId *uint32) (*iggcon.ConsumerGroupDetails, error) {
-	if MaxStringLength < len(name) {
-		return nil, ierror.TextTooLong("consumer_group_name")
+	if err := tms.connectOptions.NameValidator.Validate(iggcon.ConsumerGroupName, name); err != nil {
+		return nil, err
 	}
 	message := binaryserialization.CreateGroup(iggcon.CreateConsumerGroupRequest{
 		StreamId:        streamId,
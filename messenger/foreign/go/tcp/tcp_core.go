@@ -20,21 +20,46 @@ package tcp
 import (
 	"context"
 	"encoding/binary"
+	"errors"
 	"log"
+	"maps"
 	"net"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	binaryserialization "github.com/apache/messenger/foreign/go/binary_serialization"
+	"github.com/apache/messenger/foreign/go/capture"
 	iggcon "github.com/apache/messenger/foreign/go/contracts"
 	ierror "github.com/apache/messenger/foreign/go/errors"
+	"github.com/apache/messenger/foreign/go/ratelimit"
 )
 
 type Option func(config *Options)
 
+// ErrClosed is returned by the requests sent through a closed client.
+var ErrClosed = errors.New("tcp: client closed")
+
 type Options struct {
 	Ctx               context.Context
 	ServerAddress     string
+	ServerAddresses   []string
+	EndpointSelector  EndpointSelector
 	HeartbeatInterval time.Duration
+	RateLimiter       *ratelimit.Limiter
+	Dialect           *iggcon.Dialect
+	DetectDialect     bool
+	Labels            map[string]string
+	Dial              DialFunc
+	Resolve           ResolveFunc
+	Clock             iggcon.Clock
+	ProtocolObserver  ProtocolObserver
+	RequestObserver   RequestObserver
+	FrameCapture      *capture.Writer
+	Timeouts          Timeouts
+	LeaderRouting     time.Duration
+	NameValidator     iggcon.NameValidator
+	CircuitBreakers   map[CommandClass]CircuitBreakerConfig
 }
 
 func GetDefaultOptions() Options {
@@ -42,12 +67,36 @@ func GetDefaultOptions() Options {
 		Ctx:               context.Background(),
 		ServerAddress:     "127.0.0.1:8090",
 		HeartbeatInterval: time.Second * 5,
+		Dialect:           iggcon.MessengerDialect,
+		Timeouts:          DefaultTimeouts(),
 	}
 }
 
 type MessengerTcpClient struct {
-	conn               *net.TCPConn
+	conn               net.Conn
 	mtx                sync.Mutex
+	closed             bool
+	cancel             context.CancelFunc
+	endpoints          *endpointPool
+	connectOptions     Options
+	address            string
+	broken             bool
+	login              *sessionLogin
+	router             *leaderRouter
+	ctx                context.Context
+	rateLimiter        *ratelimit.Limiter
+	dialect            atomic.Pointer[iggcon.Dialect]
+	detectDialect      bool
+	labelsMtx          sync.RWMutex
+	labels             map[string]string
+	clock              iggcon.Clock
+	protocol           *protocolRecorder
+	observer           RequestObserver
+	connectionState    ConnectionState
+	events             connectionEvents
+	breakers           map[CommandClass]*circuitBreaker
+	capture            *capture.Writer
+	timeouts           Timeouts
 	MessageCompression iggcon.MessengerMessageCompression
 }
 
@@ -58,6 +107,75 @@ func WithServerAddress(address string) Option {
 	}
 }
 
+// WithRateLimiter sets the limiter shaping the throughput of SendMessages and PollMessages.
+// The same limiter can be shared between several clients to enforce a common quota.
+func WithRateLimiter(limiter *ratelimit.Limiter) Option {
+	return func(opts *Options) {
+		opts.RateLimiter = limiter
+	}
+}
+
+// WithRateLimit limits the throughput of SendMessages and PollMessages in messages and bytes per second.
+func WithRateLimit(config ratelimit.Config) Option {
+	return WithRateLimiter(ratelimit.NewLimiter(config))
+}
+
+// WithDialect sets the protocol dialect spoken by the server, iggcon.MessengerDialect by default.
+func WithDialect(dialect *iggcon.Dialect) Option {
+	return func(opts *Options) {
+		opts.Dialect = dialect
+	}
+}
+
+// WithDialectDetection detects the protocol dialect of the server right after logging in,
+// so the same client can talk to both Messenger and Iggy-era servers. Detection relies on
+// the server stats, hence requires the permission to read the server info.
+func WithDialectDetection() Option {
+	return func(opts *Options) {
+		opts.DetectDialect = true
+	}
+}
+
+// WithLabels attaches labels (service, version, team, environment...) to the client once logged in,
+// so operators can attribute traffic and lag to the owning teams. They are reported by GetClients.
+// Only the servers of a dialect with ClientLabels, such as iggcon.MessengerExtendedDialect, store them.
+func WithLabels(labels map[string]string) Option {
+	return func(opts *Options) {
+		opts.Labels = labels
+	}
+}
+
+// WithDialFunc sets the function opening the connection to the server, instead of net.Dialer.
+func WithDialFunc(dial DialFunc) Option {
+	return func(opts *Options) {
+		opts.Dial = dial
+	}
+}
+
+// WithResolver sets the function resolving the server address into the addresses to dial,
+// e.g. LookupSRV for SRV-record discovery. By default the address is dialed as is.
+func WithResolver(resolve ResolveFunc) Option {
+	return func(opts *Options) {
+		opts.Resolve = resolve
+	}
+}
+
+// WithClock stamps the origin timestamp of the sent messages with the given clock, unless it was set
+// with iggcon.WithTimestamp. Without a clock, the origin timestamp is the time the message was created.
+func WithClock(clock iggcon.Clock) Option {
+	return func(opts *Options) {
+		opts.Clock = clock
+	}
+}
+
+// WithNameValidator sets the validator of the stream, topic and consumer group names, which are
+// checked before being sent. iggcon.DefaultNameValidator is used by default.
+func WithNameValidator(validator iggcon.NameValidator) Option {
+	return func(opts *Options) {
+		opts.NameValidator = validator
+	}
+}
+
 // WithContext sets context
 func WithContext(ctx context.Context) Option {
 	return func(opts *Options) {
@@ -72,22 +190,49 @@ func NewMessengerTcpClient(options ...Option) (*MessengerTcpClient, error) {
 			opt(&opts)
 		}
 	}
-	addr, err := net.ResolveTCPAddr("tcp", opts.ServerAddress)
-	if err != nil {
+	if err := validateLabels(opts.Labels); err != nil {
 		return nil, err
 	}
-	ctx := opts.Ctx
-	var d = net.Dialer{
-		KeepAlive: -1,
+	ctx, cancel := context.WithCancel(opts.Ctx)
+	var endpoints *endpointPool
+	var conn net.Conn
+	var address string
+	var err error
+	if len(opts.ServerAddresses) > 0 {
+		endpoints = newEndpointPool(opts.ServerAddresses, opts.EndpointSelector)
+		conn, address, err = endpoints.connect(ctx, opts)
+	} else {
+		address = opts.ServerAddress
+		conn, err = connect(ctx, opts)
 	}
-	conn, err := d.DialContext(ctx, "tcp", addr.String())
 	if err != nil {
+		cancel()
 		return nil, err
 	}
 
 	client := &MessengerTcpClient{
-		conn: conn.(*net.TCPConn),
+		conn:           conn,
+		cancel:         cancel,
+		endpoints:      endpoints,
+		connectOptions: opts,
+		address:        address,
+		router:         newLeaderRouter(opts.LeaderRouting),
+		ctx:            ctx,
+		rateLimiter:    opts.RateLimiter,
+		detectDialect:  opts.DetectDialect,
+		labels:         maps.Clone(opts.Labels),
+		clock:          opts.Clock,
+		protocol:       newProtocolRecorder(opts.ProtocolObserver),
+		observer:       opts.requestObserver(),
+		capture:        opts.FrameCapture,
+		timeouts:       opts.Timeouts,
+		breakers:       newCircuitBreakers(opts.CircuitBreakers),
+	}
+	client.setConnectionState(ConnectionConnected, nil)
+	if opts.Dialect == nil {
+		opts.Dialect = iggcon.MessengerDialect
 	}
+	client.dialect.Store(opts.Dialect)
 
 	heartbeatInterval := opts.HeartbeatInterval
 	if heartbeatInterval > 0 {
@@ -99,7 +244,7 @@ func NewMessengerTcpClient(options ...Option) (*MessengerTcpClient, error) {
 				case <-ctx.Done():
 					return
 				case <-ticker.C:
-					if err = client.Ping(); err != nil {
+					if err := client.Ping(); err != nil && !errors.Is(err, ErrClosed) {
 						log.Printf("[WARN] heartbeat failed: %v", err)
 					}
 				}
@@ -110,6 +255,37 @@ func NewMessengerTcpClient(options ...Option) (*MessengerTcpClient, error) {
 	return client, nil
 }
 
+// Close stops the heartbeat and closes the connections to the server and to the partition
+// leaders. The requests sent afterwards fail with ErrClosed. Closing does not log out, the
+// server ending the session along with the connection.
+func (tms *MessengerTcpClient) Close() error {
+	tms.mtx.Lock()
+	if tms.closed {
+		tms.mtx.Unlock()
+		return nil
+	}
+	tms.closed = true
+	tms.cancel()
+	err := tms.conn.Close()
+	tms.setConnectionState(ConnectionClosed, nil)
+	tms.mtx.Unlock()
+
+	if r := tms.router; r != nil {
+		r.mtx.Lock()
+		nodes := r.nodes
+		r.nodes = map[uint32]*MessengerTcpClient{}
+		r.mtx.Unlock()
+		for _, node := range nodes {
+			_ = node.Close()
+		}
+	}
+	if errors.Is(err, net.ErrClosed) {
+		// already closed by a timeout or a failed request
+		return nil
+	}
+	return err
+}
+
 const (
 	InitialBytesLength   = 4
 	ExpectedResponseSize = 8
@@ -146,14 +322,155 @@ func (tms *MessengerTcpClient) write(payload []byte) (int, error) {
 }
 
 func (tms *MessengerTcpClient) sendAndFetchResponse(message []byte, command iggcon.CommandCode) ([]byte, error) {
+	return tms.sendAndFetchResponseWithin(message, command, 0)
+}
+
+// sendAndFetchResponseWithin sends a message the server may hold for up to wait before answering,
+// which extends the timeout of the command.
+func (tms *MessengerTcpClient) sendAndFetchResponseWithin(message []byte, command iggcon.CommandCode, wait time.Duration) ([]byte, error) {
+	tms.mtx.Lock()
+	defer tms.mtx.Unlock()
+
+	response, err := tms.exchange(command, len(message), wait, func() error {
+		return tms.writeMessage(command, message)
+	})
+	return response, authorizationError(command, message, err)
+}
+
+// sendAndFetchResponseContext sends a message like sendAndFetchResponseWithin, interrupting the
+// exchange when ctx is done. The response of an interrupted request would be read as the response
+// of the next one, so the connection is torn down and the next request reconnects.
+func (tms *MessengerTcpClient) sendAndFetchResponseContext(ctx context.Context, message []byte, command iggcon.CommandCode, wait time.Duration) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	tms.mtx.Lock()
 	defer tms.mtx.Unlock()
 
+	var stop func() bool
+	response, err := tms.exchange(command, len(message), wait, func() error {
+		conn := tms.conn
+		stop = context.AfterFunc(ctx, func() {
+			_ = conn.SetDeadline(time.Now())
+		})
+		return tms.writeMessage(command, message)
+	})
+	if stop != nil && !stop() {
+		// the deadline may be set, now or later, while the connection is reused
+		_ = tms.conn.Close()
+		tms.broken = true
+		if err != nil {
+			return nil, ctx.Err()
+		}
+	}
+	return response, authorizationError(command, message, err)
+}
+
+// exchangeMessage sends a message and reads its response. Must hold tms.mtx.
+func (tms *MessengerTcpClient) exchangeMessage(command iggcon.CommandCode, message []byte) ([]byte, error) {
+	return tms.exchange(command, len(message), 0, func() error {
+		return tms.writeMessage(command, message)
+	})
+}
+
+// writeMessage writes a message framed as a request of the command. Must hold tms.mtx.
+func (tms *MessengerTcpClient) writeMessage(command iggcon.CommandCode, message []byte) error {
 	payload := createPayload(message, command)
-	if _, err := tms.write(payload); err != nil {
+	_, err := tms.write(payload)
+	binaryserialization.PutBuffer(payload)
+	return err
+}
+
+// sendBuffersAndFetchResponse sends a message of the given size split into buffers with a single
+// vectored write, sparing the copy into a contiguous payload.
+func (tms *MessengerTcpClient) sendBuffersAndFetchResponse(buffers net.Buffers, size int, command iggcon.CommandCode) ([]byte, error) {
+	tms.mtx.Lock()
+	defer tms.mtx.Unlock()
+
+	response, err := tms.exchange(command, size, 0, func() error {
+		header := createPayload(nil, command)
+		defer binaryserialization.PutBuffer(header)
+		binary.LittleEndian.PutUint32(header[:4], uint32(size+4))
+		frame := append(net.Buffers{header}, buffers...)
+		_, err := frame.WriteTo(tms.conn)
+		return err
+	})
+	if err != nil && len(buffers) > 0 && len(buffers[0]) >= 4 {
+		// the message starts with the length of the metadata, followed by the identifiers
+		err = authorizationError(command, buffers[0][4:], err)
+	}
+	return response, err
+}
+
+// exchange writes a request of the given message size with write and reads its response, within
+// the timeout of the command extended by wait. Must hold tms.mtx.
+func (tms *MessengerTcpClient) exchange(command iggcon.CommandCode, size int, wait time.Duration, write func() error) (response []byte, err error) {
+	if tms.closed {
+		return nil, ErrClosed
+	}
+	if !tms.Dialect().Supports(command) {
+		return nil, ierror.CustomError("command_not_supported_by_server")
+	}
+	start := time.Now()
+	if breaker, ok := tms.breakers[commandClass(command)]; ok {
+		if err := breaker.allow(); err != nil {
+			return nil, err
+		}
+		defer func() {
+			breaker.record(time.Since(start)-wait, err)
+		}()
+	}
+	if tms.broken {
+		if err := tms.failover(); err != nil {
+			return nil, err
+		}
+	}
+	start = time.Now()
+	requestBytes := InitialBytesLength + 4 + size
+	tms.observer.OnRequestStart(RequestStart{Command: command, RequestBytes: requestBytes})
+	timeout, err := tms.setDeadline(command, start, wait)
+	if err != nil {
+		tms.recordRequest(command, start, requestBytes, nil, err)
+		tms.observeTransport(err)
+		tms.observeEndpoint(command, 0, err)
 		return nil, err
 	}
+	captured := tms.startCapture()
+	if err = write(); err == nil {
+		response, err = tms.fetchResponse()
+	}
+	tms.endCapture(captured, command, start)
+	err = tms.checkTimeout(command, timeout, err)
+	tms.recordRequest(command, start, requestBytes, response, err)
+	tms.observeTransport(err)
+	tms.observeEndpoint(command, time.Since(start), err)
+	return response, err
+}
 
+// recordRequest adds a request to the protocol statistics. Must hold tms.mtx.
+func (tms *MessengerTcpClient) recordRequest(command iggcon.CommandCode, start time.Time, requestBytes int, response []byte, err error) {
+	sample := ProtocolSample{
+		Command:      command,
+		Latency:      time.Since(start),
+		RequestBytes: requestBytes,
+		Err:          err,
+	}
+	var messengerErr *ierror.MessengerError
+	if err == nil || errors.As(err, &messengerErr) {
+		// the response header was read, and the payload too unless the server returned an error
+		sample.ResponseBytes = ExpectedResponseSize
+		if err == nil {
+			sample.ResponseBytes += len(response)
+		} else {
+			sample.ErrorCode = messengerErr.Code
+		}
+	}
+	tms.protocol.record(sample)
+	tms.observer.OnRequestEnd(sample)
+}
+
+// fetchResponse reads the response of the last command. Must hold tms.mtx.
+func (tms *MessengerTcpClient) fetchResponse() ([]byte, error) {
 	_, buffer, err := tms.read(ExpectedResponseSize)
 	if err != nil {
 		return nil, err
@@ -191,9 +508,27 @@ func (tms *MessengerTcpClient) sendAndFetchResponse(message []byte, command iggc
 	return buffer, nil
 }
 
+// Dialect returns the protocol dialect the client speaks.
+func (tms *MessengerTcpClient) Dialect() *iggcon.Dialect {
+	return tms.dialect.Load()
+}
+
+// negotiateDialect detects the dialect of the server once the session is authenticated.
+func (tms *MessengerTcpClient) negotiateDialect() {
+	if !tms.detectDialect {
+		return
+	}
+	stats, err := tms.GetStats()
+	if err != nil {
+		log.Printf("[WARN] protocol dialect detection failed, keeping %s: %v", tms.Dialect().Name, err)
+		return
+	}
+	tms.dialect.Store(iggcon.DetectDialect(stats))
+}
+
 func createPayload(message []byte, command iggcon.CommandCode) []byte {
 	messageLength := len(message) + 4
-	messageBytes := make([]byte, InitialBytesLength+messageLength)
+	messageBytes := binaryserialization.GetBuffer(InitialBytesLength + messageLength)
 	binary.LittleEndian.PutUint32(messageBytes[:4], uint32(messageLength))
 	binary.LittleEndian.PutUint32(messageBytes[4:8], uint32(command))
 	copy(messageBytes[8:], message)
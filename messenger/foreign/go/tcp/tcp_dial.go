@@ -0,0 +1,121 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+)
+
+// DialFunc opens a connection to the given address, with the signature of net.Dialer.DialContext.
+// A custom DialFunc can route the connection through a service mesh or a proxy, or return an
+// in-memory connection in tests.
+type DialFunc func(ctx context.Context, network, address string) (net.Conn, error)
+
+// ResolveFunc resolves the configured server address into the addresses to dial, tried in order
+// until one of them accepts the connection.
+type ResolveFunc func(ctx context.Context, address string) ([]string, error)
+
+func defaultDial(ctx context.Context, network, address string) (net.Conn, error) {
+	d := net.Dialer{
+		KeepAlive: -1,
+	}
+	return d.DialContext(ctx, network, address)
+}
+
+func defaultResolve(_ context.Context, address string) ([]string, error) {
+	return []string{address}, nil
+}
+
+// LookupSRV returns a ResolveFunc treating the server address as a domain name and dialing the
+// targets of its _service._proto SRV records, ordered by priority and randomized by weight.
+// A nil resolver uses net.DefaultResolver.
+func LookupSRV(resolver *net.Resolver, service, proto string) ResolveFunc {
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	return func(ctx context.Context, name string) ([]string, error) {
+		_, records, err := resolver.LookupSRV(ctx, service, proto, name)
+		if err != nil {
+			return nil, err
+		}
+		addresses := make([]string, len(records))
+		for i, record := range records {
+			addresses[i] = net.JoinHostPort(record.Target, strconv.Itoa(int(record.Port)))
+		}
+		return addresses, nil
+	}
+}
+
+// connect resolves the server address and dials the resolved addresses in order.
+func connect(ctx context.Context, opts Options) (net.Conn, error) {
+	return dialAddress(ctx, opts, opts.ServerAddress)
+}
+
+// dialAddress resolves the address and dials the resolved addresses in order.
+func dialAddress(ctx context.Context, opts Options, address string) (net.Conn, error) {
+	resolve, dial := opts.Resolve, opts.Dial
+	if resolve == nil {
+		resolve = defaultResolve
+	}
+	if dial == nil {
+		dial = defaultDial
+	}
+
+	addresses, err := withConnectTimeout(ctx, opts.Timeouts.Connect, func(ctx context.Context) ([]string, error) {
+		return resolve(ctx, address)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", address, err)
+	}
+	if len(addresses) == 0 {
+		return nil, fmt.Errorf("no address resolved for %s", address)
+	}
+
+	var errs []error
+	for i, resolved := range addresses {
+		if i > 0 {
+			opts.requestObserver().OnRetry(Retry{Address: resolved, Attempt: i + 1, Err: errs[i-1]})
+		}
+		conn, err := withConnectTimeout(ctx, opts.Timeouts.Connect, func(ctx context.Context) (net.Conn, error) {
+			return dial(ctx, "tcp", resolved)
+		})
+		if err == nil {
+			return conn, nil
+		}
+		errs = append(errs, err)
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, errors.Join(errs...)
+}
+
+// withConnectTimeout calls f with a context bounded by the connect timeout, if any.
+func withConnectTimeout[T any](ctx context.Context, timeout time.Duration, f func(ctx context.Context) (T, error)) (T, error) {
+	if timeout <= 0 {
+		return f(ctx)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return f(ctx)
+}
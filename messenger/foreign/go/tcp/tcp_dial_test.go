@@ -0,0 +1,70 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tcp
+
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+)
+
+func TestConnect_DialsResolvedAddressesInOrder(t *testing.T) {
+	var dialed []string
+	server, client := net.Pipe()
+	defer server.Close()
+
+	opts := GetDefaultOptions()
+	opts.ServerAddress = "messenger.internal"
+	opts.Resolve = func(_ context.Context, address string) ([]string, error) {
+		return []string{"10.0.0.1:8090", "10.0.0.2:8090"}, nil
+	}
+	opts.Dial = func(_ context.Context, network, address string) (net.Conn, error) {
+		dialed = append(dialed, address)
+		if address == "10.0.0.1:8090" {
+			return nil, errors.New("connection refused")
+		}
+		return client, nil
+	}
+
+	conn, err := connect(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conn != client {
+		t.Error("expected the connection returned by the dial function")
+	}
+	if !reflect.DeepEqual(dialed, []string{"10.0.0.1:8090", "10.0.0.2:8090"}) {
+		t.Errorf("unexpected dialed addresses %v", dialed)
+	}
+}
+
+func TestConnect_FailsWhenEveryAddressFails(t *testing.T) {
+	opts := GetDefaultOptions()
+	opts.Resolve = func(context.Context, string) ([]string, error) {
+		return []string{"a:1", "b:1"}, nil
+	}
+	opts.Dial = func(_ context.Context, _, address string) (net.Conn, error) {
+		return nil, errors.New(address + " unreachable")
+	}
+
+	if _, err := connect(context.Background(), opts); err == nil || err.Error() != "a:1 unreachable\nb:1 unreachable" {
+		t.Errorf("expected the errors of every address, got %v", err)
+	}
+}
@@ -0,0 +1,291 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	binaryserialization "github.com/apache/messenger/foreign/go/binary_serialization"
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	ierror "github.com/apache/messenger/foreign/go/errors"
+)
+
+// EndpointHealth is the health of one of the broker addresses the client fails over between.
+type EndpointHealth struct {
+	Address string
+	// Priority is the position of the address in the configured list, 0 being the preferred one.
+	Priority int
+	// Score between 0 and 1 is halved by every failure to connect to or talk with the endpoint,
+	// and recovers half of the way to 1 by every success. Endpoints start with a score of 1.
+	Score float64
+	// Latency is the moving average of the connect and ping latencies, 0 until measured.
+	Latency time.Duration
+	// Failures counts the failures since the last success.
+	Failures    int
+	LastFailure time.Time
+}
+
+// Healthy reports whether the endpoint succeeded more than it failed lately, i.e. has a score
+// above 0.5. Unhealthy endpoints are only tried once the healthy ones failed.
+func (h EndpointHealth) Healthy() bool {
+	return h.Score > 0.5
+}
+
+// EndpointSelector orders the endpoints to try when connecting, the first one being dialed first.
+// It is called with a copy of the endpoints it can reorder in place, in the configured order.
+type EndpointSelector func(endpoints []EndpointHealth) []EndpointHealth
+
+// Priority returns an EndpointSelector trying the healthy endpoints in the configured order,
+// the following addresses only serving as fallbacks of the first one.
+func Priority() EndpointSelector {
+	return func(endpoints []EndpointHealth) []EndpointHealth {
+		sort.SliceStable(endpoints, func(i, j int) bool {
+			return endpoints[i].Healthy() && !endpoints[j].Healthy()
+		})
+		return endpoints
+	}
+}
+
+// RoundRobin returns an EndpointSelector starting every connection at the healthy endpoint
+// following the one the previous connection started at, spreading the clients over the brokers.
+func RoundRobin() EndpointSelector {
+	var next atomic.Uint64
+	return func(endpoints []EndpointHealth) []EndpointHealth {
+		if len(endpoints) == 0 {
+			return endpoints
+		}
+		start := int(next.Add(1)-1) % len(endpoints)
+		rotated := make([]EndpointHealth, 0, len(endpoints))
+		rotated = append(append(rotated, endpoints[start:]...), endpoints[:start]...)
+		return Priority()(rotated)
+	}
+}
+
+// LowestLatency returns an EndpointSelector trying the healthy endpoints by increasing latency,
+// the endpoints not measured yet coming last in the configured order.
+func LowestLatency() EndpointSelector {
+	return func(endpoints []EndpointHealth) []EndpointHealth {
+		sort.SliceStable(endpoints, func(i, j int) bool {
+			a, b := endpoints[i], endpoints[j]
+			if a.Healthy() != b.Healthy() {
+				return a.Healthy()
+			}
+			if (a.Latency == 0) != (b.Latency == 0) {
+				return b.Latency == 0
+			}
+			return a.Latency < b.Latency
+		})
+		return endpoints
+	}
+}
+
+// WithServerAddresses sets several broker addresses to fail over between, instead of the server
+// address. The client connects to the first endpoint of the selector, Priority by default,
+// accepting the connection. After a transport error, the next request reconnects the same way
+// and logs in again with the credentials of the last login. The failed request is not retried,
+// as the server may have processed it.
+func WithServerAddresses(addresses ...string) Option {
+	return func(opts *Options) {
+		opts.ServerAddresses = addresses
+	}
+}
+
+// WithEndpointSelector sets the strategy ordering the server addresses to try, Priority by default.
+func WithEndpointSelector(selector EndpointSelector) Option {
+	return func(opts *Options) {
+		opts.EndpointSelector = selector
+	}
+}
+
+// Endpoints returns the health of the server addresses, or nil when the client does not fail over.
+func (tms *MessengerTcpClient) Endpoints() []EndpointHealth {
+	if tms.endpoints == nil {
+		return nil
+	}
+	return tms.endpoints.snapshot()
+}
+
+// endpointPool keeps the health of the server addresses. It is safe for concurrent use.
+type endpointPool struct {
+	selector EndpointSelector
+
+	mtx       sync.Mutex
+	endpoints []EndpointHealth
+}
+
+func newEndpointPool(addresses []string, selector EndpointSelector) *endpointPool {
+	if selector == nil {
+		selector = Priority()
+	}
+	endpoints := make([]EndpointHealth, len(addresses))
+	for i, address := range addresses {
+		endpoints[i] = EndpointHealth{Address: address, Priority: i, Score: 1}
+	}
+	return &endpointPool{selector: selector, endpoints: endpoints}
+}
+
+func (p *endpointPool) snapshot() []EndpointHealth {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	return append([]EndpointHealth(nil), p.endpoints...)
+}
+
+func (p *endpointPool) update(address string, f func(endpoint *EndpointHealth)) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	for i := range p.endpoints {
+		if p.endpoints[i].Address == address {
+			f(&p.endpoints[i])
+		}
+	}
+}
+
+func (p *endpointPool) success(address string, latency time.Duration) {
+	p.update(address, func(endpoint *EndpointHealth) {
+		endpoint.Score += (1 - endpoint.Score) / 2
+		endpoint.Failures = 0
+		if endpoint.Latency == 0 {
+			endpoint.Latency = latency
+		} else {
+			endpoint.Latency = (4*endpoint.Latency + latency) / 5
+		}
+	})
+}
+
+func (p *endpointPool) failure(address string) {
+	p.update(address, func(endpoint *EndpointHealth) {
+		endpoint.Score /= 2
+		endpoint.Failures++
+		endpoint.LastFailure = time.Now()
+	})
+}
+
+// connect dials the endpoints in the order of the selector, returning the first connection
+// established along with its address.
+func (p *endpointPool) connect(ctx context.Context, opts Options) (net.Conn, string, error) {
+	var errs []error
+	for i, endpoint := range p.selector(p.snapshot()) {
+		if i > 0 {
+			opts.requestObserver().OnRetry(Retry{Address: endpoint.Address, Attempt: i + 1, Err: errs[i-1]})
+		}
+		start := time.Now()
+		conn, err := dialAddress(ctx, opts, endpoint.Address)
+		if err == nil {
+			p.success(endpoint.Address, time.Since(start))
+			return conn, endpoint.Address, nil
+		}
+		p.failure(endpoint.Address)
+		errs = append(errs, err)
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	if len(errs) == 0 {
+		return nil, "", errors.New("no server address configured")
+	}
+	return nil, "", errors.Join(errs...)
+}
+
+// sessionLogin is the last successful login request, replayed after failing over.
+type sessionLogin struct {
+	command iggcon.CommandCode
+	message []byte
+}
+
+// rememberLogin records the login to replay after reconnecting or to log in to the partition
+// leaders, or forgets it when command is 0.
+func (tms *MessengerTcpClient) rememberLogin(command iggcon.CommandCode, message []byte) {
+	tms.mtx.Lock()
+	defer tms.mtx.Unlock()
+	if command == 0 {
+		tms.login = nil
+		return
+	}
+	tms.login = &sessionLogin{command: command, message: message}
+	tms.events.emit(ConnectionEventAuthenticated, tms.address, nil)
+}
+
+// observeEndpoint updates the health of the current endpoint after a request, marking the
+// connection broken on transport errors. Must hold tms.mtx.
+func (tms *MessengerTcpClient) observeEndpoint(command iggcon.CommandCode, latency time.Duration, err error) {
+	if tms.endpoints == nil {
+		return
+	}
+	var messengerErr *ierror.MessengerError
+	switch {
+	case err == nil:
+		if command == iggcon.PingCode {
+			tms.endpoints.success(tms.address, latency)
+		}
+	case !errors.As(err, &messengerErr):
+		log.Printf("[WARN] connection to %s failed, failing over: %v", tms.address, err)
+		tms.endpoints.failure(tms.address)
+		_ = tms.conn.Close()
+		tms.broken = true
+	}
+}
+
+// failover connects to the next endpoint after a transport error, or back to the server address
+// without endpoints, and restores the session of the previous connection on it. Must hold tms.mtx.
+func (tms *MessengerTcpClient) failover() error {
+	tms.events.emit(ConnectionEventReconnecting, tms.address, nil)
+	var conn net.Conn
+	var err error
+	address := tms.address
+	if tms.endpoints != nil {
+		conn, address, err = tms.endpoints.connect(tms.ctx, tms.connectOptions)
+	} else {
+		conn, err = dialAddress(tms.ctx, tms.connectOptions, address)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to fail over: %w", err)
+	}
+	tms.conn, tms.address, tms.broken = conn, address, false
+	tms.setConnectionState(ConnectionConnected, nil)
+	if tms.login == nil {
+		return nil
+	}
+	if _, err = tms.exchangeMessage(tms.login.command, tms.login.message); err != nil {
+		if !tms.broken {
+			if tms.endpoints != nil {
+				tms.endpoints.failure(address)
+			}
+			_ = tms.conn.Close()
+			tms.broken = true
+			tms.setConnectionState(ConnectionBroken, err)
+		}
+		return fmt.Errorf("failed to log in to %s: %w", address, err)
+	}
+	tms.events.emit(ConnectionEventAuthenticated, address, nil)
+	labels := tms.Labels()
+	if len(labels) > 0 && tms.Dialect().ClientLabels {
+		message := binaryserialization.SerializeUpdateClientLabels(iggcon.UpdateClientLabelsRequest{Labels: labels})
+		if _, err = tms.exchangeMessage(iggcon.UpdateClientLabelsCode, message); err != nil {
+			log.Printf("[WARN] failed to attach the client labels to %s: %v", address, err)
+		}
+	}
+	return nil
+}
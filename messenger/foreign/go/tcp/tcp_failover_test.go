@@ -0,0 +1,159 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tcp
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+)
+
+func addresses(endpoints []EndpointHealth) []string {
+	result := make([]string, len(endpoints))
+	for i, endpoint := range endpoints {
+		result[i] = endpoint.Address
+	}
+	return result
+}
+
+func TestEndpointSelectors(t *testing.T) {
+	endpoints := func() []EndpointHealth {
+		return []EndpointHealth{
+			{Address: "a", Priority: 0, Score: 0.25, Latency: time.Millisecond},
+			{Address: "b", Priority: 1, Score: 1, Latency: 30 * time.Millisecond},
+			{Address: "c", Priority: 2, Score: 1},
+			{Address: "d", Priority: 3, Score: 0.75, Latency: 10 * time.Millisecond},
+		}
+	}
+
+	if order := addresses(Priority()(endpoints())); !reflect.DeepEqual(order, []string{"b", "c", "d", "a"}) {
+		t.Errorf("priority: unexpected order %v", order)
+	}
+	if order := addresses(LowestLatency()(endpoints())); !reflect.DeepEqual(order, []string{"d", "b", "c", "a"}) {
+		t.Errorf("lowest latency: unexpected order %v", order)
+	}
+	roundRobin := RoundRobin()
+	for _, expected := range [][]string{{"b", "c", "d", "a"}, {"b", "c", "d", "a"}, {"c", "d", "b", "a"}, {"d", "b", "c", "a"}, {"b", "c", "d", "a"}} {
+		if order := addresses(roundRobin(endpoints())); !reflect.DeepEqual(order, expected) {
+			t.Errorf("round robin: expected %v, got %v", expected, order)
+		}
+	}
+}
+
+func TestEndpointPool_Health(t *testing.T) {
+	pool := newEndpointPool([]string{"a", "b"}, nil)
+	pool.failure("a")
+	if health := pool.snapshot()[0]; health.Healthy() || health.Failures != 1 {
+		t.Fatalf("expected a failure to make the endpoint unhealthy, got %+v", health)
+	}
+	pool.success("a", 10*time.Millisecond)
+	pool.success("a", 20*time.Millisecond)
+	if health := pool.snapshot()[0]; !health.Healthy() || health.Failures != 0 || health.Latency != 12*time.Millisecond {
+		t.Fatalf("expected successes to restore the endpoint, got %+v", health)
+	}
+}
+
+// serveRequests answers every request on the connection with the payload returned by respond,
+// or a user id to logins, recording the command codes.
+func serveRequests(conn net.Conn, mtx *sync.Mutex, commands *[]iggcon.CommandCode, respond func(command iggcon.CommandCode) []byte) {
+	defer conn.Close()
+	header := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(conn, header); err != nil {
+			return
+		}
+		length := binary.LittleEndian.Uint32(header[:4])
+		command := iggcon.CommandCode(binary.LittleEndian.Uint32(header[4:]))
+		if _, err := io.CopyN(io.Discard, conn, int64(length-4)); err != nil {
+			return
+		}
+		mtx.Lock()
+		*commands = append(*commands, command)
+		mtx.Unlock()
+
+		var payload []byte
+		if command == iggcon.LoginUserCode {
+			payload = binary.LittleEndian.AppendUint32(nil, 1)
+		} else if respond != nil {
+			payload = respond(command)
+		}
+		response := make([]byte, 8, 8+len(payload))
+		binary.LittleEndian.PutUint32(response[4:], uint32(len(payload)))
+		response = append(response, payload...)
+		if _, err := conn.Write(response); err != nil {
+			return
+		}
+	}
+}
+
+func TestFailover_ReconnectsAndLogsInAgain(t *testing.T) {
+	var mtx sync.Mutex
+	commands := map[string]*[]iggcon.CommandCode{"a:1": {}, "b:1": {}}
+	servers := map[string]net.Conn{}
+	dial := func(_ context.Context, _, address string) (net.Conn, error) {
+		mtx.Lock()
+		defer mtx.Unlock()
+		if servers[address] != nil {
+			return nil, errors.New(address + " refused")
+		}
+		server, client := net.Pipe()
+		servers[address] = server
+		go serveRequests(server, &mtx, commands[address], nil)
+		return client, nil
+	}
+
+	cli, err := NewMessengerTcpClient(
+		WithServerAddresses("a:1", "b:1"),
+		WithDialFunc(dial),
+		func(opts *Options) { opts.HeartbeatInterval = 0 },
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = cli.LoginUser("user", "secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	mtx.Lock()
+	_ = servers["a:1"].Close()
+	mtx.Unlock()
+	if err = cli.Ping(); err == nil {
+		t.Fatal("expected the request sent to the closed server to fail")
+	}
+	if err = cli.Ping(); err != nil {
+		t.Fatalf("expected the client to fail over, got %v", err)
+	}
+
+	mtx.Lock()
+	defer mtx.Unlock()
+	if failedOver := *commands["b:1"]; !reflect.DeepEqual(failedOver, []iggcon.CommandCode{iggcon.LoginUserCode, iggcon.PingCode}) {
+		t.Errorf("expected the login to be replayed before the ping, got %v", failedOver)
+	}
+	endpoints := cli.Endpoints()
+	if endpoints[0].Healthy() || !endpoints[1].Healthy() {
+		t.Errorf("unexpected endpoints health %+v", endpoints)
+	}
+}
@@ -18,6 +18,9 @@
 package tcp
 
 import (
+	"context"
+	"time"
+
 	binaryserialization "github.com/apache/messenger/foreign/go/binary_serialization"
 	iggcon "github.com/apache/messenger/foreign/go/contracts"
 	ierror "github.com/apache/messenger/foreign/go/errors"
@@ -29,17 +32,68 @@ func (tms *MessengerTcpClient) SendMessages(
 	partitioning iggcon.Partitioning,
 	messages []iggcon.MessengerMessage,
 ) error {
+	return tms.SendMessagesWithConfirmation(streamId, topicId, partitioning, messages, iggcon.ConfirmationDefault)
+}
+
+func (tms *MessengerTcpClient) SendMessagesWithConfirmation(
+	streamId iggcon.Identifier,
+	topicId iggcon.Identifier,
+	partitioning iggcon.Partitioning,
+	messages []iggcon.MessengerMessage,
+	confirmation iggcon.Confirmation,
+) error {
+	_, err := tms.SendMessagesWithResult(streamId, topicId, partitioning, messages, confirmation)
+	return err
+}
+
+func (tms *MessengerTcpClient) SendMessagesWithResult(
+	streamId iggcon.Identifier,
+	topicId iggcon.Identifier,
+	partitioning iggcon.Partitioning,
+	messages []iggcon.MessengerMessage,
+	confirmation iggcon.Confirmation,
+) (*iggcon.SendResult, error) {
 	if len(messages) == 0 {
-		return ierror.CustomError("messages_count_should_be_greater_than_zero")
+		return nil, ierror.CustomError("messages_count_should_be_greater_than_zero")
+	}
+	if leader := tms.leader(streamId, topicId, partitioning); leader != nil {
+		result, err := leader.SendMessagesWithResult(streamId, topicId, partitioning, messages, confirmation)
+		if err != nil {
+			tms.leaderFailed(leader, err)
+		}
+		return result, err
+	}
+	if tms.clock != nil {
+		for i := range messages {
+			messages[i].StampOrigin(tms.clock)
+		}
+	}
+	dialect := tms.Dialect()
+	if confirmation != iggcon.ConfirmationDefault && !dialect.Confirmation {
+		return nil, ierror.CustomError("confirmation_not_supported_by_server")
 	}
 	serializedRequest := binaryserialization.TcpSendMessagesRequest{
 		StreamId:     streamId,
 		TopicId:      topicId,
 		Partitioning: partitioning,
 		Messages:     messages,
+		Dialect:      dialect,
+		Confirmation: confirmation,
 	}
-	_, err := tms.sendAndFetchResponse(serializedRequest.Serialize(tms.MessageCompression), iggcon.SendMessagesCode)
-	return err
+	buffers := serializedRequest.Buffers(tms.MessageCompression)
+	defer serializedRequest.Release()
+	size := 0
+	for _, buffer := range buffers {
+		size += len(buffer)
+	}
+	if err := tms.rateLimiter.Wait(tms.ctx, len(messages), size); err != nil {
+		return nil, err
+	}
+	buffer, err := tms.sendBuffersAndFetchResponse(buffers, size, iggcon.SendMessagesCode)
+	if err != nil {
+		return nil, err
+	}
+	return binaryserialization.DeserializeSendResult(buffer)
 }
 
 func (tms *MessengerTcpClient) PollMessages(
@@ -51,6 +105,41 @@ func (tms *MessengerTcpClient) PollMessages(
 	autoCommit bool,
 	partitionId *uint32,
 ) (*iggcon.PolledMessage, error) {
+	return tms.PollMessagesWithWait(streamId, topicId, consumer, strategy, count, autoCommit, partitionId, 0)
+}
+
+func (tms *MessengerTcpClient) PollMessagesWithWait(
+	streamId iggcon.Identifier,
+	topicId iggcon.Identifier,
+	consumer iggcon.Consumer,
+	strategy iggcon.PollingStrategy,
+	count uint32,
+	autoCommit bool,
+	partitionId *uint32,
+	maxWait time.Duration,
+) (*iggcon.PolledMessage, error) {
+	return tms.PollMessagesWithContext(context.Background(), streamId, topicId, consumer, strategy, count, autoCommit, partitionId, maxWait)
+}
+
+// PollMessagesWithContext polls messages like PollMessagesWithWait, interrupting the poll as soon
+// as ctx is done. There is no request to cancel a poll, so the connection of an interrupted poll
+// is torn down, and the next request reconnects and logs in again with the credentials of the
+// last login. The messages of an interrupted poll are lost if it stored their offset.
+func (tms *MessengerTcpClient) PollMessagesWithContext(
+	ctx context.Context,
+	streamId iggcon.Identifier,
+	topicId iggcon.Identifier,
+	consumer iggcon.Consumer,
+	strategy iggcon.PollingStrategy,
+	count uint32,
+	autoCommit bool,
+	partitionId *uint32,
+	maxWait time.Duration,
+) (*iggcon.PolledMessage, error) {
+	if !tms.Dialect().LongPolling {
+		// the server answers right away
+		maxWait = 0
+	}
 	serializedRequest := binaryserialization.TcpFetchMessagesRequest{
 		StreamId:    streamId,
 		TopicId:     topicId,
@@ -59,11 +148,21 @@ func (tms *MessengerTcpClient) PollMessages(
 		Strategy:    strategy,
 		Count:       count,
 		PartitionId: partitionId,
+		MaxWait:     maxWait,
 	}
-	buffer, err := tms.sendAndFetchResponse(serializedRequest.Serialize(), iggcon.PollMessagesCode)
+	// the polled messages are only known once received, the polls wait for their debt instead
+	if err := tms.rateLimiter.WaitRepaid(ctx); err != nil {
+		return nil, err
+	}
+	buffer, err := tms.sendAndFetchResponseContext(ctx, serializedRequest.Serialize(), iggcon.PollMessagesCode, maxWait)
 	if err != nil {
 		return nil, err
 	}
+	tms.rateLimiter.ChargeBytes(len(buffer))
 
-	return binaryserialization.DeserializeFetchMessagesResponse(buffer, tms.MessageCompression)
+	polled, err := binaryserialization.DeserializeFetchMessagesResponseWithDialect(buffer, tms.MessageCompression, tms.Dialect())
+	if polled != nil {
+		tms.rateLimiter.ChargeMessages(len(polled.Messages))
+	}
+	return polled, err
 }
@@ -0,0 +1,140 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tcp
+
+import (
+	"errors"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	ierror "github.com/apache/messenger/foreign/go/errors"
+)
+
+// ConnectionState is the state of the connection of a client to a server.
+type ConnectionState uint8
+
+const (
+	// ConnectionConnected is reported once a connection is established, including after failing over.
+	ConnectionConnected ConnectionState = iota + 1
+	// ConnectionBroken is reported after a transport error, the requests failing until the client
+	// fails over to another endpoint.
+	ConnectionBroken
+	// ConnectionClosed is reported when the client is closed.
+	ConnectionClosed
+)
+
+func (s ConnectionState) String() string {
+	switch s {
+	case ConnectionConnected:
+		return "connected"
+	case ConnectionBroken:
+		return "broken"
+	case ConnectionClosed:
+		return "closed"
+	}
+	return "unknown"
+}
+
+// RequestStart describes a request about to be written.
+type RequestStart struct {
+	Command iggcon.CommandCode
+	// RequestBytes is the size of the frame, header included.
+	RequestBytes int
+}
+
+// Retry describes a connection attempt made after the previous one failed, to the next address
+// resolved for the server or to the next endpoint.
+type Retry struct {
+	Address string
+	// Attempt is the number of the attempt, 2 for the first retry.
+	Attempt int
+	// Err is the error of the previous attempt.
+	Err error
+}
+
+// ConnectionStateChange describes a change of the state of the connection to a server.
+type ConnectionStateChange struct {
+	State   ConnectionState
+	Address string
+	// Err is the transport error which broke the connection.
+	Err error
+}
+
+// RequestObserver receives the lifecycle events of the requests and of the connection, e.g. to
+// feed an APM system. Its methods are called synchronously, mostly while the connection is held,
+// so they must not block. Embed NopRequestObserver to implement only some of them.
+type RequestObserver interface {
+	// OnRequestStart is called before writing a request.
+	OnRequestStart(request RequestStart)
+	// OnRequestEnd is called once the response is read or the request failed, with the sample
+	// also passed to the ProtocolObserver.
+	OnRequestEnd(sample ProtocolSample)
+	// OnRetry is called before retrying to connect.
+	OnRetry(retry Retry)
+	// OnConnectionStateChange is called when the connection is established, broken or closed.
+	OnConnectionStateChange(change ConnectionStateChange)
+}
+
+// NopRequestObserver ignores every event.
+type NopRequestObserver struct{}
+
+func (NopRequestObserver) OnRequestStart(RequestStart)                   {}
+func (NopRequestObserver) OnRequestEnd(ProtocolSample)                   {}
+func (NopRequestObserver) OnRetry(Retry)                                 {}
+func (NopRequestObserver) OnConnectionStateChange(ConnectionStateChange) {}
+
+// WithRequestObserver sets the observer receiving the lifecycle events of the requests and of
+// the connection.
+func WithRequestObserver(observer RequestObserver) Option {
+	return func(opts *Options) {
+		opts.RequestObserver = observer
+	}
+}
+
+// requestObserver returns the observer of the options, NopRequestObserver when not set.
+func (opts Options) requestObserver() RequestObserver {
+	if opts.RequestObserver == nil {
+		return NopRequestObserver{}
+	}
+	return opts.RequestObserver
+}
+
+// setConnectionState reports a change of the state of the connection, unless it is already in
+// that state. Must hold tms.mtx.
+func (tms *MessengerTcpClient) setConnectionState(state ConnectionState, err error) {
+	if tms.connectionState == state {
+		return
+	}
+	tms.connectionState = state
+	tms.observer.OnConnectionStateChange(ConnectionStateChange{State: state, Address: tms.address, Err: err})
+	switch state {
+	case ConnectionConnected:
+		tms.events.emit(ConnectionEventConnected, tms.address, nil)
+	case ConnectionBroken:
+		tms.events.emit(ConnectionEventDisconnected, tms.address, err)
+	case ConnectionClosed:
+		tms.events.close(tms.address)
+	}
+}
+
+// observeTransport reports the connection broken by a transport error. Must hold tms.mtx.
+func (tms *MessengerTcpClient) observeTransport(err error) {
+	var messengerErr *ierror.MessengerError
+	if err != nil && !errors.Is(err, ErrClosed) && !errors.As(err, &messengerErr) {
+		tms.setConnectionState(ConnectionBroken, err)
+	}
+}
@@ -0,0 +1,168 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"sync"
+	"testing"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+)
+
+type recordingObserver struct {
+	events []string
+}
+
+func (o *recordingObserver) OnRequestStart(request RequestStart) {
+	o.events = append(o.events, fmt.Sprintf("start %d", request.Command))
+}
+
+func (o *recordingObserver) OnRequestEnd(sample ProtocolSample) {
+	o.events = append(o.events, fmt.Sprintf("end %d %t", sample.Command, sample.Err == nil))
+}
+
+func (o *recordingObserver) OnRetry(retry Retry) {
+	o.events = append(o.events, fmt.Sprintf("retry %s %d", retry.Address, retry.Attempt))
+}
+
+func (o *recordingObserver) OnConnectionStateChange(change ConnectionStateChange) {
+	o.events = append(o.events, fmt.Sprintf("%s %s", change.State, change.Address))
+}
+
+func TestRequestObserver(t *testing.T) {
+	var mtx sync.Mutex
+	var commands []iggcon.CommandCode
+	var server net.Conn
+	dial := func(_ context.Context, _, address string) (net.Conn, error) {
+		if address != "b:1" {
+			return nil, errors.New(address + " refused")
+		}
+		var client net.Conn
+		server, client = net.Pipe()
+		go serveRequests(server, &mtx, &commands, nil)
+		return client, nil
+	}
+	resolve := func(context.Context, string) ([]string, error) {
+		return []string{"a:1", "b:1"}, nil
+	}
+
+	observer := &recordingObserver{}
+	cli, err := NewMessengerTcpClient(
+		WithServerAddress("server:1"),
+		WithResolver(resolve),
+		WithDialFunc(dial),
+		WithRequestObserver(observer),
+		func(opts *Options) { opts.HeartbeatInterval = 0 },
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = cli.Ping(); err != nil {
+		t.Fatal(err)
+	}
+	_ = server.Close()
+	if err = cli.Ping(); err == nil {
+		t.Fatal("expected the request sent to the closed server to fail")
+	}
+	if err = cli.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	ping := iggcon.PingCode
+	expected := []string{
+		"retry b:1 2",
+		"connected server:1",
+		fmt.Sprintf("start %d", ping),
+		fmt.Sprintf("end %d true", ping),
+		fmt.Sprintf("start %d", ping),
+		fmt.Sprintf("end %d false", ping),
+		"broken server:1",
+		"closed server:1",
+	}
+	if !reflect.DeepEqual(observer.events, expected) {
+		t.Fatalf("expected events %q, got %q", expected, observer.events)
+	}
+}
+
+func TestConnectionEvents(t *testing.T) {
+	var mtx sync.Mutex
+	servers := map[string]net.Conn{}
+	dial := func(_ context.Context, _, address string) (net.Conn, error) {
+		mtx.Lock()
+		defer mtx.Unlock()
+		if servers[address] != nil {
+			return nil, errors.New(address + " refused")
+		}
+		server, client := net.Pipe()
+		servers[address] = server
+		go serveRequests(server, &mtx, &[]iggcon.CommandCode{}, nil)
+		return client, nil
+	}
+	cli, err := NewMessengerTcpClient(
+		WithServerAddresses("a:1", "b:1"),
+		WithDialFunc(dial),
+		func(opts *Options) { opts.HeartbeatInterval = 0 },
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := cli.ConnectionEvents()
+	if _, err = cli.LoginUser("user", "secret"); err != nil {
+		t.Fatal(err)
+	}
+	mtx.Lock()
+	_ = servers["a:1"].Close()
+	mtx.Unlock()
+	if err = cli.Ping(); err == nil {
+		t.Fatal("expected the request sent to the closed server to fail")
+	}
+	if err = cli.Ping(); err != nil {
+		t.Fatal(err)
+	}
+	if err = cli.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var received []string
+	for event := range events {
+		received = append(received, event.Kind.String()+" "+event.Address)
+		if (event.Err != nil) != (len(received) == 3) {
+			t.Errorf("unexpected error of the event %s: %v", received[len(received)-1], event.Err)
+		}
+	}
+	expected := []string{
+		"connected a:1",
+		"authenticated a:1",
+		"disconnected a:1",
+		"reconnecting a:1",
+		"connected b:1",
+		"authenticated b:1",
+		"disconnected b:1",
+	}
+	if !reflect.DeepEqual(received, expected) {
+		t.Fatalf("expected the events %v, got %v", expected, received)
+	}
+	if _, ok := <-cli.ConnectionEvents(); !ok {
+		t.Fatal("expected the current state to be replayed after closing")
+	}
+}
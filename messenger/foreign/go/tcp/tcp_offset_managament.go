@@ -48,3 +48,14 @@ func (tms *MessengerTcpClient) StoreConsumerOffset(consumer iggcon.Consumer, str
 	_, err := tms.sendAndFetchResponse(message, iggcon.StoreOffsetCode)
 	return err
 }
+
+func (tms *MessengerTcpClient) DeleteConsumerOffset(consumer iggcon.Consumer, streamId iggcon.Identifier, topicId iggcon.Identifier, partitionId *uint32) error {
+	message := binaryserialization.DeleteOffset(iggcon.DeleteConsumerOffsetRequest{
+		StreamId:    streamId,
+		TopicId:     topicId,
+		Consumer:    consumer,
+		PartitionId: partitionId,
+	})
+	_, err := tms.sendAndFetchResponse(message, iggcon.DeleteOffsetCode)
+	return err
+}
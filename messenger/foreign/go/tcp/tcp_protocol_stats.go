@@ -0,0 +1,168 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tcp
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	ierror "github.com/apache/messenger/foreign/go/errors"
+)
+
+// latencySamples is the number of most recent latencies per command the percentiles are computed from.
+const latencySamples = 1024
+
+// ProtocolSample describes a single request, as passed to a ProtocolObserver.
+type ProtocolSample struct {
+	Command iggcon.CommandCode
+	// Latency is the time from writing the request to reading the whole response.
+	Latency time.Duration
+	// RequestBytes and ResponseBytes are the sizes of the frames, headers included.
+	RequestBytes  int
+	ResponseBytes int
+	// ErrorCode is the error code returned by the server, 0 on success.
+	ErrorCode int
+	// Err is the error of the request, either returned by the server or by the transport.
+	Err error
+}
+
+// ProtocolObserver receives every request, e.g. to feed a metrics system. It is called while the
+// connection is held, so it must not block.
+type ProtocolObserver func(sample ProtocolSample)
+
+// WithProtocolObserver sets the observer receiving every request sent to the server.
+func WithProtocolObserver(observer ProtocolObserver) Option {
+	return func(opts *Options) {
+		opts.ProtocolObserver = observer
+	}
+}
+
+// CommandStats are the statistics of the requests of a command since the client was created.
+type CommandStats struct {
+	Requests uint64
+	// Errors counts the failed requests, of which TransportErrors got no response from the server.
+	Errors          uint64
+	TransportErrors uint64
+	// ErrorCodes counts the requests failed by the server per error code.
+	ErrorCodes    map[int]uint64
+	RequestBytes  uint64
+	ResponseBytes uint64
+	// P50, P90 and P99 are the latency percentiles of the most recent requests, MaxLatency the
+	// highest latency since the client was created.
+	P50        time.Duration
+	P90        time.Duration
+	P99        time.Duration
+	MaxLatency time.Duration
+}
+
+// ProtocolStats is a snapshot of the statistics of the requests sent by a client, per command.
+type ProtocolStats struct {
+	Commands map[iggcon.CommandCode]CommandStats
+}
+
+type commandRecorder struct {
+	stats     CommandStats
+	latencies []time.Duration
+	next      int
+}
+
+type protocolRecorder struct {
+	observer ProtocolObserver
+
+	mtx      sync.Mutex
+	commands map[iggcon.CommandCode]*commandRecorder
+}
+
+func newProtocolRecorder(observer ProtocolObserver) *protocolRecorder {
+	return &protocolRecorder{
+		observer: observer,
+		commands: map[iggcon.CommandCode]*commandRecorder{},
+	}
+}
+
+func (r *protocolRecorder) record(sample ProtocolSample) {
+	var messengerErr *ierror.MessengerError
+	if errors.As(sample.Err, &messengerErr) {
+		sample.ErrorCode = messengerErr.Code
+	}
+
+	r.mtx.Lock()
+	c, ok := r.commands[sample.Command]
+	if !ok {
+		c = &commandRecorder{stats: CommandStats{ErrorCodes: map[int]uint64{}}}
+		r.commands[sample.Command] = c
+	}
+	c.stats.Requests++
+	c.stats.RequestBytes += uint64(sample.RequestBytes)
+	c.stats.ResponseBytes += uint64(sample.ResponseBytes)
+	switch {
+	case sample.ErrorCode != 0:
+		c.stats.Errors++
+		c.stats.ErrorCodes[sample.ErrorCode]++
+	case sample.Err != nil:
+		c.stats.Errors++
+		c.stats.TransportErrors++
+	}
+	c.stats.MaxLatency = max(c.stats.MaxLatency, sample.Latency)
+	if len(c.latencies) < latencySamples {
+		c.latencies = append(c.latencies, sample.Latency)
+	} else {
+		c.latencies[c.next] = sample.Latency
+		c.next = (c.next + 1) % latencySamples
+	}
+	r.mtx.Unlock()
+
+	if r.observer != nil {
+		r.observer(sample)
+	}
+}
+
+func (r *protocolRecorder) snapshot() ProtocolStats {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	snapshot := ProtocolStats{Commands: make(map[iggcon.CommandCode]CommandStats, len(r.commands))}
+	for command, c := range r.commands {
+		stats := c.stats
+		stats.ErrorCodes = maps.Clone(c.stats.ErrorCodes)
+		latencies := slices.Clone(c.latencies)
+		slices.Sort(latencies)
+		stats.P50 = percentile(latencies, 50)
+		stats.P90 = percentile(latencies, 90)
+		stats.P99 = percentile(latencies, 99)
+		snapshot.Commands[command] = stats
+	}
+	return snapshot
+}
+
+// percentile returns the nearest-rank percentile p of the sorted latencies.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := (len(sorted)*p + 99) / 100
+	return sorted[max(rank, 1)-1]
+}
+
+// ProtocolStats returns the per-command statistics of the requests sent since the client was created.
+func (tms *MessengerTcpClient) ProtocolStats() ProtocolStats {
+	return tms.protocol.snapshot()
+}
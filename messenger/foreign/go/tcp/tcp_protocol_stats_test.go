@@ -0,0 +1,110 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tcp
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+)
+
+// serveStatus answers every request on conn with an empty response of the given status.
+func serveStatus(conn net.Conn, status func(command iggcon.CommandCode) uint32) {
+	header := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(conn, header); err != nil {
+			return
+		}
+		command := iggcon.CommandCode(binary.LittleEndian.Uint32(header[4:]))
+		if _, err := io.CopyN(io.Discard, conn, int64(binary.LittleEndian.Uint32(header[:4])-4)); err != nil {
+			return
+		}
+		response := make([]byte, 8)
+		binary.LittleEndian.PutUint32(response, status(command))
+		if _, err := conn.Write(response); err != nil {
+			return
+		}
+	}
+}
+
+func TestProtocolStats_RecordsRequestsPerCommand(t *testing.T) {
+	server, client := net.Pipe()
+	defer server.Close()
+	go serveStatus(server, func(command iggcon.CommandCode) uint32 {
+		if command == iggcon.GetStreamCode {
+			return 1009
+		}
+		return 0
+	})
+
+	var samples []ProtocolSample
+	cli, err := NewMessengerTcpClient(
+		WithDialFunc(func(context.Context, string, string) (net.Conn, error) { return client, nil }),
+		WithProtocolObserver(func(sample ProtocolSample) { samples = append(samples, sample) }),
+		func(opts *Options) { opts.HeartbeatInterval = 0 },
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if err = cli.Ping(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	streamId, _ := iggcon.NewIdentifier[uint32](1)
+	if _, err = cli.GetStream(streamId); err == nil {
+		t.Fatal("expected GetStream to fail")
+	}
+
+	stats := cli.ProtocolStats()
+	ping := stats.Commands[iggcon.PingCode]
+	if ping.Requests != 3 || ping.Errors != 0 || ping.RequestBytes != 3*8 || ping.ResponseBytes != 3*8 {
+		t.Errorf("unexpected ping stats %+v", ping)
+	}
+	if ping.P99 <= 0 || ping.P99 > ping.MaxLatency || ping.P50 > ping.P99 {
+		t.Errorf("unexpected ping latencies %+v", ping)
+	}
+	getStream := stats.Commands[iggcon.GetStreamCode]
+	if getStream.Requests != 1 || getStream.Errors != 1 || getStream.ErrorCodes[1009] != 1 || getStream.TransportErrors != 0 {
+		t.Errorf("unexpected get stream stats %+v", getStream)
+	}
+	if len(samples) != 4 || samples[3].ErrorCode != 1009 {
+		t.Errorf("unexpected observed samples %+v", samples)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	sorted := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	latencies := make([]time.Duration, len(sorted))
+	for i, value := range sorted {
+		latencies[i] = time.Duration(value)
+	}
+	for p, expected := range map[int]time.Duration{50: 5, 90: 9, 99: 10} {
+		if actual := percentile(latencies, p); actual != expected {
+			t.Errorf("percentile %d: expected %d, got %d", p, expected, actual)
+		}
+	}
+	if percentile(nil, 50) != 0 {
+		t.Error("expected 0 without samples")
+	}
+}
@@ -0,0 +1,108 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tcp
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/ratelimit"
+)
+
+func TestRateLimit_EmptyPollsAreNotCharged(t *testing.T) {
+	var mtx sync.Mutex
+	var commands []iggcon.CommandCode
+	dial := func(context.Context, string, string) (net.Conn, error) {
+		server, client := net.Pipe()
+		// the polls are answered without messages
+		go serveRequests(server, &mtx, &commands, nil)
+		return client, nil
+	}
+	limiter := ratelimit.NewLimiter(ratelimit.Config{MessagesPerSecond: 10})
+	cli, err := NewMessengerTcpClient(
+		WithServerAddress("server:1"),
+		WithDialFunc(dial),
+		WithRateLimiter(limiter),
+		func(opts *Options) { opts.HeartbeatInterval = 0 },
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cli.Close()
+	if _, err = cli.LoginUser("user", "secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	streamId, topicId := iggcon.MustIdentifier("orders"), iggcon.MustIdentifier("created")
+	partitionId := uint32(1)
+	start := time.Now()
+	for range 5 {
+		polled, err := cli.PollMessages(streamId, topicId, iggcon.DefaultConsumer(), iggcon.NextPollingStrategy(), 100, true, &partitionId)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(polled.Messages) != 0 {
+			t.Fatalf("expected no messages, got %d", len(polled.Messages))
+		}
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected the empty polls not to be throttled, took %s", elapsed)
+	}
+}
+
+func TestRateLimit_CancelledPollStopsWaitingForTheDebt(t *testing.T) {
+	var mtx sync.Mutex
+	var commands []iggcon.CommandCode
+	dial := func(context.Context, string, string) (net.Conn, error) {
+		server, client := net.Pipe()
+		go serveRequests(server, &mtx, &commands, nil)
+		return client, nil
+	}
+	limiter := ratelimit.NewLimiter(ratelimit.Config{MessagesPerSecond: 1})
+	cli, err := NewMessengerTcpClient(
+		WithServerAddress("server:1"),
+		WithDialFunc(dial),
+		WithRateLimiter(limiter),
+		func(opts *Options) { opts.HeartbeatInterval = 0 },
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cli.Close()
+	if _, err = cli.LoginUser("user", "secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	// earlier polls returned messages far beyond the budget
+	limiter.ChargeMessages(100)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	partitionId := uint32(1)
+	start := time.Now()
+	_, err = cli.PollMessagesWithContext(ctx, iggcon.MustIdentifier("orders"), iggcon.MustIdentifier("created"), iggcon.DefaultConsumer(), iggcon.NextPollingStrategy(), 100, true, &partitionId, time.Second)
+	if err != context.DeadlineExceeded {
+		t.Fatalf("expected the poll to time out, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected the poll to stop waiting for the debt, took %s", elapsed)
+	}
+}
@@ -28,10 +28,14 @@ func (tms *MessengerTcpClient) LoginUser(username string, password string) (*igg
 		Username: username,
 		Password: password,
 	}
-	buffer, err := tms.sendAndFetchResponse(serializedRequest.Serialize(), iggcon.LoginUserCode)
+	message := serializedRequest.Serialize()
+	buffer, err := tms.sendAndFetchResponse(message, iggcon.LoginUserCode)
 	if err != nil {
 		return nil, err
 	}
+	tms.rememberLogin(iggcon.LoginUserCode, message)
+	tms.negotiateDialect()
+	tms.sendLabels()
 
 	return binaryserialization.DeserializeLogInResponse(buffer), nil
 }
@@ -44,11 +48,17 @@ func (tms *MessengerTcpClient) LoginWithPersonalAccessToken(token string) (*iggc
 	if err != nil {
 		return nil, err
 	}
+	tms.rememberLogin(iggcon.LoginWithAccessTokenCode, message)
+	tms.negotiateDialect()
+	tms.sendLabels()
 
 	return binaryserialization.DeserializeLogInResponse(buffer), nil
 }
 
 func (tms *MessengerTcpClient) LogoutUser() error {
 	_, err := tms.sendAndFetchResponse([]byte{}, iggcon.LogoutUserCode)
+	if err == nil {
+		tms.rememberLogin(0, nil)
+	}
 	return err
 }
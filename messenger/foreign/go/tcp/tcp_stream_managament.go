@@ -51,8 +51,8 @@ func (tms *MessengerTcpClient) GetStream(streamId iggcon.Identifier) (*iggcon.St
 }
 
 func (tms *MessengerTcpClient) CreateStream(name string, streamId *uint32) (*iggcon.StreamDetails, error) {
-	if MaxStringLength < len(name) {
-		return nil, ierror.TextTooLong("stream_name")
+	if err := tms.connectOptions.NameValidator.Validate(iggcon.StreamName, name); err != nil {
+		return nil, err
 	}
 	serializedRequest := binaryserialization.TcpCreateStreamRequest{Name: name, StreamId: streamId}
 	buffer, err := tms.sendAndFetchResponse(serializedRequest.Serialize(), iggcon.CreateStreamCode)
@@ -68,8 +68,8 @@ func (tms *MessengerTcpClient) CreateStream(name string, streamId *uint32) (*igg
 }
 
 func (tms *MessengerTcpClient) UpdateStream(streamId iggcon.Identifier, name string) error {
-	if MaxStringLength <= len(name) {
-		return ierror.TextTooLong("stream_name")
+	if err := tms.connectOptions.NameValidator.Validate(iggcon.StreamName, name); err != nil {
+		return err
 	}
 	serializedRequest := binaryserialization.TcpUpdateStreamRequest{StreamId: streamId, Name: name}
 	_, err := tms.sendAndFetchResponse(serializedRequest.Serialize(), iggcon.UpdateStreamCode)
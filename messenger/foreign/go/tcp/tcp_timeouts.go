@@ -0,0 +1,134 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tcp
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+)
+
+// ErrTimeout is returned when the server did not answer a request within its timeout.
+var ErrTimeout = errors.New("tcp: request timed out")
+
+// Timeouts bound the time spent connecting to the server and waiting for its responses,
+// a zero timeout meaning unbounded. The deadline of the client context, if earlier, applies too.
+type Timeouts struct {
+	// Connect bounds the resolution of the server address, and every dial attempt.
+	Connect time.Duration
+	// Request bounds every request, from writing it to reading its whole response.
+	Request time.Duration
+	// Poll bounds the PollMessages requests instead of Request, extended by their max wait
+	// when long polling.
+	Poll time.Duration
+	// Commands overrides the timeout of specific commands.
+	Commands map[iggcon.CommandCode]time.Duration
+}
+
+// DefaultTimeouts returns the timeouts used unless configured otherwise.
+func DefaultTimeouts() Timeouts {
+	return Timeouts{
+		Connect: 10 * time.Second,
+		Request: 30 * time.Second,
+		Poll:    60 * time.Second,
+	}
+}
+
+// forCommand returns the timeout of a command.
+func (t Timeouts) forCommand(command iggcon.CommandCode) time.Duration {
+	if timeout, ok := t.Commands[command]; ok {
+		return timeout
+	}
+	if command == iggcon.PollMessagesCode {
+		return t.Poll
+	}
+	return t.Request
+}
+
+// WithTimeouts replaces all the timeouts.
+func WithTimeouts(timeouts Timeouts) Option {
+	return func(opts *Options) {
+		opts.Timeouts = timeouts
+	}
+}
+
+// WithConnectTimeout bounds the resolution of the server address and every dial attempt.
+func WithConnectTimeout(timeout time.Duration) Option {
+	return func(opts *Options) {
+		opts.Timeouts.Connect = timeout
+	}
+}
+
+// WithRequestTimeout bounds every request but the polls.
+func WithRequestTimeout(timeout time.Duration) Option {
+	return func(opts *Options) {
+		opts.Timeouts.Request = timeout
+	}
+}
+
+// WithPollTimeout bounds the PollMessages requests.
+func WithPollTimeout(timeout time.Duration) Option {
+	return func(opts *Options) {
+		opts.Timeouts.Poll = timeout
+	}
+}
+
+// WithCommandTimeout overrides the timeout of a command, e.g. of a slow administrative command.
+func WithCommandTimeout(command iggcon.CommandCode, timeout time.Duration) Option {
+	return func(opts *Options) {
+		opts.Timeouts.Commands = maps.Clone(opts.Timeouts.Commands)
+		if opts.Timeouts.Commands == nil {
+			opts.Timeouts.Commands = map[iggcon.CommandCode]time.Duration{}
+		}
+		opts.Timeouts.Commands[command] = timeout
+	}
+}
+
+// setDeadline sets the deadline of the connection for a command starting at start, the server
+// holding it for up to wait, returning the timeout applied. Must hold tms.mtx.
+func (tms *MessengerTcpClient) setDeadline(command iggcon.CommandCode, start time.Time, wait time.Duration) (time.Duration, error) {
+	timeout := tms.timeouts.forCommand(command)
+	var deadline time.Time
+	if timeout > 0 {
+		timeout += wait
+		deadline = start.Add(timeout)
+	}
+	if ctxDeadline, ok := tms.ctx.Deadline(); ok && (deadline.IsZero() || ctxDeadline.Before(deadline)) {
+		deadline = ctxDeadline
+		timeout = ctxDeadline.Sub(start)
+	}
+	if err := tms.conn.SetDeadline(deadline); err != nil {
+		return 0, err
+	}
+	return timeout, nil
+}
+
+// checkTimeout converts the timeout of a request into ErrTimeout. The late response of the request
+// would be read as the response of the next one, so the connection is closed.
+func (tms *MessengerTcpClient) checkTimeout(command iggcon.CommandCode, timeout time.Duration, err error) error {
+	var netErr net.Error
+	if !errors.As(err, &netErr) || !netErr.Timeout() {
+		return err
+	}
+	_ = tms.conn.Close()
+	return fmt.Errorf("%w: command %d got no response within %s", ErrTimeout, command, timeout)
+}
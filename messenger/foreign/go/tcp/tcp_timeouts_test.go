@@ -0,0 +1,136 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tcp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+)
+
+func TestTimeouts_ForCommand(t *testing.T) {
+	timeouts := DefaultTimeouts()
+	WithCommandTimeout(iggcon.CreateTopicCode, time.Minute)(&Options{Timeouts: timeouts})
+	if timeouts.Commands != nil {
+		t.Fatal("expected WithCommandTimeout not to alter the timeouts it was applied to")
+	}
+	opts := Options{Timeouts: timeouts}
+	WithCommandTimeout(iggcon.CreateTopicCode, time.Minute)(&opts)
+
+	for command, expected := range map[iggcon.CommandCode]time.Duration{
+		iggcon.PingCode:         30 * time.Second,
+		iggcon.PollMessagesCode: 60 * time.Second,
+		iggcon.CreateTopicCode:  time.Minute,
+	} {
+		if actual := opts.Timeouts.forCommand(command); actual != expected {
+			t.Errorf("command %d: expected %s, got %s", command, expected, actual)
+		}
+	}
+}
+
+func TestRequestTimeout_ClosesConnection(t *testing.T) {
+	server, client := net.Pipe()
+	defer server.Close()
+	go func() {
+		// read the requests without ever answering
+		_, _ = io.Copy(io.Discard, server)
+	}()
+
+	cli, err := NewMessengerTcpClient(
+		WithDialFunc(func(context.Context, string, string) (net.Conn, error) { return client, nil }),
+		WithRequestTimeout(20*time.Millisecond),
+		func(opts *Options) { opts.HeartbeatInterval = 0 },
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	if err = cli.Ping(); !errors.Is(err, ErrTimeout) {
+		t.Fatalf("expected ErrTimeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected the request to time out after 20ms, took %s", elapsed)
+	}
+	if err = cli.Ping(); err == nil || errors.Is(err, ErrTimeout) {
+		t.Fatalf("expected the connection to be closed, got %v", err)
+	}
+}
+
+func TestPollMessagesWithContext_ReconnectsAfterCancel(t *testing.T) {
+	var mtx sync.Mutex
+	var connections []*[]iggcon.CommandCode
+	unblock := make(chan struct{})
+	defer close(unblock)
+	dial := func(context.Context, string, string) (net.Conn, error) {
+		server, client := net.Pipe()
+		commands := &[]iggcon.CommandCode{}
+		mtx.Lock()
+		connections = append(connections, commands)
+		mtx.Unlock()
+		go serveRequests(server, &mtx, commands, func(command iggcon.CommandCode) []byte {
+			if command == iggcon.PollMessagesCode {
+				// the poll is held until it is interrupted
+				<-unblock
+			}
+			return nil
+		})
+		return client, nil
+	}
+	cli, err := NewMessengerTcpClient(
+		WithDialFunc(dial),
+		func(opts *Options) { opts.HeartbeatInterval = 0 },
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = cli.LoginUser("user", "secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	partitionId := uint32(1)
+	_, err = cli.PollMessagesWithContext(ctx, iggcon.MustIdentifier("stream"), iggcon.MustIdentifier("topic"),
+		iggcon.DefaultConsumer(), iggcon.NextPollingStrategy(), 10, false, &partitionId, time.Minute)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the poll to be interrupted, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected the poll to be interrupted after 20ms, took %s", elapsed)
+	}
+	if err = cli.Ping(); err != nil {
+		t.Fatalf("expected the client to reconnect, got %v", err)
+	}
+
+	mtx.Lock()
+	defer mtx.Unlock()
+	if len(connections) != 2 {
+		t.Fatalf("expected a new connection, got %d", len(connections))
+	}
+	if reconnected := *connections[1]; !reflect.DeepEqual(reconnected, []iggcon.CommandCode{iggcon.LoginUserCode, iggcon.PingCode}) {
+		t.Errorf("expected the login to be replayed before the ping, got %v", reconnected)
+	}
+}
@@ -56,13 +56,13 @@ func (tms *MessengerTcpClient) CreateTopic(
 	name string,
 	partitionsCount uint32,
 	compressionAlgorithm iggcon.CompressionAlgorithm,
-	messageExpiry iggcon.Duration,
-	maxTopicSize uint64,
+	messageExpiry iggcon.Expiry,
+	maxTopicSize iggcon.MaxTopicSize,
 	replicationFactor *uint8,
 	topicId *uint32,
 ) (*iggcon.TopicDetails, error) {
-	if MaxStringLength < len(name) {
-		return nil, ierror.TextTooLong("topic_name")
+	if err := tms.connectOptions.NameValidator.Validate(iggcon.TopicName, name); err != nil {
+		return nil, err
 	}
 	serializedRequest := binaryserialization.TcpCreateTopicRequest{
 		StreamId:             streamId,
@@ -87,12 +87,12 @@ func (tms *MessengerTcpClient) UpdateTopic(
 	topicId iggcon.Identifier,
 	name string,
 	compressionAlgorithm iggcon.CompressionAlgorithm,
-	messageExpiry iggcon.Duration,
-	maxTopicSize uint64,
+	messageExpiry iggcon.Expiry,
+	maxTopicSize iggcon.MaxTopicSize,
 	replicationFactor *uint8,
 ) error {
-	if MaxStringLength < len(name) {
-		return ierror.TextTooLong("topic_name")
+	if err := tms.connectOptions.NameValidator.Validate(iggcon.TopicName, name); err != nil {
+		return err
 	}
 	serializedRequest := binaryserialization.TcpUpdateTopicRequest{
 		StreamId:             streamId,
@@ -0,0 +1,324 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package window
+
+import (
+	"container/heap"
+	"context"
+	"encoding/binary"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/apache/messenger/foreign/go/consumer"
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengercli"
+	"github.com/google/uuid"
+)
+
+// Side is the topic of a KeyedJoin a message was consumed from.
+type Side uint8
+
+const (
+	Left Side = iota
+	Right
+)
+
+// Joined is a pair of messages sharing a key whose timestamps are within the join window.
+type Joined struct {
+	Key   string
+	Left  iggcon.ReceivedMessage
+	Right iggcon.ReceivedMessage
+}
+
+// JoinStats reports the activity of a KeyedJoin.
+type JoinStats struct {
+	// Joined is the number of pairs emitted.
+	Joined uint64
+	// Late is the number of messages dropped because they arrived past the grace period.
+	Late uint64
+	// Evicted is the number of messages evicted by the memory bounds before expiring.
+	Evicted uint64
+	// Buffered is the number of messages currently buffered.
+	Buffered int
+}
+
+// joinNamespace derives the IDs of the joined messages.
+var joinNamespace = uuid.MustParse("7c4d2e9b-1a6f-4b83-8e5d-3f9a0b2c6d41")
+
+// KeyedJoin joins the messages of two topics sharing a key, emitting a message to the sink for
+// every pair of left and right messages whose timestamps are at most the join window apart (an
+// inner join). The messages are buffered per key until the watermark, the latest timestamp seen on
+// either side, is past their join window and the grace period set with WithLateness; the messages
+// arriving later than the grace period are dropped as late, so the grace period has to cover the
+// lag between the consumers of the two topics. The buffers are kept in memory, bounded with
+// WithMaxBuffered.
+//
+// The joined messages get IDs derived from the sink and the partitions and offsets of the pair,
+// identical when a redelivery joins them again, so their consumers can drop the duplicates by ID.
+type KeyedJoin struct {
+	client  messengercli.DataClient
+	sink    consumer.Sink
+	within  time.Duration
+	key     func(message iggcon.ReceivedMessage) string
+	combine func(joined Joined) (iggcon.MessengerMessage, error)
+	opts    Options
+
+	mtx sync.Mutex
+	// buffers are the buffered messages of every side by key, and oldest all of them by timestamp.
+	buffers  [2]map[string][]*buffered
+	oldest   bufferedHeap
+	bytes    int
+	latest   time.Time
+	joined   uint64
+	late     uint64
+	evicted  uint64
+	sequence uint64
+}
+
+// buffered is a message waiting for its counterparts.
+type buffered struct {
+	side      Side
+	key       string
+	timestamp time.Time
+	message   iggcon.ReceivedMessage
+	size      int
+	// sequence breaks the ties between the timestamps.
+	sequence uint64
+}
+
+// NewKeyedJoin creates a KeyedJoin of the messages with the same key, returned by key, whose
+// timestamps are at most within apart, combine creating the message emitted for every pair.
+func NewKeyedJoin(
+	client messengercli.DataClient,
+	sink consumer.Sink,
+	within time.Duration,
+	key func(message iggcon.ReceivedMessage) string,
+	combine func(joined Joined) (iggcon.MessengerMessage, error),
+	options ...Option,
+) (*KeyedJoin, error) {
+	if client == nil {
+		return nil, errors.New("window: client is required")
+	}
+	if within < 0 {
+		return nil, errors.New("window: the join window must not be negative")
+	}
+	if key == nil || combine == nil {
+		return nil, errors.New("window: the join requires key and combine")
+	}
+	opts := GetDefaultOptions()
+	for _, opt := range options {
+		if opt != nil {
+			opt(&opts)
+		}
+	}
+	if sink.Partitioning.Kind == 0 {
+		sink.Partitioning = iggcon.None()
+	}
+	return &KeyedJoin{
+		client:  client,
+		sink:    sink,
+		within:  within,
+		key:     key,
+		combine: combine,
+		opts:    opts,
+		buffers: [2]map[string][]*buffered{{}, {}},
+	}, nil
+}
+
+// HandleLeft joins a message of the left topic, it is a consumer.Handler.
+func (j *KeyedJoin) HandleLeft(ctx context.Context, message iggcon.ReceivedMessage) error {
+	return j.Handle(ctx, Left, message)
+}
+
+// HandleRight joins a message of the right topic, it is a consumer.Handler.
+func (j *KeyedJoin) HandleRight(ctx context.Context, message iggcon.ReceivedMessage) error {
+	return j.Handle(ctx, Right, message)
+}
+
+// Handle emits the pairs of the message with the buffered messages of the other side, then
+// buffers it. A failure to emit the pairs leaves the buffers untouched, so the message can be
+// handled again.
+func (j *KeyedJoin) Handle(ctx context.Context, side Side, message iggcon.ReceivedMessage) error {
+	timestamp := j.opts.Timestamp(message)
+	key := j.key(message)
+
+	j.mtx.Lock()
+	defer j.mtx.Unlock()
+	if timestamp.Before(j.latest.Add(-j.opts.Lateness)) {
+		j.late++
+		if j.opts.OnLate != nil {
+			j.opts.OnLate(ctx, message)
+		}
+		return nil
+	}
+
+	var pairs []iggcon.MessengerMessage
+	for _, other := range j.buffers[1-side][key] {
+		if delta := timestamp.Sub(other.timestamp); delta > j.within || delta < -j.within {
+			continue
+		}
+		joined := Joined{Key: key, Left: message, Right: other.message}
+		if side == Right {
+			joined.Left, joined.Right = other.message, message
+		}
+		pair, err := j.combine(joined)
+		if err != nil {
+			return err
+		}
+		pair.Header.Id = j.joinedId(joined)
+		pairs = append(pairs, pair)
+	}
+	if len(pairs) > 0 {
+		if err := j.send(pairs); err != nil {
+			return err
+		}
+		j.joined += uint64(len(pairs))
+	}
+
+	j.sequence++
+	entry := &buffered{
+		side:      side,
+		key:       key,
+		timestamp: timestamp,
+		message:   message,
+		size:      len(message.Message.Payload) + len(message.Message.UserHeaders),
+		sequence:  j.sequence,
+	}
+	j.buffers[side][key] = append(j.buffers[side][key], entry)
+	heap.Push(&j.oldest, entry)
+	j.bytes += entry.size
+	if timestamp.After(j.latest) {
+		j.latest = timestamp
+	}
+	j.expire()
+	return nil
+}
+
+// expire removes the messages which can no longer join a message arriving within the grace
+// period, then the oldest ones while the bounds are exceeded. Must hold j.mtx.
+func (j *KeyedJoin) expire() {
+	cutoff := j.latest.Add(-j.opts.Lateness - j.within)
+	for len(j.oldest) > 0 && j.oldest[0].timestamp.Before(cutoff) {
+		j.remove(heap.Pop(&j.oldest).(*buffered))
+	}
+	for len(j.oldest) > 0 && (j.opts.MaxBuffered > 0 && len(j.oldest) > j.opts.MaxBuffered ||
+		j.opts.MaxBufferedBytes > 0 && j.bytes > j.opts.MaxBufferedBytes) {
+		j.remove(heap.Pop(&j.oldest).(*buffered))
+		j.evicted++
+	}
+}
+
+// remove removes a message popped from the heap from the buffer of its key. Must hold j.mtx.
+func (j *KeyedJoin) remove(entry *buffered) {
+	j.bytes -= entry.size
+	entries := j.buffers[entry.side][entry.key]
+	for i, buffered := range entries {
+		if buffered == entry {
+			entries = append(entries[:i], entries[i+1:]...)
+			break
+		}
+	}
+	if len(entries) == 0 {
+		delete(j.buffers[entry.side], entry.key)
+	} else {
+		j.buffers[entry.side][entry.key] = entries
+	}
+}
+
+func (j *KeyedJoin) send(messages []iggcon.MessengerMessage) error {
+	if j.sink.Confirmation != iggcon.ConfirmationDefault {
+		return j.client.SendMessagesWithConfirmation(j.sink.StreamId, j.sink.TopicId, j.sink.Partitioning, messages, j.sink.Confirmation)
+	}
+	return j.client.SendMessages(j.sink.StreamId, j.sink.TopicId, j.sink.Partitioning, messages)
+}
+
+// joinedId derives the ID of the message emitted for a pair.
+func (j *KeyedJoin) joinedId(joined Joined) iggcon.MessageID {
+	source := make([]byte, 0, len(j.sink.StreamId.Value)+len(j.sink.TopicId.Value)+24)
+	source = append(source, j.sink.StreamId.Value...)
+	source = append(source, j.sink.TopicId.Value...)
+	source = binary.LittleEndian.AppendUint32(source, joined.Left.PartitionId)
+	source = binary.LittleEndian.AppendUint64(source, joined.Left.Message.Header.Offset)
+	source = binary.LittleEndian.AppendUint32(source, joined.Right.PartitionId)
+	source = binary.LittleEndian.AppendUint64(source, joined.Right.Message.Header.Offset)
+	return iggcon.MessageID(uuid.NewSHA1(joinNamespace, source))
+}
+
+// Stats returns the activity of the join so far.
+func (j *KeyedJoin) Stats() JoinStats {
+	j.mtx.Lock()
+	defer j.mtx.Unlock()
+	return JoinStats{Joined: j.joined, Late: j.late, Evicted: j.evicted, Buffered: len(j.oldest)}
+}
+
+// Run consumes the left and right topics of streamId with a consumer each, created with the
+// options, e.g. to set the consumer group, until ctx is done or either consumer fails, which stops
+// the other one. It returns the errors of the consumers.
+func (j *KeyedJoin) Run(ctx context.Context, client messengercli.Client, streamId, leftTopicId, rightTopicId iggcon.Identifier, options ...consumer.Option) error {
+	left, err := consumer.NewConsumer(client, streamId, leftTopicId, j.HandleLeft, options...)
+	if err != nil {
+		return err
+	}
+	right, err := consumer.NewConsumer(client, streamId, rightTopicId, j.HandleRight, options...)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	errs := make(chan error, 2)
+	for _, c := range []*consumer.Consumer{left, right} {
+		go func() {
+			err := c.Run(ctx)
+			if err != nil {
+				cancel()
+			}
+			errs <- err
+		}()
+	}
+	return errors.Join(<-errs, <-errs)
+}
+
+// bufferedHeap orders the buffered messages by timestamp, then arrival.
+type bufferedHeap []*buffered
+
+func (h bufferedHeap) Len() int { return len(h) }
+
+func (h bufferedHeap) Less(i, j int) bool {
+	if !h[i].timestamp.Equal(h[j].timestamp) {
+		return h[i].timestamp.Before(h[j].timestamp)
+	}
+	return h[i].sequence < h[j].sequence
+}
+
+func (h bufferedHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+}
+
+func (h *bufferedHeap) Push(x any) {
+	*h = append(*h, x.(*buffered))
+}
+
+func (h *bufferedHeap) Pop() any {
+	old := *h
+	entry := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return entry
+}
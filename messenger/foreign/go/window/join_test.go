@@ -0,0 +1,151 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package window
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/apache/messenger/foreign/go/consumer"
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengertest"
+)
+
+func newJoinClient(t *testing.T) (*messengertest.Client, iggcon.Identifier) {
+	client := messengertest.NewClient()
+	if _, err := client.CreateStream("shop", nil); err != nil {
+		t.Fatal(err)
+	}
+	streamId, _ := iggcon.NewIdentifier("shop")
+	for _, topic := range []string{"orders", "payments", "paid"} {
+		if _, err := client.CreateTopic(streamId, topic, 1, iggcon.CompressionAlgorithmNone, 0, 0, nil, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return client, streamId
+}
+
+func newTestJoin(t *testing.T, client *messengertest.Client, streamId iggcon.Identifier, options ...Option) *KeyedJoin {
+	paidId, _ := iggcon.NewIdentifier("paid")
+	join, err := NewKeyedJoin(client, consumer.Sink{StreamId: streamId, TopicId: paidId}, time.Minute,
+		func(message iggcon.ReceivedMessage) string {
+			return string(message.Message.Key())
+		},
+		func(joined Joined) (iggcon.MessengerMessage, error) {
+			return iggcon.NewMessengerMessage(append(append(joined.Left.Message.Payload, '+'), joined.Right.Message.Payload...))
+		}, options...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return join
+}
+
+func pollPayloads(t *testing.T, client *messengertest.Client, streamId iggcon.Identifier, topic string) []string {
+	topicId, _ := iggcon.NewIdentifier(topic)
+	partitionId := uint32(1)
+	polled, err := client.PollMessages(streamId, topicId, iggcon.DefaultConsumer(), iggcon.FirstPollingStrategy(), 100, false, &partitionId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var payloads []string
+	for _, message := range polled.Messages {
+		payloads = append(payloads, string(message.Payload))
+	}
+	return payloads
+}
+
+func TestKeyedJoin(t *testing.T) {
+	client, streamId := newJoinClient(t)
+	join := newTestJoin(t, client, streamId, WithLateness(10*time.Second), WithMaxBuffered(4, 0))
+
+	base := time.Unix(1_700_000_000, 0)
+	offset := uint64(0)
+	handle := func(side Side, key, payload string, at time.Duration) {
+		message, err := iggcon.NewMessengerMessage([]byte(payload), iggcon.WithKey([]byte(key)), iggcon.WithTimestamp(base.Add(at)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		message.Header.Offset = offset
+		offset++
+		if err := join.Handle(context.Background(), side, iggcon.ReceivedMessage{Message: message, PartitionId: 1}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	handle(Left, "1", "order1", 0)
+	handle(Right, "1", "payment1", 30*time.Second)
+	handle(Right, "2", "payment2", 40*time.Second)
+	handle(Left, "2", "order2", 50*time.Second)
+	handle(Left, "3", "order3", 60*time.Second)
+	handle(Right, "3", "payment3", 3*time.Minute) // outside the join window
+	handle(Right, "1", "payment1-late", 0)        // past the grace period
+
+	if got, want := pollPayloads(t, client, streamId, "paid"), []string{"order1+payment1", "order2+payment2"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v joined, got %v", want, got)
+	}
+	stats := join.Stats()
+	if stats.Joined != 2 || stats.Late != 1 || stats.Evicted != 1 || stats.Buffered != 1 {
+		t.Fatalf("unexpected join stats: %+v", stats)
+	}
+
+	// the bounds evict the oldest messages before they expire
+	for i := 0; i < 5; i++ {
+		handle(Left, "4", "order4", 3*time.Minute)
+	}
+	if stats := join.Stats(); stats.Buffered != 4 || stats.Evicted != 3 {
+		t.Fatalf("expected the buffers to be bounded, got %+v", stats)
+	}
+}
+
+func TestKeyedJoin_Run(t *testing.T) {
+	client, streamId := newJoinClient(t)
+	// the topics are consumed concurrently, so either message may be handled first
+	join := newTestJoin(t, client, streamId, WithLateness(time.Minute))
+	send := func(topic, key, payload string) {
+		topicId, _ := iggcon.NewIdentifier(topic)
+		message, err := iggcon.NewMessengerMessage([]byte(payload), iggcon.WithKey([]byte(key)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := client.SendMessages(streamId, topicId, iggcon.PartitionId(1), []iggcon.MessengerMessage{message}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	send("orders", "1", "order1")
+	send("payments", "1", "payment1")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	ordersId, _ := iggcon.NewIdentifier("orders")
+	paymentsId, _ := iggcon.NewIdentifier("payments")
+	go func() {
+		done <- join.Run(ctx, client, streamId, ordersId, paymentsId, consumer.WithPollInterval(time.Millisecond))
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for join.Stats().Joined == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if got := pollPayloads(t, client, streamId, "paid"); len(got) != 1 {
+		t.Fatalf("expected a single pair, got %v", got)
+	}
+}
@@ -0,0 +1,118 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package window
+
+import (
+	"context"
+	"time"
+
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+)
+
+// Windows decides the windows a message belongs to, by its timestamp.
+type Windows struct {
+	size  time.Duration
+	slide time.Duration
+}
+
+// Tumbling windows of the given size follow each other without overlapping, so every message
+// belongs to exactly one window.
+func Tumbling(size time.Duration) Windows {
+	return Windows{size: size, slide: size}
+}
+
+// Sliding windows of the given size start every slide, so a message belongs to size / slide
+// overlapping windows, e.g. the last 5 minutes every minute.
+func Sliding(size time.Duration, slide time.Duration) Windows {
+	return Windows{size: size, slide: slide}
+}
+
+// assign returns the start of the windows containing t, aligned on the Unix epoch, latest first.
+func (w Windows) assign(t time.Time) []time.Time {
+	micros := t.UnixMicro()
+	slide := w.slide.Microseconds()
+	last := micros - micros%slide
+	if micros < 0 && micros%slide != 0 {
+		last -= slide
+	}
+	var starts []time.Time
+	for start := last; start > micros-w.size.Microseconds(); start -= slide {
+		starts = append(starts, time.UnixMicro(start))
+	}
+	return starts
+}
+
+type Option func(opts *Options)
+
+type Options struct {
+	// Lateness is how far the watermark trails the latest timestamp seen, the disorder tolerated
+	// among the messages before their windows are emitted, or the grace period of a KeyedJoin.
+	Lateness time.Duration
+	// Timestamp returns the time of a message, its origin timestamp by default.
+	Timestamp func(message iggcon.ReceivedMessage) time.Time
+	// OnLate, when set, is called with the messages dropped because all their windows were emitted.
+	OnLate func(ctx context.Context, message iggcon.ReceivedMessage)
+	// MaxBuffered bounds the number of messages buffered by a KeyedJoin, 0 means unbounded.
+	MaxBuffered int
+	// MaxBufferedBytes bounds the size of the messages buffered by a KeyedJoin, 0 means unbounded.
+	MaxBufferedBytes int
+}
+
+func GetDefaultOptions() Options {
+	return Options{
+		Timestamp: func(message iggcon.ReceivedMessage) time.Time {
+			return time.UnixMicro(int64(message.Message.Header.OriginTimestamp))
+		},
+		MaxBuffered:      100_000,
+		MaxBufferedBytes: 64 * 1024 * 1024,
+	}
+}
+
+// WithLateness lets the messages arrive up to lateness after the latest timestamp seen, delaying
+// the emission of the windows as much. For a KeyedJoin, it is the grace period the messages are
+// kept buffered for past their join window.
+func WithLateness(lateness time.Duration) Option {
+	return func(opts *Options) {
+		opts.Lateness = lateness
+	}
+}
+
+// WithTimestamp sets the function returning the time of a message, e.g. read from its payload.
+func WithTimestamp(timestamp func(message iggcon.ReceivedMessage) time.Time) Option {
+	return func(opts *Options) {
+		opts.Timestamp = timestamp
+	}
+}
+
+// WithOnLate sets the function called with the messages dropped because they arrived after all
+// their windows were emitted.
+func WithOnLate(onLate func(ctx context.Context, message iggcon.ReceivedMessage)) Option {
+	return func(opts *Options) {
+		opts.OnLate = onLate
+	}
+}
+
+// WithMaxBuffered bounds the messages buffered by a KeyedJoin, by count and by size in bytes. The
+// oldest messages are evicted first when a bound is reached, and no longer join. A zero value
+// leaves the corresponding dimension unbounded.
+func WithMaxBuffered(messages int, bytes int) Option {
+	return func(opts *Options) {
+		opts.MaxBuffered = messages
+		opts.MaxBufferedBytes = bytes
+	}
+}
@@ -0,0 +1,324 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package window aggregates the messages of a topic over tumbling or sliding windows of their
+// timestamps, emitting the result of every window to an output topic once the watermark, the
+// latest timestamp seen minus the tolerated lateness, passed its end. It covers counting and
+// metrics without a stream processing framework:
+//
+//	counts, _ := window.NewProcessor(client, consumer.Sink{StreamId: streamId, TopicId: countsId},
+//		window.Aggregation[int]{
+//			Windows: window.Tumbling(time.Minute),
+//			Key:     func(message iggcon.ReceivedMessage) string { return string(message.Message.Key()) },
+//			Add:     func(count int, _ iggcon.ReceivedMessage) (int, error) { return count + 1, nil },
+//			Encode:  encodeCount,
+//		}, window.WithLateness(10*time.Second))
+//	c, _ := consumer.NewConsumer(client, streamId, topicId, counts.Handle,
+//		consumer.WithCommitPolicy(consumer.CommitInterval(time.Second)),
+//		consumer.WithCheckpointer(consumer.NewCheckpointer(store, counts)))
+package window
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/apache/messenger/foreign/go/consumer"
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengercli"
+	"github.com/google/uuid"
+)
+
+// Window is the time range [Start, End) of a window.
+type Window struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Result is the aggregate of the messages of a key within a window.
+type Result[A any] struct {
+	Window Window
+	Key    string
+	Value  A
+	// Count is the number of messages aggregated.
+	Count int
+}
+
+// Aggregation describes how the messages are aggregated.
+type Aggregation[A any] struct {
+	// Windows decides the windows of the messages.
+	Windows Windows
+	// Key groups the messages within a window, nil aggregating them all together.
+	Key func(message iggcon.ReceivedMessage) string
+	// Add folds a message into the value of its window and key, starting from the zero value of A.
+	Add func(value A, message iggcon.ReceivedMessage) (A, error)
+	// Encode creates the message emitted for the result of a window.
+	Encode func(result Result[A]) (iggcon.MessengerMessage, error)
+}
+
+// resultNamespace derives the IDs of the emitted messages.
+var resultNamespace = uuid.MustParse("0f8e6c1a-3b7d-4e52-a9c4-6d2b8f1e7a35")
+
+// Processor aggregates the messages passed to Handle, typically the handler of a consumer. The
+// results are sent by Handle, before the offset of the message closing their window is stored,
+// and get IDs derived from the sink, the window and the key, identical when a redelivery emits
+// them again, so their consumers can drop the duplicates by ID.
+//
+// The open windows are kept in memory. The Processor is a consumer.State, so a consumer created
+// with consumer.WithCheckpointer checkpoints them along with the offsets, provided the values are
+// encodable as JSON; otherwise a restarted consumer misses the messages of the windows open when
+// it stopped. The watermark is shared by all the partitions consumed.
+type Processor[A any] struct {
+	client      messengercli.DataClient
+	sink        consumer.Sink
+	aggregation Aggregation[A]
+	opts        Options
+
+	mtx  sync.Mutex
+	open map[windowKey]*Result[A]
+	// latest is the latest timestamp seen and watermark the time the windows were emitted up to.
+	latest    time.Time
+	watermark time.Time
+}
+
+type windowKey struct {
+	start int64
+	key   string
+}
+
+// NewProcessor creates a Processor emitting the results of the aggregation to the sink.
+func NewProcessor[A any](client messengercli.DataClient, sink consumer.Sink, aggregation Aggregation[A], options ...Option) (*Processor[A], error) {
+	if client == nil {
+		return nil, errors.New("window: client is required")
+	}
+	if aggregation.Windows.size <= 0 || aggregation.Windows.slide <= 0 || aggregation.Windows.slide > aggregation.Windows.size {
+		return nil, errors.New("window: the windows must have a size and a slide up to the size")
+	}
+	if aggregation.Add == nil || aggregation.Encode == nil {
+		return nil, errors.New("window: the aggregation requires Add and Encode")
+	}
+	opts := GetDefaultOptions()
+	for _, opt := range options {
+		if opt != nil {
+			opt(&opts)
+		}
+	}
+	if sink.Partitioning.Kind == 0 {
+		sink.Partitioning = iggcon.None()
+	}
+	return &Processor[A]{
+		client:      client,
+		sink:        sink,
+		aggregation: aggregation,
+		opts:        opts,
+		open:        map[windowKey]*Result[A]{},
+	}, nil
+}
+
+// Handle adds the message to its open windows, after emitting the windows its timestamp closes.
+// It is a consumer.Handler. A failure leaves the windows untouched, so the message can be
+// handled again.
+func (p *Processor[A]) Handle(ctx context.Context, message iggcon.ReceivedMessage) error {
+	timestamp := p.opts.Timestamp(message)
+	key := ""
+	if p.aggregation.Key != nil {
+		key = p.aggregation.Key(message)
+	}
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	if timestamp.After(p.latest) {
+		if err := p.advance(timestamp.Add(-p.opts.Lateness)); err != nil {
+			return err
+		}
+		p.latest = timestamp
+	}
+
+	size := p.aggregation.Windows.size
+	updated := map[windowKey]*Result[A]{}
+	for _, start := range p.aggregation.Windows.assign(timestamp) {
+		if !start.Add(size).After(p.watermark) {
+			// this window and the older ones were emitted
+			break
+		}
+		wk := windowKey{start: start.UnixMicro(), key: key}
+		result := Result[A]{Window: Window{Start: start, End: start.Add(size)}, Key: key}
+		if open, ok := p.open[wk]; ok {
+			result = *open
+		}
+		value, err := p.aggregation.Add(result.Value, message)
+		if err != nil {
+			return err
+		}
+		result.Value = value
+		result.Count++
+		updated[wk] = &result
+	}
+	if len(updated) == 0 {
+		if p.opts.OnLate != nil {
+			p.opts.OnLate(ctx, message)
+		}
+		return nil
+	}
+	for wk, result := range updated {
+		p.open[wk] = result
+	}
+	return nil
+}
+
+// AdvanceTo emits the windows ending by the given watermark, e.g. from the wall clock when the
+// topic is idle, so the last windows are emitted without waiting for newer messages. The messages
+// older than the watermark arriving later are late.
+func (p *Processor[A]) AdvanceTo(watermark time.Time) error {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	return p.advance(watermark)
+}
+
+// advance emits the open windows ending by the watermark, in the order of their start and key.
+// Must hold p.mtx.
+func (p *Processor[A]) advance(watermark time.Time) error {
+	if !watermark.After(p.watermark) {
+		return nil
+	}
+	var closed []windowKey
+	for wk, result := range p.open {
+		if !result.Window.End.After(watermark) {
+			closed = append(closed, wk)
+		}
+	}
+	sort.Slice(closed, func(i, j int) bool {
+		if closed[i].start != closed[j].start {
+			return closed[i].start < closed[j].start
+		}
+		return closed[i].key < closed[j].key
+	})
+
+	messages := make([]iggcon.MessengerMessage, 0, len(closed))
+	for _, wk := range closed {
+		result := p.open[wk]
+		message, err := p.aggregation.Encode(*result)
+		if err != nil {
+			return err
+		}
+		message.Header.Id = p.resultId(result.Window, result.Key)
+		messages = append(messages, message)
+	}
+	if len(messages) > 0 {
+		if err := p.send(messages); err != nil {
+			return err
+		}
+	}
+	for _, wk := range closed {
+		delete(p.open, wk)
+	}
+	p.watermark = watermark
+	return nil
+}
+
+func (p *Processor[A]) send(messages []iggcon.MessengerMessage) error {
+	if p.sink.Confirmation != iggcon.ConfirmationDefault {
+		return p.client.SendMessagesWithConfirmation(p.sink.StreamId, p.sink.TopicId, p.sink.Partitioning, messages, p.sink.Confirmation)
+	}
+	return p.client.SendMessages(p.sink.StreamId, p.sink.TopicId, p.sink.Partitioning, messages)
+}
+
+// resultId derives the ID of the message emitted for a window and key.
+func (p *Processor[A]) resultId(window Window, key string) iggcon.MessageID {
+	source := make([]byte, 0, len(p.sink.StreamId.Value)+len(p.sink.TopicId.Value)+16+len(key))
+	source = append(source, p.sink.StreamId.Value...)
+	source = append(source, p.sink.TopicId.Value...)
+	source = binary.LittleEndian.AppendUint64(source, uint64(window.Start.UnixMicro()))
+	source = binary.LittleEndian.AppendUint64(source, uint64(window.End.UnixMicro()))
+	source = append(source, key...)
+	return iggcon.MessageID(uuid.NewSHA1(resultNamespace, source))
+}
+
+// Open returns the results of the windows not emitted yet, in the order of their start and key.
+func (p *Processor[A]) Open() []Result[A] {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	results := make([]Result[A], 0, len(p.open))
+	for _, result := range p.open {
+		results = append(results, *result)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if !results[i].Window.Start.Equal(results[j].Window.Start) {
+			return results[i].Window.Start.Before(results[j].Window.Start)
+		}
+		return results[i].Key < results[j].Key
+	})
+	return results
+}
+
+// snapshot is the state of a Processor, encoded as JSON.
+type snapshot[A any] struct {
+	Open      []snapshotResult[A] `json:"open"`
+	Latest    int64               `json:"latest"`
+	Watermark int64               `json:"watermark"`
+}
+
+type snapshotResult[A any] struct {
+	Start int64  `json:"start"`
+	End   int64  `json:"end"`
+	Key   string `json:"key"`
+	Value A      `json:"value"`
+	Count int    `json:"count"`
+}
+
+// Snapshot encodes the open windows and the watermark, implementing consumer.State.
+func (p *Processor[A]) Snapshot() ([]byte, error) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	s := snapshot[A]{Latest: p.latest.UnixMicro(), Watermark: p.watermark.UnixMicro()}
+	for _, result := range p.open {
+		s.Open = append(s.Open, snapshotResult[A]{
+			Start: result.Window.Start.UnixMicro(),
+			End:   result.Window.End.UnixMicro(),
+			Key:   result.Key,
+			Value: result.Value,
+			Count: result.Count,
+		})
+	}
+	return json.Marshal(s)
+}
+
+// Restore replaces the open windows and the watermark with a snapshot, implementing consumer.State.
+func (p *Processor[A]) Restore(data []byte) error {
+	var s snapshot[A]
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.open = make(map[windowKey]*Result[A], len(s.Open))
+	for _, restored := range s.Open {
+		p.open[windowKey{start: restored.Start, key: restored.Key}] = &Result[A]{
+			Window: Window{Start: time.UnixMicro(restored.Start), End: time.UnixMicro(restored.End)},
+			Key:    restored.Key,
+			Value:  restored.Value,
+			Count:  restored.Count,
+		}
+	}
+	p.latest = time.UnixMicro(s.Latest)
+	p.watermark = time.UnixMicro(s.Watermark)
+	return nil
+}
@@ -0,0 +1,124 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package window
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/apache/messenger/foreign/go/consumer"
+	iggcon "github.com/apache/messenger/foreign/go/contracts"
+	"github.com/apache/messenger/foreign/go/messengertest"
+)
+
+func TestWindows_Assign(t *testing.T) {
+	base := time.Unix(1_700_000_000, 0).Truncate(time.Hour)
+	if got := Tumbling(time.Minute).assign(base.Add(90 * time.Second)); !reflect.DeepEqual(got, []time.Time{base.Add(time.Minute)}) {
+		t.Fatalf("unexpected tumbling window: %v", got)
+	}
+	got := Sliding(10*time.Minute, 5*time.Minute).assign(base.Add(7 * time.Minute))
+	if want := []time.Time{base.Add(5 * time.Minute), base}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected the sliding windows %v, got %v", want, got)
+	}
+}
+
+func TestProcessor_EmitsClosedWindows(t *testing.T) {
+	client := messengertest.NewClient()
+	if _, err := client.CreateStream("metrics", nil); err != nil {
+		t.Fatal(err)
+	}
+	streamId, _ := iggcon.NewIdentifier("metrics")
+	topicId, _ := iggcon.NewIdentifier("counts")
+	if _, err := client.CreateTopic(streamId, "counts", 1, iggcon.CompressionAlgorithmNone, 0, 0, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	var late []string
+	p, err := NewProcessor(client, consumer.Sink{StreamId: streamId, TopicId: topicId}, Aggregation[int]{
+		Windows: Tumbling(time.Minute),
+		Key: func(message iggcon.ReceivedMessage) string {
+			return string(message.Message.Payload)
+		},
+		Add: func(count int, _ iggcon.ReceivedMessage) (int, error) {
+			return count + 1, nil
+		},
+		Encode: func(result Result[int]) (iggcon.MessengerMessage, error) {
+			return iggcon.NewMessengerMessage([]byte(fmt.Sprintf("%s:%d", result.Key, result.Value)))
+		},
+	}, WithLateness(5*time.Second), WithOnLate(func(_ context.Context, message iggcon.ReceivedMessage) {
+		late = append(late, string(message.Message.Payload))
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	base := time.Unix(1_700_000_000, 0).Truncate(time.Hour)
+	handle := func(payload string, at time.Duration) {
+		message, err := iggcon.NewMessengerMessage([]byte(payload), iggcon.WithTimestamp(base.Add(at)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := p.Handle(context.Background(), iggcon.ReceivedMessage{Message: message}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	handle("a", 10*time.Second)
+	handle("b", 20*time.Second)
+	handle("a", 62*time.Second) // within the lateness, the first window stays open
+	handle("a", 30*time.Second)
+	handle("a", 70*time.Second) // closes the first window
+	handle("b", 50*time.Second) // late
+	snapshot, err := p.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AdvanceTo(base.Add(2 * time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	partitionId := uint32(1)
+	polled, err := client.PollMessages(streamId, topicId, iggcon.DefaultConsumer(), iggcon.FirstPollingStrategy(), 10, false, &partitionId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var emitted []string
+	for _, message := range polled.Messages {
+		emitted = append(emitted, string(message.Payload))
+		if message.Header.Id == (iggcon.MessageID{}) {
+			t.Fatal("expected the results to get an ID")
+		}
+	}
+	if want := []string{"a:2", "b:1", "a:2"}; !reflect.DeepEqual(emitted, want) {
+		t.Fatalf("expected %v emitted, got %v", want, emitted)
+	}
+	if !reflect.DeepEqual(late, []string{"b"}) {
+		t.Fatalf("expected the late message to be dropped, got %v", late)
+	}
+
+	// the second window is back once the snapshot is restored
+	if err := p.Restore(snapshot); err != nil {
+		t.Fatal(err)
+	}
+	open := p.Open()
+	if len(open) != 1 || open[0].Key != "a" || open[0].Value != 2 || !open[0].Window.Start.Equal(base.Add(time.Minute)) {
+		t.Fatalf("unexpected windows restored: %+v", open)
+	}
+}